@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/worker"
+)
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes a JSON request body of at most limit bytes into v. The body must be sent as
+// application/json and hold a single object without unknown fields. On failure it writes the error
+// response and returns false.
+func readJSON(w http.ResponseWriter, r *http.Request, v any, limit int64) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("unexpected data after JSON object")
+	}
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, fmt.Sprintf("Request body larger than %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+		return false
+	case err != nil:
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// handleWorkerStatus handles GET /api/admin/worker/status.
+func (s *Server) handleWorkerStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.worker.Status())
+}
+
+// handleWorkerPause handles POST /api/admin/worker/pause.
+func (s *Server) handleWorkerPause(w http.ResponseWriter, r *http.Request) {
+	s.worker.Pause()
+	s.auditAdminAction(r, "pause", 0, "")
+	writeJSON(w, s.worker.Status())
+}
+
+// handleWorkerResume handles POST /api/admin/worker/resume.
+func (s *Server) handleWorkerResume(w http.ResponseWriter, r *http.Request) {
+	s.worker.Resume()
+	s.auditAdminAction(r, "resume", 0, "")
+	writeJSON(w, s.worker.Status())
+}
+
+// QuarantinedApp describes an app that keeps failing verification attempts.
+type QuarantinedApp struct {
+	ID                  int64      `json:"id"`
+	GitHubURL           string     `json:"github_url"`
+	GitRef              string     `json:"git_ref"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// handleQuarantinedApps handles GET /api/admin/apps/quarantined.
+func (s *Server) handleQuarantinedApps(w http.ResponseWriter, r *http.Request) {
+	apps, err := s.db.GetQuarantinedApps(r.Context(), namespaceFrom(r.Context()), s.cfg.Worker.QuarantineAfter)
+	if err != nil {
+		s.logger.Error("failed to get quarantined apps", "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]QuarantinedApp, 0, len(apps))
+	for _, app := range apps {
+		qa := QuarantinedApp{
+			ID:                  app.ID,
+			GitHubURL:           app.GitHubURL,
+			GitRef:              app.GitRef,
+			ConsecutiveFailures: app.ConsecutiveFailures,
+			LastError:           app.LastError.String,
+		}
+		if app.NextAttemptAt.Valid {
+			qa.NextAttemptAt = &app.NextAttemptAt.Time
+		}
+		result = append(result, qa)
+	}
+
+	writeJSON(w, result)
+}
+
+// handleRegistryErrors handles GET /api/admin/registry/errors, listing the entries of the apps
+// registry that were skipped by the latest sync.
+func (s *Server) handleRegistryErrors(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.db.GetRegistryErrors(r.Context(), namespaceFrom(r.Context()))
+	if err != nil {
+		s.logger.Error("failed to get registry errors", "error", err)
+		http.Error(w, "Failed to load registry errors", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []models.RegistryEntryError{}
+	}
+
+	writeJSON(w, entries)
+}
+
+// handleReleaseApp handles POST /api/admin/apps/{id}/release, clearing an app's failure
+// backoff so it is retried in the next cycle.
+func (s *Server) handleReleaseApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.managedApp(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.ResetAppFailures(r.Context(), app.ID); err != nil {
+		s.logger.Error("failed to reset app failures", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to release app", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "release", app.ID, "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleVerifyApp handles POST /api/admin/apps/{id}/verify, verifying an app right away.
+func (s *Server) handleVerifyApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.managedApp(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.worker.VerifyNow(r.Context(), app)
+	switch {
+	case errors.Is(err, worker.ErrAppBusy):
+		http.Error(w, "App is already being verified", http.StatusConflict)
+		return
+	case err != nil:
+		s.logger.Error("failed to start verification", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "verify", app.ID, "")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleMoveApp handles POST /api/admin/apps/{id}/move, moving an app to the URL GitHub redirects
+// its repository to, keeping its former URL as an alias.
+func (s *Server) handleMoveApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.managedApp(w, r)
+	if !ok {
+		return
+	}
+
+	movedTo, err := s.db.ConfirmAppMove(r.Context(), app.ID)
+	switch {
+	case errors.Is(err, db.ErrNoMove):
+		http.Error(w, "Repository of the app was not moved", http.StatusConflict)
+		return
+	case errors.Is(err, db.ErrURLTaken):
+		http.Error(w, "Another app is listed at the new URL", http.StatusConflict)
+		return
+	case err != nil:
+		s.logger.Error("failed to move app", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to move app", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "move", app.ID, app.GitHubURL+" → "+movedTo)
+	writeJSON(w, map[string]string{"github_url": movedTo, "alias": app.GitHubURL})
+}
+
+// managedApp loads the app named in the URL, writing an error response if it does not exist or
+// the caller may not manage it.
+func (s *Server) managedApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !principalFrom(r.Context()).CanManage(app.GitHubURL) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return app, true
+}
+
+// handleAuthKeys handles GET /api/admin/auth/keys.
+func (s *Server) handleAuthKeys(w http.ResponseWriter, _ *http.Request) {
+	if s.authClient == nil {
+		http.Error(w, "Authentication not configured", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.authClient.Keys())
+}
+
+// handleAuthRotate handles POST /api/admin/auth/rotate, making a standby SIWE key active.
+// The body may name the key to activate as {"address": "0x..."}; by default the first standby key is used.
+func (s *Server) handleAuthRotate(w http.ResponseWriter, r *http.Request) {
+	if s.authClient == nil {
+		http.Error(w, "Authentication not configured", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Address string `json:"address"`
+	}
+	if r.ContentLength != 0 && !readJSON(w, r, &req, 1<<10) {
+		return
+	}
+
+	address, err := s.authClient.Rotate(r.Context(), req.Address)
+	switch {
+	case errors.Is(err, worker.ErrInvalidKeyAddress):
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	case errors.Is(err, worker.ErrNoStandbyKey):
+		http.Error(w, "No matching standby key", http.StatusConflict)
+		return
+	case err != nil:
+		s.logger.Error("failed to rotate SIWE key", "error", err)
+		http.Error(w, "Failed to rotate key", http.StatusBadGateway)
+		return
+	}
+	if err := s.db.SetActiveAuthKey(r.Context(), address.Hex()); err != nil {
+		s.logger.Error("failed to persist SIWE key rotation", "address", address.Hex(), "error", err)
+	}
+
+	s.auditAdminAction(r, "rotate_key", 0, address.Hex())
+	writeJSON(w, s.authClient.Keys())
+}
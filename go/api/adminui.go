@@ -0,0 +1,650 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/worker"
+)
+
+const (
+	adminSessionCookie = "admin_session"
+	adminSessionMaxAge = 12 * time.Hour
+	adminAuditEntries  = 25
+)
+
+// AdminAppRow is an app listed in the admin UI.
+type AdminAppRow struct {
+	ID        int64
+	GitHubURL string
+	GitRef    string
+	Failures  int
+	LastError string
+	NextRetry string // When a backing off app is retried, empty if it is not backing off.
+	MovedTo   string // URL the repository was moved to, awaiting confirmation.
+}
+
+// AdminEvent is an entry of the verification log listed in the admin UI.
+type AdminEvent struct {
+	Index      int64
+	AppID      int64     `json:"app_id"`
+	GitHubURL  string    `json:"github_url"`
+	Deployment string    `json:"deployment"`
+	Status     string    `json:"status"`
+	CommitSHA  string    `json:"commit_sha"`
+	Time       Timestamp `json:"-"`
+}
+
+// AdminActionRow is an entry of the audit log of admin actions listed in the admin UI.
+type AdminActionRow struct {
+	Actor  string
+	Action string
+	AppID  int64
+	Detail string
+	Time   Timestamp
+}
+
+// AdminLoginData holds the data for rendering the admin sign-in page.
+type AdminLoginData struct {
+	Layout     *Layout
+	PathPrefix string // Path prefix of the namespace the admin UI is served in.
+	Error      string
+}
+
+// AdminPageData holds the data for rendering the admin UI.
+type AdminPageData struct {
+	Layout          *Layout
+	PathPrefix      string // Path prefix of the namespace the admin UI is served in.
+	Worker          worker.Status
+	Backend         worker.BackendHealth
+	Quarantined     []AdminAppRow
+	Unverified      []AdminAppRow
+	Moved           []AdminAppRow
+	RegistryErrors  []models.RegistryEntryError
+	Events          []AdminEvent
+	Actions         []AdminActionRow
+	Message         string
+	QuarantineAfter int
+}
+
+var adminLoginTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Admin · {{.Layout.Title}}</title>
+<script src="{{asset "tailwind.js"}}"></script>
+</head>
+<body class="bg-slate-50 min-h-screen flex items-center justify-center">
+<form method="post" action="{{.PathPrefix}}/admin/login" class="bg-white border border-slate-200 rounded-lg p-6 shadow-sm w-80 space-y-4">
+    <h1 class="text-xl font-bold text-slate-900">Admin</h1>
+    {{if .Error}}<div class="text-sm text-red-700">{{.Error}}</div>{{end}}
+    <input type="password" name="token" placeholder="Admin token" autofocus required
+           class="w-full px-3 py-2 border border-slate-300 rounded-md text-sm">
+    <button type="submit" class="w-full px-3 py-2 bg-slate-800 hover:bg-slate-700 text-white rounded-md text-sm font-semibold">Sign in</button>
+</form>
+</body>
+</html>
+`
+
+var adminPageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Admin · {{.Layout.Title}}</title>
+<script src="{{asset "tailwind.js"}}"></script>
+</head>
+<body class="bg-slate-50 text-slate-900">
+<div class="max-w-6xl mx-auto p-6 space-y-6">
+    <div class="flex justify-between items-center">
+        <h1 class="text-2xl font-bold">Admin</h1>
+        <form method="post" action="{{.PathPrefix}}/admin/logout">
+            <button class="text-sm text-slate-600 hover:text-slate-900 underline">Sign out</button>
+        </form>
+    </div>
+    {{if .Message}}<div class="bg-blue-50 border border-blue-200 text-blue-800 rounded-md px-4 py-2 text-sm">{{.Message}}</div>{{end}}
+
+    <!-- Worker -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <div class="flex justify-between items-center mb-3">
+            <h2 class="text-lg font-bold">Worker</h2>
+            {{if .Worker.Paused}}
+            <form method="post" action="{{.PathPrefix}}/admin/worker/resume"><button class="px-3 py-1 bg-emerald-600 hover:bg-emerald-500 text-white rounded-md text-sm font-semibold">Resume</button></form>
+            {{else}}
+            <form method="post" action="{{.PathPrefix}}/admin/worker/pause"><button class="px-3 py-1 bg-amber-600 hover:bg-amber-500 text-white rounded-md text-sm font-semibold">Pause</button></form>
+            {{end}}
+        </div>
+        <dl class="grid grid-cols-[180px_1fr] gap-x-4 gap-y-1 text-sm">
+            <dt class="text-slate-600">State</dt>
+            <dd>{{if not .Worker.Enabled}}Disabled{{else if .Worker.Paused}}Paused{{else}}Running{{end}} ({{.Worker.InstanceID}})</dd>
+            <dt class="text-slate-600">Current app</dt>
+            <dd>{{if .Worker.CurrentAppID}}#{{.Worker.CurrentAppID}} {{.Worker.CurrentAppURL}}{{else}}None{{end}}</dd>
+            <dt class="text-slate-600">Queue</dt>
+            <dd>{{.Worker.QueueLength}} apps left in the cycle</dd>
+            <dt class="text-slate-600">Last cycle</dt>
+            <dd>{{if .Worker.LastCycleCompletedAt}}{{.Worker.LastCycleCompletedAt.Format "2006-01-02 15:04:05 MST"}} ({{printf "%.0f" .Worker.LastCycleDurationSeconds}}s){{else}}Not completed yet{{end}}</dd>
+            <dt class="text-slate-600">Backend</dt>
+            <dd>{{.Backend.Status}}{{if .Backend.Version}} {{.Backend.Version}}{{end}}{{if .Backend.Error}} <span class="text-red-700">{{.Backend.Error}}</span>{{end}}</dd>
+        </dl>
+    </section>
+
+    <!-- Quarantined apps -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Quarantined apps <span class="text-sm font-normal text-slate-500">({{.QuarantineAfter}}+ consecutive failures)</span></h2>
+        {{if .Quarantined}}
+        <table class="w-full text-sm">
+            <thead><tr class="text-left text-slate-600"><th class="py-1">App</th><th>Failures</th><th>Last error</th><th>Retry</th><th></th></tr></thead>
+            <tbody>
+            {{range .Quarantined}}
+            <tr class="border-t border-slate-200 align-top">
+                <td class="py-2"><a href="{{.GitHubURL}}" class="text-blue-600 hover:underline">#{{.ID}} {{.GitHubURL}}</a> <span class="text-slate-500">@{{.GitRef}}</span></td>
+                <td class="py-2">{{.Failures}}</td>
+                <td class="py-2 text-xs text-red-700 break-all">{{.LastError}}</td>
+                <td class="py-2 text-xs">{{.NextRetry}}</td>
+                <td class="py-2 whitespace-nowrap text-right">
+                    <form method="post" action="{{$.PathPrefix}}/admin/apps/{{.ID}}/release" class="inline"><button class="px-2 py-1 bg-slate-100 hover:bg-slate-200 rounded text-xs font-semibold">Release</button></form>
+                    <form method="post" action="{{$.PathPrefix}}/admin/apps/{{.ID}}/verify" class="inline"><button class="px-2 py-1 bg-slate-800 hover:bg-slate-700 text-white rounded text-xs font-semibold">Re-verify</button></form>
+                </td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{else}}<p class="text-sm text-slate-500">No quarantined apps.</p>{{end}}
+    </section>
+
+    <!-- Pending apps -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Pending apps <span class="text-sm font-normal text-slate-500">(no verified deployment)</span></h2>
+        {{if .Unverified}}
+        <table class="w-full text-sm">
+            <tbody>
+            {{range .Unverified}}
+            <tr class="border-t border-slate-200 align-top">
+                <td class="py-2"><a href="{{.GitHubURL}}" class="text-blue-600 hover:underline">#{{.ID}} {{.GitHubURL}}</a> <span class="text-slate-500">@{{.GitRef}}</span>
+                    {{if .LastError}}<div class="text-xs text-red-700 break-all">{{.LastError}}</div>{{end}}</td>
+                <td class="py-2 text-right"><form method="post" action="{{$.PathPrefix}}/admin/apps/{{.ID}}/verify"><button class="px-2 py-1 bg-slate-800 hover:bg-slate-700 text-white rounded text-xs font-semibold">Verify now</button></form></td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{else}}<p class="text-sm text-slate-500">All apps have a verified deployment.</p>{{end}}
+    </section>
+
+    <!-- Moved repositories -->
+    {{if .Moved}}
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Moved repositories <span class="text-sm font-normal text-slate-500">(renamed or transferred on GitHub)</span></h2>
+        <table class="w-full text-sm">
+            <tbody>
+            {{range .Moved}}
+            <tr class="border-t border-slate-200 align-top">
+                <td class="py-2"><a href="{{.GitHubURL}}" class="text-blue-600 hover:underline">#{{.ID}} {{.GitHubURL}}</a>
+                    <div class="text-xs text-slate-600">now at <a href="{{.MovedTo}}" class="text-blue-600 hover:underline">{{.MovedTo}}</a></div></td>
+                <td class="py-2 text-right"><form method="post" action="{{$.PathPrefix}}/admin/apps/{{.ID}}/move"><button class="px-2 py-1 bg-slate-800 hover:bg-slate-700 text-white rounded text-xs font-semibold">Confirm move</button></form></td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+    </section>
+    {{end}}
+
+    <!-- Registry errors -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Skipped registry entries</h2>
+        {{if .RegistryErrors}}
+        <ul class="space-y-2 text-sm">
+            {{range .RegistryErrors}}
+            <li class="border-t border-slate-200 pt-2">
+                <div class="font-semibold">Entry {{.Index}}{{if .Line}} (line {{.Line}}){{end}}{{if .URL}}: {{.URL}}{{end}}</div>
+                <ul class="list-disc pl-5 text-xs text-red-700">{{range .Errors}}<li>{{.}}</li>{{end}}</ul>
+            </li>
+            {{end}}
+        </ul>
+        {{else}}<p class="text-sm text-slate-500">All registry entries are valid.</p>{{end}}
+    </section>
+
+    <!-- Audit log -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Recent admin actions</h2>
+        {{if .Actions}}
+        <table class="w-full text-sm">
+            <thead><tr class="text-left text-slate-600"><th class="py-1">Action</th><th>By</th><th>App</th><th>Detail</th><th>Time</th></tr></thead>
+            <tbody>
+            {{range .Actions}}
+            <tr class="border-t border-slate-200">
+                <td class="py-1">{{.Action}}</td>
+                <td class="py-1">{{.Actor}}</td>
+                <td class="py-1">{{if .AppID}}#{{.AppID}}{{end}}</td>
+                <td class="py-1 text-xs break-all">{{.Detail}}</td>
+                <td class="py-1 text-xs">{{.Time.HTML}}</td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{else}}<p class="text-sm text-slate-500">No admin actions yet.</p>{{end}}
+    </section>
+
+    <!-- Verification log -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-3">Recent verification log entries</h2>
+        {{if .Events}}
+        <table class="w-full text-sm">
+            <thead><tr class="text-left text-slate-600"><th class="py-1">#</th><th>App</th><th>Deployment</th><th>Status</th><th>Commit</th><th>Time</th></tr></thead>
+            <tbody>
+            {{range .Events}}
+            <tr class="border-t border-slate-200">
+                <td class="py-1 text-slate-500">{{.Index}}</td>
+                <td class="py-1">#{{.AppID}} {{.GitHubURL}}</td>
+                <td class="py-1">{{.Deployment}}</td>
+                <td class="py-1">{{.Status}}</td>
+                <td class="py-1 font-mono text-xs">{{.CommitSHA}}</td>
+                <td class="py-1 text-xs">{{.Time.HTML}}</td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{else}}<p class="text-sm text-slate-500">The verification log is empty.</p>{{end}}
+    </section>
+</div>
+</body>
+</html>
+`
+
+// adminTokenID identifies the admin token sessions are signed in with, without storing the token
+// itself, so that sessions end when the token is rotated.
+func (s *Server) adminTokenID() string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Server.AdminToken))
+	mac.Write([]byte("admin session"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// requireAdminSession restricts the admin UI to browsers signed in with the admin token. Sessions
+// are random tokens stored server-side, so that they expire and end on sign-out even if a cookie
+// was copied. The admin UI is disabled entirely when no token is configured.
+func (s *Server) requireAdminSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.Server.AdminToken == "" {
+			http.Error(w, "Admin UI not configured", http.StatusNotFound)
+			return
+		}
+
+		cookie, err := r.Cookie(adminSessionCookie)
+		if err != nil || cookie.Value == "" {
+			http.Redirect(w, r, s.pathPrefix(r)+"/admin/login", http.StatusSeeOther)
+			return
+		}
+		ok, err := s.db.IsAdminSession(r.Context(), hashSessionToken(cookie.Value), s.adminTokenID())
+		if err != nil {
+			s.logger.Error("failed to get admin session", "error", err)
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			s.setAdminSession(w, r, "")
+			http.Redirect(w, r, s.pathPrefix(r)+"/admin/login", http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setAdminSession sets or, with an empty value, clears the admin session cookie.
+func (s *Server) setAdminSession(w http.ResponseWriter, r *http.Request, value string) {
+	cookie := &http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    value,
+		Path:     s.pathPrefix(r) + "/admin",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(adminSessionMaxAge.Seconds()),
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// handleAdminLoginPage handles GET /admin/login.
+func (s *Server) handleAdminLoginPage(w http.ResponseWriter, r *http.Request) {
+	s.renderAdmin(w, s.adminLoginTemplate, AdminLoginData{Layout: s.layout, PathPrefix: s.pathPrefix(r)}, http.StatusOK)
+}
+
+// handleAdminLogin handles POST /admin/login, starting an admin session if the token is valid.
+func (s *Server) handleAdminLogin(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Server.AdminToken == "" {
+		http.Error(w, "Admin UI not configured", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	token := r.PostFormValue("token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Server.AdminToken)) != 1 {
+		s.logger.Warn("failed admin UI sign-in", "remote_addr", r.RemoteAddr)
+		s.renderAdmin(w, s.adminLoginTemplate, AdminLoginData{Layout: s.layout, PathPrefix: s.pathPrefix(r), Error: "Invalid token"}, http.StatusUnauthorized)
+		return
+	}
+
+	session, err := randomToken()
+	if err != nil {
+		s.logger.Error("failed to generate session token", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	if err := s.db.CreateAdminSession(r.Context(), hashSessionToken(session), s.adminTokenID(), time.Now().Add(adminSessionMaxAge)); err != nil {
+		s.logger.Error("failed to create admin session", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "sign_in", 0, "")
+	s.setAdminSession(w, r, session)
+	http.Redirect(w, r, s.pathPrefix(r)+"/admin", http.StatusSeeOther)
+}
+
+// handleAdminLogout handles POST /admin/logout, ending the admin session.
+func (s *Server) handleAdminLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(adminSessionCookie); err == nil && cookie.Value != "" {
+		if err := s.db.DeleteAdminSession(r.Context(), hashSessionToken(cookie.Value)); err != nil {
+			s.logger.Error("failed to delete admin session", "error", err)
+			http.Error(w, "Failed to sign out", http.StatusInternalServerError)
+			return
+		}
+	}
+	s.setAdminSession(w, r, "")
+	http.Redirect(w, r, s.pathPrefix(r)+"/admin/login", http.StatusSeeOther)
+}
+
+// handleAdminPage handles GET /admin, showing the worker state, apps needing attention, skipped
+// registry entries, and the latest verification log entries.
+func (s *Server) handleAdminPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	data := AdminPageData{
+		Layout:          s.layout,
+		PathPrefix:      s.pathPrefix(r),
+		Worker:          s.worker.Status(),
+		Backend:         s.worker.BackendHealth(),
+		Message:         adminMessage(r.URL.Query()),
+		QuarantineAfter: s.cfg.Worker.QuarantineAfter,
+	}
+
+	quarantined, err := s.db.GetQuarantinedApps(ctx, namespaceFrom(ctx), s.cfg.Worker.QuarantineAfter)
+	if err != nil {
+		s.logger.Error("failed to get quarantined apps", "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+	data.Quarantined = adminAppRows(quarantined)
+
+	unverified, err := s.db.GetUnverifiedApps(ctx, namespaceFrom(ctx))
+	if err != nil {
+		s.logger.Error("failed to get unverified apps", "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+	data.Unverified = adminAppRows(unverified)
+
+	moved, err := s.db.GetMovedApps(ctx, namespaceFrom(ctx))
+	if err != nil {
+		s.logger.Error("failed to get moved apps", "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+	data.Moved = adminAppRows(moved)
+
+	if data.RegistryErrors, err = s.db.GetRegistryErrors(ctx, namespaceFrom(ctx)); err != nil {
+		s.logger.Error("failed to get registry errors", "error", err)
+		http.Error(w, "Failed to load registry errors", http.StatusInternalServerError)
+		return
+	}
+
+	size, err := s.db.GetEventLogSize(ctx)
+	if err != nil {
+		s.logger.Error("failed to get verification log size", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	events, err := s.db.GetEvents(ctx, max(size-adminAuditEntries, 0), adminAuditEntries)
+	if err != nil {
+		s.logger.Error("failed to get verification log entries", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	loc := localeFor(r)
+	for i := len(events) - 1; i >= 0; i-- {
+		event := AdminEvent{Index: events[i].Index, Time: loc.Time(events[i].CreatedAt)}
+		if err := json.Unmarshal([]byte(events[i].Entry), &event); err != nil {
+			s.logger.Warn("failed to decode verification log entry", "index", events[i].Index, "error", err)
+		}
+		event.CommitSHA = shortSHA(event.CommitSHA)
+		data.Events = append(data.Events, event)
+	}
+
+	actions, err := s.db.GetAdminActions(ctx, namespaceFrom(ctx), adminAuditEntries)
+	if err != nil {
+		s.logger.Error("failed to get admin actions", "error", err)
+		http.Error(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+	for _, action := range actions {
+		data.Actions = append(data.Actions, AdminActionRow{
+			Actor:  action.Actor,
+			Action: action.Action,
+			AppID:  action.AppID.Int64,
+			Detail: action.Detail,
+			Time:   loc.Time(action.CreatedAt),
+		})
+	}
+
+	s.renderAdmin(w, s.adminTemplate, data, http.StatusOK)
+}
+
+// auditAdminAction logs an action taken through the admin UI or API, by the API key of the request
+// or the admin UI, and records it in the audit log shown in the admin UI. An appID of 0 means the
+// action concerns no app. Failing to record the action does not fail it, as it already took effect.
+func (s *Server) auditAdminAction(r *http.Request, action string, appID int64, detail string) {
+	actor := "admin UI"
+	if p := principalFrom(r.Context()); p != nil {
+		actor = p.Name
+	}
+	entry := &models.AdminAction{
+		Namespace: namespaceFrom(r.Context()),
+		Actor:     actor,
+		Action:    action,
+		AppID:     sql.NullInt64{Int64: appID, Valid: appID != 0},
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+	s.logger.Info("admin action", "action", action, "by", actor, "app_id", appID, "detail", detail, "namespace", entry.Namespace)
+	if err := s.db.RecordAdminAction(r.Context(), entry); err != nil {
+		s.logger.Error("failed to record admin action", "action", action, "error", err)
+	}
+}
+
+// adminAppRows converts apps to rows of the admin UI.
+func adminAppRows(apps []*models.App) []AdminAppRow {
+	rows := make([]AdminAppRow, 0, len(apps))
+	for _, app := range apps {
+		row := AdminAppRow{
+			ID:        app.ID,
+			GitHubURL: app.GitHubURL,
+			GitRef:    app.GitRef,
+			Failures:  app.ConsecutiveFailures,
+			LastError: app.LastError.String,
+			MovedTo:   app.MovedTo.String,
+		}
+		if app.NextAttemptAt.Valid && app.NextAttemptAt.Time.After(time.Now()) {
+			row.NextRetry = app.NextAttemptAt.Time.Format("2006-01-02 15:04 MST")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// handleAdminWorkerPause handles POST /admin/worker/pause.
+func (s *Server) handleAdminWorkerPause(w http.ResponseWriter, r *http.Request) {
+	s.worker.Pause()
+	s.auditAdminAction(r, "pause", 0, "")
+	s.redirectAdmin(w, r, "paused", 0)
+}
+
+// handleAdminWorkerResume handles POST /admin/worker/resume.
+func (s *Server) handleAdminWorkerResume(w http.ResponseWriter, r *http.Request) {
+	s.worker.Resume()
+	s.auditAdminAction(r, "resume", 0, "")
+	s.redirectAdmin(w, r, "resumed", 0)
+}
+
+// handleAdminReleaseApp handles POST /admin/apps/{id}/release.
+func (s *Server) handleAdminReleaseApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.adminApp(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.ResetAppFailures(r.Context(), app.ID); err != nil {
+		s.logger.Error("failed to reset app failures", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to release app", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "release", app.ID, "")
+	s.redirectAdmin(w, r, "released", app.ID)
+}
+
+// handleAdminVerifyApp handles POST /admin/apps/{id}/verify, verifying an app right away.
+func (s *Server) handleAdminVerifyApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.adminApp(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.worker.VerifyNow(r.Context(), app)
+	switch {
+	case errors.Is(err, worker.ErrAppBusy):
+		s.redirectAdmin(w, r, "busy", app.ID)
+		return
+	case err != nil:
+		s.logger.Error("failed to start verification", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "verify", app.ID, "")
+	s.redirectAdmin(w, r, "verifying", app.ID)
+}
+
+// handleAdminMoveApp handles POST /admin/apps/{id}/move, confirming the move of an app's repository.
+func (s *Server) handleAdminMoveApp(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.adminApp(w, r)
+	if !ok {
+		return
+	}
+
+	movedTo, err := s.db.ConfirmAppMove(r.Context(), app.ID)
+	switch {
+	case errors.Is(err, db.ErrNoMove):
+		s.redirectAdmin(w, r, "not_moved", app.ID)
+		return
+	case errors.Is(err, db.ErrURLTaken):
+		s.redirectAdmin(w, r, "url_taken", app.ID)
+		return
+	case err != nil:
+		s.logger.Error("failed to move app", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to move app", http.StatusInternalServerError)
+		return
+	}
+
+	s.auditAdminAction(r, "move", app.ID, app.GitHubURL+" → "+movedTo)
+	s.redirectAdmin(w, r, "moved", app.ID)
+}
+
+// adminApp loads the app named in the URL, writing an error response if it does not exist.
+func (s *Server) adminApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return nil, false
+	}
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return nil, false
+	}
+	return app, true
+}
+
+// adminMessages are the messages shown on the admin page after an action, by their code, with
+// the ID of the app the action concerns. Redirects only carry the code, so that links to the admin
+// UI cannot show arbitrary text.
+var adminMessages = map[string]string{
+	"paused":    "Worker paused.",
+	"resumed":   "Worker resumed.",
+	"released":  "App %d released, it is retried in the next cycle.",
+	"busy":      "App %d is already being verified.",
+	"verifying": "Verification of app %d started.",
+	"not_moved": "App %d was not moved.",
+	"url_taken": "Another app is already listed at the new URL of app %d.",
+	"moved":     "App %d moved to its new URL.",
+}
+
+// redirectAdmin redirects back to the admin page, showing the message of a code about an app. An
+// appID of 0 means the message concerns no app.
+func (s *Server) redirectAdmin(w http.ResponseWriter, r *http.Request, code string, appID int64) {
+	query := url.Values{"message": {code}}
+	if appID != 0 {
+		query.Set("app", strconv.FormatInt(appID, 10))
+	}
+	http.Redirect(w, r, s.pathPrefix(r)+"/admin?"+query.Encode(), http.StatusSeeOther)
+}
+
+// adminMessage returns the message of the code the admin page was redirected to with, or an empty
+// string if there is none.
+func adminMessage(query url.Values) string {
+	message, ok := adminMessages[query.Get("message")]
+	if !ok || !strings.Contains(message, "%d") {
+		return message
+	}
+	appID, err := strconv.ParseInt(query.Get("app"), 10, 64)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(message, appID)
+}
+
+// renderAdmin renders an admin UI page.
+func (s *Server) renderAdmin(w http.ResponseWriter, tmpl *template.Template, data any, status int) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		s.logger.Error("failed to render admin page", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
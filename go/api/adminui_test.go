@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+)
+
+// Test that the admin page only shows the messages of known codes.
+func TestAdminMessage(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "message=paused", want: "Worker paused."},
+		{query: "message=released&app=7", want: "App 7 released, it is retried in the next cycle."},
+		{query: "message=released", want: ""},
+		{query: "message=released&app=7%3Cb%3E", want: ""},
+		{query: "message=Your+session+expired%2C+sign+in+at+https%3A%2F%2Fevil.example", want: ""},
+		{query: "", want: ""},
+	} {
+		query, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatalf("Failed to parse query %q: %v", tc.query, err)
+		}
+		if got := adminMessage(query); got != tc.want {
+			t.Errorf("Expected message %q for %q, got %q", tc.want, tc.query, got)
+		}
+	}
+}
@@ -3,98 +3,159 @@ package api
 
 import (
 	"context"
+	"crypto/cipher"
 	"fmt"
 	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 	"github.com/go-chi/httplog/v3"
 
 	"github.com/ptrus/rofl-attestations/config"
 	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/storage"
 	"github.com/ptrus/rofl-attestations/worker"
 )
 
 // Server is the API server.
 type Server struct {
-	cfg          *config.Config
-	db           *db.DB
-	logger       *slog.Logger
-	cardTemplate *template.Template
-	authClient   *worker.AuthClient
+	cfg                *config.Config
+	db                 *db.DB
+	logger             *slog.Logger
+	cardTemplate       localizedTemplate
+	diffTemplate       localizedTemplate
+	metaTemplate       *template.Template
+	statusTemplate     localizedTemplate
+	sortTemplate       localizedTemplate
+	embedTemplate      localizedTemplate
+	watchTemplate      localizedTemplate
+	adminTemplate      *template.Template
+	adminLoginTemplate *template.Template
+	maintainerTemplate *template.Template
+	layout             *Layout
+	indexTemplate      localizedTemplate
+	authClient         *worker.AuthClient
+	backend            *http.Client // Client for requests to the verification backend.
+	worker             *worker.Worker
+	artifacts          *storage.Artifacts
+	oauth              *github.OAuth // Nil when maintainer sign-in is not configured.
+	tokenCipher        cipher.AEAD   // Encrypts the access tokens of maintainer sessions, nil without oauth.
+	idempotency        *idempotencyCache
+	pageCache          *pageCache // Nil when page caching is disabled.
 }
 
 // New creates a new API server.
-func New(cfg *config.Config, database *db.DB, logger *slog.Logger) (*Server, error) {
+func New(cfg *config.Config, database *db.DB, verificationWorker *worker.Worker, artifacts *storage.Artifacts, logger *slog.Logger) (*Server, error) {
 	// Parse the app card template once at initialization
-	cardTemplate := template.Must(template.New("app-card").Parse(appCardTemplate))
-
-	// Initialize auth client if configured
-	var authClient *worker.AuthClient
-	if cfg.Worker.PrivateKey != "" {
-		var err error
-		authClient, err = worker.NewAuthClient(
-			cfg.Worker.BackendURL,
-			cfg.Worker.PrivateKey,
-			cfg.Worker.SIWEDomain,
-			cfg.Worker.ChainID,
-			logger,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create auth client: %w", err)
+	indexTemplate := parseLocalized("index", indexTemplate)
+	cardTemplate := parseLocalized("app-card", appCardTemplate)
+	diffTemplate := parseLocalized("manifest-diff", manifestDiffTemplate)
+	metaTemplate := template.Must(template.New("page-meta").Parse(pageMetaTemplate))
+	statusTemplate := parseLocalized("backend-status", backendStatusTemplate)
+	sortTemplate := parseLocalized("sort-controls", sortControlsTemplate)
+	embedTemplate := parseLocalized("embed", embedTemplate)
+	watchTemplate := parseLocalized("watch", watchPageTemplate)
+	adminTemplate := template.Must(template.New("admin").Funcs(assetFuncs).Parse(adminPageTemplate))
+	adminLoginTemplate := template.Must(template.New("admin-login").Funcs(assetFuncs).Parse(adminLoginTemplate))
+	maintainerTemplate := template.Must(template.New("maintainer").Funcs(assetFuncs).Parse(maintainerPageTemplate))
+
+	for _, name := range assets.Missing() {
+		logger.Warn("static asset not vendored, loading it from a CDN", "asset", name)
+	}
+
+	layout := newLayout(&cfg.Branding)
+
+	clients, err := httpclient.New(&cfg.HTTP, &cfg.Worker.BackendTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	var oauth *github.OAuth
+	var tokenCipher cipher.AEAD
+	if cfg.GitHub.OAuth.Enabled() {
+		oauth = github.NewOAuth(clients.Client(httpclient.GitHub), &cfg.GitHub.OAuth)
+		if tokenCipher, err = newAccessTokenCipher(cfg.GitHub.OAuth.ClientSecret); err != nil {
+			return nil, err
 		}
-		logger.Info("API auth client initialized", "address", authClient.Address().Hex())
 	}
 
+	var pages *pageCache
+	if cfg.Server.PageCacheTTL > 0 {
+		pages = newPageCache(time.Duration(cfg.Server.PageCacheTTL) * time.Second)
+	}
+
+	// Share the worker's auth client, so that key rotations apply to both.
+	authClient := verificationWorker.AuthClient()
+
 	return &Server{
-		cfg:          cfg,
-		db:           database,
-		logger:       logger,
-		cardTemplate: cardTemplate,
-		authClient:   authClient,
+		cfg:                cfg,
+		db:                 database,
+		logger:             logger,
+		cardTemplate:       cardTemplate,
+		diffTemplate:       diffTemplate,
+		metaTemplate:       metaTemplate,
+		statusTemplate:     statusTemplate,
+		sortTemplate:       sortTemplate,
+		embedTemplate:      embedTemplate,
+		watchTemplate:      watchTemplate,
+		adminTemplate:      adminTemplate,
+		adminLoginTemplate: adminLoginTemplate,
+		maintainerTemplate: maintainerTemplate,
+		layout:             layout,
+		indexTemplate:      indexTemplate,
+		authClient:         authClient,
+		backend:            clients.Client(httpclient.Backend),
+		worker:             verificationWorker,
+		artifacts:          artifacts,
+		oauth:              oauth,
+		tokenCipher:        tokenCipher,
+		idempotency:        newIdempotencyCache(),
+		pageCache:          pages,
 	}, nil
 }
 
-// Run starts the HTTP server.
+// Run starts the HTTP server on the configured listen address.
 func (s *Server) Run(ctx context.Context) error {
-	r := chi.NewRouter()
-
-	// Setup CORS only if origins are explicitly configured.
-	// Empty list means same-origin only (no CORS).
-	if len(s.cfg.Server.AllowedOrigins) > 0 {
-		s.logger.Info("enabling CORS", "allowed_origins", s.cfg.Server.AllowedOrigins)
-		r.Use(cors.Handler(cors.Options{
-			AllowedOrigins:   s.cfg.Server.AllowedOrigins,
-			AllowedMethods:   []string{"GET", "POST", "PUT"},
-			AllowedHeaders:   []string{"Authorization", "Content-Type"},
-			AllowCredentials: false,
-		}))
-	} else {
-		s.logger.Info("CORS not configured - same-origin requests only")
+	listener, err := net.Listen("tcp", s.cfg.Server.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
 	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves HTTP requests on a listener until the context is canceled.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	r := chi.NewRouter()
 
 	// Setup global middlewares.
 	r.Use(
 		middleware.RequestID,
 		middleware.RealIP,
 		httplog.RequestLogger(s.logger, &httplog.Options{}),
+		s.resolveNamespace,
+		s.cors,
+		s.securityHeaders,
 		middleware.Recoverer,
-		middleware.Timeout(10*time.Second),
 	)
 
-	// Routes.
-	r.Get("/", s.serveIndex)
+	// Streams run until the client disconnects or the server shuts down, and build logs may be
+	// large, so neither is limited by the request timeout.
+	r.Get("/api/v1/events/stream", s.handleStatusEventStream(ctx.Done()))
+	r.Get("/api/apps/{id}/deployments/{name}/log", s.handleGetDeploymentLog)
 
-	r.Get("/htmx/apps", s.handleGetApps)
-	r.Get("/htmx/apps/{id}", s.handleGetApp)
+	// Submitting a verification may wait for a slot of the backend budget.
+	r.With(middleware.Timeout(time.Duration(s.cfg.Server.VerifyTimeout)*time.Second)).Post("/api/verify", s.handleVerify)
 
-	// Live verification API
-	r.Post("/api/verify", s.handleVerify)
-	r.Get("/api/verify/{task_id}/results", s.handleVerifyResults)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(time.Duration(s.cfg.Server.RequestTimeout) * time.Second))
+		s.routes(r)
+	})
 
 	// Health check.
 	r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
@@ -104,17 +165,16 @@ func (s *Server) Run(ctx context.Context) error {
 
 	// Start server.
 	srv := &http.Server{
-		Addr:              s.cfg.Server.ListenAddr,
 		Handler:           r,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	s.logger.Info("starting server", "addr", s.cfg.Server.ListenAddr)
+	s.logger.Info("starting server", "addr", listener.Addr().String())
 
 	// Run server in goroutine
 	errCh := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errCh <- err
 		}
 	}()
@@ -130,3 +190,108 @@ func (s *Server) Run(ctx context.Context) error {
 		return fmt.Errorf("server error: %w", err)
 	}
 }
+
+// routes registers the routes limited by the request timeout.
+func (s *Server) routes(r chi.Router) {
+	r.Get("/", s.serveIndex)
+	r.Get("/apps/{ref}", s.handleAppPage)
+	r.Get("/apps/{ref}/feed.xml", s.handleAppFeed)
+	r.Get("/feed.xml", s.handleFeed)
+	r.Get("/embed/{slug}", s.handleEmbed)
+	r.Get("/robots.txt", s.handleRobots)
+	r.Get("/static/{file}", s.handleStatic)
+	r.Get("/sitemap.xml", s.handleSitemap)
+	r.Get("/watch/confirm", s.handleWatchConfirmPage)
+	r.Post("/watch/confirm", s.handleWatchConfirm)
+	r.Get("/watch/unsubscribe", s.handleWatchUnsubscribePage)
+	r.Post("/watch/unsubscribe", s.handleWatchUnsubscribe)
+	r.Get("/metrics", s.handleMetrics)
+
+	r.Get("/htmx/apps", s.handleGetApps)
+	r.Get("/htmx/apps/{id}", s.handleGetApp)
+	r.Get("/htmx/apps/{id}/description", s.handleAppDescription)
+	r.Get("/htmx/apps/{id}/deployments/{name}/enclaves", s.handleDeploymentEnclaves)
+	r.Get("/htmx/apps/{id}/manifest/diff", s.handleManifestDiffHTML)
+	r.Post("/htmx/apps/{id}/watch", s.handleWatch)
+	r.Get("/htmx/sort", s.handleSortControls)
+	r.Get("/htmx/status", s.handleStatusHTML)
+	r.Get("/api/status", s.handleStatus)
+	r.Get("/api/oembed", s.handleOEmbed)
+	r.Get("/api/apps", s.handleListApps)
+	r.Get("/api/v1/apps/by-slug/{slug}", s.handleGetAppBySlug)
+	r.Get("/api/v1/apps/{id}", s.handleGetAppAsOf)
+	r.Get("/api/v1/apps/{id}/attestation-report", s.handleAttestationReport)
+	r.Get("/api/v1/apps/{id}/dependencies", s.handleDependencies)
+	r.Get("/api/v1/apps/{id}/deployments/{name}/provenance", s.handleProvenance)
+	r.Get("/api/v1/events", s.handleStatusEvents)
+	r.Get("/api/v1/stats", s.handleStats)
+	r.Get("/api/v1/reports/attention", s.handleAttentionReport)
+	r.Get("/api/v1/log", s.handleLogRoot)
+	r.Get("/api/v1/log/entries", s.handleLogEntries)
+	r.Get("/api/v1/log/proof/{index}", s.handleLogProof)
+	r.Get("/api/apps/{id}/manifest/diff", s.handleManifestDiff)
+	r.Get("/api/apps/{id}/compose", s.handleGetCompose)
+	r.Get("/api/apps/{id}/manifest", s.handleGetManifest)
+	r.Get("/api/apps/{id}/preview.png", s.handleAppPreview)
+	r.Get("/api/apps/{id}/logo.png", s.handleAppLogo)
+
+	// Live verification API
+	r.Get("/api/verify/{task_id}/results", s.handleVerifyResults)
+
+	// Admin API (requires server.admin_token or an API key with the given role)
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(s.requireRole(config.RoleViewer))
+		r.Get("/apps/quarantined", s.handleQuarantinedApps)
+		r.Get("/registry/errors", s.handleRegistryErrors)
+
+		r.Group(func(r chi.Router) {
+			r.Use(s.requireRole(config.RoleMaintainer))
+			r.Post("/apps/{id}/release", s.handleReleaseApp)
+			r.Post("/apps/{id}/verify", s.handleVerifyApp)
+			r.Post("/apps/{id}/move", s.handleMoveApp)
+		})
+
+		// The worker is shared by all namespaces, so keys of a single namespace cannot manage it.
+		r.Group(func(r chi.Router) {
+			r.Use(s.requireOperator)
+			r.Get("/worker/status", s.handleWorkerStatus)
+
+			r.Group(func(r chi.Router) {
+				r.Use(s.requireRole(config.RoleAdmin))
+				r.Post("/worker/pause", s.handleWorkerPause)
+				r.Post("/worker/resume", s.handleWorkerResume)
+				r.Get("/auth/keys", s.handleAuthKeys)
+				r.Post("/auth/rotate", s.handleAuthRotate)
+			})
+		})
+	})
+
+	// Admin UI (signed in with server.admin_token)
+	r.Get("/admin/login", s.handleAdminLoginPage)
+	r.Post("/admin/login", s.handleAdminLogin)
+	r.Post("/admin/logout", s.handleAdminLogout)
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(s.requireAdminSession)
+		r.Get("/", s.handleAdminPage)
+		r.Post("/worker/pause", s.handleAdminWorkerPause)
+		r.Post("/worker/resume", s.handleAdminWorkerResume)
+		r.Post("/apps/{id}/release", s.handleAdminReleaseApp)
+		r.Post("/apps/{id}/verify", s.handleAdminVerifyApp)
+		r.Post("/apps/{id}/move", s.handleAdminMoveApp)
+	})
+
+	// Maintainer dashboard (signed in with GitHub, requires github.oauth)
+	r.Get("/maintainer/login", s.handleMaintainerLogin)
+	r.Get("/maintainer/callback", s.handleMaintainerCallback)
+	r.Post("/maintainer/logout", s.handleMaintainerLogout)
+	r.Route("/maintainer", func(r chi.Router) {
+		r.Use(s.requireMaintainerSession)
+		r.Get("/", s.handleMaintainerPage)
+		r.Post("/claim", s.handleMaintainerClaim)
+		r.Post("/notifications", s.handleMaintainerNotifications)
+		r.Post("/apps/{id}/verify", s.handleMaintainerVerify)
+		r.Post("/apps/{id}/unclaim", s.handleMaintainerUnclaim)
+	})
+
+	r.Get("/healthz/ready", s.handleReady)
+}
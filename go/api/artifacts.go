@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// handleGetCompose handles GET /api/apps/{id}/compose and returns the full compose file of an app,
+// loading it from object storage if it was offloaded.
+func (s *Server) handleGetCompose(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	if !app.ComposeYAML.Valid {
+		http.Error(w, "Compose file not fetched", http.StatusNotFound)
+		return
+	}
+
+	s.writeArtifact(w, r, "application/yaml; charset=utf-8", app.ComposeYAML.String, app.ComposeYAMLRef)
+}
+
+// handleGetManifest handles GET /api/apps/{id}/manifest and returns the raw manifest of an app,
+// of which cards show only the start if it is large.
+func (s *Server) handleGetManifest(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	if !app.RoflYAML.Valid {
+		http.Error(w, "Manifest not fetched", http.StatusNotFound)
+		return
+	}
+
+	s.writeArtifact(w, r, "application/yaml; charset=utf-8", app.RoflYAML.String, sql.NullString{})
+}
+
+// handleGetDeploymentLog handles GET /api/apps/{id}/deployments/{name}/log and returns the build
+// output of the last verification of a deployment.
+func (s *Server) handleGetDeploymentLog(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.getApp(r.Context(), id); err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	buildLog, ref, err := s.db.GetDeploymentLog(r.Context(), id, chi.URLParam(r, "name"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !buildLog.Valid && !ref.Valid) {
+		http.Error(w, "Build log not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to get build log", "app_id", id, "error", err)
+		http.Error(w, "Failed to get build log", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeArtifact(w, r, "text/plain; charset=utf-8", buildLog.String, ref)
+}
+
+// artifactWriteTimeout bounds sending an artifact, so that slow clients cannot hold connections.
+const artifactWriteTimeout = 5 * time.Minute
+
+// writeArtifact writes an artifact stored in the database, or its full version if it was offloaded.
+// If object storage is unavailable, the excerpt kept in the database is written instead.
+func (s *Server) writeArtifact(w http.ResponseWriter, r *http.Request, contentType, inline string, ref sql.NullString) {
+	content := []byte(inline)
+	if ref.Valid && ref.String != "" {
+		// Downloads are not limited by the request timeout, but loading from object storage is.
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.cfg.Server.RequestTimeout)*time.Second)
+		data, err := s.artifacts.Load(ctx, ref.String)
+		cancel()
+		if err != nil {
+			s.logger.Warn("failed to load artifact, serving excerpt", "key", ref.String, "error", err)
+			w.Header().Set("X-Artifact-Truncated", "true")
+		} else {
+			content = data
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(artifactWriteTimeout))
+	_, _ = w.Write(content)
+}
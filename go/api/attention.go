@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// Reasons an app needs the attention of the maintainers of the registry.
+const (
+	attentionManifestMissing = "manifest_missing" // No manifest could be fetched yet.
+	attentionManifestInvalid = "manifest_invalid" // The fetched manifest does not parse.
+	attentionNeverVerified   = "never_verified"   // No deployment was ever verified.
+	attentionFailing         = "failing"          // A deployment failed its last verification.
+	attentionStale           = "stale"            // A deployment was not re-verified within the max age.
+	attentionErroring        = "erroring"         // Verification attempts keep erroring before reaching the backend.
+	attentionMoved           = "moved"            // The repository was renamed or transferred and the move awaits confirmation.
+	attentionUnmaintained    = "unmaintained"     // The repository was archived or deleted.
+)
+
+// AttentionReason is why an app is listed by the attention report.
+type AttentionReason struct {
+	Code         string     `json:"code"`
+	Deployment   string     `json:"deployment,omitempty"`
+	Detail       string     `json:"detail,omitempty"`
+	LastVerified *time.Time `json:"last_verified,omitempty"` // Last verification of the deployment.
+}
+
+// AttentionApp is an app listed by the attention report.
+type AttentionApp struct {
+	ID        int64             `json:"id"`
+	Slug      string            `json:"slug,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	GitHubURL string            `json:"github_url"`
+	GitRef    string            `json:"git_ref"`
+	ListedAt  time.Time         `json:"listed_at"`
+	URL       string            `json:"url"`
+	Reasons   []AttentionReason `json:"reasons"`
+}
+
+// attentionReasons returns why an app needs attention, or nil if it does not.
+func attentionReasons(app *models.App, deployments []*models.Deployment) []AttentionReason {
+	var reasons []AttentionReason
+	if app.ConsecutiveFailures > 0 {
+		reasons = append(reasons, AttentionReason{
+			Code:   attentionErroring,
+			Detail: fmt.Sprintf("%d failed attempts in a row: %s", app.ConsecutiveFailures, app.LastError.String),
+		})
+	}
+	if app.RepoStatus.Valid {
+		reasons = append(reasons, AttentionReason{Code: attentionUnmaintained, Detail: "repository " + app.RepoStatus.String})
+	}
+	if app.MovedTo.Valid {
+		reasons = append(reasons, AttentionReason{Code: attentionMoved, Detail: "now at " + app.MovedTo.String})
+	}
+	switch {
+	case app.RoflYAML.String == "":
+		reasons = append(reasons, AttentionReason{Code: attentionManifestMissing})
+	default:
+		if _, err := rofl.Parse([]byte(app.RoflYAML.String)); err != nil {
+			reasons = append(reasons, AttentionReason{Code: attentionManifestInvalid, Detail: err.Error()})
+		}
+	}
+
+	verified := false
+	for _, dep := range deployments {
+		if dep.FirstVerifiedAt.Valid {
+			verified = true
+		}
+		reason := AttentionReason{Deployment: dep.DeploymentName}
+		switch dep.Status {
+		case models.StatusFailed:
+			reason.Code = attentionFailing
+			reason.Detail = dep.VerificationMsg.String
+		case models.StatusStale:
+			reason.Code = attentionStale
+		default:
+			continue
+		}
+		if dep.LastVerified.Valid {
+			reason.LastVerified = &dep.LastVerified.Time
+		}
+		reasons = append(reasons, reason)
+	}
+	if !verified {
+		reasons = append(reasons, AttentionReason{Code: attentionNeverVerified})
+	}
+	return reasons
+}
+
+// loadAttentionReport lists the apps of a namespace that have never verified, are failing, or
+// have stale or missing manifests, in registry order.
+func (s *Server) loadAttentionReport(ctx context.Context, base string) ([]AttentionApp, error) {
+	apps, err := s.db.ListApps(ctx, namespaceFrom(ctx), db.AppOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AttentionApp, 0)
+	for _, app := range apps {
+		deps, err := s.db.GetDeploymentsByAppID(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		reasons := attentionReasons(app, deps)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		entry := AttentionApp{
+			ID:        app.ID,
+			Slug:      app.Slug.String,
+			GitHubURL: app.GitHubURL,
+			GitRef:    app.GitRef,
+			ListedAt:  app.CreatedAt,
+			URL:       base + appPath(app.ID, app.Slug.String),
+			Reasons:   reasons,
+		}
+		if manifest, err := rofl.Parse([]byte(app.RoflYAML.String)); err == nil {
+			entry.Name = manifest.Name
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// handleAttentionReport handles GET /api/v1/reports/attention, listing the apps that need the
+// attention of the maintainers of the registry with the reasons. The report is JSON, or CSV with
+// one row per reason if the format parameter is "csv".
+func (s *Server) handleAttentionReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	report, err := s.loadAttentionReport(r.Context(), s.baseURL(r))
+	if err != nil {
+		s.logger.Error("failed to load attention report", "error", err)
+		http.Error(w, "Failed to load report", http.StatusInternalServerError)
+		return
+	}
+
+	if format != "csv" {
+		writeJSON(w, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="attention.csv"`)
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"app_id", "slug", "name", "github_url", "git_ref", "reason", "deployment", "detail", "last_verified", "url"})
+	for _, app := range report {
+		for _, reason := range app.Reasons {
+			var lastVerified string
+			if reason.LastVerified != nil {
+				lastVerified = reason.LastVerified.UTC().Format(time.RFC3339)
+			}
+			_ = cw.Write([]string{
+				strconv.FormatInt(app.ID, 10), app.Slug, app.Name, app.GitHubURL, app.GitRef,
+				reason.Code, reason.Deployment, strings.ReplaceAll(reason.Detail, "\n", " "), lastVerified, app.URL,
+			})
+		}
+	}
+	cw.Flush()
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/cors"
+)
+
+// corsAllowedHeaders are the request headers cross-origin clients of the API may send, besides the
+// CORS-safelisted ones: event stream clients resume with Last-Event-ID.
+var corsAllowedHeaders = []string{"Accept", "Accept-Language", "Last-Event-ID"}
+
+// cors opens the routes under the configured paths to reads by the configured origins, answering
+// their preflight requests and plain OPTIONS requests. Other routes stay same-origin: the
+// middleware runs after resolveNamespace, so paths are matched without namespace prefixes.
+func (s *Server) cors(next http.Handler) http.Handler {
+	cfg := &s.cfg.Server.CORS
+	if len(cfg.AllowedOrigins) == 0 {
+		s.logger.Info("CORS not configured - same-origin requests only")
+		return next
+	}
+	s.logger.Info("enabling CORS", "allowed_origins", cfg.AllowedOrigins, "paths", cfg.Paths)
+
+	// The API is read-only and public, so cross-origin requests carry no credentials.
+	policy := cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   []string{http.MethodGet},
+		AllowedHeaders:   corsAllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: false,
+		MaxAge:           max(cfg.MaxAge, 0),
+	})
+	api := policy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", "GET, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Covers(r.URL.Path) {
+			api.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Test that the JSON API answers cross-origin reads and preflight requests of allowed origins, while
+// admin routes and requests changing state stay same-origin.
+func TestCORS(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.CORS = config.CORSConfig{
+		AllowedOrigins: []string{"https://dashboard.example.com"},
+		Paths:          []string{"/api/apps", "/api/v1/"},
+		MaxAge:         600,
+		ExposedHeaders: []string{"X-Cache"},
+	}
+	s := &Server{cfg: cfg, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	handler := s.cors(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		method, path, origin, requestMethod string
+		status                              int
+		allowOrigin, maxAge, allow          string
+	}{
+		// Reads of the API by allowed origins.
+		{method: "GET", path: "/api/v1/stats", origin: "https://dashboard.example.com", status: 200, allowOrigin: "https://dashboard.example.com"},
+		{method: "GET", path: "/api/apps/1/manifest", origin: "https://dashboard.example.com", status: 200, allowOrigin: "https://dashboard.example.com"},
+		{method: "OPTIONS", path: "/api/v1/stats", origin: "https://dashboard.example.com", requestMethod: "GET", status: 200, allowOrigin: "https://dashboard.example.com", maxAge: "600"},
+		{method: "OPTIONS", path: "/api/v1/stats", status: 204, allow: "GET, OPTIONS"},
+		// Other origins, methods, and routes.
+		{method: "GET", path: "/api/v1/stats", origin: "https://other.example.com", status: 200},
+		{method: "OPTIONS", path: "/api/v1/stats", origin: "https://dashboard.example.com", requestMethod: "POST", status: 200},
+		{method: "POST", path: "/api/apps", origin: "https://dashboard.example.com", status: 200},
+		{method: "GET", path: "/api/appsx", origin: "https://dashboard.example.com", status: 200},
+		{method: "GET", path: "/api/admin/worker/status", origin: "https://dashboard.example.com", status: 200},
+		{method: "GET", path: "/", origin: "https://dashboard.example.com", status: 200},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if tc.requestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", tc.requestMethod)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		header := rec.Header()
+		if rec.Code != tc.status || header.Get("Access-Control-Allow-Origin") != tc.allowOrigin ||
+			header.Get("Access-Control-Max-Age") != tc.maxAge || header.Get("Allow") != tc.allow {
+			t.Errorf("%s %s from %q: unexpected response %d %v", tc.method, tc.path, tc.origin, rec.Code, header)
+		}
+		if tc.allowOrigin != "" && tc.method == "GET" && header.Get("Access-Control-Expose-Headers") != "X-Cache" {
+			t.Errorf("%s %s: unexpected exposed headers %q", tc.method, tc.path, header.Get("Access-Control-Expose-Headers"))
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// embedRefreshSeconds is how often an embedded widget reloads itself.
+const embedRefreshSeconds = 300
+
+// EmbedData holds the data for rendering the embeddable status widget of an app.
+type EmbedData struct {
+	Layout      *Layout
+	Language    string
+	Name        string
+	Status      string
+	Summary     string
+	LastChecked string
+	AppURL      string
+	LogoURL     string // Relative to the registry, empty if the app has no logo.
+	Refresh     int
+}
+
+var embedTemplate = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Name}} · {{.Layout.Title}}</title>
+<style>
+html, body { margin: 0; padding: 0; background: transparent; }
+body { font: 14px/1.4 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #0f172a; }
+a.widget { display: flex; align-items: center; gap: 10px; box-sizing: border-box; max-width: 360px; padding: 10px 12px; border: 1px solid #e2e8f0; border-radius: 8px; background: #fff; color: inherit; text-decoration: none; }
+a.widget:hover { border-color: #94a3b8; }
+img { width: 32px; height: 32px; border-radius: 4px; flex-shrink: 0; }
+.info { min-width: 0; flex: 1; }
+.name, .summary, .footer { display: block; }
+.name { font-weight: 600; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.summary, .footer { font-size: 12px; color: #475569; }
+.footer { color: #94a3b8; }
+.badge { flex-shrink: 0; padding: 2px 8px; border-radius: 9999px; font-size: 12px; font-weight: 600; }
+.verified { background: #dcfce7; color: #166534; }
+.failed { background: #fee2e2; color: #991b1b; }
+.stale { background: #ffedd5; color: #9a3412; }
+.pending { background: #f1f5f9; color: #475569; }
+</style>
+{{if eq .Layout.Theme "dark"}}<style>` + embedDarkCSS + `</style>
+{{else if eq .Layout.Theme "system"}}<style>@media (prefers-color-scheme: dark) { ` + embedDarkCSS + ` }</style>
+{{end}}
+</head>
+<body>
+<a class="widget" href="{{.AppURL}}" target="_blank" rel="noopener noreferrer">
+{{if .LogoURL}}<img src="{{.LogoURL}}" alt="">{{end}}
+<span class="info">
+<span class="name">{{.Name}}</span>
+<span class="summary">{{.Summary}}</span>
+<span class="footer">{{.LastChecked}} · {{.Layout.Title}}</span>
+</span>
+<span class="badge {{.Status}}">{{if eq .Status "verified"}}✓ {{t "status.verified"}}{{else if eq .Status "failed"}}✗ {{t "status.failed"}}{{else if eq .Status "stale"}}{{t "status.stale"}}{{else}}{{t "status.pending"}}{{end}}</span>
+</a>
+</body>
+</html>`
+
+// embedDarkCSS restyles the widget for the dark theme.
+const embedDarkCSS = `body { color: #f1f5f9; }
+a.widget { border-color: #334155; background: #1e293b; }
+a.widget:hover { border-color: #64748b; }
+.summary { color: #94a3b8; }
+.footer { color: #64748b; }`
+
+// handleEmbed handles GET /embed/{slug}, a self-contained widget showing the verification status
+// of an app, meant to be embedded in an iframe on other sites.
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	id, err := s.resolveAppRef(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	loc := localeFor(r)
+	data, err := s.loadAppCardData(r.Context(), loc, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	base := s.baseURL(r)
+	embed := EmbedData{
+		Layout:      s.layout,
+		Language:    loc.Language,
+		Name:        data.Name,
+		Status:      data.Status,
+		Summary:     statusSummary(loc, data),
+		LastChecked: loc.T("status.not_yet_verified"),
+		AppURL:      base + appPath(data.ID, data.Slug),
+		Refresh:     embedRefreshSeconds,
+	}
+	if dep := primaryDeployment(data); dep != nil && !dep.LastVerified.Time.IsZero() {
+		embed.LastChecked = loc.T("embed.checked", dep.LastVerified.Relative)
+	}
+	if data.HasLogo {
+		embed.LogoURL = fmt.Sprintf("/api/apps/%d/logo.png", data.ID)
+	}
+
+	var buf bytes.Buffer
+	if err := s.embedTemplate.For(loc).Execute(&buf, embed); err != nil {
+		s.logger.Error("failed to render embed", "app_id", id, "error", err)
+		http.Error(w, "Failed to render embed", http.StatusInternalServerError)
+		return
+	}
+
+	// The widget is meant to be framed by any site, so it must not load anything but its logo.
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src 'self'; frame-ancestors *")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+const (
+	// maxStatusEvents limits the number of events returned by GET /api/v1/events.
+	maxStatusEvents = 1000
+	// feedItems is the number of latest status changes listed in RSS feeds.
+	feedItems = 50
+	// streamPollInterval is how often event streams check for new status changes.
+	streamPollInterval = 2 * time.Second
+	// streamKeepAlive is how often an idle event stream sends a comment, so proxies keep it open.
+	streamKeepAlive = 30 * time.Second
+)
+
+// StatusEvents is the response of GET /api/v1/events.
+type StatusEvents struct {
+	Events []*models.StatusEvent `json:"events"`
+}
+
+// handleStatusEvents handles GET /api/v1/events, returning up to limit status changes after the
+// given event ID, oldest first, optionally of a single app.
+func (s *Server) handleStatusEvents(w http.ResponseWriter, r *http.Request) {
+	after, ok := queryInt(r, "after", 0)
+	if !ok {
+		http.Error(w, "Invalid after", http.StatusBadRequest)
+		return
+	}
+	appID, ok := queryInt(r, "app_id", 0)
+	if !ok {
+		http.Error(w, "Invalid app_id", http.StatusBadRequest)
+		return
+	}
+	limit, ok := queryInt(r, "limit", 100)
+	if !ok || limit == 0 {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.db.GetStatusEventsAfter(r.Context(), namespaceFrom(r.Context()), appID, after, min(limit, maxStatusEvents))
+	if err != nil {
+		s.logger.Error("failed to get status events", "error", err)
+		http.Error(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []*models.StatusEvent{}
+	}
+
+	writeJSON(w, StatusEvents{Events: events})
+}
+
+// handleStatusEventStream handles GET /api/v1/events/stream, streaming status changes as
+// server-sent events until the client disconnects or the server shuts down. Clients resume after
+// the last received event with the Last-Event-ID header; new clients only receive new events.
+func (s *Server) handleStatusEventStream(shutdown <-chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+		appID, ok := queryInt(r, "app_id", 0)
+		if !ok {
+			http.Error(w, "Invalid app_id", http.StatusBadRequest)
+			return
+		}
+
+		var after int64
+		if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+			id, err := strconv.ParseInt(lastID, 10, 64)
+			if err != nil || id < 0 {
+				http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			after = id
+		} else {
+			id, err := s.db.GetLastStatusEventID(ctx)
+			if err != nil {
+				s.logger.Error("failed to get last status event", "error", err)
+				http.Error(w, "Failed to load events", http.StatusInternalServerError)
+				return
+			}
+			after = id
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		poll := time.NewTicker(streamPollInterval)
+		defer poll.Stop()
+		lastWrite := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-shutdown:
+				return
+			case <-poll.C:
+			}
+
+			events, err := s.db.GetStatusEventsAfter(ctx, namespaceFrom(ctx), appID, after, maxStatusEvents)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("failed to get status events", "error", err)
+				}
+				continue
+			}
+			for _, event := range events {
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: status\ndata: %s\n\n", event.ID, data); err != nil {
+					return
+				}
+				after = event.ID
+			}
+
+			switch {
+			case len(events) > 0:
+			case time.Since(lastWrite) >= streamKeepAlive:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			default:
+				continue
+			}
+			flusher.Flush()
+			lastWrite = time.Now()
+		}
+	}
+}
+
+// rssFeed is the root element of an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel describes an RSS feed and lists its items.
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+// rssItem is a single status change in an RSS feed.
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+// rssGUID uniquely identifies an item of an RSS feed.
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// handleFeed handles GET /feed.xml, an RSS feed of the latest status changes of all apps.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	base := s.baseURL(r)
+	s.writeFeed(w, r, 0, rssChannel{
+		Title:       s.layout.Title,
+		Link:        base + "/",
+		Description: "Verification status changes of ROFL apps",
+	})
+}
+
+// handleAppFeed handles GET /apps/{ref}/feed.xml, an RSS feed of the status changes of an app.
+func (s *Server) handleAppFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := s.resolveAppRef(r.Context(), chi.URLParam(r, "ref"))
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	name := repoName(app.GitHubURL)
+	s.writeFeed(w, r, id, rssChannel{
+		Title:       name + " · " + s.layout.Title,
+		Link:        s.baseURL(r) + appPath(app.ID, app.Slug.String),
+		Description: "Verification status changes of " + name,
+	})
+}
+
+// writeFeed renders the latest status changes, of a single app if appID is non-zero, as an RSS feed.
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, appID int64, channel rssChannel) {
+	events, err := s.db.GetLatestStatusEvents(r.Context(), namespaceFrom(r.Context()), appID, feedItems)
+	if err != nil {
+		s.logger.Error("failed to get status events", "error", err)
+		http.Error(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.baseURL(r)
+	for _, event := range events {
+		title := fmt.Sprintf("%s %s: %s", repoName(event.GitHubURL), event.Deployment, event.NewStatus)
+		if event.OldStatus != "" {
+			title = fmt.Sprintf("%s %s: %s → %s", repoName(event.GitHubURL), event.Deployment, event.OldStatus, event.NewStatus)
+		}
+		link := base + appPath(event.AppID, event.Slug)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       title,
+			Link:        link,
+			Description: event.Details,
+			GUID:        rssGUID{Value: fmt.Sprintf("%s#event-%d", link, event.ID)},
+			PubDate:     event.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	out, err := xml.MarshalIndent(rssFeed{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		s.logger.Error("failed to render feed", "error", err)
+		http.Error(w, "Failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(out)
+}
+
+// repoName returns the owner/repository part of a GitHub URL.
+func repoName(githubURL string) string {
+	return strings.TrimPrefix(githubURL, "https://github.com/")
+}
@@ -5,38 +5,152 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"html/template"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/worker"
 )
 
 //go:embed index.html
-var indexHTML []byte
+var indexTemplate string
+
+// IndexData holds the data for rendering the main HTML page.
+type IndexData struct {
+	Language string
+	Layout   *Layout
+	Apps     *AppList // Initial app cards, nil if the page loads them itself.
+}
+
+// renderIndex renders the main HTML page in the language of the request, with the cards of all
+// apps in the requested order so that they show without waiting for scripts. Anonymous requests
+// are served from the page cache.
+func (s *Server) renderIndex(r *http.Request) ([]byte, string, error) {
+	ctx := r.Context()
+	loc := localeFor(r)
+	order, err := s.appOrder(r)
+	if err != nil {
+		order = db.AppOrder(s.cfg.Apps.Ordering)
+	}
+
+	render := func(ctx context.Context) ([]byte, error) {
+		data := IndexData{Language: loc.Language, Layout: s.layout}
+		apps, err := s.buildAppList(ctx, loc, "", order)
+		if err != nil {
+			// The page still loads the cards once it is shown.
+			s.logger.Error("failed to render initial app list", "error", err)
+		} else {
+			data.Apps = apps
+		}
+
+		var buf bytes.Buffer
+		if err := s.indexTemplate.For(loc).Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render index page: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	if s.pageCache != nil && anonymous(r) {
+		key := "index\x00" + namespaceFrom(ctx) + "\x00" + loc.Key() + "\x00" + string(order)
+		return s.pageCache.get(ctx, key, render)
+	}
+	page, err := render(ctx)
+	return page, cacheMiss, err
+}
+
+// serveIndex serves the main HTML page, in the language of the request.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	page, result, err := s.renderIndex(r)
+	if err != nil {
+		s.logger.Error("failed to render index page", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
 
-// serveIndex serves the main HTML page.
-func (s *Server) serveIndex(w http.ResponseWriter, _ *http.Request) {
+	rememberLanguage(w, r)
 	w.Header().Set("Content-Type", "text/html")
-	_, _ = w.Write(indexHTML)
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("X-Cache", result)
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+	_, _ = w.Write(page)
 }
 
-// handleGetApps returns all apps as HTML fragments.
+// listApps returns all apps, or the apps matching query if it is not empty, in the given order.
+func (s *Server) listApps(ctx context.Context, query string, order db.AppOrder) ([]*models.App, error) {
+	if query = strings.TrimSpace(query); query != "" {
+		return s.db.SearchApps(ctx, namespaceFrom(ctx), query, order)
+	}
+	return s.db.ListApps(ctx, namespaceFrom(ctx), order)
+}
+
+// handleGetApps returns all apps as HTML fragments, or the apps matching the q parameter,
+// in the order given by the sort parameter.
+// Anonymous requests for all apps are served from the page cache. Search results are rendered
+// for every request, since cycling through queries would evict the cached pages.
 func (s *Server) handleGetApps(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	order, err := s.appOrder(r)
+	if err != nil {
+		order = db.AppOrder(s.cfg.Apps.Ordering)
+	}
 
-	apps, err := s.db.GetAllApps(ctx)
+	loc := localeFor(r)
+	render := func(ctx context.Context) ([]byte, error) {
+		return s.renderAppList(ctx, loc, query, order)
+	}
+	var (
+		page   []byte
+		result = cacheMiss
+	)
+	if s.pageCache != nil && anonymous(r) && query == "" {
+		key := namespaceFrom(ctx) + "\x00" + loc.Key() + "\x00" + string(order)
+		page, result, err = s.pageCache.get(ctx, key, render)
+	} else {
+		page, err = render(ctx)
+	}
 	if err != nil {
+		s.logger.Error("failed to list apps", "query", query, "error", err)
 		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("X-Cache", result)
+	w.Header().Set("Vary", "Accept-Language")
+	_, _ = w.Write(page)
+}
+
+// AppList is the rendered cards of a list of apps, with statistics of their deployments.
+type AppList struct {
+	Cards       template.HTML
+	Total       int // Apps listed.
+	Verified    int // Apps with at least one verified deployment.
+	Deployments int
+}
+
+// buildAppList renders the cards of all apps, or of the apps matching query, in the given order and
+// locale.
+func (s *Server) buildAppList(ctx context.Context, loc *Locale, query string, order db.AppOrder) (*AppList, error) {
+	apps, err := s.listApps(ctx, query, order)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate HTML for each app with their deployments.
 	var buf bytes.Buffer
-	verified := 0
-	totalDeployments := 0
+	list := &AppList{Total: len(apps)}
 	for _, app := range apps {
 		// Get deployments for this app
 		deps, err := s.db.GetDeploymentsByAppID(ctx, app.ID)
@@ -45,7 +159,12 @@ func (s *Server) handleGetApps(w http.ResponseWriter, r *http.Request) {
 			deps = nil // Continue with empty deployments
 		}
 
-		html, err := s.renderAppCard(app, deps)
+		imageDigests, err := s.db.GetImageDigestsByAppID(ctx, app.ID)
+		if err != nil {
+			s.logger.Error("failed to get image digests", "app_id", app.ID, "error", err)
+		}
+
+		html, err := s.renderAppCard(loc, app, deps, imageDigests, s.appUptime(ctx, app.ID))
 		if err != nil {
 			s.logger.Error("failed to render app card", "app_id", app.ID, "error", err)
 			continue
@@ -54,31 +173,140 @@ func (s *Server) handleGetApps(w http.ResponseWriter, r *http.Request) {
 		buf.WriteString("\n")
 
 		// Count verified apps (at least one deployment verified)
-		hasVerified := false
 		for _, dep := range deps {
-			if dep.Status == "verified" {
-				hasVerified = true
+			if dep.Status == models.StatusVerified {
+				list.Verified++
+				break
 			}
 		}
-		if hasVerified {
-			verified++
-		}
 
-		totalDeployments += len(deps)
+		list.Deployments += len(deps)
+	}
+	list.Cards = template.HTML(buf.String()) //nolint:gosec // Rendered from escaped templates.
+
+	return list, nil
+}
+
+// renderAppList renders the cards of all apps, or of the apps matching query, in the given order
+// and locale, as a fragment replacing the app list of the page.
+func (s *Server) renderAppList(ctx context.Context, loc *Locale, query string, order db.AppOrder) ([]byte, error) {
+	list, err := s.buildAppList(ctx, loc, query, order)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update stats using hx-swap-oob to avoid accumulating scripts in DOM
-	statsHTML := fmt.Sprintf(`
+	var buf bytes.Buffer
+	buf.WriteString(string(list.Cards))
+
+	// Stats describe the whole registry, so search results leave them untouched.
+	if query != "" {
+		if list.Total == 0 {
+			buf.WriteString(`<div class="col-span-full text-center py-12 text-slate-500">No apps match your search.</div>`)
+		}
+	} else {
+		// Update stats using hx-swap-oob to avoid accumulating scripts in DOM
+		statsHTML := fmt.Sprintf(`
 <div id="total-apps" hx-swap-oob="true">%d</div>
 <div id="verified-apps" hx-swap-oob="true">%d</div>
 <div id="deployments" hx-swap-oob="true">%d</div>
-`, len(apps), verified, totalDeployments)
+`, list.Total, list.Verified, list.Deployments)
 
-	buf.WriteString(statsHTML)
+		buf.WriteString(statsHTML)
+	}
 
-	w.Header().Set("Content-Type", "text/html")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	_, _ = w.Write(buf.Bytes())
+	return buf.Bytes(), nil
+}
+
+// AppSummary is an app as listed by the JSON API.
+type AppSummary struct {
+	ID          int64          `json:"id"`
+	Slug        string         `json:"slug,omitempty"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Author      string         `json:"author,omitempty"`
+	Version     string         `json:"version,omitempty"`
+	GitHubURL   string         `json:"github_url"`
+	Featured    bool           `json:"featured,omitempty"`
+	License     string         `json:"license,omitempty"`
+	OSIApproved bool           `json:"osi_approved"` // The license is an OSI-approved SPDX expression.
+	Status      string         `json:"status"`
+	RepoStatus  string         `json:"repo_status,omitempty"` // "archived" or "deleted" if the repository is no longer maintained.
+	Uptime      *UptimeSummary `json:"uptime,omitempty"`      // Verification checks over the last days, omitted if they could not be loaded.
+	URL         string         `json:"url"`
+}
+
+// handleListApps handles GET /api/apps, listing all apps or the apps matching the q parameter,
+// in the order given by the sort parameter.
+func (s *Server) handleListApps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query().Get("q")
+	order, err := s.appOrder(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	apps, err := s.listApps(ctx, query, order)
+	if err != nil {
+		s.logger.Error("failed to list apps", "query", query, "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.baseURL(r)
+	result := make([]AppSummary, 0, len(apps))
+	for _, app := range apps {
+		deps, err := s.db.GetDeploymentsByAppID(ctx, app.ID)
+		if err != nil {
+			s.logger.Error("failed to get deployments", "app_id", app.ID, "error", err)
+		}
+		data, err := newAppCardData(defaultLocale, app, deps, nil)
+		if err != nil {
+			s.logger.Error("failed to load app", "app_id", app.ID, "error", err)
+			continue
+		}
+		result = append(result, newAppSummary(base, data, s.appUptime(ctx, app.ID)))
+	}
+
+	writeJSON(w, result)
+}
+
+// newAppSummary builds the JSON API representation of an app and its verification uptime.
+func newAppSummary(base string, data *AppCardData, uptime *models.Uptime) AppSummary {
+	return AppSummary{
+		ID:          data.ID,
+		Slug:        data.Slug,
+		Name:        data.Name,
+		Description: data.Description,
+		Author:      data.Author,
+		Version:     data.Version,
+		GitHubURL:   data.GitHubURL,
+		Featured:    data.Featured,
+		License:     data.License,
+		OSIApproved: data.LicenseOSI,
+		Status:      data.Status,
+		RepoStatus:  data.RepoStatus,
+		Uptime:      newUptimeSummary(uptime),
+		URL:         base + appPath(data.ID, data.Slug),
+	}
+}
+
+// handleGetAppBySlug handles GET /api/v1/apps/by-slug/{slug}.
+func (s *Server) handleGetAppBySlug(w http.ResponseWriter, r *http.Request) {
+	app, err := s.getAppBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := s.loadAppCardData(r.Context(), defaultLocale, app.ID)
+	if err != nil {
+		s.logger.Error("failed to load app", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to load app", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, newAppSummary(s.baseURL(r), data, s.appUptime(r.Context(), app.ID)))
 }
 
 // handleGetApp returns a single app's details.
@@ -92,7 +320,7 @@ func (s *Server) handleGetApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	app, err := s.db.GetAppByID(ctx, id)
+	app, err := s.getApp(ctx, id)
 	if err != nil {
 		http.Error(w, "App not found", http.StatusNotFound)
 		return
@@ -105,7 +333,12 @@ func (s *Server) handleGetApp(w http.ResponseWriter, r *http.Request) {
 		deps = nil // Continue with empty deployments
 	}
 
-	html, err := s.renderAppCard(app, deps)
+	imageDigests, err := s.db.GetImageDigestsByAppID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get image digests", "app_id", id, "error", err)
+	}
+
+	html, err := s.renderAppCard(localeFor(r), app, deps, imageDigests, s.appUptime(ctx, id))
 	if err != nil {
 		http.Error(w, "Failed to render app", http.StatusInternalServerError)
 		return
@@ -116,25 +349,94 @@ func (s *Server) handleGetApp(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(html))
 }
 
+// handleAppDescription handles GET /htmx/apps/{id}/description, the full description of an app
+// whose card shows only its start.
+func (s *Server) handleAppDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.loadAppCardData(r.Context(), localeFor(r), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html.EscapeString(data.Description)))
+}
+
+// handleDeploymentEnclaves handles GET /htmx/apps/{id}/deployments/{name}/enclaves, all enclave
+// identities of a deployment whose card shows only some. The view parameter selects the markup
+// of the card ("card") or of its details ("detail").
+func (s *Server) handleDeploymentEnclaves(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	name := "detail-enclave-ids"
+	if r.URL.Query().Get("view") == "card" {
+		name = "card-enclave-ids"
+	}
+
+	loc := localeFor(r)
+	data, err := s.loadAppCardData(r.Context(), loc, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	deployments := data.OtherDeployments
+	if data.MainnetDeployment != nil {
+		deployments = append([]DeploymentStatus{*data.MainnetDeployment}, deployments...)
+	}
+	for _, dep := range deployments {
+		if dep.Name != chi.URLParam(r, "name") {
+			continue
+		}
+
+		body, err := render(s.cardTemplate, loc, name, dep)
+		if err != nil {
+			s.logger.Error("failed to render enclave identities", "app_id", id, "error", err)
+			http.Error(w, "Failed to render enclave identities", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(body)
+		return
+	}
+	http.Error(w, "Deployment not found", http.StatusNotFound)
+}
+
 // VerifyRequest represents the verification request payload.
 type VerifyRequest struct {
 	GitHubURL      string `json:"github_url"`
 	GitRef         string `json:"git_ref"`
+	CommitSHA      string `json:"commit_sha,omitempty"` // Full commit SHA to verify instead of git_ref, for auditing past releases.
 	DeploymentName string `json:"deployment_name"`
 }
 
+// maxVerifyRequestSize is the largest accepted verification request body.
+const maxVerifyRequestSize = 4 << 10
+
 // VerifyResponse represents the verification response.
 type VerifyResponse struct {
 	TaskID string `json:"task_id"`
 }
 
+// errBackendBusy is returned when no slot of the backend budget frees up in time for a verification.
+var errBackendBusy = errors.New("verification backend is busy")
+
 // handleVerify handles POST /api/verify - submits job to backend and returns task info.
 func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req VerifyRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !readJSON(w, r, &req, maxVerifyRequestSize) {
 		return
 	}
 
@@ -144,9 +446,23 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.GitRef == "" {
+	switch {
+	case req.CommitSHA != "" && req.GitRef != "":
+		http.Error(w, "git_ref and commit_sha are mutually exclusive", http.StatusBadRequest)
+		return
+	case req.CommitSHA != "":
+		if !github.IsCommitSHA(req.CommitSHA) {
+			http.Error(w, "commit_sha must be a full 40-character commit SHA", http.StatusBadRequest)
+			return
+		}
+		req.GitRef = strings.ToLower(req.CommitSHA)
+	case req.GitRef == "":
 		req.GitRef = "main"
 	}
+	if err := github.ValidateRef(req.GitRef); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Get backend URL from config
 	backendURL := s.cfg.Worker.BackendURL
@@ -155,8 +471,51 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Submit to backend
-	taskID, err := s.submitToBackend(ctx, backendURL, req.GitHubURL, req.GitRef, req.DeploymentName)
+	// Wait for a slot of the backend budget for as long as the request may take, then hold it until
+	// the task finishes, as observed by polling its results. The submission is not canceled with the
+	// request: a client that disconnected or timed out retries with the same idempotency key, which
+	// must then find the task if the backend accepted it, rather than start a second build.
+	budget := s.worker.Budget()
+	submit := func() (string, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Duration(s.cfg.Server.VerifyTimeout)*time.Second)
+		defer cancel()
+		if err := budget.Acquire(ctx, worker.TaskInteractive); err != nil {
+			return "", errBackendBusy
+		}
+		taskID, err := s.submitToBackend(ctx, backendURL, req.GitHubURL, req.GitRef, req.DeploymentName)
+		if err != nil {
+			budget.Release()
+			return "", err
+		}
+		budget.Hold(taskID, time.Duration(s.cfg.Worker.PollTimeout)*time.Minute)
+		return taskID, nil
+	}
+
+	// Submit to backend, unless a request with the same idempotency key was already submitted.
+	var (
+		taskID   string
+		replayed bool
+		err      error
+	)
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+			return
+		}
+		payload, _ := json.Marshal(req)
+		taskID, replayed, err = s.idempotency.do(ctx, key, payload, submit)
+		if errors.Is(err, errIdempotencyKeyReused) {
+			http.Error(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+			return
+		}
+	} else {
+		taskID, err = submit()
+	}
+	if errors.Is(err, errBackendBusy) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Verification backend is busy, try again later", http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		s.logger.Error("failed to submit verification", "error", err)
 		http.Error(w, fmt.Sprintf("Failed to submit verification: %v", err), http.StatusInternalServerError)
@@ -165,6 +524,9 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
 
 	// Return task ID
 	w.Header().Set("Content-Type", "application/json")
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 	_ = json.NewEncoder(w).Encode(VerifyResponse{
 		TaskID: taskID,
 	})
@@ -200,8 +562,7 @@ func (s *Server) submitToBackend(ctx context.Context, backendURL, repoURL, ref,
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.backend.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -257,8 +618,7 @@ func (s *Server) handleVerifyResults(w http.ResponseWriter, r *http.Request) {
 		proxyReq.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(proxyReq)
+	resp, err := s.backend.Do(proxyReq)
 	if err != nil {
 		s.logger.Error("failed to poll backend", "error", err)
 		http.Error(w, "Failed to contact backend", http.StatusBadGateway)
@@ -266,6 +626,11 @@ func (s *Server) handleVerifyResults(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 
+	// The task is finished once it has results or the backend no longer knows it.
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+		s.worker.Budget().Done(taskID)
+	}
+
 	// Copy response status and body
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(resp.StatusCode)
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// AppStatusAsOf is the verification state of an app at a point in time, reconstructed from the
+// verification log and the stored manifest versions.
+type AppStatusAsOf struct {
+	ID          int64               `json:"id"`
+	GitHubURL   string              `json:"github_url"`
+	AsOf        time.Time           `json:"as_of"`
+	Status      string              `json:"status"`
+	Deployments []DeploymentStateAt `json:"deployments"`
+}
+
+// DeploymentStateAt is the state of a deployment as last recorded in the verification log before
+// a point in time.
+type DeploymentStateAt struct {
+	Name           string          `json:"name"`
+	Network        string          `json:"network,omitempty"`
+	AppID          string          `json:"app_id,omitempty"`
+	Status         string          `json:"status"`
+	CommitSHA      string          `json:"commit_sha,omitempty"`
+	ManifestSHA256 string          `json:"manifest_sha256,omitempty"`
+	Since          time.Time       `json:"since"`     // When the log recorded this state.
+	LogIndex       int64           `json:"log_index"` // Provable with /api/v1/log/proof/{index}.
+	Enclaves       []ReportEnclave `json:"enclaves"`  // Empty if the manifest version is unknown.
+}
+
+// handleGetAppAsOf handles GET /api/v1/apps/{id}?as_of=RFC3339, returning the status and enclave
+// identities of an app's deployments at that time, or the latest logged state without as_of.
+func (s *Server) handleGetAppAsOf(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	asOf := time.Now()
+	if param := r.URL.Query().Get("as_of"); param != "" {
+		if asOf, err = time.Parse(time.RFC3339, param); err != nil {
+			http.Error(w, "Invalid as_of, expected an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	app, err := s.getApp(ctx, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	if asOf.Before(app.CreatedAt) {
+		http.Error(w, "App was not registered at that time", http.StatusNotFound)
+		return
+	}
+
+	events, err := s.db.GetAppEventsAsOf(ctx, id, asOf)
+	if err != nil {
+		s.logger.Error("failed to get verification log entries", "app_id", id, "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+
+	result := AppStatusAsOf{
+		ID:          app.ID,
+		GitHubURL:   app.GitHubURL,
+		AsOf:        asOf.UTC(),
+		Deployments: []DeploymentStateAt{},
+	}
+	manifests := make(map[string]*rofl.Manifest)
+	for _, event := range events {
+		var entry struct {
+			Deployment     string `json:"deployment"`
+			Status         string `json:"status"`
+			CommitSHA      string `json:"commit_sha"`
+			ManifestSHA256 string `json:"manifest_sha256"`
+		}
+		if err := json.Unmarshal([]byte(event.Entry), &entry); err != nil {
+			s.logger.Warn("failed to decode verification log entry", "index", event.Index, "error", err)
+			continue
+		}
+
+		// Enclave identities come from the manifest the deployment was verified against.
+		manifest, ok := manifests[entry.ManifestSHA256]
+		if !ok && entry.ManifestSHA256 != "" {
+			version, err := s.db.GetManifestVersionByHash(ctx, id, entry.ManifestSHA256)
+			if err != nil {
+				s.logger.Error("failed to get manifest version", "app_id", id, "error", err)
+				http.Error(w, "Failed to load manifest", http.StatusInternalServerError)
+				return
+			}
+			if version != nil {
+				if manifest, err = rofl.Parse([]byte(version.Content)); err != nil {
+					manifest = nil
+				}
+			}
+			manifests[entry.ManifestSHA256] = manifest
+		}
+		if manifest == nil {
+			manifest = &rofl.Manifest{}
+		}
+
+		state := DeploymentStateAt{
+			Name:           entry.Deployment,
+			Status:         entry.Status,
+			CommitSHA:      entry.CommitSHA,
+			ManifestSHA256: entry.ManifestSHA256,
+			Since:          event.CreatedAt.UTC(),
+			LogIndex:       event.Index,
+			Enclaves:       reportEnclaves(manifest, entry.Deployment),
+		}
+		if deployment := manifest.Deployments[entry.Deployment]; deployment != nil {
+			state.Network = deployment.Network
+			state.AppID = deployment.AppID
+		}
+		result.Deployments = append(result.Deployments, state)
+	}
+	result.Status = statusAsOf(result.Deployments)
+
+	writeJSON(w, result)
+}
+
+// statusAsOf aggregates the status of deployments the way app cards do: mainnet if deployed,
+// otherwise verified if any deployment is, otherwise the first deployment's status.
+func statusAsOf(deployments []DeploymentStateAt) string {
+	if len(deployments) == 0 {
+		return string(models.StatusPending)
+	}
+	for _, d := range deployments {
+		if d.Name == "mainnet" {
+			return d.Status
+		}
+	}
+	for _, d := range deployments {
+		if d.Status == statusVerified {
+			return statusVerified
+		}
+	}
+	return deployments[0].Status
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyTTL is how long the result of a request with an idempotency key is kept.
+	idempotencyTTL = 24 * time.Hour
+	// maxIdempotencyKeys bounds the number of kept results; the oldest are evicted first.
+	maxIdempotencyKeys = 10000
+	// maxIdempotencyKeyLength is the maximum length of an Idempotency-Key header.
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyEntry is the result of a request with an idempotency key.
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte // Hash of the request payload the key was first used with.
+	done        chan struct{}     // Closed once the request completed.
+	taskID      string
+	createdAt   time.Time
+}
+
+// idempotencyCache remembers the task IDs of verification requests by idempotency key,
+// so that retried requests return the original task instead of submitting a new build.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+// newIdempotencyCache creates an empty idempotency cache.
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotencyEntry)}
+}
+
+// errIdempotencyKeyReused is returned when a key is reused with a different payload.
+var errIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// do returns the task ID stored for key, or calls submit and stores its result. Concurrent
+// requests with the same key wait for the first one. Failed submissions are not stored, so
+// they can be retried. The returned bool reports whether the result was replayed.
+func (c *idempotencyCache) do(ctx context.Context, key string, payload []byte, submit func() (string, error)) (string, bool, error) {
+	fingerprint := sha256.Sum256(payload)
+	for {
+		c.mu.Lock()
+		c.evictLocked(time.Now())
+		entry, ok := c.entries[key]
+		if !ok {
+			entry = &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{}), createdAt: time.Now()}
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			taskID, err := submit()
+
+			c.mu.Lock()
+			if err != nil {
+				delete(c.entries, key)
+			} else {
+				entry.taskID = taskID
+			}
+			close(entry.done)
+			c.mu.Unlock()
+			return taskID, false, err
+		}
+		c.mu.Unlock()
+
+		if entry.fingerprint != fingerprint {
+			return "", false, errIdempotencyKeyReused
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+
+		c.mu.Lock()
+		taskID := entry.taskID
+		c.mu.Unlock()
+		if taskID != "" {
+			return taskID, true, nil
+		}
+		// The first request failed; try again.
+	}
+}
+
+// evictLocked removes expired entries, and the oldest completed entries if the cache is full.
+func (c *idempotencyCache) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest *idempotencyEntry
+	for key, entry := range c.entries {
+		if entry.taskID == "" {
+			continue // In flight.
+		}
+		if now.Sub(entry.createdAt) > idempotencyTTL {
+			delete(c.entries, key)
+			continue
+		}
+		if oldest == nil || entry.createdAt.Before(oldest.createdAt) {
+			oldestKey, oldest = key, entry
+		}
+	}
+	if len(c.entries) >= maxIdempotencyKeys && oldest != nil {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test that a retried request returns the task of the first one, and a key reused with another
+// payload is rejected.
+func TestIdempotencyCacheReplay(t *testing.T) {
+	ctx := context.Background()
+	cache := newIdempotencyCache()
+	var submits int
+	submit := func() (string, error) {
+		submits++
+		return "task-" + strconv.Itoa(submits), nil
+	}
+
+	taskID, replayed, err := cache.do(ctx, "key", []byte("payload"), submit)
+	if err != nil || taskID != "task-1" || replayed {
+		t.Fatalf("First request: got %q, replayed=%v, err=%v", taskID, replayed, err)
+	}
+	taskID, replayed, err = cache.do(ctx, "key", []byte("payload"), submit)
+	if err != nil || taskID != "task-1" || !replayed {
+		t.Errorf("Retried request: got %q, replayed=%v, err=%v", taskID, replayed, err)
+	}
+	if _, _, err = cache.do(ctx, "key", []byte("other payload"), submit); !errors.Is(err, errIdempotencyKeyReused) {
+		t.Errorf("Reused key: expected errIdempotencyKeyReused, got %v", err)
+	}
+	if submits != 1 {
+		t.Errorf("Expected 1 submission, got %d", submits)
+	}
+}
+
+// Test that failed submissions are not stored, so that a retry submits again.
+func TestIdempotencyCacheFailure(t *testing.T) {
+	ctx := context.Background()
+	cache := newIdempotencyCache()
+
+	if _, _, err := cache.do(ctx, "key", nil, func() (string, error) { return "", errors.New("backend down") }); err == nil {
+		t.Fatalf("Expected the submission error")
+	}
+	taskID, replayed, err := cache.do(ctx, "key", nil, func() (string, error) { return "task-2", nil })
+	if err != nil || taskID != "task-2" || replayed {
+		t.Errorf("Retry after failure: got %q, replayed=%v, err=%v", taskID, replayed, err)
+	}
+}
+
+// Test that concurrent requests with the same key wait for the first one instead of submitting.
+func TestIdempotencyCacheConcurrent(t *testing.T) {
+	ctx := context.Background()
+	cache := newIdempotencyCache()
+	release := make(chan struct{})
+	var submits atomic.Int32
+	submit := func() (string, error) {
+		submits.Add(1)
+		<-release
+		return "task", nil
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	results := make(chan string, requests)
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			taskID, _, err := cache.do(ctx, "key", []byte("payload"), submit)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+			results <- taskID
+		}()
+	}
+
+	// A waiter whose request ends stops waiting.
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	for submits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, _, err := cache.do(waitCtx, "key", []byte("payload"), submit); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the waiter to time out, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for taskID := range results {
+		if taskID != "task" {
+			t.Errorf("Unexpected task %q", taskID)
+		}
+	}
+	if n := submits.Load(); n != 1 {
+		t.Errorf("Expected 1 submission, got %d", n)
+	}
+}
+
+// Test that expired results are evicted, and the oldest ones once the cache is full.
+func TestIdempotencyCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	cache := newIdempotencyCache()
+	submit := func() (string, error) { return "task", nil }
+
+	if _, _, err := cache.do(ctx, "expired", nil, submit); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	cache.entries["expired"].createdAt = time.Now().Add(-idempotencyTTL - time.Minute)
+	if _, _, err := cache.do(ctx, "oldest", nil, submit); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if _, ok := cache.entries["expired"]; ok {
+		t.Errorf("Expired result was not evicted")
+	}
+	cache.entries["oldest"].createdAt = time.Now().Add(-time.Hour)
+
+	// Fill the cache with newer results.
+	for i := 1; i < maxIdempotencyKeys; i++ {
+		cache.entries["key-"+strconv.Itoa(i)] = &idempotencyEntry{taskID: "task", done: make(chan struct{}), createdAt: time.Now()}
+	}
+	if _, _, err := cache.do(ctx, "newest", nil, submit); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if _, ok := cache.entries["oldest"]; ok {
+		t.Errorf("Oldest result was not evicted from the full cache")
+	}
+	if len(cache.entries) != maxIdempotencyKeys {
+		t.Errorf("Expected %d results, got %d", maxIdempotencyKeys, len(cache.entries))
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Layout is the branding shared by the pages of the registry, as configured by the operator.
+type Layout struct {
+	Title          string // Name of the registry.
+	Heading        string // Heading of the index page, empty for the default one.
+	LogoURL        string // Empty for the built-in icon.
+	PrimaryColor   string
+	PrimaryDark    string // Primary color darkened, for hover states.
+	SecondaryColor string
+	Theme          string // One of the config.Theme* values.
+	FooterLinks    []config.FooterLink
+}
+
+// newLayout builds the layout of the configured branding.
+func newLayout(cfg *config.BrandingConfig) *Layout {
+	return &Layout{
+		Title:          cfg.Title,
+		Heading:        cfg.Heading,
+		LogoURL:        cfg.LogoURL,
+		PrimaryColor:   cfg.PrimaryColor,
+		PrimaryDark:    darken(cfg.PrimaryColor, 0.8),
+		SecondaryColor: cfg.SecondaryColor,
+		Theme:          cfg.Theme,
+		FooterLinks:    cfg.FooterLinks,
+	}
+}
+
+// darken scales the channels of a hex color like "#2563eb" by a factor below 1.
+func darken(color string, factor float64) string {
+	rgb, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return color
+	}
+	scale := func(shift uint) uint64 {
+		return uint64(float64((rgb>>shift)&0xff) * factor)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", scale(16), scale(8), scale(0))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"html/template"
+	"text/template/parse"
+
+	"github.com/ptrus/rofl-attestations/i18n"
+)
+
+// localizedTemplate is a template parsed once per supported language, with functions translating
+// its text into that language:
+//
+//	{{t "key" args...}}  the message of a key, formatted with the arguments
+//	{{tparts "key"}}     the message split at its %s placeholders, to place markup in between
+//	{{network "name"}}   the display name of a network, or the name itself if it is not known
+//	{{asset "name"}}     the URL of a static asset
+//	{{part $app .}}      the RenderContext of a part of an app, see RenderContext
+type localizedTemplate map[string]*template.Template
+
+// parseLocalized parses a localized template. It panics if the template is malformed or uses a
+// literal message key that the source catalog does not define.
+func parseLocalized(name, text string) localizedTemplate {
+	localized := make(localizedTemplate)
+	for _, language := range i18n.Languages() {
+		catalog := i18n.Lookup(language)
+		localized[language] = template.Must(template.New(name).Funcs(template.FuncMap{
+			"asset":  assets.URL,
+			"part":   newRenderContext,
+			"t":      catalog.T,
+			"tparts": catalog.Parts,
+			"network": func(network string) string {
+				if network != "mainnet" && network != "testnet" {
+					return network
+				}
+				return catalog.T("network." + network)
+			},
+		}).Parse(text))
+	}
+
+	for _, tmpl := range localized[i18n.Default].Templates() {
+		if key := undefinedMessage(tmpl.Tree.Root); key != "" {
+			panic(fmt.Sprintf("template %s: undefined message %q", tmpl.Name(), key))
+		}
+	}
+	return localized
+}
+
+// For returns the template in the language of a locale.
+func (l localizedTemplate) For(loc *Locale) *template.Template {
+	return l[loc.Language]
+}
+
+// undefinedMessage returns the first literal message key used in a template tree that the source
+// catalog does not define, or "" if all are defined.
+func undefinedMessage(node parse.Node) string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return ""
+		}
+		for _, child := range n.Nodes {
+			if key := undefinedMessage(child); key != "" {
+				return key
+			}
+		}
+	case *parse.ActionNode:
+		return undefinedMessage(n.Pipe)
+	case *parse.TemplateNode:
+		return undefinedMessage(n.Pipe)
+	case *parse.IfNode:
+		return undefinedBranchMessage(&n.BranchNode)
+	case *parse.RangeNode:
+		return undefinedBranchMessage(&n.BranchNode)
+	case *parse.WithNode:
+		return undefinedBranchMessage(&n.BranchNode)
+	case *parse.PipeNode:
+		if n == nil {
+			return ""
+		}
+		for _, cmd := range n.Cmds {
+			if len(cmd.Args) > 1 {
+				if fn, ok := cmd.Args[0].(*parse.IdentifierNode); ok && (fn.Ident == "t" || fn.Ident == "tparts") {
+					if key, ok := cmd.Args[1].(*parse.StringNode); ok && !i18n.Has(key.Text) {
+						return key.Text
+					}
+				}
+			}
+			for _, arg := range cmd.Args {
+				if key := undefinedMessage(arg); key != "" {
+					return key
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// undefinedBranchMessage is undefinedMessage of an if, range, or with action.
+func undefinedBranchMessage(n *parse.BranchNode) string {
+	for _, node := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if key := undefinedMessage(node); key != "" {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/merkle"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// maxLogEntries limits the number of entries returned by GET /api/v1/log/entries.
+const maxLogEntries = 1000
+
+// LogRoot is the response of GET /api/v1/log, the current state of the verification log.
+type LogRoot struct {
+	Size       int64             `json:"size"`
+	RootHash   string            `json:"root_hash"`
+	Timestamp  time.Time         `json:"timestamp"`
+	LastAnchor *models.LogAnchor `json:"last_anchor,omitempty"` // Most recent root published on chain.
+}
+
+// LogEntries is the response of GET /api/v1/log/entries.
+type LogEntries struct {
+	Entries []*models.VerificationEvent `json:"entries"`
+}
+
+// InclusionProof is the response of GET /api/v1/log/proof/{index}.
+type InclusionProof struct {
+	Index     int64    `json:"index"`
+	Size      int64    `json:"size"`
+	LeafHash  string   `json:"leaf_hash"`
+	RootHash  string   `json:"root_hash"`
+	AuditPath []string `json:"audit_path"`
+}
+
+// queryInt parses an optional non-negative integer query parameter.
+func queryInt(r *http.Request, name string, def int64) (int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	return n, err == nil && n >= 0
+}
+
+// handleLogRoot handles GET /api/v1/log.
+func (s *Server) handleLogRoot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	size, root, err := s.db.GetEventLogRoot(ctx)
+	if err != nil {
+		s.logger.Error("failed to get verification log", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	anchor, err := s.db.GetLastLogAnchor(ctx)
+	if err != nil {
+		s.logger.Error("failed to get verification log anchor", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, LogRoot{
+		Size:       size,
+		RootHash:   root.String(),
+		Timestamp:  time.Now().UTC(),
+		LastAnchor: anchor,
+	})
+}
+
+// handleLogEntries handles GET /api/v1/log/entries, returning up to count entries from start.
+func (s *Server) handleLogEntries(w http.ResponseWriter, r *http.Request) {
+	start, ok := queryInt(r, "start", 0)
+	if !ok {
+		http.Error(w, "Invalid start", http.StatusBadRequest)
+		return
+	}
+	count, ok := queryInt(r, "count", 100)
+	if !ok || count == 0 {
+		http.Error(w, "Invalid count", http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.db.GetEvents(r.Context(), start, min(count, maxLogEntries))
+	if err != nil {
+		s.logger.Error("failed to get verification log entries", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []*models.VerificationEvent{}
+	}
+
+	writeJSON(w, LogEntries{Entries: events})
+}
+
+// handleLogProof handles GET /api/v1/log/proof/{index}, proving the inclusion of an entry in the
+// log of the given size, by default its current size.
+func (s *Server) handleLogProof(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	index, err := strconv.ParseInt(chi.URLParam(r, "index"), 10, 64)
+	if err != nil || index < 0 {
+		http.Error(w, "Invalid index", http.StatusBadRequest)
+		return
+	}
+	current, err := s.db.GetEventLogSize(ctx)
+	if err != nil {
+		s.logger.Error("failed to get verification log size", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	size, ok := queryInt(r, "size", current)
+	if !ok || size > current {
+		http.Error(w, "Invalid size", http.StatusBadRequest)
+		return
+	}
+	if index >= size {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	leaves, err := s.db.GetEventLeaves(ctx, size)
+	if err != nil {
+		s.logger.Error("failed to get verification log", "error", err)
+		http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+		return
+	}
+	path, err := merkle.InclusionProof(leaves, int(index))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	proof := InclusionProof{
+		Index:     index,
+		Size:      size,
+		LeafHash:  leaves[index].String(),
+		RootHash:  merkle.Root(leaves).String(),
+		AuditPath: make([]string, 0, len(path)),
+	}
+	for _, h := range path {
+		proof.AuditPath = append(proof.AuditPath, h.String())
+	}
+	writeJSON(w, proof)
+}
@@ -0,0 +1,655 @@
+package api
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/worker"
+)
+
+const (
+	maintainerSessionCookie = "maintainer_session"
+	maintainerStateCookie   = "maintainer_oauth_state"
+	maintainerSessionMaxAge = 7 * 24 * time.Hour
+	maintainerStateMaxAge   = 10 * time.Minute
+	maintainerHistoryLength = 10
+)
+
+// MaintainerDeployment is a deployment listed on the maintainer dashboard.
+type MaintainerDeployment struct {
+	Name         string
+	Status       string
+	Message      string
+	LastVerified Timestamp
+}
+
+// MaintainerApp is a claimed app listed on the maintainer dashboard.
+type MaintainerApp struct {
+	AdminAppRow
+	Deployments []MaintainerDeployment
+	History     []AdminEvent
+}
+
+// MaintainerPageData holds the data for rendering the maintainer dashboard.
+type MaintainerPageData struct {
+	Layout        *Layout
+	PathPrefix    string // Path prefix of the namespace the dashboard is served in.
+	Login         string
+	AvatarURL     string
+	Apps          []MaintainerApp
+	Notifications *models.NotificationPreferences
+	EmailEnabled  bool // Whether the registry can send email notifications.
+	Message       string
+}
+
+var maintainerPageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Maintainer · {{.Layout.Title}}</title>
+<script src="{{asset "tailwind.js"}}"></script>
+</head>
+<body class="bg-slate-50 text-slate-900">
+<div class="max-w-5xl mx-auto p-6 space-y-6">
+    <div class="flex justify-between items-center">
+        <h1 class="text-2xl font-bold">Your apps</h1>
+        <div class="flex items-center gap-3 text-sm">
+            {{if .AvatarURL}}<img src="{{.AvatarURL}}" alt="" class="w-8 h-8 rounded-full">{{end}}
+            <span class="font-semibold">{{.Login}}</span>
+            <form method="post" action="{{.PathPrefix}}/maintainer/logout"><button class="text-slate-600 hover:text-slate-900 underline">Sign out</button></form>
+        </div>
+    </div>
+    {{if .Message}}<div class="bg-blue-50 border border-blue-200 text-blue-800 rounded-md px-4 py-2 text-sm">{{.Message}}</div>{{end}}
+
+    <!-- Claim -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-1">Claim an app</h2>
+        <p class="text-sm text-slate-600 mb-3">Claiming requires admin permission on the app's repository.</p>
+        <form method="post" action="{{.PathPrefix}}/maintainer/claim" class="flex gap-2">
+            <input type="url" name="url" placeholder="https://github.com/owner/repo" required
+                   class="flex-1 px-3 py-2 border border-slate-300 rounded-md text-sm">
+            <button class="px-3 py-2 bg-slate-800 hover:bg-slate-700 text-white rounded-md text-sm font-semibold">Claim</button>
+        </form>
+    </section>
+
+    <!-- Notifications -->
+    <section class="bg-white border border-slate-200 rounded-lg p-4">
+        <h2 class="text-lg font-bold mb-1">Notifications</h2>
+        <p class="text-sm text-slate-600 mb-3">Get notified when a verification changes the status of a deployment of your apps. Leave a channel empty to disable it.</p>
+        <form method="post" action="{{.PathPrefix}}/maintainer/notifications" class="space-y-3 text-sm">
+            <label class="block">
+                <span class="text-slate-600">Email</span>
+                <input type="email" name="email" value="{{.Notifications.Email}}" {{if not .EmailEnabled}}disabled{{end}}
+                       class="w-full px-3 py-2 border border-slate-300 rounded-md">
+                {{if not .EmailEnabled}}<span class="text-xs text-slate-500">Email notifications are not enabled on this registry.</span>{{end}}
+            </label>
+            <label class="block">
+                <span class="text-slate-600">Webhook URL</span>
+                <input type="url" name="webhook_url" value="{{.Notifications.WebhookURL}}" placeholder="https://"
+                       class="w-full px-3 py-2 border border-slate-300 rounded-md">
+                <span class="text-xs text-slate-500">Receives a JSON POST for every notification.</span>
+            </label>
+            <label class="flex items-center gap-2"><input type="checkbox" name="only_failures" {{if .Notifications.OnlyFailures}}checked{{end}}> Only failures</label>
+            <label class="flex items-center gap-2"><input type="checkbox" name="only_mainnet" {{if .Notifications.OnlyMainnet}}checked{{end}}> Only mainnet deployments</label>
+            <button class="px-3 py-2 bg-slate-800 hover:bg-slate-700 text-white rounded-md font-semibold">Save</button>
+        </form>
+    </section>
+
+    {{range .Apps}}
+    <section class="bg-white border border-slate-200 rounded-lg p-4 space-y-3">
+        <div class="flex justify-between items-start">
+            <div>
+                <h2 class="text-lg font-bold"><a href="{{.GitHubURL}}" class="text-blue-600 hover:underline">{{.GitHubURL}}</a></h2>
+                <div class="text-sm text-slate-500">@{{.GitRef}}{{if .Failures}} · {{.Failures}} consecutive failures{{end}}{{if .NextRetry}} · retried {{.NextRetry}}{{end}}</div>
+                {{if .LastError}}<div class="text-xs text-red-700 break-all mt-1">{{.LastError}}</div>{{end}}
+            </div>
+            <div class="whitespace-nowrap">
+                <form method="post" action="{{$.PathPrefix}}/maintainer/apps/{{.ID}}/verify" class="inline"><button class="px-2 py-1 bg-slate-800 hover:bg-slate-700 text-white rounded text-xs font-semibold">Re-verify</button></form>
+                <form method="post" action="{{$.PathPrefix}}/maintainer/apps/{{.ID}}/unclaim" class="inline"><button class="px-2 py-1 bg-slate-100 hover:bg-slate-200 rounded text-xs font-semibold">Unclaim</button></form>
+            </div>
+        </div>
+        {{if .Deployments}}
+        <table class="w-full text-sm">
+            <thead><tr class="text-left text-slate-600"><th class="py-1">Deployment</th><th>Status</th><th>Last verified</th></tr></thead>
+            <tbody>
+            {{range .Deployments}}
+            <tr class="border-t border-slate-200 align-top">
+                <td class="py-1">{{.Name}}</td>
+                <td class="py-1">{{.Status}}{{if .Message}}<div class="text-xs text-slate-500 break-all">{{.Message}}</div>{{end}}</td>
+                <td class="py-1 text-xs">{{.LastVerified.HTML}}</td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{end}}
+        <h3 class="font-semibold text-sm">Verification history</h3>
+        {{if .History}}
+        <table class="w-full text-sm">
+            <tbody>
+            {{range .History}}
+            <tr class="border-t border-slate-200">
+                <td class="py-1 text-slate-500">#{{.Index}}</td>
+                <td class="py-1">{{.Deployment}}</td>
+                <td class="py-1">{{.Status}}</td>
+                <td class="py-1 font-mono text-xs">{{.CommitSHA}}</td>
+                <td class="py-1 text-xs">{{.Time.HTML}}</td>
+            </tr>
+            {{end}}
+            </tbody>
+        </table>
+        {{else}}<p class="text-sm text-slate-500">No verification log entries yet.</p>{{end}}
+    </section>
+    {{else}}
+    <p class="text-sm text-slate-500">You have not claimed any apps yet.</p>
+    {{end}}
+</div>
+</body>
+</html>
+`
+
+type maintainerKey struct{}
+
+// maintainerFrom returns the session stored by requireMaintainerSession.
+func maintainerFrom(ctx context.Context) *models.MaintainerSession {
+	session, _ := ctx.Value(maintainerKey{}).(*models.MaintainerSession)
+	return session
+}
+
+// hashSessionToken returns the hash a session is stored under, so that a leaked database does
+// not expose usable session cookies.
+func hashSessionToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// newAccessTokenCipher returns the cipher the GitHub access tokens of maintainer sessions are
+// stored with, keyed by the OAuth client secret, so that a leaked database does not expose them.
+func newAccessTokenCipher(clientSecret string) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, []byte(clientSecret), nil, "maintainer access tokens", 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive access token key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access token cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealAccessToken encrypts the access token of a session, bound to the hash the session is stored
+// under, so that it cannot be moved to another session.
+func (s *Server) sealAccessToken(tokenHash, accessToken string) ([]byte, error) {
+	nonce := make([]byte, s.tokenCipher.NonceSize(), s.tokenCipher.NonceSize()+len(accessToken)+s.tokenCipher.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.tokenCipher.Seal(nonce, nonce, []byte(accessToken), []byte(tokenHash)), nil
+}
+
+// openAccessToken decrypts the access token of a session sealed by sealAccessToken.
+func (s *Server) openAccessToken(tokenHash string, sealed []byte) (string, error) {
+	if len(sealed) < s.tokenCipher.NonceSize() {
+		return "", errors.New("sealed access token too short")
+	}
+	nonce, ciphertext := sealed[:s.tokenCipher.NonceSize()], sealed[s.tokenCipher.NonceSize():]
+	accessToken, err := s.tokenCipher.Open(nil, nonce, ciphertext, []byte(tokenHash))
+	if err != nil {
+		return "", err
+	}
+	return string(accessToken), nil
+}
+
+// randomToken returns a random hex encoded token.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setMaintainerCookie sets or, with an empty value, clears a maintainer cookie. Cookies are lax,
+// as GitHub redirects back to the callback from another site.
+func (s *Server) setMaintainerCookie(w http.ResponseWriter, r *http.Request, name, value string, maxAge time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     s.pathPrefix(r) + "/maintainer",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(maxAge.Seconds()),
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// requireMaintainerSession restricts the maintainer dashboard to signed in GitHub users.
+// The dashboard is disabled entirely when no OAuth app is configured.
+func (s *Server) requireMaintainerSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.oauth == nil {
+			http.Error(w, "Maintainer sign-in not configured", http.StatusNotFound)
+			return
+		}
+
+		cookie, err := r.Cookie(maintainerSessionCookie)
+		if err != nil {
+			http.Redirect(w, r, s.pathPrefix(r)+"/maintainer/login", http.StatusSeeOther)
+			return
+		}
+		tokenHash := hashSessionToken(cookie.Value)
+		session, err := s.db.GetMaintainerSession(r.Context(), tokenHash)
+		if err != nil {
+			s.logger.Error("failed to get maintainer session", "error", err)
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if session == nil {
+			http.Redirect(w, r, s.pathPrefix(r)+"/maintainer/login", http.StatusSeeOther)
+			return
+		}
+		// Sessions sealed with a former client secret cannot be used anymore.
+		if session.AccessToken, err = s.openAccessToken(tokenHash, session.SealedAccessToken); err != nil {
+			s.logger.Warn("failed to decrypt maintainer access token", "login", session.Login, "error", err)
+			http.Redirect(w, r, s.pathPrefix(r)+"/maintainer/login", http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), maintainerKey{}, session)))
+	})
+}
+
+// maintainerCallbackURL returns the URL GitHub redirects users back to after granting access.
+func (s *Server) maintainerCallbackURL(r *http.Request) string {
+	return s.baseURL(r) + "/maintainer/callback"
+}
+
+// handleMaintainerLogin handles GET /maintainer/login, sending the user to GitHub to sign in.
+func (s *Server) handleMaintainerLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oauth == nil {
+		http.Error(w, "Maintainer sign-in not configured", http.StatusNotFound)
+		return
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		s.logger.Error("failed to generate OAuth state", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	s.setMaintainerCookie(w, r, maintainerStateCookie, state, maintainerStateMaxAge)
+	http.Redirect(w, r, s.oauth.AuthorizeURL(s.maintainerCallbackURL(r), state), http.StatusSeeOther)
+}
+
+// handleMaintainerCallback handles GET /maintainer/callback, starting a session for the user
+// GitHub redirected back.
+func (s *Server) handleMaintainerCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oauth == nil {
+		http.Error(w, "Maintainer sign-in not configured", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	cookie, err := r.Cookie(maintainerStateCookie)
+	if err != nil || query.Get("state") == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+		http.Error(w, "Invalid OAuth state, please sign in again", http.StatusBadRequest)
+		return
+	}
+	s.setMaintainerCookie(w, r, maintainerStateCookie, "", 0)
+	if errCode := query.Get("error"); errCode != "" {
+		http.Error(w, "GitHub sign-in failed: "+errCode, http.StatusForbidden)
+		return
+	}
+
+	ctx := r.Context()
+	accessToken, err := s.oauth.Exchange(ctx, query.Get("code"), s.maintainerCallbackURL(r))
+	if err != nil {
+		s.logger.Warn("failed to exchange OAuth code", "error", err)
+		http.Error(w, "GitHub sign-in failed", http.StatusBadGateway)
+		return
+	}
+	user, err := s.oauth.User(ctx, accessToken)
+	if err != nil {
+		s.logger.Warn("failed to get GitHub user", "error", err)
+		http.Error(w, "GitHub sign-in failed", http.StatusBadGateway)
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		s.logger.Error("failed to generate session token", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	tokenHash := hashSessionToken(token)
+	sealed, err := s.sealAccessToken(tokenHash, accessToken)
+	if err != nil {
+		s.logger.Error("failed to encrypt access token", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	session := &models.MaintainerSession{
+		GitHubID:          user.ID,
+		Login:             user.Login,
+		AvatarURL:         user.AvatarURL,
+		SealedAccessToken: sealed,
+		ExpiresAt:         time.Now().Add(maintainerSessionMaxAge),
+	}
+	if err := s.db.CreateMaintainerSession(ctx, tokenHash, session); err != nil {
+		s.logger.Error("failed to create maintainer session", "error", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("maintainer signed in", "login", user.Login, "github_id", user.ID)
+	s.setMaintainerCookie(w, r, maintainerSessionCookie, token, maintainerSessionMaxAge)
+	http.Redirect(w, r, s.pathPrefix(r)+"/maintainer", http.StatusSeeOther)
+}
+
+// handleMaintainerLogout handles POST /maintainer/logout.
+func (s *Server) handleMaintainerLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(maintainerSessionCookie); err == nil {
+		if err := s.db.DeleteMaintainerSession(r.Context(), hashSessionToken(cookie.Value)); err != nil {
+			s.logger.Error("failed to delete maintainer session", "error", err)
+		}
+	}
+	s.setMaintainerCookie(w, r, maintainerSessionCookie, "", 0)
+	http.Redirect(w, r, s.pathPrefix(r)+"/", http.StatusSeeOther)
+}
+
+// handleMaintainerPage handles GET /maintainer, showing the claimed apps of the signed in user
+// with their deployments and verification history.
+func (s *Server) handleMaintainerPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session := maintainerFrom(ctx)
+	data := MaintainerPageData{
+		Layout:       s.layout,
+		PathPrefix:   s.pathPrefix(r),
+		Login:        session.Login,
+		AvatarURL:    session.AvatarURL,
+		EmailEnabled: s.cfg.Worker.Notifications.SMTP.Enabled(),
+		Message:      s.maintainerMessage(r),
+	}
+
+	notifications, err := s.db.GetNotificationPreferences(ctx, session.GitHubID)
+	if err != nil {
+		s.logger.Error("failed to get notification preferences", "github_id", session.GitHubID, "error", err)
+		http.Error(w, "Failed to load notification preferences", http.StatusInternalServerError)
+		return
+	}
+	data.Notifications = notifications
+
+	apps, err := s.db.GetMaintainedApps(ctx, session.GitHubID)
+	if err != nil {
+		s.logger.Error("failed to get maintained apps", "github_id", session.GitHubID, "error", err)
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+	rows := adminAppRows(apps)
+	loc := localeFor(r)
+	for i, app := range apps {
+		entry := MaintainerApp{AdminAppRow: rows[i]}
+
+		deployments, err := s.db.GetDeploymentsByAppID(ctx, app.ID)
+		if err != nil {
+			s.logger.Error("failed to get deployments", "app_id", app.ID, "error", err)
+			http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+			return
+		}
+		for _, d := range deployments {
+			deployment := MaintainerDeployment{
+				Name:    d.DeploymentName,
+				Status:  string(d.Status),
+				Message: d.VerificationMsg.String,
+			}
+			deployment.LastVerified = loc.NullTime(d.LastVerified)
+			entry.Deployments = append(entry.Deployments, deployment)
+		}
+
+		events, err := s.db.GetAppEvents(ctx, app.ID, maintainerHistoryLength)
+		if err != nil {
+			s.logger.Error("failed to get verification log entries", "app_id", app.ID, "error", err)
+			http.Error(w, "Failed to load verification log", http.StatusInternalServerError)
+			return
+		}
+		for _, e := range events {
+			event := AdminEvent{Index: e.Index, Time: loc.Time(e.CreatedAt)}
+			if err := json.Unmarshal([]byte(e.Entry), &event); err != nil {
+				s.logger.Warn("failed to decode verification log entry", "index", e.Index, "error", err)
+			}
+			event.CommitSHA = shortSHA(event.CommitSHA)
+			entry.History = append(entry.History, event)
+		}
+
+		data.Apps = append(data.Apps, entry)
+	}
+
+	s.renderAdmin(w, s.maintainerTemplate, data, http.StatusOK)
+}
+
+// handleMaintainerClaim handles POST /maintainer/claim, recording the signed in user as a
+// maintainer of an app if they have admin permission on its repository.
+func (s *Server) handleMaintainerClaim(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session := maintainerFrom(ctx)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	repoURL := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(r.PostFormValue("url")), "/"), ".git")
+	app, err := s.db.GetAppByURL(ctx, namespaceFrom(ctx), repoURL)
+	if err != nil {
+		s.redirectMaintainer(w, r, "not_found", 0)
+		return
+	}
+
+	if !s.checkRepoAdmin(w, r, session, app) {
+		return
+	}
+	if err := s.db.AddAppMaintainer(ctx, app.ID, session.GitHubID, session.Login); err != nil {
+		s.logger.Error("failed to add app maintainer", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to claim app", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("app claimed", "app_id", app.ID, "login", session.Login)
+	s.redirectMaintainer(w, r, "claimed", app.ID)
+}
+
+// handleMaintainerVerify handles POST /maintainer/apps/{id}/verify, verifying a claimed app
+// right away.
+func (s *Server) handleMaintainerVerify(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.maintainedApp(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkRepoAdmin(w, r, maintainerFrom(r.Context()), app) {
+		return
+	}
+
+	err := s.worker.VerifyNow(r.Context(), app)
+	switch {
+	case errors.Is(err, worker.ErrAppBusy):
+		s.redirectMaintainer(w, r, "busy", app.ID)
+		return
+	case err != nil:
+		s.logger.Error("failed to start verification", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("verification requested by maintainer", "app_id", app.ID, "login", maintainerFrom(r.Context()).Login)
+	s.redirectMaintainer(w, r, "verifying", app.ID)
+}
+
+// handleMaintainerUnclaim handles POST /maintainer/apps/{id}/unclaim.
+func (s *Server) handleMaintainerUnclaim(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.maintainedApp(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.RemoveAppMaintainer(r.Context(), app.ID, maintainerFrom(r.Context()).GitHubID); err != nil {
+		s.logger.Error("failed to remove app maintainer", "app_id", app.ID, "error", err)
+		http.Error(w, "Failed to unclaim app", http.StatusInternalServerError)
+		return
+	}
+	s.redirectMaintainer(w, r, "unclaimed", app.ID)
+}
+
+// handleMaintainerNotifications handles POST /maintainer/notifications, storing the notification
+// preferences of the signed in user.
+func (s *Server) handleMaintainerNotifications(w http.ResponseWriter, r *http.Request) {
+	session := maintainerFrom(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+	prefs := &models.NotificationPreferences{
+		GitHubID:     session.GitHubID,
+		Email:        strings.TrimSpace(r.PostFormValue("email")),
+		WebhookURL:   strings.TrimSpace(r.PostFormValue("webhook_url")),
+		OnlyFailures: r.PostFormValue("only_failures") != "",
+		OnlyMainnet:  r.PostFormValue("only_mainnet") != "",
+	}
+	if !s.cfg.Worker.Notifications.SMTP.Enabled() {
+		// Keep the stored address, as the disabled input is not submitted.
+		current, err := s.db.GetNotificationPreferences(r.Context(), session.GitHubID)
+		if err != nil {
+			s.logger.Error("failed to get notification preferences", "github_id", session.GitHubID, "error", err)
+			http.Error(w, "Failed to save notification preferences", http.StatusInternalServerError)
+			return
+		}
+		prefs.Email = current.Email
+	}
+	if prefs.Email != "" {
+		if addr, err := mail.ParseAddress(prefs.Email); err != nil || addr.Name != "" {
+			s.redirectMaintainer(w, r, "invalid_email", 0)
+			return
+		}
+	}
+	if prefs.WebhookURL != "" {
+		if err := httpclient.CheckURL(prefs.WebhookURL); err != nil {
+			s.redirectMaintainer(w, r, "invalid_webhook", 0)
+			return
+		}
+	}
+
+	if err := s.db.SetNotificationPreferences(r.Context(), prefs); err != nil {
+		s.logger.Error("failed to set notification preferences", "github_id", session.GitHubID, "error", err)
+		http.Error(w, "Failed to save notification preferences", http.StatusInternalServerError)
+		return
+	}
+	s.redirectMaintainer(w, r, "saved", 0)
+}
+
+// maintainedApp loads the app named in the URL, writing an error response if it does not exist
+// or was not claimed by the signed in user.
+func (s *Server) maintainedApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return nil, false
+	}
+	ok, err := s.db.IsAppMaintainer(r.Context(), id, maintainerFrom(r.Context()).GitHubID)
+	if err != nil {
+		s.logger.Error("failed to check app maintainer", "app_id", id, "error", err)
+		http.Error(w, "Failed to load app", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !ok {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return nil, false
+	}
+	app, err := s.db.GetAppByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return nil, false
+	}
+	return app, true
+}
+
+// checkRepoAdmin checks that the signed in user has admin permission on the repository of an
+// app, redirecting back with a message if not. Permissions are checked on every action, so
+// that claims end with the user's access to the repository.
+func (s *Server) checkRepoAdmin(w http.ResponseWriter, r *http.Request, session *models.MaintainerSession, app *models.App) bool {
+	admin, err := s.oauth.IsRepoAdmin(r.Context(), session.AccessToken, app.GitHubURL)
+	if err != nil {
+		s.logger.Warn("failed to check repository permissions", "app_id", app.ID, "login", session.Login, "error", err)
+		s.redirectMaintainer(w, r, "permission_failed", app.ID)
+		return false
+	}
+	if !admin {
+		if err := s.db.RemoveAppMaintainer(r.Context(), app.ID, session.GitHubID); err != nil {
+			s.logger.Error("failed to remove app maintainer", "app_id", app.ID, "error", err)
+		}
+		s.redirectMaintainer(w, r, "not_admin", app.ID)
+		return false
+	}
+	return true
+}
+
+// maintainerMessages are the messages shown on the maintainer dashboard after an action, by their
+// code, with the repository URL of the app the action concerns. Redirects only carry the code and
+// the app ID, so that links to the dashboard cannot show arbitrary text.
+var maintainerMessages = map[string]string{
+	"not_found":         "No app in the registry is hosted at that URL.",
+	"claimed":           "You now maintain %s.",
+	"unclaimed":         "You no longer maintain %s.",
+	"busy":              "%s is already being verified.",
+	"verifying":         "Verification of %s started, reload the page for the outcome.",
+	"permission_failed": "Failed to check your permissions on %s.",
+	"not_admin":         "You need admin permission on %s to maintain it.",
+	"invalid_email":     "Invalid email address.",
+	"invalid_webhook":   "The webhook URL must be an https URL of a public host.",
+	"saved":             "Notification preferences saved.",
+}
+
+// redirectMaintainer redirects back to the maintainer dashboard, showing the message of a code
+// about an app. An appID of 0 means the message concerns no app.
+func (s *Server) redirectMaintainer(w http.ResponseWriter, r *http.Request, code string, appID int64) {
+	query := url.Values{"message": {code}}
+	if appID != 0 {
+		query.Set("app", strconv.FormatInt(appID, 10))
+	}
+	http.Redirect(w, r, s.pathPrefix(r)+"/maintainer?"+query.Encode(), http.StatusSeeOther)
+}
+
+// maintainerMessage returns the message of the code the maintainer dashboard was redirected to
+// with, or an empty string if there is none.
+func (s *Server) maintainerMessage(r *http.Request) string {
+	query := r.URL.Query()
+	message, ok := maintainerMessages[query.Get("message")]
+	if !ok || !strings.Contains(message, "%s") {
+		return message
+	}
+	appID, err := strconv.ParseInt(query.Get("app"), 10, 64)
+	if err != nil {
+		return ""
+	}
+	app, err := s.getApp(r.Context(), appID)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(message, app.GitHubURL)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// ManifestDiff compares the current manifest of an app against the previously verified one.
+type ManifestDiff struct {
+	AppID          int64                 `json:"app_id"`
+	CurrentHash    string                `json:"current_hash,omitempty"`
+	BaseHash       string                `json:"base_hash,omitempty"`
+	BaseVerified   bool                  `json:"base_verified"` // False if no other version was ever verified and the previous fetch is used.
+	BaseFirstSeen  *time.Time            `json:"base_first_seen,omitempty"`
+	BaseVerifiedAt *time.Time            `json:"base_verified_at,omitempty"`
+	Changes        []rofl.ManifestChange `json:"changes"`
+}
+
+// manifestDiff builds the diff between the current manifest of an app and the base it is compared to:
+// the most recently verified other version, or else the previously fetched version.
+func (s *Server) manifestDiff(ctx context.Context, app *models.App) (*ManifestDiff, error) {
+	diff := &ManifestDiff{AppID: app.ID, Changes: []rofl.ManifestChange{}}
+	if !app.RoflYAML.Valid || app.RoflYAML.String == "" {
+		return diff, nil
+	}
+	current := app.RoflYAML.String
+
+	versions, err := s.db.GetManifestVersions(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *models.ManifestVersion
+	for _, version := range versions {
+		if version.Content == current {
+			diff.CurrentHash = version.ContentHash
+			continue
+		}
+		if version.VerifiedAt.Valid && (base == nil || !base.VerifiedAt.Valid) {
+			base = version
+		}
+		if base == nil {
+			base = version
+		}
+	}
+	if base == nil {
+		return diff, nil
+	}
+
+	changes, err := rofl.DiffManifests([]byte(base.Content), []byte(current))
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff manifests: %w", err)
+	}
+
+	diff.BaseHash = base.ContentHash
+	diff.BaseFirstSeen = &base.FirstSeenAt
+	if base.VerifiedAt.Valid {
+		diff.BaseVerified = true
+		diff.BaseVerifiedAt = &base.VerifiedAt.Time
+	}
+	if changes != nil {
+		diff.Changes = changes
+	}
+	return diff, nil
+}
+
+// loadManifestDiff parses the app ID URL parameter and builds its manifest diff, writing an error response on failure.
+func (s *Server) loadManifestDiff(w http.ResponseWriter, r *http.Request) (*ManifestDiff, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	diff, err := s.manifestDiff(r.Context(), app)
+	if err != nil {
+		s.logger.Error("failed to build manifest diff", "app_id", id, "error", err)
+		http.Error(w, "Failed to build manifest diff", http.StatusInternalServerError)
+		return nil, false
+	}
+	return diff, true
+}
+
+// handleManifestDiff handles GET /api/apps/{id}/manifest/diff.
+func (s *Server) handleManifestDiff(w http.ResponseWriter, r *http.Request) {
+	diff, ok := s.loadManifestDiff(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, diff)
+}
+
+// handleManifestDiffHTML handles GET /htmx/apps/{id}/manifest/diff.
+func (s *Server) handleManifestDiffHTML(w http.ResponseWriter, r *http.Request) {
+	diff, ok := s.loadManifestDiff(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	loc := localeFor(r)
+	if err := s.diffTemplate.For(loc).Execute(&buf, newManifestDiffData(loc, diff)); err != nil {
+		s.logger.Error("failed to render manifest diff", "app_id", diff.AppID, "error", err)
+		http.Error(w, "Failed to render manifest diff", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	_, _ = w.Write(buf.Bytes())
+}
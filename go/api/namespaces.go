@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// errAppNotFound is returned for apps of other namespaces than the one of a request.
+var errAppNotFound = errors.New("app not found")
+
+type namespaceKey struct{}
+
+// namespaceFrom returns the namespace of a request, as resolved by resolveNamespace.
+func namespaceFrom(ctx context.Context) string {
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok {
+		return namespace
+	}
+	return models.DefaultNamespace
+}
+
+// resolveNamespace determines the namespace of a request from its host or path prefix, removing
+// the prefix from the path. Requests matching no namespace are served from the default one.
+func (s *Server) resolveNamespace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace := models.DefaultNamespace
+		if ns := s.namespaceByHost(requestHost(r)); ns != nil {
+			namespace = ns.Name
+		} else {
+			for _, ns := range s.cfg.Namespaces {
+				if ns.PathPrefix == "" {
+					continue
+				}
+				rest, ok := strings.CutPrefix(r.URL.Path, ns.PathPrefix)
+				if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+					continue
+				}
+				namespace = ns.Name
+				u := *r.URL
+				u.Path = "/" + strings.TrimPrefix(rest, "/")
+				u.RawPath = ""
+				r = r.Clone(r.Context())
+				r.URL = &u
+				break
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), namespaceKey{}, namespace)))
+	})
+}
+
+// pathPrefix returns the path prefix a request was served below, which links, redirects and
+// cookies of pages served to it must carry. Requests of namespaces served on their own hosts have
+// no prefix.
+func (s *Server) pathPrefix(r *http.Request) string {
+	ns := s.cfg.Namespace(namespaceFrom(r.Context()))
+	if ns == nil || s.namespaceByHost(requestHost(r)) == ns {
+		return ""
+	}
+	return ns.PathPrefix
+}
+
+// requestHost returns the host name of a request, without the port.
+func requestHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		return host
+	}
+	return r.Host
+}
+
+// namespaceByHost returns the namespace served on a host, or nil if there is none.
+func (s *Server) namespaceByHost(host string) *config.NamespaceConfig {
+	for i, ns := range s.cfg.Namespaces {
+		for _, h := range ns.Hosts {
+			if strings.EqualFold(h, host) {
+				return &s.cfg.Namespaces[i]
+			}
+		}
+	}
+	return nil
+}
+
+// getApp retrieves an app of the namespace of a request by ID.
+func (s *Server) getApp(ctx context.Context, id int64) (*models.App, error) {
+	app, err := s.db.GetAppByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if app.Namespace != namespaceFrom(ctx) {
+		return nil, errAppNotFound
+	}
+	return app, nil
+}
+
+// getAppBySlug retrieves an app of the namespace of a request by slug.
+func (s *Server) getAppBySlug(ctx context.Context, slug string) (*models.App, error) {
+	return s.db.GetAppBySlug(ctx, namespaceFrom(ctx), slug)
+}
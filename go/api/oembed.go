@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Default size of the embedded widget.
+const (
+	embedWidth  = 380
+	embedHeight = 80
+)
+
+// OEmbed is an oEmbed response of type "rich", see https://oembed.com/.
+type OEmbed struct {
+	Type            string `json:"type"`
+	Version         string `json:"version"`
+	Title           string `json:"title"`
+	ProviderName    string `json:"provider_name"`
+	ProviderURL     string `json:"provider_url"`
+	CacheAge        int    `json:"cache_age"`
+	HTML            string `json:"html"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
+
+// oembedURL returns the oEmbed discovery URL of an app page.
+func oembedURL(base, pageURL string) string {
+	return base + "/api/oembed?format=json&url=" + url.QueryEscape(pageURL)
+}
+
+// dimension parses an optional maxwidth or maxheight parameter, returning def if it is not
+// set or larger than def.
+func dimension(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid dimension %q", value)
+	}
+	return min(n, def), nil
+}
+
+// handleOEmbed handles GET /api/oembed, returning an embed of the app page given by the url
+// parameter. Only JSON is supported.
+func (s *Server) handleOEmbed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if format := query.Get("format"); format != "" && format != "json" {
+		http.Error(w, "Only the json format is supported", http.StatusNotImplemented)
+		return
+	}
+
+	base := s.baseURL(r)
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		http.Error(w, "Invalid base URL", http.StatusInternalServerError)
+		return
+	}
+	pageURL, err := url.Parse(query.Get("url"))
+	if err != nil || !strings.EqualFold(pageURL.Host, baseURL.Host) {
+		http.Error(w, "URL is not an app page of this registry", http.StatusNotFound)
+		return
+	}
+	ref, ok := strings.CutPrefix(pageURL.Path, "/apps/")
+	if !ok || ref == "" || strings.Contains(ref, "/") {
+		http.Error(w, "URL is not an app page of this registry", http.StatusNotFound)
+		return
+	}
+
+	width, err := dimension(query.Get("maxwidth"), embedWidth)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	height, err := dimension(query.Get("maxheight"), embedHeight)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.resolveAppRef(r.Context(), ref)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	data, err := s.loadAppCardData(r.Context(), defaultLocale, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	embedURL := base + "/embed/" + url.PathEscape(ref)
+	if data.Slug != "" {
+		embedURL = base + "/embed/" + url.PathEscape(data.Slug)
+	}
+	resp := OEmbed{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        data.Name,
+		ProviderName: s.layout.Title,
+		ProviderURL:  base + "/",
+		CacheAge:     embedRefreshSeconds,
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border:0" title="%s" loading="lazy"></iframe>`,
+			html.EscapeString(embedURL), width, height, html.EscapeString(data.Name)),
+		Width:  width,
+		Height: height,
+	}
+	// The thumbnail must fit the requested size as well, and the preview image is not resized.
+	maxWidth, _ := dimension(query.Get("maxwidth"), previewWidth)
+	maxHeight, _ := dimension(query.Get("maxheight"), previewHeight)
+	if maxWidth == previewWidth && maxHeight == previewHeight {
+		resp.ThumbnailURL = fmt.Sprintf("%s/api/apps/%d/preview.png", base, data.ID)
+		resp.ThumbnailWidth = previewWidth
+		resp.ThumbnailHeight = previewHeight
+	}
+
+	writeJSON(w, resp)
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCachedPages bounds the number of cached pages; the least recently rendered are evicted first.
+	maxCachedPages = 256
+	// pageStaleFactor is how many TTLs a stale page is still served for while it is refreshed.
+	pageStaleFactor = 10
+	// pageRenderTimeout bounds rendering a page in the background.
+	pageRenderTimeout = 10 * time.Second
+)
+
+// Cache results reported in the X-Cache header.
+const (
+	cacheHit   = "HIT"
+	cacheStale = "STALE"
+	cacheMiss  = "MISS"
+)
+
+// cachedPage is a rendered page.
+type cachedPage struct {
+	body       []byte
+	renderedAt time.Time
+	rendering  chan struct{} // Closed once the page is rendered, nil if it is not being rendered.
+	err        error         // Error of the last synchronous render, for waiting requests.
+}
+
+// pageCache keeps rendered pages in memory. Fresh pages are served as is, and stale ones are
+// served while being rendered again in the background, so that a traffic spike renders each page
+// once per TTL instead of once per request.
+type pageCache struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	pages map[string]*cachedPage
+}
+
+// newPageCache creates an empty page cache.
+func newPageCache(ttl time.Duration) *pageCache {
+	return &pageCache{ttl: ttl, pages: make(map[string]*cachedPage)}
+}
+
+// get returns the page cached under key, rendering it if it is missing or too stale to be served.
+// Concurrent requests for a missing page wait for a single render. Background renders use ctx
+// without its cancellation, so they outlive the request that started them.
+func (c *pageCache) get(ctx context.Context, key string, render func(context.Context) ([]byte, error)) ([]byte, string, error) {
+	now := time.Now()
+	c.mu.Lock()
+	page, ok := c.pages[key]
+	switch {
+	case ok && page.body != nil && now.Sub(page.renderedAt) < c.ttl:
+		body := page.body
+		c.mu.Unlock()
+		return body, cacheHit, nil
+	case ok && page.body != nil && now.Sub(page.renderedAt) < pageStaleFactor*c.ttl:
+		body := page.body
+		if page.rendering == nil {
+			page.rendering = make(chan struct{})
+			go c.refresh(context.WithoutCancel(ctx), page, render)
+		}
+		c.mu.Unlock()
+		return body, cacheStale, nil
+	case ok && page.rendering != nil:
+		// Another request is rendering the page.
+		done := page.rendering
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, cacheMiss, ctx.Err()
+		}
+		c.mu.Lock()
+		body, err := page.body, page.err
+		c.mu.Unlock()
+		return body, cacheMiss, err
+	}
+
+	if !ok {
+		c.evictLocked()
+		page = &cachedPage{}
+		c.pages[key] = page
+	}
+	page.rendering = make(chan struct{})
+	c.mu.Unlock()
+
+	body, err := render(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page.err = err
+	if err == nil {
+		page.body, page.renderedAt = body, time.Now()
+	}
+	close(page.rendering)
+	page.rendering = nil
+	if page.body == nil {
+		delete(c.pages, key)
+	}
+	return body, cacheMiss, err
+}
+
+// refresh renders a stale page again in the background, keeping the stale one if rendering fails.
+func (c *pageCache) refresh(ctx context.Context, page *cachedPage, render func(context.Context) ([]byte, error)) {
+	ctx, cancel := context.WithTimeout(ctx, pageRenderTimeout)
+	defer cancel()
+	body, err := render(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		page.body, page.renderedAt = body, time.Now()
+	}
+	close(page.rendering)
+	page.rendering = nil
+}
+
+// evictLocked removes the least recently rendered page if the cache is full.
+func (c *pageCache) evictLocked() {
+	if len(c.pages) < maxCachedPages {
+		return
+	}
+	var (
+		oldestKey string
+		oldest    *cachedPage
+	)
+	for key, page := range c.pages {
+		if page.rendering != nil {
+			continue
+		}
+		if oldest == nil || page.renderedAt.Before(oldest.renderedAt) {
+			oldestKey, oldest = key, page
+		}
+	}
+	if oldest != nil {
+		delete(c.pages, oldestKey)
+	}
+}
+
+// anonymous reports whether a request carries no credentials, so it may be served a cached page.
+// Signed-in operators and maintainers always see the current state.
+func anonymous(r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" {
+		return false
+	}
+	for _, name := range []string{adminSessionCookie, maintainerSessionCookie} {
+		if _, err := r.Cookie(name); err == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PageMeta holds the link preview metadata of an app page.
+type PageMeta struct {
+	SiteName    string
+	Title       string
+	Description string
+	URL         string
+	ImageURL    string
+	ImageWidth  int
+	ImageHeight int
+	OEmbedURL   string
+
+	StructuredData *SoftwareApplication
+}
+
+var pageMetaTemplate = `<title>{{.Title}}</title>
+    <meta name="description" content="{{.Description}}">
+    <link rel="canonical" href="{{.URL}}">
+    <link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}" title="{{.Title}}">
+    <meta property="og:type" content="website">
+    <meta property="og:site_name" content="{{.SiteName}}">
+    <meta property="og:title" content="{{.Title}}">
+    <meta property="og:description" content="{{.Description}}">
+    <meta property="og:url" content="{{.URL}}">
+    <meta property="og:image" content="{{.ImageURL}}">
+    <meta property="og:image:width" content="{{.ImageWidth}}">
+    <meta property="og:image:height" content="{{.ImageHeight}}">
+    <meta name="twitter:card" content="summary_large_image">
+    <meta name="twitter:title" content="{{.Title}}">
+    <meta name="twitter:description" content="{{.Description}}">
+    <meta name="twitter:image" content="{{.ImageURL}}">
+    <script type="application/ld+json">{{.StructuredData}}</script>`
+
+// appPath returns the permalink path of an app: its slug, or its ID if no slug is assigned yet.
+func appPath(id int64, slug string) string {
+	if slug == "" {
+		return fmt.Sprintf("/apps/%d", id)
+	}
+	return "/apps/" + slug
+}
+
+// parseAppRef parses the app ID from a legacy permalink reference of the form "slug-id" or "id".
+func parseAppRef(ref string) (int64, error) {
+	if i := strings.LastIndexByte(ref, '-'); i != -1 {
+		ref = ref[i+1:]
+	}
+	return strconv.ParseInt(ref, 10, 64)
+}
+
+// resolveAppRef returns the ID of the app a permalink reference points to, looking it up
+// as a slug first.
+func (s *Server) resolveAppRef(ctx context.Context, ref string) (int64, error) {
+	if app, err := s.getAppBySlug(ctx, ref); err == nil {
+		return app.ID, nil
+	}
+	return parseAppRef(ref)
+}
+
+// baseURL returns the public base URL of the registry, without a trailing slash.
+func (s *Server) baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	// Namespaces are served on their own hosts or below their path prefix.
+	if ns := s.cfg.Namespace(namespaceFrom(r.Context())); ns != nil {
+		if s.namespaceByHost(requestHost(r)) == ns {
+			return scheme + "://" + r.Host
+		}
+		if s.cfg.Server.PublicURL != "" {
+			return strings.TrimRight(s.cfg.Server.PublicURL, "/") + ns.PathPrefix
+		}
+		return scheme + "://" + r.Host + ns.PathPrefix
+	}
+
+	if s.cfg.Server.PublicURL != "" {
+		return strings.TrimRight(s.cfg.Server.PublicURL, "/")
+	}
+	return scheme + "://" + r.Host
+}
+
+// primaryDeployment returns the deployment the aggregated status of an app is based on.
+func primaryDeployment(data *AppCardData) *DeploymentStatus {
+	if data.MainnetDeployment != nil {
+		return data.MainnetDeployment
+	}
+	for i := range data.OtherDeployments {
+		if data.OtherDeployments[i].Status == data.Status {
+			return &data.OtherDeployments[i]
+		}
+	}
+	return nil
+}
+
+// statusSummary describes the verification status of an app in a single sentence.
+func statusSummary(loc *Locale, data *AppCardData) string {
+	dep := primaryDeployment(data)
+	switch {
+	case dep == nil:
+		return loc.T("summary.not_verified")
+	case dep.Status == statusVerified && dep.CommitSHA != "":
+		return loc.T("summary.verified_at_commit", dep.Name, dep.CommitLabel)
+	case dep.Status == statusVerified:
+		return loc.T("summary.verified", dep.Name)
+	case dep.Status == "stale":
+		return loc.T("summary.stale", dep.Name)
+	case dep.Status == "failed":
+		return loc.T("summary.failed", dep.Name)
+	default:
+		return loc.T("summary.pending", dep.Name)
+	}
+}
+
+// loadAppCardData loads an app and builds its card data, formatted for a locale.
+func (s *Server) loadAppCardData(ctx context.Context, loc *Locale, id int64) (*AppCardData, error) {
+	app, err := s.getApp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := s.db.GetDeploymentsByAppID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get deployments", "app_id", id, "error", err)
+		deps = nil // Continue with empty deployments
+	}
+
+	return newAppCardData(loc, app, deps, nil)
+}
+
+// handleAppPage handles GET /apps/{ref}, the permalink page of an app. It serves the index page
+// with link preview metadata of the app; the page opens the app details on load.
+func (s *Server) handleAppPage(w http.ResponseWriter, r *http.Request) {
+	id, err := s.resolveAppRef(r.Context(), chi.URLParam(r, "ref"))
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	loc := localeFor(r)
+	data, err := s.loadAppCardData(r.Context(), loc, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	base := s.baseURL(r)
+	description := statusSummary(loc, data)
+	if data.Description != "" {
+		description += " " + data.Description
+	}
+	meta := PageMeta{
+		SiteName:    s.layout.Title,
+		Title:       data.Name + " · " + s.layout.Title,
+		Description: description,
+		URL:         base + appPath(data.ID, data.Slug),
+		ImageURL:    fmt.Sprintf("%s/api/apps/%d/preview.png", base, data.ID),
+		ImageWidth:  previewWidth,
+		ImageHeight: previewHeight,
+	}
+	meta.OEmbedURL = oembedURL(base, meta.URL)
+	meta.StructuredData = newSoftwareApplication(data, meta.URL, meta.ImageURL)
+
+	var head bytes.Buffer
+	if err := s.metaTemplate.Execute(&head, meta); err != nil {
+		s.logger.Error("failed to render page metadata", "app_id", id, "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	// Replace the generic title of the index page with the app metadata.
+	index, _, err := s.renderIndex(r)
+	if err != nil {
+		s.logger.Error("failed to render index page", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	start := bytes.Index(index, []byte("<title>"))
+	end := bytes.Index(index, []byte("</title>"))
+	if start == -1 || end == -1 {
+		s.serveIndex(w, r)
+		return
+	}
+	page := make([]byte, 0, len(index)+head.Len())
+	page = append(page, index[:start]...)
+	page = append(page, head.Bytes()...)
+	page = append(page, index[end+len("</title>"):]...)
+
+	rememberLanguage(w, r)
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	_, _ = w.Write(page)
+}
+
+// handleAppLogo serves the sanitized logo of an app, as fetched and re-encoded by the worker.
+func (s *Server) handleAppLogo(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.getApp(r.Context(), id); err != nil {
+		http.Error(w, "Logo not found", http.StatusNotFound)
+		return
+	}
+
+	logo, updatedAt, err := s.db.GetAppLogo(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Logo not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	http.ServeContent(w, r, "logo.png", updatedAt, bytes.NewReader(logo))
+}
+
+// handleAppPreview handles GET /api/apps/{id}/preview.png, the link preview image of an app.
+func (s *Server) handleAppPreview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.loadAppCardData(r.Context(), defaultLocale, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	img, err := renderPreview(s.layout, data)
+	if err != nil {
+		s.logger.Error("failed to render preview", "app_id", id, "error", err)
+		http.Error(w, "Failed to render preview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(img)
+}
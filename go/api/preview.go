@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode"
+)
+
+// Link preview image size, as recommended for OpenGraph and Twitter large image cards.
+const (
+	previewWidth  = 1200
+	previewHeight = 630
+)
+
+// previewMargin is the horizontal margin of the preview image content.
+const previewMargin = 80
+
+var (
+	previewBackground = color.RGBA{0x0f, 0x17, 0x2a, 0xff} // slate-900
+	previewText       = color.RGBA{0xf8, 0xfa, 0xfc, 0xff} // slate-50
+	previewMuted      = color.RGBA{0x94, 0xa3, 0xb8, 0xff} // slate-400
+)
+
+// previewStatusColors are the badge colors of each verification status.
+var previewStatusColors = map[string]color.RGBA{
+	statusVerified: {0x10, 0xb9, 0x81, 0xff}, // emerald-500
+	"pending":      {0xf5, 0x9e, 0x0b, 0xff}, // amber-500
+	"stale":        {0xf9, 0x73, 0x16, 0xff}, // orange-500
+	"failed":       {0xef, 0x44, 0x44, 0xff}, // red-500
+}
+
+// renderPreview renders the link preview image of an app: its name, verification status, and commit.
+func renderPreview(layout *Layout, data *AppCardData) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, previewWidth, previewHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(previewBackground), image.Point{}, draw.Src)
+
+	statusColor, ok := previewStatusColors[data.Status]
+	if !ok {
+		statusColor = previewStatusColors["pending"]
+	}
+
+	drawText(img, previewMargin, 80, 4, layout.Title, previewMuted)
+	drawText(img, previewMargin, 200, 10, fitText(data.Name, 10), previewText)
+
+	// Status badge.
+	badge := strings.ToUpper(data.Status)
+	if data.Status == statusVerified {
+		badge = "VERIFIED ✓"
+	}
+	badgeWidth := textWidth(badge, 6) + 2*36
+	fillRect(img, image.Rect(previewMargin, 340, previewMargin+badgeWidth, 340+42+2*24), statusColor)
+	drawText(img, previewMargin+36, 364, 6, badge, previewBackground)
+
+	var details []string
+	if dep := primaryDeployment(data); dep != nil {
+		details = append(details, strings.ToUpper(dep.Name))
+		if dep.CommitSHAShort != "" {
+			details = append(details, "COMMIT "+dep.CommitSHAShort)
+		}
+	}
+	if data.Version != "" {
+		details = append(details, "V"+data.Version)
+	}
+	drawText(img, previewMargin, 480, 5, fitText(strings.Join(details, " · "), 5), previewMuted)
+
+	fillRect(img, image.Rect(0, previewHeight-16, previewWidth, previewHeight), statusColor)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode preview: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitText truncates text so it fits the preview width when drawn at scale.
+func fitText(text string, scale int) string {
+	maxGlyphs := (previewWidth - 2*previewMargin) / (glyphAdvance * scale)
+	runes := []rune(text)
+	if len(runes) <= maxGlyphs {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxGlyphs-2])) + ".."
+}
+
+// fillRect fills a rectangle of img with c.
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// Glyphs are 5x7 pixels, drawn 6 pixels apart.
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+// textWidth returns the width of text drawn at scale.
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*glyphAdvance - 1) * scale
+}
+
+// drawText draws text with its top left corner at x, y, scaling each glyph pixel to a square of scale pixels.
+// Letters are drawn in upper case; characters without a glyph are drawn as question marks.
+func drawText(img draw.Image, x, y, scale int, text string, c color.Color) {
+	src := image.NewUniform(c)
+	for _, r := range text {
+		glyph, ok := glyphs[unicode.ToUpper(r)]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				px := x + col*scale
+				py := y + row*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), src, image.Point{}, draw.Src)
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
+
+// glyphs is a 5x7 bitmap font; each row is a bit mask with the leftmost pixel as the highest bit.
+var glyphs = map[rune][glyphHeight]uint8{
+	' ':  {},
+	'A':  {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'B':  {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C':  {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D':  {0x1e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1e},
+	'E':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G':  {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H':  {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I':  {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M':  {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P':  {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q':  {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R':  {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S':  {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T':  {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X':  {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04},
+	'Z':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	'0':  {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1':  {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3':  {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4':  {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5':  {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6':  {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9':  {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	',':  {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	':':  {0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00},
+	'-':  {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+	'/':  {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'!':  {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'?':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'+':  {0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00},
+	'&':  {0x0c, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0d},
+	'\'': {0x04, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00},
+	'·':  {0x00, 0x00, 0x00, 0x0c, 0x0c, 0x00, 0x00},
+	'✓':  {0x00, 0x01, 0x02, 0x02, 0x14, 0x08, 0x00},
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// Types of provenance documents.
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	slsaProvenanceType  = "https://slsa.dev/provenance/v1"
+
+	// provenanceBuildType defines the parameters of provenance documents: building a ROFL app
+	// with oasis rofl build from a manifest in a git repository.
+	provenanceBuildType = "https://github.com/ptrus/rofl-attestations/provenance/rofl-build/v1"
+)
+
+// ProvenanceStatement is an in-toto statement of the SLSA build provenance of a deployment: the
+// enclave identities that building an app at a commit yields, and how the registry built it.
+type ProvenanceStatement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"` // The enclave identities of the deployment policy.
+	PredicateType string               `json:"predicateType"`
+	Predicate     ProvenancePredicate  `json:"predicate"`
+}
+
+// ResourceDescriptor identifies an artifact by name or URI, and its digests.
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// ProvenancePredicate describes a build in the SLSA provenance format.
+type ProvenancePredicate struct {
+	BuildDefinition ProvenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      ProvenanceRunDetails      `json:"runDetails"`
+}
+
+// ProvenanceBuildDefinition is what was built: the parameters of the build and the sources and
+// artifacts it used.
+type ProvenanceBuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ProvenanceParameters `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies"`
+}
+
+// ProvenanceParameters are the build parameters of a deployment: where its source is, and the
+// build settings of its manifest.
+type ProvenanceParameters struct {
+	Repository string              `json:"repository"`
+	Ref        string              `json:"ref"`
+	Manifest   string              `json:"manifest"` // Path of the manifest in the repository.
+	Deployment string              `json:"deployment"`
+	Network    string              `json:"network,omitempty"`
+	TEE        string              `json:"tee,omitempty"`
+	Kind       string              `json:"kind,omitempty"`
+	Resources  ProvenanceResources `json:"resources"`
+	Artifacts  ProvenanceArtifacts `json:"artifacts"`
+}
+
+// ProvenanceResources are the resources a manifest requests for the app.
+type ProvenanceResources struct {
+	Memory      int     `json:"memory,omitempty"` // MiB.
+	CPUs        float64 `json:"cpus,omitempty"`
+	StorageKind string  `json:"storage_kind,omitempty"`
+	StorageSize int     `json:"storage_size,omitempty"` // MiB.
+}
+
+// ProvenanceArtifacts are the artifacts a manifest builds the app from.
+type ProvenanceArtifacts struct {
+	Builder          string `json:"builder,omitempty"`
+	Firmware         string `json:"firmware,omitempty"`
+	Kernel           string `json:"kernel,omitempty"`
+	Stage2           string `json:"stage2,omitempty"`
+	ContainerRuntime string `json:"container_runtime,omitempty"`
+	ContainerCompose string `json:"container_compose,omitempty"`
+}
+
+// ProvenanceRunDetails is how the build ran: the builder and the verification it was part of.
+type ProvenanceRunDetails struct {
+	Builder  ProvenanceBuilder  `json:"builder"`
+	Metadata ProvenanceMetadata `json:"metadata"`
+}
+
+// ProvenanceBuilder identifies the registry that reproduced the build, and the toolchain it used.
+type ProvenanceBuilder struct {
+	ID                  string               `json:"id"`
+	Version             map[string]string    `json:"version,omitempty"`
+	BuilderDependencies []ResourceDescriptor `json:"builderDependencies,omitempty"`
+}
+
+// ProvenanceMetadata identifies the verification that reproduced the build.
+type ProvenanceMetadata struct {
+	InvocationID string     `json:"invocationId,omitempty"` // Backend task.
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   time.Time  `json:"finishedOn"`
+}
+
+// newProvenanceStatement returns the provenance statement of a build reproduced by the registry at
+// base, using the given manifest.
+func newProvenanceStatement(app *models.App, provenance *models.Provenance, manifest *rofl.Manifest, base string) *ProvenanceStatement {
+	statement := &ProvenanceStatement{
+		Type:          inTotoStatementType,
+		Subject:       []ResourceDescriptor{},
+		PredicateType: slsaProvenanceType,
+	}
+
+	params := ProvenanceParameters{
+		Repository: app.GitHubURL,
+		Ref:        app.GitRef,
+		Manifest:   app.ManifestPath.String,
+		Deployment: provenance.DeploymentName,
+		TEE:        manifest.TEE,
+		Kind:       manifest.Kind,
+		Resources: ProvenanceResources{
+			Memory:      manifest.Resources.Memory,
+			CPUs:        manifest.Resources.CPUs,
+			StorageKind: manifest.Resources.Storage.Kind,
+			StorageSize: manifest.Resources.Storage.Size,
+		},
+		Artifacts: ProvenanceArtifacts{
+			Builder:          manifest.Artifacts.Builder,
+			Firmware:         manifest.Artifacts.Firmware,
+			Kernel:           manifest.Artifacts.Kernel,
+			Stage2:           manifest.Artifacts.Stage2,
+			ContainerRuntime: manifest.Artifacts.Container.Runtime,
+			ContainerCompose: manifest.Artifacts.Container.Compose,
+		},
+	}
+	if params.Manifest == "" {
+		params.Manifest = "rofl.yaml"
+	}
+	if deployment := manifest.Deployments[provenance.DeploymentName]; deployment != nil {
+		params.Network = deployment.Network
+		for _, id := range deployment.Policy.Enclaves {
+			statement.Subject = append(statement.Subject, enclaveDescriptor(id))
+		}
+	}
+
+	dependencies := []ResourceDescriptor{
+		{URI: "git+" + app.GitHubURL, Digest: map[string]string{"gitCommit": provenance.CommitSHA}},
+		{Name: params.Manifest, Digest: map[string]string{"sha256": provenance.ManifestSHA256}},
+	}
+	for _, artifact := range []string{params.Artifacts.Firmware, params.Artifacts.Kernel, params.Artifacts.Stage2, params.Artifacts.ContainerRuntime} {
+		if artifact != "" {
+			dependencies = append(dependencies, artifactDescriptor(artifact))
+		}
+	}
+
+	builder := ProvenanceBuilder{ID: base}
+	if provenance.CLIVersion != "" {
+		builder.Version = map[string]string{"oasis-cli": provenance.CLIVersion}
+	}
+	if provenance.BuilderImage != "" {
+		builder.BuilderDependencies = []ResourceDescriptor{imageDescriptor(provenance.BuilderImage)}
+	}
+
+	statement.Predicate = ProvenancePredicate{
+		BuildDefinition: ProvenanceBuildDefinition{
+			BuildType:            provenanceBuildType,
+			ExternalParameters:   params,
+			ResolvedDependencies: dependencies,
+		},
+		RunDetails: ProvenanceRunDetails{
+			Builder: builder,
+			Metadata: ProvenanceMetadata{
+				InvocationID: provenance.TaskID,
+				StartedOn:    reportTime(provenance.StartedAt.Time, provenance.StartedAt.Valid),
+				FinishedOn:   provenance.FinishedAt.UTC(),
+			},
+		},
+	}
+	return statement
+}
+
+// enclaveDescriptor describes an enclave identity by its measurements. Identities that cannot be
+// decoded are described by name only.
+func enclaveDescriptor(id string) ResourceDescriptor {
+	descriptor := ResourceDescriptor{Name: id}
+	identity, err := rofl.DecodeEnclaveIdentity(id)
+	if err != nil {
+		return descriptor
+	}
+	descriptor.Digest = map[string]string{"mrenclave": hex.EncodeToString(identity.MrEnclave[:])}
+	if identity.HasSigner() {
+		descriptor.Digest["mrsigner"] = hex.EncodeToString(identity.MrSigner[:])
+	}
+	return descriptor
+}
+
+// artifactDescriptor describes an artifact of a manifest. Manifests pin artifacts by appending
+// their SHA-256 digest to the URL as a fragment.
+func artifactDescriptor(artifact string) ResourceDescriptor {
+	uri, digest, found := strings.Cut(artifact, "#")
+	if _, err := hex.DecodeString(digest); !found || err != nil || len(digest) != 64 {
+		return ResourceDescriptor{URI: artifact}
+	}
+	return ResourceDescriptor{URI: uri, Digest: map[string]string{"sha256": strings.ToLower(digest)}}
+}
+
+// imageDescriptor describes a container image, by digest if it is referenced by one.
+func imageDescriptor(image string) ResourceDescriptor {
+	name, digest, found := strings.Cut(image, "@sha256:")
+	if !found {
+		return ResourceDescriptor{URI: "docker://" + image}
+	}
+	return ResourceDescriptor{URI: "docker://" + name, Digest: map[string]string{"sha256": digest}}
+}
+
+// handleProvenance handles GET /api/v1/apps/{id}/deployments/{name}/provenance and returns the
+// provenance of the last build of a deployment that verification reproduced, or with
+// ?commit=<sha> of the last one of a commit.
+func (s *Server) handleProvenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+	commitSHA := r.URL.Query().Get("commit")
+	if commitSHA != "" && !github.IsCommitSHA(commitSHA) {
+		http.Error(w, "Invalid commit (must be a full commit SHA)", http.StatusBadRequest)
+		return
+	}
+
+	app, err := s.getApp(ctx, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	deploymentName := chi.URLParam(r, "name")
+	provenance, err := s.db.GetProvenance(ctx, id, deploymentName, commitSHA)
+	if err != nil {
+		s.logger.Error("failed to get provenance", "app_id", id, "deployment", deploymentName, "error", err)
+		http.Error(w, "Failed to load provenance", http.StatusInternalServerError)
+		return
+	}
+	if provenance == nil {
+		http.Error(w, "No verified build found", http.StatusNotFound)
+		return
+	}
+	version, err := s.db.GetManifestVersionByHash(ctx, id, provenance.ManifestSHA256)
+	if err != nil {
+		s.logger.Error("failed to get manifest version", "app_id", id, "error", err)
+		http.Error(w, "Failed to load manifest", http.StatusInternalServerError)
+		return
+	}
+	if version == nil {
+		http.Error(w, "Manifest of the build not found", http.StatusNotFound)
+		return
+	}
+	manifest, err := rofl.Parse([]byte(version.Content))
+	if err != nil {
+		s.logger.Error("failed to parse manifest version", "app_id", id, "error", err)
+		http.Error(w, "Failed to parse manifest", http.StatusInternalServerError)
+		return
+	}
+
+	statement := newProvenanceStatement(app, provenance, manifest, s.baseURL(r))
+
+	name := strconv.FormatInt(app.ID, 10)
+	if app.Slug.Valid && app.Slug.String != "" {
+		name = app.Slug.String
+	}
+	filename := fmt.Sprintf("%s-%s-%.7s.intoto.json", name, elementIDPart(deploymentName), provenance.CommitSHA)
+	w.Header().Set("Content-Type", "application/vnd.in-toto+json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(statement)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// Test that provenance describes the enclave identities of the deployment policy as subjects, and
+// the commit, manifest, and pinned artifacts as dependencies of the build.
+func TestNewProvenanceStatement(t *testing.T) {
+	manifest, err := rofl.Parse([]byte(`
+name: price-oracle
+tee: tdx
+kind: container
+artifacts:
+  firmware: https://example.com/ovmf.tdx.fd#DB47100A7D6A0C1F6983BE224137C3F8D7CB09B63BB1C7A5EE7829D8E994A42F
+  kernel: https://example.com/stage1.bin
+deployments:
+  mainnet:
+    network: mainnet
+    policy:
+      enclaves:
+        - id: KPHyi5wtHyCPYNaCqd7+G9lXCA0FFlwy7DFA29h8ZKIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	app := &models.App{ID: 1, GitHubURL: "https://github.com/example/price-oracle", GitRef: "main"}
+	provenance := &models.Provenance{
+		AppID:          1,
+		DeploymentName: "mainnet",
+		CommitSHA:      "3fa9c2d1e0b7a6f5c4d3e2f1a0b9c8d7e6f5a4b3",
+		ManifestSHA256: "00ff",
+		CLIVersion:     "0.14.1",
+		BuilderImage:   "ghcr.io/oasisprotocol/rofl-dev@sha256:abcd",
+		TaskID:         "task-1",
+		FinishedAt:     time.Date(2025, 6, 1, 14, 3, 0, 0, time.UTC),
+	}
+
+	statement := newProvenanceStatement(app, provenance, manifest, "https://registry.example.com")
+
+	if len(statement.Subject) != 1 {
+		t.Fatalf("Expected 1 subject, got %d", len(statement.Subject))
+	}
+	subject := statement.Subject[0].Digest
+	if subject["mrenclave"] != "28f1f28b9c2d1f208f60d682a9defe1bd957080d05165c32ec3140dbd87c64a2" || subject["mrsigner"] != "" {
+		t.Errorf("Unexpected subject digest: %v", subject)
+	}
+
+	deps := statement.Predicate.BuildDefinition.ResolvedDependencies
+	if len(deps) != 4 {
+		t.Fatalf("Expected 4 dependencies, got %+v", deps)
+	}
+	if deps[0].URI != "git+https://github.com/example/price-oracle" || deps[0].Digest["gitCommit"] != provenance.CommitSHA {
+		t.Errorf("Unexpected source dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "rofl.yaml" || deps[1].Digest["sha256"] != "00ff" {
+		t.Errorf("Unexpected manifest dependency: %+v", deps[1])
+	}
+	if deps[2].URI != "https://example.com/ovmf.tdx.fd" || deps[2].Digest["sha256"] != "db47100a7d6a0c1f6983be224137c3f8d7cb09b63bb1c7a5ee7829d8e994a42f" {
+		t.Errorf("Unexpected pinned artifact: %+v", deps[2])
+	}
+	if deps[3].URI != "https://example.com/stage1.bin" || deps[3].Digest != nil {
+		t.Errorf("Unexpected unpinned artifact: %+v", deps[3])
+	}
+
+	run := statement.Predicate.RunDetails
+	if run.Builder.ID != "https://registry.example.com" || run.Builder.Version["oasis-cli"] != "0.14.1" {
+		t.Errorf("Unexpected builder: %+v", run.Builder)
+	}
+	if image := run.Builder.BuilderDependencies; len(image) != 1 || image[0].URI != "docker://ghcr.io/oasisprotocol/rofl-dev" || image[0].Digest["sha256"] != "abcd" {
+		t.Errorf("Unexpected builder image: %+v", image)
+	}
+	if run.Metadata.StartedOn != nil || run.Metadata.InvocationID != "task-1" {
+		t.Errorf("Unexpected metadata: %+v", run.Metadata)
+	}
+
+	// Deployments missing from the manifest have no subjects, which encode as an empty list.
+	provenance.DeploymentName = "testnet"
+	provenance.StartedAt = sql.NullTime{Time: provenance.FinishedAt.Add(-time.Minute), Valid: true}
+	statement = newProvenanceStatement(app, provenance, manifest, "https://registry.example.com")
+	if statement.Subject == nil || len(statement.Subject) != 0 || statement.Predicate.RunDetails.Metadata.StartedOn == nil {
+		t.Errorf("Unexpected statement of a missing deployment: %+v", statement)
+	}
+}
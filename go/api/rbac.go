@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/github"
+)
+
+// principal is the caller of an admin API request.
+type principal struct {
+	Name      string
+	Role      config.Role
+	Repos     []string // Owners or repositories a maintainer manages.
+	Namespace string   // Namespace the key is limited to, empty for keys of the whole deployment.
+}
+
+type principalKey struct{}
+
+// principalFrom returns the caller stored by requireRole.
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+// CanManage reports whether the caller may act on the app of a repository: admins manage all
+// apps and maintainers the apps of their repositories.
+func (p *principal) CanManage(repoURL string) bool {
+	if p.Role.Allows(config.RoleAdmin) {
+		return true
+	}
+	if !p.Role.Allows(config.RoleMaintainer) {
+		return false
+	}
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return false
+	}
+	for _, r := range p.Repos {
+		if strings.EqualFold(r, owner) || strings.EqualFold(r, owner+"/"+repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate resolves the bearer token of a request to the admin token or an API key. Keys of a
+// namespace are only accepted for requests to that namespace.
+func (s *Server) authenticate(r *http.Request) *principal {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	if s.cfg.Server.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Server.AdminToken)) == 1 {
+		return &principal{Name: "admin_token", Role: config.RoleAdmin}
+	}
+	for _, key := range s.cfg.Server.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key.Key)) == 1 {
+			return &principal{Name: key.Name, Role: key.Role, Repos: key.Repos}
+		}
+	}
+	if ns := s.cfg.Namespace(namespaceFrom(r.Context())); ns != nil {
+		for _, key := range ns.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(key.Key)) == 1 {
+				return &principal{Name: key.Name, Role: key.Role, Repos: key.Repos, Namespace: ns.Name}
+			}
+		}
+	}
+	return nil
+}
+
+// adminAPIConfigured reports whether any token may access the admin API of a request's namespace.
+func (s *Server) adminAPIConfigured(r *http.Request) bool {
+	if s.cfg.Server.AdminToken != "" || len(s.cfg.Server.APIKeys) > 0 {
+		return true
+	}
+	ns := s.cfg.Namespace(namespaceFrom(r.Context()))
+	return ns != nil && len(ns.APIKeys) > 0
+}
+
+// requireRole restricts access to requests bearing a token with at least the given role.
+// The admin API is disabled entirely when neither an admin token nor API keys are configured.
+func (s *Server) requireRole(role config.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.adminAPIConfigured(r) {
+				http.Error(w, "Admin API not configured", http.StatusNotFound)
+				return
+			}
+
+			p := principalFrom(r.Context())
+			if p == nil {
+				if p = s.authenticate(r); p == nil {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+			}
+			if !p.Role.Allows(role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireOperator restricts access to callers authenticated by requireRole with a token of the
+// whole deployment, rather than of a single namespace.
+func (s *Server) requireOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := principalFrom(r.Context()); p == nil || p.Namespace != "" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
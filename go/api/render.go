@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenderContext is what partial templates of an app are executed with: the app, and the part of
+// it the partial renders, e.g. one of its deployments. Templates create it with the part function,
+// as in {{template "card-deployment" (part $ .MainnetDeployment)}}, so that every partial can
+// label its elements by the app they belong to.
+type RenderContext struct {
+	App  *AppCardData
+	Part any
+}
+
+// newRenderContext returns the context of a part of an app.
+func newRenderContext(app *AppCardData, part any) *RenderContext {
+	return &RenderContext{App: app, Part: part}
+}
+
+// ID returns a document-unique element ID of the app for the given name, e.g. "app-7-details" or
+// "app-7-deployment-mainnet", for labelling elements with aria-labelledby and aria-controls.
+func (c *RenderContext) ID(name string, qualifiers ...string) string {
+	var b strings.Builder
+	b.WriteString("app-")
+	b.WriteString(strconv.FormatInt(c.App.ID, 10))
+	b.WriteString("-")
+	b.WriteString(name)
+	for _, q := range qualifiers {
+		b.WriteString("-")
+		b.WriteString(elementIDPart(q))
+	}
+	return b.String()
+}
+
+// elementIDPart replaces the characters of s that are not valid in element IDs and CSS selectors.
+func elementIDPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// render executes a named template of a localized template set in the language of a locale.
+func render(templates localizedTemplate, loc *Locale, name string, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.For(loc).ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
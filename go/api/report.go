@@ -0,0 +1,262 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// attestationReportVersion is the version of the attestation report format.
+const attestationReportVersion = 1
+
+// AttestationReport is the verification state of an app at the time the report was generated,
+// meant as audit evidence.
+type AttestationReport struct {
+	Version     int                `json:"version"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Registry    string             `json:"registry"`
+	App         ReportApp          `json:"app"`
+	Manifest    *ReportManifest    `json:"manifest,omitempty"` // Nil until the manifest is fetched.
+	Deployments []ReportDeployment `json:"deployments"`
+
+	// Dependency lockfiles at the last verified commit, nil until they were checked.
+	Dependencies *models.DependencyReport `json:"dependencies,omitempty"`
+}
+
+// ReportApp identifies the app a report is about.
+type ReportApp struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	URL       string `json:"url"`
+	GitHubURL string `json:"github_url"`
+	GitRef    string `json:"git_ref"`
+}
+
+// ReportManifest identifies the manifest the report is based on.
+type ReportManifest struct {
+	Path        string     `json:"path"`
+	SHA256      string     `json:"sha256"`
+	FirstSeenAt *time.Time `json:"first_seen_at,omitempty"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"` // Last time a deployment verified against it.
+	TEE         string     `json:"tee,omitempty"`
+}
+
+// ReportDeployment is the verification state of a single deployment.
+type ReportDeployment struct {
+	Name            string          `json:"name"`
+	Network         string          `json:"network,omitempty"`
+	AppID           string          `json:"app_id,omitempty"`
+	Status          string          `json:"status"`
+	VerifiedCommit  string          `json:"verified_commit,omitempty"`
+	VerificationMsg string          `json:"verification_message,omitempty"`
+	CLIVersion      string          `json:"cli_version,omitempty"`
+	BuilderImage    string          `json:"builder_image,omitempty"`
+	LastVerified    *time.Time      `json:"last_verified,omitempty"`
+	VerifiedSince   *time.Time      `json:"verified_since,omitempty"`
+	FirstVerifiedAt *time.Time      `json:"first_verified_at,omitempty"`
+	VerifiedStreak  int64           `json:"verified_streak"` // Successful verifications in a row.
+	FirstChecked    time.Time       `json:"first_checked"`
+	LastChecked     time.Time       `json:"last_checked"`
+	Enclaves        []ReportEnclave `json:"enclaves"`
+
+	// Rules of the registry's verification policy the deployment violates, nil unless verified.
+	PolicyViolations []models.PolicyViolation `json:"policy_violations,omitempty"`
+
+	// Enclave identities expected and built by the last verification, nil unless it failed.
+	Identities *models.VerificationReport `json:"identities,omitempty"`
+}
+
+// ReportEnclave is an enclave identity of the deployment policy. Verification rebuilds the app
+// and checks that it yields exactly these identities, which the policy registers on chain.
+type ReportEnclave struct {
+	ID         string                  `json:"id"`
+	Components []rofl.EnclaveComponent `json:"components,omitempty"`
+}
+
+// reportTime converts a nullable time into an optional report field.
+func reportTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// handleAttestationReport handles GET /api/v1/apps/{id}/attestation-report.
+func (s *Server) handleAttestationReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	app, err := s.getApp(ctx, id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	deps, err := s.db.GetDeploymentsByAppID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get deployments", "app_id", id, "error", err)
+		http.Error(w, "Failed to load deployments", http.StatusInternalServerError)
+		return
+	}
+	var versions []*models.ManifestVersion
+	if app.RoflYAML.Valid && app.RoflYAML.String != "" {
+		if versions, err = s.db.GetManifestVersions(ctx, id); err != nil {
+			s.logger.Error("failed to get manifest versions", "app_id", id, "error", err)
+			http.Error(w, "Failed to load manifest", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	dependencies, err := s.db.GetDependencyReport(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get dependency report", "app_id", id, "error", err)
+		http.Error(w, "Failed to load dependencies", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.baseURL(r)
+	report := newAttestationReport(app, deps, versions, base)
+	report.Dependencies = dependencies
+
+	filename := fmt.Sprintf("attestation-report-%d.json", app.ID)
+	if app.Slug.Valid && app.Slug.String != "" {
+		filename = "attestation-report-" + app.Slug.String + ".json"
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	writeJSON(w, report)
+}
+
+// handleDependencies handles GET /api/v1/apps/{id}/dependencies, returning the digests and
+// summaries of the dependency lockfiles of an app at its last verified commit.
+func (s *Server) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.getApp(r.Context(), id); err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := s.db.GetDependencyReport(r.Context(), id)
+	if err != nil {
+		s.logger.Error("failed to get dependency report", "app_id", id, "error", err)
+		http.Error(w, "Failed to load dependencies", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "Dependencies not checked yet", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, report)
+}
+
+// newAttestationReport builds the attestation report of an app from its stored state.
+func newAttestationReport(app *models.App, deps []*models.Deployment, versions []*models.ManifestVersion, base string) *AttestationReport {
+	report := &AttestationReport{
+		Version:     attestationReportVersion,
+		GeneratedAt: time.Now().UTC(),
+		Registry:    base,
+		App: ReportApp{
+			ID:        app.ID,
+			URL:       base + appPath(app.ID, app.Slug.String),
+			GitHubURL: app.GitHubURL,
+			GitRef:    app.GitRef,
+		},
+		Deployments: []ReportDeployment{},
+	}
+
+	manifest := &rofl.Manifest{}
+	if app.RoflYAML.Valid && app.RoflYAML.String != "" {
+		if parsed, err := rofl.Parse([]byte(app.RoflYAML.String)); err == nil {
+			manifest = parsed
+		}
+		report.Manifest = &ReportManifest{
+			Path: "rofl.yaml",
+			TEE:  manifest.TEE,
+		}
+		if app.ManifestPath.Valid && app.ManifestPath.String != "" {
+			report.Manifest.Path = app.ManifestPath.String
+		}
+		for _, version := range versions {
+			if version.Content == app.RoflYAML.String {
+				report.Manifest.SHA256 = version.ContentHash
+				report.Manifest.FirstSeenAt = reportTime(version.FirstSeenAt, true)
+				report.Manifest.VerifiedAt = reportTime(version.VerifiedAt.Time, version.VerifiedAt.Valid)
+				break
+			}
+		}
+	}
+	report.App.Name = manifest.Name
+	report.App.Version = manifest.Version
+
+	for _, dep := range deps {
+		rd := ReportDeployment{
+			Name:            dep.DeploymentName,
+			Status:          string(dep.Status),
+			VerificationMsg: dep.VerificationMsg.String,
+			CLIVersion:      dep.CLIVersion.String,
+			BuilderImage:    dep.BuilderImage.String,
+			LastVerified:    reportTime(dep.LastVerified.Time, dep.LastVerified.Valid),
+			VerifiedSince:   reportTime(dep.VerifiedSince.Time, dep.VerifiedSince.Valid),
+			FirstVerifiedAt: reportTime(dep.FirstVerifiedAt.Time, dep.FirstVerifiedAt.Valid),
+			VerifiedStreak:  dep.VerifiedStreak,
+			FirstChecked:    dep.CreatedAt.UTC(),
+			LastChecked:     dep.UpdatedAt.UTC(),
+		}
+		if dep.Status == models.StatusVerified {
+			rd.VerifiedCommit = dep.CommitSHA.String
+			rd.PolicyViolations = dep.PolicyViolations
+		}
+		if dep.Status == models.StatusFailed {
+			rd.Identities = dep.VerificationReport
+		}
+		if manifestDep := manifest.Deployments[dep.DeploymentName]; manifestDep != nil {
+			rd.Network = manifestDep.Network
+			rd.AppID = manifestDep.AppID
+		}
+		rd.Enclaves = reportEnclaves(manifest, dep.DeploymentName)
+		report.Deployments = append(report.Deployments, rd)
+	}
+	sort.Slice(report.Deployments, func(i, j int) bool {
+		return report.Deployments[i].Name < report.Deployments[j].Name
+	})
+
+	return report
+}
+
+// reportEnclaves returns the enclave identities of a deployment's policy in a manifest.
+func reportEnclaves(manifest *rofl.Manifest, deploymentName string) []ReportEnclave {
+	enclaves := []ReportEnclave{}
+	deployment := manifest.Deployments[deploymentName]
+	if deployment == nil {
+		return enclaves
+	}
+	for _, enc := range deployment.Policy.Enclaves {
+		if enc == "" {
+			continue
+		}
+		enclave := ReportEnclave{ID: enc}
+		if decoded, err := rofl.DecodeEnclaveIdentity(enc); err == nil {
+			enclave.Components = decoded.Components(manifest.TEE)
+		}
+		enclaves = append(enclaves, enclave)
+	}
+	return enclaves
+}
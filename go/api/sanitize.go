@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// safeURL returns a link from a manifest if it is an absolute http or https URL, or "" otherwise.
+// Templates escape URLs by context, but would still link to e.g. data: or credential-bearing URLs.
+func safeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" || u.User != nil {
+		return ""
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// plainText strips characters of manifest text that alter how the surrounding page reads: control
+// characters other than newlines and tabs, and bidirectional formatting characters, which could
+// make displayed text differ from what it is.
+func plainText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r):
+			return -1
+		default:
+			return r
+		}
+	}, strings.ToValidUTF8(s, ""))
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// contentSecurityPolicy returns the built-in Content-Security-Policy of the pages. Pages render
+// manifest content chosen by app authors, so they may only run the script files of the registry
+// itself, never inline scripts or event handlers, and load scripts from CDNs only for the assets
+// that are not vendored.
+func contentSecurityPolicy(cfg *config.Config) string {
+	scripts := []string{"'self'"}
+	for _, name := range assets.Missing() {
+		if u, err := url.Parse(assetFallbacks[name]); err == nil {
+			scripts = append(scripts, u.Scheme+"://"+u.Host)
+		}
+	}
+	images := []string{"'self'", "data:", "https:"}
+	if u, err := url.Parse(cfg.Branding.LogoURL); err == nil && u.Scheme == "http" {
+		images = append(images, "http://"+u.Host)
+	}
+	frameAncestors := "'none'"
+	if cfg.Server.SecurityHeaders.FrameOptions == config.FrameOptionsSameOrigin {
+		frameAncestors = "'self'"
+	}
+
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src " + strings.Join(scripts, " "),
+		"style-src 'self' 'unsafe-inline'",
+		"img-src " + strings.Join(images, " "),
+		// The manual verification form reads manifests straight from GitHub.
+		"connect-src 'self' https://api.github.com https://raw.githubusercontent.com",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors " + frameAncestors,
+	}, "; ")
+}
+
+// securityHeaders sets the configured security headers on responses. The embed widget is meant to
+// be framed by other sites and sets its own policy, so it gets no X-Frame-Options.
+func (s *Server) securityHeaders(next http.Handler) http.Handler {
+	cfg := &s.cfg.Server.SecurityHeaders
+	if cfg.Disabled {
+		return next
+	}
+
+	policy := cfg.ContentSecurityPolicy
+	if policy == "" {
+		policy = contentSecurityPolicy(s.cfg)
+	}
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("Content-Security-Policy", policy)
+		if !strings.HasPrefix(r.URL.Path, "/embed/") {
+			header.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		header.Set("X-Content-Type-Options", "nosniff")
+		// Browsers ignore the header on plain HTTP responses, so it is safe to always send.
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
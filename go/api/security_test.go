@@ -0,0 +1,41 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// inlineScript matches inline event handler attributes and script elements without a src, other
+// than data blocks.
+var inlineScript = regexp.MustCompile(`\son[a-z]+\s*=|<script(\s+type="text/javascript")?\s*>`)
+
+// Test that the built-in policy allows no inline scripts, and that the pages need none.
+func TestContentSecurityPolicy(t *testing.T) {
+	policy := contentSecurityPolicy(&config.Config{})
+	for _, directive := range strings.Split(policy, "; ") {
+		if strings.HasPrefix(directive, "script-src ") && strings.Contains(directive, "'unsafe-inline'") {
+			t.Errorf("Scripts may run inline: %s", directive)
+		}
+	}
+
+	for name, page := range map[string]string{
+		"index":          indexTemplate,
+		"app card":       appCardTemplate,
+		"manifest diff":  manifestDiffTemplate,
+		"sort controls":  sortControlsTemplate,
+		"backend status": backendStatusTemplate,
+		"page meta":      pageMetaTemplate,
+		"embed":          embedTemplate,
+		"admin login":    adminLoginTemplate,
+		"admin":          adminPageTemplate,
+		"maintainer":     maintainerPageTemplate,
+		"watch":          watchPageTemplate,
+	} {
+		if match := inlineScript.FindString(page); match != "" {
+			t.Errorf("Template %s has an inline script: %q", name, match)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/db"
+)
+
+// handleRobots handles GET /robots.txt.
+func (s *Server) handleRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintf(w, "User-agent: *\nDisallow: /htmx/\nDisallow: /api/admin/\nDisallow: /api/verify\n\nSitemap: %s/sitemap.xml\n", s.baseURL(r))
+}
+
+// sitemapURLSet is the root element of a sitemap.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a single sitemap entry.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// handleSitemap handles GET /sitemap.xml, listing the index and the page of every app.
+func (s *Server) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	apps, err := s.db.ListApps(r.Context(), namespaceFrom(r.Context()), db.AppOrderID)
+	if err != nil {
+		http.Error(w, "Failed to load apps", http.StatusInternalServerError)
+		return
+	}
+
+	base := s.baseURL(r)
+	sitemap := sitemapURLSet{URLs: []sitemapURL{{Loc: base + "/"}}}
+	for _, app := range apps {
+		sitemap.URLs = append(sitemap.URLs, sitemapURL{
+			Loc:     base + appPath(app.ID, app.Slug.String),
+			LastMod: app.UpdatedAt.UTC().Format(time.DateOnly),
+		})
+	}
+
+	out, err := xml.MarshalIndent(sitemap, "", "  ")
+	if err != nil {
+		s.logger.Error("failed to render sitemap", "error", err)
+		http.Error(w, "Failed to render sitemap", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(out)
+}
+
+// SoftwareApplication is schema.org structured data describing an app, embedded in app pages as JSON-LD.
+type SoftwareApplication struct {
+	Context             string        `json:"@context"`
+	Type                string        `json:"@type"`
+	Name                string        `json:"name"`
+	Description         string        `json:"description,omitempty"`
+	URL                 string        `json:"url"`
+	Image               string        `json:"image,omitempty"`
+	SoftwareVersion     string        `json:"softwareVersion,omitempty"`
+	ApplicationCategory string        `json:"applicationCategory"`
+	OperatingSystem     string        `json:"operatingSystem"`
+	License             string        `json:"license,omitempty"`
+	Author              *SchemaPerson `json:"author,omitempty"`
+	SameAs              []string      `json:"sameAs,omitempty"`
+}
+
+// SchemaPerson is a schema.org Person.
+type SchemaPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// newSoftwareApplication builds the structured data of an app page.
+func newSoftwareApplication(data *AppCardData, pageURL, imageURL string) *SoftwareApplication {
+	app := &SoftwareApplication{
+		Context:             "https://schema.org",
+		Type:                "SoftwareApplication",
+		Name:                data.Name,
+		Description:         data.Description,
+		URL:                 pageURL,
+		Image:               imageURL,
+		SoftwareVersion:     data.Version,
+		ApplicationCategory: "DeveloperApplication",
+		OperatingSystem:     "Oasis ROFL",
+		License:             data.License,
+		SameAs:              []string{data.GitHubURL},
+	}
+	if data.Author != "" {
+		app.Author = &SchemaPerson{Type: "Person", Name: data.Author}
+	}
+	if data.Homepage != "" && data.Homepage != data.GitHubURL {
+		app.SameAs = append(app.SameAs, data.Homepage)
+	}
+	return app
+}
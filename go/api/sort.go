@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/ptrus/rofl-attestations/db"
+)
+
+// sortOption is an app order offered in the sort controls.
+type sortOption struct {
+	Value    db.AppOrder
+	Label    string // Message key of the label.
+	Selected bool
+}
+
+// sortOptions lists the app orders offered in the sort controls, in display order.
+var sortOptions = []sortOption{
+	{Value: db.AppOrderID, Label: "sort.registry_order"},
+	{Value: db.AppOrderFeatured, Label: "sort.featured"},
+	{Value: db.AppOrderRecent, Label: "sort.recent"},
+	{Value: db.AppOrderName, Label: "sort.name"},
+	{Value: db.AppOrderStars, Label: "sort.stars"},
+	{Value: db.AppOrderStreak, Label: "sort.streak"},
+}
+
+// appOrder returns the app order requested by the sort parameter, or the configured
+// ordering if the parameter is not set.
+func (s *Server) appOrder(r *http.Request) (db.AppOrder, error) {
+	sort := r.URL.Query().Get("sort")
+	if sort == "" {
+		return db.AppOrder(s.cfg.Apps.Ordering), nil
+	}
+	if order := db.AppOrder(sort); order.Valid() {
+		return order, nil
+	}
+	return "", fmt.Errorf("invalid sort %q", sort)
+}
+
+// handleSortControls handles GET /htmx/sort, rendering the sort controls of the app list.
+func (s *Server) handleSortControls(w http.ResponseWriter, r *http.Request) {
+	options := make([]sortOption, len(sortOptions))
+	for i, option := range sortOptions {
+		option.Selected = option.Value == db.AppOrder(s.cfg.Apps.Ordering)
+		options[i] = option
+	}
+
+	var buf bytes.Buffer
+	if err := s.sortTemplate.For(localeFor(r)).Execute(&buf, options); err != nil {
+		s.logger.Error("failed to render sort controls", "error", err)
+		http.Error(w, "Failed to render sort controls", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write(buf.Bytes())
+}
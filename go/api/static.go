@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// assetFallbacks are the CDN URLs of the third-party assets, loaded from there until they are
+// vendored into static/ with `make assets`.
+var assetFallbacks = map[string]string{
+	"htmx.min.js":    "https://unpkg.com/htmx.org@2.0.8",
+	"tailwind.js":    "https://cdn.tailwindcss.com",
+	"js-yaml.min.js": "https://cdn.jsdelivr.net/npm/js-yaml@4.1.0/dist/js-yaml.min.js",
+}
+
+// staticAsset is an embedded static file.
+type staticAsset struct {
+	content     []byte
+	contentType string
+	hash        string // Hex prefix of the SHA-256 of the content.
+	hashedName  string // Name with the hash before the extension, e.g. "htmx.min.1a2b3c4d5e6f.js".
+}
+
+// staticAssets are the embedded static files, by name and by hashed name.
+type staticAssets struct {
+	byName       map[string]*staticAsset
+	byHashedName map[string]*staticAsset
+}
+
+// assets are the static files served under /static/.
+var assets = mustLoadAssets()
+
+// assetFuncs are the template functions of pages that load static assets.
+var assetFuncs = template.FuncMap{"asset": assets.URL}
+
+// mustLoadAssets loads the embedded static files.
+func mustLoadAssets() *staticAssets {
+	entries, err := staticFiles.ReadDir("static")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read static assets: %v", err))
+	}
+
+	loaded := &staticAssets{
+		byName:       make(map[string]*staticAsset, len(entries)),
+		byHashedName: make(map[string]*staticAsset, len(entries)),
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		content, err := fs.ReadFile(staticFiles, path.Join("static", name))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read static asset %s: %v", name, err))
+		}
+		sum := sha256.Sum256(content)
+		asset := &staticAsset{
+			content:     content,
+			contentType: mime.TypeByExtension(path.Ext(name)),
+			hash:        hex.EncodeToString(sum[:6]),
+		}
+		if asset.contentType == "" {
+			asset.contentType = "application/octet-stream"
+		}
+		ext := path.Ext(name)
+		asset.hashedName = strings.TrimSuffix(name, ext) + "." + asset.hash + ext
+		loaded.byName[name] = asset
+		loaded.byHashedName[asset.hashedName] = asset
+	}
+	return loaded
+}
+
+// URL returns the URL of a static asset, by its hashed name so that it can be cached
+// indefinitely, or its CDN URL if it is not vendored.
+func (a *staticAssets) URL(name string) (string, error) {
+	if asset, ok := a.byName[name]; ok {
+		return "/static/" + asset.hashedName, nil
+	}
+	if url, ok := assetFallbacks[name]; ok {
+		return url, nil
+	}
+	return "", fmt.Errorf("unknown static asset %q", name)
+}
+
+// Missing returns the third-party assets that are loaded from a CDN, because they are not vendored.
+func (a *staticAssets) Missing() []string {
+	var missing []string
+	for name := range assetFallbacks {
+		if _, ok := a.byName[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// handleStatic handles GET /static/{file}, serving an embedded static file. Files requested by
+// their hashed name never change, so they are cached indefinitely.
+func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "file")
+	cacheControl := "public, max-age=31536000, immutable"
+	asset, ok := assets.byHashedName[name]
+	if !ok {
+		if asset, ok = assets.byName[name]; !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		cacheControl = "no-cache"
+	}
+
+	w.Header().Set("Content-Type", asset.contentType)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", `"`+asset.hash+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(asset.content))
+}
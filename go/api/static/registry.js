@@ -0,0 +1,620 @@
+let roflYamlData = null;
+
+// Handle ref selection change
+function handleRefChange() {
+    const refSelect = document.getElementById('git-ref-select');
+    const customInput = document.getElementById('git-ref-custom-input');
+
+    if (refSelect.value === 'custom') {
+        refSelect.classList.add('hidden');
+        customInput.classList.remove('hidden');
+        customInput.focus();
+    }
+}
+
+// Allow going back from custom input
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('git-ref-select').addEventListener('change', handleRefChange);
+
+    const customInput = document.getElementById('git-ref-custom-input');
+    customInput.addEventListener('blur', function() {
+        // If empty, go back to dropdown
+        if (!this.value.trim()) {
+            const refSelect = document.getElementById('git-ref-select');
+            refSelect.value = 'auto';
+            refSelect.classList.remove('hidden');
+            this.classList.add('hidden');
+        }
+    });
+
+    // Add escape key to cancel
+    customInput.addEventListener('keydown', function(e) {
+        if (e.key === 'Escape') {
+            const refSelect = document.getElementById('git-ref-select');
+            refSelect.value = 'auto';
+            refSelect.classList.remove('hidden');
+            this.classList.add('hidden');
+            this.value = '';
+        }
+    });
+});
+
+// Get the current git ref value
+function getCurrentGitRef() {
+    const refSelect = document.getElementById('git-ref-select');
+    const customInput = document.getElementById('git-ref-custom-input');
+
+    if (refSelect.value === 'custom') {
+        return customInput.value.trim() || 'main';
+    } else if (refSelect.value === 'auto') {
+        return 'auto';
+    } else {
+        return refSelect.value;
+    }
+}
+
+// Fetch rofl.yaml from GitHub
+async function fetchRoflYaml() {
+    const urlInput = document.getElementById('github-url-input');
+    const statusDiv = document.getElementById('verify-status');
+    const deploymentSelector = document.getElementById('deployment-selector');
+    const deploymentSelect = document.getElementById('deployment-select');
+    const fetchBtn = document.getElementById('fetch-btn');
+
+    const githubUrl = urlInput.value.trim();
+    let gitRef = getCurrentGitRef();
+
+    if (!githubUrl || !githubUrl.match(/https:\/\/github\.com\/[^/]+\/[^/]+/)) {
+        statusDiv.className = 'text-sm text-red-600';
+        statusDiv.textContent = 'Please enter a valid GitHub repository URL';
+        statusDiv.classList.remove('hidden');
+        return;
+    }
+
+    // Extract owner/repo from URL
+    const match = githubUrl.match(/https:\/\/github\.com\/([^/]+)\/([^/]+)/);
+    if (!match) return;
+
+    const owner = match[1];
+    const repo = match[2].replace(/\.git$/, '');
+
+    // Reset state
+    statusDiv.className = 'text-sm text-slate-600';
+    statusDiv.textContent = 'Fetching rofl.yaml...';
+    statusDiv.classList.remove('hidden');
+    deploymentSelector.classList.add('hidden');
+    fetchBtn.disabled = true;
+
+    try {
+        let rawUrl, response;
+
+        // Handle auto mode - check which branch is more active
+        if (gitRef === 'auto') {
+            statusDiv.textContent = 'Detecting most recent branch...';
+
+            // Fetch GitHub API to check both branches
+            const [mainInfo, masterInfo] = await Promise.all([
+                fetch(`https://api.github.com/repos/${owner}/${repo}/branches/main`).then(r => r.ok ? r.json() : null),
+                fetch(`https://api.github.com/repos/${owner}/${repo}/branches/master`).then(r => r.ok ? r.json() : null)
+            ]);
+
+            // Determine which branch to use based on existence and recent commits
+            let selectedBranch = 'main'; // default
+
+            if (mainInfo && masterInfo) {
+                // Both exist, pick the one with more recent commit
+                const mainDate = new Date(mainInfo.commit.commit.committer.date);
+                const masterDate = new Date(masterInfo.commit.commit.committer.date);
+                selectedBranch = mainDate > masterDate ? 'main' : 'master';
+                statusDiv.textContent = `Using ${selectedBranch} branch (more recent commits)...`;
+            } else if (masterInfo && !mainInfo) {
+                selectedBranch = 'master';
+                statusDiv.textContent = 'Using master branch...';
+            } else {
+                statusDiv.textContent = 'Using main branch...';
+            }
+
+            gitRef = selectedBranch;
+            rawUrl = `https://raw.githubusercontent.com/${owner}/${repo}/${gitRef}/rofl.yaml`;
+            response = await fetch(rawUrl);
+        } else {
+            // Try the specific ref
+            statusDiv.textContent = `Fetching from ${gitRef} branch...`;
+            rawUrl = `https://raw.githubusercontent.com/${owner}/${repo}/${gitRef}/rofl.yaml`;
+            response = await fetch(rawUrl);
+        }
+
+        if (!response.ok) {
+            throw new Error(`rofl.yaml not found in repository at ref '${gitRef}'. Make sure the file exists at the root of the branch.`);
+        }
+
+        // Store the detected git ref for form submission
+        detectedGitRef = gitRef;
+
+        // Update the dropdown to show the detected branch
+        const refSelect = document.getElementById('git-ref-select');
+        const customInput = document.getElementById('git-ref-custom-input');
+
+        if (refSelect.value === 'auto' || customInput.classList.contains('hidden')) {
+            // Only update if we're in auto mode or using dropdown
+            if (gitRef === 'main' || gitRef === 'master') {
+                refSelect.value = gitRef;
+            } else {
+                // Custom branch detected, show in custom input
+                refSelect.value = 'custom';
+                refSelect.classList.add('hidden');
+                customInput.classList.remove('hidden');
+                customInput.value = gitRef;
+            }
+        }
+
+        const yamlText = await response.text();
+
+        // Parse YAML (simple parser for deployments)
+        roflYamlData = parseRoflYaml(yamlText);
+
+        console.log('Parsed ROFL YAML:', roflYamlData);
+
+        if (!roflYamlData.deployments || roflYamlData.deployments.length === 0) {
+            throw new Error('No deployments found in rofl.yaml');
+        }
+
+        // Populate deployment selector
+        // Keep only the placeholder option.
+        deploymentSelect.length = 1;
+        roflYamlData.deployments.forEach(dep => {
+            const option = document.createElement('option');
+            option.value = dep.name;
+            option.textContent = `${dep.name} (${dep.network || 'unknown network'})`;
+            deploymentSelect.appendChild(option);
+        });
+
+        statusDiv.className = 'text-sm text-emerald-600';
+        statusDiv.textContent = `✓ Found ${roflYamlData.deployments.length} deployment(s). Select one to verify.`;
+        deploymentSelector.classList.remove('hidden');
+
+    } catch (error) {
+        statusDiv.className = 'text-sm text-red-600';
+        statusDiv.textContent = `Error: ${error.message}`;
+    } finally {
+        fetchBtn.disabled = false;
+    }
+}
+
+// Parse YAML using js-yaml library to extract deployments and enclave identities
+function parseRoflYaml(yamlText) {
+    try {
+        const data = jsyaml.load(yamlText);
+        const deployments = [];
+
+        if (data.deployments) {
+            for (const [name, config] of Object.entries(data.deployments)) {
+                const deployment = {
+                    name: name,
+                    network: config.network || 'unknown',
+                    enclaveIdentities: []
+                };
+
+                // Extract enclave IDs from policy.enclaves
+                if (config.policy && config.policy.enclaves) {
+                    deployment.enclaveIdentities = config.policy.enclaves
+                        .map(e => e.id)
+                        .filter(id => id);
+                }
+
+                deployments.push(deployment);
+            }
+        }
+
+        return { deployments };
+    } catch (error) {
+        console.error('Failed to parse YAML:', error);
+        throw new Error('Invalid rofl.yaml format');
+    }
+}
+
+// Store the detected git ref after fetching
+let detectedGitRef = null;
+
+// Live verification functionality
+document.getElementById('verify-form').addEventListener('submit', async function(e) {
+    e.preventDefault();
+
+    const urlInput = document.getElementById('github-url-input');
+    const deploymentSelect = document.getElementById('deployment-select');
+    const statusDiv = document.getElementById('verify-status');
+    const resultDiv = document.getElementById('verify-result');
+    const submitBtn = e.target.querySelector('button[type="submit"]');
+
+    const githubUrl = urlInput.value.trim();
+    const gitRef = detectedGitRef || getCurrentGitRef();
+    const deploymentName = deploymentSelect.value;
+
+    if (!deploymentName) {
+        statusDiv.className = 'text-sm text-red-600';
+        statusDiv.textContent = 'Please select a deployment';
+        statusDiv.classList.remove('hidden');
+        return;
+    }
+
+    // Reset state
+    statusDiv.className = 'text-sm text-slate-600';
+    statusDiv.innerHTML = 'Submitting verification request...';
+    statusDiv.classList.remove('hidden');
+    resultDiv.classList.add('hidden');
+    submitBtn.disabled = true;
+    submitBtn.textContent = 'Verifying...';
+
+    try {
+        // Submit verification request. Network errors are retried with the same
+        // idempotency key, so a request that did reach the server isn't built twice.
+        // crypto.randomUUID is only available in secure contexts, unlike getRandomValues.
+        const idempotencyKey = crypto.randomUUID ? crypto.randomUUID()
+            : Array.from(crypto.getRandomValues(new Uint8Array(16)), b => b.toString(16).padStart(2, '0')).join('');
+        let response;
+        for (let attempt = 1; ; attempt++) {
+            try {
+                response = await fetch('/api/verify', {
+                    method: 'POST',
+                    headers: {
+                        'Content-Type': 'application/json',
+                        'Idempotency-Key': idempotencyKey
+                    },
+                    body: JSON.stringify({
+                        github_url: githubUrl,
+                        git_ref: gitRef,
+                        deployment_name: deploymentName
+                    })
+                });
+                break;
+            } catch (err) {
+                if (attempt >= 3) throw err;
+                await new Promise(resolve => setTimeout(resolve, 1000 * attempt));
+            }
+        }
+
+        if (!response.ok) {
+            throw new Error(`HTTP ${response.status}: ${await response.text()}`);
+        }
+
+        const data = await response.json();
+
+        // Poll for results
+        statusDiv.innerHTML = `
+            <div class="flex items-center gap-2">
+                <svg class="animate-spin h-4 w-4 text-primary" xmlns="http://www.w3.org/2000/svg" fill="none" viewBox="0 0 24 24">
+                    <circle class="opacity-25" cx="12" cy="12" r="10" stroke="currentColor" stroke-width="4"></circle>
+                    <path class="opacity-75" fill="currentColor" d="M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 014 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z"></path>
+                </svg>
+                <span>Verification in progress... (~30 seconds)</span>
+            </div>
+        `;
+
+        const result = await pollVerificationResults(data.task_id);
+
+        // Show results
+        displayVerificationResult(result);
+        statusDiv.classList.add('hidden');
+
+    } catch (error) {
+        statusDiv.className = 'text-sm text-red-600';
+        statusDiv.textContent = `Error: ${error.message}`;
+    } finally {
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Verify Deployment';
+    }
+});
+
+async function pollVerificationResults(taskId) {
+    const maxAttempts = 200; // 10 minutes max
+    const pollInterval = 3000; // 3 seconds
+
+    for (let i = 0; i < maxAttempts; i++) {
+        await new Promise(resolve => setTimeout(resolve, pollInterval));
+
+        const response = await fetch(`/api/verify/${taskId}/results`);
+
+        if (response.status === 202) {
+            // Still in progress, continue polling
+            continue;
+        }
+
+        if (!response.ok) {
+            throw new Error(`Failed to check status: HTTP ${response.status}`);
+        }
+
+        // Got results (HTTP 200)
+        return await response.json();
+    }
+
+    throw new Error('Verification timeout - please try again later');
+}
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function displayVerificationResult(result) {
+    const resultDiv = document.getElementById('verify-result');
+
+    // Get enclave identities from the selected deployment
+    const deploymentSelect = document.getElementById('deployment-select');
+    const selectedDeploymentName = deploymentSelect.value;
+    const selectedDeployment = roflYamlData?.deployments?.find(d => d.name === selectedDeploymentName);
+    const enclaveIdentities = selectedDeployment?.enclaveIdentities || [];
+
+    console.log('Selected deployment:', selectedDeploymentName);
+    console.log('Selected deployment data:', selectedDeployment);
+    console.log('Enclave identities:', enclaveIdentities);
+
+    // Build result HTML
+    let html = '<div class="border-2 rounded-lg p-6">';
+
+    if (result.verified) {
+        html += '<div class="flex items-center gap-3 mb-4">';
+        html += '<svg class="w-8 h-8 text-emerald-600" fill="none" stroke="currentColor" viewBox="0 0 24 24">';
+        html += '<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z"></path>';
+        html += '</svg>';
+        html += '<h3 class="text-2xl font-bold text-emerald-900">Verification Successful!</h3>';
+        html += '</div>';
+        html += '<div class="bg-emerald-50 border border-emerald-200 rounded-lg p-4">';
+        html += '<p class="text-emerald-800 font-semibold mb-2">Built enclave identities MATCH on-chain measurements</p>';
+
+        // Display matching enclave IDs
+        if (enclaveIdentities.length > 0) {
+            html += '<div class="mt-3 space-y-2">';
+            enclaveIdentities.forEach(id => {
+                html += `<div class="flex items-center gap-2 text-sm">`;
+                html += '<svg class="w-4 h-4 text-emerald-600 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">';
+                html += '<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z"></path>';
+                html += '</svg>';
+                html += `<code class="font-mono text-xs text-emerald-900 break-all">${escapeHtml(id)}</code>`;
+                html += '</div>';
+            });
+            html += '</div>';
+        }
+        html += '</div>';
+    } else {
+        html += '<div class="flex items-center gap-3 mb-4">';
+        html += '<svg class="w-8 h-8 text-red-600" fill="none" stroke="currentColor" viewBox="0 0 24 24">';
+        html += '<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 14l2-2m0 0l2-2m-2 2l-2-2m2 2l2 2m7-2a9 9 0 11-18 0 9 9 0 0118 0z"></path>';
+        html += '</svg>';
+        html += '<h3 class="text-2xl font-bold text-red-900">Verification Failed</h3>';
+        html += '</div>';
+        html += '<div class="bg-red-50 border border-red-200 rounded-lg p-4">';
+        html += '<p class="text-red-800 font-semibold mb-2">Enclave measurements do NOT match on-chain deployments</p>';
+
+        // Display mismatched enclave IDs
+        if (enclaveIdentities.length > 0) {
+            html += '<div class="mt-3 space-y-2">';
+            enclaveIdentities.forEach(id => {
+                html += `<div class="flex items-center gap-2 text-sm">`;
+                html += '<svg class="w-4 h-4 text-red-600 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">';
+                html += '<path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"></path>';
+                html += '</svg>';
+                html += `<code class="font-mono text-xs text-red-900 break-all">${escapeHtml(id)}</code>`;
+                html += '</div>';
+            });
+            html += '</div>';
+        }
+
+        // Display error details if available
+        if (result.stdout || result.stderr) {
+            html += '<div class="mt-4 space-y-2">';
+            if (result.stdout) {
+                html += '<div class="bg-slate-100 border border-slate-300 rounded p-3">';
+                html += '<p class="text-xs font-semibold text-slate-700 mb-1">Build Output:</p>';
+                html += `<pre class="text-xs text-slate-800 whitespace-pre-wrap font-mono">${escapeHtml(result.stdout)}</pre>`;
+                html += '</div>';
+            }
+            if (result.stderr) {
+                html += '<div class="bg-red-100 border border-red-300 rounded p-3">';
+                html += '<p class="text-xs font-semibold text-red-700 mb-1">Error Output:</p>';
+                html += `<pre class="text-xs text-red-800 whitespace-pre-wrap font-mono">${escapeHtml(result.stderr)}</pre>`;
+                html += '</div>';
+            }
+            html += '</div>';
+        }
+        html += '</div>';
+    }
+
+    if (result.commit_sha) {
+        html += '<div class="mt-4 text-sm text-slate-600">';
+        html += '<span class="font-semibold">Verified Commit:</span> ';
+        html += `<span class="font-mono">${escapeHtml(result.commit_sha)}</span>`;
+        html += '</div>';
+    }
+
+    html += '</div>';
+
+    resultDiv.innerHTML = html;
+    resultDiv.classList.remove('hidden');
+}
+
+// Modal functions. The details of an app are moved into the modal while it is open, and
+// back when it closes, so that element IDs stay unique and htmx bindings keep working.
+let modalDetails = null;
+let modalOpener = null;
+
+function openModal(appId, slug, opener) {
+    const modal = document.getElementById('app-modal');
+    const modalContent = document.getElementById(`modal-content-${appId}`);
+
+    if (modalContent) {
+        if (modalDetails) {
+            restoreModalDetails();
+        } else {
+            modalOpener = opener || document.activeElement;
+        }
+        document.getElementById('modal-body').replaceChildren(...modalContent.childNodes);
+        modalDetails = modalContent;
+        modal.setAttribute('aria-labelledby', modalContent.dataset.title);
+        setBackgroundInert(true);
+        modal.classList.remove('hidden');
+        document.body.style.overflow = 'hidden';
+        document.getElementById('modal-close').focus();
+        // Update URL to the app permalink, which carries link preview metadata.
+        history.replaceState(null, '', slug ? `/apps/${slug}` : `/apps/${appId}`);
+    }
+}
+
+function closeModal() {
+    const modal = document.getElementById('app-modal');
+    if (modal.classList.contains('hidden')) {
+        return;
+    }
+    restoreModalDetails();
+    modal.classList.add('hidden');
+    modal.removeAttribute('aria-labelledby');
+    setBackgroundInert(false);
+    document.body.style.overflow = 'auto';
+    // Return focus to the button that opened the modal.
+    if (modalOpener && modalOpener.isConnected) {
+        modalOpener.focus();
+    }
+    modalOpener = null;
+    // Return to the index URL
+    history.replaceState(null, '', '/');
+}
+
+function restoreModalDetails() {
+    if (modalDetails) {
+        modalDetails.replaceChildren(...document.getElementById('modal-body').childNodes);
+        modalDetails = null;
+    }
+}
+
+// Keep keyboard focus and screen readers inside the modal while it is open.
+function setBackgroundInert(inert) {
+    for (const el of document.body.children) {
+        if (el.id !== 'app-modal' && el.tagName !== 'SCRIPT') {
+            el.inert = inert;
+        }
+    }
+}
+
+// Close modal on Escape key
+document.addEventListener('keydown', function(e) {
+    if (e.key === 'Escape') {
+        closeModal();
+    }
+});
+
+// Toggle manual verification details
+function toggleManualVerification() {
+    const details = document.getElementById('manual-verification-details');
+    const btn = document.getElementById('manual-verify-toggle');
+
+    details.classList.toggle('hidden');
+    btn.textContent = details.classList.contains('hidden') ? btn.dataset.show : btn.dataset.hide;
+}
+
+// Copy to clipboard
+function copyToClipboard(text, button) {
+    navigator.clipboard.writeText(text).then(() => {
+        const originalHTML = button.innerHTML;
+        button.innerHTML = '<svg class="w-3 h-3" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M5 13l4 4L19 7"></path></svg>';
+        button.classList.add('text-emerald-600');
+        setTimeout(() => {
+            button.innerHTML = originalHTML;
+            button.classList.remove('text-emerald-600');
+        }, 1500);
+    }).catch(err => {
+        console.error('Failed to copy:', err);
+    });
+}
+
+// Show or hide the section a toggle button controls.
+function toggleSection(btn, container) {
+    container.classList.toggle('hidden');
+    const hidden = container.classList.contains('hidden');
+    btn.textContent = hidden ? btn.dataset.show : btn.dataset.hide;
+    btn.setAttribute('aria-expanded', String(!hidden));
+}
+
+// Toggle YAML
+function toggleYaml(btn, appId) {
+    const yamlContainer = document.getElementById(`yaml-${appId}`);
+    if (yamlContainer) {
+        toggleSection(btn, yamlContainer);
+    }
+}
+
+// Toggle compose file
+function toggleCompose(btn, appId) {
+    const composeContainer = document.getElementById(`compose-${appId}`);
+    if (composeContainer) {
+        toggleSection(btn, composeContainer);
+    }
+}
+
+// Toggle manifest changes, loading the diff on first open
+function toggleManifestDiff(btn, appId) {
+    const diffContainer = document.getElementById(`manifest-diff-${appId}`);
+    if (diffContainer) {
+        if (!diffContainer.dataset.loaded) {
+            diffContainer.dataset.loaded = 'true';
+            htmx.ajax('GET', `/htmx/apps/${appId}/manifest/diff`, {target: diffContainer, swap: 'innerHTML'});
+        }
+        toggleSection(btn, diffContainer);
+    }
+}
+
+// Elements name what clicking them does in data-action, instead of inline event handlers, which the
+// Content-Security-Policy does not allow. Clicks are handled on the document, so that elements
+// swapped in by htmx need no handlers of their own.
+const actions = {
+    'toggle-manual-verification': () => toggleManualVerification(),
+    'fetch-manifest': () => fetchRoflYaml(),
+    'open-modal': el => openModal(parseInt(el.dataset.appId), el.dataset.slug, el),
+    'close-modal': () => closeModal(),
+    'copy': el => copyToClipboard(el.dataset.copy, el),
+    'toggle-yaml': el => toggleYaml(el, el.dataset.appId),
+    'toggle-compose': el => toggleCompose(el, el.dataset.appId),
+    'toggle-manifest-diff': el => toggleManifestDiff(el, el.dataset.appId),
+};
+
+document.addEventListener('click', function(e) {
+    const el = e.target.closest('[data-action]');
+    if (el && actions[el.dataset.action]) {
+        actions[el.dataset.action](el);
+    }
+});
+
+// Open the app of a deep link once the apps are shown
+function openDeepLink() {
+    // Check for a permalink (/apps/slug), a legacy permalink (/apps/slug-name-123),
+    // or a legacy deep link hash (#slug-name-123)
+    let ref = '';
+    if (window.location.pathname.startsWith('/apps/')) {
+        ref = decodeURIComponent(window.location.pathname.substring('/apps/'.length));
+    } else if (window.location.hash.length > 1) {
+        ref = window.location.hash.substring(1);
+    }
+    if (ref) {
+        const card = document.querySelector(`.app-card[data-slug="${CSS.escape(ref)}"]`);
+        if (card) {
+            openModal(parseInt(card.dataset.appId), ref);
+        } else {
+            // ID is the last segment after the final hyphen
+            const appId = parseInt(ref.substring(ref.lastIndexOf('-') + 1));
+            const legacyCard = document.getElementById(`card-${appId}`);
+            openModal(appId, legacyCard ? legacyCard.dataset.slug : '');
+        }
+    }
+}
+
+// Apps rendered with the page are shown right away, otherwise the page loads them.
+document.addEventListener('DOMContentLoaded', function() {
+    if (!document.getElementById('apps-container').hasAttribute('hx-get')) {
+        openDeepLink();
+    }
+});
+document.addEventListener('htmx:afterSwap', function(evt) {
+    if (evt.detail.target.id === 'apps-container') {
+        evt.detail.target.removeAttribute('aria-busy');
+        openDeepLink();
+    }
+});
@@ -0,0 +1,31 @@
+// Configures Tailwind with the colors of the registry and applies the theme before the page renders.
+// Both are set in data attributes of the html element.
+(function () {
+    const root = document.documentElement;
+
+    tailwind.config = {
+        darkMode: 'class',
+        theme: {
+            extend: {
+                colors: {
+                    primary: {
+                        DEFAULT: root.dataset.primaryColor,
+                        dark: root.dataset.primaryDark,
+                    },
+                    secondary: {
+                        DEFAULT: root.dataset.secondaryColor,
+                    },
+                }
+            }
+        }
+    };
+
+    if (root.dataset.theme === 'system' && window.matchMedia('(prefers-color-scheme: dark)').matches) {
+        root.classList.add('dark');
+    }
+
+    // Absolute times are shown in the reader's time zone.
+    try {
+        document.cookie = 'tz=' + Intl.DateTimeFormat().resolvedOptions().timeZone + '; path=/; max-age=31536000; samesite=lax';
+    } catch (e) {}
+})();
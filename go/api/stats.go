@@ -0,0 +1,180 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// DurationStats summarizes how long verifications took.
+type DurationStats struct {
+	Count      int     `json:"count"`
+	P50Seconds float64 `json:"p50_seconds"`
+	P95Seconds float64 `json:"p95_seconds"`
+	SumSeconds float64 `json:"sum_seconds"`
+}
+
+// AppDurationStats summarizes how long the verifications of an app took.
+type AppDurationStats struct {
+	AppID int64  `json:"app_id"`
+	Slug  string `json:"slug,omitempty"`
+	DurationStats
+}
+
+// Stats is the response of GET /api/v1/stats, for planning the capacity of the backend.
+type Stats struct {
+	WindowDays               int                `json:"window_days"` // Days of verifications summarized.
+	QueueLength              int                `json:"queue_length"`
+	LastCycleCompletedAt     *time.Time         `json:"last_cycle_completed_at,omitempty"` // Of any worker instance.
+	LastCycleDurationSeconds float64            `json:"last_cycle_duration_seconds"`
+	WorkerHeartbeatAt        *time.Time         `json:"worker_heartbeat_at,omitempty"` // Latest heartbeat of any worker instance.
+	Verifications            DurationStats      `json:"verifications"`
+	Apps                     []AppDurationStats `json:"apps"`
+}
+
+// summarizeDurations computes the percentiles of durations, using the nearest rank.
+func summarizeDurations(durations []time.Duration) DurationStats {
+	stats := DurationStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(rank, 0)].Seconds()
+	}
+	stats.P50Seconds = percentile(0.5)
+	stats.P95Seconds = percentile(0.95)
+	for _, d := range sorted {
+		stats.SumSeconds += d.Seconds()
+	}
+	return stats
+}
+
+// loadStats summarizes the verifications of the apps of a namespace within the retention period
+// of verification jobs.
+func (s *Server) loadStats(ctx context.Context) (*Stats, error) {
+	durations, err := s.db.GetVerificationDurations(ctx, namespaceFrom(ctx), time.Now().Add(-db.VerificationJobRetention))
+	if err != nil {
+		return nil, err
+	}
+
+	var all []time.Duration
+	byApp := make(map[int64][]time.Duration)
+	apps := make(map[int64]*models.VerificationDuration)
+	for _, d := range durations {
+		all = append(all, d.Duration)
+		byApp[d.AppID] = append(byApp[d.AppID], d.Duration)
+		apps[d.AppID] = d
+	}
+
+	stats := &Stats{
+		WindowDays:    int(db.VerificationJobRetention / (24 * time.Hour)),
+		QueueLength:   s.worker.Status().QueueLength,
+		Verifications: summarizeDurations(all),
+		Apps:          make([]AppDurationStats, 0, len(byApp)),
+	}
+
+	// The last cycle is persisted, so that it is known across restarts and replicas.
+	health, err := s.workerHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.LastCycleCompletedAt = health.LastCycleCompletedAt
+	stats.LastCycleDurationSeconds = health.LastCycleSeconds
+	stats.WorkerHeartbeatAt = health.LastHeartbeatAt
+
+	for appID, appDurations := range byApp {
+		stats.Apps = append(stats.Apps, AppDurationStats{
+			AppID:         appID,
+			Slug:          apps[appID].Slug,
+			DurationStats: summarizeDurations(appDurations),
+		})
+	}
+	slices.SortFunc(stats.Apps, func(a, b AppDurationStats) int {
+		return cmp.Compare(a.AppID, b.AppID)
+	})
+	return stats, nil
+}
+
+// handleStats handles GET /api/v1/stats.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.loadStats(r.Context())
+	if err != nil {
+		s.logger.Error("failed to load stats", "error", err)
+		http.Error(w, "Failed to load stats", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// metricLabel escapes a label value of the Prometheus text format.
+var metricLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// handleMetrics handles GET /metrics, exposing the stats in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.loadStats(r.Context())
+	if err != nil {
+		s.logger.Error("failed to load stats", "error", err)
+		http.Error(w, "Failed to load stats", http.StatusInternalServerError)
+		return
+	}
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	value := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	summary := func(name, labels string, d DurationStats) {
+		sep := ""
+		if labels != "" {
+			sep = ","
+		}
+		if d.Count > 0 {
+			fmt.Fprintf(&b, "%s{%s%squantile=\"0.5\"} %s\n", name, labels, sep, value(d.P50Seconds))
+			fmt.Fprintf(&b, "%s{%s%squantile=\"0.95\"} %s\n", name, labels, sep, value(d.P95Seconds))
+		}
+		if labels != "" {
+			labels = "{" + labels + "}"
+		}
+		fmt.Fprintf(&b, "%s_sum%s %s\n%s_count%s %d\n", name, labels, value(d.SumSeconds), name, labels, d.Count)
+	}
+
+	metric("rofl_registry_verification_duration_seconds", "summary",
+		fmt.Sprintf("Time from submitting a verification to the backend until its result, over the last %d days.", stats.WindowDays))
+	summary("rofl_registry_verification_duration_seconds", "", stats.Verifications)
+
+	metric("rofl_registry_app_verification_duration_seconds", "summary",
+		fmt.Sprintf("Time from submitting a verification of an app to the backend until its result, over the last %d days.", stats.WindowDays))
+	for _, app := range stats.Apps {
+		labels := fmt.Sprintf(`app_id="%d",slug="%s"`, app.AppID, metricLabel.Replace(app.Slug))
+		summary("rofl_registry_app_verification_duration_seconds", labels, app.DurationStats)
+	}
+
+	metric("rofl_registry_queue_length", "gauge", "Apps left to verify in the current cycle.")
+	fmt.Fprintf(&b, "rofl_registry_queue_length %d\n", stats.QueueLength)
+
+	metric("rofl_registry_last_cycle_duration_seconds", "gauge", "Duration of the last completed verification cycle.")
+	fmt.Fprintf(&b, "rofl_registry_last_cycle_duration_seconds %s\n", value(stats.LastCycleDurationSeconds))
+
+	if stats.LastCycleCompletedAt != nil {
+		metric("rofl_registry_last_cycle_completed_timestamp_seconds", "gauge", "Unix time the last verification cycle completed.")
+		fmt.Fprintf(&b, "rofl_registry_last_cycle_completed_timestamp_seconds %d\n", stats.LastCycleCompletedAt.Unix())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write([]byte(b.String()))
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/worker"
+)
+
+// ServiceStatus is the response of GET /api/status.
+type ServiceStatus struct {
+	Backend worker.BackendHealth `json:"backend"`
+}
+
+// handleStatus handles GET /api/status.
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, ServiceStatus{Backend: s.worker.BackendHealth()})
+}
+
+// handleStatusHTML handles GET /htmx/status, rendering the backend status shown in the page footer.
+func (s *Server) handleStatusHTML(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := s.statusTemplate.For(localeFor(r)).Execute(&buf, s.worker.BackendHealth()); err != nil {
+		s.logger.Error("failed to render backend status", "error", err)
+		http.Error(w, "Failed to render status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Readiness statuses.
+const (
+	readinessOK          = "ok"
+	readinessDegraded    = "degraded"    // Serving, but the worker fell behind its schedule.
+	readinessUnavailable = "unavailable" // Not able to serve, e.g. the database is unreachable.
+)
+
+// Readiness is the response of GET /healthz/ready.
+type Readiness struct {
+	Status  string        `json:"status"`
+	Reasons []string      `json:"reasons,omitempty"`
+	Worker  *WorkerHealth `json:"worker,omitempty"` // Nil when the worker is disabled.
+}
+
+// WorkerHealth is the state of the verification cycles of all worker instances.
+type WorkerHealth struct {
+	LastHeartbeatAt      *time.Time `json:"last_heartbeat_at,omitempty"`
+	LastCycleCompletedAt *time.Time `json:"last_cycle_completed_at,omitempty"`
+	LastCycleSeconds     float64    `json:"last_cycle_seconds"` // Duration of the last completed cycle.
+	ExpectedCycleSeconds float64    `json:"expected_cycle_seconds"`
+	Overdue              bool       `json:"overdue"` // No cycle completed within cycle_overdue_factor expected cycles.
+}
+
+// workerHealth summarizes the heartbeats of the worker instances. Instances share the apps to
+// verify, so the registry is healthy as long as any of them keeps completing cycles.
+func (s *Server) workerHealth(ctx context.Context) (*WorkerHealth, error) {
+	heartbeats, err := s.db.GetWorkerHeartbeats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &WorkerHealth{}
+	for _, h := range heartbeats {
+		if health.LastHeartbeatAt == nil || h.HeartbeatAt.After(*health.LastHeartbeatAt) {
+			health.LastHeartbeatAt = &h.HeartbeatAt
+		}
+		if h.LastCycleCompletedAt != nil && (health.LastCycleCompletedAt == nil || h.LastCycleCompletedAt.After(*health.LastCycleCompletedAt)) {
+			health.LastCycleCompletedAt = h.LastCycleCompletedAt
+			health.LastCycleSeconds = h.LastCycleDuration.Seconds()
+			health.ExpectedCycleSeconds = h.ExpectedCycleDuration.Seconds()
+		}
+	}
+	if health.LastCycleCompletedAt != nil {
+		allowed := time.Duration(health.ExpectedCycleSeconds*float64(s.cfg.Worker.CycleOverdueFactor)) * time.Second
+		health.Overdue = time.Since(*health.LastCycleCompletedAt) > allowed
+	}
+	return health, nil
+}
+
+// handleReady handles GET /healthz/ready. It fails with 503 when the registry cannot serve, and
+// reports degraded health when the worker stopped heartbeating or fell behind its schedule.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready, code := s.readiness(r.Context()), http.StatusOK
+	if ready.Status == readinessUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(ready)
+}
+
+// readiness checks whether the registry can serve, and whether its worker keeps up.
+func (s *Server) readiness(ctx context.Context) *Readiness {
+	if err := s.db.PingContext(ctx); err != nil {
+		s.logger.Error("readiness check failed", "error", err)
+		return &Readiness{Status: readinessUnavailable, Reasons: []string{"database unreachable"}}
+	}
+	ready := &Readiness{Status: readinessOK}
+	if !s.cfg.Worker.Enabled {
+		return ready
+	}
+
+	health, err := s.workerHealth(ctx)
+	if err != nil {
+		s.logger.Error("failed to load worker heartbeats", "error", err)
+		return &Readiness{Status: readinessUnavailable, Reasons: []string{"failed to load worker heartbeats"}}
+	}
+	ready.Worker = health
+	if health.LastHeartbeatAt == nil || time.Since(*health.LastHeartbeatAt) > 3*worker.HeartbeatInterval {
+		ready.Reasons = append(ready.Reasons, "worker heartbeat missing")
+	}
+	if health.Overdue {
+		ready.Reasons = append(ready.Reasons, "verification cycle overdue")
+	}
+	if len(ready.Reasons) > 0 {
+		ready.Status = readinessDegraded
+	}
+	return ready
+}
@@ -1,69 +1,239 @@
 package api
 
 import (
-	"bytes"
-	"database/sql"
 	"fmt"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ptrus/rofl-attestations/github"
 	"github.com/ptrus/rofl-attestations/models"
 	"github.com/ptrus/rofl-attestations/rofl"
 )
 
 const (
-	notYetVerified = "Not yet verified"
 	statusVerified = "verified"
 )
 
-// slugify converts a string to a URL-safe slug.
-func slugify(s string) string {
-	// Convert to lowercase
-	s = strings.ToLower(s)
-	// Replace spaces and special chars with hyphens
-	reg := regexp.MustCompile(`[^a-z0-9]+`)
-	s = reg.ReplaceAllString(s, "-")
-	// Remove leading/trailing hyphens
-	s = strings.Trim(s, "-")
-	return s
-}
-
 // EnclaveIdentity holds enclave identity information.
 type EnclaveIdentity struct {
-	Type  string
-	Value string
+	Type       string
+	Value      string
+	Components []rofl.EnclaveComponent // Decoded measurements, empty if the identity could not be decoded.
 }
 
 // DeploymentInfo holds deployment details for display.
 type DeploymentInfo struct {
-	Name     string
-	Network  string
-	AppID    string
-	Enclaves []EnclaveIdentity
+	Name        string
+	Network     string
+	AppID       string
+	AppIDError  string // Set if the app ID is not a valid rofl1... address.
+	ExplorerURL string // Explorer page of the app, empty if there is none.
+	Enclaves    []EnclaveIdentity
+
+	HiddenEnclaves int // Enclave identities not shown, see maxCardEnclaves.
+}
+
+// ContractInfo holds a related smart contract for display.
+type ContractInfo struct {
+	Label       string
+	Network     string
+	Address     string
+	Error       string // Set if the network or address is invalid.
+	ExplorerURL string // Explorer page of the contract, empty if the contract is invalid.
+}
+
+// newContractInfos returns the related contracts declared in a manifest.
+func newContractInfos(contracts []rofl.Contract) []ContractInfo {
+	infos := make([]ContractInfo, 0, len(contracts))
+	for _, contract := range contracts {
+		info := ContractInfo{
+			Label:   plainText(contract.Label),
+			Network: contract.Network,
+			Address: contract.Address,
+		}
+		switch err := rofl.ValidateContractAddress(contract.Address); {
+		case err != nil:
+			info.Error = err.Error()
+		case contract.Network != "mainnet" && contract.Network != "testnet":
+			info.Error = fmt.Sprintf("unsupported network %q", contract.Network)
+		default:
+			info.ExplorerURL = fmt.Sprintf("https://explorer.oasis.io/%s/sapphire/address/%s", contract.Network, contract.Address)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// OwnerInfo is the GitHub user or organization owning an app's repository.
+type OwnerInfo struct {
+	Login        string
+	Name         string // Display name, falls back to the login.
+	AvatarURL    string
+	URL          string
+	Organization bool
+	Verified     bool               // Organization has a domain verified by GitHub.
+	Match        *github.OwnerMatch // Owner compared with the manifest author and homepage, nil if nothing is claimed.
+}
+
+// newOwnerInfo returns the repository owner of an app, or nil if it was not fetched yet.
+func newOwnerInfo(app *models.App, manifest *rofl.Manifest) *OwnerInfo {
+	if !app.OwnerLogin.Valid {
+		return nil
+	}
+	owner := &OwnerInfo{
+		Login:        app.OwnerLogin.String,
+		Name:         app.OwnerName.String,
+		URL:          "https://github.com/" + app.OwnerLogin.String,
+		Organization: app.OwnerType.String == "Organization",
+		Verified:     app.OwnerVerified,
+	}
+	owner.Match = github.MatchOwner(&github.Owner{
+		Login: app.OwnerLogin.String,
+		Name:  app.OwnerName.String,
+		Blog:  app.OwnerBlog.String,
+		Email: app.OwnerEmail.String,
+	}, manifest.Author, manifest.Homepage)
+	if owner.Name == "" {
+		owner.Name = owner.Login
+	}
+	if strings.HasPrefix(app.OwnerAvatarURL.String, "https://") {
+		owner.AvatarURL = app.OwnerAvatarURL.String
+	}
+	return owner
 }
 
 // DeploymentStatus holds verification status for a deployment.
 type DeploymentStatus struct {
 	Name            string
-	Status          string // "verified", "pending", "failed"
+	Status          string // "verified", "pending", "failed", "stale"
 	CommitSHA       string
 	CommitSHAShort  string
+	CommitTag       string // Tag pointing at the verified commit, empty if none.
+	CommitLabel     string // e.g. "v1.4.2 (3fa9c2d)", or the short commit SHA if it has no tag.
 	VerificationMsg string
-	LastVerified    string
+	LastVerified    Timestamp
+	VerifiedStreak  string // e.g. "Continuously verified for 94 days", empty unless verified.
+	FirstVerified   string // Date of the first successful verification, empty if never verified.
 	EnclaveIDs      []string
+	HiddenEnclaves  int    // Enclave identities not shown, see maxCardEnclaves.
+	EnclavesURL     string // Fragment listing all enclave identities, set if some are hidden.
+	ExplorerURL     string // Explorer page of the app, empty if there is none.
+	LogURL          string // Build log of the last verification, empty if none is stored.
+	ProvenanceURL   string // SLSA provenance of the verified build, empty unless verified.
+	CLIVersion      string // oasis-cli version of the last verification, empty if unknown.
+	BuilderImage    string // Builder image of the last verification, empty if unknown.
+	VerifyCommands  string // Commands reproducing a successful verification locally, empty otherwise.
+
+	LiveChecked            bool      // Whether the on-chain policy was cross-checked against the verified build.
+	LiveInstances          int64     // Active instances at the last cross-check.
+	LiveCheckedAt          Timestamp // Time of the last cross-check.
+	LiveUnverifiedEnclaves []string  // Identities admitted on chain that are not in the verified build.
+
+	PolicyViolations []models.PolicyViolation // Rules of the operator's verification policy that are not met.
+
+	Identities []IdentityComparison // Enclave identities expected and built, set if verification failed on a mismatch.
+}
+
+// IdentityComparison compares an enclave identity a deployment was expected to build with the one
+// it built.
+type IdentityComparison struct {
+	Source     string // Where the expected identity comes from, e.g. "On-chain policy".
+	Expected   string // Empty if the built identity was not expected.
+	Actual     string // Empty if the expected identity was not built.
+	Match      bool
+	Components []ComponentComparison // Measurements of the identities, empty if they could not be decoded.
+}
+
+// ComponentComparison compares a measurement of an expected enclave identity with the one built.
+type ComponentComparison struct {
+	Name        string
+	Description string
+	Expected    string // Hex, empty if there is no expected identity.
+	Actual      string // Hex, empty if there is no built identity.
+	Match       bool
+}
+
+// newIdentityComparisons returns the comparisons of a verification report, with the measurements
+// of the identities named according to the TEE type. It returns nil unless an identity differs.
+func newIdentityComparisons(loc *Locale, report *models.VerificationReport, tee string) []IdentityComparison {
+	if report == nil {
+		return nil
+	}
+
+	var comparisons []IdentityComparison
+	var mismatch bool
+	for _, enclave := range report.Enclaves {
+		source := loc.T("identities.source_manifest")
+		if enclave.Source == models.IdentitySourceOnChain {
+			source = loc.T("identities.source_onchain")
+		}
+		comparison := IdentityComparison{
+			Source:   source,
+			Expected: enclave.Expected,
+			Actual:   enclave.Actual,
+			Match:    enclave.Expected == enclave.Actual,
+		}
+		mismatch = mismatch || !comparison.Match
+
+		var expected, actual []rofl.EnclaveComponent
+		if decoded, err := rofl.DecodeEnclaveIdentity(enclave.Expected); err == nil {
+			expected = decoded.Components(tee)
+		}
+		if decoded, err := rofl.DecodeEnclaveIdentity(enclave.Actual); err == nil {
+			actual = decoded.Components(tee)
+		}
+		for i := range max(len(expected), len(actual)) {
+			var component ComponentComparison
+			if i < len(expected) {
+				component.Name, component.Description, component.Expected = expected[i].Name, expected[i].Description, expected[i].Hex
+			}
+			if i < len(actual) {
+				component.Name, component.Description, component.Actual = actual[i].Name, actual[i].Description, actual[i].Hex
+			}
+			component.Match = component.Expected == component.Actual
+			comparison.Components = append(comparison.Components, component)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	if !mismatch {
+		return nil
+	}
+	return comparisons
+}
+
+// ComposeImage holds a container image reference extracted from the compose file.
+type ComposeImage struct {
+	Service        string
+	Name           string
+	Tag            string
+	Digest         string
+	ResolvedDigest string // Digest a mutable tag resolved to at verification time.
+	ResolvedAt     Timestamp
 }
 
 // AppCardData holds the data for rendering an app card.
 type AppCardData struct {
 	ID                int64
 	Name              string
-	Slug              string // URL-safe slug for the app name
+	Slug              string // Unique permalink slug, empty until assigned.
+	Featured          bool
+	HasLogo           bool
 	Version           string
 	Description       string
+	DescTruncated     bool // Only the start of the description is shown.
 	GitHubURL         string
 	Author            string
+	Owner             *OwnerInfo // Owner of the repository, nil until fetched.
+	ForkOf            string     // Repository the app's repository was forked from, empty if it is not a fork.
+	RepoStatus        string     // models.RepoArchived or models.RepoDeleted if the repository is no longer maintained.
+	Unmaintained      string     // Why the repository is no longer maintained, empty if it is.
+	Watchable         bool       // Whether visitors can subscribe to status changes by email.
 	License           string
+	LicenseOSI        bool   // License is an OSI-approved SPDX expression.
+	LicenseError      string // Why the license is not a valid SPDX expression, empty if it is.
 	TEE               string
 	Kind              string
 	Repository        string
@@ -78,6 +248,7 @@ type AppCardData struct {
 	Networks          []string
 	NetworksStr       string
 	Deployments       []DeploymentInfo
+	Contracts         []ContractInfo // Smart contracts the app serves.
 	Builder           string
 	Firmware          string
 	Kernel            string
@@ -85,553 +256,956 @@ type AppCardData struct {
 	ContainerRuntime  string
 	ContainerCompose  string
 	RoflYAML          string
+	RoflYAMLTruncated bool   // Only the start of the manifest is shown.
+	ManifestURL       string // Raw manifest.
+	ManifestPath      string // Filename the manifest was found under.
+	ComposeYAML       string
+	ComposeTruncated  bool // Only an excerpt of the compose file is shown.
+	ComposeURL        string
+	ComposeCommitSHA  string
+	ComposeImages     []ComposeImage
+	Readme            []ReadmeBlock
+	ReadmeCommitSHA   string
+	LiveAttestation   string      // When a live instance last attested a verified identity, empty if never.
+	LiveProbeError    string      // Why the last attestation probe failed, empty if it succeeded.
+	DomainVerified    string      // Homepage domain proven to reference the app, empty if not.
+	DomainMethod      string      // How the domain was proven: "dns" or "well-known".
+	DomainError       string      // Why the last domain check failed, empty if it succeeded.
+	Uptime            *UptimeInfo // Share of successful verifications, nil if there were none recently.
+}
+
+// ManifestFile returns the file name of the manifest, without the directory it was found in.
+func (d *AppCardData) ManifestFile() string {
+	if d.ManifestPath == "" {
+		return "rofl.yaml"
+	}
+	return path.Base(d.ManifestPath)
+}
+
+// ReadmeBlock is a block of a README excerpt.
+type ReadmeBlock struct {
+	Kind string // "heading", "item", or "paragraph"
+	Text string
+}
+
+// readmeBlocks splits a README excerpt, as produced by github.ReadmeExcerpt, into blocks.
+func readmeBlocks(excerpt string) []ReadmeBlock {
+	var blocks []ReadmeBlock
+	for _, block := range strings.Split(excerpt, "\n\n") {
+		switch {
+		case block == "":
+		case strings.HasPrefix(block, "# "):
+			blocks = append(blocks, ReadmeBlock{Kind: "heading", Text: strings.TrimPrefix(block, "# ")})
+		case strings.HasPrefix(block, "- "):
+			blocks = append(blocks, ReadmeBlock{Kind: "item", Text: strings.TrimPrefix(block, "- ")})
+		default:
+			blocks = append(blocks, ReadmeBlock{Kind: "paragraph", Text: block})
+		}
+	}
+	return blocks
 }
 
 var appCardTemplate = `<!-- App Card: {{.Name}} -->
-<div class="app-card bg-white border border-slate-200 rounded-lg p-6 shadow-sm hover:shadow-md transition-shadow h-full flex flex-col"
+{{template "card" .}}
+{{template "details" .}}
+{{define "card"}}
+{{$ctx := part . nil}}
+<article class="app-card bg-white border border-slate-200 rounded-lg p-6 shadow-sm hover:shadow-md transition-shadow h-full flex flex-col"
      data-status="{{.Status}}"
      data-tee="{{.TEE}}"
      data-networks="{{.NetworksStr}}"
+     data-license-osi="{{.LicenseOSI}}"
      data-name="{{.Name}}"
      data-app-id="{{.ID}}"
-     id="card-{{.ID}}">
+     data-slug="{{.Slug}}"
+     id="card-{{.ID}}"
+     aria-labelledby="{{$ctx.ID "name"}}">
 
-    <div class="flex justify-between items-start mb-4">
+    <header class="flex justify-between items-start mb-4">
+        <div class="flex items-start gap-4">
+        {{if .HasLogo}}<img src="/api/apps/{{.ID}}/logo.png" alt="" loading="lazy" class="w-12 h-12 rounded-md object-contain border border-slate-200 bg-white">{{end}}
         <div>
-            <h3 class="text-2xl font-bold text-slate-900 mb-2">{{.Name}}</h3>
+            <h3 id="{{$ctx.ID "name"}}" class="text-2xl font-bold text-slate-900 mb-2">{{.Name}}</h3>
             <span class="inline-block px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-sm font-semibold">{{.Version}}</span>
+            {{if .Featured}}<span class="inline-block px-3 py-1 bg-primary/5 border border-primary/20 text-primary-dark rounded-md text-sm font-semibold">{{t "card.featured"}}</span>{{end}}
+            {{if .ForkOf}}<span class="inline-block px-3 py-1 bg-amber-50 border border-amber-200 text-amber-700 rounded-md text-sm font-semibold" title="{{t "card.forked_from" .ForkOf}}">{{t "card.fork"}}<span class="sr-only">: {{t "card.forked_from" .ForkOf}}</span></span>{{end}}
+            {{if .Unmaintained}}<span class="inline-block px-3 py-1 bg-slate-100 border border-slate-300 text-slate-600 rounded-md text-sm font-semibold" title="{{.Unmaintained}}">{{t "card.unmaintained"}}<span class="sr-only">: {{.Unmaintained}}</span></span>{{end}}
+        </div>
+        </div>
+        {{template "status-badge" .Status}}
+    </header>
+
+    <ul class="flex flex-wrap gap-2 mb-4" aria-label="{{t "a11y.platforms"}}">
+        <li class="px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-xs font-semibold uppercase">{{.TEE}}</li>
+        {{range .Networks}}
+        <li class="px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-xs font-medium">{{network .}}</li>
+        {{end}}
+    </ul>
+
+    <p class="text-slate-600 mb-6 leading-relaxed" style="height: 4.5rem; overflow: hidden; display: -webkit-box; -webkit-line-clamp: 3; -webkit-box-orient: vertical;">
+        {{if .Description}}{{.Description}}{{else}}<span class="text-slate-400 italic">{{t "card.no_description"}}</span>{{end}}
+    </p>
+
+    <footer class="border-t border-slate-200 pt-4 mt-auto">
+        <!-- Verification Status -->
+        <div class="text-sm text-slate-600 mb-3">
+            {{if .MainnetDeployment}}
+            {{template "card-deployment" (part . .MainnetDeployment)}}
+            {{else if .OtherDeployments}}
+            {{template "card-deployment" (part . (index .OtherDeployments 0))}}
+            {{else}}
+            <div><span class="text-slate-500 font-medium">{{t "status.not_yet_verified"}}</span></div>
+            {{end}}
+        </div>
+
+        {{with .Uptime}}{{template "card-uptime" .}}{{end}}
+
+        {{template "card-links" .}}
+
+        <!-- Enclave IDs / Status Box -->
+        {{if .MainnetDeployment}}
+        {{template "card-deployment-box" .MainnetDeployment}}
+        {{else if .OtherDeployments}}
+        {{template "card-deployment-box" (index .OtherDeployments 0)}}
+        {{else}}
+        <div class="bg-slate-50 border border-slate-200 rounded-md p-3 text-xs mt-3">
+            <div class="text-slate-600 text-center">{{t "status.not_yet_verified"}}</div>
         </div>
-        {{if eq .Status "verified"}}
+        {{end}}
+    </footer>
+</article>
+{{end}}
+
+{{define "icon-check"}}<svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M5 13l4 4L19 7"></path></svg>{{end}}
+{{define "icon-clock"}}<svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"></path></svg>{{end}}
+{{define "icon-cross"}}<svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"></path></svg>{{end}}
+{{define "icon-external"}}<svg class="w-3 h-3 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 6H6a2 2 0 00-2 2v10a2 2 0 002 2h10a2 2 0 002-2v-4M14 4h6m0 0v6m0-6L10 14"></path></svg>{{end}}
+{{define "new-tab"}}<span class="sr-only"> {{t "a11y.new_tab"}}</span>{{end}}
+
+{{define "status-badge"}}
+        {{if eq . "verified"}}
         <div class="flex items-center gap-2 px-4 py-2 bg-emerald-50 border border-emerald-200 text-emerald-700 rounded-lg font-semibold text-sm">
-            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
                 <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z"></path>
             </svg>
-            Verified
+            {{t "status.verified"}}
         </div>
-        {{else if eq .Status "pending"}}
+        {{else if eq . "pending"}}
         <div class="flex items-center gap-2 px-4 py-2 bg-amber-50 border border-amber-200 text-amber-700 rounded-lg font-semibold text-sm">
-            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
                 <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"></path>
             </svg>
-            Pending
+            {{t "status.pending"}}
+        </div>
+        {{else if eq . "stale"}}
+        <div class="flex items-center gap-2 px-4 py-2 bg-orange-50 border border-orange-200 text-orange-700 rounded-lg font-semibold text-sm">
+            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
+                <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 9v2m0 4h.01m-6.938 4h13.856c1.54 0 2.502-1.667 1.732-3L13.732 4c-.77-1.333-2.694-1.333-3.464 0L3.34 16c-.77 1.333.192 3 1.732 3z"></path>
+            </svg>
+            {{t "status.stale"}}
         </div>
         {{else}}
         <div class="flex items-center gap-2 px-4 py-2 bg-red-50 border border-red-200 text-red-700 rounded-lg font-semibold text-sm">
-            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
                 <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 14l2-2m0 0l2-2m-2 2l-2-2m2 2l2 2m7-2a9 9 0 11-18 0 9 9 0 0118 0z"></path>
             </svg>
-            Failed
+            {{t "status.failed"}}
         </div>
         {{end}}
-    </div>
+{{end}}
 
-    <div class="flex flex-wrap gap-2 mb-4">
-        <span class="px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-xs font-semibold uppercase">{{.TEE}}</span>
-        {{range .Networks}}
-        {{if eq . "mainnet"}}
-        <span class="px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-xs font-medium">Mainnet</span>
-        {{else}}
-        <span class="px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-xs font-medium">Testnet</span>
-        {{end}}
-        {{end}}
-    </div>
-
-    <div class="text-slate-600 mb-6 leading-relaxed" style="height: 4.5rem; overflow: hidden; display: -webkit-box; -webkit-line-clamp: 3; -webkit-box-orient: vertical;">
-        {{if .Description}}{{.Description}}{{else}}<span class="text-slate-400 italic">No description available</span>{{end}}
-    </div>
-
-    <div class="border-t border-slate-200 pt-4 mt-auto">
-        <!-- Verification Status -->
-        <div class="text-sm text-slate-600 mb-3">
-            {{if .MainnetDeployment}}
-                {{if and (eq .MainnetDeployment.Status "verified") .MainnetDeployment.CommitSHA}}
+{{define "card-deployment"}}
+                {{$dep := .Part}}
+                {{if and (eq $dep.Status "verified") $dep.CommitSHA}}
                 <div class="flex items-center gap-1.5">
-                    Mainnet:
+                    {{network $dep.Name}}:
                     <span class="text-emerald-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M5 13l4 4L19 7"></path>
-                        </svg>
-                        Verified
+                        {{template "icon-check"}}
+                        {{t "status.verified"}}
                     </span>
-                    <span class="text-slate-900 font-mono text-xs">{{.MainnetDeployment.CommitSHAShort}}</span>
+                    <span class="text-slate-900 font-mono text-xs">{{$dep.CommitLabel}}</span>
                 </div>
-                <div class="text-xs text-slate-500 mt-1">{{.MainnetDeployment.LastVerified}}</div>
-                {{else if eq .MainnetDeployment.Status "pending"}}
+                {{if $dep.VerifiedStreak}}<div class="text-xs text-emerald-700 mt-1">{{$dep.VerifiedStreak}}</div>{{end}}
+                <div class="text-xs text-slate-500 mt-1">{{$dep.LastVerified.HTML}}</div>
+                {{else if eq $dep.Status "pending"}}
                 <div class="flex items-center gap-1.5">
-                    Mainnet:
+                    {{network $dep.Name}}:
                     <span class="text-amber-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"></path>
-                        </svg>
-                        Pending
+                        {{template "icon-clock"}}
+                        {{t "status.pending"}}
                     </span>
                 </div>
-                <div class="text-xs text-slate-500 mt-1">{{.MainnetDeployment.LastVerified}}</div>
-                {{else}}
+                <div class="text-xs text-slate-500 mt-1">{{$dep.LastVerified.HTML}}</div>
+                {{else if eq $dep.Status "stale"}}
                 <div class="flex items-center gap-1.5">
-                    Mainnet:
-                    <span class="text-red-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"></path>
-                        </svg>
-                        Failed
-                    </span>
-                    {{if .MainnetDeployment.CommitSHAShort}}
-                    <span class="text-slate-900 font-mono text-xs">{{.MainnetDeployment.CommitSHAShort}}</span>
+                    {{network $dep.Name}}:
+                    <span class="text-orange-700 font-medium">{{t "status.stale"}}</span>
+                    {{if $dep.CommitSHAShort}}
+                    <span class="text-slate-900 font-mono text-xs">{{$dep.CommitLabel}}</span>
                     {{end}}
                 </div>
-                <div class="text-xs text-slate-500 mt-1">{{.MainnetDeployment.LastVerified}}</div>
-                {{end}}
-            {{else if .OtherDeployments}}
-                {{$first := index .OtherDeployments 0}}
-                {{if and (eq $first.Status "verified") $first.CommitSHA}}
-                <div class="flex items-center gap-1.5">
-                    {{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}}:
-                    <span class="text-emerald-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M5 13l4 4L19 7"></path>
-                        </svg>
-                        Verified
-                    </span>
-                    <span class="text-slate-900 font-mono text-xs">{{$first.CommitSHAShort}}</span>
-                </div>
-                <div class="text-xs text-slate-500 mt-1">{{$first.LastVerified}}</div>
-                {{else if eq $first.Status "pending"}}
-                <div class="flex items-center gap-1.5">
-                    {{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}}:
-                    <span class="text-amber-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4l3 3m6-3a9 9 0 11-18 0 9 9 0 0118 0z"></path>
-                        </svg>
-                        Pending
-                    </span>
-                </div>
-                <div class="text-xs text-slate-500 mt-1">{{$first.LastVerified}}</div>
+                <div class="text-xs text-slate-500 mt-1">{{with tparts "card.last_verified"}}{{index . 0}}{{$dep.LastVerified.HTML}}{{index . 1}}{{end}}</div>
                 {{else}}
                 <div class="flex items-center gap-1.5">
-                    {{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}}:
+                    {{network $dep.Name}}:
                     <span class="text-red-700 font-medium inline-flex items-center gap-1">
-                        <svg class="w-3.5 h-3.5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"></path>
-                        </svg>
-                        Failed
+                        {{template "icon-cross"}}
+                        {{t "status.failed"}}
                     </span>
-                    {{if $first.CommitSHAShort}}
-                    <span class="text-slate-900 font-mono text-xs">{{$first.CommitSHAShort}}</span>
+                    {{if $dep.CommitSHAShort}}
+                    <span class="text-slate-900 font-mono text-xs">{{$dep.CommitLabel}}</span>
                     {{end}}
                 </div>
-                <div class="text-xs text-slate-500 mt-1">{{$first.LastVerified}}</div>
+                <div class="text-xs text-slate-500 mt-1">{{$dep.LastVerified.HTML}}</div>
                 {{end}}
-            {{else}}
-            <div><span class="text-slate-500 font-medium">Not yet verified</span></div>
-            {{end}}
+{{end}}
+
+{{define "card-uptime"}}
+        <!-- Uptime -->
+        <div class="flex items-center gap-2 mb-3">
+            <svg class="h-4 w-24 flex-shrink-0" viewBox="0 0 {{.Days}} 16" preserveAspectRatio="none" role="img" aria-label="{{.Summary}}">
+                {{range .Bars}}<rect x="{{.X}}" y="{{.Y}}" width="0.8" height="{{.Height}}" class="{{.Class}}"><title>{{.Title}}</title></rect>{{end}}
+            </svg>
+            <span class="text-xs text-slate-600" title="{{.Summary}}">{{t "uptime.short" .Percent}}</span>
         </div>
+{{end}}
 
+{{define "card-links"}}
         <!-- Links and Button -->
         <div class="flex flex-wrap gap-3 items-center">
             {{if .Homepage}}
             <a href="{{.Homepage}}"
                target="_blank"
-               onclick="event.stopPropagation()"
-               class="text-blue-600 hover:text-blue-800 hover:underline text-xs font-medium flex items-center gap-1">
-                <svg class="w-3.5 h-3.5 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+               rel="noopener noreferrer"
+               class="text-primary hover:text-primary-dark hover:underline text-xs font-medium flex items-center gap-1">
+                <svg class="w-3.5 h-3.5 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
                     <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M3.055 11H5a2 2 0 012 2v1a2 2 0 002 2 2 2 0 012 2v2.945M8 3.935V5.5A2.5 2.5 0 0010.5 8h.5a2 2 0 012 2 2 2 0 104 0 2 2 0 012-2h1.064M15 20.488V18a2 2 0 012-2h3.064M21 12a9 9 0 11-18 0 9 9 0 0118 0z"></path>
                 </svg>
-                <span>Website</span>
-                <svg class="w-3 h-3 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 6H6a2 2 0 00-2 2v10a2 2 0 002 2h10a2 2 0 002-2v-4M14 4h6m0 0v6m0-6L10 14"></path>
-                </svg>
+                <span>{{t "card.website"}}</span>
+                {{if .DomainVerified}}<span class="text-emerald-700" title="{{t "card.domain_verified"}}"><span aria-hidden="true">✓</span><span class="sr-only">{{t "card.domain_verified"}}</span></span>{{end}}
+                {{template "icon-external"}}{{template "new-tab"}}
             </a>
             {{end}}
-            {{if .Repository}}
-            <a href="{{.Repository}}"
-               target="_blank"
-               onclick="event.stopPropagation()"
-               class="text-blue-600 hover:text-blue-800 hover:underline text-xs font-medium flex items-center gap-1 max-w-[200px] truncate">
-                <span class="truncate">{{.Repository}}</span>
-                <svg class="w-3 h-3 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 6H6a2 2 0 00-2 2v10a2 2 0 002 2h10a2 2 0 002-2v-4M14 4h6m0 0v6m0-6L10 14"></path>
-                </svg>
-            </a>
-            {{else}}
-            <a href="{{.GitHubURL}}"
+            <a href="{{with .Repository}}{{.}}{{else}}{{.GitHubURL}}{{end}}"
                target="_blank"
-               onclick="event.stopPropagation()"
-               class="text-blue-600 hover:text-blue-800 hover:underline text-xs font-medium flex items-center gap-1 max-w-[200px] truncate">
-                <span class="truncate">{{.GitHubURL}}</span>
-                <svg class="w-3 h-3 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 6H6a2 2 0 00-2 2v10a2 2 0 002 2h10a2 2 0 002-2v-4M14 4h6m0 0v6m0-6L10 14"></path>
-                </svg>
+               rel="noopener noreferrer"
+               class="text-primary hover:text-primary-dark hover:underline text-xs font-medium flex items-center gap-1 max-w-[200px] truncate">
+                <span class="truncate">{{with .Repository}}{{.}}{{else}}{{.GitHubURL}}{{end}}</span>
+                {{template "icon-external"}}{{template "new-tab"}}
             </a>
-            {{end}}
-            <button onclick="openModal({{.ID}}, '{{.Slug}}')"
+            <button type="button"
+                    data-action="open-modal" data-app-id="{{.ID}}" data-slug="{{.Slug}}"
+                    aria-haspopup="dialog"
+                    aria-controls="app-modal"
                     class="px-4 py-2 bg-slate-900 hover:bg-slate-800 text-white rounded-lg font-semibold text-sm transition-colors whitespace-nowrap ml-auto">
-                Show Details
+                {{t "card.show_details"}}<span class="sr-only">: {{.Name}}</span>
             </button>
         </div>
+{{end}}
 
-        <!-- Enclave IDs / Status Box -->
-        {{if .MainnetDeployment}}
-            {{if and (eq .MainnetDeployment.Status "verified") .MainnetDeployment.EnclaveIDs}}
+{{define "card-deployment-box"}}
+            {{if and (eq .Status "verified") .EnclaveIDs}}
             <div class="bg-emerald-50 border border-emerald-200 rounded-md p-3 text-xs mt-3">
-                <div class="font-semibold text-emerald-900 mb-2">Mainnet Enclave IDs:</div>
-                <div class="space-y-1">
-                    {{range .MainnetDeployment.EnclaveIDs}}
-                    <div class="font-mono text-emerald-800 break-all text-xs">{{.}}</div>
-                    {{end}}
-                </div>
+                <div class="font-semibold text-emerald-900 mb-2">{{t "card.enclave_ids" (network .Name)}}</div>
+                <div class="space-y-1">{{template "card-enclave-ids" .}}</div>
             </div>
             {{else}}
             <div class="bg-slate-50 border border-slate-200 rounded-md p-3 text-xs mt-3">
-                {{if eq .MainnetDeployment.Status "pending"}}
-                <div class="text-slate-600 text-center">Mainnet verification pending</div>
-                {{else if eq .MainnetDeployment.Status "failed"}}
-                <div class="text-red-800 font-semibold mb-1">Mainnet verification failed</div>
-                {{if .MainnetDeployment.VerificationMsg}}
-                <div class="text-slate-600 text-xs leading-relaxed line-clamp-3">{{.MainnetDeployment.VerificationMsg}}</div>
+                {{if eq .Status "pending"}}
+                <div class="text-slate-600 text-center">{{t "card.verification_pending" (network .Name)}}</div>
+                {{else if eq .Status "stale"}}
+                <div class="text-orange-800 font-semibold mb-1">{{t "card.verification_stale" (network .Name)}}</div>
+                <div class="text-slate-600 text-xs leading-relaxed">{{t "card.stale_explanation"}}</div>
+                {{else if eq .Status "failed"}}
+                <div class="text-red-800 font-semibold mb-1">{{t "card.verification_failed" (network .Name)}}</div>
+                {{if .VerificationMsg}}
+                <div class="text-slate-600 text-xs leading-relaxed line-clamp-3">{{.VerificationMsg}}</div>
                 {{end}}
-                <div class="text-slate-500 text-xs mt-2 italic">See details for more information</div>
+                <div class="text-slate-500 text-xs mt-2 italic">{{t "card.see_details"}}</div>
                 {{end}}
             </div>
             {{end}}
-        {{else if .OtherDeployments}}
-            {{$first := index .OtherDeployments 0}}
-            {{if and (eq $first.Status "verified") $first.EnclaveIDs}}
-            <div class="bg-emerald-50 border border-emerald-200 rounded-md p-3 text-xs mt-3">
-                <div class="font-semibold text-emerald-900 mb-2">{{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}} Enclave IDs:</div>
-                <div class="space-y-1">
-                    {{range $first.EnclaveIDs}}
-                    <div class="font-mono text-emerald-800 break-all text-xs">{{.}}</div>
-                    {{end}}
-                </div>
-            </div>
-            {{else}}
-            <div class="bg-slate-50 border border-slate-200 rounded-md p-3 text-xs mt-3">
-                {{if eq $first.Status "pending"}}
-                <div class="text-slate-600 text-center">{{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}} verification pending</div>
-                {{else if eq $first.Status "failed"}}
-                <div class="text-red-800 font-semibold mb-1">{{if eq $first.Name "testnet"}}Testnet{{else}}{{$first.Name}}{{end}} verification failed</div>
-                {{if $first.VerificationMsg}}
-                <div class="text-slate-600 text-xs leading-relaxed line-clamp-3">{{$first.VerificationMsg}}</div>
-                {{end}}
-                <div class="text-slate-500 text-xs mt-2 italic">See details for more information</div>
-                {{end}}
-            </div>
-            {{end}}
-        {{else}}
-        <div class="bg-slate-50 border border-slate-200 rounded-md p-3 text-xs mt-3">
-            <div class="text-slate-600 text-center">Not yet verified</div>
-        </div>
-        {{end}}
+{{end}}
+
+{{define "details"}}
+{{$ctx := part . nil}}
+<!-- Modal Content for {{.Name}} -->
+<div id="modal-content-{{.ID}}" class="hidden" data-title="{{$ctx.ID "title"}}">
+    {{template "details-header" $ctx}}
+
+    <div class="space-y-4">
+        {{template "details-verification" $ctx}}
+        {{if .Readme}}{{template "details-readme" $ctx}}{{end}}
+        {{template "details-info" $ctx}}
+        {{if or .Memory .CPUs .StorageKind}}{{template "details-resources" $ctx}}{{end}}
+        {{if or .Builder .Firmware .Kernel .Stage2 .ContainerRuntime}}{{template "details-artifacts" $ctx}}{{end}}
+        {{if .Deployments}}{{template "details-deployments" $ctx}}{{end}}
+        {{if .Contracts}}{{template "details-contracts" $ctx}}{{end}}
+        {{if .RoflYAML}}{{template "details-manifest" $ctx}}{{end}}
+        {{if .ComposeYAML}}{{template "details-compose" $ctx}}{{end}}
     </div>
 </div>
+{{end}}
 
-<!-- Modal Content for {{.Name}} -->
-<div id="modal-content-{{.ID}}" class="hidden">
-    <!-- Modal Header -->
-    <div class="mb-6">
+{{define "copy-button"}}
+<button type="button" data-action="copy" data-copy="{{.}}"
+        class="flex-shrink-0 p-1 hover:bg-slate-200 rounded transition-colors text-slate-600 hover:text-slate-900"
+        title="{{t "details.copy"}}" aria-label="{{t "details.copy"}}">
+    <svg class="w-3 h-3" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true" focusable="false">
+        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M8 16H6a2 2 0 01-2-2V6a2 2 0 012-2h8a2 2 0 012 2v2m-6 12h8a2 2 0 002-2v-8a2 2 0 00-2-2h-8a2 2 0 00-2 2v8a2 2 0 002 2z"></path>
+    </svg>
+</button>
+{{end}}
+
+{{define "details-header"}}
+    {{$app := .App}}
+    <header class="mb-6">
         <div class="flex justify-between items-start">
             <div>
-                <h2 class="text-3xl font-bold text-slate-900 mb-2">{{.Name}}</h2>
+                <h2 id="{{.ID "title"}}" class="text-3xl font-bold text-slate-900 mb-2">{{$app.Name}}</h2>
                 <div class="flex items-center gap-3">
-                    <span class="inline-block px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-sm font-semibold">{{.Version}}</span>
-                    {{if eq .Status "verified"}}
+                    <span class="inline-block px-3 py-1 bg-slate-100 text-slate-700 rounded-md text-sm font-semibold">{{$app.Version}}</span>
+                    {{if eq $app.Status "verified"}}
                     <span class="inline-flex items-center gap-2 px-3 py-1 bg-emerald-50 border border-emerald-200 text-emerald-700 rounded-md text-sm font-semibold">
-                        <span>✓</span> Verified
+                        <span aria-hidden="true">✓</span> {{t "status.verified"}}
                     </span>
-                    {{else if eq .Status "pending"}}
+                    {{else if eq $app.Status "pending"}}
                     <span class="inline-flex items-center gap-2 px-3 py-1 bg-amber-50 border border-amber-200 text-amber-700 rounded-md text-sm font-semibold">
-                        <span>⏳</span> Pending
+                        <span aria-hidden="true">⏳</span> {{t "status.pending"}}
+                    </span>
+                    {{else if eq $app.Status "stale"}}
+                    <span class="inline-flex items-center gap-2 px-3 py-1 bg-orange-50 border border-orange-200 text-orange-700 rounded-md text-sm font-semibold">
+                        <span aria-hidden="true">⚠</span> {{t "status.stale"}}
                     </span>
                     {{else}}
                     <span class="inline-flex items-center gap-2 px-3 py-1 bg-red-50 border border-red-200 text-red-700 rounded-md text-sm font-semibold">
-                        <span>✗</span> Failed
+                        <span aria-hidden="true">✗</span> {{t "status.failed"}}
                     </span>
                     {{end}}
+                    <a href="/api/v1/apps/{{$app.ID}}/attestation-report" target="_blank" rel="noopener noreferrer" class="text-sm text-slate-600 hover:text-slate-900 hover:underline">{{t "details.attestation_report"}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
                 </div>
             </div>
         </div>
-        <p class="text-slate-600 mt-3 leading-relaxed">{{.Description}}</p>
-    </div>
+        <p class="text-slate-600 mt-3 leading-relaxed">{{$app.Description}}{{if $app.DescTruncated}}
+            <button type="button" hx-get="/htmx/apps/{{$app.ID}}/description" hx-target="closest p" hx-swap="innerHTML" class="text-slate-900 font-semibold hover:underline">{{t "details.show_more"}}</button>{{end}}</p>
+        {{if $app.Unmaintained}}<div role="note" class="mt-3 px-4 py-2 bg-amber-50 border border-amber-200 text-amber-800 rounded-md text-sm font-semibold"><span aria-hidden="true">⚠</span> {{$app.Unmaintained}}</div>{{end}}
+        {{if $app.Watchable}}
+        <form hx-post="/htmx/apps/{{$app.ID}}/watch" hx-swap="outerHTML" class="mt-3 flex flex-wrap items-center gap-2 text-sm">
+            <label class="flex flex-wrap items-center gap-2 text-slate-600">{{t "watch.description"}}
+                <input type="email" name="email" required autocomplete="email" placeholder="{{t "watch.email_placeholder"}}" class="px-3 py-1 border border-slate-300 rounded-md text-slate-900">
+            </label>
+            <button class="px-3 py-1 bg-slate-800 hover:bg-slate-700 text-white rounded-md font-semibold">{{t "watch.button"}}</button>
+        </form>
+        {{end}}
+    </header>
+{{end}}
 
-    <div class="space-y-4">
+{{define "details-verification"}}
+        {{$app := .App}}
         <!-- Verification Details -->
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <h4 class="text-lg font-bold text-slate-900 mb-3">Verification Details</h4>
-                {{if .MainnetDeployment}}
-                <div class="mb-4 pb-4 border-b border-slate-300">
-                    <div class="font-semibold text-slate-900 mb-2">Mainnet</div>
-                    <div class="space-y-2 text-sm">
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Status:</span>
-                            <span class="text-slate-900">{{.MainnetDeployment.Status}}</span>
-                        </div>
-                        {{if .MainnetDeployment.CommitSHA}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Commit SHA:</span>
-                            <span class="font-mono text-xs text-slate-700">{{.MainnetDeployment.CommitSHA}}</span>
-                        </div>
-                        {{end}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Last Verified:</span>
-                            <span class="text-slate-700">{{.MainnetDeployment.LastVerified}}</span>
-                        </div>
-                        {{if .MainnetDeployment.VerificationMsg}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Message:</span>
-                            <span class="text-slate-700 whitespace-pre-wrap text-xs">{{.MainnetDeployment.VerificationMsg}}</span>
-                        </div>
-                        {{end}}
-                        {{if and (eq .MainnetDeployment.Status "verified") .MainnetDeployment.EnclaveIDs}}
-                        <div class="grid grid-cols-1 gap-2 mt-2">
-                            <div class="font-semibold text-emerald-900">Enclave IDs:</div>
-                            <div class="space-y-1">
-                                {{range .MainnetDeployment.EnclaveIDs}}
-                                <div class="bg-emerald-50 border border-emerald-200 rounded px-2 py-1">
-                                    <div class="font-mono text-xs text-emerald-800 break-all">{{.}}</div>
-                                </div>
-                                {{end}}
-                            </div>
-                        </div>
-                        {{end}}
-                    </div>
-                </div>
-                {{end}}
-                {{range .OtherDeployments}}
-                <div class="mb-4 pb-4 border-b border-slate-300 last:border-b-0 last:mb-0 last:pb-0">
-                    <div class="font-semibold text-slate-900 mb-2">{{.Name}}</div>
-                    <div class="space-y-2 text-sm">
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Status:</span>
-                            <span class="text-slate-900">{{.Status}}</span>
-                        </div>
-                        {{if .CommitSHA}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Commit SHA:</span>
-                            <div class="flex items-center gap-2">
-                                <span class="font-mono text-xs text-slate-700">{{.CommitSHA}}</span>
-                                <button onclick="copyToClipboard('{{.CommitSHA}}', this)"
-                                        class="flex-shrink-0 p-1 hover:bg-slate-200 rounded transition-colors text-slate-600 hover:text-slate-900"
-                                        title="Copy to clipboard">
-                                    <svg class="w-3 h-3" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M8 16H6a2 2 0 01-2-2V6a2 2 0 012-2h8a2 2 0 012 2v2m-6 12h8a2 2 0 002-2v-8a2 2 0 00-2-2h-8a2 2 0 00-2 2v8a2 2 0 002 2z"></path>
-                                    </svg>
-                                </button>
-                            </div>
-                        </div>
-                        {{end}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Last Verified:</span>
-                            <span class="text-slate-700">{{.LastVerified}}</span>
-                        </div>
-                        {{if .VerificationMsg}}
-                        <div class="grid grid-cols-[120px_1fr] gap-2">
-                            <span class="text-slate-600 font-semibold">Message:</span>
-                            <span class="text-slate-700 text-xs leading-relaxed">{{.VerificationMsg}}</span>
-                        </div>
-                        {{end}}
-                        {{if and (eq .Status "verified") .EnclaveIDs}}
-                        <div class="grid grid-cols-1 gap-2 mt-2">
-                            <div class="font-semibold text-emerald-900">Enclave IDs:</div>
-                            <div class="space-y-1">
-                                {{range .EnclaveIDs}}
-                                <div class="bg-emerald-50 border border-emerald-200 rounded px-2 py-1">
-                                    <div class="font-mono text-xs text-emerald-800 break-all">{{.}}</div>
-                                </div>
-                                {{end}}
-                            </div>
-                        </div>
-                        {{end}}
-                    </div>
-                </div>
-                {{end}}
-                {{if and (not .MainnetDeployment) (not .OtherDeployments)}}
-                <div class="text-sm text-slate-600 text-center py-4">No deployments verified yet</div>
-                {{end}}
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "verification"}}">
+            <h3 id="{{.ID "verification"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "details.verification"}}</h3>
+            {{if or $app.LiveAttestation $app.LiveProbeError}}
+            <div class="mb-4 pb-4 border-b border-slate-300 text-sm">
+                {{if $app.LiveAttestation}}<div class="text-emerald-700 font-semibold"><span aria-hidden="true">✓</span> {{$app.LiveAttestation}}</div>{{end}}
+                {{if $app.LiveProbeError}}<div class="text-amber-700 text-xs">{{t "details.probe_failed" $app.LiveProbeError}}</div>{{end}}
             </div>
+            {{end}}
+            {{with $app.MainnetDeployment}}{{template "details-deployment" (part $app .)}}{{end}}
+            {{range $app.OtherDeployments}}{{template "details-deployment" (part $app .)}}{{end}}
+            {{if and (not $app.MainnetDeployment) (not $app.OtherDeployments)}}
+            <p class="text-sm text-slate-600 text-center py-4">{{t "details.no_deployments"}}</p>
+            {{end}}
+        </section>
+{{end}}
 
-            <!-- Application Info -->
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <h4 class="text-lg font-bold text-slate-900 mb-3">Application Info</h4>
-                <div class="space-y-2 text-sm">
-                    {{if .Author}}
+{{define "details-deployment"}}
+            {{$dep := .Part}}
+            <div class="mb-4 pb-4 border-b border-slate-300 last:border-b-0 last:mb-0 last:pb-0" role="group" aria-labelledby="{{.ID "deployment" $dep.Name}}">
+                <h4 id="{{.ID "deployment" $dep.Name}}" class="font-semibold text-slate-900 mb-2">{{network $dep.Name}}</h4>
+                <dl class="space-y-2 text-sm">
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Author:</span>
-                        <span class="text-slate-700">{{.Author}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.status"}}</dt>
+                        <dd class="text-slate-900">{{t (print "status." $dep.Status)}}</dd>
                     </div>
-                    {{end}}
-                    {{if .License}}
+                    {{if $dep.CommitSHA}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">License:</span>
-                        <span class="text-slate-700">{{.License}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.commit"}}</dt>
+                        <dd class="flex items-center gap-2">
+                            <span class="font-mono text-xs text-slate-700">{{with $dep.CommitTag}}<span class="font-semibold">{{.}}</span> {{end}}{{$dep.CommitSHA}}</span>
+                            {{template "copy-button" $dep.CommitSHA}}
+                        </dd>
                     </div>
                     {{end}}
-                    {{if .Kind}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Kind:</span>
-                        <span class="text-slate-700">{{.Kind}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.last_verified"}}</dt>
+                        <dd class="text-slate-700">{{$dep.LastVerified.HTML}}</dd>
                     </div>
-                    {{end}}
-                    {{if .Repository}}
+                    {{if $dep.VerifiedStreak}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Repository:</span>
-                        <a href="{{.Repository}}" target="_blank" class="text-blue-600 hover:text-blue-800 underline">{{.Repository}}</a>
+                        <dt class="text-slate-600 font-semibold">{{t "details.streak"}}</dt>
+                        <dd class="text-slate-700">{{$dep.VerifiedStreak}}</dd>
                     </div>
                     {{end}}
-                    {{if .Homepage}}
+                    {{if $dep.FirstVerified}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Homepage:</span>
-                        <a href="{{.Homepage}}" target="_blank" class="text-blue-600 hover:text-blue-800 underline">{{.Homepage}}</a>
+                        <dt class="text-slate-600 font-semibold">{{t "details.first_verified"}}</dt>
+                        <dd class="text-slate-700">{{$dep.FirstVerified}}</dd>
                     </div>
                     {{end}}
-                </div>
-            </div>
-
-            <!-- Resource Requirements -->
-            {{if or .Memory .CPUs .StorageKind}}
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <h4 class="text-lg font-bold text-slate-900 mb-3">Resource Requirements</h4>
-                <div class="space-y-2 text-sm">
-                    {{if .Memory}}
+                    {{if $dep.VerificationMsg}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Memory:</span>
-                        <span class="text-slate-700">{{.Memory}} MB</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.message"}}</dt>
+                        <dd class="text-slate-700 whitespace-pre-wrap text-xs leading-relaxed">{{$dep.VerificationMsg}}</dd>
                     </div>
                     {{end}}
-                    {{if .CPUs}}
+                    {{if or $dep.CLIVersion $dep.BuilderImage}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">CPUs:</span>
-                        <span class="text-slate-700">{{.CPUs}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.toolchain"}}</dt>
+                        <dd class="text-xs text-slate-700">{{if $dep.CLIVersion}}oasis-cli {{$dep.CLIVersion}}{{end}}{{if $dep.BuilderImage}}<span class="block font-mono break-all">{{$dep.BuilderImage}}</span>{{end}}</dd>
                     </div>
                     {{end}}
-                    {{if .StorageKind}}
+                    {{if $dep.LiveChecked}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Storage:</span>
-                        <span class="text-slate-700">{{.StorageKind}} ({{.StorageSize}} MB)</span>
-                    </div>
-                    {{end}}
-                </div>
-            </div>
-            {{end}}
-
-            <!-- Artifacts -->
-            {{if or .Builder .Firmware .Kernel .Stage2 .ContainerRuntime}}
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <h4 class="text-lg font-bold text-slate-900 mb-3">Runtime Artifacts</h4>
-                <div class="space-y-2 text-sm">
-                    {{if .Builder}}
-                    <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Builder:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.Builder}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.live"}}</dt>
+                        {{if $dep.LiveUnverifiedEnclaves}}
+                        <dd class="text-xs text-amber-700">{{t "details.live_unverified" $dep.LiveInstances}}{{range $dep.LiveUnverifiedEnclaves}}<span class="block font-mono break-all">{{.}}</span>{{end}}</dd>
+                        {{else}}
+                        <dd class="text-xs text-slate-700">{{with tparts "details.live_verified"}}{{printf (index . 0) $dep.LiveInstances}}{{$dep.LiveCheckedAt.HTML}}{{index . 1}}{{end}}</dd>
+                        {{end}}
                     </div>
                     {{end}}
-                    {{if .Firmware}}
+                    {{if $dep.PolicyViolations}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Firmware:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.Firmware}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.policy"}}</dt>
+                        <dd class="text-xs text-amber-700"><ul>{{range $dep.PolicyViolations}}<li><span aria-hidden="true">⚠</span> {{.Message}} <span class="font-mono text-slate-500">({{.Rule}})</span></li>{{end}}</ul></dd>
                     </div>
                     {{end}}
-                    {{if .Kernel}}
+                    {{if $dep.LogURL}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Kernel:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.Kernel}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.build_log"}}</dt>
+                        <dd><a href="{{$dep.LogURL}}" target="_blank" rel="noopener noreferrer" class="text-xs text-slate-700 hover:text-slate-900 hover:underline">{{t "details.view_build_log"}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a></dd>
                     </div>
                     {{end}}
-                    {{if .Stage2}}
+                    {{if $dep.ProvenanceURL}}
                     <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Stage2:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.Stage2}}</span>
+                        <dt class="text-slate-600 font-semibold">{{t "details.provenance"}}</dt>
+                        <dd><a href="{{$dep.ProvenanceURL}}" download class="text-xs text-slate-700 hover:text-slate-900 hover:underline">{{t "details.download_provenance"}}</a></dd>
                     </div>
                     {{end}}
-                    {{if .ContainerRuntime}}
-                    <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Runtime:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.ContainerRuntime}}</span>
+                </dl>
+                {{if $dep.Identities}}{{template "details-identities" .}}{{end}}
+                {{if $dep.VerifyCommands}}
+                <div class="grid grid-cols-1 gap-2 mt-2 text-sm">
+                    <div class="flex items-center justify-between">
+                        <span class="font-semibold text-slate-900">{{t "details.verify_yourself"}}</span>
+                        {{template "copy-button" $dep.VerifyCommands}}
                     </div>
+                    <pre class="bg-slate-900 text-slate-100 rounded p-3 text-xs font-mono overflow-x-auto" tabindex="0">{{$dep.VerifyCommands}}</pre>
+                    <div class="text-xs text-slate-500">{{with tparts "details.requires_cli"}}{{index . 0}}<a href="https://github.com/oasisprotocol/cli" target="_blank" rel="noopener noreferrer" class="underline hover:text-slate-900">Oasis CLI{{template "new-tab"}}</a>{{index . 1}}{{end}}</div>
+                </div>
+                {{end}}
+                {{if and (eq $dep.Status "verified") $dep.EnclaveIDs}}
+                <div class="grid grid-cols-1 gap-2 mt-2 text-sm">
+                    <div class="font-semibold text-emerald-900">{{t "details.enclave_ids"}}</div>
+                    <div class="space-y-1">{{template "detail-enclave-ids" $dep}}</div>
+                </div>
+                {{end}}
+            </div>
+{{end}}
+
+{{define "details-identities"}}
+                {{$dep := .Part}}
+                <div class="mt-3 overflow-x-auto">
+                    <table class="w-full text-xs border border-slate-300 bg-white" aria-describedby="{{.ID "identities-note" $dep.Name}}">
+                        <caption class="text-left text-sm font-semibold text-slate-900 mb-1">{{t "identities.title"}}</caption>
+                        <thead class="bg-slate-100 text-slate-700">
+                            <tr>
+                                <th scope="col" class="text-left font-semibold px-2 py-1">{{t "identities.enclave"}}</th>
+                                <th scope="col" class="text-left font-semibold px-2 py-1">{{t "identities.expected"}}</th>
+                                <th scope="col" class="text-left font-semibold px-2 py-1">{{t "identities.built"}}</th>
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {{range $dep.Identities}}
+                            {{$source := .Source}}
+                            {{if .Components}}
+                            {{range .Components}}
+                            <tr class="border-t border-slate-200 {{if not .Match}}bg-red-50{{end}}">
+                                <th scope="row" class="text-left font-normal text-slate-600 px-2 py-1 align-top" title="{{.Description}}">{{$source}} · {{.Name}}{{if not .Match}} <span class="text-red-700 font-semibold">{{t "identities.differs"}}</span>{{end}}</th>
+                                <td class="font-mono break-all px-2 py-1 align-top {{if .Match}}text-slate-700{{else}}text-red-800{{end}}">{{if .Expected}}{{.Expected}}{{else}}<span class="font-sans italic text-slate-500">{{t "identities.none"}}</span>{{end}}</td>
+                                <td class="font-mono break-all px-2 py-1 align-top {{if .Match}}text-slate-700{{else}}text-red-800{{end}}">{{if .Actual}}{{.Actual}}{{else}}<span class="font-sans italic text-slate-500">{{t "identities.none"}}</span>{{end}}</td>
+                            </tr>
+                            {{end}}
+                            {{else}}
+                            <tr class="border-t border-slate-200 {{if not .Match}}bg-red-50{{end}}">
+                                <th scope="row" class="text-left font-normal text-slate-600 px-2 py-1 align-top">{{$source}}{{if not .Match}} <span class="text-red-700 font-semibold">{{t "identities.differs"}}</span>{{end}}</th>
+                                <td class="font-mono break-all px-2 py-1 align-top {{if .Match}}text-slate-700{{else}}text-red-800{{end}}">{{if .Expected}}{{.Expected}}{{else}}<span class="font-sans italic text-slate-500">{{t "identities.none"}}</span>{{end}}</td>
+                                <td class="font-mono break-all px-2 py-1 align-top {{if .Match}}text-slate-700{{else}}text-red-800{{end}}">{{if .Actual}}{{.Actual}}{{else}}<span class="font-sans italic text-slate-500">{{t "identities.none"}}</span>{{end}}</td>
+                            </tr>
+                            {{end}}
+                            {{end}}
+                        </tbody>
+                    </table>
+                    <p id="{{.ID "identities-note" $dep.Name}}" class="text-xs text-slate-500 mt-1">{{t "identities.note"}}</p>
+                </div>
+{{end}}
+
+{{define "details-readme"}}
+        {{$app := .App}}
+        <!-- README -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "readme"}}">
+            <h3 id="{{.ID "readme"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "readme.title"}}</h3>
+            <div class="space-y-2 text-sm text-slate-700 leading-relaxed">
+                {{range $app.Readme}}
+                {{if eq .Kind "heading"}}
+                <h4 class="font-semibold text-slate-900 pt-1">{{.Text}}</h4>
+                {{else if eq .Kind "item"}}
+                <div class="pl-4"><span aria-hidden="true">•</span> {{.Text}}</div>
+                {{else}}
+                <p>{{.Text}}</p>
+                {{end}}
+                {{end}}
+            </div>
+            <div class="text-xs text-slate-500 mt-3">
+                {{t "readme.excerpt_at"}} <span class="font-mono">{{$app.ReadmeCommitSHA}}</span> ·
+                <a href="{{$app.GitHubURL}}/tree/{{$app.ReadmeCommitSHA}}" target="_blank" rel="noopener noreferrer" class="hover:text-slate-900 hover:underline">{{t "readme.read_more"}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
+            </div>
+        </section>
+{{end}}
+
+{{define "details-info"}}
+        {{$app := .App}}
+        <!-- Application Info -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "info"}}">
+            <h3 id="{{.ID "info"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "info.title"}}</h3>
+            <dl class="space-y-2 text-sm">
+                {{if $app.Author}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.author"}}</dt>
+                    <dd class="text-slate-700">{{$app.Author}}</dd>
+                </div>
+                {{end}}
+                {{with $app.Owner}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.maintainer"}}</dt>
+                    <dd>
+                    <a href="{{.URL}}" target="_blank" rel="noopener noreferrer" class="inline-flex items-center gap-2 text-slate-700 hover:text-slate-900 hover:underline">
+                        {{if .AvatarURL}}<img src="{{.AvatarURL}}" alt="" loading="lazy" class="w-5 h-5 {{if .Organization}}rounded{{else}}rounded-full{{end}} border border-slate-200">{{end}}
+                        <span>{{.Name}}{{if ne .Name .Login}} <span class="text-slate-500">@{{.Login}}</span>{{end}}</span>
+                        {{if .Organization}}<span class="px-2 py-0.5 bg-slate-100 text-slate-600 rounded text-xs">{{t "info.organization"}}</span>{{end}}
+                        {{if .Verified}}<span class="px-2 py-0.5 bg-emerald-50 border border-emerald-200 text-emerald-700 rounded text-xs font-semibold" title="{{t "info.verified_org_title"}}">{{t "info.verified_org"}}</span>{{end}}
+                        {{template "new-tab"}}
+                    </a>
+                    {{with .Match}}
+                    {{if .Matched}}
+                    <div class="text-xs text-emerald-700"><span aria-hidden="true">✓</span> {{t "info.owner_matches" .Reason}}</div>
+                    {{else}}
+                    <div class="text-xs text-amber-700 font-semibold"><span aria-hidden="true">⚠</span> {{t "info.owner_mismatch" .Reason}}</div>
                     {{end}}
-                    {{if .ContainerCompose}}
-                    <div class="grid grid-cols-[120px_1fr] gap-2">
-                        <span class="text-slate-600 font-semibold">Compose:</span>
-                        <span class="font-mono text-xs text-slate-700 break-all">{{.ContainerCompose}}</span>
-                    </div>
                     {{end}}
+                    </dd>
                 </div>
-            </div>
-            {{end}}
+                {{end}}
+                {{if $app.ForkOf}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.fork_of"}}</dt>
+                    <dd><a href="{{$app.ForkOf}}" target="_blank" rel="noopener noreferrer" class="text-amber-700 hover:text-amber-900 underline break-all">{{$app.ForkOf}}{{template "new-tab"}}</a></dd>
+                </div>
+                {{end}}
+                {{if $app.License}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.license"}}</dt>
+                    <dd class="text-slate-700">
+                        {{$app.License}}
+                        {{if $app.LicenseOSI}}<span class="ml-1 px-2 py-0.5 bg-emerald-50 border border-emerald-200 text-emerald-700 rounded text-xs font-semibold" title="{{t "info.osi_approved_title"}}">{{t "info.osi_approved"}}</span>
+                        {{else if $app.LicenseError}}<span class="ml-1 px-2 py-0.5 bg-amber-50 border border-amber-200 text-amber-700 rounded text-xs font-semibold" title="{{$app.LicenseError}}">{{t "info.unknown_license"}}</span>
+                        {{else}}<span class="ml-1 px-2 py-0.5 bg-slate-100 text-slate-600 rounded text-xs font-semibold">{{t "info.not_osi_approved"}}</span>{{end}}
+                    </dd>
+                </div>
+                {{end}}
+                {{if $app.Kind}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.kind"}}</dt>
+                    <dd class="text-slate-700">{{$app.Kind}}</dd>
+                </div>
+                {{end}}
+                {{if $app.Repository}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.repository"}}</dt>
+                    <dd><a href="{{$app.Repository}}" target="_blank" rel="noopener noreferrer" class="text-primary hover:text-primary-dark underline">{{$app.Repository}}{{template "new-tab"}}</a></dd>
+                </div>
+                {{end}}
+                {{if $app.Homepage}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "info.homepage"}}</dt>
+                    <dd>
+                        <a href="{{$app.Homepage}}" target="_blank" rel="noopener noreferrer" class="text-primary hover:text-primary-dark underline">{{$app.Homepage}}{{template "new-tab"}}</a>
+                        {{if $app.DomainVerified}}
+                        <div class="text-xs text-emerald-700 font-semibold"><span aria-hidden="true">✓</span> {{if eq $app.DomainMethod "dns"}}{{t "info.domain_verified" (t "info.dns_record")}}{{else}}{{t "info.domain_verified" "/.well-known/rofl-registry"}}{{end}}</div>
+                        {{else if $app.DomainError}}
+                        <div class="text-xs text-slate-500">{{t "info.domain_not_verified" $app.DomainError}}</div>
+                        {{end}}
+                    </dd>
+                </div>
+                {{end}}
+            </dl>
+        </section>
+{{end}}
 
-            <!-- Deployments -->
-            {{if .Deployments}}
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <h4 class="text-lg font-bold text-slate-900 mb-3">Deployments</h4>
-                <div class="space-y-3 text-sm">
-                    {{range .Deployments}}
-                    <div class="bg-white border border-slate-300 rounded-md p-3">
-                        <div class="font-semibold text-slate-900 mb-2">{{.Name}}</div>
-                        <div class="space-y-1">
-                            {{if .Network}}
-                            <div class="grid grid-cols-[80px_1fr] gap-2">
-                                <span class="text-slate-600">Network:</span>
-                                <span class="text-slate-700">{{.Network}}</span>
-                            </div>
+{{define "details-resources"}}
+        {{$app := .App}}
+        <!-- Resource Requirements -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "resources"}}">
+            <h3 id="{{.ID "resources"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "resources.title"}}</h3>
+            <dl class="space-y-2 text-sm">
+                {{if $app.Memory}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "resources.memory"}}</dt>
+                    <dd class="text-slate-700">{{$app.Memory}} MB</dd>
+                </div>
+                {{end}}
+                {{if $app.CPUs}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "resources.cpus"}}</dt>
+                    <dd class="text-slate-700">{{$app.CPUs}}</dd>
+                </div>
+                {{end}}
+                {{if $app.StorageKind}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "resources.storage"}}</dt>
+                    <dd class="text-slate-700">{{$app.StorageKind}} ({{$app.StorageSize}} MB)</dd>
+                </div>
+                {{end}}
+            </dl>
+        </section>
+{{end}}
+
+{{define "details-artifacts"}}
+        {{$app := .App}}
+        <!-- Artifacts -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "artifacts"}}">
+            <h3 id="{{.ID "artifacts"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "artifacts.title"}}</h3>
+            <dl class="space-y-2 text-sm">
+                {{if $app.Builder}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.builder"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.Builder}}</dd>
+                </div>
+                {{end}}
+                {{if $app.Firmware}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.firmware"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.Firmware}}</dd>
+                </div>
+                {{end}}
+                {{if $app.Kernel}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.kernel"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.Kernel}}</dd>
+                </div>
+                {{end}}
+                {{if $app.Stage2}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.stage2"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.Stage2}}</dd>
+                </div>
+                {{end}}
+                {{if $app.ContainerRuntime}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.runtime"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.ContainerRuntime}}</dd>
+                </div>
+                {{end}}
+                {{if $app.ContainerCompose}}
+                <div class="grid grid-cols-[120px_1fr] gap-2">
+                    <dt class="text-slate-600 font-semibold">{{t "artifacts.compose"}}</dt>
+                    <dd class="font-mono text-xs text-slate-700 break-all">{{$app.ContainerCompose}}</dd>
+                </div>
+                {{end}}
+            </dl>
+        </section>
+{{end}}
+
+{{define "details-deployments"}}
+        {{$app := .App}}
+        <!-- Deployments -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "deployments"}}">
+            <h3 id="{{.ID "deployments"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "deployments.title"}}</h3>
+            <ul class="space-y-3 text-sm">
+                {{range $app.Deployments}}
+                <li class="bg-white border border-slate-300 rounded-md p-3">
+                    <h4 class="font-semibold text-slate-900 mb-2">{{.Name}}</h4>
+                    <dl class="space-y-1">
+                        {{if .Network}}
+                        <div class="grid grid-cols-[80px_1fr] gap-2">
+                            <dt class="text-slate-600">{{t "deployments.network"}}</dt>
+                            <dd class="text-slate-700">{{.Network}}</dd>
+                        </div>
+                        {{end}}
+                        {{if .AppID}}
+                        <div class="grid grid-cols-[80px_1fr] gap-2">
+                            <dt class="text-slate-600">{{t "deployments.app_id"}}</dt>
+                            <dd>
+                            {{if .ExplorerURL}}
+                            <a href="{{.ExplorerURL}}"
+                               target="_blank"
+                               rel="noopener noreferrer"
+                               class="font-mono text-xs text-primary hover:text-primary-dark hover:underline break-all">
+                                {{.AppID}} <span aria-hidden="true">↗</span>{{template "new-tab"}}
+                            </a>
+                            {{else}}
+                            <span class="font-mono text-xs text-slate-700 break-all">{{.AppID}}</span>
                             {{end}}
-                            {{if .AppID}}
-                            <div class="grid grid-cols-[80px_1fr] gap-2">
-                                <span class="text-slate-600">App ID:</span>
-                                <a href="https://explorer.oasis.io/{{.Network}}/sapphire/rofl/app/{{.AppID}}"
-                                   target="_blank"
-                                   rel="noopener noreferrer"
-                                   class="font-mono text-xs text-blue-600 hover:text-blue-800 hover:underline break-all">
-                                    {{.AppID}} ↗
-                                </a>
-                            </div>
+                            {{if .AppIDError}}
+                            <div class="text-xs text-red-700">{{.AppIDError}}</div>
                             {{end}}
-                            {{if .Enclaves}}
-                            <div class="mt-2 pt-2 border-t border-slate-200">
-                                <div class="text-slate-600 font-semibold mb-1">Enclave Identities:</div>
-                                <div class="space-y-1">
-                                    {{range .Enclaves}}
-                                    <div class="bg-slate-50 rounded px-2 py-1">
-                                        <div class="text-xs text-slate-600">{{.Type}}</div>
-                                        <div class="font-mono text-xs text-slate-700 break-all">{{.Value}}</div>
+                            </dd>
+                        </div>
+                        {{end}}
+                    </dl>
+                    {{if .Enclaves}}
+                    <div class="mt-2 pt-2 border-t border-slate-200">
+                        <div class="text-slate-600 font-semibold mb-1">{{t "deployments.enclave_identities"}}</div>
+                        <ul class="space-y-1">
+                            {{$explorer := .ExplorerURL}}
+                            {{range .Enclaves}}
+                            <li class="bg-slate-50 rounded px-2 py-1">
+                                <div class="text-xs text-slate-600">{{.Type}}</div>
+                                {{if $explorer}}
+                                <a href="{{$explorer}}" target="_blank" rel="noopener noreferrer" class="font-mono text-xs text-primary hover:text-primary-dark hover:underline break-all">{{.Value}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
+                                {{else}}
+                                <div class="font-mono text-xs text-slate-700 break-all">{{.Value}}</div>
+                                {{end}}
+                                {{range .Components}}
+                                <div class="mt-1 pl-2 border-l-2 border-slate-200">
+                                    <div class="text-xs text-slate-600" title="{{.Description}}">{{.Name}}</div>
+                                    <div class="flex items-center gap-2">
+                                        <span class="font-mono text-xs text-slate-700 break-all">{{.Hex}}</span>
+                                        {{template "copy-button" .Hex}}
                                     </div>
-                                    {{end}}
                                 </div>
-                            </div>
+                                {{end}}
+                            </li>
                             {{end}}
-                        </div>
+                        </ul>
+                        {{if .HiddenEnclaves}}
+                        <a href="{{$app.ManifestURL}}" target="_blank" rel="noopener noreferrer" class="block mt-1 text-xs text-slate-600 hover:text-slate-900 hover:underline">{{t "deployments.more_in_manifest" .HiddenEnclaves}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
+                        {{end}}
                     </div>
                     {{end}}
-                </div>
+                </li>
+                {{end}}
+            </ul>
+        </section>
+{{end}}
+
+{{define "details-contracts"}}
+        {{$app := .App}}
+        <!-- Related Contracts -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "contracts"}}">
+            <h3 id="{{.ID "contracts"}}" class="text-lg font-bold text-slate-900 mb-3">{{t "contracts.title"}}</h3>
+            <ul class="space-y-2 text-sm">
+                {{range $app.Contracts}}
+                <li class="bg-white border border-slate-300 rounded-md p-3">
+                    <div class="flex justify-between items-center gap-2 mb-1">
+                        <span class="font-semibold text-slate-900">{{if .Label}}{{.Label}}{{else}}{{t "contracts.contract"}}{{end}}</span>
+                        {{if .Network}}<span class="px-2 py-0.5 bg-slate-100 text-slate-700 rounded text-xs">{{.Network}}</span>{{end}}
+                    </div>
+                    {{if .ExplorerURL}}
+                    <a href="{{.ExplorerURL}}" target="_blank" rel="noopener noreferrer" class="font-mono text-xs text-primary hover:text-primary-dark hover:underline break-all">{{.Address}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
+                    {{else}}
+                    <span class="font-mono text-xs text-slate-700 break-all">{{.Address}}</span>
+                    <div class="text-xs text-red-700">{{.Error}}</div>
+                    {{end}}
+                </li>
+                {{end}}
+            </ul>
+        </section>
+{{end}}
+
+{{define "details-manifest"}}
+        {{$app := .App}}
+        <!-- Raw manifest -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "manifest"}}">
+            <div class="flex justify-between items-center mb-3">
+                <h3 id="{{.ID "manifest"}}" class="text-lg font-bold text-slate-900">{{$app.ManifestPath}}</h3>
+                <button type="button" data-action="toggle-yaml" data-app-id="{{$app.ID}}" aria-expanded="false" aria-controls="yaml-{{$app.ID}}" data-show="{{t "manifest.show_file" $app.ManifestFile}}" data-hide="{{t "manifest.hide_file" $app.ManifestFile}}" class="px-3 py-1 bg-slate-700 hover:bg-slate-600 text-white rounded-md text-xs font-semibold transition-colors">
+                    {{t "manifest.show_file" $app.ManifestFile}}
+                </button>
             </div>
-            {{end}}
+            <div id="yaml-{{$app.ID}}" class="hidden">
+                <pre class="bg-slate-900 text-slate-100 rounded-md p-4 text-xs overflow-x-auto" tabindex="0"><code>{{$app.RoflYAML}}</code></pre>
+                {{if $app.RoflYAMLTruncated}}
+                <div class="text-xs text-slate-500 mt-2">{{with tparts "manifest.truncated"}}{{index . 0}}<a href="{{$app.ManifestURL}}" target="_blank" rel="noopener noreferrer" class="text-slate-700 hover:text-slate-900 hover:underline">{{index . 1}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>{{index . 2}}{{end}}</div>
+                {{end}}
+            </div>
+        </section>
 
-            <!-- Raw rofl.yaml -->
-            {{if .RoflYAML}}
-            <div class="bg-slate-50 border border-slate-200 rounded-lg p-4">
-                <div class="flex justify-between items-center mb-3">
-                    <h4 class="text-lg font-bold text-slate-900">rofl.yaml</h4>
-                    <button onclick="toggleYaml(event, {{.ID}})" class="px-3 py-1 bg-slate-700 hover:bg-slate-600 text-white rounded-md text-xs font-semibold transition-colors">
-                        Show rofl.yaml
-                    </button>
-                </div>
-                <div id="yaml-{{.ID}}" class="hidden">
-                    <pre class="bg-slate-900 text-slate-100 rounded-md p-4 text-xs overflow-x-auto"><code>{{.RoflYAML}}</code></pre>
-                </div>
+        <!-- Manifest changes -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "changes"}}">
+            <div class="flex justify-between items-center mb-3">
+                <h3 id="{{.ID "changes"}}" class="text-lg font-bold text-slate-900">{{t "manifest.changes"}}</h3>
+                <button type="button" data-action="toggle-manifest-diff" data-app-id="{{$app.ID}}" aria-expanded="false" aria-controls="manifest-diff-{{$app.ID}}" data-show="{{t "manifest.show_changes"}}" data-hide="{{t "manifest.hide_changes"}}" class="px-3 py-1 bg-slate-700 hover:bg-slate-600 text-white rounded-md text-xs font-semibold transition-colors">
+                    {{t "manifest.show_changes"}}
+                </button>
             </div>
+            <div id="manifest-diff-{{$app.ID}}" class="hidden" aria-live="polite"></div>
+        </section>
+{{end}}
+
+{{define "details-compose"}}
+        {{$app := .App}}
+        <!-- Compose file -->
+        <section class="bg-slate-50 border border-slate-200 rounded-lg p-4" aria-labelledby="{{.ID "compose"}}">
+            <div class="flex justify-between items-center mb-3">
+                <h3 id="{{.ID "compose"}}" class="text-lg font-bold text-slate-900">{{$app.ContainerCompose}}</h3>
+                <button type="button" data-action="toggle-compose" data-app-id="{{$app.ID}}" aria-expanded="false" aria-controls="compose-{{$app.ID}}" data-show="{{t "compose.show_file"}}" data-hide="{{t "compose.hide_file"}}" class="px-3 py-1 bg-slate-700 hover:bg-slate-600 text-white rounded-md text-xs font-semibold transition-colors">
+                    {{t "compose.show_file"}}
+                </button>
+            </div>
+            {{if $app.ComposeCommitSHA}}
+            <div class="text-xs text-slate-500 mb-3">{{t "compose.fetched_at"}} <span class="font-mono">{{$app.ComposeCommitSHA}}</span></div>
             {{end}}
-        </div>
+            {{if $app.ComposeImages}}
+            <ul class="space-y-1 text-sm mb-3">
+                {{range $app.ComposeImages}}
+                <li class="bg-white border border-slate-300 rounded-md px-3 py-2">
+                    <div class="text-xs text-slate-600">{{.Service}}</div>
+                    <div class="font-mono text-xs text-slate-700 break-all">{{.Name}}{{if .Tag}}:{{.Tag}}{{end}}</div>
+                    {{if .Digest}}
+                    <div class="font-mono text-xs text-emerald-800 break-all">{{.Digest}}</div>
+                    {{else}}
+                    <div class="text-xs text-amber-700 font-semibold"><span aria-hidden="true">⚠</span> {{t "compose.mutable_tag"}}</div>
+                    {{if .ResolvedDigest}}
+                    <div class="text-xs text-slate-600 mt-1">{{$resolved := .ResolvedAt}}{{with tparts "compose.resolved"}}{{index . 0}}{{$resolved.HTML}}{{index . 1}}{{end}}</div>
+                    <div class="font-mono text-xs text-slate-700 break-all">{{.ResolvedDigest}}</div>
+                    {{end}}
+                    {{end}}
+                </li>
+                {{end}}
+            </ul>
+            {{end}}
+            <div id="compose-{{$app.ID}}" class="hidden">
+                <pre class="bg-slate-900 text-slate-100 rounded-md p-4 text-xs overflow-x-auto" tabindex="0"><code>{{$app.ComposeYAML}}</code></pre>
+                {{if $app.ComposeTruncated}}
+                <div class="text-xs text-slate-500 mt-2">{{with tparts "manifest.truncated"}}{{index . 0}}<a href="{{$app.ComposeURL}}" target="_blank" rel="noopener noreferrer" class="text-slate-700 hover:text-slate-900 hover:underline">{{index . 1}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>{{index . 2}}{{end}}</div>
+                {{end}}
+            </div>
+        </section>
+{{end}}
+
+{{define "card-enclave-ids"}}
+    {{$explorer := .ExplorerURL}}
+    {{range .EnclaveIDs}}
+    {{if $explorer}}
+    <a href="{{$explorer}}" target="_blank" rel="noopener noreferrer" class="block font-mono text-emerald-800 hover:text-emerald-950 hover:underline break-all text-xs">{{.}}{{template "new-tab"}}</a>
+    {{else}}
+    <div class="font-mono text-emerald-800 break-all text-xs">{{.}}</div>
+    {{end}}
+    {{end}}
+    {{if .HiddenEnclaves}}
+    <button type="button" hx-get="{{.EnclavesURL}}?view=card" hx-target="closest .space-y-1" hx-swap="innerHTML" class="text-emerald-900 font-semibold hover:underline">{{t "card.show_more_enclaves" .HiddenEnclaves}}</button>
+    {{end}}
+{{end}}
+{{define "detail-enclave-ids"}}
+    {{$explorer := .ExplorerURL}}
+    {{range .EnclaveIDs}}
+    <div class="bg-emerald-50 border border-emerald-200 rounded px-2 py-1">
+        {{if $explorer}}
+        <a href="{{$explorer}}" target="_blank" rel="noopener noreferrer" class="font-mono text-xs text-emerald-800 hover:text-emerald-950 hover:underline break-all">{{.}} <span aria-hidden="true">↗</span>{{template "new-tab"}}</a>
+        {{else}}
+        <div class="font-mono text-xs text-emerald-800 break-all">{{.}}</div>
+        {{end}}
     </div>
-</div>
+    {{end}}
+    {{if .HiddenEnclaves}}
+    <button type="button" hx-get="{{.EnclavesURL}}?view=detail" hx-target="closest .space-y-1" hx-swap="innerHTML" class="text-xs text-emerald-900 font-semibold hover:underline">{{t "card.show_more_enclaves" .HiddenEnclaves}}</button>
+    {{end}}
+{{end}}
 `
 
-func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment) (string, error) {
+// Limits on the manifest-derived content rendered in a card, protecting page weight against a
+// bloated or hostile manifest. The rest is loaded on demand.
+const (
+	maxCardDescription = 500        // Runes of the description.
+	maxCardRoflYAML    = 32 * 1024  // Bytes of the raw manifest.
+	maxCardEnclaves    = 3          // Enclave identities per deployment.
+	maxCardSize        = 512 * 1024 // Bytes of the rendered card, beyond which it is not served.
+)
+
+// limitForDisplay truncates the content of card data to the card limits.
+func limitForDisplay(data *AppCardData) {
+	if runes := []rune(data.Description); len(runes) > maxCardDescription {
+		data.Description = strings.TrimSpace(string(runes[:maxCardDescription])) + "…"
+		data.DescTruncated = true
+	}
+	if len(data.RoflYAML) > maxCardRoflYAML {
+		data.RoflYAML = strings.ToValidUTF8(data.RoflYAML[:maxCardRoflYAML], "")
+		data.RoflYAMLTruncated = true
+	}
+
+	limitStatus := func(dep *DeploymentStatus) {
+		if len(dep.EnclaveIDs) > maxCardEnclaves {
+			dep.HiddenEnclaves = len(dep.EnclaveIDs) - maxCardEnclaves
+			dep.EnclaveIDs = dep.EnclaveIDs[:maxCardEnclaves]
+			dep.EnclavesURL = fmt.Sprintf("/htmx/apps/%d/deployments/%s/enclaves", data.ID, url.PathEscape(dep.Name))
+		}
+	}
+	if data.MainnetDeployment != nil {
+		limitStatus(data.MainnetDeployment)
+	}
+	for i := range data.OtherDeployments {
+		limitStatus(&data.OtherDeployments[i])
+	}
+	for i := range data.Deployments {
+		if dep := &data.Deployments[i]; len(dep.Enclaves) > maxCardEnclaves {
+			dep.HiddenEnclaves = len(dep.Enclaves) - maxCardEnclaves
+			dep.Enclaves = dep.Enclaves[:maxCardEnclaves]
+		}
+	}
+}
+
+func (s *Server) renderAppCard(loc *Locale, app *models.App, deployments []*models.Deployment, imageDigests map[string]*models.ImageDigest, uptime *models.Uptime) (string, error) {
+	data, err := newAppCardData(loc, app, deployments, imageDigests)
+	if err != nil {
+		return "", err
+	}
+	data.Watchable = s.cfg.Worker.Notifications.SMTP.Enabled()
+	data.Uptime = newUptimeInfo(loc, uptime)
+
+	card, err := renderCard(s.cardTemplate, loc, data)
+	if err != nil {
+		return "", err
+	}
+	return string(card), nil
+}
+
+// renderCard renders the card and details of an app with the card template, truncating the data
+// to the card limits.
+func renderCard(cardTemplate localizedTemplate, loc *Locale, data *AppCardData) ([]byte, error) {
+	limitForDisplay(data)
+
+	card, err := render(cardTemplate, loc, "app-card", data)
+	if err != nil {
+		return nil, err
+	}
+	if len(card) > maxCardSize {
+		return nil, fmt.Errorf("card of %d bytes exceeds the limit of %d", len(card), maxCardSize)
+	}
+	return card, nil
+}
+
+// newAppCardData builds the data shown for an app from its manifest and verification state, with
+// timestamps formatted in the given locale.
+func newAppCardData(loc *Locale, app *models.App, deployments []*models.Deployment, imageDigests map[string]*models.ImageDigest) (*AppCardData, error) {
 	// Parse rofl.yaml if available.
 	var manifest *rofl.Manifest
 	if app.RoflYAML.Valid && app.RoflYAML.String != "" {
 		var err error
 		manifest, err = rofl.Parse([]byte(app.RoflYAML.String))
 		if err != nil {
-			return "", fmt.Errorf("failed to parse rofl.yaml: %w", err)
+			return nil, fmt.Errorf("failed to parse rofl.yaml: %w", err)
 		}
 	} else {
 		// Empty manifest for apps without rofl.yaml yet.
@@ -659,6 +1233,17 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 		}
 	}
 
+	// Check the license against the SPDX list.
+	var licenseOSI bool
+	var licenseError string
+	if manifest.License != "" {
+		if license, err := rofl.ParseLicense(manifest.License); err != nil {
+			licenseError = err.Error()
+		} else {
+			licenseOSI = license.OSIApproved
+		}
+	}
+
 	// Process deployments from database
 	var mainnetDeployment *DeploymentStatus
 	var otherDeployments []DeploymentStatus
@@ -666,7 +1251,9 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 	for _, dep := range deployments {
 		// Get enclave IDs for this deployment from rofl.yaml
 		var enclaveIDs []string
+		var explorerURL string
 		if manifestDep, ok := manifest.Deployments[dep.DeploymentName]; ok && manifestDep != nil {
+			explorerURL = explorerAppURL(manifestDep.Network, manifestDep.AppID)
 			// Policy.Enclaves might be nil or empty if not specified in rofl.yaml
 			if manifestDep.Policy.Enclaves != nil {
 				for _, enc := range manifestDep.Policy.Enclaves {
@@ -682,9 +1269,39 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 			Status:          string(dep.Status),
 			CommitSHA:       dep.CommitSHA.String,
 			CommitSHAShort:  shortSHA(dep.CommitSHA.String),
+			CommitTag:       dep.CommitTag.String,
+			CommitLabel:     commitLabel(dep.CommitSHA.String, dep.CommitTag.String),
 			VerificationMsg: dep.VerificationMsg.String,
-			LastVerified:    formatTime(dep.LastVerified),
+			LastVerified:    loc.NullTime(dep.LastVerified),
+			FirstVerified:   loc.Date(dep.FirstVerifiedAt),
 			EnclaveIDs:      enclaveIDs,
+			ExplorerURL:     explorerURL,
+			CLIVersion:      dep.CLIVersion.String,
+			BuilderImage:    dep.BuilderImage.String,
+		}
+		if dep.Status == models.StatusVerified && dep.VerifiedSince.Valid {
+			deploymentStatus.VerifiedStreak = verifiedStreak(loc, dep.VerifiedSince.Time, dep.VerifiedStreak)
+		}
+		if dep.Status == models.StatusVerified && dep.CommitSHA.String != "" {
+			deploymentStatus.VerifyCommands = verifyCommands(app.GitHubURL, dep.CommitSHA.String, dep.DeploymentName, dep.CLIVersion.String)
+			deploymentStatus.ProvenanceURL = fmt.Sprintf("/api/v1/apps/%d/deployments/%s/provenance?commit=%s", app.ID, url.PathEscape(dep.DeploymentName), dep.CommitSHA.String)
+		}
+		if dep.Status == models.StatusVerified && dep.LiveCheckedAt.Valid {
+			deploymentStatus.LiveChecked = true
+			deploymentStatus.LiveInstances = dep.LiveInstances.Int64
+			deploymentStatus.LiveCheckedAt = loc.NullTime(dep.LiveCheckedAt)
+			if dep.LiveUnverifiedEnclaves.String != "" {
+				deploymentStatus.LiveUnverifiedEnclaves = strings.Split(dep.LiveUnverifiedEnclaves.String, ",")
+			}
+		}
+		if dep.Status == models.StatusVerified {
+			deploymentStatus.PolicyViolations = dep.PolicyViolations
+		}
+		if dep.Status == models.StatusFailed {
+			deploymentStatus.Identities = newIdentityComparisons(loc, dep.VerificationReport, manifest.TEE)
+		}
+		if dep.HasLog {
+			deploymentStatus.LogURL = fmt.Sprintf("/api/apps/%d/deployments/%s/log", app.ID, url.PathEscape(dep.DeploymentName))
 		}
 
 		// Mainnet takes priority
@@ -729,19 +1346,30 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 		if deployment.Policy.Enclaves != nil {
 			for _, enc := range deployment.Policy.Enclaves {
 				if enc != "" {
-					enclaves = append(enclaves, EnclaveIdentity{
+					identity := EnclaveIdentity{
 						Type:  "Enclave ID",
 						Value: enc,
-					})
+					}
+					if decoded, err := rofl.DecodeEnclaveIdentity(enc); err == nil {
+						identity.Components = decoded.Components(manifest.TEE)
+					}
+					enclaves = append(enclaves, identity)
 				}
 			}
 		}
-		deploymentInfos = append(deploymentInfos, DeploymentInfo{
-			Name:     name,
-			Network:  deployment.Network,
-			AppID:    deployment.AppID,
-			Enclaves: enclaves,
-		})
+		info := DeploymentInfo{
+			Name:        name,
+			Network:     deployment.Network,
+			AppID:       deployment.AppID,
+			ExplorerURL: explorerAppURL(deployment.Network, deployment.AppID),
+			Enclaves:    enclaves,
+		}
+		if deployment.AppID != "" {
+			if err := rofl.ValidateAppID(deployment.AppID); err != nil {
+				info.AppIDError = err.Error()
+			}
+		}
+		deploymentInfos = append(deploymentInfos, info)
 	}
 
 	// Get raw rofl.yaml content.
@@ -749,20 +1377,51 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 	if app.RoflYAML.Valid {
 		roflYAML = app.RoflYAML.String
 	}
+	manifestPath := "rofl.yaml"
+	if app.ManifestPath.Valid && app.ManifestPath.String != "" {
+		manifestPath = app.ManifestPath.String
+	}
 
-	data := AppCardData{
+	// Extract image references from the compose file, if fetched.
+	var composeImages []ComposeImage
+	if app.ComposeYAML.Valid && app.ComposeYAML.String != "" {
+		if compose, err := rofl.ParseCompose([]byte(app.ComposeYAML.String)); err == nil {
+			for _, img := range compose.Images() {
+				composeImage := ComposeImage{
+					Service: img.Service,
+					Name:    img.Name,
+					Tag:     img.Tag,
+					Digest:  img.Digest,
+				}
+				if resolved, ok := imageDigests[img.Raw]; ok && !img.Pinned() {
+					composeImage.ResolvedDigest = resolved.Digest
+					composeImage.ResolvedAt = loc.Time(resolved.ResolvedAt)
+				}
+				composeImages = append(composeImages, composeImage)
+			}
+		}
+	}
+
+	data := &AppCardData{
 		ID:                app.ID,
-		Name:              manifest.Name,
-		Slug:              slugify(manifest.Name),
-		Version:           manifest.Version,
-		Description:       manifest.Description,
+		Name:              plainText(manifest.Name),
+		Slug:              app.Slug.String,
+		Featured:          app.Featured,
+		HasLogo:           app.HasLogo,
+		Version:           plainText(manifest.Version),
+		Description:       plainText(manifest.Description),
 		GitHubURL:         app.GitHubURL,
-		Author:            manifest.Author,
-		License:           manifest.License,
+		Author:            plainText(manifest.Author),
+		Owner:             newOwnerInfo(app, manifest),
+		ForkOf:            app.ForkOf.String,
+		RepoStatus:        app.RepoStatus.String,
+		LicenseOSI:        licenseOSI,
+		LicenseError:      licenseError,
+		License:           plainText(manifest.License),
 		TEE:               manifest.TEE,
 		Kind:              manifest.Kind,
-		Repository:        manifest.Repository,
-		Homepage:          manifest.Homepage,
+		Repository:        safeURL(manifest.Repository),
+		Homepage:          safeURL(manifest.Homepage),
 		Memory:            manifest.Resources.Memory,
 		CPUs:              manifest.Resources.CPUs,
 		StorageKind:       manifest.Resources.Storage.Kind,
@@ -773,6 +1432,7 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 		Networks:          networks,
 		NetworksStr:       joinNetworks(networks),
 		Deployments:       deploymentInfos,
+		Contracts:         newContractInfos(manifest.Contracts),
 		Builder:           manifest.Artifacts.Builder,
 		Firmware:          manifest.Artifacts.Firmware,
 		Kernel:            manifest.Artifacts.Kernel,
@@ -780,74 +1440,222 @@ func (s *Server) renderAppCard(app *models.App, deployments []*models.Deployment
 		ContainerRuntime:  manifest.Artifacts.Container.Runtime,
 		ContainerCompose:  manifest.Artifacts.Container.Compose,
 		RoflYAML:          roflYAML,
+		ManifestPath:      manifestPath,
+		ComposeYAML:       app.ComposeYAML.String,
+		ComposeTruncated:  app.ComposeYAMLRef.Valid && app.ComposeYAMLRef.String != "",
+		ComposeURL:        fmt.Sprintf("/api/apps/%d/compose", app.ID),
+		ManifestURL:       fmt.Sprintf("/api/apps/%d/manifest", app.ID),
+		ComposeCommitSHA:  app.ComposeCommitSHA.String,
+		ComposeImages:     composeImages,
+		Readme:            readmeBlocks(app.ReadmeExcerpt.String),
+		ReadmeCommitSHA:   app.ReadmeCommitSHA.String,
+	}
+	switch app.RepoStatus.String {
+	case models.RepoArchived:
+		data.Unmaintained = loc.T("details.repo_archived", loc.Date(app.RepoStatusAt))
+	case models.RepoDeleted:
+		data.Unmaintained = loc.T("details.repo_deleted", loc.Date(app.RepoStatusAt))
+	}
+	if app.AttestationURL.String != "" {
+		if app.ProbeAttestedAt.Valid {
+			data.LiveAttestation = loc.T("details.live_attested", loc.Time(app.ProbeAttestedAt.Time), app.ProbeDeployment.String)
+		}
+		data.LiveProbeError = app.ProbeError.String
+	}
+	// Only show a verified domain while it is still the homepage.
+	if homepage, err := url.Parse(manifest.Homepage); err == nil && strings.EqualFold(homepage.Hostname(), app.DomainVerified.String) {
+		data.DomainVerified = app.DomainVerified.String
+		data.DomainMethod = app.DomainMethod.String
+	} else if manifest.Homepage != "" {
+		data.DomainError = app.DomainError.String
 	}
 
 	// Use default values if rofl.yaml is not available.
 	if data.Name == "" {
-		data.Name = "Unknown App"
-		data.Description = "Verification pending..."
+		data.Name = loc.T("card.unknown_app")
+		data.Description = loc.T("card.pending_description")
 	}
 
-	// Render template using pre-parsed template.
-	var buf bytes.Buffer
-	if err := s.cardTemplate.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
-	}
+	return data, nil
+}
 
-	return buf.String(), nil
+// ManifestDiffSection groups the changes of a manifest diff by section.
+type ManifestDiffSection struct {
+	Name        string
+	Significant bool
+	Changes     []rofl.ManifestChange
 }
 
-func shortSHA(sha string) string {
-	if len(sha) > 7 {
-		return sha[:7]
-	}
-	return sha
+// ManifestDiffData holds the data for rendering a manifest diff.
+type ManifestDiffData struct {
+	HasBase      bool
+	BaseVerified bool
+	BaseHash     string
+	BaseSince    Timestamp
+	Sections     []ManifestDiffSection
+}
+
+// manifestDiffSections lists the diff sections in display order.
+var manifestDiffSections = []string{
+	rofl.SectionEnclaves,
+	rofl.SectionPolicy,
+	rofl.SectionArtifacts,
+	rofl.SectionResources,
+	rofl.SectionOther,
 }
 
-func formatTime(t interface{}) string {
-	switch v := t.(type) {
-	case time.Time:
-		return timeAgo(v)
-	case sql.NullTime:
-		if v.Valid {
-			return timeAgo(v.Time)
+// newManifestDiffData groups a manifest diff for display.
+func newManifestDiffData(loc *Locale, diff *ManifestDiff) ManifestDiffData {
+	data := ManifestDiffData{
+		HasBase:      diff.BaseHash != "",
+		BaseVerified: diff.BaseVerified,
+		BaseHash:     shortSHA(diff.BaseHash),
+	}
+	switch {
+	case diff.BaseVerifiedAt != nil:
+		data.BaseSince = loc.Time(*diff.BaseVerifiedAt)
+	case diff.BaseFirstSeen != nil:
+		data.BaseSince = loc.Time(*diff.BaseFirstSeen)
+	}
+
+	for _, name := range manifestDiffSections {
+		section := ManifestDiffSection{Name: name, Significant: name != rofl.SectionOther}
+		for _, change := range diff.Changes {
+			if change.Section == name {
+				section.Changes = append(section.Changes, change)
+			}
+		}
+		if len(section.Changes) > 0 {
+			data.Sections = append(data.Sections, section)
 		}
-		return notYetVerified
-	default:
-		return notYetVerified
 	}
+	return data
 }
 
-func timeAgo(t time.Time) string {
-	if t.IsZero() {
-		return notYetVerified
+var manifestDiffTemplate = `<!-- Manifest Diff -->
+{{if not .HasBase}}
+<div class="text-sm text-slate-600">{{t "diff.no_base"}}</div>
+{{else}}
+<div class="text-xs text-slate-600 mb-3">
+    {{if .BaseVerified}}{{t "diff.compared_verified"}}{{else}}{{t "diff.compared_fetched"}}{{end}}
+    <span class="font-mono">{{.BaseHash}}</span>{{if .BaseSince.Relative}}{{$since := .BaseSince}}{{$key := "diff.first_seen_at"}}{{if .BaseVerified}}{{$key = "diff.verified_at"}}{{end}} ({{with tparts $key}}{{index . 0}}{{$since.HTML}}{{index . 1}}{{end}}){{end}}.
+</div>
+{{if not .Sections}}
+<div class="text-sm text-slate-600">{{t "diff.no_changes"}}</div>
+{{end}}
+{{range .Sections}}
+<div class="mb-3 {{if .Significant}}border-l-4 border-amber-400 pl-3{{end}}">
+    <div class="font-semibold text-slate-900 text-sm mb-1 capitalize">{{.Name}}</div>
+    <div class="space-y-1">
+        {{range .Changes}}
+        <div class="text-xs">
+            <div class="font-mono text-slate-700 break-all">{{.Path}} <span class="text-slate-500">({{.Kind}})</span></div>
+            {{if .Old}}<div class="font-mono text-red-700 bg-red-50 rounded px-2 py-0.5 break-all">- {{.Old}}</div>{{end}}
+            {{if .New}}<div class="font-mono text-emerald-700 bg-emerald-50 rounded px-2 py-0.5 break-all">+ {{.New}}</div>{{end}}
+        </div>
+        {{end}}
+    </div>
+</div>
+{{end}}
+{{end}}
+`
+
+var sortControlsTemplate = `<!-- Sort Controls -->
+<label class="flex items-center gap-2 text-sm text-slate-600">
+    <span class="font-semibold whitespace-nowrap">{{t "sort.label"}}</span>
+    <select name="sort"
+            hx-get="/htmx/apps"
+            hx-trigger="change"
+            hx-target="#apps-container"
+            hx-swap="innerHTML"
+            hx-include="[name='q']"
+            class="px-3 py-2 bg-white border border-slate-300 rounded-md text-slate-900 focus:outline-none focus:ring-2 focus:ring-primary focus:border-primary text-sm">
+        {{range .}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{t .Label}}</option>
+        {{end}}
+    </select>
+</label>
+`
+
+var backendStatusTemplate = `<!-- Backend Status -->
+<span class="inline-flex items-center gap-2" title="{{if .Error}}{{.Error}}{{end}}{{if .CheckedAt}} ({{t "backend.checked" (.CheckedAt.Format "2006-01-02 15:04:05 MST")}}){{end}}">
+    {{if eq .Status "healthy"}}<span class="w-2 h-2 rounded-full bg-emerald-500"></span>
+    {{else if eq .Status "degraded"}}<span class="w-2 h-2 rounded-full bg-amber-500"></span>
+    {{else if eq .Status "unreachable"}}<span class="w-2 h-2 rounded-full bg-red-500"></span>
+    {{else}}<span class="w-2 h-2 rounded-full bg-slate-400"></span>{{end}}
+    <span>{{t "backend.label"}} {{t (print "backend." .Status)}}{{if .Version}}, v{{.Version}}{{end}}</span>
+</span>
+`
+
+// shellSafe matches arguments that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9._/:@%+=,-]+$`)
+
+// shellQuote quotes an argument for a POSIX shell, if needed.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
 	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
 
-	diff := time.Since(t)
+// verifyCommands returns the shell commands that rebuild a deployment at the verified commit
+// and compare the result with the enclave identities registered on chain.
+func verifyCommands(githubURL, commitSHA, deploymentName, cliVersion string) string {
+	dir := path.Base(strings.TrimSuffix(githubURL, "/"))
+	var b strings.Builder
+	if cliVersion != "" {
+		fmt.Fprintf(&b, "# Verified with oasis-cli %s\n", cliVersion)
+	}
+	fmt.Fprintf(&b, "git clone %s.git\n", shellQuote(strings.TrimSuffix(githubURL, "/")))
+	fmt.Fprintf(&b, "cd %s\n", shellQuote(dir))
+	fmt.Fprintf(&b, "git checkout %s\n", shellQuote(commitSHA))
+	fmt.Fprintf(&b, "oasis rofl build --verify --deployment %s", shellQuote(deploymentName))
+	return b.String()
+}
 
-	if diff < time.Minute {
-		return "just now"
+// explorerAppURL returns the explorer page of a ROFL app, or an empty string if the
+// network has no public explorer or the app ID is malformed.
+func explorerAppURL(network, appID string) string {
+	if network != "mainnet" && network != "testnet" {
+		return ""
 	}
-	if diff < time.Hour {
-		mins := int(diff.Minutes())
-		if mins == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", mins)
+	if rofl.ValidateAppID(appID) != nil {
+		return ""
 	}
-	if diff < 24*time.Hour {
-		hours := int(diff.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
+	return fmt.Sprintf("https://explorer.oasis.io/%s/sapphire/rofl/app/%s", network, appID)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
 	}
+	return sha
+}
 
-	days := int(diff.Hours() / 24)
-	if days == 1 {
-		return "1 day ago"
+// commitLabel names a commit by its tag and short SHA, e.g. "v1.4.2 (3fa9c2d)", or by its short SHA
+// alone if it has no tag.
+func commitLabel(sha, tag string) string {
+	if tag == "" || sha == "" {
+		return shortSHA(sha)
+	}
+	return fmt.Sprintf("%s (%s)", tag, shortSHA(sha))
+}
+
+// verifiedStreak describes how long a deployment has been verified without interruption.
+func verifiedStreak(loc *Locale, since time.Time, checks int64) string {
+	days := int(time.Since(since).Hours() / 24)
+	var streak string
+	switch days {
+	case 0:
+		streak = loc.T("streak.today")
+	case 1:
+		streak = loc.T("streak.day")
+	default:
+		streak = loc.T("streak.days", days)
+	}
+	if checks > 1 {
+		streak += " " + loc.T("streak.checks", checks)
 	}
-	return fmt.Sprintf("%d days ago", days)
+	return streak
 }
 
 func joinNetworks(networks []string) string {
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+var update = flag.Bool("update", false, "update the golden files of rendered templates")
+
+// fixtureTime is the time the timestamps of card fixtures refer to.
+var fixtureTime = time.Date(2025, 6, 1, 14, 3, 0, 0, time.UTC)
+
+// fixtureTimestamp returns a timestamp with fixed relative and absolute text, so that renders do
+// not depend on the current time.
+func fixtureTimestamp(relative string) Timestamp {
+	return Timestamp{Time: fixtureTime, Relative: relative, Absolute: "Jun 1, 2025, 14:03 UTC"}
+}
+
+// fixtureEnclaveIDs returns n enclave identities.
+func fixtureEnclaveIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := range n {
+		ids = append(ids, fmt.Sprintf("enclave%02dAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", i))
+	}
+	return ids
+}
+
+// fixtureApp returns the card data of an app with a manifest, without deployments.
+func fixtureApp(id int64) *AppCardData {
+	return &AppCardData{
+		ID:          id,
+		Name:        "Price Oracle",
+		Slug:        "price-oracle",
+		Version:     "1.4.2",
+		Description: "Publishes signed price feeds from a TDX enclave.",
+		GitHubURL:   "https://github.com/example/price-oracle",
+		Author:      "Example Labs <dev@example.com>",
+		Owner: &OwnerInfo{
+			Login:        "example",
+			Name:         "Example Labs",
+			URL:          "https://github.com/example",
+			Organization: true,
+			Verified:     true,
+		},
+		License:          "Apache-2.0",
+		LicenseOSI:       true,
+		TEE:              "tdx",
+		Kind:             "container",
+		Repository:       "https://github.com/example/price-oracle",
+		Homepage:         "https://oracle.example.com",
+		Memory:           512,
+		CPUs:             1,
+		StorageKind:      "disk-persistent",
+		StorageSize:      512,
+		Networks:         []string{"mainnet"},
+		NetworksStr:      "mainnet",
+		Firmware:         "https://example.com/ovmf.tdx.fd",
+		Kernel:           "https://example.com/stage1.bin",
+		Stage2:           "https://example.com/stage2-podman.tar.bz2",
+		ContainerCompose: "compose.yaml",
+		RoflYAML:         "name: price-oracle\nversion: 1.4.2\ntee: tdx\n",
+		ManifestURL:      "https://raw.githubusercontent.com/example/price-oracle/main/rofl.yaml",
+		ManifestPath:     "rofl.yaml",
+		DomainVerified:   "oracle.example.com",
+		DomainMethod:     "dns",
+	}
+}
+
+// cardFixtures are the card data rendered against the golden files, by name.
+var cardFixtures = map[string]func() *AppCardData{
+	"verified": func() *AppCardData {
+		data := fixtureApp(1)
+		data.Status = "verified"
+		data.Featured = true
+		data.MainnetDeployment = &DeploymentStatus{
+			Name:           "mainnet",
+			Status:         "verified",
+			CommitSHA:      "3fa9c2d1e0b7a6f5c4d3e2f1a0b9c8d7e6f5a4b3",
+			CommitSHAShort: "3fa9c2d",
+			CommitTag:      "v1.4.2",
+			CommitLabel:    "v1.4.2 (3fa9c2d)",
+			LastVerified:   fixtureTimestamp("2 hours ago"),
+			VerifiedStreak: "Continuously verified for 94 days",
+			FirstVerified:  "Feb 27, 2025",
+			EnclaveIDs:     fixtureEnclaveIDs(2),
+			ExplorerURL:    "https://explorer.oasis.io/mainnet/sapphire/rofl/app/rofl1qexample",
+			CLIVersion:     "0.14.1",
+			VerifyCommands: "git clone https://github.com/example/price-oracle\noasis rofl build --verify --deployment mainnet",
+			ProvenanceURL:  "/api/v1/apps/1/deployments/mainnet/provenance?commit=3fa9c2d1e0b7a6f5c4d3e2f1a0b9c8d7e6f5a4b3",
+			LiveChecked:    true,
+			LiveInstances:  2,
+			LiveCheckedAt:  fixtureTimestamp("5 minutes ago"),
+		}
+		data.Deployments = []DeploymentInfo{{
+			Name:        "mainnet",
+			Network:     "mainnet",
+			AppID:       "rofl1qexample",
+			ExplorerURL: "https://explorer.oasis.io/mainnet/sapphire/rofl/app/rofl1qexample",
+			Enclaves: []EnclaveIdentity{{
+				Type:  "TDX",
+				Value: "enclave00AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+				Components: []rofl.EnclaveComponent{
+					{Name: "MRTD", Description: "Measurement of the initial TD contents", Hex: "00aa11bb"},
+				},
+			}},
+		}}
+		data.Contracts = []ContractInfo{{
+			Label:       "Feed",
+			Network:     "mainnet",
+			Address:     "0x0000000000000000000000000000000000000001",
+			ExplorerURL: "https://explorer.oasis.io/mainnet/sapphire/address/0x0000000000000000000000000000000000000001",
+		}}
+		data.Uptime = &UptimeInfo{
+			Days:    3,
+			Percent: "75.0%",
+			Summary: "Verified 75.0% of 4 checks over the last 3 days",
+			Bars: []UptimeBar{
+				{X: 0, Y: 0, Height: 16, Class: "fill-emerald-500", Title: "2025-05-30: 2 of 2 checks verified"},
+				{X: 2, Y: 8, Height: 8, Class: "fill-amber-500", Title: "2025-06-01: 1 of 2 checks verified"},
+			},
+		}
+		return data
+	},
+	"failed": func() *AppCardData {
+		data := fixtureApp(2)
+		data.Status = "failed"
+		data.Networks = []string{"mainnet", "testnet"}
+		data.NetworksStr = "mainnet,testnet"
+		data.MainnetDeployment = &DeploymentStatus{
+			Name:            "mainnet",
+			Status:          "failed",
+			CommitSHA:       "9b8a7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b",
+			CommitSHAShort:  "9b8a7c6",
+			CommitLabel:     "9b8a7c6",
+			VerificationMsg: "Enclave identity mismatch: built enclave01AAAA= but the policy admits enclave00AAAA=",
+			LastVerified:    fixtureTimestamp("1 day ago"),
+			LogURL:          "/api/v1/apps/2/deployments/mainnet/log",
+			BuilderImage:    "ghcr.io/oasisprotocol/rofl-dev:v0.5.0",
+			PolicyViolations: []models.PolicyViolation{
+				{Rule: "require_tag", Message: "The verified commit is not tagged"},
+			},
+			Identities: []IdentityComparison{
+				{
+					Source:   "On-chain policy",
+					Expected: "enclave00AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+					Actual:   "enclave01AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+					Components: []ComponentComparison{
+						{Name: "TD measurement", Description: "Hash of the TD measurements", Expected: "00aa11bb", Actual: "00aa11cc"},
+					},
+				},
+				{Source: "Manifest", Expected: "enclave02AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", Actual: "enclave02AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", Match: true},
+				{Source: "Manifest", Actual: "enclave03AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+			},
+		}
+		data.OtherDeployments = []DeploymentStatus{{
+			Name:         "testnet",
+			Status:       "verified",
+			CommitSHA:    "9b8a7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b",
+			LastVerified: fixtureTimestamp("3 hours ago"),
+			EnclaveIDs:   fixtureEnclaveIDs(1),
+		}}
+		return data
+	},
+	"pending": func() *AppCardData {
+		data := fixtureApp(3)
+		data.Status = "pending"
+		data.Watchable = true
+		data.MainnetDeployment = &DeploymentStatus{
+			Name:         "mainnet",
+			Status:       "pending",
+			LastVerified: Timestamp{Relative: "Not yet verified"},
+		}
+		return data
+	},
+	"no-manifest": func() *AppCardData {
+		return &AppCardData{
+			ID:          4,
+			Name:        "Unknown App",
+			Description: "Verification pending...",
+			GitHubURL:   "https://github.com/example/new-app",
+			Status:      "pending",
+			Networks:    []string{},
+		}
+	},
+	"many-enclaves": func() *AppCardData {
+		data := fixtureApp(5)
+		data.Status = "verified"
+		data.MainnetDeployment = &DeploymentStatus{
+			Name:           "mainnet",
+			Status:         "verified",
+			CommitSHA:      "3fa9c2d1e0b7a6f5c4d3e2f1a0b9c8d7e6f5a4b3",
+			CommitSHAShort: "3fa9c2d",
+			CommitLabel:    "3fa9c2d",
+			LastVerified:   fixtureTimestamp("2 hours ago"),
+			EnclaveIDs:     fixtureEnclaveIDs(maxCardEnclaves + 4),
+		}
+		var enclaves []EnclaveIdentity
+		for _, id := range fixtureEnclaveIDs(maxCardEnclaves + 2) {
+			enclaves = append(enclaves, EnclaveIdentity{Type: "TDX", Value: id})
+		}
+		data.Deployments = []DeploymentInfo{{Name: "mainnet", Network: "mainnet", AppID: "rofl1qexample", Enclaves: enclaves}}
+		return data
+	},
+}
+
+// Test that cards render as in their golden files. Run the tests with -update to accept changes of
+// the output, and review the diff of testdata.
+func TestRenderCardGolden(t *testing.T) {
+	cardTemplate := parseLocalized("app-card", appCardTemplate)
+	loc := newLocale("en", time.UTC)
+
+	for name, fixture := range cardFixtures {
+		t.Run(name, func(t *testing.T) {
+			card, err := renderCard(cardTemplate, loc, fixture())
+			if err != nil {
+				t.Fatalf("renderCard failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "cards", name+".html")
+			if *update {
+				if err := os.WriteFile(golden, card, 0o644); err != nil { //nolint:gosec // Test data.
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(card, expected) {
+				t.Errorf("Card differs from %s (run with -update to accept): %s", golden, firstDifference(expected, card))
+			}
+		})
+	}
+}
+
+// Test that every part of a card labels its elements with IDs unique in the page.
+func TestRenderCardUniqueIDs(t *testing.T) {
+	cardTemplate := parseLocalized("app-card", appCardTemplate)
+	loc := newLocale("en", time.UTC)
+
+	seen := make(map[string]string)
+	for name, fixture := range cardFixtures {
+		card, err := renderCard(cardTemplate, loc, fixture())
+		if err != nil {
+			t.Fatalf("%s: renderCard failed: %v", name, err)
+		}
+		for _, attr := range strings.Split(string(card), ` id="`)[1:] {
+			id := attr[:strings.IndexByte(attr, '"')]
+			if other, ok := seen[id]; ok {
+				t.Errorf("%s: element ID %q is also used by %s", name, id, other)
+			}
+			seen[id] = name
+		}
+	}
+}
+
+// Test that the manifest toggle is labeled with the file name the manifest was found under.
+func TestRenderCardManifestFile(t *testing.T) {
+	cardTemplate := parseLocalized("app-card", appCardTemplate)
+	loc := newLocale("en", time.UTC)
+
+	app := fixtureApp(1)
+	app.ManifestPath = "deploy/rofl-mainnet.yaml"
+	card, err := renderCard(cardTemplate, loc, app)
+	if err != nil {
+		t.Fatalf("renderCard failed: %v", err)
+	}
+	for _, label := range []string{"Show rofl-mainnet.yaml", "Hide rofl-mainnet.yaml"} {
+		if !bytes.Contains(card, []byte(label)) {
+			t.Errorf("Expected the card to contain %q", label)
+		}
+	}
+	if bytes.Contains(card, []byte("Show rofl.yaml")) {
+		t.Errorf("Expected no toggle labeled with the default file name")
+	}
+}
+
+// firstDifference describes the first line in which two renders differ.
+func firstDifference(expected, got []byte) string {
+	expectedLines := strings.Split(string(expected), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(expectedLines) || i < len(gotLines); i++ {
+		var want, have string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(gotLines) {
+			have = gotLines[i]
+		}
+		if want != have {
+			return fmt.Sprintf("line %d: expected %q, got %q", i+1, strings.TrimSpace(want), strings.TrimSpace(have))
+		}
+	}
+	return fmt.Sprintf("expected %d lines, got %d", len(expectedLines), len(gotLines))
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/i18n"
+)
+
+const (
+	// langCookie is the cookie in which the language chosen with the lang parameter is kept.
+	langCookie = "lang"
+	// tzCookie is the cookie in which the page stores the IANA time zone of the reader.
+	tzCookie = "tz"
+)
+
+// Locale formats text and timestamps for a reader, in their language and time zone.
+type Locale struct {
+	Language string         // Tag of a supported language.
+	Location *time.Location // Time zone of absolute times.
+	catalog  *i18n.Catalog
+}
+
+// defaultLocale formats in the default language and UTC, for responses that are not localized,
+// like the JSON API.
+var defaultLocale = newLocale(i18n.Default, time.UTC)
+
+func newLocale(language string, location *time.Location) *Locale {
+	catalog := i18n.Lookup(language)
+	return &Locale{Language: catalog.Language, Location: location, catalog: catalog}
+}
+
+// localeFor returns the locale of a request. The language is the one of the lang parameter, of
+// the lang cookie, or else the most preferred supported one of the Accept-Language header. The
+// time zone is the one of the tz cookie, UTC if it has none.
+func localeFor(r *http.Request) *Locale {
+	location := time.UTC
+	if cookie, err := r.Cookie(tzCookie); err == nil {
+		if loc, ok := loadLocation(cookie.Value); ok {
+			location = loc
+		}
+	}
+
+	language := i18n.Supported(r.URL.Query().Get("lang"))
+	if cookie, err := r.Cookie(langCookie); language == "" && err == nil {
+		language = i18n.Supported(cookie.Value)
+	}
+	if language == "" {
+		language = i18n.Negotiate(r.Header.Get("Accept-Language"))
+	}
+	return newLocale(language, location)
+}
+
+// rememberLanguage keeps the language chosen with the lang parameter of a page request in a
+// cookie, so that the fragments the page loads are rendered in it too.
+func rememberLanguage(w http.ResponseWriter, r *http.Request) {
+	language := i18n.Supported(r.URL.Query().Get("lang"))
+	if language == "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     langCookie,
+		Value:    language,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// T returns a message in the language of the locale, formatted with the given arguments.
+func (l *Locale) T(key string, args ...any) string {
+	return l.catalog.T(key, args...)
+}
+
+// Key identifies the locale, e.g. in cache keys of rendered pages.
+func (l *Locale) Key() string {
+	return l.Language + "/" + l.Location.String()
+}
+
+// locations caches the time zones loaded by name.
+var locations sync.Map
+
+// loadLocation returns the IANA time zone of the given name.
+func loadLocation(name string) (*time.Location, bool) {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), true
+	}
+	// Local is the time zone of the server, not of the reader.
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locations.Store(name, loc)
+	return loc, true
+}
+
+// Timestamp is a point in time formatted for display.
+type Timestamp struct {
+	Time     time.Time // Zero if the time is not known.
+	Relative string    // e.g. "2 hours ago".
+	Absolute string    // e.g. "Jun 1, 2025, 14:03 UTC", empty if the time is not known.
+}
+
+// String returns the relative time.
+func (t Timestamp) String() string {
+	return t.Relative
+}
+
+// HTML returns the relative time as a <time> element, carrying the ISO 8601 time as its datetime
+// and the absolute time as its title.
+func (t Timestamp) HTML() template.HTML {
+	if t.Time.IsZero() {
+		return template.HTML(html.EscapeString(t.Relative)) //nolint:gosec // Escaped.
+	}
+	return template.HTML(fmt.Sprintf(`<time datetime="%s" title="%s">%s</time>`, //nolint:gosec // Escaped.
+		t.Time.UTC().Format(time.RFC3339), html.EscapeString(t.Absolute), html.EscapeString(t.Relative)))
+}
+
+// Time formats a time, zero if it is not known.
+func (l *Locale) Time(t time.Time) Timestamp {
+	if t.IsZero() {
+		return Timestamp{Relative: l.T("status.not_yet_verified")}
+	}
+	return Timestamp{
+		Time:     t,
+		Relative: l.relative(time.Since(t)),
+		Absolute: t.In(l.Location).Format(l.T("time.datetime_layout")),
+	}
+}
+
+// NullTime formats a nullable time.
+func (l *Locale) NullTime(t sql.NullTime) Timestamp {
+	if !t.Valid {
+		return l.Time(time.Time{})
+	}
+	return l.Time(t.Time)
+}
+
+// Date formats the day of a nullable time, or returns an empty string if it is not set.
+func (l *Locale) Date(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.In(l.Location).Format(l.T("time.date_layout"))
+}
+
+// relative describes how long ago something happened.
+func (l *Locale) relative(diff time.Duration) string {
+	plural := func(n int, one, other string) string {
+		if n == 1 {
+			return l.T(one)
+		}
+		return l.T(other, n)
+	}
+
+	switch {
+	case diff < time.Minute:
+		return l.T("time.just_now")
+	case diff < time.Hour:
+		return plural(int(diff.Minutes()), "time.minute", "time.minutes")
+	case diff < 24*time.Hour:
+		return plural(int(diff.Hours()), "time.hour", "time.hours")
+	default:
+		return plural(int(diff.Hours()/24), "time.day", "time.days")
+	}
+}
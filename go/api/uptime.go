@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// UptimeInfo is the share of successful verification checks of an app, for display on its card.
+type UptimeInfo struct {
+	Days    int         // Length of the window.
+	Percent string      // e.g. "99.2%".
+	Summary string      // e.g. "Verified 99.2% of 412 checks over the last 90 days".
+	Bars    []UptimeBar // Sparkline of the share per day, oldest first.
+}
+
+// UptimeBar is a day of the uptime sparkline, drawn in a viewBox 1 unit wide per day and
+// sparklineHeight units high.
+type UptimeBar struct {
+	X      int
+	Y      float64
+	Height float64
+	Class  string // Fill color by share: all, some, or none verified.
+	Title  string
+}
+
+// sparklineHeight is the height of the uptime sparkline in viewBox units.
+const sparklineHeight = 16
+
+// UptimeSummary is the share of successful verification checks of an app, as listed by the JSON
+// API.
+type UptimeSummary struct {
+	WindowDays int           `json:"window_days"`
+	Checks     int64         `json:"checks"`
+	Verified   int64         `json:"verified"`
+	Percent    float64       `json:"percent"` // Rounded down to one decimal.
+	Daily      []UptimeDaily `json:"daily"`   // Oldest first.
+}
+
+// UptimeDaily counts the verification checks of an app finished on a day.
+type UptimeDaily struct {
+	Date     string `json:"date"` // YYYY-MM-DD in UTC.
+	Checks   int64  `json:"checks"`
+	Verified int64  `json:"verified"`
+}
+
+// appUptime returns the verification checks of an app over the uptime window, or nil if they
+// could not be loaded.
+func (s *Server) appUptime(ctx context.Context, appID int64) *models.Uptime {
+	uptime, err := s.db.GetAppUptime(ctx, appID, db.UptimeDays, time.Now())
+	if err != nil {
+		s.logger.Error("failed to get uptime", "app_id", appID, "error", err)
+		return nil
+	}
+	return uptime
+}
+
+// uptimePercent returns the share of verified checks in percent, rounded down to one decimal so
+// that a single failure never shows as 100%.
+func uptimePercent(uptime *models.Uptime) float64 {
+	if uptime.Checks == 0 {
+		return 0
+	}
+	return math.Floor(float64(uptime.Verified)*1000/float64(uptime.Checks)) / 10
+}
+
+// newUptimeInfo formats the uptime of an app for its card, nil if it has no checks in the window.
+func newUptimeInfo(loc *Locale, uptime *models.Uptime) *UptimeInfo {
+	if uptime == nil || uptime.Checks == 0 {
+		return nil
+	}
+
+	percent := fmt.Sprintf("%.1f%%", uptimePercent(uptime))
+	info := &UptimeInfo{
+		Days:    uptime.Days,
+		Percent: percent,
+		Summary: loc.T("uptime.summary", percent, uptime.Checks, uptime.Days),
+	}
+	for i, day := range uptime.Daily {
+		if day.Checks == 0 {
+			continue
+		}
+		share := float64(day.Verified) / float64(day.Checks)
+		height := math.Max(share*sparklineHeight, 2)
+		class := "fill-amber-500"
+		switch day.Verified {
+		case day.Checks:
+			class = "fill-emerald-500"
+		case 0:
+			class = "fill-red-500"
+		}
+		info.Bars = append(info.Bars, UptimeBar{
+			X:      i,
+			Y:      sparklineHeight - height,
+			Height: height,
+			Class:  class,
+			Title:  loc.T("uptime.day", day.Date.Format(time.DateOnly), day.Verified, day.Checks),
+		})
+	}
+	return info
+}
+
+// newUptimeSummary builds the JSON API representation of the uptime of an app, nil if it could not
+// be loaded.
+func newUptimeSummary(uptime *models.Uptime) *UptimeSummary {
+	if uptime == nil {
+		return nil
+	}
+
+	summary := &UptimeSummary{
+		WindowDays: uptime.Days,
+		Checks:     uptime.Checks,
+		Verified:   uptime.Verified,
+		Percent:    uptimePercent(uptime),
+		Daily:      make([]UptimeDaily, 0, len(uptime.Daily)),
+	}
+	for _, day := range uptime.Daily {
+		summary.Daily = append(summary.Daily, UptimeDaily{
+			Date:     day.Date.Format(time.DateOnly),
+			Checks:   day.Checks,
+			Verified: day.Verified,
+		})
+	}
+	return summary
+}
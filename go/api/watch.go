@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+const (
+	watchConfirmTTL       = 7 * 24 * time.Hour // How long a confirmation link is valid.
+	watchResendInterval   = time.Hour          // Minimum time between confirmations of an address to an app.
+	watchMaxPendingPerDay = 5                  // Confirmations sent to an address per day.
+)
+
+// WatchPageData holds the data for rendering the pages confirming and ending subscriptions.
+type WatchPageData struct {
+	Layout   *Layout
+	Language string
+	Title    string
+	Message  string
+	Action   string // Path the button posts the token to, empty once done.
+	Token    string
+	Button   string
+}
+
+var watchPageTemplate = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}} · {{.Layout.Title}}</title>
+<script src="{{asset "tailwind.js"}}"></script>
+</head>
+<body class="bg-slate-50 min-h-screen flex items-center justify-center">
+<div class="bg-white border border-slate-200 rounded-lg p-6 shadow-sm w-96 space-y-4">
+    <h1 class="text-xl font-bold text-slate-900">{{.Title}}</h1>
+    <p class="text-sm text-slate-700">{{.Message}}</p>
+    {{if .Action}}
+    <form method="post" action="{{.Action}}">
+        <input type="hidden" name="token" value="{{.Token}}">
+        <button type="submit" class="w-full px-3 py-2 bg-slate-800 hover:bg-slate-700 text-white rounded-md text-sm font-semibold">{{.Button}}</button>
+    </form>
+    {{end}}
+    <a href="/" class="block text-sm text-slate-600 hover:text-slate-900 underline">{{t "watch.back"}}</a>
+</div>
+</body>
+</html>
+`
+
+// handleWatch handles POST /htmx/apps/{id}/watch, emailing a link confirming the subscription of
+// an address to the status changes of an app. The response is the same whether or not the address
+// was already subscribed, so that it does not reveal subscriptions.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.Worker.Notifications.SMTP.Enabled() {
+		http.Error(w, "Email notifications are not enabled", http.StatusNotFound)
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid app ID", http.StatusBadRequest)
+		return
+	}
+	app, err := s.getApp(r.Context(), id)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	loc := localeFor(r)
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	addr, err := mail.ParseAddress(strings.TrimSpace(r.PostFormValue("email")))
+	if err != nil || addr.Name != "" {
+		writeWatchResult(w, loc.T("watch.invalid_email"))
+		return
+	}
+
+	if err := s.requestWatch(r.Context(), app, addr.Address); err != nil {
+		s.logger.Error("failed to request subscription", "app_id", app.ID, "error", err)
+		writeWatchResult(w, loc.T("watch.failed"))
+		return
+	}
+	writeWatchResult(w, loc.T("watch.check_inbox"))
+}
+
+// requestWatch stores a pending subscription and emails its confirmation link, unless the address
+// is already subscribed or was sent a confirmation recently. The link points to the configured URL
+// of the app's namespace rather than the host of the request, which clients choose.
+func (s *Server) requestWatch(ctx context.Context, app *models.App, email string) error {
+	sub, err := s.db.GetSubscription(ctx, app.ID, email)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if sub != nil && (sub.ConfirmedAt.Valid || now.Sub(sub.ConfirmationSentAt) < watchResendInterval) {
+		return nil
+	}
+	pending, err := s.db.CountPendingSubscriptions(ctx, email, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if pending >= watchMaxPendingPerDay {
+		s.logger.Warn("too many pending subscriptions of an address", "app_id", app.ID, "pending", pending)
+		return nil
+	}
+
+	confirmToken, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	unsubscribeToken, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	sub = &models.Subscription{
+		AppID:              app.ID,
+		Email:              email,
+		UnsubscribeToken:   unsubscribeToken,
+		ConfirmationSentAt: now,
+	}
+	if err := s.db.SavePendingSubscription(ctx, sub, hashSessionToken(confirmToken), now.Add(-watchConfirmTTL)); err != nil {
+		return err
+	}
+
+	confirmURL := s.cfg.Worker.Notifications.SMTP.LinkURL(app.Namespace) + "/watch/confirm?token=" + confirmToken
+	return s.worker.SendSubscriptionConfirmation(email, strings.TrimPrefix(app.GitHubURL, "https://github.com/"), confirmURL)
+}
+
+// writeWatchResult writes the fragment replacing the watch form.
+func writeWatchResult(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprintf(w, `<p class="mt-3 text-sm text-slate-700">%s</p>`, template.HTMLEscapeString(message))
+}
+
+// handleWatchConfirmPage handles GET /watch/confirm, the page of confirmation links. Confirming
+// takes a POST, so that mail scanners following links do not confirm subscriptions.
+func (s *Server) handleWatchConfirmPage(w http.ResponseWriter, r *http.Request) {
+	loc := localeFor(r)
+	s.renderWatchPage(w, loc, WatchPageData{
+		Title:   loc.T("watch.confirm_title"),
+		Message: loc.T("watch.confirm_prompt"),
+		Action:  "confirm",
+		Token:   r.URL.Query().Get("token"),
+		Button:  loc.T("watch.confirm_button"),
+	})
+}
+
+// handleWatchConfirm handles POST /watch/confirm, confirming a subscription.
+func (s *Server) handleWatchConfirm(w http.ResponseWriter, r *http.Request) {
+	loc := localeFor(r)
+	data := WatchPageData{Title: loc.T("watch.confirm_title")}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	sub, err := s.db.ConfirmSubscription(r.Context(), hashSessionToken(r.PostFormValue("token")), time.Now().Add(-watchConfirmTTL))
+	switch {
+	case errors.Is(err, db.ErrSubscriptionNotFound):
+		data.Message = loc.T("watch.confirm_expired")
+	case err != nil:
+		s.logger.Error("failed to confirm subscription", "error", err)
+		http.Error(w, "Failed to confirm subscription", http.StatusInternalServerError)
+		return
+	default:
+		data.Message = loc.T("watch.confirmed", s.subscriptionAppName(r.Context(), sub))
+	}
+	s.renderWatchPage(w, loc, data)
+}
+
+// handleWatchUnsubscribePage handles GET /watch/unsubscribe, the page of unsubscribe links.
+func (s *Server) handleWatchUnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	loc := localeFor(r)
+	s.renderWatchPage(w, loc, WatchPageData{
+		Title:   loc.T("watch.unsubscribe_title"),
+		Message: loc.T("watch.unsubscribe_prompt"),
+		Action:  "unsubscribe",
+		Token:   r.URL.Query().Get("token"),
+		Button:  loc.T("watch.unsubscribe_button"),
+	})
+}
+
+// handleWatchUnsubscribe handles POST /watch/unsubscribe, ending a subscription. The token is also
+// accepted in the query, for one-click unsubscribing from the List-Unsubscribe header of emails.
+func (s *Server) handleWatchUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	loc := localeFor(r)
+	data := WatchPageData{Title: loc.T("watch.unsubscribe_title")}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	sub, err := s.db.DeleteSubscription(r.Context(), r.FormValue("token"))
+	switch {
+	case errors.Is(err, db.ErrSubscriptionNotFound):
+		data.Message = loc.T("watch.not_subscribed")
+	case err != nil:
+		s.logger.Error("failed to delete subscription", "error", err)
+		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	default:
+		data.Message = loc.T("watch.unsubscribed", s.subscriptionAppName(r.Context(), sub))
+	}
+	s.renderWatchPage(w, loc, data)
+}
+
+// subscriptionAppName returns the repository path of the app of a subscription.
+func (s *Server) subscriptionAppName(ctx context.Context, sub *models.Subscription) string {
+	app, err := s.db.GetAppByID(ctx, sub.AppID)
+	if err != nil {
+		return fmt.Sprintf("app %d", sub.AppID)
+	}
+	return strings.TrimPrefix(app.GitHubURL, "https://github.com/")
+}
+
+// renderWatchPage renders a page confirming or ending a subscription. Its URL holds a token, so
+// it is neither cached nor sent as a referrer.
+func (s *Server) renderWatchPage(w http.ResponseWriter, loc *Locale, data WatchPageData) {
+	data.Layout = s.layout
+	data.Language = loc.Language
+
+	var buf bytes.Buffer
+	if err := s.watchTemplate.For(loc).Execute(&buf, data); err != nil {
+		s.logger.Error("failed to render watch page", "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/mockbackend"
+)
+
+var (
+	devBackendAddr string
+	devScript      string
+
+	devCmd = &cobra.Command{
+		Use:   "dev",
+		Short: "Run the registry against an embedded mock verification backend",
+		Long: `Run the registry with the verification worker enabled against an embedded mock of
+rofl-app-backend, so that verification can be exercised end to end without real infrastructure.
+
+The mock implements SIWE sign-in, task submission, and polling. By default every task verifies
+successfully; --script loads a YAML file of outcomes per repository and deployment, e.g.:
+
+  outcomes:
+    - repository: https://github.com/my-org/app
+      deployment: testnet
+      result: failed
+      err: "enclave identity mismatch"
+      duration: 10
+      report:
+        enclaves:
+          - source: onchain
+            expected: "<identity admitted on chain>"
+            actual: "<identity built>"`,
+		Args: cobra.NoArgs,
+		RunE: runDev,
+	}
+)
+
+func init() {
+	devCmd.Flags().StringVar(&devBackendAddr, "backend-addr", "127.0.0.1:0", "listen address of the mock backend")
+	devCmd.Flags().StringVar(&devScript, "script", "", "YAML file with scripted verification outcomes")
+	devCmd.Flags().BoolVar(&skipSeed, "skip-seed", false, "do not sync apps from the registry on startup")
+	rootCmd.AddCommand(devCmd)
+}
+
+func runDev(cmd *cobra.Command, _ []string) error {
+	logger := newLogger()
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var script *mockbackend.Script
+	if devScript != "" {
+		if script, err = mockbackend.LoadScript(devScript); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", devBackendAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	go func() {
+		if err := mockbackend.New(script, logger).Serve(ctx, listener); err != nil {
+			logger.Error("mock backend stopped", "error", err)
+		}
+	}()
+
+	cfg.Worker.Enabled = true
+	cfg.Worker.BackendURL = "http://" + listener.Addr().String()
+	logger.Warn("⚠️  DEV MODE - verifying against a mock backend, results are not real", "backend_url", cfg.Worker.BackendURL)
+
+	return serve(logger, cfg)
+}
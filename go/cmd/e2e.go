@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ptrus/rofl-attestations/api"
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/mockbackend"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/storage"
+	"github.com/ptrus/rofl-attestations/worker"
+)
+
+var (
+	e2eVerbose bool
+
+	e2eCmd = &cobra.Command{
+		Use:   "e2e",
+		Short: "Run an end-to-end test against a mock backend",
+		Long: `Run the registry end to end without external services: fixture apps are seeded into an
+in-memory database, verified once against the embedded mock backend with manifests served from
+fixtures instead of GitHub, and the API server is started on a random port to check the rendered
+output. Exits with an error if any check fails.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runE2E,
+	}
+)
+
+func init() {
+	e2eCmd.Flags().BoolVar(&e2eVerbose, "verbose", false, "log everything instead of only warnings")
+	rootCmd.AddCommand(e2eCmd)
+}
+
+// e2eAppID is the app ID of all fixture deployments.
+const e2eAppID = "rofl1qzp3c6zt96r5c5sw0sljlvepwgg4u23atgh4legq"
+
+// e2eCommitSHA is the commit the mock backend reports building for fixtures with lockfiles.
+const e2eCommitSHA = "e2e0000000000000000000000000000000000001"
+
+// e2eFixture is an app seeded by the end-to-end test, with its expected status.
+type e2eFixture struct {
+	url         string
+	name        string
+	deployments []string
+	outcome     *mockbackend.Outcome // Scripted backend outcome, nil to verify successfully.
+	status      string
+	logEntries  int               // Verification log entries expected for the app.
+	lockfiles   map[string]string // Lockfiles served at e2eCommitSHA.
+}
+
+var e2eFixtures = []e2eFixture{
+	{
+		url:         "https://github.com/e2e/verified-app",
+		name:        "Verified App",
+		deployments: []string{"mainnet", "testnet"},
+		outcome:     &mockbackend.Outcome{CommitSHA: e2eCommitSHA},
+		status:      "verified",
+		logEntries:  2,
+		lockfiles: map[string]string{
+			"Cargo.lock": "version = 3\n\n[[package]]\nname = \"app\"\nversion = \"1.0.0\"\ndependencies = [\"serde\"]\n\n" +
+				"[[package]]\nname = \"serde\"\nversion = \"1.0.193\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n",
+		},
+	},
+	{
+		url:         "https://github.com/e2e/failing-app",
+		name:        "Failing App",
+		deployments: []string{"mainnet"},
+		outcome:     &mockbackend.Outcome{Result: mockbackend.ResultFailed, Err: "enclave identity mismatch"},
+		status:      "failed",
+		logEntries:  1,
+	},
+	{
+		url:         "https://github.com/e2e/unreachable-app",
+		name:        "Unreachable App",
+		deployments: []string{"mainnet"},
+		outcome:     &mockbackend.Outcome{SubmitStatus: http.StatusServiceUnavailable},
+		status:      "pending",
+	},
+}
+
+// manifest returns the rofl.yaml of a fixture.
+func (f *e2eFixture) manifest() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "name: %s\nversion: 1.0.0\ntee: tdx\nkind: container\n", f.name)
+	b.WriteString("artifacts:\n  firmware: firmware.fd\n  kernel: bzImage\n  stage2: stage2.tar.bz2\n" +
+		"  container:\n    runtime: runtime.elf\n    compose: compose.yaml\ndeployments:\n")
+	for _, name := range f.deployments {
+		fmt.Fprintf(&b, "  %s:\n    app_id: %s\n    network: %s\n    paratime: sapphire\n", name, e2eAppID, name)
+	}
+	return b.String()
+}
+
+// fixtureTransport serves the manifests and repositories of fixture apps in place of GitHub. All other requests
+// fail with 404 Not Found, so nothing leaves the process.
+type fixtureTransport map[string]string
+
+func (t fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := t[req.URL.String()]
+	status := http.StatusOK
+	if !ok {
+		status, body = http.StatusNotFound, "Not Found"
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": {"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func runE2E(cmd *cobra.Command, _ []string) error {
+	level := slog.LevelWarn
+	if e2eVerbose {
+		level = slog.LevelInfo
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Worker.PollInterval = 1
+	cfg.Worker.DeploymentInterval = 0
+
+	database, err := db.New(fmt.Sprintf("file:e2e-%d?mode=memory&cache=shared", os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+	if err := database.InitSchema(); err != nil {
+		return fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	// Start the mock backend with the scripted outcomes of the fixtures.
+	script := &mockbackend.Script{}
+	files := make(fixtureTransport)
+	for _, f := range e2eFixtures {
+		if f.outcome != nil {
+			outcome := *f.outcome
+			outcome.Repository = f.url
+			script.Outcomes = append(script.Outcomes, outcome)
+		}
+		files[github.RawURL(f.url, "main", "rofl.yaml")] = f.manifest()
+		files["https://api.github.com/repos/"+strings.TrimPrefix(f.url, "https://github.com/")] = fmt.Sprintf(`{"html_url": %q}`, f.url)
+		for name, content := range f.lockfiles {
+			files[github.RawURL(f.url, e2eCommitSHA, name)] = content
+		}
+		if err := database.UpsertApp(ctx, models.DefaultNamespace, f.url, "main", false, "", "", nil); err != nil {
+			return fmt.Errorf("failed to seed %s: %w", f.url, err)
+		}
+	}
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	go func() {
+		if err := mockbackend.New(script, logger).Serve(ctx, backendListener); err != nil {
+			logger.Error("mock backend stopped", "error", err)
+		}
+	}()
+	cfg.Worker.BackendURL = "http://" + backendListener.Addr().String()
+
+	// Verify all apps once.
+	clients, err := newHTTPClients(cfg)
+	if err != nil {
+		return err
+	}
+	gh := github.NewClient(clients.ClientWithTransport(httpclient.GitHub, files), &cfg.GitHub, cfg.Apps.ManifestFilenames, logger)
+	artifacts, err := storage.New(&cfg.Storage, clients.Client(httpclient.Storage))
+	if err != nil {
+		return fmt.Errorf("failed to create artifact storage: %w", err)
+	}
+	verificationWorker, err := worker.New(&cfg.Worker, &cfg.HTTP, database, gh, artifacts, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create worker: %w", err)
+	}
+	fmt.Printf("verifying %d fixture apps against %s\n", len(e2eFixtures), cfg.Worker.BackendURL)
+	if err := verificationWorker.RunOnce(ctx); err != nil {
+		return err
+	}
+
+	// Serve the API and check what it renders.
+	server, err := api.New(cfg, database, verificationWorker, artifacts, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create API server: %w", err)
+	}
+	apiListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	go func() {
+		if err := server.Serve(ctx, apiListener); err != nil {
+			logger.Error("API server stopped", "error", err)
+		}
+	}()
+
+	checks := &e2eChecks{base: "http://" + apiListener.Addr().String()}
+	checks.run(ctx)
+	if checks.failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", checks.failed, checks.total)
+	}
+	fmt.Printf("all %d checks passed\n", checks.total)
+	return nil
+}
+
+// e2eChecks runs checks against the API server and reports their outcome.
+type e2eChecks struct {
+	base   string
+	total  int
+	failed int
+}
+
+// check reports the outcome of a check.
+func (c *e2eChecks) check(name string, err error) {
+	c.total++
+	if err != nil {
+		c.failed++
+		fmt.Printf("FAIL %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("ok   %s\n", name)
+}
+
+// get fetches a path from the API server.
+func (c *e2eChecks) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned HTTP %d", path, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// run checks the JSON API, the rendered app list and pages, and the verification log.
+func (c *e2eChecks) run(ctx context.Context) {
+	var apps []api.AppSummary
+	body, err := c.get(ctx, "/api/apps")
+	if err == nil {
+		err = json.Unmarshal(body, &apps)
+	}
+	c.check("list apps", err)
+	byURL := make(map[string]api.AppSummary, len(apps))
+	for _, app := range apps {
+		byURL[app.GitHubURL] = app
+	}
+
+	list, listErr := c.get(ctx, "/htmx/apps")
+	for _, f := range e2eFixtures {
+		app, ok := byURL[f.url]
+		switch {
+		case !ok:
+			err = errors.New("app not listed")
+		case app.Status != f.status:
+			err = fmt.Errorf("expected status %q, got %q", f.status, app.Status)
+		default:
+			err = nil
+		}
+		c.check(f.name+" status", err)
+
+		if err = listErr; err == nil && !strings.Contains(string(list), f.name) {
+			err = errors.New("app card not rendered")
+		}
+		c.check(f.name+" card", err)
+
+		if ok && app.Slug != "" {
+			page, err := c.get(ctx, "/apps/"+app.Slug)
+			if err == nil && !strings.Contains(string(page), f.name) {
+				err = errors.New("app name not rendered")
+			}
+			c.check(f.name+" page", err)
+		}
+
+		if ok && len(f.lockfiles) > 0 {
+			var report models.DependencyReport
+			body, err := c.get(ctx, fmt.Sprintf("/api/v1/apps/%d/dependencies", app.ID))
+			if err == nil {
+				err = json.Unmarshal(body, &report)
+			}
+			if err == nil && len(report.Lockfiles) != len(f.lockfiles) {
+				err = fmt.Errorf("expected %d lockfiles, got %d", len(f.lockfiles), len(report.Lockfiles))
+			}
+			c.check(f.name+" dependencies", err)
+		}
+
+		if ok && f.status == "verified" {
+			for _, deployment := range f.deployments {
+				var statement api.ProvenanceStatement
+				body, err := c.get(ctx, fmt.Sprintf("/api/v1/apps/%d/deployments/%s/provenance", app.ID, deployment))
+				if err == nil {
+					err = json.Unmarshal(body, &statement)
+				}
+				if err == nil && len(statement.Predicate.BuildDefinition.ResolvedDependencies) == 0 {
+					err = errors.New("no dependencies")
+				}
+				if err == nil && statement.Predicate.BuildDefinition.ResolvedDependencies[0].Digest["gitCommit"] != e2eCommitSHA {
+					err = fmt.Errorf("expected commit %s", e2eCommitSHA)
+				}
+				c.check(f.name+" "+deployment+" provenance", err)
+			}
+		}
+	}
+
+	var root struct {
+		Size int `json:"size"`
+	}
+	body, err = c.get(ctx, "/api/v1/log")
+	if err == nil {
+		err = json.Unmarshal(body, &root)
+	}
+	expected := 0
+	for _, f := range e2eFixtures {
+		expected += f.logEntries
+	}
+	if err == nil && root.Size != expected {
+		err = fmt.Errorf("expected %d entries, got %d", expected, root.Size)
+	}
+	c.check("verification log", err)
+
+	// Every deployment changed status once, from none to its first result.
+	var events struct {
+		Events []json.RawMessage `json:"events"`
+	}
+	body, err = c.get(ctx, "/api/v1/events")
+	if err == nil {
+		err = json.Unmarshal(body, &events)
+	}
+	if err == nil && len(events.Events) != expected {
+		err = fmt.Errorf("expected %d status events, got %d", expected, len(events.Events))
+	}
+	c.check("status events", err)
+
+	body, err = c.get(ctx, "/feed.xml")
+	if err == nil && bytes.Count(body, []byte("<item>")) != expected {
+		err = fmt.Errorf("expected %d feed items", expected)
+	}
+	c.check("status feed", err)
+}
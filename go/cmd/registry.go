@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// registryFields lists the fields of an apps registry entry.
+var registryFields = map[string]bool{
+	"url":             true,
+	"ref":             true,
+	"featured":        true,
+	"logo":            true,
+	"attestation_url": true,
+	"deployments":     true,
+	"allow_fork":      true,
+}
+
+// registryEntry is an entry of the apps registry being validated.
+type registryEntry struct {
+	repo      config.GitHubRepo
+	index     int      // Position of the entry in the registry.
+	line      int      // Line of the entry in apps.yaml, 0 for the local fallback list.
+	problems  []string // Problems found while decoding the entry.
+	malformed bool     // The entry is not a mapping, so its fields are not checked.
+}
+
+// parseAppsRegistry parses apps.yaml, returning its valid entries and the problems of invalid ones.
+func parseAppsRegistry(data []byte) ([]registryEntry, []models.RegistryEntryError, error) {
+	var registry struct {
+		Apps []yaml.Node `yaml:"apps"`
+	}
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	entries := make([]registryEntry, len(registry.Apps))
+	for i, node := range registry.Apps {
+		entry := &entries[i]
+		entry.index = i
+		entry.line = node.Line
+		if node.Kind != yaml.MappingNode {
+			entry.problems = append(entry.problems, "entry must be a mapping")
+			entry.malformed = true
+			continue
+		}
+		for j := 0; j < len(node.Content); j += 2 {
+			if key := node.Content[j].Value; !registryFields[key] {
+				entry.problems = append(entry.problems, fmt.Sprintf("%s: unknown field", key))
+			}
+		}
+		var typeErr *yaml.TypeError
+		if err := node.Decode(&entry.repo); errors.As(err, &typeErr) {
+			entry.problems = append(entry.problems, typeErr.Errors...)
+		} else if err != nil {
+			entry.problems = append(entry.problems, err.Error())
+		}
+	}
+
+	valid, errs := validateRegistry(entries)
+	return valid, errs, nil
+}
+
+// registryKey normalizes a repository URL for comparing registry entries.
+func registryKey(repoURL string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git"))
+}
+
+// validateRegistry checks registry entries for missing fields, unsupported providers, invalid
+// URLs, and duplicates. Entries with problems are left out of the returned list.
+func validateRegistry(entries []registryEntry) ([]registryEntry, []models.RegistryEntryError) {
+	var (
+		valid []registryEntry
+		errs  []models.RegistryEntryError
+	)
+	seen := make(map[string]int)
+	for _, entry := range entries {
+		i := entry.index
+		repo := entry.repo
+		problems := entry.problems
+		if entry.malformed {
+			errs = append(errs, models.RegistryEntryError{Index: i, Line: entry.line, Errors: problems})
+			continue
+		}
+
+		if repo.URL == "" {
+			problems = append(problems, "url: required")
+		} else if u, err := url.Parse(repo.URL); err != nil || u.Host != "github.com" {
+			problems = append(problems, fmt.Sprintf("url: unsupported provider (only https://github.com/owner/repo is supported, got %q)", repo.URL))
+		} else if _, _, err := github.ParseRepoURL(repo.URL); err != nil {
+			problems = append(problems, "url: "+err.Error())
+		} else {
+			key := registryKey(repo.URL)
+			if first, ok := seen[key]; ok {
+				problems = append(problems, fmt.Sprintf("url: duplicate of entry %d", first))
+			} else {
+				seen[key] = i
+			}
+		}
+		if strings.TrimSpace(repo.Ref) == "" {
+			problems = append(problems, fmt.Sprintf("ref: required (use %q for the default branch)", github.DefaultRef))
+		} else if err := github.ValidateRef(repo.Ref); err != nil {
+			problems = append(problems, "ref: "+err.Error())
+		}
+		if strings.Contains(repo.Logo, "://") && !strings.HasPrefix(repo.Logo, "https://") {
+			problems = append(problems, fmt.Sprintf("logo: must be a path in the repository or an https URL (got %q)", repo.Logo))
+		}
+		if repo.AttestationURL != "" {
+			if err := httpclient.CheckURL(repo.AttestationURL); err != nil {
+				problems = append(problems, fmt.Sprintf("attestation_url: must be an https URL of a public host (got %q)", repo.AttestationURL))
+			}
+		}
+
+		selected := make(map[string]bool, len(repo.Deployments))
+		for _, name := range repo.Deployments {
+			switch {
+			case strings.TrimSpace(name) == "" || strings.Contains(name, ","):
+				problems = append(problems, fmt.Sprintf("deployments: invalid deployment name %q", name))
+			case selected[name]:
+				problems = append(problems, fmt.Sprintf("deployments: duplicate deployment %q", name))
+			}
+			selected[name] = true
+		}
+
+		if len(problems) > 0 {
+			errs = append(errs, models.RegistryEntryError{
+				Index:  i,
+				Line:   entry.line,
+				URL:    repo.URL,
+				Errors: problems,
+			})
+			continue
+		}
+		valid = append(valid, entry)
+	}
+	return valid, errs
+}
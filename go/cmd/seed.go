@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+var (
+	seedRegistryURL string
+	seedFixture     string
+
+	seedCmd = &cobra.Command{
+		Use:   "seed",
+		Short: "Sync apps from the registry and fetch their manifests",
+		Long: `Sync apps from the apps registry (or the local fallback list) into the database
+and fetch their manifests from GitHub, then exit.
+
+With --fixture, load apps with their verification history from a database dump instead,
+e.g. fixtures/demo.json for a demo. Timestamps are shifted so the most recent one is the
+time of seeding, and apps that already exist are overwritten.`,
+		Args: cobra.NoArgs,
+		RunE: runSeed,
+	}
+)
+
+func init() {
+	seedCmd.Flags().StringVar(&seedRegistryURL, "registry-url", "", "apps registry URL (defaults to apps.registry_url)")
+	seedCmd.Flags().StringVar(&seedFixture, "fixture", "", "load apps from a JSON database dump instead of the registry")
+	seedCmd.MarkFlagsMutuallyExclusive("registry-url", "fixture")
+	rootCmd.AddCommand(seedCmd)
+}
+
+func runSeed(cmd *cobra.Command, _ []string) error {
+	logger := newLogger()
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if seedRegistryURL != "" {
+		cfg.Apps.RegistryURL = seedRegistryURL
+	}
+
+	database, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if seedFixture != "" {
+		return seedFromFixture(ctx, logger, database, seedFixture, time.Now().UTC())
+	}
+
+	clients, err := newHTTPClients(cfg)
+	if err != nil {
+		return err
+	}
+	httpClient := clients.Client(httpclient.GitHub)
+	gh := github.NewClient(httpClient, &cfg.GitHub, cfg.Apps.ManifestFilenames, logger)
+	return seedApps(ctx, logger, cfg, database, httpClient, gh)
+}
+
+// seedApps syncs apps from the registries of all namespaces into the database and fetches their
+// manifests. Failures of individual apps are logged and do not abort seeding.
+func seedApps(ctx context.Context, logger *slog.Logger, cfg *config.Config, database *db.DB, httpClient *http.Client, gh *github.Client) error {
+	if err := seedNamespace(ctx, logger, database, httpClient, gh, models.DefaultNamespace, cfg.Apps.RegistryURL, cfg.Apps.GitHubRepos); err != nil {
+		return err
+	}
+	for _, ns := range cfg.Namespaces {
+		if err := seedNamespace(ctx, logger.With("namespace", ns.Name), database, httpClient, gh, ns.Name, ns.RegistryURL, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedNamespace syncs the apps of a namespace from its registry, or from the fallback list if the
+// registry cannot be fetched.
+func seedNamespace(ctx context.Context, logger *slog.Logger, database *db.DB, httpClient *http.Client, gh *github.Client, namespace, registryURL string, fallback []config.GitHubRepo) error {
+	// Fetch apps registry from GitHub (or use local fallback).
+	apps, registryErrors, err := fetchAppsRegistry(ctx, logger, httpClient, registryURL)
+	if err != nil {
+		logger.Warn("failed to fetch apps registry from GitHub, using local config fallback", "error", err)
+		entries := make([]registryEntry, 0, len(fallback))
+		for i, repo := range fallback {
+			entries = append(entries, registryEntry{repo: repo, index: i})
+		}
+		apps, registryErrors = validateRegistry(entries)
+	}
+
+	// Invalid entries are skipped and reported via the admin API.
+	for _, entry := range registryErrors {
+		logger.Warn("invalid apps registry entry", "index", entry.Index, "line", entry.Line, "url", entry.URL, "errors", entry.Errors)
+	}
+	defer func() {
+		if err := database.ReplaceRegistryErrors(ctx, namespace, registryErrors); err != nil {
+			logger.Error("failed to record apps registry errors", "error", err)
+		}
+	}()
+
+	registered := make(map[string]bool, len(apps))
+	for _, entry := range apps {
+		registered[registryKey(entry.repo.URL)] = true
+	}
+
+	// Seed apps from registry.
+	seeded := 0
+	for _, entry := range apps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		repo := entry.repo
+
+		// Forks of registered apps are only listed if the entry allows it, to avoid confusing
+		// duplicate listings. Other forks are listed and labeled as forks.
+		parents, forkErr := gh.ForkParents(ctx, repo.URL)
+		if forkErr != nil {
+			logger.Warn("failed to check whether repository is a fork", "repo", repo.URL, "error", forkErr)
+		}
+		if parent := registeredParent(parents, registered); parent != "" && !repo.AllowFork {
+			logger.Warn("skipping fork of registered app", "repo", repo.URL, "parent", parent)
+			registryErrors = append(registryErrors, models.RegistryEntryError{
+				Index:  entry.index,
+				Line:   entry.line,
+				URL:    repo.URL,
+				Errors: []string{fmt.Sprintf("url: fork of registered app %s (set allow_fork to list it anyway)", parent)},
+			})
+			continue
+		}
+
+		// Upsert app - creates new or updates git_ref if URL already exists.
+		err := database.UpsertApp(ctx, namespace, repo.URL, repo.Ref, repo.Featured, repo.Logo, repo.AttestationURL, repo.Deployments)
+		if err != nil {
+			logger.Error("failed to upsert app", "repo", repo.URL, "ref", repo.Ref, "error", err)
+			continue
+		}
+
+		// Get the app to fetch rofl.yaml.
+		app, err := database.GetAppByURL(ctx, namespace, repo.URL)
+		if err != nil {
+			logger.Error("failed to get app after upsert", "repo", repo.URL, "error", err)
+			continue
+		}
+
+		logger.Info("app synced from config", "app_id", app.ID, "github_url", repo.URL, "ref", repo.Ref)
+		seeded++
+
+		if forkErr == nil {
+			forkOf := ""
+			if len(parents) > 0 {
+				forkOf = parents[0]
+			}
+			if err := database.UpdateAppForkOf(ctx, app.ID, forkOf); err != nil {
+				logger.Error("failed to record fork parent", "app_id", app.ID, "error", err)
+			}
+		}
+
+		// Fetch rofl.yaml from GitHub.
+		if err := fetchRoflYAML(ctx, logger, database, gh, app); err != nil {
+			logger.Error("failed to fetch rofl.yaml", "app_id", app.ID, "github_url", repo.URL, "error", err)
+		}
+	}
+
+	logger.Info("seeding complete", "count", seeded)
+	return nil
+}
+
+// registeredParent returns the first of a fork's parent repositories that is registered, or an
+// empty string if none is.
+func registeredParent(parents []string, registered map[string]bool) string {
+	for _, parent := range parents {
+		if registered[registryKey(parent)] {
+			return parent
+		}
+	}
+	return ""
+}
+
+// fetchAppsRegistry fetches the apps registry from the configured URL, returning its valid entries
+// and the problems of invalid ones.
+func fetchAppsRegistry(ctx context.Context, logger *slog.Logger, httpClient *http.Client, registryURL string) ([]registryEntry, []models.RegistryEntryError, error) {
+	logger.Info("fetching apps registry", "url", registryURL)
+
+	// Create request with timeout context.
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, registryURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Fetch apps.yaml.
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// Limit response size to 1MB.
+	const maxRegistrySize = 1 * 1024 * 1024
+	limitedReader := io.LimitReader(resp.Body, maxRegistrySize)
+
+	data, err := io.ReadAll(limitedReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	// Check if we hit the size limit.
+	if int64(len(data)) >= maxRegistrySize {
+		return nil, nil, fmt.Errorf("apps.yaml exceeds maximum size of %d bytes", maxRegistrySize)
+	}
+
+	apps, registryErrors, err := parseAppsRegistry(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.Info("successfully fetched apps registry", "count", len(apps), "invalid", len(registryErrors))
+	return apps, registryErrors, nil
+}
+
+// fetchRoflYAML fetches the rofl.yaml file from GitHub and updates the database.
+func fetchRoflYAML(ctx context.Context, logger *slog.Logger, database *db.DB, gh *github.Client, app *models.App) error {
+	logger.Info("fetching rofl.yaml", "github_url", app.GitHubURL, "ref", app.GitRef)
+
+	// Create request with timeout context.
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ref, err := gh.ResolveRef(reqCtx, app.GitHubURL, app.GitRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref: %w", err)
+	}
+
+	// Make the request conditional on the previous fetch, if any.
+	manifest, err := gh.FetchManifest(reqCtx, app.GitHubURL, ref, github.CachedManifest(app))
+	if err != nil {
+		return err
+	}
+	if manifest.NotModified {
+		logger.Info("rofl.yaml not modified", "github_url", app.GitHubURL, "path", manifest.Path)
+		return nil
+	}
+
+	// Update database with rofl.yaml content.
+	err = database.UpdateAppRoflYAML(ctx, app.ID, string(manifest.Data), manifest.Path, manifest.ETag, manifest.LastModified)
+	if err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+	if err := database.RecordManifestVersion(ctx, app.ID, string(manifest.Data)); err != nil {
+		return err
+	}
+
+	logger.Info("successfully fetched rofl.yaml", "github_url", app.GitHubURL, "path", manifest.Path, "size", len(manifest.Data))
+	return nil
+}
+
+// seedFromFixture loads apps with their history from a database dump, overwriting existing apps.
+func seedFromFixture(ctx context.Context, logger *slog.Logger, database *db.DB, path string, now time.Time) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the operator.
+	if err != nil {
+		return fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var dump db.Dump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	shiftDumpTimes(&dump, now)
+
+	stats, err := database.Import(ctx, &dump, db.ConflictOverwrite)
+	if err != nil {
+		return fmt.Errorf("failed to import fixture: %w", err)
+	}
+
+	logger.Info("seeded apps from fixture", "path", path, "created", stats.Created, "updated", stats.Updated)
+	return nil
+}
+
+// shiftDumpTimes moves all timestamps of a dump by the same amount, so that the most recent one
+// is now. A fixture then looks freshly verified no matter when it was written.
+func shiftDumpTimes(dump *db.Dump, now time.Time) {
+	var times []*time.Time
+	add := func(ts ...*time.Time) {
+		for _, t := range ts {
+			if t != nil && !t.IsZero() {
+				times = append(times, t)
+			}
+		}
+	}
+	for _, app := range dump.Apps {
+		add(&app.CreatedAt, &app.UpdatedAt)
+		for _, deployment := range app.Deployments {
+			add(deployment.LastVerified, deployment.VerifiedSince, deployment.FirstVerifiedAt, &deployment.CreatedAt, &deployment.UpdatedAt)
+		}
+		for _, digest := range app.ImageDigests {
+			add(&digest.ResolvedAt)
+		}
+		for _, version := range app.ManifestVersions {
+			add(&version.FirstSeenAt, version.VerifiedAt)
+		}
+	}
+
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = *t
+		}
+	}
+	offset := now.Sub(latest)
+	for _, t := range times {
+		*t = t.Add(offset)
+	}
+}
@@ -3,27 +3,200 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
+
+	"github.com/ptrus/rofl-attestations/models"
 )
 
 // Config holds the application configuration.
 type Config struct {
-	Server ServerConfig `koanf:"server"`
-	DB     DBConfig     `koanf:"db"`
-	Apps   AppsConfig   `koanf:"apps"`
-	Worker WorkerConfig `koanf:"worker"`
-	Debug  bool         `koanf:"debug"` // Enable debug mode with mock data.
+	Server  ServerConfig  `koanf:"server"`
+	DB      DBConfig      `koanf:"db"`
+	Apps    AppsConfig    `koanf:"apps"`
+	Worker  WorkerConfig  `koanf:"worker"`
+	GitHub  GitHubConfig  `koanf:"github"`
+	Storage StorageConfig `koanf:"storage"`
+	HTTP    HTTPConfig    `koanf:"http"`
+
+	Branding BrandingConfig `koanf:"branding"`
+
+	Namespaces []NamespaceConfig `koanf:"namespaces"` // Registries served besides the default one.
+}
+
+// NamespaceConfig is a registry served from the same deployment as the default one, with its own
+// apps, address, and admin keys. Workers verify the apps of all namespaces.
+type NamespaceConfig struct {
+	Name        string   `koanf:"name"`         // Identifier of lowercase letters, digits, and dashes, e.g. "partner-x".
+	RegistryURL string   `koanf:"registry_url"` // URL to fetch the namespace's apps.yaml from.
+	Hosts       []string `koanf:"hosts"`        // Host names serving the namespace, e.g. "registry.partner-x.com".
+	PathPrefix  string   `koanf:"path_prefix"`  // Path prefix serving the namespace's API and feeds, e.g. "/partner-x".
+	APIKeys     []APIKey `koanf:"api_keys"`     // Admin API keys limited to the namespace's apps.
 }
 
+// reservedPathPrefixes are the top-level paths of the server, which namespaces cannot be served under.
+var reservedPathPrefixes = []string{"/admin", "/api", "/apps", "/embed", "/feed.xml", "/health", "/healthz", "/htmx", "/maintainer", "/metrics", "/robots.txt", "/sitemap.xml", "/static"}
+
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
 	ListenAddr     string   `koanf:"listen_addr"`
-	AllowedOrigins []string `koanf:"allowed_origins"` // CORS allowed origins (empty = same-origin only)
+	AllowedOrigins []string `koanf:"allowed_origins"` // Deprecated: default of cors.allowed_origins.
+	AdminToken     string   `koanf:"admin_token"`     // Bearer token for /api/admin endpoints (empty = admin API disabled)
+	PublicURL      string   `koanf:"public_url"`      // Public base URL used in link previews (empty = derived from requests)
+	APIKeys        []APIKey `koanf:"api_keys"`        // Bearer tokens granting a role on the admin API, besides admin_token.
+	PageCacheTTL   int      `koanf:"page_cache_ttl"`  // Seconds the rendered app list is served from memory to anonymous visitors (default: 10, negative = disabled).
+	RequestTimeout int      `koanf:"request_timeout"` // Seconds a request may take, except streams and log downloads (default: 10).
+	VerifyTimeout  int      `koanf:"verify_timeout"`  // Seconds POST /api/verify may take, including waiting for a backend slot (default: 60).
+
+	SecurityHeaders SecurityHeadersConfig `koanf:"security_headers"`
+	CORS            CORSConfig            `koanf:"cors"`
+}
+
+// CORSConfig holds the cross-origin access to the public read-only JSON API, e.g. for dashboards of
+// other sites. Pages, the admin and maintainer routes, and requests changing state stay
+// same-origin.
+type CORSConfig struct {
+	AllowedOrigins []string `koanf:"allowed_origins"` // Origins allowed to read the API, "*" for any (empty = same-origin only).
+	Paths          []string `koanf:"paths"`           // Path prefixes of the routes open cross-origin (default: the public JSON API).
+	MaxAge         int      `koanf:"max_age"`         // Seconds browsers cache preflight responses (default: 600, negative = not cached).
+	ExposedHeaders []string `koanf:"exposed_headers"` // Response headers scripts of allowed origins can read, besides the CORS-safelisted ones.
+}
+
+// defaultCORSPaths are the path prefixes of the public read-only JSON API.
+var defaultCORSPaths = []string{"/api/status", "/api/oembed", "/api/apps", "/api/v1/", "/api/verify/"}
+
+// defaultCORSExposedHeaders are the response headers of the JSON API that scripts may read.
+var defaultCORSExposedHeaders = []string{"Content-Disposition", "X-Artifact-Truncated", "X-Cache"}
+
+// sameOriginPaths are paths of routes acting with credentials, which are never open cross-origin.
+var sameOriginPaths = []string{"/admin/", "/api/admin/", "/maintainer/"}
+
+// Covers returns whether a path is under one of the path prefixes open cross-origin. Prefixes not
+// ending with a slash only cover whole path segments, so that "/api/apps" covers "/api/apps/1" but
+// not "/api/appsx".
+func (c *CORSConfig) Covers(path string) bool {
+	for _, prefix := range c.Paths {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if ok && (rest == "" || strings.HasSuffix(prefix, "/") || strings.HasPrefix(rest, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecurityHeadersConfig holds the security headers set on responses.
+type SecurityHeadersConfig struct {
+	Disabled              bool   `koanf:"disabled"`                // Set none of the headers, e.g. when a proxy sets them.
+	ContentSecurityPolicy string `koanf:"content_security_policy"` // Replaces the built-in policy (empty = built-in).
+	FrameOptions          string `koanf:"frame_options"`           // DENY or SAMEORIGIN (default: DENY); the embed widget can be framed by any site.
+	ReferrerPolicy        string `koanf:"referrer_policy"`         // Default: strict-origin-when-cross-origin.
+	HSTSMaxAge            int    `koanf:"hsts_max_age"`            // Seconds browsers only connect over HTTPS (default: 1 year, negative = disabled).
+	HSTSIncludeSubdomains bool   `koanf:"hsts_include_subdomains"`
+}
+
+// Values of the X-Frame-Options header.
+const (
+	FrameOptionsDeny       = "DENY"
+	FrameOptionsSameOrigin = "SAMEORIGIN"
+)
+
+var referrerPolicies = []string{
+	"no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+	"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url",
+}
+
+// Role is the access level granted to an API key.
+type Role string
+
+// API roles, each granting the access of the previous ones.
+const (
+	RoleViewer     Role = "viewer"     // Read worker status, quarantined apps, and registry errors.
+	RoleMaintainer Role = "maintainer" // Also re-verify and release the apps of its repositories.
+	RoleAdmin      Role = "admin"      // Also pause the worker and manage all apps and keys.
+)
+
+var roleLevels = map[Role]int{RoleViewer: 1, RoleMaintainer: 2, RoleAdmin: 3}
+
+// Allows reports whether the role grants the access of the required role.
+func (r Role) Allows(required Role) bool {
+	return roleLevels[r] > 0 && roleLevels[r] >= roleLevels[required]
+}
+
+// APIKey is a bearer token granting a role. Maintainer keys are scoped to repositories.
+type APIKey struct {
+	Name  string   `koanf:"name"` // Identifies the key in logs.
+	Key   string   `koanf:"key"`
+	Role  Role     `koanf:"role"`
+	Repos []string `koanf:"repos"` // Owners ("my-org") or repositories ("my-org/app") a maintainer key manages.
+}
+
+// Themes of the web UI.
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system" // Light or dark as the reader's system prefers.
+)
+
+// BrandingConfig customizes the look of the web UI.
+type BrandingConfig struct {
+	Title          string       `koanf:"title"`           // Name of the registry in page titles and link previews (default: "Oasis ROFL App Attestations").
+	Heading        string       `koanf:"heading"`         // Heading of the index page (default: "Verified Oasis ROFL Apps", translated).
+	LogoURL        string       `koanf:"logo_url"`        // Logo in the page header, an http(s) URL or a path (default: built-in icon).
+	PrimaryColor   string       `koanf:"primary_color"`   // Hex color of links, buttons, and highlights (default: "#2563eb").
+	SecondaryColor string       `koanf:"secondary_color"` // Hex color of accents complementing the primary color (default: "#9333ea").
+	Theme          string       `koanf:"theme"`           // "light" (default), "dark", or "system".
+	FooterLinks    []FooterLink `koanf:"footer_links"`    // Links in the page footer, e.g. to an imprint.
+}
+
+// FooterLink is a link in the page footer.
+type FooterLink struct {
+	Label string `koanf:"label"`
+	URL   string `koanf:"url"`
+}
+
+// hexColor matches colors like "#2563eb".
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// HTTPConfig holds settings of outbound HTTP requests (GitHub, the apps registry, the backend, object storage).
+type HTTPConfig struct {
+	ProxyURL string `koanf:"proxy_url"` // Proxy for all requests (default: HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables).
+	NoProxy  string `koanf:"no_proxy"`  // Comma-separated hosts or domains that bypass proxy_url, e.g. "localhost,.internal".
+
+	UserAgent string `koanf:"user_agent"` // User-Agent of all requests (default: "rofl-registry/<version> (+https://github.com/ptrus/rofl-attestations)").
+
+	GitHub   HTTPClientConfig `koanf:"github"`   // GitHub API and raw content, including the apps registry.
+	Backend  HTTPClientConfig `koanf:"backend"`  // Verification backend.
+	Storage  HTTPClientConfig `koanf:"storage"`  // Object storage of artifacts.
+	External HTTPClientConfig `koanf:"external"` // Everything else, e.g. container registries, logos, webhooks, and Nexus.
+}
+
+// HTTPClientConfig holds the timeout and retry policy of requests to one destination.
+type HTTPClientConfig struct {
+	Timeout      int `koanf:"timeout"`       // Seconds per request, including retries (default: 30).
+	Retries      int `koanf:"retries"`       // Retries of GET requests failing with a network error, 502, 503, or 504 (default: 2, negative disables).
+	RetryBackoff int `koanf:"retry_backoff"` // Milliseconds before the first retry, doubled for each further one (default: 500).
+}
+
+// setDefaults fills in the defaults of unset fields.
+func (c *HTTPClientConfig) setDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = 30
+	}
+	if c.Retries == 0 {
+		c.Retries = 2
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = 500
+	}
 }
 
 // DBConfig holds database configuration.
@@ -33,26 +206,343 @@ type DBConfig struct {
 
 // GitHubRepo represents a GitHub repository with branch/tag/ref.
 type GitHubRepo struct {
-	URL string `koanf:"url"`
-	Ref string `koanf:"ref"` // Branch, tag, or commit ref to verify.
+	URL      string `koanf:"url" yaml:"url"`
+	Ref      string `koanf:"ref" yaml:"ref"`           // Branch, tag, or commit ref to verify ("default" for the default branch).
+	Featured bool   `koanf:"featured" yaml:"featured"` // Highlight the app on the landing page.
+	Logo     string `koanf:"logo" yaml:"logo"`         // Logo path in the repository or https URL, overriding the manifest logo.
+
+	AttestationURL string   `koanf:"attestation_url" yaml:"attestation_url"` // HTTPS endpoint of a running instance serving fresh TDX quotes.
+	Deployments    []string `koanf:"deployments" yaml:"deployments"`         // Deployments to verify (default: all declared in the manifest).
+	AllowFork      bool     `koanf:"allow_fork" yaml:"allow_fork"`           // List the app even if it is a fork of another registered app.
 }
 
+// App orderings.
+const (
+	AppOrderingID       = "id"       // Registry order.
+	AppOrderingFeatured = "featured" // Featured apps first, then most recently verified.
+)
+
 // AppsConfig holds apps configuration.
 type AppsConfig struct {
-	RegistryURL string       `koanf:"registry_url"` // URL to fetch apps.yaml from (default: GitHub master)
-	GitHubRepos []GitHubRepo `koanf:"github_repos"` // Fallback: local apps list (optional)
+	RegistryURL       string       `koanf:"registry_url"`       // URL to fetch apps.yaml from (default: GitHub master)
+	GitHubRepos       []GitHubRepo `koanf:"github_repos"`       // Fallback: local apps list (optional)
+	ManifestFilenames []string     `koanf:"manifest_filenames"` // Manifest filenames to try, in order (default: rofl.yaml, rofl.yml).
+	Ordering          string       `koanf:"ordering"`           // Order of the app list: "id" (default) or "featured".
+}
+
+// GitHubConfig holds GitHub access configuration.
+// Tokens allow fetching manifests and metadata from private repositories.
+type GitHubConfig struct {
+	Token  string        `koanf:"token"`  // Token used for all repositories without a more specific one.
+	Tokens []GitHubToken `koanf:"tokens"` // Per-owner or per-repository tokens (most specific wins).
+
+	OAuth GitHubOAuthConfig `koanf:"oauth"` // OAuth app signing in app maintainers (empty = maintainer sign-in disabled).
+}
+
+// GitHubOAuthConfig holds the credentials of the GitHub OAuth app maintainers sign in with.
+// The app's callback URL must be <public_url>/maintainer/callback.
+type GitHubOAuthConfig struct {
+	ClientID     string `koanf:"client_id"`
+	ClientSecret string `koanf:"client_secret"`
+}
+
+// Enabled reports whether maintainer sign-in is configured.
+func (c *GitHubOAuthConfig) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// GitHubToken is an access token scoped to an owner ("my-org") or a repository ("my-org/app").
+type GitHubToken struct {
+	Repo  string `koanf:"repo"`
+	Token string `koanf:"token"`
+}
+
+// TokenFor returns the access token to use for the given owner and repository, if any.
+func (c *GitHubConfig) TokenFor(owner, repo string) string {
+	if token := scopedToken(c.Tokens, owner, repo); token != "" {
+		return token
+	}
+	return c.Token
+}
+
+// scopedToken returns the token of a list scoped to the repository, or else to its owner, if any.
+func scopedToken(tokens []GitHubToken, owner, repo string) string {
+	var ownerToken string
+	for _, t := range tokens {
+		switch {
+		case strings.EqualFold(t.Repo, owner+"/"+repo):
+			return t.Token
+		case strings.EqualFold(t.Repo, owner):
+			ownerToken = t.Token
+		}
+	}
+	return ownerToken
 }
 
 // WorkerConfig holds periodic verification worker configuration.
 type WorkerConfig struct {
 	Enabled      bool   `koanf:"enabled"`       // Enable periodic verification worker.
 	BackendURL   string `koanf:"backend_url"`   // URL of rofl-app-backend service.
-	AppInterval  int    `koanf:"app_interval"`  // Delay between apps in minutes (default: 1).
+	AppInterval  int    `koanf:"app_interval"`  // Delay between apps in minutes (default: 1), unless cycle_window is set.
 	PollInterval int    `koanf:"poll_interval"` // Poll interval in seconds (default: 5).
 	PollTimeout  int    `koanf:"poll_timeout"`  // Poll timeout in minutes (default: 5).
 	PrivateKey   string `koanf:"private_key"`   // Private key for SIWE authentication (hex string without 0x prefix).
 	SIWEDomain   string `koanf:"siwe_domain"`   // Domain for SIWE messages (default: localhost).
 	ChainID      int    `koanf:"chain_id"`      // Chain ID for SIWE (default: 0x5aff for testnet).
+
+	PrivateKeyFile   string             `koanf:"private_key_file"`   // File holding the private key, instead of private_key.
+	PrivateKeySecret SecretConfig       `koanf:"private_key_secret"` // Secret store holding the private key, instead of private_key.
+	RemoteSigner     RemoteSignerConfig `koanf:"remote_signer"`      // Signing service used instead of a private key.
+
+	StandbyPrivateKeys       []string       `koanf:"standby_private_keys"`        // Standby private keys that the admin API can rotate to.
+	StandbyPrivateKeyFiles   []string       `koanf:"standby_private_key_files"`   // Files holding standby private keys.
+	StandbyPrivateKeySecrets []SecretConfig `koanf:"standby_private_key_secrets"` // Secret stores holding standby private keys.
+
+	BackendTLS BackendTLSConfig `koanf:"backend_tls"` // TLS settings for connections to the backend.
+
+	HealthPath     string `koanf:"health_path"`     // Path of the backend health endpoint (default: /health).
+	HealthInterval int    `koanf:"health_interval"` // Backend health probe interval in seconds (default: 60).
+
+	ResolveImageDigests bool `koanf:"resolve_image_digests"` // Resolve mutable compose image tags to digests and record them.
+
+	CycleWindow        int `koanf:"cycle_window"`        // Spread verifications uniformly across this many minutes per cycle (0 = use app_interval).
+	JitterPercent      int `koanf:"jitter_percent"`      // Random delay added to each wait, as a percentage of it (0-100).
+	DeploymentInterval int `koanf:"deployment_interval"` // Delay between deployments of the same app in seconds (default: 0).
+
+	BatchDeployments bool `koanf:"batch_deployments"` // Verify all deployments of an app in one backend task; requires a backend accepting deployment_names.
+
+	MaxBackendTasks int `koanf:"max_backend_tasks"` // Backend tasks in flight at once, shared by the worker and /api/verify (0 = unlimited).
+
+	FailureBackoff    int `koanf:"failure_backoff"`     // Initial backoff after an app fails in minutes, doubled per failure (default: 10).
+	MaxFailureBackoff int `koanf:"max_failure_backoff"` // Maximum backoff for failing apps in minutes (default: 1440).
+	QuarantineAfter   int `koanf:"quarantine_after"`    // Consecutive failures after which an app is listed as quarantined (default: 5).
+
+	MaxVerificationAge int `koanf:"max_verification_age"` // Hours after which unrefreshed verified deployments become stale (0 = never).
+
+	ShutdownGracePeriod int `koanf:"shutdown_grace_period"` // Seconds a verification in progress may take to finish on shutdown (default: 60).
+
+	CycleOverdueFactor int `koanf:"cycle_overdue_factor"` // Readiness reports degraded once no cycle completed for this many times the expected cycle duration (default: 3).
+
+	InstanceID    string `koanf:"instance_id"`    // Identifies this replica in app leases (default: hostname-pid).
+	LeaseDuration int    `koanf:"lease_duration"` // Minutes an app lease is held before other replicas may take over (default: 30).
+
+	Queue QueueConfig `koanf:"queue"`
+
+	Anchor AnchorConfig `koanf:"anchor"`
+
+	Nexus NexusConfig `koanf:"nexus"`
+
+	VerifyDomains bool `koanf:"verify_domains"` // Check that app homepages prove control of their domain.
+
+	Notifications NotificationsConfig `koanf:"notifications"`
+
+	Policy PolicyConfig `koanf:"policy"`
+}
+
+// Kinds of verification policy rules.
+const (
+	PolicyPinnedBuilder   = "pinned_builder"     // The builder image is referenced by digest.
+	PolicyMinEnclaves     = "min_enclaves"       // The policy admits at least min enclave identities.
+	PolicyMaxTrustRootAge = "max_trust_root_age" // The consensus trust root is at most max_age days old.
+)
+
+// PolicyConfig lists the rules verified deployments are checked against after each verification.
+type PolicyConfig struct {
+	Rules []PolicyRule `koanf:"rules"`
+}
+
+// PolicyRule is a rule of the verification policy. Violations are shown alongside the result of
+// the build verification, which they do not affect.
+type PolicyRule struct {
+	Name        string   `koanf:"name"`
+	Kind        string   `koanf:"kind"`
+	Networks    []string `koanf:"networks"`    // Networks the rule applies to (empty = all).
+	Min         int      `koanf:"min"`         // Enclave identities, for min_enclaves.
+	MaxAge      int      `koanf:"max_age"`     // Days, for max_trust_root_age.
+	Description string   `koanf:"description"` // Shown for violations instead of the generated message.
+}
+
+// NotificationsConfig configures notifying app maintainers of deployment status changes.
+// Webhook notifications need no configuration; email notifications require an SMTP server.
+type NotificationsConfig struct {
+	SMTP      SMTPConfig      `koanf:"smtp"`
+	PagerDuty PagerDutyConfig `koanf:"pagerduty"`
+	Telegram  TelegramConfig  `koanf:"telegram"`
+
+	GitHubIssues GitHubIssuesConfig `koanf:"github_issues"`
+}
+
+// PagerDutyConfig configures paging the operators of the registry when a verified deployment
+// fails verification.
+type PagerDutyConfig struct {
+	RoutingKey string `koanf:"routing_key"` // Events API v2 integration key (empty = disabled).
+	URL        string `koanf:"url"`         // Events API endpoint (default: https://events.pagerduty.com/v2/enqueue).
+}
+
+// Enabled reports whether regressions are sent to PagerDuty.
+func (c *PagerDutyConfig) Enabled() bool {
+	return c.RoutingKey != ""
+}
+
+// TelegramConfig configures posting deployment status changes to Telegram chats through a bot.
+type TelegramConfig struct {
+	BotToken     string   `koanf:"bot_token"`     // Token of the bot, from @BotFather (empty = disabled).
+	ChatIDs      []string `koanf:"chat_ids"`      // Chats the bot posts to, as numeric IDs or @channel usernames.
+	OnlyFailures bool     `koanf:"only_failures"` // Only post deployments becoming failed.
+	OnlyMainnet  bool     `koanf:"only_mainnet"`  // Only post mainnet deployments.
+	APIURL       string   `koanf:"api_url"`       // Bot API endpoint (default: https://api.telegram.org).
+}
+
+// Enabled reports whether status changes are posted to Telegram.
+func (c *TelegramConfig) Enabled() bool {
+	return c.BotToken != ""
+}
+
+// GitHubIssuesConfig configures filing issues in the repositories of apps whose deployments keep
+// failing verification. Apps opt in by providing a token allowed to write the issues of their
+// repository.
+type GitHubIssuesConfig struct {
+	Repos     []GitHubToken `koanf:"repos"`      // Repositories ("my-org/app") or owners ("my-org") issues are filed for, with their tokens (empty = disabled).
+	After     int           `koanf:"after"`      // Failed verifications of a deployment in a row before an issue is filed (default: 3).
+	Label     string        `koanf:"label"`      // Label of filed issues, by which they are found again (default: rofl-registry).
+	PublicURL string        `koanf:"public_url"` // Base URL of the registry linked from issues (default: server.public_url).
+
+	NamespaceURLs map[string]string `koanf:"-"` // Base URLs of the namespaces linked from issues, derived from public_url.
+}
+
+// Enabled reports whether issues are filed for any app.
+func (c *GitHubIssuesConfig) Enabled() bool {
+	return len(c.Repos) > 0
+}
+
+// TokenFor returns the token issues are filed in a repository with, or an empty string if issues
+// are not filed for it.
+func (c *GitHubIssuesConfig) TokenFor(owner, repo string) string {
+	return scopedToken(c.Repos, owner, repo)
+}
+
+// LinkURL returns the base URL of the registry of a namespace, which links in issues about its
+// apps point to.
+func (c *GitHubIssuesConfig) LinkURL(namespace string) string {
+	if u, ok := c.NamespaceURLs[namespace]; ok {
+		return u
+	}
+	return strings.TrimRight(c.PublicURL, "/")
+}
+
+// SMTPConfig is the mail server notification emails are sent through.
+type SMTPConfig struct {
+	Addr     string `koanf:"addr"` // host:port of the server (empty = email notifications disabled).
+	Username string `koanf:"username"`
+	Password string `koanf:"password"`
+	From     string `koanf:"from"` // Sender address, e.g. "ROFL Registry <registry@example.com>".
+
+	PublicURL     string            `koanf:"public_url"` // Base URL of the registry linked from emails (default: server.public_url).
+	NamespaceURLs map[string]string `koanf:"-"`          // Base URLs of the namespaces linked from emails, derived from public_url.
+}
+
+// Enabled reports whether email notifications are configured.
+func (c *SMTPConfig) Enabled() bool {
+	return c.Addr != ""
+}
+
+// LinkURL returns the base URL of the registry of a namespace, which links in emails about its
+// apps point to. Links are only built from configuration, never from requests, so that they
+// cannot be pointed at other hosts.
+func (c *SMTPConfig) LinkURL(namespace string) string {
+	if u, ok := c.NamespaceURLs[namespace]; ok {
+		return u
+	}
+	return strings.TrimRight(c.PublicURL, "/")
+}
+
+// NexusConfig configures cross-checking verified deployments against the on-chain state indexed by Nexus.
+type NexusConfig struct {
+	Enabled    bool   `koanf:"enabled"`     // Compare the live policy of verified deployments with the verified build.
+	MainnetURL string `koanf:"mainnet_url"` // Nexus API for mainnet deployments (default: https://nexus.oasis.io/v1).
+	TestnetURL string `koanf:"testnet_url"` // Nexus API for testnet deployments (default: https://testnet.nexus.oasis.io/v1).
+}
+
+// AnchorConfig configures publishing the root of the verification log to a contract on Sapphire.
+type AnchorConfig struct {
+	Enabled  bool   `koanf:"enabled"`   // Periodically anchor the verification log, signed with the worker private key.
+	RPCURL   string `koanf:"rpc_url"`   // Sapphire JSON-RPC endpoint, e.g. https://testnet.sapphire.oasis.io.
+	Contract string `koanf:"contract"`  // Address of the contract exposing anchor(bytes32 root, uint256 size).
+	Interval int    `koanf:"interval"`  // Minutes between anchors; unchanged logs are not anchored again (default: 1440).
+	GasLimit uint64 `koanf:"gas_limit"` // Gas limit of anchor transactions (default: 100000).
+}
+
+// Storage backends.
+const (
+	StorageBackendNone = ""
+	StorageBackendS3   = "s3"
+)
+
+// StorageConfig holds the configuration of object storage for large artifacts.
+type StorageConfig struct {
+	Backend         string `koanf:"backend"`           // "" (disabled, default) or "s3".
+	Endpoint        string `koanf:"endpoint"`          // e.g. https://s3.us-east-1.amazonaws.com or a MinIO URL.
+	Region          string `koanf:"region"`            // Signing region (default: us-east-1).
+	Bucket          string `koanf:"bucket"`            // Bucket name.
+	Prefix          string `koanf:"prefix"`            // Prefix of all object keys, e.g. "rofl-registry/".
+	PathStyle       bool   `koanf:"path_style"`        // Address the bucket in the path instead of the host name.
+	AccessKeyID     string `koanf:"access_key_id"`     // Access key ID.
+	SecretAccessKey string `koanf:"secret_access_key"` // Secret access key.
+	Threshold       int    `koanf:"threshold"`         // Artifacts larger than this many bytes are offloaded (default: 65536).
+}
+
+// Secret providers.
+const (
+	SecretProviderNone  = ""
+	SecretProviderVault = "vault"
+	SecretProviderAWS   = "aws"
+)
+
+// SecretConfig references a secret in an external secret store.
+type SecretConfig struct {
+	Provider string `koanf:"provider"` // "" (disabled, default), "vault", or "aws".
+	Name     string `koanf:"name"`     // Vault KV path or AWS Secrets Manager secret ID.
+	Key      string `koanf:"key"`      // Field of the secret holding the value (default: private_key).
+
+	VaultAddress string `koanf:"vault_address"` // Vault server URL (default: VAULT_ADDR).
+	VaultToken   string `koanf:"vault_token"`   // Vault token (default: VAULT_TOKEN).
+	VaultMount   string `koanf:"vault_mount"`   // KV version 2 mount (default: secret).
+
+	AWSRegion string `koanf:"aws_region"` // AWS region (default: AWS_REGION). Credentials are read from the AWS_* environment variables.
+}
+
+// RemoteSignerConfig configures a signing service holding the SIWE key, e.g. Web3Signer or Clef.
+type RemoteSignerConfig struct {
+	URL     string `koanf:"url"`     // JSON-RPC endpoint of the signer.
+	Address string `koanf:"address"` // Address of the signing account.
+	Method  string `koanf:"method"`  // JSON-RPC method called with the address and hex-encoded message (default: eth_sign).
+	Token   string `koanf:"token"`   // Bearer token sent to the signer, if required.
+}
+
+// BackendTLSConfig holds TLS settings for a backend behind an internal PKI.
+type BackendTLSConfig struct {
+	CAFile     string `koanf:"ca_file"`     // PEM bundle of CAs trusted for the backend certificate (default: system roots).
+	CertFile   string `koanf:"cert_file"`   // PEM client certificate for mutual TLS.
+	KeyFile    string `koanf:"key_file"`    // PEM private key of the client certificate.
+	ServerName string `koanf:"server_name"` // Name to verify the backend certificate against (default: backend URL host).
+}
+
+// Enabled returns whether any TLS setting is configured.
+func (c *BackendTLSConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.KeyFile != "" || c.ServerName != ""
+}
+
+// Queue backends.
+const (
+	QueueBackendMemory = "memory"
+	QueueBackendRedis  = "redis"
+)
+
+// QueueConfig holds the configuration of the verification queue.
+type QueueConfig struct {
+	Backend   string `koanf:"backend"`    // "memory" (default) or "redis".
+	RedisURL  string `koanf:"redis_url"`  // e.g. redis://:password@localhost:6379/0, rediss:// for TLS.
+	KeyPrefix string `koanf:"key_prefix"` // Prefix of all Redis keys (default: "rofl-registry:").
 }
 
 // Load loads configuration from file and environment variables.
@@ -82,12 +572,60 @@ func Load(configPath string) (*Config, error) {
 	if cfg.Server.ListenAddr == "" {
 		cfg.Server.ListenAddr = ":8080"
 	}
+	if cfg.Server.PageCacheTTL == 0 {
+		cfg.Server.PageCacheTTL = 10
+	}
+	if cfg.Server.RequestTimeout == 0 {
+		cfg.Server.RequestTimeout = 10
+	}
+	if cfg.Server.VerifyTimeout == 0 {
+		cfg.Server.VerifyTimeout = 60
+	}
+	if len(cfg.Server.CORS.AllowedOrigins) == 0 {
+		cfg.Server.CORS.AllowedOrigins = cfg.Server.AllowedOrigins
+	}
+	if len(cfg.Server.CORS.Paths) == 0 {
+		cfg.Server.CORS.Paths = defaultCORSPaths
+	}
+	if cfg.Server.CORS.MaxAge == 0 {
+		cfg.Server.CORS.MaxAge = 600
+	}
+	if cfg.Server.CORS.ExposedHeaders == nil {
+		cfg.Server.CORS.ExposedHeaders = defaultCORSExposedHeaders
+	}
+	if cfg.Server.SecurityHeaders.FrameOptions == "" {
+		cfg.Server.SecurityHeaders.FrameOptions = FrameOptionsDeny
+	}
+	if cfg.Server.SecurityHeaders.ReferrerPolicy == "" {
+		cfg.Server.SecurityHeaders.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	if cfg.Server.SecurityHeaders.HSTSMaxAge == 0 {
+		cfg.Server.SecurityHeaders.HSTSMaxAge = 365 * 24 * 60 * 60 // 1 year
+	}
+	if cfg.Branding.Title == "" {
+		cfg.Branding.Title = "Oasis ROFL App Attestations"
+	}
+	if cfg.Branding.PrimaryColor == "" {
+		cfg.Branding.PrimaryColor = "#2563eb"
+	}
+	if cfg.Branding.SecondaryColor == "" {
+		cfg.Branding.SecondaryColor = "#9333ea"
+	}
+	if cfg.Branding.Theme == "" {
+		cfg.Branding.Theme = ThemeLight
+	}
 	if cfg.DB.Path == "" {
 		cfg.DB.Path = "rofl-registry.db"
 	}
 	if cfg.Apps.RegistryURL == "" {
 		cfg.Apps.RegistryURL = "https://raw.githubusercontent.com/ptrus/rofl-attestations/master/apps.yaml"
 	}
+	if len(cfg.Apps.ManifestFilenames) == 0 {
+		cfg.Apps.ManifestFilenames = []string{"rofl.yaml", "rofl.yml"}
+	}
+	if cfg.Apps.Ordering == "" {
+		cfg.Apps.Ordering = AppOrderingID
+	}
 	if cfg.Worker.AppInterval == 0 {
 		cfg.Worker.AppInterval = 1 // 1 minute between apps
 	}
@@ -97,6 +635,103 @@ func Load(configPath string) (*Config, error) {
 	if cfg.Worker.PollTimeout == 0 {
 		cfg.Worker.PollTimeout = 5 // 5 minutes
 	}
+	if cfg.Worker.HealthPath == "" {
+		cfg.Worker.HealthPath = "/health"
+	}
+	if cfg.Worker.HealthInterval == 0 {
+		cfg.Worker.HealthInterval = 60 // 1 minute
+	}
+	if cfg.Worker.FailureBackoff == 0 {
+		cfg.Worker.FailureBackoff = 10 // 10 minutes
+	}
+	if cfg.Worker.MaxFailureBackoff == 0 {
+		cfg.Worker.MaxFailureBackoff = 24 * 60 // 1 day
+	}
+	if cfg.Worker.QuarantineAfter == 0 {
+		cfg.Worker.QuarantineAfter = 5
+	}
+	if cfg.Worker.CycleOverdueFactor == 0 {
+		cfg.Worker.CycleOverdueFactor = 3
+	}
+	if cfg.Worker.ShutdownGracePeriod == 0 {
+		cfg.Worker.ShutdownGracePeriod = 60 // 1 minute
+	}
+	if cfg.Worker.InstanceID == "" {
+		hostname, _ := os.Hostname()
+		cfg.Worker.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if cfg.Worker.LeaseDuration == 0 {
+		cfg.Worker.LeaseDuration = 30 // 30 minutes
+	}
+	if cfg.Worker.Queue.Backend == "" {
+		cfg.Worker.Queue.Backend = QueueBackendMemory
+	}
+	if cfg.Worker.Anchor.Interval == 0 {
+		cfg.Worker.Anchor.Interval = 24 * 60 // 1 day
+	}
+	if cfg.Worker.Anchor.GasLimit == 0 {
+		cfg.Worker.Anchor.GasLimit = 100_000
+	}
+	if cfg.Worker.Notifications.PagerDuty.URL == "" {
+		cfg.Worker.Notifications.PagerDuty.URL = "https://events.pagerduty.com/v2/enqueue"
+	}
+	if cfg.Worker.Notifications.Telegram.APIURL == "" {
+		cfg.Worker.Notifications.Telegram.APIURL = "https://api.telegram.org"
+	}
+	if cfg.Worker.Notifications.GitHubIssues.After == 0 {
+		cfg.Worker.Notifications.GitHubIssues.After = 3
+	}
+	if cfg.Worker.Notifications.GitHubIssues.Label == "" {
+		cfg.Worker.Notifications.GitHubIssues.Label = "rofl-registry"
+	}
+	if cfg.Worker.Notifications.GitHubIssues.PublicURL == "" {
+		cfg.Worker.Notifications.GitHubIssues.PublicURL = cfg.Server.PublicURL
+	}
+	if cfg.Worker.Notifications.SMTP.PublicURL == "" {
+		cfg.Worker.Notifications.SMTP.PublicURL = cfg.Server.PublicURL
+	}
+	cfg.Worker.Notifications.SMTP.NamespaceURLs = make(map[string]string, len(cfg.Namespaces))
+	cfg.Worker.Notifications.GitHubIssues.NamespaceURLs = make(map[string]string, len(cfg.Namespaces))
+	for _, ns := range cfg.Namespaces {
+		cfg.Worker.Notifications.SMTP.NamespaceURLs[ns.Name] = ns.baseURL(cfg.Worker.Notifications.SMTP.PublicURL)
+		cfg.Worker.Notifications.GitHubIssues.NamespaceURLs[ns.Name] = ns.baseURL(cfg.Worker.Notifications.GitHubIssues.PublicURL)
+	}
+	if cfg.Worker.Nexus.MainnetURL == "" {
+		cfg.Worker.Nexus.MainnetURL = "https://nexus.oasis.io/v1"
+	}
+	if cfg.Worker.Nexus.TestnetURL == "" {
+		cfg.Worker.Nexus.TestnetURL = "https://testnet.nexus.oasis.io/v1"
+	}
+	if cfg.Worker.Queue.KeyPrefix == "" {
+		cfg.Worker.Queue.KeyPrefix = "rofl-registry:"
+	}
+	for _, client := range []*HTTPClientConfig{&cfg.HTTP.GitHub, &cfg.HTTP.Backend, &cfg.HTTP.Storage, &cfg.HTTP.External} {
+		client.setDefaults()
+	}
+	if cfg.Storage.Region == "" {
+		cfg.Storage.Region = "us-east-1"
+	}
+	if cfg.Storage.Threshold == 0 {
+		cfg.Storage.Threshold = 64 * 1024 // 64 KiB
+	}
+	if cfg.Worker.PrivateKeySecret.Key == "" {
+		cfg.Worker.PrivateKeySecret.Key = "private_key"
+	}
+	if cfg.Worker.RemoteSigner.Method == "" {
+		cfg.Worker.RemoteSigner.Method = "eth_sign"
+	}
+	if cfg.Worker.PrivateKeySecret.VaultMount == "" {
+		cfg.Worker.PrivateKeySecret.VaultMount = "secret"
+	}
+	for i := range cfg.Worker.StandbyPrivateKeySecrets {
+		secret := &cfg.Worker.StandbyPrivateKeySecrets[i]
+		if secret.Key == "" {
+			secret.Key = "private_key"
+		}
+		if secret.VaultMount == "" {
+			secret.VaultMount = "secret"
+		}
+	}
 	if cfg.Worker.SIWEDomain == "" {
 		cfg.Worker.SIWEDomain = "localhost"
 	}
@@ -112,8 +747,92 @@ func Load(configPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// baseURL returns the base URL of a namespace, on its first host or below its path prefix of the
+// base URL of the registry. Namespace hosts are assumed to use the scheme of the registry.
+func (ns *NamespaceConfig) baseURL(registryURL string) string {
+	registryURL = strings.TrimRight(registryURL, "/")
+	if len(ns.Hosts) > 0 {
+		scheme, _, found := strings.Cut(registryURL, "://")
+		if !found {
+			scheme = "https"
+		}
+		return scheme + "://" + ns.Hosts[0]
+	}
+	return registryURL + ns.PathPrefix
+}
+
+// Namespace returns the configuration of a namespace other than the default one, or nil if it
+// does not exist.
+func (c *Config) Namespace(name string) *NamespaceConfig {
+	for i := range c.Namespaces {
+		if c.Namespaces[i].Name == name {
+			return &c.Namespaces[i]
+		}
+	}
+	return nil
+}
+
+// validateAPIKeys validates API keys, which must be unique across all keys seen.
+func validateAPIKeys(field string, apiKeys []APIKey, seen map[string]bool) error {
+	for i, k := range apiKeys {
+		if k.Key == "" {
+			return fmt.Errorf("%s[%d]: key cannot be empty", field, i)
+		}
+		if seen[k.Key] {
+			return fmt.Errorf("%s[%d]: duplicate key", field, i)
+		}
+		seen[k.Key] = true
+		if roleLevels[k.Role] == 0 {
+			return fmt.Errorf("%s[%d]: role must be %q, %q, or %q (got %q)", field, i, RoleViewer, RoleMaintainer, RoleAdmin, k.Role)
+		}
+		if k.Role == RoleMaintainer && len(k.Repos) == 0 {
+			return fmt.Errorf("%s[%d]: maintainer keys must list repos", field, i)
+		}
+		for _, repo := range k.Repos {
+			if repo == "" || strings.Count(repo, "/") > 1 {
+				return fmt.Errorf("%s[%d]: repos must be \"owner\" or \"owner/repo\" (got %q)", field, i, repo)
+			}
+		}
+	}
+	return nil
+}
+
 // Validate validates the configuration.
 func (c *Config) Validate() error {
+	if c.Server.PublicURL != "" && !strings.HasPrefix(c.Server.PublicURL, "https://") && !strings.HasPrefix(c.Server.PublicURL, "http://") {
+		return fmt.Errorf("server.public_url must be an http(s) URL (got %q)", c.Server.PublicURL)
+	}
+	if c.Server.RequestTimeout < 0 || c.Server.VerifyTimeout < 0 {
+		return fmt.Errorf("server.request_timeout and server.verify_timeout must not be negative")
+	}
+	if headers := c.Server.SecurityHeaders; !headers.Disabled {
+		if headers.FrameOptions != FrameOptionsDeny && headers.FrameOptions != FrameOptionsSameOrigin {
+			return fmt.Errorf("server.security_headers.frame_options must be %q or %q (got %q)", FrameOptionsDeny, FrameOptionsSameOrigin, headers.FrameOptions)
+		}
+		if !slices.Contains(referrerPolicies, headers.ReferrerPolicy) {
+			return fmt.Errorf("server.security_headers.referrer_policy must be one of %s (got %q)", strings.Join(referrerPolicies, ", "), headers.ReferrerPolicy)
+		}
+		if strings.ContainsAny(headers.ContentSecurityPolicy, "\r\n") {
+			return fmt.Errorf("server.security_headers.content_security_policy must be a single line")
+		}
+	}
+
+	for _, origin := range c.Server.CORS.AllowedOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "https://") && !strings.HasPrefix(origin, "http://") {
+			return fmt.Errorf("server.cors.allowed_origins must be \"*\" or http(s) origins (got %q)", origin)
+		}
+	}
+	for _, path := range c.Server.CORS.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("server.cors.paths must start with a slash (got %q)", path)
+		}
+	}
+	for _, path := range sameOriginPaths {
+		if c.Server.CORS.Covers(path) {
+			return fmt.Errorf("server.cors.paths must not cover %s, which stays same-origin", path)
+		}
+	}
+
 	// Validate GitHub repository URLs (if provided as fallback)
 	for i, repo := range c.Apps.GitHubRepos {
 		if repo.URL == "" {
@@ -131,8 +850,267 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("apps.github_repos[%d]: ref cannot be empty", i)
 		}
 	}
+	if c.Apps.Ordering != AppOrderingID && c.Apps.Ordering != AppOrderingFeatured {
+		return fmt.Errorf("apps.ordering must be %q or %q (got %q)", AppOrderingID, AppOrderingFeatured, c.Apps.Ordering)
+	}
+
+	// Validate branding
+	for field, color := range map[string]string{"primary_color": c.Branding.PrimaryColor, "secondary_color": c.Branding.SecondaryColor} {
+		if !hexColor.MatchString(color) {
+			return fmt.Errorf("branding.%s must be a hex color like \"#2563eb\" (got %q)", field, color)
+		}
+	}
+	if c.Branding.Theme != ThemeLight && c.Branding.Theme != ThemeDark && c.Branding.Theme != ThemeSystem {
+		return fmt.Errorf("branding.theme must be %q, %q, or %q (got %q)", ThemeLight, ThemeDark, ThemeSystem, c.Branding.Theme)
+	}
+	if logo := c.Branding.LogoURL; logo != "" && !strings.HasPrefix(logo, "https://") && !strings.HasPrefix(logo, "http://") && (!strings.HasPrefix(logo, "/") || strings.HasPrefix(logo, "//")) {
+		return fmt.Errorf("branding.logo_url must be an http(s) URL or a path (got %q)", logo)
+	}
+	for i, link := range c.Branding.FooterLinks {
+		if link.Label == "" {
+			return fmt.Errorf("branding.footer_links[%d]: label cannot be empty", i)
+		}
+		if !strings.HasPrefix(link.URL, "https://") && !strings.HasPrefix(link.URL, "http://") && !strings.HasPrefix(link.URL, "mailto:") {
+			return fmt.Errorf("branding.footer_links[%d]: url must be an http(s) or mailto URL (got %q)", i, link.URL)
+		}
+	}
+
+	if (c.GitHub.OAuth.ClientID == "") != (c.GitHub.OAuth.ClientSecret == "") {
+		return fmt.Errorf("github.oauth.client_id and github.oauth.client_secret must be set together")
+	}
+
+	// Validate API keys
+	keys := map[string]bool{c.Server.AdminToken: true}
+	if err := validateAPIKeys("server.api_keys", c.Server.APIKeys, keys); err != nil {
+		return err
+	}
+
+	// Validate namespaces
+	names := map[string]bool{models.DefaultNamespace: true}
+	hosts := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for i, ns := range c.Namespaces {
+		if ns.Name == "" || strings.Trim(ns.Name, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return fmt.Errorf("namespaces[%d]: name must consist of lowercase letters, digits, and dashes (got %q)", i, ns.Name)
+		}
+		if names[ns.Name] {
+			return fmt.Errorf("namespaces[%d]: duplicate or reserved name %q", i, ns.Name)
+		}
+		names[ns.Name] = true
+		if !strings.HasPrefix(ns.RegistryURL, "https://") && !strings.HasPrefix(ns.RegistryURL, "http://") {
+			return fmt.Errorf("namespaces[%d]: registry_url must be an http(s) URL (got %q)", i, ns.RegistryURL)
+		}
+		if len(ns.Hosts) == 0 && ns.PathPrefix == "" {
+			return fmt.Errorf("namespaces[%d]: hosts or path_prefix must be set", i)
+		}
+		for _, host := range ns.Hosts {
+			host = strings.ToLower(host)
+			if host == "" || strings.ContainsAny(host, ":/") {
+				return fmt.Errorf("namespaces[%d]: hosts must be host names without a port (got %q)", i, host)
+			}
+			if hosts[host] {
+				return fmt.Errorf("namespaces[%d]: duplicate host %q", i, host)
+			}
+			hosts[host] = true
+		}
+		if prefix := ns.PathPrefix; prefix != "" {
+			if !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") || strings.Count(prefix, "/") > 1 {
+				return fmt.Errorf("namespaces[%d]: path_prefix must be a single path segment like \"/%s\" (got %q)", i, ns.Name, prefix)
+			}
+			if prefixes[prefix] || slices.Contains(reservedPathPrefixes, prefix) {
+				return fmt.Errorf("namespaces[%d]: duplicate or reserved path_prefix %q", i, prefix)
+			}
+			prefixes[prefix] = true
+		}
+		if err := validateAPIKeys(fmt.Sprintf("namespaces[%d].api_keys", i), ns.APIKeys, keys); err != nil {
+			return err
+		}
+	}
+
+	// Validate per-repository GitHub tokens
+	for i, t := range c.GitHub.Tokens {
+		if t.Repo == "" || strings.Count(t.Repo, "/") > 1 {
+			return fmt.Errorf("github.tokens[%d]: repo must be \"owner\" or \"owner/repo\" (got %q)", i, t.Repo)
+		}
+		if t.Token == "" {
+			return fmt.Errorf("github.tokens[%d]: token cannot be empty", i)
+		}
+	}
+
+	// Validate manifest filenames
+	for i, name := range c.Apps.ManifestFilenames {
+		if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "..") {
+			return fmt.Errorf("apps.manifest_filenames[%d]: invalid filename %q", i, name)
+		}
+	}
+
+	keySources := 0
+	for _, set := range []bool{c.Worker.PrivateKey != "", c.Worker.PrivateKeyFile != "", c.Worker.PrivateKeySecret.Provider != SecretProviderNone, c.Worker.RemoteSigner.URL != ""} {
+		if set {
+			keySources++
+		}
+	}
+	if keySources > 1 {
+		return fmt.Errorf("only one of worker.private_key, worker.private_key_file, worker.private_key_secret, and worker.remote_signer can be set")
+	}
+	standbyKeys := len(c.Worker.StandbyPrivateKeys) + len(c.Worker.StandbyPrivateKeyFiles) + len(c.Worker.StandbyPrivateKeySecrets)
+	if standbyKeys > 0 && keySources == 0 {
+		return fmt.Errorf("worker standby keys require an active key")
+	}
+	if c.Worker.RemoteSigner.URL != "" && c.Worker.RemoteSigner.Address == "" {
+		return fmt.Errorf("worker.remote_signer.address cannot be empty")
+	}
+	switch c.Worker.PrivateKeySecret.Provider {
+	case SecretProviderNone:
+	case SecretProviderVault, SecretProviderAWS:
+		if c.Worker.PrivateKeySecret.Name == "" {
+			return fmt.Errorf("worker.private_key_secret.name cannot be empty")
+		}
+	default:
+		return fmt.Errorf("worker.private_key_secret.provider must be empty, %q, or %q (got %q)", SecretProviderVault, SecretProviderAWS, c.Worker.PrivateKeySecret.Provider)
+	}
+	for i, secret := range c.Worker.StandbyPrivateKeySecrets {
+		if secret.Provider != SecretProviderVault && secret.Provider != SecretProviderAWS {
+			return fmt.Errorf("worker.standby_private_key_secrets[%d].provider must be %q or %q (got %q)", i, SecretProviderVault, SecretProviderAWS, secret.Provider)
+		}
+		if secret.Name == "" {
+			return fmt.Errorf("worker.standby_private_key_secrets[%d].name cannot be empty", i)
+		}
+	}
+
+	if c.Worker.Anchor.Enabled {
+		if !strings.HasPrefix(c.Worker.Anchor.RPCURL, "https://") && !strings.HasPrefix(c.Worker.Anchor.RPCURL, "http://") {
+			return fmt.Errorf("worker.anchor.rpc_url must be an http(s) URL (got %q)", c.Worker.Anchor.RPCURL)
+		}
+		if c.Worker.Anchor.Contract == "" {
+			return fmt.Errorf("worker.anchor.contract cannot be empty")
+		}
+		if c.Worker.Anchor.Interval <= 0 {
+			return fmt.Errorf("worker.anchor.interval must be positive (got %d)", c.Worker.Anchor.Interval)
+		}
+		if keySources == 0 || c.Worker.RemoteSigner.URL != "" {
+			return fmt.Errorf("worker.anchor requires worker.private_key, worker.private_key_file, or worker.private_key_secret")
+		}
+	}
+
+	if smtp := c.Worker.Notifications.SMTP; smtp.Enabled() {
+		if _, _, err := net.SplitHostPort(smtp.Addr); err != nil {
+			return fmt.Errorf("worker.notifications.smtp.addr must be host:port (got %q)", smtp.Addr)
+		}
+		if _, err := mail.ParseAddress(smtp.From); err != nil {
+			return fmt.Errorf("worker.notifications.smtp.from must be an email address (got %q)", smtp.From)
+		}
+		if !strings.HasPrefix(smtp.PublicURL, "https://") && !strings.HasPrefix(smtp.PublicURL, "http://") {
+			return fmt.Errorf("worker.notifications.smtp.public_url must be an http(s) URL (got %q), or server.public_url set", smtp.PublicURL)
+		}
+	}
+
+	if pd := c.Worker.Notifications.PagerDuty; pd.Enabled() && !strings.HasPrefix(pd.URL, "https://") && !strings.HasPrefix(pd.URL, "http://") {
+		return fmt.Errorf("worker.notifications.pagerduty.url must be an http(s) URL (got %q)", pd.URL)
+	}
+
+	if tg := c.Worker.Notifications.Telegram; tg.Enabled() {
+		if len(tg.ChatIDs) == 0 {
+			return fmt.Errorf("worker.notifications.telegram.chat_ids cannot be empty when bot_token is set")
+		}
+		if !strings.HasPrefix(tg.APIURL, "https://") && !strings.HasPrefix(tg.APIURL, "http://") {
+			return fmt.Errorf("worker.notifications.telegram.api_url must be an http(s) URL (got %q)", tg.APIURL)
+		}
+	}
+
+	if gi := c.Worker.Notifications.GitHubIssues; gi.Enabled() {
+		if gi.After <= 0 {
+			return fmt.Errorf("worker.notifications.github_issues.after must be positive (got %d)", gi.After)
+		}
+		if !strings.HasPrefix(gi.PublicURL, "https://") && !strings.HasPrefix(gi.PublicURL, "http://") {
+			return fmt.Errorf("worker.notifications.github_issues.public_url must be an http(s) URL (got %q), or server.public_url set", gi.PublicURL)
+		}
+		for i, repo := range gi.Repos {
+			if repo.Repo == "" || repo.Token == "" {
+				return fmt.Errorf("worker.notifications.github_issues.repos[%d]: repo and token cannot be empty", i)
+			}
+		}
+	}
+
+	rules := make(map[string]bool, len(c.Worker.Policy.Rules))
+	for i, rule := range c.Worker.Policy.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("worker.policy.rules[%d]: name cannot be empty", i)
+		}
+		if rules[rule.Name] {
+			return fmt.Errorf("worker.policy.rules[%d]: duplicate name %q", i, rule.Name)
+		}
+		rules[rule.Name] = true
+		switch rule.Kind {
+		case PolicyPinnedBuilder:
+		case PolicyMinEnclaves:
+			if rule.Min <= 0 {
+				return fmt.Errorf("worker.policy.rules[%d]: min must be positive (got %d)", i, rule.Min)
+			}
+		case PolicyMaxTrustRootAge:
+			if rule.MaxAge <= 0 {
+				return fmt.Errorf("worker.policy.rules[%d]: max_age must be positive (got %d)", i, rule.MaxAge)
+			}
+		default:
+			return fmt.Errorf("worker.policy.rules[%d]: kind must be %q, %q, or %q (got %q)", i, PolicyPinnedBuilder, PolicyMinEnclaves, PolicyMaxTrustRootAge, rule.Kind)
+		}
+	}
+
+	if c.Worker.Nexus.Enabled {
+		for name, url := range map[string]string{"mainnet_url": c.Worker.Nexus.MainnetURL, "testnet_url": c.Worker.Nexus.TestnetURL} {
+			if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+				return fmt.Errorf("worker.nexus.%s must be an http(s) URL (got %q)", name, url)
+			}
+		}
+	}
+
+	if (c.Worker.BackendTLS.CertFile == "") != (c.Worker.BackendTLS.KeyFile == "") {
+		return fmt.Errorf("worker.backend_tls.cert_file and worker.backend_tls.key_file must be set together")
+	}
+
+	if c.HTTP.ProxyURL != "" && !strings.HasPrefix(c.HTTP.ProxyURL, "http://") && !strings.HasPrefix(c.HTTP.ProxyURL, "https://") && !strings.HasPrefix(c.HTTP.ProxyURL, "socks5://") {
+		return fmt.Errorf("http.proxy_url must be an http(s) or socks5 URL (got %q)", c.HTTP.ProxyURL)
+	}
+	for name, client := range map[string]HTTPClientConfig{"github": c.HTTP.GitHub, "backend": c.HTTP.Backend, "storage": c.HTTP.Storage, "external": c.HTTP.External} {
+		if client.Timeout < 0 || client.RetryBackoff < 0 {
+			return fmt.Errorf("http.%s.timeout and http.%s.retry_backoff must not be negative", name, name)
+		}
+	}
+
+	// Validate object storage
+	switch c.Storage.Backend {
+	case StorageBackendNone:
+	case StorageBackendS3:
+		if !strings.HasPrefix(c.Storage.Endpoint, "https://") && !strings.HasPrefix(c.Storage.Endpoint, "http://") {
+			return fmt.Errorf("storage.endpoint must be an http(s) URL (got %q)", c.Storage.Endpoint)
+		}
+		if c.Storage.Bucket == "" {
+			return fmt.Errorf("storage.bucket cannot be empty")
+		}
+		if c.Storage.AccessKeyID == "" || c.Storage.SecretAccessKey == "" {
+			return fmt.Errorf("storage.access_key_id and storage.secret_access_key are required")
+		}
+	default:
+		return fmt.Errorf("storage.backend must be empty or %q (got %q)", StorageBackendS3, c.Storage.Backend)
+	}
+	if c.Storage.Threshold < 0 {
+		return fmt.Errorf("storage.threshold cannot be negative (got %d)", c.Storage.Threshold)
+	}
+
+	if c.Worker.MaxVerificationAge < 0 {
+		return fmt.Errorf("worker.max_verification_age cannot be negative (got %d)", c.Worker.MaxVerificationAge)
+	}
 
 	// Validate worker configuration if enabled
+	if c.Worker.HealthInterval <= 0 {
+		return fmt.Errorf("worker.health_interval must be positive (got %d)", c.Worker.HealthInterval)
+	}
+	if !strings.HasPrefix(c.Worker.HealthPath, "/") {
+		return fmt.Errorf("worker.health_path must start with / (got %q)", c.Worker.HealthPath)
+	}
+	if c.Worker.MaxBackendTasks < 0 {
+		return fmt.Errorf("worker.max_backend_tasks cannot be negative (got %d)", c.Worker.MaxBackendTasks)
+	}
 	if c.Worker.Enabled {
 		if c.Worker.BackendURL == "" {
 			return fmt.Errorf("worker.backend_url cannot be empty when worker is enabled")
@@ -146,6 +1124,41 @@ func (c *Config) Validate() error {
 		if c.Worker.PollTimeout <= 0 {
 			return fmt.Errorf("worker.poll_timeout must be positive (got %d)", c.Worker.PollTimeout)
 		}
+		if c.Worker.CycleWindow < 0 {
+			return fmt.Errorf("worker.cycle_window cannot be negative (got %d)", c.Worker.CycleWindow)
+		}
+		if c.Worker.JitterPercent < 0 || c.Worker.JitterPercent > 100 {
+			return fmt.Errorf("worker.jitter_percent must be between 0 and 100 (got %d)", c.Worker.JitterPercent)
+		}
+		if c.Worker.DeploymentInterval < 0 {
+			return fmt.Errorf("worker.deployment_interval cannot be negative (got %d)", c.Worker.DeploymentInterval)
+		}
+		if c.Worker.FailureBackoff <= 0 || c.Worker.MaxFailureBackoff < c.Worker.FailureBackoff {
+			return fmt.Errorf("worker.failure_backoff must be positive and at most worker.max_failure_backoff (got %d, %d)",
+				c.Worker.FailureBackoff, c.Worker.MaxFailureBackoff)
+		}
+		if c.Worker.QuarantineAfter <= 0 {
+			return fmt.Errorf("worker.quarantine_after must be positive (got %d)", c.Worker.QuarantineAfter)
+		}
+		if c.Worker.CycleOverdueFactor < 1 {
+			return fmt.Errorf("worker.cycle_overdue_factor must be at least 1 (got %d)", c.Worker.CycleOverdueFactor)
+		}
+		if c.Worker.ShutdownGracePeriod < 0 {
+			return fmt.Errorf("worker.shutdown_grace_period cannot be negative (got %d)", c.Worker.ShutdownGracePeriod)
+		}
+		switch c.Worker.Queue.Backend {
+		case QueueBackendMemory:
+		case QueueBackendRedis:
+			if c.Worker.Queue.RedisURL == "" {
+				return fmt.Errorf("worker.queue.redis_url cannot be empty when using the redis queue")
+			}
+		default:
+			return fmt.Errorf("worker.queue.backend must be %q or %q (got %q)", QueueBackendMemory, QueueBackendRedis, c.Worker.Queue.Backend)
+		}
+		if c.Worker.LeaseDuration <= c.Worker.PollTimeout {
+			return fmt.Errorf("worker.lease_duration must be longer than worker.poll_timeout (got %d, %d)",
+				c.Worker.LeaseDuration, c.Worker.PollTimeout)
+		}
 	}
 
 	return nil
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// adminSchema creates the tables of admin UI sessions and of the audit log of admin actions.
+// Sessions are keyed by the SHA-256 hash of their cookie value, and belong to the admin token they
+// were signed in with, so that rotating the token ends them. Audit entries are kept when their app
+// is removed.
+const adminSchema = `
+	CREATE TABLE IF NOT EXISTS admin_sessions (
+		token_hash TEXT PRIMARY KEY,
+		admin_token_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS admin_actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		namespace TEXT NOT NULL DEFAULT 'default',
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		app_id INTEGER,
+		detail TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_admin_actions_namespace ON admin_actions(namespace, id);
+`
+
+// CreateAdminSession stores a new admin session of an admin token and removes expired ones.
+func (db *DB) CreateAdminSession(ctx context.Context, tokenHash, adminTokenID string, expiresAt time.Time) error {
+	now := time.Now()
+	if _, err := db.ExecContext(ctx, `DELETE FROM admin_sessions WHERE expires_at < ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	query := `INSERT INTO admin_sessions (token_hash, admin_token_id, created_at, expires_at) VALUES (?, ?, ?, ?)`
+	if _, err := db.ExecContext(ctx, query, tokenHash, adminTokenID, now, expiresAt); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// IsAdminSession reports whether an unexpired admin session of an admin token exists.
+func (db *DB) IsAdminSession(ctx context.Context, tokenHash, adminTokenID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM admin_sessions WHERE token_hash = ? AND admin_token_id = ? AND expires_at > ?)`
+	if err := db.QueryRowContext(ctx, query, tokenHash, adminTokenID, time.Now()).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+	return exists, nil
+}
+
+// DeleteAdminSession removes an admin session.
+func (db *DB) DeleteAdminSession(ctx context.Context, tokenHash string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM admin_sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// RecordAdminAction adds an action to the audit log of admin actions.
+func (db *DB) RecordAdminAction(ctx context.Context, action *models.AdminAction) error {
+	query := `
+		INSERT INTO admin_actions (namespace, actor, action, app_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.ExecContext(ctx, query, action.Namespace, action.Actor, action.Action, action.AppID, action.Detail, action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return nil
+}
+
+// GetAdminActions returns the latest admin actions of a namespace, newest first.
+func (db *DB) GetAdminActions(ctx context.Context, namespace string, limit int) ([]*models.AdminAction, error) {
+	query := `
+		SELECT id, namespace, actor, action, app_id, detail, created_at
+		FROM admin_actions
+		WHERE namespace = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := db.QueryContext(ctx, query, namespace, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin actions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var actions []*models.AdminAction
+	for rows.Next() {
+		var action models.AdminAction
+		if err := rows.Scan(&action.ID, &action.Namespace, &action.Actor, &action.Action, &action.AppID, &action.Detail, &action.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan admin action: %w", err)
+		}
+		actions = append(actions, &action)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query admin actions: %w", err)
+	}
+	return actions, nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test that admin sessions are only valid for the admin token they were signed in with, until they
+// expire or are deleted.
+func TestAdminSessions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	if err := db.CreateAdminSession(ctx, "current", "token-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := db.CreateAdminSession(ctx, "expired", "token-1", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	for _, tc := range []struct {
+		hash, tokenID string
+		valid         bool
+	}{
+		{"current", "token-1", true},
+		{"current", "token-2", false},
+		{"expired", "token-1", false},
+		{"unknown", "token-1", false},
+	} {
+		valid, err := db.IsAdminSession(ctx, tc.hash, tc.tokenID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if valid != tc.valid {
+			t.Errorf("Session %s of %s: expected valid=%v", tc.hash, tc.tokenID, tc.valid)
+		}
+	}
+
+	if err := db.DeleteAdminSession(ctx, "current"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if valid, _ := db.IsAdminSession(ctx, "current", "token-1"); valid {
+		t.Errorf("Deleted session is still valid")
+	}
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// aliasSchema creates the table of former GitHub URLs of apps whose repository was renamed or
+// transferred, so that registries still listing a former URL keep referring to the same app. Apps
+// of different namespaces can share a former URL.
+const aliasSchema = `
+	CREATE TABLE IF NOT EXISTS app_aliases (
+		github_url TEXT NOT NULL,
+		app_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (github_url, app_id),
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_app_aliases_app_id ON app_aliases(app_id);
+`
+
+// migrateAliases recreates the aliases table of older versions, whose former URLs were unique
+// across namespaces, keeping its rows.
+func (db *DB) migrateAliases() error {
+	var ddl string
+	err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'app_aliases'`).Scan(&ddl)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect app aliases: %w", err)
+	}
+	if !strings.Contains(ddl, "github_url TEXT PRIMARY KEY") {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, stmt := range []string{
+		`ALTER TABLE app_aliases RENAME TO app_aliases_old`,
+		`DROP INDEX IF EXISTS idx_app_aliases_app_id`,
+		aliasSchema,
+		`INSERT INTO app_aliases (github_url, app_id, created_at) SELECT github_url, app_id, created_at FROM app_aliases_old`,
+		`DROP TABLE app_aliases_old`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to migrate app aliases: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ErrNoMove is returned when confirming the move of an app whose repository was not found moved.
+var ErrNoMove = errors.New("repository of the app was not moved")
+
+// ErrURLTaken is returned when moving an app to the GitHub URL of another app of its namespace.
+var ErrURLTaken = errors.New("another app is listed at the new URL")
+
+// RecordAppMove records the URL GitHub redirects an app's repository to, awaiting confirmation of
+// the move. An empty URL clears it.
+func (db *DB) RecordAppMove(ctx context.Context, id int64, movedTo string) error {
+	_, err := db.ExecContext(ctx, `UPDATE apps SET moved_to = ? WHERE id = ?`, nullString(movedTo), id)
+	if err != nil {
+		return fmt.Errorf("failed to record move: %w", err)
+	}
+	return nil
+}
+
+// GetMovedApps retrieves the apps of a namespace, or of all namespaces if empty, whose repository
+// was found moved and awaits confirmation.
+func (db *DB) GetMovedApps(ctx context.Context, namespace string) ([]*models.App, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM apps
+		WHERE moved_to IS NOT NULL AND (? = '' OR namespace = ?)
+		ORDER BY id ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, namespace, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apps []*models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+		apps = append(apps, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return apps, nil
+}
+
+// ConfirmAppMove moves an app to the URL its repository was found moved to, keeping its former URL
+// as an alias, and returns the new URL. The app keeps its ID, so its verification history stays
+// attached.
+func (db *DB) ConfirmAppMove(ctx context.Context, id int64) (string, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var namespace, githubURL string
+	var movedTo sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT namespace, github_url, moved_to FROM apps WHERE id = ?`, id).Scan(&namespace, &githubURL, &movedTo)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", fmt.Errorf("app not found")
+	case err != nil:
+		return "", fmt.Errorf("failed to get app: %w", err)
+	case movedTo.String == "":
+		return "", ErrNoMove
+	}
+
+	var taken int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM apps WHERE namespace = ? AND github_url = ? AND id != ?`, namespace, movedTo.String, id).Scan(&taken); err != nil {
+		return "", fmt.Errorf("failed to check new URL: %w", err)
+	}
+	if taken > 0 {
+		return "", ErrURLTaken
+	}
+
+	// A repository moved back to a former URL is no longer an alias of it, and the former URL of
+	// the app is an alias of it alone in the namespace.
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM app_aliases WHERE github_url IN (?, ?) AND app_id IN (SELECT id FROM apps WHERE namespace = ?)
+	`, movedTo.String, githubURL, namespace); err != nil {
+		return "", fmt.Errorf("failed to remove alias: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO app_aliases (github_url, app_id) VALUES (?, ?)`, githubURL, id); err != nil {
+		return "", fmt.Errorf("failed to record alias: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE apps SET github_url = ?, moved_to = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, movedTo.String, id); err != nil {
+		return "", fmt.Errorf("failed to update app: %w", err)
+	}
+	if err := indexApp(ctx, tx, id); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return movedTo.String, nil
+}
+
+// GetAppAliases returns the former GitHub URLs of an app, oldest first.
+func (db *DB) GetAppAliases(ctx context.Context, id int64) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT github_url FROM app_aliases WHERE app_id = ? ORDER BY created_at, github_url`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aliases: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// canonicalURL returns the current GitHub URL of the app of a namespace a URL is an alias of, or
+// the URL itself if it is not an alias.
+func (db *DB) canonicalURL(ctx context.Context, namespace, githubURL string) (string, error) {
+	var current string
+	err := db.QueryRowContext(ctx, `
+		SELECT a.github_url FROM app_aliases al JOIN apps a ON a.id = al.app_id
+		WHERE al.github_url = ? AND a.namespace = ?
+	`, githubURL, namespace).Scan(&current)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return githubURL, nil
+	case err != nil:
+		return "", fmt.Errorf("failed to resolve alias: %w", err)
+	}
+	return current, nil
+}
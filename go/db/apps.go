@@ -3,72 +3,149 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ptrus/rofl-attestations/models"
 )
 
-// CreateApp creates a new app in the database.
-func (db *DB) CreateApp(ctx context.Context, githubURL, gitRef string) (*models.App, error) {
-	query := `
-		INSERT INTO apps (github_url, git_ref)
-		VALUES (?, ?)
-		RETURNING id, github_url, git_ref, rofl_yaml, created_at, updated_at
-	`
+// appColumns is the column list selected for every app query, in scanApp order.
+const appColumns = `
+	id, namespace, github_url, slug, git_ref, featured, stars,
+	owner_login, owner_name, owner_avatar_url, owner_type, owner_blog, owner_email, owner_verified, fork_of,
+	logo_url, logo_source, logo IS NOT NULL, logo_updated_at, rofl_yaml,
+	manifest_path, manifest_etag, manifest_last_modified,
+	compose_yaml, compose_yaml_ref, compose_commit_sha,
+	readme_excerpt, readme_commit_sha,
+	consecutive_failures, next_attempt_at, last_error,
+	attestation_url, selected_deployments, probe_checked_at, probe_attested_at, probe_deployment, probe_error,
+	domain_checked_at, domain_verified, domain_method, domain_error, repo_status, repo_status_at, moved_to,
+	created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+// scanApp scans a row selected with appColumns into an app.
+func scanApp(row rowScanner) (*models.App, error) {
 	app := &models.App{}
-	err := db.QueryRowContext(ctx, query, githubURL, gitRef).Scan(
+	err := row.Scan(
 		&app.ID,
+		&app.Namespace,
 		&app.GitHubURL,
+		&app.Slug,
 		&app.GitRef,
+		&app.Featured,
+		&app.Stars,
+		&app.OwnerLogin,
+		&app.OwnerName,
+		&app.OwnerAvatarURL,
+		&app.OwnerType,
+		&app.OwnerBlog,
+		&app.OwnerEmail,
+		&app.OwnerVerified,
+		&app.ForkOf,
+		&app.LogoURL,
+		&app.LogoSource,
+		&app.HasLogo,
+		&app.LogoUpdatedAt,
 		&app.RoflYAML,
+		&app.ManifestPath,
+		&app.ManifestETag,
+		&app.ManifestLastModified,
+		&app.ComposeYAML,
+		&app.ComposeYAMLRef,
+		&app.ComposeCommitSHA,
+		&app.ReadmeExcerpt,
+		&app.ReadmeCommitSHA,
+		&app.ConsecutiveFailures,
+		&app.NextAttemptAt,
+		&app.LastError,
+		&app.AttestationURL,
+		&app.SelectedDeployments,
+		&app.ProbeCheckedAt,
+		&app.ProbeAttestedAt,
+		&app.ProbeDeployment,
+		&app.ProbeError,
+		&app.DomainCheckedAt,
+		&app.DomainVerified,
+		&app.DomainMethod,
+		&app.DomainError,
+		&app.RepoStatus,
+		&app.RepoStatusAt,
+		&app.MovedTo,
 		&app.CreatedAt,
 		&app.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// CreateApp creates a new app in the database.
+func (db *DB) CreateApp(ctx context.Context, githubURL, gitRef string) (*models.App, error) {
+	query := `
+		INSERT INTO apps (github_url, git_ref)
+		VALUES (?, ?)
+		RETURNING ` + appColumns
+
+	app, err := scanApp(db.QueryRowContext(ctx, query, githubURL, gitRef))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create app: %w", err)
 	}
 
+	if err := indexApp(ctx, db, app.ID); err != nil {
+		return nil, err
+	}
+
 	return app, nil
 }
 
-// UpsertApp creates a new app or updates git_ref if the app already exists.
-func (db *DB) UpsertApp(ctx context.Context, githubURL, gitRef string) error {
+// UpsertApp creates a new app in a namespace or updates its registry settings (git_ref, featured,
+// logo_url, attestation_url, and the selected deployments) if the app already exists. Namespaces
+// list apps independently: a repository listed in several namespaces is a separate app in each.
+func (db *DB) UpsertApp(ctx context.Context, namespace, githubURL, gitRef string, featured bool, logoURL, attestationURL string, deployments []string) error {
+	githubURL, err := db.canonicalURL(ctx, namespace, githubURL)
+	if err != nil {
+		return err
+	}
 	now := time.Now()
 	query := `
-		INSERT INTO apps (github_url, git_ref, updated_at)
-		VALUES (?, ?, ?)
-		ON CONFLICT(github_url) DO UPDATE SET
+		INSERT INTO apps (namespace, github_url, git_ref, featured, logo_url, attestation_url, selected_deployments, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(namespace, github_url) DO UPDATE SET
 			git_ref = excluded.git_ref,
+			featured = excluded.featured,
+			logo_url = excluded.logo_url,
+			attestation_url = excluded.attestation_url,
+			selected_deployments = excluded.selected_deployments,
 			updated_at = excluded.updated_at
+		RETURNING id
 	`
 
-	_, err := db.ExecContext(ctx, query, githubURL, gitRef, now)
+	var id int64
+	err = db.QueryRowContext(ctx, query, namespace, githubURL, gitRef, featured, nullString(logoURL), nullString(attestationURL), nullString(strings.Join(deployments, ",")), now).Scan(&id)
 	if err != nil {
 		return fmt.Errorf("failed to upsert app: %w", err)
 	}
 
-	return nil
+	return indexApp(ctx, db, id)
 }
 
 // GetAppByID retrieves an app by ID.
 func (db *DB) GetAppByID(ctx context.Context, id int64) (*models.App, error) {
 	query := `
-		SELECT id, github_url, git_ref, rofl_yaml, created_at, updated_at
+		SELECT ` + appColumns + `
 		FROM apps
 		WHERE id = ?
 	`
 
-	app := &models.App{}
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&app.ID,
-		&app.GitHubURL,
-		&app.GitRef,
-		&app.RoflYAML,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	app, err := scanApp(db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("app not found")
@@ -80,23 +157,17 @@ func (db *DB) GetAppByID(ctx context.Context, id int64) (*models.App, error) {
 	return app, nil
 }
 
-// GetAppByURL retrieves an app by GitHub URL.
-func (db *DB) GetAppByURL(ctx context.Context, githubURL string) (*models.App, error) {
+// GetAppByURL retrieves an app of a namespace by its GitHub URL or a former one.
+func (db *DB) GetAppByURL(ctx context.Context, namespace, githubURL string) (*models.App, error) {
 	query := `
-		SELECT id, github_url, git_ref, rofl_yaml, created_at, updated_at
+		SELECT ` + appColumns + `
 		FROM apps
-		WHERE github_url = ?
+		WHERE namespace = ? AND (github_url = ? OR id IN (SELECT app_id FROM app_aliases WHERE github_url = ?))
+		ORDER BY github_url = ? DESC
+		LIMIT 1
 	`
 
-	app := &models.App{}
-	err := db.QueryRowContext(ctx, query, githubURL).Scan(
-		&app.ID,
-		&app.GitHubURL,
-		&app.GitRef,
-		&app.RoflYAML,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	app, err := scanApp(db.QueryRowContext(ctx, query, namespace, githubURL, githubURL, githubURL))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("app not found")
@@ -108,15 +179,69 @@ func (db *DB) GetAppByURL(ctx context.Context, githubURL string) (*models.App, e
 	return app, nil
 }
 
-// GetAllApps retrieves all apps.
+// GetAllApps retrieves the apps of all namespaces.
 func (db *DB) GetAllApps(ctx context.Context) ([]*models.App, error) {
+	return db.ListApps(ctx, "", AppOrderID)
+}
+
+// AppOrder is the order in which apps are listed.
+type AppOrder string
+
+// App orders.
+const (
+	AppOrderID       AppOrder = "id"       // Registry order.
+	AppOrderFeatured AppOrder = "featured" // Featured apps first, then most recently verified.
+	AppOrderRecent   AppOrder = "recent"   // Most recently verified first.
+	AppOrderName     AppOrder = "name"     // Alphabetical by manifest name.
+	AppOrderStars    AppOrder = "stars"    // Most GitHub stars first.
+	AppOrderStreak   AppOrder = "streak"   // Longest continuously verified first.
+)
+
+// Expressions used by app orders.
+const (
+	lastVerifiedExpr  = `(SELECT MAX(d.last_verified) FROM deployments d WHERE d.app_id = apps.id AND d.status = 'verified')`
+	verifiedSinceExpr = `(SELECT MIN(d.verified_since) FROM deployments d WHERE d.app_id = apps.id AND d.status = 'verified')`
+	nameExpr          = `(SELECT NULLIF(f.name, '') FROM apps_fts f WHERE f.docid = apps.id)`
+)
+
+// Valid returns whether o is a known app order.
+func (o AppOrder) Valid() bool {
+	switch o {
+	case AppOrderID, AppOrderFeatured, AppOrderRecent, AppOrderName, AppOrderStars, AppOrderStreak:
+		return true
+	default:
+		return false
+	}
+}
+
+// orderBy returns the ORDER BY clause of an app query, falling back to registry order.
+// Ties and apps without a value are listed in registry order.
+func (o AppOrder) orderBy() string {
+	switch o {
+	case AppOrderFeatured:
+		return `ORDER BY featured DESC, ` + lastVerifiedExpr + ` DESC NULLS LAST, id ASC`
+	case AppOrderRecent:
+		return `ORDER BY ` + lastVerifiedExpr + ` DESC NULLS LAST, id ASC`
+	case AppOrderName:
+		return `ORDER BY ` + nameExpr + ` COLLATE NOCASE ASC NULLS LAST, id ASC`
+	case AppOrderStars:
+		return `ORDER BY stars DESC NULLS LAST, id ASC`
+	case AppOrderStreak:
+		return `ORDER BY ` + verifiedSinceExpr + ` ASC NULLS LAST, id ASC`
+	default:
+		return `ORDER BY id ASC`
+	}
+}
+
+// ListApps retrieves the apps of a namespace, or of all namespaces if it is empty, in the given order.
+func (db *DB) ListApps(ctx context.Context, namespace string, order AppOrder) ([]*models.App, error) {
 	query := `
-		SELECT id, github_url, git_ref, rofl_yaml, created_at, updated_at
+		SELECT ` + appColumns + `
 		FROM apps
-		ORDER BY id ASC
-	`
+		WHERE ? = '' OR namespace = ?
+		` + order.orderBy()
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query, namespace, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query apps: %w", err)
 	}
@@ -126,15 +251,7 @@ func (db *DB) GetAllApps(ctx context.Context) ([]*models.App, error) {
 
 	var apps []*models.App
 	for rows.Next() {
-		app := &models.App{}
-		err := rows.Scan(
-			&app.ID,
-			&app.GitHubURL,
-			&app.GitRef,
-			&app.RoflYAML,
-			&app.CreatedAt,
-			&app.UpdatedAt,
-		)
+		app, err := scanApp(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan app: %w", err)
 		}
@@ -148,32 +265,83 @@ func (db *DB) GetAllApps(ctx context.Context) ([]*models.App, error) {
 	return apps, nil
 }
 
-// UpsertDeployment creates or updates a deployment record.
-func (db *DB) UpsertDeployment(ctx context.Context, appID int64, deploymentName, commitSHA, status, verificationMsg string) error {
+// UpsertDeployment creates or updates a deployment record, returning its previous status, or
+// an empty status if the deployment is new. The verified streak of the deployment starts with a
+// successful verification and ends with any other status, and likewise its failed streak with a
+// failed one.
+func (db *DB) UpsertDeployment(ctx context.Context, appID int64, deploymentName, commitSHA, status, verificationMsg string) (models.VerificationStatus, error) {
 	now := time.Now()
 	query := `
-		INSERT INTO deployments (app_id, deployment_name, commit_sha, status, verification_msg, last_verified)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO deployments (app_id, deployment_name, commit_sha, status, verification_msg, last_verified, verified_since, first_verified_at, verified_streak, failed_streak)
+		VALUES (?, ?, ?, ?, ?, ?, CASE WHEN ? = 'verified' THEN ? END, CASE WHEN ? = 'verified' THEN ? END, CASE WHEN ? = 'verified' THEN 1 ELSE 0 END, CASE WHEN ? = 'failed' THEN 1 ELSE 0 END)
 		ON CONFLICT(app_id, deployment_name) DO UPDATE SET
 			commit_sha = excluded.commit_sha,
+			commit_tag = CASE WHEN deployments.commit_sha = excluded.commit_sha THEN deployments.commit_tag END,
 			status = excluded.status,
 			verification_msg = excluded.verification_msg,
+			verification_report = NULL,
 			last_verified = excluded.last_verified,
+			verified_since = CASE
+				WHEN excluded.status != 'verified' THEN NULL
+				WHEN deployments.status = 'verified' THEN COALESCE(deployments.verified_since, excluded.verified_since)
+				ELSE excluded.verified_since
+			END,
+			first_verified_at = COALESCE(deployments.first_verified_at, excluded.first_verified_at),
+			verified_streak = CASE
+				WHEN excluded.status != 'verified' THEN 0
+				WHEN deployments.status = 'verified' THEN deployments.verified_streak + 1
+				ELSE 1
+			END,
+			failed_streak = CASE
+				WHEN excluded.status != 'failed' THEN 0
+				WHEN deployments.status = 'failed' THEN deployments.failed_streak + 1
+				ELSE 1
+			END,
 			updated_at = ?
 	`
 
-	_, err := db.ExecContext(ctx, query, appID, deploymentName, commitSHA, status, verificationMsg, now, now)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to upsert deployment: %w", err)
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	return nil
+	var previous models.VerificationStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM deployments WHERE app_id = ? AND deployment_name = ?`, appID, deploymentName).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get deployment status: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, appID, deploymentName, commitSHA, status, verificationMsg, now, status, now, status, now, status, status, now); err != nil {
+		return "", fmt.Errorf("failed to upsert deployment: %w", err)
+	}
+	if err := appendVerificationEvent(ctx, tx, appID, deploymentName, now); err != nil {
+		return "", err
+	}
+	if previous != models.VerificationStatus(status) {
+		if err := insertStatusEvent(ctx, tx, appID, deploymentName, previous, models.VerificationStatus(status), commitSHA, verificationMsg, now); err != nil {
+			return "", err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return previous, nil
 }
 
 // GetDeploymentsByAppID retrieves all deployments for an app.
 func (db *DB) GetDeploymentsByAppID(ctx context.Context, appID int64) ([]*models.Deployment, error) {
 	query := `
-		SELECT id, app_id, deployment_name, commit_sha, status, verification_msg, last_verified, created_at, updated_at
+		SELECT id, app_id, deployment_name, commit_sha, commit_tag, status, verification_msg,
+			verification_log IS NOT NULL OR verification_log_ref IS NOT NULL,
+			cli_version, builder_image,
+			last_verified, verified_since, first_verified_at, verified_streak, failed_streak,
+			live_checked_at, live_instances, live_unverified_enclaves,
+			policy_checked_at, policy_violations, verification_report,
+			created_at, updated_at
 		FROM deployments
 		WHERE app_id = ?
 		ORDER BY deployment_name ASC
@@ -189,21 +357,49 @@ func (db *DB) GetDeploymentsByAppID(ctx context.Context, appID int64) ([]*models
 
 	var deployments []*models.Deployment
 	for rows.Next() {
-		deployment := &models.Deployment{}
+		var (
+			deployment         = &models.Deployment{}
+			policyViolations   sql.NullString
+			verificationReport sql.NullString
+		)
 		err := rows.Scan(
 			&deployment.ID,
 			&deployment.AppID,
 			&deployment.DeploymentName,
 			&deployment.CommitSHA,
+			&deployment.CommitTag,
 			&deployment.Status,
 			&deployment.VerificationMsg,
+			&deployment.HasLog,
+			&deployment.CLIVersion,
+			&deployment.BuilderImage,
 			&deployment.LastVerified,
+			&deployment.VerifiedSince,
+			&deployment.FirstVerifiedAt,
+			&deployment.VerifiedStreak,
+			&deployment.FailedStreak,
+			&deployment.LiveCheckedAt,
+			&deployment.LiveInstances,
+			&deployment.LiveUnverifiedEnclaves,
+			&deployment.PolicyCheckedAt,
+			&policyViolations,
+			&verificationReport,
 			&deployment.CreatedAt,
 			&deployment.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan deployment: %w", err)
 		}
+		if policyViolations.Valid {
+			if err := json.Unmarshal([]byte(policyViolations.String), &deployment.PolicyViolations); err != nil {
+				return nil, fmt.Errorf("failed to decode policy violations: %w", err)
+			}
+		}
+		if verificationReport.Valid {
+			if err := json.Unmarshal([]byte(verificationReport.String), &deployment.VerificationReport); err != nil {
+				return nil, fmt.Errorf("failed to decode verification report: %w", err)
+			}
+		}
 		deployments = append(deployments, deployment)
 	}
 
@@ -214,18 +410,538 @@ func (db *DB) GetDeploymentsByAppID(ctx context.Context, appID int64) ([]*models
 	return deployments, nil
 }
 
-// UpdateAppRoflYAML updates the rofl.yaml content of an app.
-func (db *DB) UpdateAppRoflYAML(ctx context.Context, id int64, roflYAML string) error {
+// UpdateDeploymentToolchain records the oasis-cli version and builder image the backend used for the
+// last verification of a deployment. Empty values are stored as unknown.
+func (db *DB) UpdateDeploymentToolchain(ctx context.Context, appID int64, deploymentName, cliVersion, builderImage string) error {
+	query := `
+		UPDATE deployments
+		SET cli_version = ?, builder_image = ?
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, nullString(cliVersion), nullString(builderImage), appID, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment toolchain: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeploymentCommitTag records the tag pointing at the verified commit of the deployments of
+// an app verified at that commit. An empty tag is stored as none.
+func (db *DB) UpdateDeploymentCommitTag(ctx context.Context, appID int64, commitSHA, tag string) error {
+	query := `
+		UPDATE deployments
+		SET commit_tag = ?
+		WHERE app_id = ? AND commit_sha = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, nullString(tag), appID, commitSHA)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment commit tag: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeploymentsExcept removes the deployments of an app other than the given ones, e.g. when
+// the registry limits which deployments are verified, together with their history. The
+// verification log keeps recording them.
+func (db *DB) DeleteDeploymentsExcept(ctx context.Context, appID int64, keep []string) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	condition := `app_id = ?`
+	args := []any{appID}
+	if len(keep) > 0 {
+		condition += ` AND deployment_name NOT IN (?` + strings.Repeat(`, ?`, len(keep)-1) + `)`
+		for _, name := range keep {
+			args = append(args, name)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM deployments WHERE `+condition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete deployments: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if deleted > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM notification_outbox WHERE event_id IN (SELECT id FROM events WHERE `+condition+`)`, args...); err != nil {
+			return 0, fmt.Errorf("failed to delete notifications: %w", err)
+		}
+		for _, table := range historyTables {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE `+condition, args...); err != nil {
+				return 0, fmt.Errorf("failed to delete history from %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// UpdateDeploymentLiveCheck records the number of active instances of a deployment and the enclave
+// identities its on-chain policy admits that are not part of the verified build.
+func (db *DB) UpdateDeploymentLiveCheck(ctx context.Context, appID int64, deploymentName string, instances int, unverified []string) error {
+	query := `
+		UPDATE deployments
+		SET live_checked_at = CURRENT_TIMESTAMP, live_instances = ?, live_unverified_enclaves = ?
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, instances, strings.Join(unverified, ","), appID, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment live check: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeploymentPolicy records the violations of the verification policy by a deployment.
+func (db *DB) UpdateDeploymentPolicy(ctx context.Context, appID int64, deploymentName string, violations []models.PolicyViolation) error {
+	data, err := json.Marshal(violations)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy violations: %w", err)
+	}
+
+	query := `
+		UPDATE deployments
+		SET policy_checked_at = ?, policy_violations = ?
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	_, err = db.ExecContext(ctx, query, time.Now(), string(data), appID, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment policy: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeploymentReport stores the enclave identities expected and built by the last verification
+// of a deployment, as reported by the verification. Recording a new verification status clears it.
+func (db *DB) UpdateDeploymentReport(ctx context.Context, appID int64, deploymentName string, report *models.VerificationReport) error {
+	var data sql.NullString
+	if report != nil {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode verification report: %w", err)
+		}
+		data = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	query := `
+		UPDATE deployments
+		SET verification_report = ?
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, data, appID, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment verification report: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeploymentLog stores the build output of the last verification of a deployment.
+// If the output was offloaded to object storage, logRef is its key and log an excerpt.
+func (db *DB) UpdateDeploymentLog(ctx context.Context, appID int64, deploymentName, log, logRef string) error {
+	query := `
+		UPDATE deployments
+		SET verification_log = ?, verification_log_ref = ?
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, log, nullString(logRef), appID, deploymentName)
+	if err != nil {
+		return fmt.Errorf("failed to update deployment log: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeploymentLog retrieves the stored build output of a deployment and its object storage key, if offloaded.
+func (db *DB) GetDeploymentLog(ctx context.Context, appID int64, deploymentName string) (sql.NullString, sql.NullString, error) {
+	query := `
+		SELECT verification_log, verification_log_ref
+		FROM deployments
+		WHERE app_id = ? AND deployment_name = ?
+	`
+
+	var log, logRef sql.NullString
+	err := db.QueryRowContext(ctx, query, appID, deploymentName).Scan(&log, &logRef)
+	if err == sql.ErrNoRows {
+		return log, logRef, fmt.Errorf("deployment not found: %w", err)
+	}
+	if err != nil {
+		return log, logRef, fmt.Errorf("failed to get deployment log: %w", err)
+	}
+
+	return log, logRef, nil
+}
+
+// nullString converts an empty string to NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// MarkStaleDeployments downgrades verified deployments last verified before the cutoff to stale.
+// It returns the number of deployments downgraded.
+func (db *DB) MarkStaleDeployments(ctx context.Context, cutoff time.Time) (int64, error) {
+	now := time.Now()
+	query := `
+		UPDATE deployments
+		SET status = ?, verified_since = NULL, verified_streak = 0, updated_at = ?
+		WHERE status = ? AND last_verified < ?
+		RETURNING app_id, deployment_name, COALESCE(commit_sha, '')
+	`
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, query, models.StatusStale, now, models.StatusVerified, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark stale deployments: %w", err)
+	}
+	type deploymentKey struct {
+		appID     int64
+		name      string
+		commitSHA string
+	}
+	var stale []deploymentKey
+	for rows.Next() {
+		var key deploymentKey
+		if err := rows.Scan(&key.appID, &key.name, &key.commitSHA); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan deployment: %w", err)
+		}
+		stale = append(stale, key)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	for _, key := range stale {
+		if err := appendVerificationEvent(ctx, tx, key.appID, key.name, now); err != nil {
+			return 0, err
+		}
+		details := "Not re-verified since " + cutoff.UTC().Format(time.RFC3339)
+		if err := insertStatusEvent(ctx, tx, key.appID, key.name, models.StatusVerified, models.StatusStale, key.commitSHA, details, now); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int64(len(stale)), nil
+}
+
+// UpdateAppRoflYAML updates the rofl.yaml content of an app, the filename it was found under,
+// and the HTTP cache validators used to make subsequent fetches conditional.
+func (db *DB) UpdateAppRoflYAML(ctx context.Context, id int64, roflYAML, manifestPath, etag, lastModified string) error {
 	query := `
 		UPDATE apps
-		SET rofl_yaml = ?, updated_at = ?
+		SET rofl_yaml = ?, manifest_path = ?, manifest_etag = ?, manifest_last_modified = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	_, err := db.ExecContext(ctx, query, roflYAML, time.Now(), id)
+	_, err := db.ExecContext(ctx, query, roflYAML, manifestPath, etag, lastModified, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to update rofl.yaml: %w", err)
 	}
 
+	if err := assignSlug(ctx, db, id); err != nil {
+		return err
+	}
+	return indexApp(ctx, db, id)
+}
+
+// UpdateAppCompose stores the compose file referenced by the manifest, as fetched at commitSHA.
+// If the file was offloaded to object storage, composeRef is its key and composeYAML an excerpt.
+func (db *DB) UpdateAppCompose(ctx context.Context, id int64, composeYAML, composeRef, commitSHA string) error {
+	query := `
+		UPDATE apps
+		SET compose_yaml = ?, compose_yaml_ref = ?, compose_commit_sha = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, composeYAML, nullString(composeRef), commitSHA, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update compose file: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAppReadme stores the README excerpt of an app and the commit it was fetched at.
+// An empty excerpt records that the repository has no README at that commit.
+func (db *DB) UpdateAppReadme(ctx context.Context, id int64, excerpt, commitSHA string) error {
+	query := `
+		UPDATE apps
+		SET readme_excerpt = ?, readme_commit_sha = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, excerpt, commitSHA, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update readme: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAppStars updates the GitHub stargazer count of an app.
+func (db *DB) UpdateAppStars(ctx context.Context, id int64, stars int) error {
+	_, err := db.ExecContext(ctx, `UPDATE apps SET stars = ? WHERE id = ?`, stars, id)
+	if err != nil {
+		return fmt.Errorf("failed to update stars: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAppRepoStatus records whether an app's repository was archived or deleted, with
+// models.RepoArchived or models.RepoDeleted, or is maintained if status is empty. The time the
+// status was first recorded is kept while it is unchanged.
+func (db *DB) UpdateAppRepoStatus(ctx context.Context, id int64, status string) error {
+	query := `
+		UPDATE apps
+		SET repo_status_at = CASE
+				WHEN ? IS NULL THEN NULL
+				WHEN repo_status IS ? THEN repo_status_at
+				ELSE CURRENT_TIMESTAMP
+			END,
+			repo_status = ?
+		WHERE id = ?
+	`
+	value := nullString(status)
+	if _, err := db.ExecContext(ctx, query, value, value, value, id); err != nil {
+		return fmt.Errorf("failed to update repository status: %w", err)
+	}
+	return nil
+}
+
+// RecordAttestationProbe records the outcome of probing the attestation endpoint of an app. On
+// success probeErr is empty and deployment names the deployment whose identity was attested;
+// failures keep the time and deployment of the last successful probe.
+func (db *DB) RecordAttestationProbe(ctx context.Context, id int64, deployment, probeErr string) error {
+	query := `
+		UPDATE apps
+		SET probe_checked_at = CURRENT_TIMESTAMP, probe_error = ?
+		WHERE id = ?
+	`
+	args := []any{nullString(probeErr), id}
+	if probeErr == "" {
+		query = `
+			UPDATE apps
+			SET probe_checked_at = CURRENT_TIMESTAMP, probe_attested_at = CURRENT_TIMESTAMP,
+				probe_deployment = ?, probe_error = NULL
+			WHERE id = ?
+		`
+		args = []any{deployment, id}
+	}
+
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record attestation probe: %w", err)
+	}
+
+	return nil
+}
+
+// RecordDomainCheck records the outcome of checking control of an app's homepage domain. On
+// success checkErr is empty and domain and method name the verified domain and how it was proven.
+func (db *DB) RecordDomainCheck(ctx context.Context, id int64, domain, method, checkErr string) error {
+	query := `
+		UPDATE apps
+		SET domain_checked_at = CURRENT_TIMESTAMP, domain_verified = ?, domain_method = ?, domain_error = ?
+		WHERE id = ?
+	`
+	if _, err := db.ExecContext(ctx, query, nullString(domain), nullString(method), nullString(checkErr), id); err != nil {
+		return fmt.Errorf("failed to record domain check: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAppOwner updates the GitHub profile of the owner of an app's repository.
+func (db *DB) UpdateAppOwner(ctx context.Context, id int64, login, name, avatarURL, ownerType, blog, email string, verified bool) error {
+	query := `
+		UPDATE apps
+		SET owner_login = ?, owner_name = ?, owner_avatar_url = ?, owner_type = ?,
+			owner_blog = ?, owner_email = ?, owner_verified = ?
+		WHERE id = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, nullString(login), nullString(name), nullString(avatarURL), nullString(ownerType),
+		nullString(blog), nullString(email), verified, id)
+	if err != nil {
+		return fmt.Errorf("failed to update owner: %w", err)
+	}
+
 	return nil
 }
+
+// UpdateAppForkOf records the repository an app's repository was forked from, empty if it is not a fork.
+func (db *DB) UpdateAppForkOf(ctx context.Context, id int64, forkOf string) error {
+	if _, err := db.ExecContext(ctx, `UPDATE apps SET fork_of = ? WHERE id = ?`, nullString(forkOf), id); err != nil {
+		return fmt.Errorf("failed to update fork parent: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAppLogo stores the sanitized PNG logo of an app and the URL it was fetched from.
+// A nil logo removes it.
+func (db *DB) UpdateAppLogo(ctx context.Context, id int64, source string, logo []byte) error {
+	query := `
+		UPDATE apps
+		SET logo_source = ?, logo = ?, logo_updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, nullString(source), logo, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update logo: %w", err)
+	}
+
+	return nil
+}
+
+// GetAppLogo retrieves the sanitized PNG logo of an app and when it was fetched.
+func (db *DB) GetAppLogo(ctx context.Context, id int64) ([]byte, time.Time, error) {
+	var (
+		logo      []byte
+		updatedAt sql.NullTime
+	)
+	err := db.QueryRowContext(ctx, `SELECT logo, logo_updated_at FROM apps WHERE id = ?`, id).Scan(&logo, &updatedAt)
+	if err == sql.ErrNoRows || (err == nil && logo == nil) {
+		return nil, time.Time{}, fmt.Errorf("logo not found")
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get logo: %w", err)
+	}
+
+	return logo, updatedAt.Time, nil
+}
+
+// RecordAppFailure increments the consecutive failure count of an app and defers its next attempt.
+// It returns the new failure count.
+func (db *DB) RecordAppFailure(ctx context.Context, id int64, errMsg string, nextAttemptAt time.Time) (int, error) {
+	query := `
+		UPDATE apps
+		SET consecutive_failures = consecutive_failures + 1, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+		RETURNING consecutive_failures
+	`
+
+	var failures int
+	if err := db.QueryRowContext(ctx, query, errMsg, nextAttemptAt, id).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record app failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+// ResetAppFailures clears the failure tracking of an app, e.g. after a successful attempt.
+func (db *DB) ResetAppFailures(ctx context.Context, id int64) error {
+	query := `
+		UPDATE apps
+		SET consecutive_failures = 0, last_error = NULL, next_attempt_at = NULL
+		WHERE id = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset app failures: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnverifiedApps retrieves the apps of a namespace, or of all namespaces if it is empty,
+// without a verified deployment.
+func (db *DB) GetUnverifiedApps(ctx context.Context, namespace string) ([]*models.App, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM apps
+		WHERE NOT EXISTS (
+			SELECT 1 FROM deployments d WHERE d.app_id = apps.id AND d.status = 'verified'
+		) AND (? = '' OR namespace = ?)
+		ORDER BY id ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, namespace, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apps []*models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return apps, nil
+}
+
+// GetQuarantinedApps retrieves the apps of a namespace, or of all namespaces if it is empty, with
+// at least minFailures consecutive failures.
+func (db *DB) GetQuarantinedApps(ctx context.Context, namespace string, minFailures int) ([]*models.App, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM apps
+		WHERE consecutive_failures >= ? AND (? = '' OR namespace = ?)
+		ORDER BY consecutive_failures DESC, id ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, minFailures, namespace, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apps []*models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test that databases of older versions, whose GitHub URLs, aliases, and slugs were unique across
+// namespaces, are migrated with their rows, and that namespaces then list repositories
+// independently of each other.
+func TestAppNamespaces(t *testing.T) {
+	ctx := context.Background()
+	const (
+		githubURL = "https://github.com/example/app"
+		formerURL = "https://github.com/example/old-app"
+		manifest  = "name: demo"
+	)
+
+	// Create the tables of an older version from the current ones.
+	current := newTestDB(t)
+	legacy := []struct {
+		table        string
+		replacements []string
+	}{
+		{"apps", []string{"github_url TEXT NOT NULL,", "github_url TEXT NOT NULL UNIQUE,", ",\n\t\tUNIQUE (namespace, github_url)", ""}},
+		{"deployments", nil},
+		{"app_aliases", []string{"github_url TEXT NOT NULL,", "github_url TEXT PRIMARY KEY,", "\n\t\tPRIMARY KEY (github_url, app_id),", ""}},
+	}
+	db, err := New(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	for _, l := range legacy {
+		var ddl string
+		if err := current.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, l.table).Scan(&ddl); err != nil {
+			t.Fatalf("Failed to get definition of %s: %v", l.table, err)
+		}
+		replaced := strings.NewReplacer(l.replacements...).Replace(ddl)
+		if len(l.replacements) > 0 && replaced == ddl {
+			t.Fatalf("Failed to create legacy definition of %s", l.table)
+		}
+		if _, err := db.ExecContext(ctx, replaced); err != nil {
+			t.Fatalf("Failed to create legacy %s: %v", l.table, err)
+		}
+	}
+	for _, stmt := range []string{
+		`CREATE UNIQUE INDEX idx_apps_slug ON apps(slug)`,
+		`INSERT INTO apps (github_url, slug, git_ref, rofl_yaml) VALUES ('` + githubURL + `', 'demo', 'main', '` + manifest + `')`,
+		`INSERT INTO deployments (app_id, deployment_name) VALUES (1, 'mainnet')`,
+		`INSERT INTO app_aliases (github_url, app_id) VALUES ('` + formerURL + `', 1)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to seed legacy database: %v", err)
+		}
+	}
+
+	if err := db.InitSchema(); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+	app, err := db.GetAppByURL(ctx, "default", formerURL)
+	if err != nil || app.ID != 1 || app.GitHubURL != githubURL {
+		t.Fatalf("Failed to get migrated app by its alias: %+v, %v", app, err)
+	}
+	if deployments, err := db.GetDeploymentsByAppID(ctx, app.ID); err != nil || len(deployments) != 1 {
+		t.Errorf("Deployments of the migrated app were not kept: %v, %v", deployments, err)
+	}
+
+	// Another namespace lists the same repository, and the former URL, as apps of its own.
+	for _, url := range []string{githubURL, formerURL} {
+		if err := db.UpsertApp(ctx, "partner", url, "develop", false, "", "", nil); err != nil {
+			t.Fatalf("Failed to add %s to another namespace: %v", url, err)
+		}
+		other, err := db.GetAppByURL(ctx, "partner", url)
+		if err != nil || other.ID == app.ID || other.GitRef != "develop" {
+			t.Fatalf("Expected a separate app for %s, got %+v, %v", url, other, err)
+		}
+		if err := db.UpdateAppRoflYAML(ctx, other.ID, manifest, "rofl.yaml", "", ""); err != nil {
+			t.Fatalf("Failed to update manifest: %v", err)
+		}
+	}
+	if app, err := db.GetAppByURL(ctx, "default", githubURL); err != nil || app.ID != 1 || app.GitRef != "main" {
+		t.Errorf("App of the default namespace was changed: %+v, %v", app, err)
+	}
+
+	for namespace, want := range map[string]string{"default": githubURL, "partner": githubURL} {
+		app, err := db.GetAppBySlug(ctx, namespace, "demo")
+		if err != nil || app.Namespace != namespace || app.GitHubURL != want {
+			t.Errorf("Expected slug demo in namespace %s to be %s, got %+v, %v", namespace, want, app, err)
+		}
+	}
+	if app, err := db.GetAppBySlug(ctx, "partner", "demo-example"); err != nil || app.GitHubURL != formerURL {
+		t.Errorf("Expected the second app named demo of a namespace to get another slug, got %+v, %v", app, err)
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// authKeySchema creates the table holding the SIWE key made active by the latest key rotation, so
+// that rotations survive restarts. It holds at most one row.
+const authKeySchema = `
+	CREATE TABLE IF NOT EXISTS active_auth_key (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		address TEXT NOT NULL,
+		rotated_at DATETIME NOT NULL
+	);
+`
+
+// SetActiveAuthKey records the address of the SIWE key made active by a key rotation.
+func (db *DB) SetActiveAuthKey(ctx context.Context, address string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO active_auth_key (id, address, rotated_at) VALUES (1, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET address = excluded.address, rotated_at = excluded.rotated_at
+	`, address, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record active key: %w", err)
+	}
+	return nil
+}
+
+// GetActiveAuthKey returns the address of the SIWE key made active by the latest key rotation, or
+// an empty string if keys were never rotated.
+func (db *DB) GetActiveAuthKey(ctx context.Context) (string, error) {
+	var address string
+	err := db.QueryRowContext(ctx, `SELECT address FROM active_auth_key WHERE id = 1`).Scan(&address)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get active key: %w", err)
+	}
+	return address, nil
+}
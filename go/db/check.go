@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// historyTables lists the tables recording the history of deployments by app and deployment name.
+// The verification log is left out, as it is append-only and outlives the deployments it records.
+var historyTables = []string{
+	"events",
+	"provenance",
+	"verification_jobs",
+}
+
+// CheckReport is the result of a database integrity check.
+type CheckReport struct {
+	IntegrityErrors      []string              // Problems reported by PRAGMA integrity_check.
+	ForeignKeyViolations []ForeignKeyViolation // Rows referencing missing parent rows.
+	Orphans              map[string]int64      // Number of rows whose parent row or deployment is missing, by table.
+}
+
+// ForeignKeyViolation is a row reported by PRAGMA foreign_key_check.
+type ForeignKeyViolation struct {
+	Table  string
+	RowID  int64
+	Parent string
+}
+
+// OK reports whether the check found no problems.
+func (r *CheckReport) OK() bool {
+	return len(r.IntegrityErrors) == 0 && len(r.ForeignKeyViolations) == 0 && r.OrphanCount() == 0
+}
+
+// OrphanCount returns the total number of orphaned rows.
+func (r *CheckReport) OrphanCount() int64 {
+	var n int64
+	for _, count := range r.Orphans {
+		n += count
+	}
+	return n
+}
+
+// Check verifies the database file structure, foreign keys, and looks for rows whose parent row,
+// e.g. the app of a deployment, was deleted without them, as happens with foreign keys disabled,
+// and for history of deployments that no longer exist.
+func (db *DB) Check(ctx context.Context) (*CheckReport, error) {
+	report := &CheckReport{Orphans: make(map[string]int64)}
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if msg != "ok" {
+			report.IntegrityErrors = append(report.IntegrityErrors, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+
+	fkRows, err := db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run foreign key check: %w", err)
+	}
+	defer func() {
+		_ = fkRows.Close()
+	}()
+	for fkRows.Next() {
+		var (
+			v     ForeignKeyViolation
+			rowID sql.NullInt64
+			fkID  int64
+		)
+		if err := fkRows.Scan(&v.Table, &rowID, &v.Parent, &fkID); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key check: %w", err)
+		}
+		v.RowID = rowID.Int64
+		report.ForeignKeyViolations = append(report.ForeignKeyViolations, v)
+	}
+	if err := fkRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to run foreign key check: %w", err)
+	}
+
+	orphans, err := db.orphanConditions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orphans {
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", o.table, o.condition) // #nosec G201 -- identifiers from the schema.
+		var count int64
+		if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count orphaned rows in %s: %w", o.table, err)
+		}
+		if count > 0 {
+			report.Orphans[o.table] += count
+		}
+	}
+
+	return report, nil
+}
+
+// Repair rebuilds all indexes, which resolves index corruption reported by the integrity check,
+// and deletes the orphaned rows found by Check, returning the number of deleted rows. Parent
+// tables are repaired before the tables referencing them, so that rows referencing deleted
+// orphans are deleted too. This resolves all foreign key violations.
+func (db *DB) Repair(ctx context.Context) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, "REINDEX"); err != nil {
+		return 0, fmt.Errorf("failed to rebuild indexes: %w", err)
+	}
+
+	orphans, err := db.orphanConditions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, o := range orphans {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s", o.table, o.condition) // #nosec G201 -- identifiers from the schema.
+		result, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete orphaned rows from %s: %w", o.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		deleted += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// orphanCondition matches the orphaned rows of a table.
+type orphanCondition struct {
+	table     string
+	condition string
+}
+
+// orphanConditions returns the conditions matching orphaned rows: rows of a table whose foreign key
+// references a missing row, and rows of history tables whose deployment no longer exists. Tables
+// are ordered parents first, with the history of deployments before the tables referencing it.
+func (db *DB) orphanConditions(ctx context.Context) ([]orphanCondition, error) {
+	tables, err := db.pgTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []orphanCondition
+	for _, table := range historyTables {
+		conditions = append(conditions, orphanCondition{table, fmt.Sprintf(
+			"deployment_name IS NOT NULL AND NOT EXISTS (SELECT 1 FROM deployments d WHERE d.app_id = %[1]s.app_id AND d.deployment_name = %[1]s.deployment_name)",
+			table,
+		)})
+	}
+	for _, table := range tables {
+		keys, err := db.foreignKeys(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			to := key.to
+			if len(to) == 0 {
+				if to, err = db.primaryKey(ctx, key.parent); err != nil {
+					return nil, err
+				}
+			}
+			var notNull, matches []string
+			for i, from := range key.from {
+				notNull = append(notNull, fmt.Sprintf("%s.%s IS NOT NULL", table, from))
+				matches = append(matches, fmt.Sprintf("p.%s = %s.%s", to[i], table, from))
+			}
+			conditions = append(conditions, orphanCondition{table, fmt.Sprintf(
+				"%s AND NOT EXISTS (SELECT 1 FROM %s p WHERE %s)",
+				strings.Join(notNull, " AND "), key.parent, strings.Join(matches, " AND "),
+			)})
+		}
+	}
+	return conditions, nil
+}
+
+// primaryKey returns the primary key columns of a table.
+func (db *DB) primaryKey(ctx context.Context, table string) ([]string, error) {
+	columns, err := db.pgColumns(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	var key []string
+	for _, col := range columns {
+		if col.primaryKey {
+			key = append(key, col.name)
+		}
+	}
+	return key, nil
+}
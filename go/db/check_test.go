@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Test that rows left behind with foreign keys disabled, including rows referencing history rather
+// than apps, and history of removed deployments are found and deleted, while other rows are kept.
+func TestCheckRepair(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	for _, app := range []struct {
+		url        string
+		deployment string
+	}{
+		{"https://github.com/example/kept", "mainnet"},
+		{"https://github.com/example/deleted", "testnet"},
+	} {
+		if err := db.UpsertApp(ctx, models.DefaultNamespace, app.url, "main", false, "", "", nil); err != nil {
+			t.Fatalf("Failed to add app: %v", err)
+		}
+	}
+	for appID, deployment := range map[int64]string{1: "mainnet", 2: "testnet"} {
+		if _, err := db.UpsertDeployment(ctx, appID, deployment, "abc123", string(models.StatusVerified), ""); err != nil {
+			t.Fatalf("Failed to add deployment: %v", err)
+		}
+	}
+	countEvents := func() int64 {
+		t.Helper()
+		var n int64
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events WHERE app_id = 1 AND deployment_name = 'mainnet'`).Scan(&n); err != nil {
+			t.Fatalf("Failed to count events: %v", err)
+		}
+		return n
+	}
+	kept := countEvents()
+	if kept == 0 {
+		t.Fatalf("Expected status events of the kept deployment")
+	}
+
+	report, err := db.Check(ctx)
+	if err != nil || !report.OK() {
+		t.Fatalf("Expected a clean database, got %+v, %v", report, err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	now := time.Now()
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		{query: `PRAGMA foreign_keys = OFF`},
+		{query: `DELETE FROM apps WHERE id = 2`},
+		{query: `INSERT INTO events (id, app_id, deployment_name, new_status, created_at) VALUES (100, 1, 'removed', 'failed', ?)`, args: []any{now}},
+		{query: `INSERT INTO verification_jobs (app_id, deployment_name, status) VALUES (1, 'removed', 'completed')`},
+		{query: `INSERT INTO notification_outbox (event_id, channel, destination, payload, next_attempt_at) VALUES (100, 'email', 'a@example.com', '{}', ?)`, args: []any{now}},
+		{query: `INSERT INTO notification_outbox (event_id, channel, destination, payload, next_attempt_at) VALUES (999, 'email', 'a@example.com', '{}', ?)`, args: []any{now}},
+		{query: `PRAGMA foreign_keys = ON`},
+	} {
+		if _, err := conn.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			t.Fatalf("Failed to seed orphans with %q: %v", stmt.query, err)
+		}
+	}
+	_ = conn.Close()
+
+	report, err = db.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	for _, table := range []string{"deployments", "events", "verification_jobs", "notification_outbox"} {
+		if report.Orphans[table] == 0 {
+			t.Errorf("Expected orphans in %s, got %v", table, report.Orphans)
+		}
+	}
+	if len(report.ForeignKeyViolations) == 0 {
+		t.Errorf("Expected foreign key violations")
+	}
+
+	if _, err := db.Repair(ctx); err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+	report, err = db.Check(ctx)
+	if err != nil || !report.OK() {
+		t.Errorf("Expected a clean database after repair, got %+v, %v", report, err)
+	}
+	var outbox int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notification_outbox`).Scan(&outbox); err != nil || outbox != 0 {
+		t.Errorf("Expected notifications of deleted events to be deleted, got %d, %v", outbox, err)
+	}
+	if n := countEvents(); n != kept {
+		t.Errorf("Expected %d status events of the kept deployment, got %d", kept, n)
+	}
+	if deployments, err := db.GetDeploymentsByAppID(ctx, 1); err != nil || len(deployments) != 1 {
+		t.Errorf("Expected the kept deployment, got %v, %v", deployments, err)
+	}
+}
@@ -2,8 +2,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver.
 )
@@ -47,11 +49,52 @@ func (db *DB) InitSchema() error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS apps (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		github_url TEXT NOT NULL UNIQUE,
+		namespace TEXT NOT NULL DEFAULT 'default',
+		github_url TEXT NOT NULL,
+		slug TEXT,
 		git_ref TEXT NOT NULL,
+		featured INTEGER NOT NULL DEFAULT 0,
+		stars INTEGER,
+		owner_login TEXT,
+		owner_name TEXT,
+		owner_avatar_url TEXT,
+		owner_type TEXT,
+		owner_blog TEXT,
+		owner_email TEXT,
+		owner_verified INTEGER NOT NULL DEFAULT 0,
+		fork_of TEXT,
+		logo_url TEXT,
+		logo_source TEXT,
+		logo BLOB,
+		logo_updated_at DATETIME,
 		rofl_yaml TEXT,
+		manifest_path TEXT,
+		manifest_etag TEXT,
+		manifest_last_modified TEXT,
+		compose_yaml TEXT,
+		compose_yaml_ref TEXT,
+		compose_commit_sha TEXT,
+		readme_excerpt TEXT,
+		readme_commit_sha TEXT,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME,
+		last_error TEXT,
+		attestation_url TEXT,
+		selected_deployments TEXT,
+		probe_checked_at DATETIME,
+		probe_attested_at DATETIME,
+		probe_deployment TEXT,
+		probe_error TEXT,
+		domain_checked_at DATETIME,
+		domain_verified TEXT,
+		domain_method TEXT,
+		domain_error TEXT,
+		repo_status TEXT,
+		repo_status_at DATETIME,
+		moved_to TEXT,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (namespace, github_url)
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_apps_github_url ON apps(github_url);
@@ -61,9 +104,24 @@ func (db *DB) InitSchema() error {
 		app_id INTEGER NOT NULL,
 		deployment_name TEXT NOT NULL,
 		commit_sha TEXT,
+		commit_tag TEXT,
 		status TEXT NOT NULL DEFAULT 'pending',
 		verification_msg TEXT,
+		verification_log TEXT,
+		verification_log_ref TEXT,
+		cli_version TEXT,
+		builder_image TEXT,
 		last_verified DATETIME,
+		verified_since DATETIME,
+		first_verified_at DATETIME,
+		verified_streak INTEGER NOT NULL DEFAULT 0,
+		failed_streak INTEGER NOT NULL DEFAULT 0,
+		live_checked_at DATETIME,
+		live_instances INTEGER,
+		live_unverified_enclaves TEXT,
+		policy_checked_at DATETIME,
+		policy_violations TEXT,
+		verification_report TEXT,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE,
@@ -76,6 +134,7 @@ func (db *DB) InitSchema() error {
 	CREATE TABLE IF NOT EXISTS verification_jobs (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		app_id INTEGER NOT NULL,
+		deployment_name TEXT,
 		status TEXT NOT NULL DEFAULT 'pending',
 		job_id TEXT,
 		result TEXT,
@@ -88,11 +147,385 @@ func (db *DB) InitSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_jobs_app_id ON verification_jobs(app_id);
 	CREATE INDEX IF NOT EXISTS idx_jobs_status ON verification_jobs(status);
 	CREATE INDEX IF NOT EXISTS idx_jobs_job_id ON verification_jobs(job_id);
+
+	CREATE TABLE IF NOT EXISTS image_digests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		image TEXT NOT NULL,
+		digest TEXT NOT NULL,
+		resolved_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE,
+		UNIQUE(app_id, image)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_image_digests_app_id ON image_digests(app_id);
+
+	CREATE TABLE IF NOT EXISTS manifest_versions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		content_hash TEXT NOT NULL,
+		content TEXT NOT NULL,
+		first_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		verified_at DATETIME,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE,
+		UNIQUE(app_id, content_hash)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_manifest_versions_app_id ON manifest_versions(app_id);
+
+	CREATE TABLE IF NOT EXISTS app_leases (
+		app_id INTEGER PRIMARY KEY,
+		claimed_by TEXT NOT NULL,
+		claimed_until DATETIME NOT NULL,
+		last_attempt_at DATETIME,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	if err := db.migrateColumns(); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_apps_namespace ON apps(namespace)`); err != nil {
+		return fmt.Errorf("failed to create namespace index: %w", err)
+	}
+	if err := db.migrateAppURLs(); err != nil {
+		return err
+	}
+
+	// Deployments verified before streaks were tracked start their streak at the last verification.
+	if _, err := db.Exec(`UPDATE deployments SET verified_since = last_verified WHERE status = 'verified' AND verified_since IS NULL`); err != nil {
+		return fmt.Errorf("failed to backfill verified streaks: %w", err)
+	}
+	if _, err := db.Exec(`
+		UPDATE deployments SET first_verified_at = COALESCE(verified_since, last_verified)
+		WHERE status IN ('verified', 'stale') AND first_verified_at IS NULL
+	`); err != nil {
+		return fmt.Errorf("failed to backfill first verifications: %w", err)
+	}
+	if _, err := db.Exec(`UPDATE deployments SET verified_streak = 1 WHERE status = 'verified' AND verified_streak = 0`); err != nil {
+		return fmt.Errorf("failed to backfill verified streaks: %w", err)
+	}
+
+	if _, err := db.Exec(eventLogSchema); err != nil {
+		return fmt.Errorf("failed to create verification log: %w", err)
+	}
+
+	if _, err := db.Exec(statusEventSchema); err != nil {
+		return fmt.Errorf("failed to create status events: %w", err)
+	}
+
+	if _, err := db.Exec(dependencySchema); err != nil {
+		return fmt.Errorf("failed to create dependency reports: %w", err)
+	}
+
+	if err := db.migrateRegistryErrors(); err != nil {
+		return err
+	}
+	if _, err := db.Exec(registrySchema); err != nil {
+		return fmt.Errorf("failed to create registry errors: %w", err)
+	}
+
+	if err := db.migrateMaintainerSessions(); err != nil {
+		return err
+	}
+	if _, err := db.Exec(maintainerSchema); err != nil {
+		return fmt.Errorf("failed to create maintainer tables: %w", err)
+	}
+
+	if _, err := db.Exec(heartbeatSchema); err != nil {
+		return fmt.Errorf("failed to create worker heartbeats: %w", err)
+	}
+
+	if err := db.migrateAliases(); err != nil {
+		return err
+	}
+	if _, err := db.Exec(aliasSchema); err != nil {
+		return fmt.Errorf("failed to create app aliases: %w", err)
+	}
+
+	if _, err := db.Exec(subscriptionSchema); err != nil {
+		return fmt.Errorf("failed to create subscriptions: %w", err)
+	}
+	if err := db.migrateSubscriptions(); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(outboxSchema); err != nil {
+		return fmt.Errorf("failed to create notification outbox: %w", err)
+	}
+
+	if _, err := db.Exec(provenanceSchema); err != nil {
+		return fmt.Errorf("failed to create provenance: %w", err)
+	}
+
+	if _, err := db.Exec(adminSchema); err != nil {
+		return fmt.Errorf("failed to create admin tables: %w", err)
+	}
+
+	if _, err := db.Exec(authKeySchema); err != nil {
+		return fmt.Errorf("failed to create active auth key: %w", err)
+	}
+
+	if _, err := db.Exec(slugSchema); err != nil {
+		return fmt.Errorf("failed to create slug index: %w", err)
+	}
+	if err := db.backfillSlugs(context.Background()); err != nil {
+		return fmt.Errorf("failed to assign slugs: %w", err)
+	}
+
+	if _, err := db.Exec(searchSchema); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	if err := db.syncSearchIndex(context.Background()); err != nil {
+		return fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	return nil
+}
+
+// columnMigration describes a column added after the initial schema.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists columns that must be added to databases created by older versions.
+// New databases already get these columns from the CREATE TABLE statements above.
+var columnMigrations = []columnMigration{
+	{"apps", "compose_yaml", "TEXT"},
+	{"apps", "compose_commit_sha", "TEXT"},
+	{"apps", "manifest_path", "TEXT"},
+	{"apps", "manifest_etag", "TEXT"},
+	{"apps", "manifest_last_modified", "TEXT"},
+	{"apps", "consecutive_failures", "INTEGER NOT NULL DEFAULT 0"},
+	{"apps", "next_attempt_at", "DATETIME"},
+	{"apps", "last_error", "TEXT"},
+	{"apps", "compose_yaml_ref", "TEXT"},
+	{"apps", "readme_excerpt", "TEXT"},
+	{"apps", "readme_commit_sha", "TEXT"},
+	{"apps", "slug", "TEXT"},
+	{"apps", "featured", "INTEGER NOT NULL DEFAULT 0"},
+	{"apps", "stars", "INTEGER"},
+	{"apps", "owner_login", "TEXT"},
+	{"apps", "owner_name", "TEXT"},
+	{"apps", "owner_avatar_url", "TEXT"},
+	{"apps", "owner_type", "TEXT"},
+	{"apps", "owner_blog", "TEXT"},
+	{"apps", "owner_email", "TEXT"},
+	{"apps", "owner_verified", "INTEGER NOT NULL DEFAULT 0"},
+	{"apps", "fork_of", "TEXT"},
+	{"apps", "logo_url", "TEXT"},
+	{"apps", "logo_source", "TEXT"},
+	{"apps", "logo", "BLOB"},
+	{"apps", "logo_updated_at", "DATETIME"},
+	{"apps", "attestation_url", "TEXT"},
+	{"apps", "selected_deployments", "TEXT"},
+	{"apps", "probe_checked_at", "DATETIME"},
+	{"apps", "probe_attested_at", "DATETIME"},
+	{"apps", "probe_deployment", "TEXT"},
+	{"apps", "probe_error", "TEXT"},
+	{"apps", "domain_checked_at", "DATETIME"},
+	{"apps", "domain_verified", "TEXT"},
+	{"apps", "domain_method", "TEXT"},
+	{"apps", "domain_error", "TEXT"},
+	{"apps", "namespace", "TEXT NOT NULL DEFAULT 'default'"},
+	{"apps", "moved_to", "TEXT"},
+	{"apps", "repo_status", "TEXT"},
+	{"apps", "repo_status_at", "DATETIME"},
+	{"verification_jobs", "deployment_name", "TEXT"},
+	{"deployments", "verification_log", "TEXT"},
+	{"deployments", "verification_log_ref", "TEXT"},
+	{"deployments", "cli_version", "TEXT"},
+	{"deployments", "builder_image", "TEXT"},
+	{"deployments", "verified_since", "DATETIME"},
+	{"deployments", "first_verified_at", "DATETIME"},
+	{"deployments", "verified_streak", "INTEGER NOT NULL DEFAULT 0"},
+	{"deployments", "live_checked_at", "DATETIME"},
+	{"deployments", "live_instances", "INTEGER"},
+	{"deployments", "live_unverified_enclaves", "TEXT"},
+	{"deployments", "policy_checked_at", "DATETIME"},
+	{"deployments", "policy_violations", "TEXT"},
+	{"deployments", "commit_tag", "TEXT"},
+	{"deployments", "verification_report", "TEXT"},
+	{"deployments", "failed_streak", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrateColumns adds any missing columns from columnMigrations.
+func (db *DB) migrateColumns() error {
+	for _, m := range columnMigrations {
+		exists, err := db.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition) // #nosec G201 -- constant identifiers.
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	return nil
+}
+
+// migrateAppURLs rebuilds the apps table of older versions, whose GitHub URLs were unique across
+// namespaces, so that every namespace can list any repository. SQLite cannot drop a column
+// constraint, so the table is copied into one created from its definition without it. Foreign keys
+// are off while the table is replaced, so that the rows referencing apps are kept.
+func (db *DB) migrateAppURLs() error {
+	const globalURL = "github_url TEXT NOT NULL UNIQUE"
+
+	ctx := context.Background()
+	var ddl string
+	if err := db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'apps'`).Scan(&ddl); err != nil {
+		return fmt.Errorf("failed to inspect apps: %w", err)
+	}
+	if !strings.Contains(ddl, globalURL) {
+		return nil
+	}
+	name := strings.Index(ddl, "apps")
+	end := strings.LastIndex(ddl, ")")
+	if name < 0 || end < name {
+		return fmt.Errorf("failed to migrate apps: unexpected definition %q", ddl)
+	}
+	ddl = "CREATE TABLE apps_new" + strings.Replace(ddl[name+len("apps"):end], globalURL, "github_url TEXT NOT NULL", 1) + ",\n\tUNIQUE (namespace, github_url)\n)"
+
+	rows, err := db.QueryContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = 'apps' AND sql IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes of apps: %w", err)
+	}
+	var indexes []string
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan index of apps: %w", err)
+		}
+		indexes = append(indexes, index)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list indexes of apps: %w", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	statements := append([]string{
+		ddl,
+		`INSERT INTO apps_new SELECT * FROM apps`,
+		`DROP TABLE apps`,
+		`ALTER TABLE apps_new RENAME TO apps`,
+	}, indexes...)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate apps: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
+
+// columnExists checks whether a table has the given column.
+func (db *DB) columnExists(table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table)) // #nosec G201 -- constant identifier.
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// foreignKey is a foreign key of a table, as reported by PRAGMA foreign_key_list.
+type foreignKey struct {
+	parent   string   // Referenced table.
+	from     []string // Columns of the table.
+	to       []string // Referenced columns, empty if the key references the primary key.
+	onDelete string
+}
+
+// foreignKeys returns the foreign keys of a table.
+func (db *DB) foreignKeys(ctx context.Context, table string) ([]foreignKey, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA foreign_key_list(%s)", table)) // #nosec G201 -- table name from sqlite_master or a constant.
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys of %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var keys []foreignKey
+	ids := make(map[int]int)
+	for rows.Next() {
+		var (
+			id, seq            int
+			parent, from       string
+			to                 sql.NullString
+			onUpdate, onDelete string
+			match              string
+		)
+		if err := rows.Scan(&id, &seq, &parent, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key of %s: %w", table, err)
+		}
+		i, ok := ids[id]
+		if !ok {
+			i = len(keys)
+			ids[id] = i
+			keys = append(keys, foreignKey{parent: parent, onDelete: onDelete})
+		}
+		keys[i].from = append(keys[i].from, from)
+		if to.Valid {
+			keys[i].to = append(keys[i].to, to.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys of %s: %w", table, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// dependencySchema creates the table of dependency lockfile summaries, one per app at the commit
+// it was last verified at.
+const dependencySchema = `
+	CREATE TABLE IF NOT EXISTS dependency_reports (
+		app_id INTEGER PRIMARY KEY,
+		commit_sha TEXT NOT NULL,
+		lockfiles TEXT NOT NULL,
+		checked_at DATETIME NOT NULL,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
+`
+
+// UpsertDependencyReport stores the lockfile summaries of an app at a commit, replacing those of
+// an earlier commit.
+func (db *DB) UpsertDependencyReport(ctx context.Context, appID int64, commitSHA string, lockfiles []models.Lockfile) error {
+	if lockfiles == nil {
+		lockfiles = []models.Lockfile{}
+	}
+	data, err := json.Marshal(lockfiles)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfiles: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO dependency_reports (app_id, commit_sha, lockfiles, checked_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(app_id) DO UPDATE SET
+			commit_sha = excluded.commit_sha,
+			lockfiles = excluded.lockfiles,
+			checked_at = excluded.checked_at
+	`, appID, commitSHA, string(data), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store dependency report: %w", err)
+	}
+	return nil
+}
+
+// GetDependencyReport returns the lockfile summaries of an app, or nil if they were never checked.
+func (db *DB) GetDependencyReport(ctx context.Context, appID int64) (*models.DependencyReport, error) {
+	var (
+		report = &models.DependencyReport{AppID: appID}
+		data   string
+	)
+	err := db.QueryRowContext(ctx, `
+		SELECT commit_sha, lockfiles, checked_at FROM dependency_reports WHERE app_id = ?
+	`, appID).Scan(&report.CommitSHA, &data, &report.CheckedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get dependency report: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(data), &report.Lockfiles); err != nil {
+		return nil, fmt.Errorf("failed to decode lockfiles: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,545 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// DumpVersion is the format version of database dumps.
+const DumpVersion = 1
+
+// ConflictStrategy determines how imported apps that already exist are handled.
+type ConflictStrategy string
+
+// Conflict strategies.
+const (
+	ConflictSkip      ConflictStrategy = "skip"      // Keep the existing app untouched.
+	ConflictOverwrite ConflictStrategy = "overwrite" // Replace the existing app with the imported one.
+	ConflictNewer     ConflictStrategy = "newer"     // Replace the existing app only if the imported one was updated later.
+)
+
+// ParseConflictStrategy parses a conflict strategy name.
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(s); strategy {
+	case ConflictSkip, ConflictOverwrite, ConflictNewer:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict strategy %q (expected skip, overwrite or newer)", s)
+	}
+}
+
+// Dump is a portable snapshot of the registry database. Apps are keyed by GitHub URL,
+// so dumps can be imported into instances with different row IDs.
+type Dump struct {
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Apps       []*DumpApp `json:"apps"`
+}
+
+// DumpApp is an app together with its verification history.
+// Failure tracking is instance specific and not included.
+type DumpApp struct {
+	Namespace            string    `json:"namespace,omitempty"` // Empty for the default namespace.
+	GitHubURL            string    `json:"github_url"`
+	GitRef               string    `json:"git_ref"`
+	Featured             bool      `json:"featured,omitempty"`
+	RoflYAML             *string   `json:"rofl_yaml,omitempty"`
+	ManifestPath         *string   `json:"manifest_path,omitempty"`
+	ManifestETag         *string   `json:"manifest_etag,omitempty"`
+	ManifestLastModified *string   `json:"manifest_last_modified,omitempty"`
+	ComposeYAML          *string   `json:"compose_yaml,omitempty"`
+	ComposeYAMLRef       *string   `json:"compose_yaml_ref,omitempty"`
+	ComposeCommitSHA     *string   `json:"compose_commit_sha,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+
+	Deployments      []*DumpDeployment      `json:"deployments,omitempty"`
+	ImageDigests     []*DumpImageDigest     `json:"image_digests,omitempty"`
+	ManifestVersions []*DumpManifestVersion `json:"manifest_versions,omitempty"`
+	StatusEvents     []*DumpStatusEvent     `json:"status_events,omitempty"`
+	VerificationJobs []*DumpVerificationJob `json:"verification_jobs,omitempty"`
+}
+
+// DumpDeployment is the verification state of a single deployment.
+type DumpDeployment struct {
+	Name            string     `json:"name"`
+	CommitSHA       *string    `json:"commit_sha,omitempty"`
+	CommitTag       *string    `json:"commit_tag,omitempty"`
+	Status          string     `json:"status"`
+	VerificationMsg *string    `json:"verification_msg,omitempty"`
+	CLIVersion      *string    `json:"cli_version,omitempty"`
+	BuilderImage    *string    `json:"builder_image,omitempty"`
+	LastVerified    *time.Time `json:"last_verified,omitempty"`
+	VerifiedSince   *time.Time `json:"verified_since,omitempty"`
+	FirstVerifiedAt *time.Time `json:"first_verified_at,omitempty"`
+	VerifiedStreak  int64      `json:"verified_streak,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// DumpImageDigest is a resolved image digest.
+type DumpImageDigest struct {
+	Image      string    `json:"image"`
+	Digest     string    `json:"digest"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// DumpManifestVersion is a stored manifest version.
+type DumpManifestVersion struct {
+	Content     string     `json:"content"`
+	FirstSeenAt time.Time  `json:"first_seen_at"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+}
+
+// DumpStatusEvent is a status change of a deployment.
+type DumpStatusEvent struct {
+	Deployment string    `json:"deployment"`
+	OldStatus  *string   `json:"old_status,omitempty"`
+	NewStatus  string    `json:"new_status"`
+	CommitSHA  *string   `json:"commit_sha,omitempty"`
+	Details    *string   `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DumpVerificationJob is a verification task finished by the backend.
+type DumpVerificationJob struct {
+	Deployment  *string    `json:"deployment,omitempty"`
+	Status      string     `json:"status"`
+	JobID       *string    `json:"job_id,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ImportStats summarizes the result of an import.
+type ImportStats struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// Export creates a dump of all apps and their history.
+func (db *DB) Export(ctx context.Context) (*Dump, error) {
+	apps, err := db.GetAllApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &Dump{
+		Version:    DumpVersion,
+		ExportedAt: time.Now().UTC(),
+		Apps:       make([]*DumpApp, 0, len(apps)),
+	}
+	for _, app := range apps {
+		dumpApp := &DumpApp{
+			Namespace:            dumpNamespace(app.Namespace),
+			GitHubURL:            app.GitHubURL,
+			GitRef:               app.GitRef,
+			Featured:             app.Featured,
+			RoflYAML:             stringPtr(app.RoflYAML),
+			ManifestPath:         stringPtr(app.ManifestPath),
+			ManifestETag:         stringPtr(app.ManifestETag),
+			ManifestLastModified: stringPtr(app.ManifestLastModified),
+			ComposeYAML:          stringPtr(app.ComposeYAML),
+			ComposeYAMLRef:       stringPtr(app.ComposeYAMLRef),
+			ComposeCommitSHA:     stringPtr(app.ComposeCommitSHA),
+			CreatedAt:            app.CreatedAt,
+			UpdatedAt:            app.UpdatedAt,
+		}
+
+		deployments, err := db.GetDeploymentsByAppID(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, deployment := range deployments {
+			dumpApp.Deployments = append(dumpApp.Deployments, &DumpDeployment{
+				Name:            deployment.DeploymentName,
+				CommitSHA:       stringPtr(deployment.CommitSHA),
+				CommitTag:       stringPtr(deployment.CommitTag),
+				Status:          string(deployment.Status),
+				VerificationMsg: stringPtr(deployment.VerificationMsg),
+				CLIVersion:      stringPtr(deployment.CLIVersion),
+				BuilderImage:    stringPtr(deployment.BuilderImage),
+				LastVerified:    timePtr(deployment.LastVerified),
+				VerifiedSince:   timePtr(deployment.VerifiedSince),
+				FirstVerifiedAt: timePtr(deployment.FirstVerifiedAt),
+				VerifiedStreak:  deployment.VerifiedStreak,
+				CreatedAt:       deployment.CreatedAt,
+				UpdatedAt:       deployment.UpdatedAt,
+			})
+		}
+
+		digests, err := db.GetImageDigestsByAppID(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, digest := range digests {
+			dumpApp.ImageDigests = append(dumpApp.ImageDigests, &DumpImageDigest{
+				Image:      digest.Image,
+				Digest:     digest.Digest,
+				ResolvedAt: digest.ResolvedAt,
+			})
+		}
+
+		versions, err := db.GetManifestVersions(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			dumpApp.ManifestVersions = append(dumpApp.ManifestVersions, &DumpManifestVersion{
+				Content:     version.Content,
+				FirstSeenAt: version.FirstSeenAt,
+				VerifiedAt:  timePtr(version.VerifiedAt),
+			})
+		}
+
+		if dumpApp.StatusEvents, err = db.exportStatusEvents(ctx, app.ID); err != nil {
+			return nil, err
+		}
+		if dumpApp.VerificationJobs, err = db.exportVerificationJobs(ctx, app.ID); err != nil {
+			return nil, err
+		}
+
+		dump.Apps = append(dump.Apps, dumpApp)
+	}
+
+	return dump, nil
+}
+
+// exportStatusEvents returns the status changes of the deployments of an app, oldest first.
+func (db *DB) exportStatusEvents(ctx context.Context, appID int64) ([]*DumpStatusEvent, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT deployment_name, old_status, new_status, commit_sha, details, created_at
+		FROM events
+		WHERE app_id = ?
+		ORDER BY id
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []*DumpStatusEvent
+	for rows.Next() {
+		var (
+			event                         DumpStatusEvent
+			oldStatus, commitSHA, details sql.NullString
+		)
+		if err := rows.Scan(&event.Deployment, &oldStatus, &event.NewStatus, &commitSHA, &details, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status event: %w", err)
+		}
+		event.OldStatus, event.CommitSHA, event.Details = stringPtr(oldStatus), stringPtr(commitSHA), stringPtr(details)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return events, nil
+}
+
+// exportVerificationJobs returns the verification tasks of an app finished by the backend, oldest
+// first.
+func (db *DB) exportVerificationJobs(ctx context.Context, appID int64) ([]*DumpVerificationJob, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT deployment_name, status, job_id, started_at, completed_at
+		FROM verification_jobs
+		WHERE app_id = ?
+		ORDER BY id
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query verification jobs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var jobs []*DumpVerificationJob
+	for rows.Next() {
+		var (
+			job                    DumpVerificationJob
+			deployment, jobID      sql.NullString
+			startedAt, completedAt sql.NullTime
+		)
+		if err := rows.Scan(&deployment, &job.Status, &jobID, &startedAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan verification job: %w", err)
+		}
+		job.Deployment, job.JobID = stringPtr(deployment), stringPtr(jobID)
+		job.StartedAt, job.CompletedAt = timePtr(startedAt), timePtr(completedAt)
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return jobs, nil
+}
+
+// Import loads a dump into the database in a single transaction.
+// Apps that already exist are handled according to strategy.
+func (db *DB) Import(ctx context.Context, dump *Dump, strategy ConflictStrategy) (*ImportStats, error) {
+	if dump.Version != DumpVersion {
+		return nil, fmt.Errorf("unsupported dump version %d (expected %d)", dump.Version, DumpVersion)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stats := &ImportStats{}
+	for _, app := range dump.Apps {
+		if app.GitHubURL == "" {
+			return nil, fmt.Errorf("dump contains an app without github_url")
+		}
+
+		var appID int64
+		var updatedAt time.Time
+		err := tx.QueryRowContext(ctx, `SELECT id, updated_at FROM apps WHERE namespace = ? AND github_url = ?`, importNamespace(app.Namespace), app.GitHubURL).Scan(&appID, &updatedAt)
+		switch {
+		case err == sql.ErrNoRows:
+			if appID, err = importApp(ctx, tx, app); err != nil {
+				return nil, err
+			}
+			stats.Created++
+		case err != nil:
+			return nil, fmt.Errorf("failed to look up app %s: %w", app.GitHubURL, err)
+		case strategy == ConflictSkip, strategy == ConflictNewer && !app.UpdatedAt.After(updatedAt):
+			// The existing app and its deployments are kept, but its manifest history is still merged.
+			if err := mergeManifestVersions(ctx, tx, appID, app); err != nil {
+				return nil, fmt.Errorf("failed to import history of %s: %w", app.GitHubURL, err)
+			}
+			stats.Skipped++
+			continue
+		default:
+			if err := overwriteApp(ctx, tx, appID, app); err != nil {
+				return nil, err
+			}
+			stats.Updated++
+		}
+
+		if err := assignSlug(ctx, tx, appID); err != nil {
+			return nil, fmt.Errorf("failed to assign slug of %s: %w", app.GitHubURL, err)
+		}
+		if err := indexApp(ctx, tx, appID); err != nil {
+			return nil, fmt.Errorf("failed to index %s: %w", app.GitHubURL, err)
+		}
+		if err := importAppHistory(ctx, tx, appID, app); err != nil {
+			return nil, fmt.Errorf("failed to import history of %s: %w", app.GitHubURL, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return stats, nil
+}
+
+// dumpNamespace returns the namespace of an app as written to dumps, omitting the default one.
+func dumpNamespace(namespace string) string {
+	if namespace == models.DefaultNamespace {
+		return ""
+	}
+	return namespace
+}
+
+// importNamespace returns the namespace of an app read from a dump.
+func importNamespace(namespace string) string {
+	if namespace == "" {
+		return models.DefaultNamespace
+	}
+	return namespace
+}
+
+// importApp inserts a new app from a dump and returns its ID.
+func importApp(ctx context.Context, tx *sql.Tx, app *DumpApp) (int64, error) {
+	query := `
+		INSERT INTO apps (
+			namespace, github_url, git_ref, featured, rofl_yaml,
+			manifest_path, manifest_etag, manifest_last_modified,
+			compose_yaml, compose_yaml_ref, compose_commit_sha,
+			created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := tx.ExecContext(ctx, query,
+		importNamespace(app.Namespace), app.GitHubURL, app.GitRef, app.Featured, app.RoflYAML,
+		app.ManifestPath, app.ManifestETag, app.ManifestLastModified,
+		app.ComposeYAML, app.ComposeYAMLRef, app.ComposeCommitSHA,
+		app.CreatedAt, app.UpdatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert app %s: %w", app.GitHubURL, err)
+	}
+
+	return res.LastInsertId()
+}
+
+// overwriteApp replaces the fields of an existing app with those from a dump.
+func overwriteApp(ctx context.Context, tx *sql.Tx, id int64, app *DumpApp) error {
+	query := `
+		UPDATE apps
+		SET namespace = ?, git_ref = ?, featured = ?, rofl_yaml = ?,
+			manifest_path = ?, manifest_etag = ?, manifest_last_modified = ?,
+			compose_yaml = ?, compose_yaml_ref = ?, compose_commit_sha = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		importNamespace(app.Namespace), app.GitRef, app.Featured, app.RoflYAML,
+		app.ManifestPath, app.ManifestETag, app.ManifestLastModified,
+		app.ComposeYAML, app.ComposeYAMLRef, app.ComposeCommitSHA,
+		app.UpdatedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update app %s: %w", app.GitHubURL, err)
+	}
+
+	return nil
+}
+
+// importAppHistory upserts the deployments and image digests of an imported app, and merges its
+// manifest versions, status events, and verification jobs.
+func importAppHistory(ctx context.Context, tx *sql.Tx, appID int64, app *DumpApp) error {
+	for _, deployment := range app.Deployments {
+		query := `
+			INSERT INTO deployments (app_id, deployment_name, commit_sha, commit_tag, status, verification_msg, cli_version, builder_image, last_verified, verified_since, first_verified_at, verified_streak, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(app_id, deployment_name) DO UPDATE SET
+				commit_sha = excluded.commit_sha,
+				commit_tag = excluded.commit_tag,
+				status = excluded.status,
+				verification_msg = excluded.verification_msg,
+				cli_version = excluded.cli_version,
+				builder_image = excluded.builder_image,
+				last_verified = excluded.last_verified,
+				verified_since = excluded.verified_since,
+				first_verified_at = excluded.first_verified_at,
+				verified_streak = excluded.verified_streak,
+				updated_at = excluded.updated_at
+		`
+		_, err := tx.ExecContext(ctx, query,
+			appID, deployment.Name, deployment.CommitSHA, deployment.CommitTag, deployment.Status, deployment.VerificationMsg,
+			deployment.CLIVersion, deployment.BuilderImage, deployment.LastVerified, deployment.VerifiedSince, deployment.FirstVerifiedAt, deployment.VerifiedStreak, deployment.CreatedAt, deployment.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upsert deployment %s: %w", deployment.Name, err)
+		}
+	}
+
+	for _, digest := range app.ImageDigests {
+		query := `
+			INSERT INTO image_digests (app_id, image, digest, resolved_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(app_id, image) DO UPDATE SET
+				digest = excluded.digest,
+				resolved_at = excluded.resolved_at
+		`
+		if _, err := tx.ExecContext(ctx, query, appID, digest.Image, digest.Digest, digest.ResolvedAt); err != nil {
+			return fmt.Errorf("failed to upsert image digest %s: %w", digest.Image, err)
+		}
+	}
+
+	if err := mergeStatusEvents(ctx, tx, appID, app); err != nil {
+		return err
+	}
+	if err := mergeVerificationJobs(ctx, tx, appID, app); err != nil {
+		return err
+	}
+	return mergeManifestVersions(ctx, tx, appID, app)
+}
+
+// mergeStatusEvents adds the status events of an imported app that are not stored yet. They are
+// marked notified, so that importing history does not notify about it again.
+func mergeStatusEvents(ctx context.Context, tx *sql.Tx, appID int64, app *DumpApp) error {
+	now := time.Now()
+	for _, event := range app.StatusEvents {
+		query := `
+			INSERT INTO events (app_id, deployment_name, old_status, new_status, commit_sha, details, notified_at, created_at)
+			SELECT ?, ?, ?, ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM events
+				WHERE app_id = ? AND deployment_name = ? AND new_status = ? AND created_at = ?
+			)
+		`
+		_, err := tx.ExecContext(ctx, query,
+			appID, event.Deployment, event.OldStatus, event.NewStatus, event.CommitSHA, event.Details, now, event.CreatedAt,
+			appID, event.Deployment, event.NewStatus, event.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to merge status event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeVerificationJobs adds the verification jobs of an imported app that are not stored yet.
+func mergeVerificationJobs(ctx context.Context, tx *sql.Tx, appID int64, app *DumpApp) error {
+	for _, job := range app.VerificationJobs {
+		query := `
+			INSERT INTO verification_jobs (app_id, deployment_name, status, job_id, started_at, completed_at)
+			SELECT ?, ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM verification_jobs
+				WHERE app_id = ? AND job_id IS ? AND completed_at IS ?
+			)
+		`
+		_, err := tx.ExecContext(ctx, query,
+			appID, job.Deployment, job.Status, job.JobID, job.StartedAt, job.CompletedAt,
+			appID, job.JobID, job.CompletedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to merge verification job: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeManifestVersions merges the manifest versions of an imported app into those stored. Versions
+// are content addressed, so they are merged rather than replaced.
+func mergeManifestVersions(ctx context.Context, tx *sql.Tx, appID int64, app *DumpApp) error {
+	for _, version := range app.ManifestVersions {
+		query := `
+			INSERT INTO manifest_versions (app_id, content_hash, content, first_seen_at, verified_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(app_id, content_hash) DO UPDATE SET
+				first_seen_at = MIN(first_seen_at, excluded.first_seen_at),
+				verified_at = MAX(COALESCE(verified_at, excluded.verified_at), COALESCE(excluded.verified_at, verified_at))
+		`
+		_, err := tx.ExecContext(ctx, query,
+			appID, manifestHash(version.Content), version.Content, version.FirstSeenAt, version.VerifiedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to merge manifest version: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// stringPtr converts a nullable string to a pointer, nil if not set.
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// timePtr converts a nullable time to a pointer, nil if not set.
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
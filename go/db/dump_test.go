@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Test that an exported app imported over an existing one is kept or replaced according to the
+// conflict strategy, while its manifest history is merged either way.
+func TestImportConflicts(t *testing.T) {
+	ctx := context.Background()
+	const githubURL = "https://github.com/example/app"
+
+	// seed adds the app with a deployment of a status and a manifest version.
+	seed := func(db *DB, gitRef, status, manifest string) {
+		t.Helper()
+		if err := db.UpsertApp(ctx, "default", githubURL, gitRef, false, "", "", []string{"mainnet"}); err != nil {
+			t.Fatalf("Failed to add app: %v", err)
+		}
+		if _, err := db.UpsertDeployment(ctx, 1, "mainnet", "abc123", status, ""); err != nil {
+			t.Fatalf("Failed to add deployment: %v", err)
+		}
+		if err := db.RecordManifestVersion(ctx, 1, manifest); err != nil {
+			t.Fatalf("Failed to add manifest version: %v", err)
+		}
+	}
+
+	source := newTestDB(t)
+	seed(source, "main", string(models.StatusVerified), "name: exported")
+	dump, err := source.Export(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		strategy  ConflictStrategy
+		updatedAt time.Time // Of the dumped app, if set.
+		replaced  bool
+	}{
+		{name: "skip", strategy: ConflictSkip},
+		{name: "overwrite", strategy: ConflictOverwrite, replaced: true},
+		{name: "newer with an older dump", strategy: ConflictNewer, updatedAt: time.Now().Add(-time.Hour)},
+		{name: "newer with a newer dump", strategy: ConflictNewer, updatedAt: time.Now().Add(time.Hour), replaced: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			target := newTestDB(t)
+			seed(target, "develop", string(models.StatusFailed), "name: existing")
+			if !tc.updatedAt.IsZero() {
+				dump.Apps[0].UpdatedAt = tc.updatedAt
+			}
+
+			stats, err := target.Import(ctx, dump, tc.strategy)
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+			if tc.replaced && stats.Updated != 1 || !tc.replaced && stats.Skipped != 1 {
+				t.Errorf("Unexpected stats %+v", stats)
+			}
+
+			app, err := target.GetAppByURL(ctx, models.DefaultNamespace, githubURL)
+			if err != nil {
+				t.Fatalf("Failed to get app: %v", err)
+			}
+			deployments, err := target.GetDeploymentsByAppID(ctx, app.ID)
+			if err != nil || len(deployments) != 1 {
+				t.Fatalf("Failed to get deployments: %v, %v", deployments, err)
+			}
+			wantRef, wantStatus := "develop", models.StatusFailed
+			if tc.replaced {
+				wantRef, wantStatus = "main", models.StatusVerified
+			}
+			if app.GitRef != wantRef || deployments[0].Status != wantStatus {
+				t.Errorf("Expected git ref %s and status %s, got %s and %s", wantRef, wantStatus, app.GitRef, deployments[0].Status)
+			}
+
+			versions, err := target.GetManifestVersions(ctx, app.ID)
+			if err != nil {
+				t.Fatalf("Failed to get manifest versions: %v", err)
+			}
+			if len(versions) != 2 {
+				t.Errorf("Expected the manifest histories to be merged, got %d versions", len(versions))
+			}
+		})
+	}
+}
+
+// Test that the status events and verification jobs of an app are carried over, once however
+// often a dump is imported, without notifying about the events again.
+func TestImportHistory(t *testing.T) {
+	ctx := context.Background()
+	source := newTestDB(t)
+	if err := source.UpsertApp(ctx, models.DefaultNamespace, "https://github.com/example/app", "main", false, "", "", nil); err != nil {
+		t.Fatalf("Failed to add app: %v", err)
+	}
+	for _, status := range []models.VerificationStatus{models.StatusFailed, models.StatusVerified} {
+		if _, err := source.UpsertDeployment(ctx, 1, "mainnet", "abc123", string(status), ""); err != nil {
+			t.Fatalf("Failed to add deployment: %v", err)
+		}
+	}
+	completedAt := time.Now()
+	if err := source.RecordVerificationJob(ctx, 1, "mainnet", "task", string(models.StatusVerified), completedAt.Add(-time.Minute), completedAt); err != nil {
+		t.Fatalf("Failed to record verification job: %v", err)
+	}
+	dump, err := source.Export(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(dump.Apps) != 1 || len(dump.Apps[0].StatusEvents) != 2 || len(dump.Apps[0].VerificationJobs) != 1 {
+		t.Fatalf("Expected the history in the dump, got %+v", dump.Apps)
+	}
+
+	target := newTestDB(t)
+	for range 2 {
+		if _, err := target.Import(ctx, dump, ConflictOverwrite); err != nil {
+			t.Fatalf("Failed to import: %v", err)
+		}
+	}
+	for query, want := range map[string]int64{
+		`SELECT COUNT(*) FROM events`:                                  2,
+		`SELECT COUNT(*) FROM events WHERE notified_at IS NULL`:        0,
+		`SELECT COUNT(*) FROM verification_jobs WHERE job_id = 'task'`: 1,
+	} {
+		var n int64
+		if err := target.QueryRowContext(ctx, query).Scan(&n); err != nil || n != want {
+			t.Errorf("Expected %d from %q, got %d, %v", want, query, n, err)
+		}
+	}
+}
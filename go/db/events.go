@@ -0,0 +1,324 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/merkle"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// eventLogSchema creates the append-only log of verification events. Entries are kept when their
+// app is removed, and triggers reject updates and deletes, so rewriting history requires
+// tampering with the database file and changes the published Merkle root.
+const eventLogSchema = `
+	CREATE TABLE IF NOT EXISTS verification_events (
+		leaf_index INTEGER PRIMARY KEY,
+		app_id INTEGER NOT NULL,
+		deployment_name TEXT NOT NULL,
+		entry TEXT NOT NULL,
+		leaf_hash TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_verification_events_deployment ON verification_events(app_id, deployment_name, leaf_index);
+
+	CREATE TRIGGER IF NOT EXISTS verification_events_no_update BEFORE UPDATE ON verification_events
+	BEGIN
+		SELECT RAISE(ABORT, 'verification events are append-only');
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS verification_events_no_delete BEFORE DELETE ON verification_events
+	BEGIN
+		SELECT RAISE(ABORT, 'verification events are append-only');
+	END;
+
+	CREATE TABLE IF NOT EXISTS log_anchors (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		size INTEGER NOT NULL,
+		root_hash TEXT NOT NULL,
+		chain_id INTEGER NOT NULL,
+		contract TEXT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		anchored_at DATETIME NOT NULL
+	);
+`
+
+// eventEntry is the content of a verification event, serialized as JSON and hashed into the log.
+type eventEntry struct {
+	AppID          int64     `json:"app_id"`
+	GitHubURL      string    `json:"github_url"`
+	Deployment     string    `json:"deployment"`
+	Status         string    `json:"status"`
+	CommitSHA      string    `json:"commit_sha,omitempty"`
+	ManifestSHA256 string    `json:"manifest_sha256,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// eventQueryer is implemented by both *sql.DB and *sql.Tx.
+type eventQueryer interface {
+	queryExecer
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// appendVerificationEvent appends the current state of a deployment to the verification log,
+// unless its status, commit, and manifest are the same as in its last event.
+func appendVerificationEvent(ctx context.Context, q eventQueryer, appID int64, deploymentName string, now time.Time) error {
+	var (
+		githubURL string
+		roflYAML  sql.NullString
+		status    string
+		commitSHA sql.NullString
+	)
+	query := `
+		SELECT a.github_url, a.rofl_yaml, d.status, d.commit_sha
+		FROM deployments d JOIN apps a ON a.id = d.app_id
+		WHERE d.app_id = ? AND d.deployment_name = ?
+	`
+	if err := q.QueryRowContext(ctx, query, appID, deploymentName).Scan(&githubURL, &roflYAML, &status, &commitSHA); err != nil {
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	entry := eventEntry{
+		AppID:      appID,
+		GitHubURL:  githubURL,
+		Deployment: deploymentName,
+		Status:     status,
+		CommitSHA:  commitSHA.String,
+		Timestamp:  now.UTC().Truncate(time.Second),
+	}
+	if roflYAML.Valid && roflYAML.String != "" {
+		entry.ManifestSHA256 = manifestHash(roflYAML.String)
+	}
+
+	var last string
+	err := q.QueryRowContext(ctx, `
+		SELECT entry FROM verification_events
+		WHERE app_id = ? AND deployment_name = ?
+		ORDER BY leaf_index DESC LIMIT 1
+	`, appID, deploymentName).Scan(&last)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to get last event: %w", err)
+	default:
+		var previous eventEntry
+		if err := json.Unmarshal([]byte(last), &previous); err == nil &&
+			previous.Status == entry.Status && previous.CommitSHA == entry.CommitSHA && previous.ManifestSHA256 == entry.ManifestSHA256 {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO verification_events (leaf_index, app_id, deployment_name, entry, leaf_hash, created_at)
+		SELECT COALESCE(MAX(leaf_index) + 1, 0), ?, ?, ?, ?, ? FROM verification_events
+	`, appID, deploymentName, string(data), merkle.LeafHash(data).String(), now)
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return nil
+}
+
+// GetEventLogSize returns the number of entries of the verification log.
+func (db *DB) GetEventLogSize(ctx context.Context) (int64, error) {
+	var size int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM verification_events`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return size, nil
+}
+
+// GetEventLeaves returns the leaf hashes of the first size entries of the verification log.
+func (db *DB) GetEventLeaves(ctx context.Context, size int64) ([]merkle.Hash, error) {
+	rows, err := db.QueryContext(ctx, `SELECT leaf_hash FROM verification_events WHERE leaf_index < ? ORDER BY leaf_index`, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	leaves := make([]merkle.Hash, 0, size)
+	for rows.Next() {
+		var encoded string
+		if err := rows.Scan(&encoded); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		leaf, err := merkle.ParseHash(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", len(leaves), err)
+		}
+		leaves = append(leaves, leaf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if int64(len(leaves)) != size {
+		return nil, fmt.Errorf("verification log has %d entries, expected %d", len(leaves), size)
+	}
+
+	return leaves, nil
+}
+
+// GetEventLogRoot returns the current size and Merkle root hash of the verification log.
+func (db *DB) GetEventLogRoot(ctx context.Context) (int64, merkle.Hash, error) {
+	size, err := db.GetEventLogSize(ctx)
+	if err != nil {
+		return 0, merkle.Hash{}, err
+	}
+	leaves, err := db.GetEventLeaves(ctx, size)
+	if err != nil {
+		return 0, merkle.Hash{}, err
+	}
+	return size, merkle.Root(leaves), nil
+}
+
+// GetEvents returns up to limit entries of the verification log, starting at the given index.
+func (db *DB) GetEvents(ctx context.Context, start, limit int64) ([]*models.VerificationEvent, error) {
+	query := `
+		SELECT leaf_index, entry, leaf_hash, created_at
+		FROM verification_events
+		WHERE leaf_index >= ?
+		ORDER BY leaf_index
+		LIMIT ?
+	`
+
+	rows, err := db.QueryContext(ctx, query, start, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []*models.VerificationEvent
+	for rows.Next() {
+		event := &models.VerificationEvent{}
+		if err := rows.Scan(&event.Index, &event.Entry, &event.LeafHash, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetAppEvents returns the latest entries of the verification log for an app, newest first.
+func (db *DB) GetAppEvents(ctx context.Context, appID, limit int64) ([]*models.VerificationEvent, error) {
+	query := `
+		SELECT leaf_index, entry, leaf_hash, created_at
+		FROM verification_events
+		WHERE app_id = ?
+		ORDER BY leaf_index DESC
+		LIMIT ?
+	`
+
+	rows, err := db.QueryContext(ctx, query, appID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []*models.VerificationEvent
+	for rows.Next() {
+		event := &models.VerificationEvent{}
+		if err := rows.Scan(&event.Index, &event.Entry, &event.LeafHash, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetAppEventsAsOf returns, for each deployment of an app, the last entry of the verification log
+// recorded at or before a point in time, ordered by deployment name.
+func (db *DB) GetAppEventsAsOf(ctx context.Context, appID int64, asOf time.Time) ([]*models.VerificationEvent, error) {
+	query := `
+		SELECT e.leaf_index, e.entry, e.leaf_hash, e.created_at
+		FROM verification_events e
+		WHERE e.leaf_index IN (
+			SELECT MAX(leaf_index)
+			FROM verification_events
+			WHERE app_id = ? AND created_at <= ?
+			GROUP BY deployment_name
+		)
+		ORDER BY e.deployment_name
+	`
+
+	// Times are stored as text in the zone they were written in, which is local time.
+	rows, err := db.QueryContext(ctx, query, appID, asOf.Local())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []*models.VerificationEvent
+	for rows.Next() {
+		event := &models.VerificationEvent{}
+		if err := rows.Scan(&event.Index, &event.Entry, &event.LeafHash, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// RecordLogAnchor records that the root of the verification log was published on chain.
+func (db *DB) RecordLogAnchor(ctx context.Context, anchor *models.LogAnchor) error {
+	query := `
+		INSERT INTO log_anchors (size, root_hash, chain_id, contract, tx_hash, anchored_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := db.ExecContext(ctx, query, anchor.Size, anchor.RootHash, anchor.ChainID, anchor.Contract, anchor.TxHash, anchor.AnchoredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record anchor: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastLogAnchor returns the most recent anchor of the verification log, or nil if it was
+// never anchored.
+func (db *DB) GetLastLogAnchor(ctx context.Context) (*models.LogAnchor, error) {
+	query := `
+		SELECT size, root_hash, chain_id, contract, tx_hash, anchored_at
+		FROM log_anchors
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	anchor := &models.LogAnchor{}
+	err := db.QueryRowContext(ctx, query).Scan(&anchor.Size, &anchor.RootHash, &anchor.ChainID, &anchor.Contract, &anchor.TxHash, &anchor.AnchoredAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get last anchor: %w", err)
+	}
+
+	return anchor, nil
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// heartbeatSchema creates the table of worker heartbeats, one row per worker instance, so that
+// the last completed cycle survives restarts and is visible to all replicas.
+const heartbeatSchema = `
+	CREATE TABLE IF NOT EXISTS worker_heartbeats (
+		instance_id TEXT PRIMARY KEY,
+		heartbeat_at DATETIME NOT NULL,
+		last_cycle_completed_at DATETIME,
+		last_cycle_duration_ms INTEGER NOT NULL DEFAULT 0,
+		expected_cycle_ms INTEGER NOT NULL DEFAULT 0
+	);
+`
+
+// RecordHeartbeat records that a worker instance is alive.
+func (db *DB) RecordHeartbeat(ctx context.Context, instanceID string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO worker_heartbeats (instance_id, heartbeat_at) VALUES (?, ?)
+		ON CONFLICT(instance_id) DO UPDATE SET heartbeat_at = excluded.heartbeat_at
+	`, instanceID, now)
+	if err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	return nil
+}
+
+// RecordCycleCompleted records a verification cycle completed by a worker instance, with how long
+// it took and how long cycles are expected to take.
+func (db *DB) RecordCycleCompleted(ctx context.Context, instanceID string, completedAt time.Time, duration, expected time.Duration) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO worker_heartbeats (instance_id, heartbeat_at, last_cycle_completed_at, last_cycle_duration_ms, expected_cycle_ms)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(instance_id) DO UPDATE SET
+			heartbeat_at = excluded.heartbeat_at,
+			last_cycle_completed_at = excluded.last_cycle_completed_at,
+			last_cycle_duration_ms = excluded.last_cycle_duration_ms,
+			expected_cycle_ms = excluded.expected_cycle_ms
+	`, instanceID, completedAt, completedAt, duration.Milliseconds(), expected.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to record completed cycle: %w", err)
+	}
+	return nil
+}
+
+// GetWorkerHeartbeats returns the heartbeats of all worker instances, latest first.
+func (db *DB) GetWorkerHeartbeats(ctx context.Context) ([]*models.WorkerHeartbeat, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT instance_id, heartbeat_at, last_cycle_completed_at, last_cycle_duration_ms, expected_cycle_ms
+		FROM worker_heartbeats
+		ORDER BY heartbeat_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query worker heartbeats: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var heartbeats []*models.WorkerHeartbeat
+	for rows.Next() {
+		var (
+			h                    models.WorkerHeartbeat
+			completedAt          sql.NullTime
+			durationMs, expectMs int64
+		)
+		if err := rows.Scan(&h.InstanceID, &h.HeartbeatAt, &completedAt, &durationMs, &expectMs); err != nil {
+			return nil, fmt.Errorf("failed to scan worker heartbeat: %w", err)
+		}
+		if completedAt.Valid {
+			h.LastCycleCompletedAt = &completedAt.Time
+		}
+		h.LastCycleDuration = time.Duration(durationMs) * time.Millisecond
+		h.ExpectedCycleDuration = time.Duration(expectMs) * time.Millisecond
+		heartbeats = append(heartbeats, &h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return heartbeats, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// UpsertImageDigest records the digest an image reference of an app resolved to.
+func (db *DB) UpsertImageDigest(ctx context.Context, appID int64, image, digest string) error {
+	query := `
+		INSERT INTO image_digests (app_id, image, digest, resolved_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(app_id, image) DO UPDATE SET
+			digest = excluded.digest,
+			resolved_at = excluded.resolved_at
+	`
+
+	_, err := db.ExecContext(ctx, query, appID, image, digest, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to upsert image digest: %w", err)
+	}
+
+	return nil
+}
+
+// GetImageDigestsByAppID retrieves the resolved image digests of an app, keyed by image reference.
+func (db *DB) GetImageDigestsByAppID(ctx context.Context, appID int64) (map[string]*models.ImageDigest, error) {
+	query := `
+		SELECT id, app_id, image, digest, resolved_at
+		FROM image_digests
+		WHERE app_id = ?
+	`
+
+	rows, err := db.QueryContext(ctx, query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query image digests: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	digests := make(map[string]*models.ImageDigest)
+	for rows.Next() {
+		digest := &models.ImageDigest{}
+		err := rows.Scan(
+			&digest.ID,
+			&digest.AppID,
+			&digest.Image,
+			&digest.Digest,
+			&digest.ResolvedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan image digest: %w", err)
+		}
+		digests[digest.Image] = digest
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return digests, nil
+}
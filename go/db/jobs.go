@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// VerificationJobRetention is how long finished verification jobs are kept, to summarize how long
+// the backend takes to verify and how often apps verify.
+const VerificationJobRetention = UptimeDays * 24 * time.Hour
+
+// UptimeDays is the number of days over which the share of successful verifications of an app is
+// reported.
+const UptimeDays = 90
+
+// RecordVerificationJob records a verification task finished by the backend, and deletes the jobs
+// finished before the retention period.
+func (db *DB) RecordVerificationJob(ctx context.Context, appID int64, deploymentName, taskID, status string, startedAt, completedAt time.Time) error {
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO verification_jobs (app_id, deployment_name, status, job_id, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, appID, deploymentName, status, taskID, startedAt, completedAt); err != nil {
+		return fmt.Errorf("failed to record verification job: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM verification_jobs WHERE completed_at < ?`, completedAt.Add(-VerificationJobRetention)); err != nil {
+		return fmt.Errorf("failed to prune verification jobs: %w", err)
+	}
+	return nil
+}
+
+// GetVerificationDurations returns the durations of the verification jobs of the apps of a
+// namespace finished since the given time, oldest first.
+func (db *DB) GetVerificationDurations(ctx context.Context, namespace string, since time.Time) ([]*models.VerificationDuration, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT j.app_id, COALESCE(a.slug, ''), COALESCE(j.deployment_name, ''), j.started_at, j.completed_at
+		FROM verification_jobs j JOIN apps a ON a.id = j.app_id
+		WHERE a.namespace = ? AND j.started_at IS NOT NULL AND j.completed_at >= ?
+		ORDER BY j.completed_at
+	`, namespace, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query verification jobs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var durations []*models.VerificationDuration
+	for rows.Next() {
+		var (
+			d         models.VerificationDuration
+			startedAt time.Time
+		)
+		if err := rows.Scan(&d.AppID, &d.Slug, &d.Deployment, &startedAt, &d.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan verification job: %w", err)
+		}
+		d.Duration = d.CompletedAt.Sub(startedAt)
+		durations = append(durations, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return durations, nil
+}
+
+// GetAppUptime counts the verification jobs of an app finished in the given number of days up to
+// now, in total and per day.
+func (db *DB) GetAppUptime(ctx context.Context, appID int64, days int, now time.Time) (*models.Uptime, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, 1-days)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT status, completed_at
+		FROM verification_jobs
+		WHERE app_id = ? AND completed_at >= ?
+	`, appID, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query verification jobs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	uptime := &models.Uptime{Days: days, Daily: make([]models.UptimeDay, days)}
+	for i := range uptime.Daily {
+		uptime.Daily[i].Date = start.AddDate(0, 0, i)
+	}
+	for rows.Next() {
+		var (
+			status      string
+			completedAt time.Time
+		)
+		if err := rows.Scan(&status, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan verification job: %w", err)
+		}
+		i := int(completedAt.UTC().Sub(start) / (24 * time.Hour))
+		if i < 0 || i >= days {
+			continue
+		}
+		uptime.Checks++
+		uptime.Daily[i].Checks++
+		if status == string(models.StatusVerified) {
+			uptime.Verified++
+			uptime.Daily[i].Verified++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return uptime, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ClaimApp acquires the verification lease of an app for owner until the given time.
+// The lease is granted if it is free, expired, or already held by owner, and the app was
+// not attempted since attemptedBefore (e.g. by another instance during the current cycle).
+// It reports whether the lease was acquired.
+func (db *DB) ClaimApp(ctx context.Context, appID int64, owner string, until, attemptedBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO app_leases (app_id, claimed_by, claimed_until)
+		VALUES (?, ?, ?)
+		ON CONFLICT(app_id) DO UPDATE SET
+			claimed_by = excluded.claimed_by,
+			claimed_until = excluded.claimed_until
+		WHERE (app_leases.claimed_by = excluded.claimed_by OR app_leases.claimed_until < ?)
+			AND (app_leases.last_attempt_at IS NULL OR app_leases.last_attempt_at < ?)
+	`
+
+	res, err := db.ExecContext(ctx, query, appID, owner, until, time.Now(), attemptedBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim app: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim app: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RenewAppLease extends a lease held by owner. It reports whether the lease is still held.
+func (db *DB) RenewAppLease(ctx context.Context, appID int64, owner string, until time.Time) (bool, error) {
+	query := `
+		UPDATE app_leases
+		SET claimed_until = ?
+		WHERE app_id = ? AND claimed_by = ?
+	`
+
+	res, err := db.ExecContext(ctx, query, until, appID, owner)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew app lease: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew app lease: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ReleaseApp releases a lease held by owner and records the attempt time.
+func (db *DB) ReleaseApp(ctx context.Context, appID int64, owner string) error {
+	now := time.Now()
+	query := `
+		UPDATE app_leases
+		SET claimed_until = ?, last_attempt_at = ?
+		WHERE app_id = ? AND claimed_by = ?
+	`
+
+	_, err := db.ExecContext(ctx, query, now, now, appID, owner)
+	if err != nil {
+		return fmt.Errorf("failed to release app lease: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// maintainerSchema creates the tables of maintainer sessions, the apps maintainers claimed, and
+// their notification preferences.
+// Sessions are keyed by the SHA-256 hash of their cookie value, and keep the GitHub access token of
+// the user encrypted.
+const maintainerSchema = `
+	CREATE TABLE IF NOT EXISTS maintainer_sessions (
+		token_hash TEXT PRIMARY KEY,
+		github_id INTEGER NOT NULL,
+		login TEXT NOT NULL,
+		avatar_url TEXT NOT NULL,
+		sealed_access_token BLOB NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS app_maintainers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		github_id INTEGER NOT NULL,
+		login TEXT NOT NULL,
+		claimed_at DATETIME NOT NULL,
+		UNIQUE (app_id, github_id),
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_app_maintainers_github_id ON app_maintainers(github_id);
+
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		github_id INTEGER PRIMARY KEY,
+		email TEXT NOT NULL DEFAULT '',
+		webhook_url TEXT NOT NULL DEFAULT '',
+		only_failures INTEGER NOT NULL DEFAULT 0,
+		only_mainnet INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL
+	);
+`
+
+// migrateMaintainerSessions drops the maintainer sessions of older versions, which stored access
+// tokens in plaintext. Their maintainers sign in again.
+func (db *DB) migrateMaintainerSessions() error {
+	exists, err := db.columnExists("maintainer_sessions", "access_token")
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := db.Exec(`DROP TABLE maintainer_sessions`); err != nil {
+		return fmt.Errorf("failed to drop maintainer sessions: %w", err)
+	}
+	return nil
+}
+
+// CreateMaintainerSession stores a new maintainer session and removes expired ones.
+func (db *DB) CreateMaintainerSession(ctx context.Context, tokenHash string, session *models.MaintainerSession) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM maintainer_sessions WHERE expires_at < ?`, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	query := `
+		INSERT INTO maintainer_sessions (token_hash, github_id, login, avatar_url, sealed_access_token, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.ExecContext(ctx, query, tokenHash, session.GitHubID, session.Login, session.AvatarURL, session.SealedAccessToken, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetMaintainerSession returns an unexpired maintainer session, or nil if there is none.
+func (db *DB) GetMaintainerSession(ctx context.Context, tokenHash string) (*models.MaintainerSession, error) {
+	query := `
+		SELECT github_id, login, avatar_url, sealed_access_token, expires_at
+		FROM maintainer_sessions
+		WHERE token_hash = ? AND expires_at > ?
+	`
+
+	var session models.MaintainerSession
+	err := db.QueryRowContext(ctx, query, tokenHash, time.Now()).Scan(
+		&session.GitHubID, &session.Login, &session.AvatarURL, &session.SealedAccessToken, &session.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// DeleteMaintainerSession removes a maintainer session.
+func (db *DB) DeleteMaintainerSession(ctx context.Context, tokenHash string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM maintainer_sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// AddAppMaintainer records that a GitHub user claimed an app.
+func (db *DB) AddAppMaintainer(ctx context.Context, appID, githubID int64, login string) error {
+	query := `
+		INSERT INTO app_maintainers (app_id, github_id, login, claimed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(app_id, github_id) DO UPDATE SET login = excluded.login
+	`
+	if _, err := db.ExecContext(ctx, query, appID, githubID, login, time.Now()); err != nil {
+		return fmt.Errorf("failed to add app maintainer: %w", err)
+	}
+	return nil
+}
+
+// RemoveAppMaintainer removes the claim of a GitHub user on an app.
+func (db *DB) RemoveAppMaintainer(ctx context.Context, appID, githubID int64) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM app_maintainers WHERE app_id = ? AND github_id = ?`, appID, githubID); err != nil {
+		return fmt.Errorf("failed to remove app maintainer: %w", err)
+	}
+	return nil
+}
+
+// IsAppMaintainer reports whether a GitHub user claimed an app.
+func (db *DB) IsAppMaintainer(ctx context.Context, appID, githubID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM app_maintainers WHERE app_id = ? AND github_id = ?)`
+	if err := db.QueryRowContext(ctx, query, appID, githubID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check app maintainer: %w", err)
+	}
+	return exists, nil
+}
+
+// GetMaintainedApps returns the apps a GitHub user claimed.
+func (db *DB) GetMaintainedApps(ctx context.Context, githubID int64) ([]*models.App, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM apps
+		WHERE id IN (SELECT app_id FROM app_maintainers WHERE github_id = ?)
+		ORDER BY id ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, githubID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apps []*models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return apps, nil
+}
+
+// GetNotificationPreferences returns the notification preferences of a GitHub user. Users who
+// never set them get empty preferences, which disable all notifications.
+func (db *DB) GetNotificationPreferences(ctx context.Context, githubID int64) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT email, webhook_url, only_failures, only_mainnet
+		FROM notification_preferences
+		WHERE github_id = ?
+	`
+
+	prefs := &models.NotificationPreferences{GitHubID: githubID}
+	err := db.QueryRowContext(ctx, query, githubID).Scan(&prefs.Email, &prefs.WebhookURL, &prefs.OnlyFailures, &prefs.OnlyMainnet)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// SetNotificationPreferences stores the notification preferences of a GitHub user.
+func (db *DB) SetNotificationPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (github_id, email, webhook_url, only_failures, only_mainnet, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(github_id) DO UPDATE SET
+			email = excluded.email,
+			webhook_url = excluded.webhook_url,
+			only_failures = excluded.only_failures,
+			only_mainnet = excluded.only_mainnet,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.ExecContext(ctx, query, prefs.GitHubID, prefs.Email, prefs.WebhookURL, prefs.OnlyFailures, prefs.OnlyMainnet, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set notification preferences: %w", err)
+	}
+	return nil
+}
+
+// GetAppNotificationPreferences returns the notification preferences of the maintainers of an
+// app that enabled at least one channel.
+func (db *DB) GetAppNotificationPreferences(ctx context.Context, appID int64) ([]*models.NotificationPreferences, error) {
+	query := `
+		SELECT p.github_id, p.email, p.webhook_url, p.only_failures, p.only_mainnet
+		FROM notification_preferences p
+		JOIN app_maintainers m ON m.github_id = p.github_id
+		WHERE m.app_id = ? AND (p.email != '' OR p.webhook_url != '')
+	`
+
+	rows, err := db.QueryContext(ctx, query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var result []*models.NotificationPreferences
+	for rows.Next() {
+		prefs := &models.NotificationPreferences{}
+		if err := rows.Scan(&prefs.GitHubID, &prefs.Email, &prefs.WebhookURL, &prefs.OnlyFailures, &prefs.OnlyMainnet); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preferences: %w", err)
+		}
+		result = append(result, prefs)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
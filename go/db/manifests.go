@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// manifestHash returns the content address of a manifest.
+func manifestHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordManifestVersion stores a fetched manifest if its content has not been seen for the app before.
+func (db *DB) RecordManifestVersion(ctx context.Context, appID int64, content string) error {
+	query := `
+		INSERT INTO manifest_versions (app_id, content_hash, content, first_seen_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(app_id, content_hash) DO NOTHING
+	`
+
+	_, err := db.ExecContext(ctx, query, appID, manifestHash(content), content, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record manifest version: %w", err)
+	}
+
+	return nil
+}
+
+// MarkManifestVerified records that a deployment of the app verified against the given manifest content.
+func (db *DB) MarkManifestVerified(ctx context.Context, appID int64, content string) error {
+	now := time.Now()
+	query := `
+		INSERT INTO manifest_versions (app_id, content_hash, content, first_seen_at, verified_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(app_id, content_hash) DO UPDATE SET
+			verified_at = excluded.verified_at
+	`
+
+	_, err := db.ExecContext(ctx, query, appID, manifestHash(content), content, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark manifest verified: %w", err)
+	}
+
+	return nil
+}
+
+// GetManifestVersions retrieves all stored manifest versions of an app, newest first.
+func (db *DB) GetManifestVersions(ctx context.Context, appID int64) ([]*models.ManifestVersion, error) {
+	query := `
+		SELECT id, app_id, content_hash, content, first_seen_at, verified_at
+		FROM manifest_versions
+		WHERE app_id = ?
+		ORDER BY first_seen_at DESC, id DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query manifest versions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var versions []*models.ManifestVersion
+	for rows.Next() {
+		version := &models.ManifestVersion{}
+		err := rows.Scan(
+			&version.ID,
+			&version.AppID,
+			&version.ContentHash,
+			&version.Content,
+			&version.FirstSeenAt,
+			&version.VerifiedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan manifest version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetManifestVersionByHash returns the version of an app's manifest with the given content hash,
+// or nil if it was never recorded.
+func (db *DB) GetManifestVersionByHash(ctx context.Context, appID int64, contentHash string) (*models.ManifestVersion, error) {
+	query := `
+		SELECT id, app_id, content_hash, content, first_seen_at, verified_at
+		FROM manifest_versions
+		WHERE app_id = ? AND content_hash = ?
+	`
+
+	version := &models.ManifestVersion{}
+	err := db.QueryRowContext(ctx, query, appID, contentHash).Scan(
+		&version.ID,
+		&version.AppID,
+		&version.ContentHash,
+		&version.Content,
+		&version.FirstSeenAt,
+		&version.VerifiedAt,
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get manifest version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// outboxSchema creates the outbox of notifications awaiting delivery. Deliveries are claimed for a
+// lease, so that with several workers sharing the database each is sent by one, and a delivery of
+// a worker that stopped is retried by another once its claim expires.
+const outboxSchema = `
+	CREATE TABLE IF NOT EXISTS notification_outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		priority INTEGER NOT NULL DEFAULT 0,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		claimed_until DATETIME,
+		last_error TEXT,
+		delivered_at DATETIME,
+		failed_at DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (event_id, channel, destination),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(next_attempt_at)
+		WHERE delivered_at IS NULL AND failed_at IS NULL;
+`
+
+// EnqueueNotifications adds deliveries to the outbox, due right away. Deliveries already queued
+// for the same event, channel, and destination are skipped.
+func (db *DB) EnqueueNotifications(ctx context.Context, deliveries []*models.NotificationDelivery) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+	for _, d := range deliveries {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_outbox (event_id, channel, destination, payload, priority, next_attempt_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(event_id, channel, destination) DO NOTHING
+		`, d.EventID, d.Channel, d.Destination, string(d.Payload), d.Priority, now); err != nil {
+			return fmt.Errorf("failed to enqueue notification: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueNotifications claims up to limit deliveries that are due for the given lease and returns
+// them, highest priority first.
+func (db *DB) ClaimDueNotifications(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error) {
+	now := time.Now()
+	rows, err := db.QueryContext(ctx, `
+		UPDATE notification_outbox SET claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?
+				AND (claimed_until IS NULL OR claimed_until < ?)
+			ORDER BY priority DESC, id
+			LIMIT ?
+		)
+		RETURNING id, event_id, channel, destination, payload, priority, attempts
+	`, now.Add(lease), now, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notifications: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var deliveries []*models.NotificationDelivery
+	for rows.Next() {
+		var d models.NotificationDelivery
+		var payload string
+		if err := rows.Scan(&d.ID, &d.EventID, &d.Channel, &d.Destination, &payload, &d.Priority, &d.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	// RETURNING does not preserve the order of the subquery.
+	slices.SortFunc(deliveries, func(a, b *models.NotificationDelivery) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.ID, b.ID))
+	})
+	return deliveries, nil
+}
+
+// MarkNotificationDelivered records the successful delivery of a notification.
+func (db *DB) MarkNotificationDelivered(ctx context.Context, id int64) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE notification_outbox SET attempts = attempts + 1, delivered_at = ?, claimed_until = NULL, last_error = NULL WHERE id = ?
+	`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification delivered: %w", err)
+	}
+	return nil
+}
+
+// RetryNotification records a failed attempt to deliver a notification, to be retried at the given
+// time, or given up on if the time is zero.
+func (db *DB) RetryNotification(ctx context.Context, id int64, next time.Time, deliveryErr string) error {
+	query := `UPDATE notification_outbox SET attempts = attempts + 1, next_attempt_at = ?, claimed_until = NULL, last_error = ? WHERE id = ?`
+	args := []any{next, deliveryErr, id}
+	if next.IsZero() {
+		query = `UPDATE notification_outbox SET attempts = attempts + 1, failed_at = ?, claimed_until = NULL, last_error = ? WHERE id = ?`
+		args[0] = time.Now()
+	}
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record notification attempt: %w", err)
+	}
+	return nil
+}
+
+// PruneNotificationOutbox removes deliveries that were delivered or given up on before the given
+// time.
+func (db *DB) PruneNotificationOutbox(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM notification_outbox WHERE delivered_at < ? OR failed_at < ?
+	`, before, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notification outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
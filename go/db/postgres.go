@@ -0,0 +1,372 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pgColumn is a column of a table being migrated to Postgres.
+type pgColumn struct {
+	name       string
+	sqliteType string
+	notNull    bool
+	defaultVal *string
+	primaryKey bool
+}
+
+// WritePostgresScript writes a psql script that creates the registry schema in an empty Postgres
+// database and copies all rows into it, in a single transaction. SQLite column types are converted
+// to their Postgres equivalents: INTEGER to BIGINT, DATETIME to TIMESTAMPTZ, and BLOB to BYTEA. It
+// returns the number of rows written per table, for verifying the copy.
+func (db *DB) WritePostgresScript(ctx context.Context, out io.Writer) (map[string]int64, error) {
+	tables, err := db.pgTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(out)
+	counts := make(map[string]int64, len(tables))
+
+	fmt.Fprintln(w, "\\set ON_ERROR_STOP on")
+	fmt.Fprintln(w, "BEGIN;")
+	for _, table := range tables {
+		columns, err := db.pgColumns(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		ddl, err := db.pgCreateTable(ctx, table, columns)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(w, ddl)
+
+		n, err := db.writePgCopy(ctx, w, table, columns)
+		if err != nil {
+			return nil, err
+		}
+		counts[table] = n
+
+		// Continue identity sequences after the copied IDs.
+		if len(columns) > 0 && columns[0].name == "id" && columns[0].primaryKey {
+			fmt.Fprintf(w, "SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM %[1]s;\n", table)
+		}
+	}
+	fmt.Fprintln(w, "COMMIT;")
+
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write script: %w", err)
+	}
+	return counts, nil
+}
+
+// pgTables returns the tables of the database, parents before the tables referencing them. The
+// full-text search index is left out: its virtual table and the shadow tables storing it only work
+// in SQLite, and the index is derived from the apps.
+func (db *DB) pgTables(ctx context.Context) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT name, sql FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var names, virtual []string
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		if strings.HasPrefix(strings.ToUpper(ddl), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return slices.ContainsFunc(virtual, func(v string) bool { return strings.HasPrefix(name, v+"_") })
+	})
+
+	parents := make(map[string][]string, len(names))
+	for _, table := range names {
+		keys, err := db.foreignKeys(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if key.parent != table {
+				parents[table] = append(parents[table], key.parent)
+			}
+		}
+	}
+
+	// Add tables once all their parents were added.
+	tables := make([]string, 0, len(names))
+	for len(names) > 0 {
+		rest := names[:0:0]
+		for _, table := range names {
+			if slices.ContainsFunc(parents[table], func(parent string) bool { return !slices.Contains(tables, parent) }) {
+				rest = append(rest, table)
+				continue
+			}
+			tables = append(tables, table)
+		}
+		if len(rest) == len(names) {
+			return nil, fmt.Errorf("failed to order tables: foreign keys of %s form a cycle or reference missing tables", strings.Join(rest, ", "))
+		}
+		names = rest
+	}
+	return tables, nil
+}
+
+// pgColumns returns the columns of a table.
+func (db *DB) pgColumns(ctx context.Context, table string) ([]pgColumn, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table)) // #nosec G201 -- constant identifier.
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var columns []pgColumn
+	for rows.Next() {
+		var (
+			cid     int
+			col     pgColumn
+			notNull int
+			pk      int
+		)
+		if err := rows.Scan(&cid, &col.name, &col.sqliteType, &notNull, &col.defaultVal, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		col.notNull = notNull != 0
+		col.primaryKey = pk != 0
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	return columns, nil
+}
+
+// pgType converts a SQLite column type to Postgres.
+func pgType(sqliteType string) string {
+	switch strings.ToUpper(sqliteType) {
+	case "INTEGER":
+		return "BIGINT"
+	case "DATETIME":
+		return "TIMESTAMPTZ"
+	case "BLOB":
+		return "BYTEA"
+	default:
+		return "TEXT"
+	}
+}
+
+// pgCreateTable returns the Postgres CREATE TABLE statement of a table, including its foreign keys
+// and unique constraints.
+func (db *DB) pgCreateTable(ctx context.Context, table string, columns []pgColumn) (string, error) {
+	var primaryKey []string
+	for _, col := range columns {
+		if col.primaryKey {
+			primaryKey = append(primaryKey, col.name)
+		}
+	}
+	composite := len(primaryKey) > 1
+
+	var defs []string
+	for _, col := range columns {
+		def := col.name + " " + pgType(col.sqliteType)
+		switch {
+		case col.primaryKey && col.name == "id":
+			def += " GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY"
+		case col.primaryKey && !composite:
+			def += " PRIMARY KEY"
+		case col.primaryKey:
+			def += " NOT NULL"
+		case col.notNull:
+			def += " NOT NULL"
+		}
+		if col.defaultVal != nil {
+			def += " DEFAULT " + *col.defaultVal
+		}
+		defs = append(defs, def)
+	}
+	if composite {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+
+	keys, err := db.foreignKeys(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range keys {
+		def := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", strings.Join(key.from, ", "), key.parent)
+		if len(key.to) > 0 {
+			def += "(" + strings.Join(key.to, ", ") + ")"
+		}
+		if key.onDelete != "" && key.onDelete != "NO ACTION" {
+			def += " ON DELETE " + key.onDelete
+		}
+		defs = append(defs, def)
+	}
+
+	uniques, err := db.uniqueConstraints(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	for _, cols := range uniques {
+		defs = append(defs, "UNIQUE ("+strings.Join(cols, ", ")+")")
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n\t%s\n);", table, strings.Join(defs, ",\n\t")), nil
+}
+
+// uniqueConstraints returns the columns of each UNIQUE constraint and unique index of a table.
+func (db *DB) uniqueConstraints(ctx context.Context, table string) ([][]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_list(%s)", table)) // #nosec G201 -- constant identifier.
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", table, err)
+	}
+	var indexes []string
+	for rows.Next() {
+		var (
+			seq     int
+			name    string
+			unique  int
+			origin  string
+			partial int
+		)
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan index of %s: %w", table, err)
+		}
+		if unique != 0 && origin != "pk" {
+			indexes = append(indexes, name)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", table, err)
+	}
+
+	var uniques [][]string
+	for _, index := range indexes {
+		cols, err := db.indexColumns(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		uniques = append(uniques, cols)
+	}
+	return uniques, nil
+}
+
+// indexColumns returns the columns of an index, in order.
+func (db *DB) indexColumns(ctx context.Context, index string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_info('%s')", index)) // #nosec G201 -- index name from PRAGMA index_list.
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect index %s: %w", index, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var cols []string
+	for rows.Next() {
+		var (
+			seqno, cid int
+			name       string
+		)
+		if err := rows.Scan(&seqno, &cid, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan index %s: %w", index, err)
+		}
+		cols = append(cols, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to inspect index %s: %w", index, err)
+	}
+	return cols, nil
+}
+
+// writePgCopy writes the rows of a table as a COPY statement in text format and returns the row count.
+func (db *DB) writePgCopy(ctx context.Context, w io.Writer, table string, columns []pgColumn) (int64, error) {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	columnList := strings.Join(names, ", ")
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s ORDER BY rowid", columnList, table)) // #nosec G201 -- constant identifiers.
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	fmt.Fprintf(w, "COPY %s (%s) FROM stdin;\n", table, columnList)
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	fields := make([]string, len(columns))
+
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, fmt.Errorf("failed to scan row of %s: %w", table, err)
+		}
+		for i, v := range values {
+			fields[i] = pgCopyValue(v, pgType(columns[i].sqliteType) == "BYTEA")
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	fmt.Fprintln(w, "\\.")
+
+	return n, nil
+}
+
+// pgCopyEscaper escapes values for the COPY text format.
+var pgCopyEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// pgCopyValue formats a SQLite value as a COPY text format field. Values of BYTEA columns are
+// written in hex format, since Postgres rejects NUL and invalid UTF-8 bytes in text.
+func pgCopyValue(v any, bytea bool) string {
+	if bytea {
+		switch b := v.(type) {
+		case []byte:
+			return `\\x` + hex.EncodeToString(b)
+		case string:
+			return `\\x` + hex.EncodeToString([]byte(b))
+		}
+	}
+	switch v := v.(type) {
+	case nil:
+		return `\N`
+	case time.Time:
+		return v.UTC().Format("2006-01-02 15:04:05.999999Z07:00")
+	case []byte:
+		return pgCopyEscaper.Replace(string(v))
+	case string:
+		return pgCopyEscaper.Replace(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
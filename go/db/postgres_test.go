@@ -0,0 +1,110 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestDB returns a database with the registry schema in a temporary directory.
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "registry.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if err := db.InitSchema(); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return db
+}
+
+// Test that the migration script copies every table of the schema, parents first, and writes
+// binary columns in the hex format of BYTEA.
+func TestWritePostgresScript(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	if err := db.UpsertApp(ctx, "default", "https://github.com/example/app", "main", false, "", "", nil); err != nil {
+		t.Fatalf("Failed to add app: %v", err)
+	}
+	if err := db.UpdateAppLogo(ctx, 1, "https://example.com/logo.png", []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}); err != nil {
+		t.Fatalf("Failed to store logo: %v", err)
+	}
+
+	var script bytes.Buffer
+	counts, err := db.WritePostgresScript(ctx, &script)
+	if err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	out := script.String()
+
+	for _, table := range []string{"apps", "deployments", "events", "notification_outbox", "verification_events", "worker_heartbeats", "maintainer_sessions", "notification_preferences"} {
+		if _, ok := counts[table]; !ok {
+			t.Errorf("Table %s is not copied", table)
+		}
+	}
+	for table := range counts {
+		if strings.HasPrefix(table, "apps_fts") {
+			t.Errorf("Search index table %s is copied", table)
+		}
+	}
+	if counts["apps"] != 1 {
+		t.Errorf("Expected 1 app, got %d", counts["apps"])
+	}
+
+	if !strings.Contains(out, "\tlogo BYTEA,") {
+		t.Errorf("Logo column is not BYTEA")
+	}
+	if !strings.Contains(out, `\\x89504e4700ff`) {
+		t.Errorf("Logo is not written in hex format")
+	}
+
+	// Tables are created after the tables they reference.
+	apps := strings.Index(out, "CREATE TABLE apps ")
+	events := strings.Index(out, "CREATE TABLE events ")
+	outbox := strings.Index(out, "CREATE TABLE notification_outbox ")
+	if apps < 0 || events < apps || outbox < events {
+		t.Errorf("Tables are not created parents first: apps at %d, events at %d, notification_outbox at %d", apps, events, outbox)
+	}
+}
+
+// Test that tables get the foreign keys of their SQLite schema: tables without an app_id column
+// reference nothing, and the verification log keeps the entries of removed apps.
+func TestPostgresForeignKeys(t *testing.T) {
+	db := newTestDB(t)
+
+	var script bytes.Buffer
+	if _, err := db.WritePostgresScript(context.Background(), &script); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	out := script.String()
+
+	createTable := func(table string) string {
+		start := strings.Index(out, "CREATE TABLE "+table+" (")
+		if start < 0 {
+			t.Fatalf("Table %s is not created", table)
+		}
+		end := strings.Index(out[start:], ");")
+		return out[start : start+end]
+	}
+
+	for table, want := range map[string]string{
+		"deployments":         "FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE",
+		"notification_outbox": "FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE",
+	} {
+		if ddl := createTable(table); !strings.Contains(ddl, want) || strings.Count(ddl, "FOREIGN KEY") != 1 {
+			t.Errorf("Unexpected foreign keys of %s:\n%s", table, ddl)
+		}
+	}
+	for _, table := range []string{"apps", "verification_events", "log_anchors", "registry_errors", "worker_heartbeats"} {
+		if ddl := createTable(table); strings.Contains(ddl, "FOREIGN KEY") {
+			t.Errorf("Table %s has foreign keys:\n%s", table, ddl)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// provenanceSchema creates the table of builds that successful verifications reproduced. A build
+// is identified by its deployment, commit, and manifest version, and verifying it again updates
+// its record, so that there is one per release rather than per verification cycle.
+const provenanceSchema = `
+	CREATE TABLE IF NOT EXISTS provenance (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		deployment_name TEXT NOT NULL,
+		commit_sha TEXT NOT NULL,
+		manifest_sha256 TEXT NOT NULL,
+		cli_version TEXT,
+		builder_image TEXT,
+		task_id TEXT,
+		started_at DATETIME,
+		finished_at DATETIME NOT NULL,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE,
+		UNIQUE (app_id, deployment_name, commit_sha, manifest_sha256)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_provenance_deployment ON provenance(app_id, deployment_name, finished_at);
+`
+
+// RecordProvenance records a build that a successful verification reproduced, using the given
+// manifest content.
+func (db *DB) RecordProvenance(ctx context.Context, provenance *models.Provenance, manifest string) error {
+	provenance.ManifestSHA256 = manifestHash(manifest)
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO provenance (app_id, deployment_name, commit_sha, manifest_sha256, cli_version, builder_image, task_id, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(app_id, deployment_name, commit_sha, manifest_sha256) DO UPDATE SET
+			cli_version = excluded.cli_version,
+			builder_image = excluded.builder_image,
+			task_id = excluded.task_id,
+			started_at = excluded.started_at,
+			finished_at = excluded.finished_at
+	`, provenance.AppID, provenance.DeploymentName, provenance.CommitSHA, provenance.ManifestSHA256,
+		nullString(provenance.CLIVersion), nullString(provenance.BuilderImage), nullString(provenance.TaskID),
+		provenance.StartedAt, provenance.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record provenance: %w", err)
+	}
+	return nil
+}
+
+// GetProvenance returns the most recently verified build of a deployment, limited to the builds
+// of a commit unless commitSHA is empty, or nil if there is none.
+func (db *DB) GetProvenance(ctx context.Context, appID int64, deploymentName, commitSHA string) (*models.Provenance, error) {
+	query := `
+		SELECT app_id, deployment_name, commit_sha, manifest_sha256, COALESCE(cli_version, ''),
+			COALESCE(builder_image, ''), COALESCE(task_id, ''), started_at, finished_at
+		FROM provenance
+		WHERE app_id = ? AND deployment_name = ? AND (? = '' OR commit_sha = ? COLLATE NOCASE)
+		ORDER BY finished_at DESC
+		LIMIT 1
+	`
+
+	provenance := &models.Provenance{}
+	err := db.QueryRowContext(ctx, query, appID, deploymentName, commitSHA, commitSHA).Scan(
+		&provenance.AppID,
+		&provenance.DeploymentName,
+		&provenance.CommitSHA,
+		&provenance.ManifestSHA256,
+		&provenance.CLIVersion,
+		&provenance.BuilderImage,
+		&provenance.TaskID,
+		&provenance.StartedAt,
+		&provenance.FinishedAt,
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get provenance: %w", err)
+	}
+	return provenance, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// registrySchema creates the table of problems found in entries of the apps registries, replaced
+// whenever a registry is synced.
+const registrySchema = `
+	CREATE TABLE IF NOT EXISTS registry_errors (
+		namespace TEXT NOT NULL DEFAULT 'default',
+		entry_index INTEGER NOT NULL,
+		line INTEGER,
+		url TEXT,
+		errors TEXT NOT NULL,
+		checked_at DATETIME NOT NULL,
+		PRIMARY KEY (namespace, entry_index)
+	);
+`
+
+// migrateRegistryErrors drops the registry errors table of older versions, keyed by entry alone.
+// Its rows are recreated by the next sync.
+func (db *DB) migrateRegistryErrors() error {
+	exists, err := db.columnExists("registry_errors", "entry_index")
+	if err != nil || !exists {
+		return err
+	}
+	namespaced, err := db.columnExists("registry_errors", "namespace")
+	if err != nil || namespaced {
+		return err
+	}
+	if _, err := db.Exec(`DROP TABLE registry_errors`); err != nil {
+		return fmt.Errorf("failed to drop registry errors: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRegistryErrors replaces the stored problems of the entries of a namespace's registry with
+// those of the latest sync.
+func (db *DB) ReplaceRegistryErrors(ctx context.Context, namespace string, entries []models.RegistryEntryError) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM registry_errors WHERE namespace = ?`, namespace); err != nil {
+		return fmt.Errorf("failed to clear registry errors: %w", err)
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO registry_errors (namespace, entry_index, line, url, errors, checked_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, namespace, entry.Index, entry.Line, nullString(entry.URL), strings.Join(entry.Errors, "\n"), now)
+		if err != nil {
+			return fmt.Errorf("failed to record registry error: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetRegistryErrors returns the problems of the registry entries of a namespace, or of all
+// namespaces if it is empty, found by the latest sync.
+func (db *DB) GetRegistryErrors(ctx context.Context, namespace string) ([]models.RegistryEntryError, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT namespace, entry_index, line, COALESCE(url, ''), errors, checked_at
+		FROM registry_errors
+		WHERE ? = '' OR namespace = ?
+		ORDER BY namespace, entry_index
+	`, namespace, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query registry errors: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []models.RegistryEntryError
+	for rows.Next() {
+		var (
+			entry  models.RegistryEntryError
+			errors string
+		)
+		if err := rows.Scan(&entry.Namespace, &entry.Index, &entry.Line, &entry.URL, &errors, &entry.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan registry error: %w", err)
+		}
+		entry.Errors = strings.Split(errors, "\n")
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return entries, nil
+}
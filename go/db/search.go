@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// maxSearchTerms limits the number of terms of a search query.
+const maxSearchTerms = 10
+
+// searchSchema creates the full-text index of apps, keyed by app ID. FTS4 is used as FTS5 is
+// only available in go-sqlite3 builds with the sqlite_fts5 tag.
+const searchSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS apps_fts USING fts4(
+		name, description, author, github_url,
+		tokenize=unicode61
+	);
+`
+
+// queryExecer is implemented by both *sql.DB and *sql.Tx.
+type queryExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// indexApp updates the search index entry of an app from its GitHub URL and manifest.
+func indexApp(ctx context.Context, q queryExecer, id int64) error {
+	var (
+		githubURL string
+		roflYAML  sql.NullString
+	)
+	if err := q.QueryRowContext(ctx, `SELECT github_url, rofl_yaml FROM apps WHERE id = ?`, id).Scan(&githubURL, &roflYAML); err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+
+	// Apps with an invalid manifest remain searchable by URL.
+	manifest := &rofl.Manifest{}
+	if roflYAML.Valid && roflYAML.String != "" {
+		if parsed, err := rofl.Parse([]byte(roflYAML.String)); err == nil {
+			manifest = parsed
+		}
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM apps_fts WHERE docid = ?`, id); err != nil {
+		return fmt.Errorf("failed to update search index: %w", err)
+	}
+	query := `INSERT INTO apps_fts (docid, name, description, author, github_url) VALUES (?, ?, ?, ?, ?)`
+	if _, err := q.ExecContext(ctx, query, id, manifest.Name, manifest.Description, manifest.Author, githubURL); err != nil {
+		return fmt.Errorf("failed to update search index: %w", err)
+	}
+	return nil
+}
+
+// syncSearchIndex indexes apps missing from the search index, e.g. after an upgrade,
+// and drops entries of deleted apps.
+func (db *DB) syncSearchIndex(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM apps_fts WHERE docid NOT IN (SELECT id FROM apps)`); err != nil {
+		return fmt.Errorf("failed to prune search index: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT id FROM apps WHERE id NOT IN (SELECT docid FROM apps_fts)`)
+	if err != nil {
+		return fmt.Errorf("failed to query unindexed apps: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan app ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query unindexed apps: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := indexApp(ctx, db, id); err != nil {
+			return fmt.Errorf("failed to index app %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// matchQuery converts user input into an FTS query matching apps that contain every word,
+// where the last characters of each word may be missing. It returns an empty string if the
+// input contains no words.
+func matchQuery(input string) string {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	for i, word := range words {
+		words[i] = word + "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// SearchApps retrieves the apps of a namespace, or of all namespaces if it is empty, whose name,
+// description, author, or GitHub URL match query, in the given order.
+func (db *DB) SearchApps(ctx context.Context, namespace, query string, order AppOrder) ([]*models.App, error) {
+	match := matchQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+appColumns+`
+		FROM apps
+		WHERE id IN (SELECT docid FROM apps_fts WHERE apps_fts MATCH ?) AND (? = '' OR namespace = ?)
+		`+order.orderBy(), match, namespace, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search apps: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apps []*models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan app: %w", err)
+		}
+		apps = append(apps, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// slugSchema enforces unique slugs within each namespace, replacing the index of older versions
+// that enforced them across namespaces. It is created after column migrations, since older
+// databases only get the slug column from them.
+const slugSchema = `
+	DROP INDEX IF EXISTS idx_apps_slug;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_apps_namespace_slug ON apps(namespace, slug);
+`
+
+// slugInvalidChars matches runs of characters not allowed in slugs.
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts a string to a URL-safe slug.
+func slugify(s string) string {
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// slugCandidates returns the slugs to try for an app, in order of preference: the app name,
+// the name qualified by the repository owner, and the name with increasing numeric suffixes.
+// Apps without a name are named after their repository.
+func slugCandidates(name, githubURL string) iter.Seq[string] {
+	var owner, repo string
+	if u, err := url.Parse(githubURL); err == nil {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 2 {
+			owner, repo = slugify(parts[0]), slugify(parts[1])
+		}
+	}
+
+	base := slugify(name)
+	if base == "" {
+		base = repo
+	}
+	switch {
+	case base == "":
+		base = "app"
+	case strings.Trim(base, "0123456789") == "":
+		// Numeric slugs would be mistaken for app IDs.
+		base = "app-" + base
+	}
+
+	return func(yield func(string) bool) {
+		if !yield(base) {
+			return
+		}
+		if owner != "" && !yield(base+"-"+owner) {
+			return
+		}
+		for n := 2; ; n++ {
+			if !yield(base + "-" + strconv.Itoa(n)) {
+				return
+			}
+		}
+	}
+}
+
+// assignSlug gives an app with a manifest its permanent slug, unique within its namespace, if it
+// does not have one yet. Slugs stay the same when the app is renamed, so permalinks keep working.
+func assignSlug(ctx context.Context, q queryExecer, id int64) error {
+	var (
+		namespace string
+		githubURL string
+		roflYAML  sql.NullString
+		slug      sql.NullString
+	)
+	if err := q.QueryRowContext(ctx, `SELECT namespace, github_url, rofl_yaml, slug FROM apps WHERE id = ?`, id).Scan(&namespace, &githubURL, &roflYAML, &slug); err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+	if slug.Valid || !roflYAML.Valid || roflYAML.String == "" {
+		return nil
+	}
+
+	var name string
+	if manifest, err := rofl.Parse([]byte(roflYAML.String)); err == nil {
+		name = manifest.Name
+	}
+
+	for candidate := range slugCandidates(name, githubURL) {
+		var taken bool
+		if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM apps WHERE namespace = ? AND slug = ?)`, namespace, candidate).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check slug: %w", err)
+		}
+		if taken {
+			continue
+		}
+		if _, err := q.ExecContext(ctx, `UPDATE apps SET slug = ? WHERE id = ? AND slug IS NULL`, candidate, id); err != nil {
+			return fmt.Errorf("failed to assign slug: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// backfillSlugs assigns slugs to apps that have a manifest but no slug, in order of app ID.
+func (db *DB) backfillSlugs(ctx context.Context) error {
+	rows, err := db.QueryContext(ctx, `SELECT id FROM apps WHERE slug IS NULL AND rofl_yaml IS NOT NULL ORDER BY id ASC`)
+	if err != nil {
+		return fmt.Errorf("failed to query apps without slug: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan app ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query apps without slug: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := assignSlug(ctx, db, id); err != nil {
+			return fmt.Errorf("failed to assign slug of app %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// GetAppBySlug retrieves an app of a namespace by slug.
+func (db *DB) GetAppBySlug(ctx context.Context, namespace, slug string) (*models.App, error) {
+	query := `
+		SELECT ` + appColumns + `
+		FROM apps
+		WHERE namespace = ? AND slug = ?
+	`
+
+	app, err := scanApp(db.QueryRowContext(ctx, query, namespace, slug))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("app not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+
+	return app, nil
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// statusEventSchema creates the table of deployment status changes. Unlike the verification log,
+// it only records transitions, and is removed with its app.
+const statusEventSchema = `
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		deployment_name TEXT NOT NULL,
+		old_status TEXT,
+		new_status TEXT NOT NULL,
+		commit_sha TEXT,
+		details TEXT,
+		notified_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_events_app_id ON events(app_id, id);
+	CREATE INDEX IF NOT EXISTS idx_events_unnotified ON events(id) WHERE notified_at IS NULL;
+`
+
+// insertStatusEvent records a status change of a deployment.
+func insertStatusEvent(ctx context.Context, q queryExecer, appID int64, deploymentName string, oldStatus, newStatus models.VerificationStatus, commitSHA, details string, now time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO events (app_id, deployment_name, old_status, new_status, commit_sha, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, appID, deploymentName, nullString(string(oldStatus)), newStatus, nullString(commitSHA), nullString(details), now)
+	if err != nil {
+		return fmt.Errorf("failed to record status event: %w", err)
+	}
+	return nil
+}
+
+// statusEventColumns are the columns scanned by scanStatusEvents, from events e joined with apps a.
+const statusEventColumns = `
+	e.id, e.app_id, a.github_url, COALESCE(a.slug, ''), e.deployment_name,
+	COALESCE(e.old_status, ''), e.new_status, COALESCE(e.commit_sha, ''), COALESCE(e.details, ''), e.created_at
+`
+
+// GetStatusEventsAfter returns up to limit status events of the apps of a namespace with IDs above
+// after, oldest first. With a non-zero appID, only events of that app are returned.
+func (db *DB) GetStatusEventsAfter(ctx context.Context, namespace string, appID, after, limit int64) ([]*models.StatusEvent, error) {
+	query := `
+		SELECT ` + statusEventColumns + `
+		FROM events e JOIN apps a ON a.id = e.app_id
+		WHERE e.id > ? AND a.namespace = ? AND (? = 0 OR e.app_id = ?)
+		ORDER BY e.id
+		LIMIT ?
+	`
+	return db.queryStatusEvents(ctx, query, after, namespace, appID, appID, limit)
+}
+
+// GetLatestStatusEvents returns the latest limit status events of the apps of a namespace, newest
+// first. With a non-zero appID, only events of that app are returned.
+func (db *DB) GetLatestStatusEvents(ctx context.Context, namespace string, appID, limit int64) ([]*models.StatusEvent, error) {
+	query := `
+		SELECT ` + statusEventColumns + `
+		FROM events e JOIN apps a ON a.id = e.app_id
+		WHERE a.namespace = ? AND (? = 0 OR e.app_id = ?)
+		ORDER BY e.id DESC
+		LIMIT ?
+	`
+	return db.queryStatusEvents(ctx, query, namespace, appID, appID, limit)
+}
+
+// GetLastDeploymentStatusEvent returns the latest status event of a deployment, i.e. the change to
+// its current status, or nil if there is none.
+func (db *DB) GetLastDeploymentStatusEvent(ctx context.Context, appID int64, deploymentName string) (*models.StatusEvent, error) {
+	query := `
+		SELECT ` + statusEventColumns + `
+		FROM events e JOIN apps a ON a.id = e.app_id
+		WHERE e.app_id = ? AND e.deployment_name = ?
+		ORDER BY e.id DESC
+		LIMIT 1
+	`
+	events, err := db.queryStatusEvents(ctx, query, appID, deploymentName)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// GetLastStatusEventID returns the ID of the latest status event, or 0 if there are none.
+func (db *DB) GetLastStatusEventID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get last status event: %w", err)
+	}
+	return id, nil
+}
+
+// ClaimUnnotifiedStatusEvents marks up to limit status events whose notifications were not sent yet
+// as notified and returns them, oldest first. Claiming is atomic, so with several workers sharing
+// the database each event is notified once.
+func (db *DB) ClaimUnnotifiedStatusEvents(ctx context.Context, limit int64) ([]*models.StatusEvent, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Claim the events before reading them, so the write lock is taken first.
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE events SET notified_at = ?
+		WHERE id IN (SELECT id FROM events WHERE notified_at IS NULL ORDER BY id LIMIT ?)
+		RETURNING id
+	`, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim status events: %w", err)
+	}
+	var ids []any
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan status event: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+statusEventColumns+`
+		FROM events e JOIN apps a ON a.id = e.app_id
+		WHERE e.id IN (?`+strings.Repeat(`, ?`, len(ids)-1)+`)
+		ORDER BY e.id
+	`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status events: %w", err)
+	}
+	events, err := scanStatusEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return events, nil
+}
+
+// queryStatusEvents runs a query selecting statusEventColumns.
+func (db *DB) queryStatusEvents(ctx context.Context, query string, args ...any) ([]*models.StatusEvent, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status events: %w", err)
+	}
+	return scanStatusEvents(rows)
+}
+
+// scanStatusEvents scans and closes rows of statusEventColumns.
+func scanStatusEvents(rows *sql.Rows) ([]*models.StatusEvent, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events []*models.StatusEvent
+	for rows.Next() {
+		event := &models.StatusEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.AppID,
+			&event.GitHubURL,
+			&event.Slug,
+			&event.Deployment,
+			&event.OldStatus,
+			&event.NewStatus,
+			&event.CommitSHA,
+			&event.Details,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// subscriptionSchema creates the table of email subscriptions of visitors to the status changes of
+// an app. Pending subscriptions are confirmed with a token, of which only the SHA-256 hash is kept.
+const subscriptionSchema = `
+	CREATE TABLE IF NOT EXISTS subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		app_id INTEGER NOT NULL,
+		email TEXT NOT NULL,
+		confirm_token_hash TEXT,
+		unsubscribe_token TEXT NOT NULL UNIQUE,
+		confirmed_at DATETIME,
+		confirmation_sent_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (app_id, email),
+		FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_confirm_token_hash ON subscriptions(confirm_token_hash);
+`
+
+// migrateSubscriptions drops the base URL column of older versions, which stored the host of the
+// subscribing request for the links of emails. Links are built from the configured URLs instead.
+func (db *DB) migrateSubscriptions() error {
+	exists, err := db.columnExists("subscriptions", "base_url")
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := db.Exec(`ALTER TABLE subscriptions DROP COLUMN base_url`); err != nil {
+		return fmt.Errorf("failed to drop subscription base URLs: %w", err)
+	}
+	return nil
+}
+
+// ErrSubscriptionNotFound is returned for confirmation and unsubscribe tokens of no subscription.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+const subscriptionColumns = `id, app_id, email, unsubscribe_token, confirmed_at, confirmation_sent_at, created_at`
+
+// scanSubscription scans a row of subscriptionColumns.
+func scanSubscription(row rowScanner) (*models.Subscription, error) {
+	var sub models.Subscription
+	err := row.Scan(&sub.ID, &sub.AppID, &sub.Email, &sub.UnsubscribeToken, &sub.ConfirmedAt, &sub.ConfirmationSentAt, &sub.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetSubscription retrieves the subscription of an email address to an app, or nil if there is
+// none.
+func (db *DB) GetSubscription(ctx context.Context, appID int64, email string) (*models.Subscription, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE app_id = ? AND email = ?`, appID, email)
+	sub, err := scanSubscription(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetSubscriptionByID retrieves a subscription, or nil if there is none.
+func (db *DB) GetSubscriptionByID(ctx context.Context, id int64) (*models.Subscription, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = ?`, id)
+	sub, err := scanSubscription(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// CountPendingSubscriptions counts the unconfirmed subscriptions of an email address whose
+// confirmation was sent since the given time.
+func (db *DB) CountPendingSubscriptions(ctx context.Context, email string, since time.Time) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM subscriptions WHERE email = ? AND confirmed_at IS NULL AND confirmation_sent_at >= ?
+	`, email, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+	return count, nil
+}
+
+// SavePendingSubscription stores an unconfirmed subscription, awaiting confirmation with the token
+// of the given hash, and removes unconfirmed subscriptions whose confirmation was sent before the
+// given time. A pending subscription of the same address to the app is replaced; a confirmed one
+// is kept.
+func (db *DB) SavePendingSubscription(ctx context.Context, sub *models.Subscription, confirmTokenHash string, expired time.Time) error {
+	if _, err := db.ExecContext(ctx, `
+		DELETE FROM subscriptions WHERE confirmed_at IS NULL AND confirmation_sent_at < ?
+	`, expired); err != nil {
+		return fmt.Errorf("failed to delete expired subscriptions: %w", err)
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO subscriptions (app_id, email, confirm_token_hash, unsubscribe_token, confirmation_sent_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(app_id, email) DO UPDATE SET
+			confirm_token_hash = excluded.confirm_token_hash,
+			unsubscribe_token = excluded.unsubscribe_token,
+			confirmation_sent_at = excluded.confirmation_sent_at
+		WHERE subscriptions.confirmed_at IS NULL
+	`, sub.AppID, sub.Email, confirmTokenHash, sub.UnsubscribeToken, sub.ConfirmationSentAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+// ConfirmSubscription confirms the subscription awaiting the token of the given hash, if its
+// confirmation was sent after the given time, and returns it.
+func (db *DB) ConfirmSubscription(ctx context.Context, confirmTokenHash string, notBefore time.Time) (*models.Subscription, error) {
+	row := db.QueryRowContext(ctx, `
+		UPDATE subscriptions SET confirmed_at = ?, confirm_token_hash = NULL
+		WHERE confirm_token_hash = ? AND confirmation_sent_at >= ?
+		RETURNING `+subscriptionColumns, time.Now(), confirmTokenHash, notBefore)
+	sub, err := scanSubscription(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrSubscriptionNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// DeleteSubscription removes the subscription of an unsubscribe token and returns it.
+func (db *DB) DeleteSubscription(ctx context.Context, unsubscribeToken string) (*models.Subscription, error) {
+	row := db.QueryRowContext(ctx, `DELETE FROM subscriptions WHERE unsubscribe_token = ? RETURNING `+subscriptionColumns, unsubscribeToken)
+	sub, err := scanSubscription(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrSubscriptionNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetAppSubscriptions retrieves the confirmed subscriptions to an app.
+func (db *DB) GetAppSubscriptions(ctx context.Context, appID int64) ([]*models.Subscription, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+subscriptionColumns+` FROM subscriptions WHERE app_id = ? AND confirmed_at IS NOT NULL ORDER BY id
+	`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var subs []*models.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return subs, nil
+}
@@ -0,0 +1,454 @@
+// Package github provides access to repository contents and metadata hosted on GitHub.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// DefaultRef is the special ref that resolves to the repository's default branch.
+const DefaultRef = "default"
+
+// commitSHALength is the length of a full hex encoded commit SHA.
+const commitSHALength = 40
+
+// urlPrefix is the prefix of all supported repository URLs.
+const urlPrefix = "https://github.com/"
+
+// defaultBranchTTL is how long resolved default branches are cached.
+const defaultBranchTTL = 1 * time.Hour
+
+// ErrNotFound is returned when the requested file or repository does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrRateLimited is returned while GitHub rate limits are exhausted.
+var ErrRateLimited = errors.New("rate limited by GitHub")
+
+// defaultRateLimitBackoff is used when a rate limited response does not say when to retry.
+const defaultRateLimitBackoff = 1 * time.Minute
+
+// Client fetches files and metadata from GitHub repositories.
+type Client struct {
+	httpClient        *http.Client
+	logger            *slog.Logger
+	cfg               *config.GitHubConfig
+	manifestFilenames []string
+
+	mu              sync.Mutex
+	defaultBranches map[string]cachedBranch
+	owners          map[string]cachedOwner
+	rateLimitReset  time.Time
+}
+
+// Manifest is a fetched ROFL manifest along with the HTTP cache validators of the response.
+type Manifest struct {
+	Data         []byte
+	Path         string // Filename the manifest was found under.
+	ETag         string
+	LastModified string
+	NotModified  bool // The manifest is unchanged since the previous fetch and Data is empty.
+}
+
+// CachedManifest returns the cache validators of an app's previously fetched manifest, or nil if none.
+func CachedManifest(app *models.App) *Manifest {
+	if !app.RoflYAML.Valid || !app.ManifestPath.Valid {
+		return nil
+	}
+	return &Manifest{
+		Path:         app.ManifestPath.String,
+		ETag:         app.ManifestETag.String,
+		LastModified: app.ManifestLastModified.String,
+	}
+}
+
+type cachedBranch struct {
+	branch  string
+	expires time.Time
+}
+
+// NewClient creates a new GitHub client. Manifest filenames are tried in order when fetching manifests.
+func NewClient(httpClient *http.Client, cfg *config.GitHubConfig, manifestFilenames []string, logger *slog.Logger) *Client {
+	return &Client{
+		httpClient:        httpClient,
+		logger:            logger,
+		cfg:               cfg,
+		manifestFilenames: manifestFilenames,
+		defaultBranches:   make(map[string]cachedBranch),
+		owners:            make(map[string]cachedOwner),
+	}
+}
+
+// ParseRepoURL splits a https://github.com/owner/repo URL into owner and repo.
+func ParseRepoURL(repoURL string) (owner, repo string, err error) {
+	path := strings.TrimSuffix(strings.TrimPrefix(repoURL, urlPrefix), "/")
+	if !strings.HasPrefix(repoURL, urlPrefix) || path == "" {
+		return "", "", fmt.Errorf("invalid GitHub URL %q (must be https://github.com/owner/repo)", repoURL)
+	}
+	owner, repo, found := strings.Cut(path, "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid GitHub URL %q (must be https://github.com/owner/repo)", repoURL)
+	}
+	return owner, repo, nil
+}
+
+// RawURL builds the raw.githubusercontent.com URL of a file in a repository at ref.
+// https://github.com/oasisprotocol/wt3 -> https://raw.githubusercontent.com/oasisprotocol/wt3/master/rofl.yaml
+func RawURL(repoURL, ref, filePath string) string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s",
+		strings.TrimSuffix(strings.TrimPrefix(repoURL, urlPrefix), "/"),
+		ref,
+		filePath)
+}
+
+// IsCommitSHA reports whether ref is a full hex encoded commit SHA, which pins verification to a
+// single commit instead of following a branch or tag.
+func IsCommitSHA(ref string) bool {
+	if len(ref) != commitSHALength {
+		return false
+	}
+	for _, c := range ref {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateRef checks that ref can name a branch, tag, or commit, following the rules of
+// git check-ref-format, or is DefaultRef.
+func ValidateRef(ref string) error {
+	switch {
+	case strings.TrimSpace(ref) == "":
+		return fmt.Errorf("ref cannot be empty")
+	case strings.ContainsAny(ref, " \t\n~^:?*[\\") || strings.Contains(ref, "..") || strings.Contains(ref, "@{"),
+		strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".lock"):
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// ResolveRef returns ref unchanged, unless it is DefaultRef in which case the
+// repository's default branch is looked up.
+func (c *Client) ResolveRef(ctx context.Context, repoURL, ref string) (string, error) {
+	if ref != DefaultRef {
+		return ref, nil
+	}
+	return c.DefaultBranch(ctx, repoURL)
+}
+
+// DefaultBranch queries the GitHub API for the default branch of a repository.
+func (c *Client) DefaultBranch(ctx context.Context, repoURL string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.defaultBranches[repoURL]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.branch, nil
+	}
+
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	if err := c.getJSON(ctx, repoURL, apiURL, &result); err != nil {
+		return "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	if result.DefaultBranch == "" {
+		return "", fmt.Errorf("repository has no default branch")
+	}
+
+	c.mu.Lock()
+	c.defaultBranches[repoURL] = cachedBranch{
+		branch:  result.DefaultBranch,
+		expires: time.Now().Add(defaultBranchTTL),
+	}
+	c.mu.Unlock()
+
+	c.logger.Debug("resolved default branch", "github_url", repoURL, "branch", result.DefaultBranch)
+	return result.DefaultBranch, nil
+}
+
+// Repository is the metadata of a GitHub repository.
+type Repository struct {
+	URL      string // Current URL, which differs from the requested one if the repository was renamed or transferred.
+	Stars    int
+	Archived bool // The repository is read-only.
+}
+
+// Moved reports whether the repository was renamed or transferred away from repoURL.
+func (r *Repository) Moved(repoURL string) bool {
+	return r.URL != "" && !strings.EqualFold(strings.TrimSuffix(r.URL, "/"), strings.TrimSuffix(repoURL, "/"))
+}
+
+// Repository queries the GitHub API for the metadata of a repository. Requests for renamed and
+// transferred repositories are redirected by GitHub, so the current URL is returned for them.
+func (c *Client) Repository(ctx context.Context, repoURL string) (*Repository, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		HTMLURL         string `json:"html_url"`
+		StargazersCount int    `json:"stargazers_count"`
+		Archived        bool   `json:"archived"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	if err := c.getJSON(ctx, repoURL, apiURL, &result); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	return &Repository{URL: result.HTMLURL, Stars: result.StargazersCount, Archived: result.Archived}, nil
+}
+
+// ForkParents queries the GitHub API for the repositories a fork was created from: its parent and,
+// for forks of forks, the root repository. It returns nil if the repository is not a fork.
+func (c *Client) ForkParents(ctx context.Context, repoURL string) ([]string, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	type repository struct {
+		HTMLURL string `json:"html_url"`
+	}
+	var result struct {
+		Fork   bool        `json:"fork"`
+		Parent *repository `json:"parent"`
+		Source *repository `json:"source"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	if err := c.getJSON(ctx, repoURL, apiURL, &result); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	if !result.Fork || result.Parent == nil {
+		return nil, nil
+	}
+
+	parents := []string{result.Parent.HTMLURL}
+	if result.Source != nil && result.Source.HTMLURL != result.Parent.HTMLURL {
+		parents = append(parents, result.Source.HTMLURL)
+	}
+	return parents, nil
+}
+
+// FetchFile fetches a file from a repository at ref. Files larger than maxSize are rejected.
+func (c *Client) FetchFile(ctx context.Context, repoURL, ref, filePath string, maxSize int64) ([]byte, error) {
+	file, err := c.fetchFile(ctx, repoURL, ref, filePath, maxSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	return file.Data, nil
+}
+
+// fetchFile fetches a file, making the request conditional on the validators of a previous fetch.
+func (c *Client) fetchFile(ctx context.Context, repoURL, ref, filePath string, maxSize int64, previous *Manifest) (*Manifest, error) {
+	if err := c.checkRateLimit(); err != nil {
+		return nil, err
+	}
+
+	rawURL := RawURL(repoURL, ref, filePath)
+
+	c.logger.Debug("fetching file", "url", rawURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authorize(req, repoURL)
+	if previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if err := c.updateRateLimit(resp); err != nil {
+		return nil, err
+	}
+
+	file := &Manifest{
+		Path:         filePath,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		file.NotModified = true
+		return file, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", filePath, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	// Check if we hit the size limit.
+	if int64(len(data)) >= maxSize {
+		return nil, fmt.Errorf("%s exceeds maximum size of %d bytes", filePath, maxSize)
+	}
+
+	file.Data = data
+	return file, nil
+}
+
+// maxManifestSize limits the size of fetched manifests to prevent memory exhaustion.
+const maxManifestSize = 10 * 1024 * 1024
+
+// FetchManifest fetches the ROFL manifest of a repository at ref, trying each configured
+// filename in order. If previous is given, the previously found filename is tried first
+// with a conditional request, and an unchanged manifest is reported via NotModified.
+func (c *Client) FetchManifest(ctx context.Context, repoURL, ref string, previous *Manifest) (*Manifest, error) {
+	if previous != nil && previous.Path != "" {
+		manifest, err := c.fetchFile(ctx, repoURL, ref, previous.Path, maxManifestSize, previous)
+		switch {
+		case err == nil:
+			return manifest, nil
+		case !errors.Is(err, ErrNotFound):
+			return nil, err
+		}
+	}
+
+	for _, filename := range c.manifestFilenames {
+		manifest, err := c.fetchFile(ctx, repoURL, ref, filename, maxManifestSize, nil)
+		switch {
+		case err == nil:
+			return manifest, nil
+		case errors.Is(err, ErrNotFound):
+			continue
+		default:
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no manifest found (tried %s): %w", strings.Join(c.manifestFilenames, ", "), ErrNotFound)
+}
+
+// RateLimitReset returns when the current rate limit window ends, or the zero time if not rate limited.
+func (c *Client) RateLimitReset() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().After(c.rateLimitReset) {
+		return time.Time{}
+	}
+	return c.rateLimitReset
+}
+
+// checkRateLimit fails fast while a previously observed rate limit is in effect.
+func (c *Client) checkRateLimit() error {
+	if reset := c.RateLimitReset(); !reset.IsZero() {
+		return fmt.Errorf("%w until %s", ErrRateLimited, reset.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// updateRateLimit inspects rate limit headers and records when requests may resume.
+// It returns ErrRateLimited if the response itself was rejected due to rate limiting.
+func (c *Client) updateRateLimit(resp *http.Response) error {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && remaining == "0")
+	if remaining != "0" && !limited {
+		return nil
+	}
+
+	reset := time.Now().Add(defaultRateLimitBackoff)
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		reset = time.Now().Add(time.Duration(retryAfter) * time.Second)
+	} else if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(epoch, 0)
+	}
+
+	c.mu.Lock()
+	if reset.After(c.rateLimitReset) {
+		c.rateLimitReset = reset
+	}
+	c.mu.Unlock()
+
+	c.logger.Warn("GitHub rate limit exhausted, backing off", "reset", reset, "status", resp.StatusCode)
+
+	if limited {
+		return fmt.Errorf("%w until %s", ErrRateLimited, reset.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// authorize adds the configured access token for the repository to the request, if any.
+func (c *Client) authorize(req *http.Request, repoURL string) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return
+	}
+	if token := c.cfg.TokenFor(owner, repo); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// getJSON performs a GitHub API request for a repository and decodes the JSON response.
+func (c *Client) getJSON(ctx context.Context, repoURL, apiURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	c.authorize(req, repoURL)
+
+	if err := c.checkRateLimit(); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if err := c.updateRateLimit(resp); err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that only full hex commit SHAs pin a commit.
+func TestIsCommitSHA(t *testing.T) {
+	if !IsCommitSHA("0123456789abcdef0123456789ABCDEF01234567") {
+		t.Error("Expected full SHA to be a commit SHA")
+	}
+	for _, ref := range []string{"main", "0123456", "0123456789abcdef0123456789abcdef0123456g", "v1.0.0"} {
+		if IsCommitSHA(ref) {
+			t.Errorf("Expected %q not to be a commit SHA", ref)
+		}
+	}
+}
+
+// Test that malformed refs are rejected.
+func TestValidateRef(t *testing.T) {
+	for _, ref := range []string{"main", "default", "release/v1.2", "0123456789abcdef0123456789abcdef01234567"} {
+		if err := ValidateRef(ref); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", ref, err)
+		}
+	}
+	for _, ref := range []string{"", " ", "a..b", "feature branch", "main~1", "/main", "main.lock", "HEAD@{1}"} {
+		if err := ValidateRef(ref); err == nil {
+			t.Errorf("Expected error for %q", ref)
+		}
+	}
+}
+
+// Test comparing repository owners with the publisher claimed by a manifest.
+func TestMatchOwner(t *testing.T) {
+	oasis := &Owner{Login: "oasisprotocol", Name: "Oasis Protocol Foundation", Blog: "oasis.net", Type: "Organization"}
+
+	for _, tc := range []struct {
+		author, homepage string
+		matched          bool
+	}{
+		{"Oasis Protocol Foundation <info@oasisprotocol.org>", "", true},
+		{"Oasis Protocol", "", true},
+		{"", "https://www.oasis.net/wt3", true},
+		{"", "https://rofl.oasis.net", true},
+		{"", "https://github.com/oasisprotocol/wt3", true},
+		{"Someone <dev@oasis.net>", "", true},
+		{"Oasis Protocol Foundation Team", "https://oasis-net.io", false},
+		{"Someone <someone@gmail.com>", "", false},
+		{"", "https://evil.net", false},
+	} {
+		match := MatchOwner(oasis, tc.author, tc.homepage)
+		if match == nil || match.Matched != tc.matched {
+			t.Errorf("MatchOwner(%q, %q) = %+v, expected matched=%v", tc.author, tc.homepage, match, tc.matched)
+		}
+	}
+
+	if match := MatchOwner(oasis, "", ""); match != nil {
+		t.Errorf("Expected no match without claims, got %+v", match)
+	}
+}
+
+// Test that version tags are preferred when several tags point at a commit.
+func TestTagForCommit(t *testing.T) {
+	sha := "3fa9c2d000000000000000000000000000000000"
+	tags := []Tag{
+		{Name: "v1.5.0", CommitSHA: "1111111000000000000000000000000000000000"},
+		{Name: "latest", CommitSHA: sha},
+		{Name: "v1.4.2", CommitSHA: strings.ToUpper(sha)},
+	}
+	if got := TagForCommit(tags, sha); got != "v1.4.2" {
+		t.Errorf("Expected v1.4.2, got %q", got)
+	}
+	if got := TagForCommit(tags[:2], sha); got != "latest" {
+		t.Errorf("Expected latest, got %q", got)
+	}
+	if got := TagForCommit(tags, "2222222000000000000000000000000000000000"); got != "" {
+		t.Errorf("Expected no tag, got %q", got)
+	}
+}
+
+// Test that renames and transfers are detected, but not differences in case.
+func TestRepositoryMoved(t *testing.T) {
+	repo := &Repository{URL: "https://github.com/NewOwner/app"}
+	if !repo.Moved("https://github.com/oldowner/app") {
+		t.Errorf("Expected transfer to be detected")
+	}
+	if repo.Moved("https://github.com/newowner/App/") {
+		t.Errorf("Expected a difference in case not to be a move")
+	}
+	if (&Repository{}).Moved("https://github.com/owner/app") {
+		t.Errorf("Expected unknown URL not to be a move")
+	}
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxIssues is the number of open issues of a repository searched for an issue, the most a single
+// page of the GitHub API returns.
+const maxIssues = 100
+
+// Issue is an issue of a GitHub repository.
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+}
+
+// StatusError is returned when the GitHub API rejects a request.
+type StatusError struct {
+	StatusCode int
+	Message    string // Message of the response, if any.
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitHub returned HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("GitHub returned HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// FindOpenIssue queries the GitHub API for an open issue of a repository with a label and title,
+// or returns nil if none of the most recent ones with the label has the title. Unlike the other
+// requests of the client, issue requests are authorized with the given token, which must be
+// allowed to read the issues of the repository.
+func (c *Client) FindOpenIssue(ctx context.Context, repoURL, token, label, title string) (*Issue, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues?state=open&labels=%s&per_page=%d", owner, repo, url.QueryEscape(label), maxIssues)
+	if err := c.issueRequest(ctx, http.MethodGet, apiURL, token, nil, &issues); err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.Title == title {
+			return issue, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateIssue opens an issue with a label in a repository, authorized with a token allowed to
+// write its issues.
+func (c *Client) CreateIssue(ctx context.Context, repoURL, token, label, title, body string) (*Issue, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	request := struct {
+		Title  string   `json:"title"`
+		Body   string   `json:"body"`
+		Labels []string `json:"labels"`
+	}{Title: title, Body: body, Labels: []string{label}}
+	var issue Issue
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues", owner, repo)
+	if err := c.issueRequest(ctx, http.MethodPost, apiURL, token, request, &issue); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return &issue, nil
+}
+
+// CommentOnIssue comments on an issue of a repository, authorized with a token allowed to write
+// its issues.
+func (c *Client) CommentOnIssue(ctx context.Context, repoURL, token string, number int, body string) error {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	request := struct {
+		Body string `json:"body"`
+	}{Body: body}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/comments", owner, repo, number)
+	if err := c.issueRequest(ctx, http.MethodPost, apiURL, token, request, nil); err != nil {
+		return fmt.Errorf("failed to comment on issue: %w", err)
+	}
+	return nil
+}
+
+// CloseIssue closes an issue of a repository as completed, authorized with a token allowed to
+// write its issues.
+func (c *Client) CloseIssue(ctx context.Context, repoURL, token string, number int) error {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	request := struct {
+		State       string `json:"state"`
+		StateReason string `json:"state_reason"`
+	}{State: "closed", StateReason: "completed"}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", owner, repo, number)
+	if err := c.issueRequest(ctx, http.MethodPatch, apiURL, token, request, nil); err != nil {
+		return fmt.Errorf("failed to close issue: %w", err)
+	}
+	return nil
+}
+
+// issueRequest performs a GitHub API request authorized with a token, sending a JSON request body
+// unless it is nil, and decoding the JSON response into v unless it is nil.
+func (c *Client) issueRequest(ctx context.Context, method, apiURL, token string, body, v any) error {
+	if err := c.checkRateLimit(); err != nil {
+		return err
+	}
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, &reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if err := c.updateRateLimit(resp); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return &StatusError{StatusCode: resp.StatusCode, Message: result.Message}
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
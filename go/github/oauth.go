@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+const (
+	oauthAuthorizeURL = "https://github.com/login/oauth/authorize"
+	oauthTokenURL     = "https://github.com/login/oauth/access_token" // #nosec G101 -- not a credential.
+)
+
+// OAuth signs in GitHub users through an OAuth app and checks their repository permissions.
+type OAuth struct {
+	httpClient *http.Client
+	cfg        *config.GitHubOAuthConfig
+}
+
+// User is a GitHub user signed in through OAuth.
+type User struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// NewOAuth creates a new OAuth client.
+func NewOAuth(httpClient *http.Client, cfg *config.GitHubOAuthConfig) *OAuth {
+	return &OAuth{
+		httpClient: httpClient,
+		cfg:        cfg,
+	}
+}
+
+// AuthorizeURL returns the URL users are sent to for granting access. No scopes are requested,
+// as reading the user profile and repository permissions only needs the public access of a token.
+func (o *OAuth) AuthorizeURL(redirectURL, state string) string {
+	query := url.Values{
+		"client_id":    {o.cfg.ClientID},
+		"redirect_uri": {redirectURL},
+		"state":        {state},
+		"allow_signup": {"false"},
+	}
+	return oauthAuthorizeURL + "?" + query.Encode()
+}
+
+// Exchange exchanges the code GitHub redirected the user back with for an access token.
+func (o *OAuth) Exchange(ctx context.Context, code, redirectURL string) (string, error) {
+	form := url.Values{
+		"client_id":     {o.cfg.ClientID},
+		"client_secret": {o.cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oauthTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("%s: %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("response has no access token")
+	}
+	return token.AccessToken, nil
+}
+
+// User returns the profile of the user an access token belongs to.
+func (o *OAuth) User(ctx context.Context, token string) (*User, error) {
+	var user User
+	if err := o.getJSON(ctx, token, "https://api.github.com/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// IsRepoAdmin reports whether the user an access token belongs to has admin permission on a
+// repository, which proves they control the app it hosts.
+func (o *OAuth) IsRepoAdmin(ctx context.Context, token, repoURL string) (bool, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return false, err
+	}
+
+	var repository struct {
+		Permissions struct {
+			Admin bool `json:"admin"`
+		} `json:"permissions"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	if err := o.getJSON(ctx, token, apiURL, &repository); err != nil {
+		return false, fmt.Errorf("failed to get repository: %w", err)
+	}
+	return repository.Permissions.Admin, nil
+}
+
+// getJSON performs a GitHub API request on behalf of a user and decodes the JSON response.
+func (o *OAuth) getJSON(ctx context.Context, token, apiURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ownerTTL is how long repository owner profiles are cached.
+const ownerTTL = 24 * time.Hour
+
+// Owner is the profile of the user or organization owning a repository.
+type Owner struct {
+	Login     string `json:"login"`
+	Name      string `json:"name"` // Display name, empty if not set.
+	AvatarURL string `json:"avatar_url"`
+	Type      string `json:"type"` // "User" or "Organization".
+	Blog      string `json:"blog"` // Website, empty if not set.
+	Email     string `json:"email"`
+	Verified  bool   `json:"is_verified"` // Organization has a domain verified by GitHub.
+}
+
+type cachedOwner struct {
+	owner   *Owner
+	expires time.Time
+}
+
+// Owner queries the GitHub API for the profile of the owner of a repository.
+func (c *Client) Owner(ctx context.Context, repoURL string) (*Owner, error) {
+	login, _, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.ToLower(login)
+
+	c.mu.Lock()
+	cached, ok := c.owners[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.owner, nil
+	}
+
+	var owner Owner
+	apiURL := fmt.Sprintf("https://api.github.com/users/%s", login)
+	if err := c.getJSON(ctx, repoURL, apiURL, &owner); err != nil {
+		return nil, fmt.Errorf("failed to get owner: %w", err)
+	}
+
+	c.mu.Lock()
+	c.owners[key] = cachedOwner{
+		owner:   &owner,
+		expires: time.Now().Add(ownerTTL),
+	}
+	c.mu.Unlock()
+
+	return &owner, nil
+}
+
+// freeMailDomains are email providers whose domains say nothing about who owns an address.
+var freeMailDomains = map[string]bool{
+	"gmail.com":                true,
+	"googlemail.com":           true,
+	"outlook.com":              true,
+	"hotmail.com":              true,
+	"yahoo.com":                true,
+	"icloud.com":               true,
+	"proton.me":                true,
+	"protonmail.com":           true,
+	"users.noreply.github.com": true,
+}
+
+// OwnerMatch is the outcome of comparing a repository owner with the publisher a manifest claims.
+type OwnerMatch struct {
+	Matched bool
+	Reason  string // What matched, or what the manifest claims if nothing did.
+}
+
+// MatchOwner compares the owner of a repository with the author and homepage claimed by its
+// manifest. The claims match if the author name is the owner's name or login, or if the homepage
+// or author email is on a domain of the owner's website or email. A mismatch may mean the
+// repository is a copy registered by someone other than the claimed publisher. It returns nil if
+// the manifest makes no claim to compare.
+func MatchOwner(owner *Owner, author, homepage string) *OwnerMatch {
+	authorName, authorEmail := author, ""
+	if addr, err := mail.ParseAddress(author); err == nil {
+		authorName, authorEmail = addr.Name, addr.Address
+	}
+
+	var claimed []string
+	if authorName = strings.TrimSpace(authorName); authorName != "" {
+		claimed = append(claimed, fmt.Sprintf("author %q", authorName))
+		if name := normalizeName(authorName); name != "" && (name == normalizeName(owner.Name) || name == normalizeName(owner.Login)) {
+			return &OwnerMatch{Matched: true, Reason: "author matches the repository owner"}
+		}
+	}
+
+	ownerDomains := ownerDomains(owner)
+	if host := hostOf(homepage); host != "" {
+		claimed = append(claimed, "homepage "+host)
+		if login, ok := strings.CutSuffix(host, ".github.io"); ok && strings.EqualFold(login, owner.Login) {
+			return &OwnerMatch{Matched: true, Reason: "homepage is the owner's GitHub Pages site"}
+		}
+		if login, _, _ := strings.Cut(strings.TrimPrefix(homepage, urlPrefix), "/"); host == "github.com" && strings.EqualFold(login, owner.Login) {
+			return &OwnerMatch{Matched: true, Reason: "homepage is on the owner's GitHub account"}
+		}
+		if matchesDomain(host, ownerDomains) {
+			return &OwnerMatch{Matched: true, Reason: "homepage is on the owner's domain"}
+		}
+	}
+	if _, domain, ok := strings.Cut(authorEmail, "@"); ok && !freeMailDomains[strings.ToLower(domain)] {
+		claimed = append(claimed, "email domain "+strings.ToLower(domain))
+		if matchesDomain(strings.ToLower(domain), ownerDomains) {
+			return &OwnerMatch{Matched: true, Reason: "author email is on the owner's domain"}
+		}
+	}
+
+	if len(claimed) == 0 {
+		return nil
+	}
+	return &OwnerMatch{Reason: "manifest claims " + strings.Join(claimed, ", ")}
+}
+
+// ownerDomains returns the domains of an owner's website and non-free email address.
+func ownerDomains(owner *Owner) []string {
+	var domains []string
+	blog := owner.Blog
+	if blog != "" && !strings.Contains(blog, "://") {
+		blog = "https://" + blog
+	}
+	if host := hostOf(blog); host != "" {
+		domains = append(domains, host)
+	}
+	if _, domain, ok := strings.Cut(owner.Email, "@"); ok && !freeMailDomains[strings.ToLower(domain)] {
+		domains = append(domains, strings.ToLower(domain))
+	}
+	return domains
+}
+
+// hostOf returns the lowercased host of a URL without a leading www., or an empty string.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// matchesDomain reports whether host is one of the domains or a subdomain of one.
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) || strings.HasSuffix(domain, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeName lowercases a name and strips everything but letters and digits, so that
+// "Oasis Protocol" matches the login "oasisprotocol".
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
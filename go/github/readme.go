@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// readmeFilenames are the README filenames tried, in order.
+var readmeFilenames = []string{"README.md", "readme.md", "Readme.md", "README.markdown", "README"}
+
+// maxReadmeSize limits the size of fetched READMEs.
+const maxReadmeSize = 1 * 1024 * 1024
+
+// FetchReadme fetches the README of a repository at ref.
+func (c *Client) FetchReadme(ctx context.Context, repoURL, ref string) ([]byte, error) {
+	for _, filename := range readmeFilenames {
+		data, err := c.FetchFile(ctx, repoURL, ref, filename, maxReadmeSize)
+		switch {
+		case err == nil:
+			return data, nil
+		case errors.Is(err, ErrNotFound):
+			continue
+		default:
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no README found: %w", ErrNotFound)
+}
+
+var (
+	readmeImage     = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	readmeLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	readmeRefLink   = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	readmeHTMLTag   = regexp.MustCompile(`<[^>]*>`)
+	readmeEmphasis  = regexp.MustCompile("\\*\\*|__|`")
+	readmeLinkDef   = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+	readmeListItem  = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+	readmeHeading   = regexp.MustCompile(`^#{1,6}\s+`)
+	readmeRule      = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,}|={3,})$`)
+	readmeSpaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// ReadmeExcerpt extracts a plain text excerpt of about maxLen bytes from a Markdown README.
+// Code blocks, tables, images, and HTML are dropped and links reduced to their text. The excerpt
+// consists of blocks separated by blank lines; headings start with "# " and list items with "- ".
+func ReadmeExcerpt(readme []byte, maxLen int) string {
+	var blocks []string
+	var paragraph []string
+	length := 0
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, strings.Join(paragraph, " "))
+			length += len(blocks[len(blocks)-1])
+			paragraph = nil
+		}
+	}
+	add := func(block string) {
+		flush()
+		blocks = append(blocks, block)
+		length += len(block)
+	}
+
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(readme), "\r\n", "\n"), "\n") {
+		if length >= maxLen {
+			break
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		switch {
+		case trimmed == "" || readmeRule.MatchString(trimmed):
+			flush()
+			continue
+		case strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, ">") || readmeLinkDef.MatchString(trimmed):
+			flush()
+			continue
+		}
+
+		text := cleanReadmeLine(trimmed)
+		switch {
+		case readmeHeading.MatchString(trimmed):
+			text = cleanReadmeLine(readmeHeading.ReplaceAllString(trimmed, ""))
+			if text != "" {
+				add("# " + text)
+			}
+		case readmeListItem.MatchString(trimmed):
+			text = cleanReadmeLine(readmeListItem.ReplaceAllString(trimmed, ""))
+			if text != "" {
+				add("- " + text)
+			}
+		case text != "":
+			paragraph = append(paragraph, text)
+		}
+	}
+	flush()
+
+	// The first heading usually repeats the app name shown next to the excerpt.
+	if len(blocks) > 0 && strings.HasPrefix(blocks[0], "# ") {
+		blocks = blocks[1:]
+	}
+
+	excerpt := strings.Join(blocks, "\n\n")
+	if len(excerpt) > maxLen {
+		excerpt = strings.ToValidUTF8(excerpt[:maxLen], "")
+		if i := strings.LastIndexByte(excerpt, ' '); i > maxLen/2 {
+			excerpt = excerpt[:i]
+		}
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// cleanReadmeLine strips Markdown and HTML markup from a line of text.
+func cleanReadmeLine(line string) string {
+	line = readmeImage.ReplaceAllString(line, "")
+	line = readmeLink.ReplaceAllString(line, "$1")
+	line = readmeRefLink.ReplaceAllString(line, "$1")
+	line = readmeHTMLTag.ReplaceAllString(line, "")
+	line = readmeEmphasis.ReplaceAllString(line, "")
+	return strings.TrimSpace(readmeSpaceRuns.ReplaceAllString(line, " "))
+}
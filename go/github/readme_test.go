@@ -0,0 +1,47 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadmeExcerpt(t *testing.T) {
+	readme := strings.Join([]string{
+		"# My App",
+		"",
+		"[![CI](https://example.com/badge.svg)](https://example.com/ci)",
+		"<p align=\"center\"><img src=\"logo.png\"></p>",
+		"",
+		"A **confidential** oracle running in a",
+		"[ROFL](https://docs.oasis.io) enclave.",
+		"",
+		"## Features",
+		"",
+		"- Fetches `prices` <script>alert(1)</script>",
+		"* Signs them",
+		"",
+		"```bash",
+		"make build",
+		"```",
+		"",
+		"| a | b |",
+		"|---|---|",
+	}, "\n")
+
+	want := "A confidential oracle running in a ROFL enclave.\n\n# Features\n\n- Fetches prices alert(1)\n\n- Signs them"
+	if got := ReadmeExcerpt([]byte(readme), 1000); got != want {
+		t.Errorf("ReadmeExcerpt() = %q, want %q", got, want)
+	}
+}
+
+func TestReadmeExcerptTruncates(t *testing.T) {
+	readme := strings.Repeat("word ", 100)
+
+	got := ReadmeExcerpt([]byte(readme), 42)
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("ReadmeExcerpt() = %q, want truncated excerpt", got)
+	}
+	if len(got) > 42+len("…") {
+		t.Errorf("ReadmeExcerpt() length = %d, want at most %d", len(got), 42+len("…"))
+	}
+}
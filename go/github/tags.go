@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxTags is the number of tags of a repository searched for a commit, the most a single page of
+// the GitHub API returns.
+const maxTags = 100
+
+// Tag is a git tag of a repository.
+type Tag struct {
+	Name      string
+	CommitSHA string
+}
+
+// versionTag matches tag names that look like release versions, e.g. "v1.4.2" or "2.0.0-rc1".
+var versionTag = regexp.MustCompile(`^v?\d+(\.\d+)*([-+].*)?$`)
+
+// Tags queries the GitHub API for the most recent tags of a repository.
+func (c *Client) Tags(ctx context.Context, repoURL string) ([]Tag, error) {
+	owner, repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/tags?per_page=%d", owner, repo, maxTags)
+	if err := c.getJSON(ctx, repoURL, apiURL, &result); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tags := make([]Tag, 0, len(result))
+	for _, tag := range result {
+		tags = append(tags, Tag{Name: tag.Name, CommitSHA: tag.Commit.SHA})
+	}
+	return tags, nil
+}
+
+// CommitTag queries the GitHub API for a tag pointing at a commit, or returns an empty string if
+// none of the most recent tags does.
+func (c *Client) CommitTag(ctx context.Context, repoURL, commitSHA string) (string, error) {
+	tags, err := c.Tags(ctx, repoURL)
+	if err != nil {
+		return "", err
+	}
+	return TagForCommit(tags, commitSHA), nil
+}
+
+// TagForCommit returns the name of a tag pointing at a commit, preferring tags that look like
+// release versions, or an empty string if there is none. Tags are compared in the order given.
+func TagForCommit(tags []Tag, commitSHA string) string {
+	var found string
+	for _, tag := range tags {
+		if !strings.EqualFold(tag.CommitSHA, commitSHA) {
+			continue
+		}
+		if versionTag.MatchString(tag.Name) {
+			return tag.Name
+		}
+		if found == "" {
+			found = tag.Name
+		}
+	}
+	return found
+}
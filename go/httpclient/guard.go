@@ -0,0 +1,163 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBlockedDestination is returned for requests of untrusted clients to URLs that are not https
+// or to hosts that are not public.
+var ErrBlockedDestination = errors.New("destination not allowed")
+
+// blockedPrefixes are ranges of global unicast addresses that are not publicly reachable.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network.
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT.
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments.
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking.
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved.
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which may translate to private IPv4 addresses.
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// publicAddr returns whether an address is publicly reachable.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL returns an error wrapping ErrBlockedDestination unless a URL derived from a manifest or
+// user input is an https URL whose host may be public. Host names are resolved and checked when
+// requests are sent.
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid URL: %w", ErrBlockedDestination, err)
+	}
+	return checkURL(u)
+}
+
+// checkURL implements CheckURL.
+func checkURL(u *url.URL) error {
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	switch {
+	case u.Scheme != "https":
+		return fmt.Errorf("%w: %q is not an https URL", ErrBlockedDestination, u.Redacted())
+	case host == "":
+		return fmt.Errorf("%w: %q has no host", ErrBlockedDestination, u.Redacted())
+	case host == "localhost" || strings.HasSuffix(host, ".localhost"):
+		return fmt.Errorf("%w: %s is not a public host", ErrBlockedDestination, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return fmt.Errorf("%w: %s is not a public address", ErrBlockedDestination, host)
+	}
+	return nil
+}
+
+// resolvePublic resolves a host name, returning an error wrapping ErrBlockedDestination if any of
+// its addresses is not public.
+func resolvePublic(ctx context.Context, host string) ([]netip.Addr, error) {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrBlockedDestination, host, addr)
+		}
+	}
+	return addrs, nil
+}
+
+// guardTransport rejects requests to URLs that are not https or to hosts that are not public,
+// including when following redirects.
+type guardTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *guardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newGuardedTransport creates the transport of untrusted clients from the shared one. It connects
+// to the addresses it checked, so that a host name cannot resolve to a public address when checked
+// and to a private one when connecting. Requests through a proxy are checked before they are sent,
+// but the proxy resolves host names again.
+func newGuardedTransport(base *http.Transport) http.RoundTripper {
+	transport := base.Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	// Proxies are configured by the operator, so connections to them are not checked.
+	var proxies sync.Map
+	proxy := transport.Proxy
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if proxyURL == nil || err != nil {
+			return proxyURL, err
+		}
+		if _, err := resolvePublic(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		proxies.Store(proxyAddr(proxyURL), true)
+		return proxyURL, nil
+	}
+
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if _, ok := proxies.Load(address); ok {
+			return dialer.DialContext(ctx, network, address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := resolvePublic(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+	return &guardTransport{base: transport}
+}
+
+// proxyAddr returns the address connections to a proxy are dialed at.
+func proxyAddr(proxyURL *url.URL) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+	port := "80"
+	switch proxyURL.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
@@ -0,0 +1,211 @@
+// Package httpclient creates the HTTP clients of outbound requests, with the configured proxy,
+// timeouts, retry policy, and User-Agent.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Version of the registry, sent in the User-Agent. It is taken from the build info unless set at
+// link time with -ldflags "-X github.com/ptrus/rofl-attestations/httpclient.Version=v1.2.3".
+var Version = buildVersion()
+
+// buildVersion returns the module version the binary was built at, or the commit if it was built
+// from a work tree.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return setting.Value[:12]
+		}
+	}
+	return "dev"
+}
+
+// DefaultUserAgent is the User-Agent of outbound requests unless configured otherwise.
+func DefaultUserAgent() string {
+	return "rofl-registry/" + Version + " (+https://github.com/ptrus/rofl-attestations)"
+}
+
+// Destination is a kind of outbound request with its own timeout and retry policy.
+type Destination string
+
+// Destinations of outbound requests.
+const (
+	GitHub   Destination = "github"   // GitHub API and raw content, including the apps registry.
+	Backend  Destination = "backend"  // Verification backend.
+	Storage  Destination = "storage"  // Object storage of artifacts.
+	External Destination = "external" // Everything else, e.g. container registries, logos, webhooks, and Nexus.
+)
+
+// maxRetryAfter caps how long a Retry-After header may delay a retry.
+const maxRetryAfter = 30 * time.Second
+
+// Factory creates the HTTP clients of outbound requests. Clients share the connections of their
+// transport.
+type Factory struct {
+	cfg       *config.HTTPConfig
+	userAgent string
+	transport http.RoundTripper
+	backend   http.RoundTripper
+	untrusted http.RoundTripper
+}
+
+// New creates a factory of HTTP clients. Requests to the verification backend present the
+// configured client certificate and trust the configured CAs.
+func New(cfg *config.HTTPConfig, backendTLS *config.BackendTLSConfig) (*Factory, error) {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	backend, err := NewBackendTransport(cfg, backendTLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend transport: %w", err)
+	}
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+	return &Factory{
+		cfg:       cfg,
+		userAgent: userAgent,
+		transport: transport,
+		backend:   backend,
+		untrusted: newGuardedTransport(transport),
+	}, nil
+}
+
+// Client returns a new client for requests to a destination.
+func (f *Factory) Client(dest Destination) *http.Client {
+	transport := f.transport
+	if dest == Backend {
+		transport = f.backend
+	}
+	return f.ClientWithTransport(dest, transport)
+}
+
+// Untrusted returns a new client for URLs derived from manifests or user input, e.g. logos,
+// homepages, container registries, and webhooks, with the policy of External requests. It only
+// sends https requests to public addresses, including when following redirects.
+func (f *Factory) Untrusted() *http.Client {
+	return f.ClientWithTransport(External, f.untrusted)
+}
+
+// ClientWithTransport returns a new client for requests to a destination, sent with the given
+// transport instead of the shared one, e.g. to serve them from fixtures.
+func (f *Factory) ClientWithTransport(dest Destination, transport http.RoundTripper) *http.Client {
+	policy := f.policy(dest)
+	return &http.Client{
+		Transport: &retryTransport{
+			base:      transport,
+			userAgent: f.userAgent,
+			retries:   max(policy.Retries, 0),
+			backoff:   time.Duration(policy.RetryBackoff) * time.Millisecond,
+		},
+		Timeout: time.Duration(policy.Timeout) * time.Second,
+	}
+}
+
+// policy returns the timeout and retry policy of a destination.
+func (f *Factory) policy(dest Destination) config.HTTPClientConfig {
+	switch dest {
+	case GitHub:
+		return f.cfg.GitHub
+	case Backend:
+		return f.cfg.Backend
+	case Storage:
+		return f.cfg.Storage
+	default:
+		return f.cfg.External
+	}
+}
+
+// retryTransport sets the User-Agent of requests and retries idempotent requests that fail with a
+// network error or a 502, 503, or 504, with exponential backoff.
+type retryTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	retries   int
+	backoff   time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	retries := t.retries
+	if !idempotent(req) {
+		retries = 0
+	}
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= retries || !retryable(resp, err) {
+			return resp, err
+		}
+
+		wait := backoff
+		if resp != nil {
+			if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && after >= 0 {
+				wait = min(time.Duration(after)*time.Second, maxRetryAfter)
+			}
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// idempotent returns whether a request may be sent again.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody
+	default:
+		return false
+	}
+}
+
+// retryable returns whether a failed attempt may succeed when retried.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrBlockedDestination)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Test that GET requests are retried on 503 while POST requests are not, and that requests carry the User-Agent.
+func TestRetryTransport(t *testing.T) {
+	var attempts int
+	transport := &retryTransport{
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if ua := req.Header.Get("User-Agent"); ua != "test-agent" {
+				t.Errorf("Unexpected User-Agent %q", ua)
+			}
+			status := http.StatusServiceUnavailable
+			if attempts == 3 {
+				status = http.StatusOK
+			}
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+		userAgent: "test-agent",
+		retries:   2,
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got %d attempts: %v", attempts, err)
+	}
+
+	attempts = 0
+	req, _ = http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("{}"))
+	resp, err = transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || attempts != 1 {
+		t.Errorf("Expected a single attempt of POST, got %d attempts: %v", attempts, err)
+	}
+}
+
+// Test that untrusted clients only request https URLs of public hosts.
+func TestCheckURL(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://example.com/logo.png", true},
+		{"https://93.184.215.14/", true},
+		{"https://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]/", true},
+		{"http://example.com/", false},
+		{"file:///etc/passwd", false},
+		{"https://localhost:8080/", false},
+		{"https://api.localhost./", false},
+		{"https://127.0.0.1/", false},
+		{"https://10.0.0.1/", false},
+		{"https://169.254.169.254/latest/meta-data/", false},
+		{"https://100.64.0.1/", false},
+		{"https://[::1]/", false},
+		{"https://[::ffff:192.168.1.1]/", false},
+		{"https://[fd00::1]/", false},
+		{"https://0.0.0.0/", false},
+	} {
+		if err := CheckURL(tc.url); (err == nil) != tc.allowed {
+			t.Errorf("CheckURL(%q) = %v, expected allowed %v", tc.url, err, tc.allowed)
+		}
+	}
+
+	// Host names are checked when connecting.
+	transport := newGuardedTransport(&http.Transport{Proxy: http.ProxyFromEnvironment}).(*guardTransport).base.(*http.Transport)
+	if _, err := transport.DialContext(context.Background(), "tcp", "localhost:443"); !errors.Is(err, ErrBlockedDestination) {
+		t.Errorf("Expected connecting to localhost to be blocked, got %v", err)
+	}
+}
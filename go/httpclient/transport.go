@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// NewTransport creates the HTTP transport used for outbound requests. Requests go through
+// the configured proxy, or else the proxy given by the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+// environment variables.
+func NewTransport(cfg *config.HTTPConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if cfg.ProxyURL == "" {
+		return transport, nil
+	}
+
+	proxyURL, err := url.Parse(cfg.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	noProxy := strings.Split(cfg.NoProxy, ",")
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+	return transport, nil
+}
+
+// bypassProxy returns whether host matches one of the no_proxy entries: "*", a host name,
+// a domain (".example.com" or "example.com", matching subdomains too), an IP address, or a CIDR range.
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			if entry == host {
+				return true
+			}
+		default:
+			domain := strings.TrimPrefix(entry, ".")
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewBackendTransport creates the HTTP transport used for requests to the verification backend,
+// presenting a client certificate and trusting a custom CA bundle if configured.
+func NewBackendTransport(httpCfg *config.HTTPConfig, cfg *config.BackendTLSConfig) (http.RoundTripper, error) {
+	transport, err := NewTransport(httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled() {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,155 @@
+// Package i18n holds the message catalogs the web UI is translated with. A catalog is a JSON file
+// in locales/ named by its language tag, mapping message keys to messages in that language. The
+// English catalog is the source every other catalog translates; messages missing from a
+// translation fall back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language of the source catalog, served when no supported language is accepted.
+const Default = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// Catalog is the messages of a language.
+type Catalog struct {
+	Language string
+	messages map[string]string
+	fallback *Catalog // Source catalog, nil for the source catalog itself.
+}
+
+// catalogs are the supported languages by tag.
+var catalogs = mustLoad()
+
+// mustLoad loads the embedded catalogs.
+func mustLoad() map[string]*Catalog {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+
+	loaded := make(map[string]*Catalog, len(entries))
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", entry.Name(), err))
+		}
+		language := strings.ToLower(strings.TrimSuffix(entry.Name(), ".json"))
+		catalog := &Catalog{Language: language}
+		if err := json.Unmarshal(data, &catalog.messages); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse %s: %v", entry.Name(), err))
+		}
+		loaded[language] = catalog
+	}
+
+	source, ok := loaded[Default]
+	if !ok {
+		panic("i18n: missing source catalog")
+	}
+	for language, catalog := range loaded {
+		if language != Default {
+			catalog.fallback = source
+		}
+	}
+	return loaded
+}
+
+// Languages returns the tags of the supported languages, in order.
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Lookup returns the catalog of a supported language, or of the default language if the
+// language is not supported.
+func Lookup(language string) *Catalog {
+	if catalog, ok := catalogs[Supported(language)]; ok {
+		return catalog
+	}
+	return catalogs[Default]
+}
+
+// Supported returns the supported language matching a language tag, falling back from a
+// regional variant such as pt-BR to its language, or "" if neither is supported.
+func Supported(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	if language, _, ok := strings.Cut(tag, "-"); ok {
+		if _, ok := catalogs[language]; ok {
+			return language
+		}
+	}
+	return ""
+}
+
+// Negotiate returns the supported language of an Accept-Language header with the highest weight,
+// or the default language if none is supported.
+func Negotiate(acceptLanguage string) string {
+	best, bestWeight := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if weight, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if language := Supported(tag); language != "" && weight > bestWeight {
+			best, bestWeight = language, weight
+		}
+	}
+	return best
+}
+
+// Has reports whether the source catalog defines a message.
+func Has(key string) bool {
+	_, ok := catalogs[Default].messages[key]
+	return ok
+}
+
+// Message returns the message of a key, falling back to the source catalog, or the key itself
+// if it is not defined at all.
+func (c *Catalog) Message(key string) string {
+	if message, ok := c.messages[key]; ok {
+		return message
+	}
+	if c.fallback != nil {
+		return c.fallback.Message(key)
+	}
+	return key
+}
+
+// T returns a message formatted with the given arguments, as by fmt.Sprintf.
+func (c *Catalog) T(key string, args ...any) string {
+	if len(args) == 0 {
+		return c.Message(key)
+	}
+	return fmt.Sprintf(c.Message(key), args...)
+}
+
+// Parts splits a message at its %s placeholders, so that markup can be placed between the
+// parts. It returns one more part than the source message has placeholders.
+func (c *Catalog) Parts(key string) []string {
+	parts := strings.Split(c.Message(key), "%s")
+	n := strings.Count(catalogs[Default].Message(key), "%s") + 1
+	for len(parts) < n {
+		parts = append(parts, "")
+	}
+	return parts[:n]
+}
@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// verbs matches the formatting verbs of a message.
+var verbs = regexp.MustCompile(`%[a-z%]`)
+
+// Test that translations only define messages of the source catalog, with the same arguments.
+func TestCatalogs(t *testing.T) {
+	source := catalogs[Default]
+	for language, catalog := range catalogs {
+		for key, message := range catalog.messages {
+			expected, ok := source.messages[key]
+			if !ok {
+				t.Errorf("%s: unknown message %q", language, key)
+				continue
+			}
+			if got, want := verbs.FindAllString(message, -1), verbs.FindAllString(expected, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: message %q has verbs %v, expected %v", language, key, got, want)
+			}
+		}
+	}
+}
+
+// Test matching language tags and Accept-Language headers to supported languages.
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		tag      string
+		expected string
+	}{
+		{"en", "en"},
+		{"EN-us", "en"},
+		{"xx", ""},
+		{"", ""},
+	} {
+		if got := Supported(tc.tag); got != tc.expected {
+			t.Errorf("Supported(%q): expected %q, got %q", tc.tag, tc.expected, got)
+		}
+	}
+
+	for _, tc := range []struct {
+		header   string
+		expected string
+	}{
+		{"", Default},
+		{"xx, en-GB;q=0.8", "en"},
+		{"xx;q=0.9, yy", Default},
+		{"en;q=invalid", Default},
+	} {
+		if got := Negotiate(tc.header); got != tc.expected {
+			t.Errorf("Negotiate(%q): expected %q, got %q", tc.header, tc.expected, got)
+		}
+	}
+}
+
+// Test the fallback to the source catalog and splitting messages around placeholders.
+func TestCatalog(t *testing.T) {
+	catalog := &Catalog{
+		Language: "xx",
+		messages: map[string]string{"details.requires_cli": "Nur mit %s."},
+		fallback: catalogs[Default],
+	}
+	if got := catalog.T("status.verified"); got != "Verified" {
+		t.Errorf("Expected fallback to the source message, got %q", got)
+	}
+	if got := catalog.T("summary.verified", "mainnet"); got != "Verified on mainnet." {
+		t.Errorf("Expected formatted source message, got %q", got)
+	}
+	if got := catalog.Parts("details.requires_cli"); !slices.Equal(got, []string{"Nur mit ", "."}) {
+		t.Errorf("Expected message parts, got %q", got)
+	}
+	if got := catalog.Message("no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected the key of an undefined message, got %q", got)
+	}
+}
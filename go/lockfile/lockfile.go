@@ -0,0 +1,315 @@
+// Package lockfile summarizes dependency lockfiles, so that a verified build can be tied to the
+// exact set of dependencies it was built from.
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Filenames lists the supported lockfiles.
+var Filenames = []string{"Cargo.lock", "go.sum", "package-lock.json"}
+
+// Ecosystem returns the package ecosystem of a lockfile, or "" if it is not supported.
+func Ecosystem(filePath string) string {
+	switch path.Base(filePath) {
+	case "Cargo.lock":
+		return "cargo"
+	case "go.sum":
+		return "go"
+	case "package-lock.json":
+		return "npm"
+	default:
+		return ""
+	}
+}
+
+// Digest returns the hex encoded SHA-256 digest of a lockfile.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse summarizes a lockfile. The direct dependencies of Go modules are declared in go.mod
+// rather than go.sum; see GoModDirect.
+func Parse(filePath string, data []byte) (*models.Lockfile, error) {
+	lockfile := &models.Lockfile{
+		Path:      filePath,
+		Ecosystem: Ecosystem(filePath),
+		SHA256:    Digest(data),
+	}
+
+	var err error
+	switch lockfile.Ecosystem {
+	case "cargo":
+		lockfile.Dependencies, lockfile.Direct, err = parseCargoLock(data)
+	case "go":
+		lockfile.Dependencies, err = parseGoSum(data)
+	case "npm":
+		lockfile.Dependencies, lockfile.Direct, err = parsePackageLock(data)
+	default:
+		err = fmt.Errorf("unsupported lockfile")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return lockfile, nil
+}
+
+// cargoPackage is a [[package]] entry of a Cargo.lock file.
+type cargoPackage struct {
+	name         string
+	version      string
+	source       string
+	dependencies []string
+}
+
+// parseCargoLock counts the packages of a Cargo.lock file, excluding the local workspace
+// packages, whose dependencies are the direct ones.
+func parseCargoLock(data []byte) (int, []string, error) {
+	var (
+		packages []*cargoPackage
+		current  *cargoPackage
+		inDeps   bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case line == "[[package]]":
+			current = &cargoPackage{}
+			packages = append(packages, current)
+			inDeps = false
+			continue
+		case strings.HasPrefix(line, "["):
+			// Other tables, e.g. [metadata].
+			current = nil
+			inDeps = false
+			continue
+		case current == nil:
+			continue
+		}
+
+		if inDeps {
+			if strings.HasPrefix(line, "]") {
+				inDeps = false
+				continue
+			}
+			current.dependencies = append(current.dependencies, unquote(strings.TrimSuffix(line, ",")))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.name = unquote(value)
+		case "version":
+			current.version = unquote(value)
+		case "source":
+			current.source = unquote(value)
+		case "dependencies":
+			// Either inline, dependencies = ["a", "b"], or one per line.
+			if value == "[" {
+				inDeps = true
+				continue
+			}
+			for _, dep := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if dep = strings.TrimSpace(dep); dep != "" {
+					current.dependencies = append(current.dependencies, unquote(dep))
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to read: %w", err)
+	}
+	if len(packages) == 0 {
+		return 0, nil, fmt.Errorf("no packages found")
+	}
+
+	versions := make(map[string][]string)
+	for _, pkg := range packages {
+		versions[pkg.name] = append(versions[pkg.name], pkg.version)
+	}
+
+	var (
+		count  int
+		direct = make(map[string]bool)
+	)
+	for _, pkg := range packages {
+		if pkg.source != "" {
+			count++
+			continue
+		}
+		// Dependencies are "name", or "name version" if several versions are locked.
+		for _, dep := range pkg.dependencies {
+			fields := strings.Fields(dep)
+			if len(fields) == 0 {
+				continue
+			}
+			name, version := fields[0], ""
+			if len(fields) > 1 {
+				version = fields[1]
+			} else if v := versions[name]; len(v) == 1 {
+				version = v[0]
+			}
+			direct[nameVersion(name, version)] = true
+		}
+	}
+	// Workspace packages depend on each other as well.
+	for _, pkg := range packages {
+		if pkg.source == "" {
+			delete(direct, nameVersion(pkg.name, pkg.version))
+		}
+	}
+
+	return count, sortedKeys(direct), nil
+}
+
+// parseGoSum counts the module versions of a go.sum file. Entries of only a module's go.mod are
+// not counted, as its code is not part of the build.
+func parseGoSum(data []byte) (int, error) {
+	modules := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		modules[fields[0]+"@"+fields[1]] = true
+	}
+	return len(modules), nil
+}
+
+// GoModDirect returns the direct requirements of a go.mod file.
+func GoModDirect(data []byte) []string {
+	var (
+		direct  []string
+		inBlock bool
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		line, _, _ = strings.Cut(line, "//")
+		if fields := strings.Fields(line); len(fields) == 2 {
+			direct = append(direct, nameVersion(fields[0], fields[1]))
+		}
+	}
+	sort.Strings(direct)
+	return direct
+}
+
+// packageLock is a package-lock.json file. Version 1 lists nested dependencies, versions 2 and 3
+// list installed packages by their node_modules path.
+type packageLock struct {
+	LockfileVersion int                           `json:"lockfileVersion"`
+	Packages        map[string]packageLockPackage `json:"packages"`
+	Dependencies    map[string]packageLockDep     `json:"dependencies"`
+}
+
+// packageLockPackage is an installed package of a version 2 or 3 lockfile.
+type packageLockPackage struct {
+	Version      string            `json:"version"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// packageLockDep is a dependency of a version 1 lockfile.
+type packageLockDep struct {
+	Version      string                    `json:"version"`
+	Dev          bool                      `json:"dev"`
+	Dependencies map[string]packageLockDep `json:"dependencies"`
+}
+
+// parsePackageLock counts the packages of a package-lock.json file, and returns the dependencies
+// of the root package as the direct ones.
+func parsePackageLock(data []byte) (int, []string, error) {
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	direct := make(map[string]bool)
+	if lock.Packages != nil {
+		count := 0
+		for pkgPath := range lock.Packages {
+			if pkgPath != "" {
+				count++
+			}
+		}
+		for name := range lock.Packages[""].Dependencies {
+			direct[nameVersion(name, lock.Packages["node_modules/"+name].Version)] = true
+		}
+		return count, sortedKeys(direct), nil
+	}
+
+	var count func(deps map[string]packageLockDep) int
+	count = func(deps map[string]packageLockDep) int {
+		n := len(deps)
+		for _, dep := range deps {
+			n += count(dep.Dependencies)
+		}
+		return n
+	}
+	for name, dep := range lock.Dependencies {
+		if !dep.Dev {
+			direct[nameVersion(name, dep.Version)] = true
+		}
+	}
+	return count(lock.Dependencies), sortedKeys(direct), nil
+}
+
+// nameVersion formats a dependency as name@version.
+func nameVersion(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+// unquote removes the double quotes around a TOML string.
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"`)
+}
+
+// sortedKeys returns the keys of a set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
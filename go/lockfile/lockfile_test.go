@@ -0,0 +1,151 @@
+package lockfile
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test summarizing a Cargo.lock of a workspace with two members.
+func TestParse_CargoLock(t *testing.T) {
+	data := `# This file is automatically @generated by Cargo.
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "common",
+ "serde",
+ "tokio 1.35.0",
+]
+
+[[package]]
+name = "common"
+version = "0.1.0"
+dependencies = ["serde"]
+
+[[package]]
+name = "serde"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "25dd9975e68d0cb5aa1120c288333fc98731bd1dd12f561e468ea4728c042b89"
+
+[[package]]
+name = "tokio"
+version = "1.35.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "tokio"
+version = "0.2.25"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`
+
+	lockfile, err := Parse("Cargo.lock", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if lockfile.Ecosystem != "cargo" {
+		t.Errorf("Expected ecosystem cargo, got %q", lockfile.Ecosystem)
+	}
+	if lockfile.Dependencies != 3 {
+		t.Errorf("Expected 3 dependencies, got %d", lockfile.Dependencies)
+	}
+	if expected := []string{"serde@1.0.193", "tokio@1.35.0"}; !slices.Equal(lockfile.Direct, expected) {
+		t.Errorf("Expected direct dependencies %v, got %v", expected, lockfile.Direct)
+	}
+	if lockfile.SHA256 != Digest([]byte(data)) || len(lockfile.SHA256) != 64 {
+		t.Errorf("Unexpected digest %q", lockfile.SHA256)
+	}
+}
+
+// Test counting module versions in go.sum and reading direct requirements from go.mod.
+func TestParse_GoSum(t *testing.T) {
+	sum := `github.com/go-chi/chi/v5 v5.0.12 h1:9euLV5sTrTNTRUU9POmDUvfxyj6LAABLUcEWO+JJb4s=
+github.com/go-chi/chi/v5 v5.0.12/go.mod h1:DslCQbL2OYiznFReuXYUmQ2hGd1aDpCnlMNITLSKoi8=
+golang.org/x/crypto v0.21.0 h1:X31++rzVUdKhX5sWmSOFZxx8UW/ldWx55cbf08iNAMA=
+golang.org/x/crypto v0.21.0/go.mod h1:0BP7YvVV9gBbVKyeTG0Gyn+gZm94bibOW5BjDEYAOMs=
+golang.org/x/sys v0.18.0/go.mod h1:/VUhepiaJMQUp4+oa/7Zr1D23ma6VTLIYjOOTFZPUcA=
+`
+	mod := `module example.com/app
+
+go 1.22
+
+require github.com/go-chi/chi/v5 v5.0.12
+
+require (
+	golang.org/x/crypto v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+`
+
+	lockfile, err := Parse("go.sum", []byte(sum))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if lockfile.Dependencies != 2 {
+		t.Errorf("Expected 2 dependencies, got %d", lockfile.Dependencies)
+	}
+	if expected := []string{"github.com/go-chi/chi/v5@v5.0.12", "gopkg.in/yaml.v3@v3.0.1"}; !slices.Equal(GoModDirect([]byte(mod)), expected) {
+		t.Errorf("Expected direct dependencies %v, got %v", expected, GoModDirect([]byte(mod)))
+	}
+}
+
+// Test summarizing package-lock.json files of all lockfile versions.
+func TestParse_PackageLock(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+	}{
+		{
+			name: "v3",
+			data: `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.18.2"}, "devDependencies": {"jest": "^29.0.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"accepts": "~1.3.8"}},
+    "node_modules/accepts": {"version": "1.3.8"},
+    "node_modules/jest": {"version": "29.7.0", "dev": true}
+  }
+}`,
+		},
+		{
+			name: "v1",
+			data: `{
+  "name": "app",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {"version": "4.18.2", "dependencies": {"accepts": {"version": "1.3.8"}}},
+    "jest": {"version": "29.7.0", "dev": true}
+  }
+}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lockfile, err := Parse("package-lock.json", []byte(tc.data))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if lockfile.Dependencies != 3 {
+				t.Errorf("Expected 3 dependencies, got %d", lockfile.Dependencies)
+			}
+			if expected := []string{"express@4.18.2"}; !slices.Equal(lockfile.Direct, expected) {
+				t.Errorf("Expected direct dependencies %v, got %v", expected, lockfile.Direct)
+			}
+		})
+	}
+}
+
+// Test rejecting malformed lockfiles.
+func TestParse_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"Cargo.lock":        "version = 3\n",
+		"package-lock.json": "not json",
+		"yarn.lock":         "",
+	} {
+		if _, err := Parse(name, []byte(data)); err == nil {
+			t.Errorf("Expected error parsing %s", name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package merkle implements the Merkle tree hashing and inclusion proofs of RFC 6962 (Certificate
+// Transparency), used by the append-only verification log.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+)
+
+// Domain separation prefixes of leaf and interior node hashes.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Hash is a SHA-256 hash of a leaf or node of the tree.
+type Hash [sha256.Size]byte
+
+// String returns the hex encoding of the hash.
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// ParseHash parses a hex encoded hash.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// LeafHash returns the hash of a log entry.
+func LeafHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{leafPrefix}, data...))
+}
+
+// nodeHash returns the hash of an interior node.
+func nodeHash(left, right Hash) Hash {
+	buf := make([]byte, 0, 1+2*len(left))
+	buf = append(buf, nodePrefix)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// Root returns the root hash of a tree with the given leaf hashes. The root of an empty tree is the
+// hash of the empty string.
+func Root(leaves []Hash) Hash {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// InclusionProof returns the audit path proving that the leaf at index is included in the tree
+// with the given leaf hashes, ordered from the leaf up.
+func InclusionProof(leaves []Hash, index int) ([]Hash, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range for tree size %d", index, len(leaves))
+	}
+	return inclusionPath(leaves, index), nil
+}
+
+func inclusionPath(leaves []Hash, index int) []Hash {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(inclusionPath(leaves[:k], index), Root(leaves[k:]))
+	}
+	return append(inclusionPath(leaves[k:], index-k), Root(leaves[:k]))
+}
+
+// VerifyInclusion checks an audit path proving that leaf is the entry at index of the tree of the
+// given size with the given root.
+func VerifyInclusion(leaf Hash, index, size int, proof []Hash, root Hash) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
@@ -0,0 +1,82 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+)
+
+func testLeaves(n int) []Hash {
+	leaves := make([]Hash, n)
+	for i := range leaves {
+		leaves[i] = LeafHash([]byte(fmt.Sprintf("entry %d", i)))
+	}
+	return leaves
+}
+
+// Test the root of the empty tree and of a single leaf.
+func TestRoot_Small(t *testing.T) {
+	if got, want := Root(nil).String(), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; got != want {
+		t.Errorf("Expected empty root %s, got %s", want, got)
+	}
+
+	// The leaf hash of the empty entry is SHA-256 of a single zero byte.
+	leaf := LeafHash(nil)
+	if got, want := leaf.String(), "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"; got != want {
+		t.Errorf("Expected leaf hash %s, got %s", want, got)
+	}
+	if Root([]Hash{leaf}) != leaf {
+		t.Error("Expected the root of a single leaf to be the leaf hash")
+	}
+}
+
+// Test that the tree is split at the largest power of two smaller than its size.
+func TestRoot_Unbalanced(t *testing.T) {
+	leaves := testLeaves(3)
+	want := nodeHash(nodeHash(leaves[0], leaves[1]), leaves[2])
+	if got := Root(leaves); got != want {
+		t.Errorf("Expected root %s, got %s", want, got)
+	}
+}
+
+// Test that proofs of every leaf verify for trees of various sizes.
+func TestInclusionProof(t *testing.T) {
+	for size := 1; size <= 33; size++ {
+		leaves := testLeaves(size)
+		root := Root(leaves)
+		for index := range size {
+			proof, err := InclusionProof(leaves, index)
+			if err != nil {
+				t.Fatalf("InclusionProof(%d, %d) failed: %v", size, index, err)
+			}
+			if !VerifyInclusion(leaves[index], index, size, proof, root) {
+				t.Errorf("Proof of leaf %d in tree of size %d did not verify", index, size)
+			}
+		}
+	}
+}
+
+// Test that proofs do not verify a different leaf, index, or root.
+func TestVerifyInclusion_Tampered(t *testing.T) {
+	leaves := testLeaves(10)
+	root := Root(leaves)
+	proof, err := InclusionProof(leaves, 6)
+	if err != nil {
+		t.Fatalf("InclusionProof failed: %v", err)
+	}
+
+	if VerifyInclusion(leaves[5], 6, 10, proof, root) {
+		t.Error("Expected proof of a different leaf to fail")
+	}
+	if VerifyInclusion(leaves[6], 7, 10, proof, root) {
+		t.Error("Expected proof at a different index to fail")
+	}
+	if VerifyInclusion(leaves[6], 6, 10, proof, Root(leaves[:9])) {
+		t.Error("Expected proof against a different root to fail")
+	}
+	if VerifyInclusion(leaves[6], 6, 10, proof[:len(proof)-1], root) {
+		t.Error("Expected truncated proof to fail")
+	}
+	if _, err := InclusionProof(leaves, 10); err == nil {
+		t.Error("Expected out of range index to fail")
+	}
+}
@@ -0,0 +1,370 @@
+// Package mockbackend implements an in-process mock of rofl-app-backend for development and
+// tests: SIWE sign-in, verification task submission, and polling, with scriptable outcomes.
+package mockbackend
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- only used to derive fake commit SHAs.
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spruceid/siwe-go"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Version is reported by the health endpoint of the mock.
+const Version = "mock"
+
+// Result values of an outcome.
+const (
+	ResultVerified = "verified"
+	ResultFailed   = "failed"
+)
+
+// Outcome is the scripted result of the verification tasks it matches.
+type Outcome struct {
+	Repository string `yaml:"repository" json:"repository"` // Repository URL to match, empty matches all.
+	Deployment string `yaml:"deployment" json:"deployment"` // Deployment name to match, empty matches all.
+
+	Result    string `yaml:"result" json:"result"`         // "verified" (default) or "failed".
+	CommitSHA string `yaml:"commit_sha" json:"commit_sha"` // Built commit (default: the pinned ref or a SHA derived from the ref).
+	Duration  int    `yaml:"duration" json:"duration"`     // Seconds a task stays in progress.
+	Stdout    string `yaml:"stdout" json:"stdout"`
+	Stderr    string `yaml:"stderr" json:"stderr"`
+	Err       string `yaml:"err" json:"err"`
+
+	// Enclave identities expected and built, returned to requests asking for the report.
+	Report *models.VerificationReport `yaml:"report" json:"report"`
+
+	SubmitStatus int `yaml:"submit_status" json:"submit_status"` // Reject submissions with this HTTP status.
+	ResultStatus int `yaml:"result_status" json:"result_status"` // Answer polls with this HTTP status instead of the result.
+}
+
+// Script lists the outcomes of verification tasks. The first outcome matching the repository
+// and deployment of a task applies; tasks matching none verify successfully.
+type Script struct {
+	Outcomes []Outcome `yaml:"outcomes" json:"outcomes"`
+}
+
+// LoadScript reads a script from a YAML or JSON file.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	var script Script
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+	for i, outcome := range script.Outcomes {
+		if outcome.Result != "" && outcome.Result != ResultVerified && outcome.Result != ResultFailed {
+			return nil, fmt.Errorf("outcomes[%d]: result must be %q or %q (got %q)", i, ResultVerified, ResultFailed, outcome.Result)
+		}
+	}
+	return &script, nil
+}
+
+// match returns the outcome of a task.
+func (s *Script) match(repository, deployment string) Outcome {
+	if s != nil {
+		for _, outcome := range s.Outcomes {
+			if (outcome.Repository == "" || strings.EqualFold(outcome.Repository, repository)) &&
+				(outcome.Deployment == "" || outcome.Deployment == deployment) {
+				return outcome
+			}
+		}
+	}
+	return Outcome{}
+}
+
+// task is a submitted verification task.
+type task struct {
+	repository string
+	ref        string
+	deployment string
+	outcome    Outcome
+	report     bool // Whether the submission asked for the report.
+	doneAt     time.Time
+
+	batch map[string]Outcome // Outcomes by deployment of a batched task, nil for a single deployment.
+}
+
+// Server is a mock verification backend.
+type Server struct {
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	script *Script
+	nonces map[string]string // Outstanding sign-in nonces by address.
+	tokens map[string]bool
+	tasks  map[string]*task
+}
+
+// New creates a mock backend answering tasks according to script, which may be nil.
+func New(script *Script, logger *slog.Logger) *Server {
+	return &Server{
+		logger: logger,
+		script: script,
+		nonces: make(map[string]string),
+		tokens: make(map[string]bool),
+		tasks:  make(map[string]*task),
+	}
+}
+
+// SetScript replaces the script applied to tasks submitted from now on.
+func (s *Server) SetScript(script *Script) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = script
+}
+
+// Handler returns the HTTP handler of the mock.
+func (s *Server) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/health", s.handleHealth)
+	r.Get("/auth/nonce", s.handleNonce)
+	r.Post("/auth/login", s.handleLogin)
+	r.Post("/rofl/verify_deployments", s.handleSubmit)
+	r.Get("/rofl/verify_deployments/{task_id}/results", s.handleResults)
+	return r
+}
+
+// Serve serves the mock on a listener until the context is canceled.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("mock backend error: %w", err)
+	}
+	return nil
+}
+
+// handleHealth handles GET /health.
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": Version})
+}
+
+// handleNonce handles GET /auth/nonce, issuing a nonce for signing in an address.
+func (s *Server) handleNonce(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "address is required", http.StatusBadRequest)
+		return
+	}
+	nonce := randomHex(8)
+
+	s.mu.Lock()
+	s.nonces[strings.ToLower(address)] = nonce
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"nonce": nonce})
+}
+
+// handleLogin handles POST /auth/login, issuing a token for a SIWE message signed with a nonce
+// issued to its address.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	msg, err := siwe.ParseMessage(body.Message)
+	if err != nil {
+		http.Error(w, "invalid SIWE message", http.StatusBadRequest)
+		return
+	}
+	address := msg.GetAddress().Hex()
+
+	s.mu.Lock()
+	nonce, ok := s.nonces[strings.ToLower(address)]
+	delete(s.nonces, strings.ToLower(address))
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "no nonce issued", http.StatusUnauthorized)
+		return
+	}
+	sig := r.URL.Query().Get("sig")
+	if len(sig) != 2+2*65 {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if _, err := msg.Verify(sig, nil, &nonce, nil); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	token := randomHex(16)
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	s.logger.Info("mock backend signed in", "address", address)
+	writeJSON(w, http.StatusOK, map[string]string{"token": token, "address": address})
+}
+
+// authorized reports whether a request is anonymous or bears a token issued by the mock.
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[strings.TrimPrefix(header, "Bearer ")]
+}
+
+// handleSubmit handles POST /rofl/verify_deployments.
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	var body struct {
+		RepositoryURL   string   `json:"repository_url"`
+		Ref             string   `json:"ref"`
+		DeploymentName  string   `json:"deployment_name"`
+		DeploymentNames []string `json:"deployment_names"`
+		IncludeReport   bool     `json:"include_report"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RepositoryURL == "" {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// A batched task builds once, so it takes as long as its slowest deployment and fails as a
+	// whole if any of its outcomes fails the submission or the results.
+	t := &task{repository: body.RepositoryURL, ref: body.Ref, deployment: body.DeploymentName, report: body.IncludeReport}
+	s.mu.Lock()
+	if len(body.DeploymentNames) == 0 {
+		t.outcome = s.script.match(body.RepositoryURL, body.DeploymentName)
+	} else {
+		t.deployment = strings.Join(body.DeploymentNames, ",")
+		t.batch = make(map[string]Outcome, len(body.DeploymentNames))
+		for i, name := range body.DeploymentNames {
+			outcome := s.script.match(body.RepositoryURL, name)
+			t.batch[name] = outcome
+			if i == 0 {
+				t.outcome = outcome
+			}
+			t.outcome.Duration = max(t.outcome.Duration, outcome.Duration)
+			t.outcome.SubmitStatus = cmp.Or(t.outcome.SubmitStatus, outcome.SubmitStatus)
+			t.outcome.ResultStatus = cmp.Or(t.outcome.ResultStatus, outcome.ResultStatus)
+		}
+	}
+	s.mu.Unlock()
+	if t.outcome.SubmitStatus != 0 {
+		http.Error(w, "scripted failure", t.outcome.SubmitStatus)
+		return
+	}
+	t.doneAt = time.Now().Add(time.Duration(t.outcome.Duration) * time.Second)
+
+	id := randomHex(8)
+	s.mu.Lock()
+	s.tasks[id] = t
+	s.mu.Unlock()
+
+	s.logger.Info("mock backend accepted task", "task_id", id, "repository", body.RepositoryURL, "ref", body.Ref, "deployment", t.deployment)
+	writeJSON(w, http.StatusOK, map[string]string{"task_id": id})
+}
+
+// handleResults handles GET /rofl/verify_deployments/{task_id}/results.
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	t, ok := s.tasks[chi.URLParam(r, "task_id")]
+	s.mu.Unlock()
+	switch {
+	case !ok:
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	case time.Now().Before(t.doneAt):
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "in_progress"})
+		return
+	case t.outcome.ResultStatus != 0:
+		http.Error(w, "scripted failure", t.outcome.ResultStatus)
+		return
+	}
+
+	commitSHA := t.outcome.CommitSHA
+	if commitSHA == "" {
+		commitSHA = fakeCommitSHA(t.repository, t.ref)
+	}
+	result := map[string]any{
+		"verified":      t.outcome.Result != ResultFailed,
+		"commit_sha":    commitSHA,
+		"stdout":        t.outcome.Stdout,
+		"stderr":        t.outcome.Stderr,
+		"err":           t.outcome.Err,
+		"cli_version":   Version,
+		"builder_image": Version,
+	}
+	if t.report && t.outcome.Report != nil {
+		result["report"] = t.outcome.Report
+	}
+	if t.batch != nil {
+		verified := true
+		deployments := make(map[string]any, len(t.batch))
+		for name, outcome := range t.batch {
+			verdict := map[string]any{"verified": outcome.Result != ResultFailed, "err": outcome.Err}
+			if t.report && outcome.Report != nil {
+				verdict["report"] = outcome.Report
+			}
+			deployments[name] = verdict
+			verified = verified && outcome.Result != ResultFailed
+		}
+		delete(result, "report")
+		result["verified"] = verified
+		result["deployments"] = deployments
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// fakeCommitSHA returns the ref if it is a commit SHA, and otherwise a SHA derived from the
+// repository and ref, so that repeated builds of a ref report the same commit.
+func fakeCommitSHA(repository, ref string) string {
+	if _, err := hex.DecodeString(ref); err == nil && len(ref) == 40 {
+		return strings.ToLower(ref)
+	}
+	hash := sha1.Sum([]byte(repository + "@" + ref)) // #nosec G401 -- not used for security.
+	return hex.EncodeToString(hash[:])
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -14,16 +14,78 @@ const (
 	StatusPending  VerificationStatus = "pending"
 	StatusVerified VerificationStatus = "verified"
 	StatusFailed   VerificationStatus = "failed"
+	StatusStale    VerificationStatus = "stale" // Verified, but not re-verified within the configured max age.
 )
 
+// Statuses of repositories that are no longer maintained.
+const (
+	RepoArchived = "archived" // Archived on GitHub, so it is read-only.
+	RepoDeleted  = "deleted"  // Deleted on GitHub, or made private.
+)
+
+// DefaultNamespace is the namespace of apps of the registry configured under apps, as opposed to
+// the additional registries configured under namespaces.
+const DefaultNamespace = "default"
+
 // App represents a ROFL application in the registry.
 type App struct {
 	ID        int64          `json:"id"`
+	Namespace string         `json:"namespace"`  // Registry the app is listed in.
 	GitHubURL string         `json:"github_url"` // e.g., https://github.com/oasisprotocol/wt3
-	GitRef    string         `json:"git_ref"`    // Branch, tag, or commit ref to verify.
+	Slug      sql.NullString `json:"slug"`       // Unique permalink slug, assigned once the manifest is known.
+	GitRef    string         `json:"git_ref"`    // Branch, tag, or commit ref to verify ("default" for the default branch).
+	Featured  bool           `json:"featured"`   // Highlighted on the landing page, as set in the registry.
+	Stars     sql.NullInt64  `json:"stars"`      // GitHub stargazers of the repository, when last checked.
 	RoflYAML  sql.NullString `json:"rofl_yaml"`  // Raw rofl.yaml content.
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+
+	OwnerLogin     sql.NullString `json:"owner_login"`      // GitHub user or organization owning the repository.
+	OwnerName      sql.NullString `json:"owner_name"`       // Display name of the owner.
+	OwnerAvatarURL sql.NullString `json:"owner_avatar_url"` // Avatar of the owner.
+	OwnerType      sql.NullString `json:"owner_type"`       // "User" or "Organization".
+	OwnerBlog      sql.NullString `json:"owner_blog"`       // Website of the owner.
+	OwnerEmail     sql.NullString `json:"owner_email"`      // Public email of the owner.
+	OwnerVerified  bool           `json:"owner_verified"`   // Owner is an organization with a domain verified by GitHub.
+	ForkOf         sql.NullString `json:"fork_of"`          // Repository the app's repository was forked from, if it is a fork.
+
+	LogoURL       sql.NullString `json:"logo_url"`        // Logo declared in the registry, overriding the manifest logo.
+	LogoSource    sql.NullString `json:"logo_source"`     // URL the stored logo was fetched from.
+	HasLogo       bool           `json:"has_logo"`        // Whether a sanitized logo is stored.
+	LogoUpdatedAt sql.NullTime   `json:"logo_updated_at"` // When the logo was last fetched.
+
+	ManifestPath         sql.NullString `json:"manifest_path"`          // Filename the manifest was found under, e.g. rofl.yml.
+	ManifestETag         sql.NullString `json:"manifest_etag"`          // ETag of the last manifest fetch.
+	ManifestLastModified sql.NullString `json:"manifest_last_modified"` // Last-Modified of the last manifest fetch.
+
+	ComposeYAML      sql.NullString `json:"compose_yaml"`       // Raw compose file referenced by the manifest, truncated if offloaded.
+	ComposeYAMLRef   sql.NullString `json:"compose_yaml_ref"`   // Object storage key of the full compose file, if offloaded.
+	ComposeCommitSHA sql.NullString `json:"compose_commit_sha"` // Commit the compose file was fetched at.
+
+	ReadmeExcerpt   sql.NullString `json:"readme_excerpt"`    // Plain text excerpt of the repository README.
+	ReadmeCommitSHA sql.NullString `json:"readme_commit_sha"` // Commit the README was fetched at.
+
+	ConsecutiveFailures int            `json:"consecutive_failures"` // Verification attempts that errored in a row.
+	NextAttemptAt       sql.NullTime   `json:"next_attempt_at"`      // Earliest time of the next attempt when backing off.
+	LastError           sql.NullString `json:"last_error"`           // Error of the most recent failed attempt.
+
+	SelectedDeployments sql.NullString `json:"selected_deployments"` // Comma-separated deployments to verify as set in the registry, all if empty.
+
+	AttestationURL  sql.NullString `json:"attestation_url"`   // Endpoint of a running instance serving fresh TDX quotes, as set in the registry.
+	ProbeCheckedAt  sql.NullTime   `json:"probe_checked_at"`  // When the attestation endpoint was last probed.
+	ProbeAttestedAt sql.NullTime   `json:"probe_attested_at"` // When a live instance last attested a verified enclave identity.
+	ProbeDeployment sql.NullString `json:"probe_deployment"`  // Deployment whose identity the live instance attested.
+	ProbeError      sql.NullString `json:"probe_error"`       // Why the last probe failed, empty if it succeeded.
+
+	DomainCheckedAt sql.NullTime   `json:"domain_checked_at"` // When control of the homepage domain was last checked.
+	DomainVerified  sql.NullString `json:"domain_verified"`   // Homepage domain proven to reference the app, empty if not.
+	DomainMethod    sql.NullString `json:"domain_method"`     // How the domain was proven: "dns" or "well-known".
+	DomainError     sql.NullString `json:"domain_error"`      // Why the last check failed, empty if it succeeded.
+
+	RepoStatus   sql.NullString `json:"repo_status"`    // RepoArchived or RepoDeleted if the repository is no longer maintained, empty if it is.
+	RepoStatusAt sql.NullTime   `json:"repo_status_at"` // When the repository was found archived or deleted.
+	MovedTo      sql.NullString `json:"moved_to"`       // URL GitHub redirects the repository to after a rename or transfer, awaiting confirmation.
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Deployment represents a single deployment of an app (e.g., mainnet, testnet).
@@ -32,11 +94,32 @@ type Deployment struct {
 	AppID           int64              `json:"app_id"`
 	DeploymentName  string             `json:"deployment_name"`  // e.g., "mainnet", "testnet"
 	CommitSHA       sql.NullString     `json:"commit_sha"`       // Git commit SHA that was verified.
-	Status          VerificationStatus `json:"status"`           // "pending", "verified", "failed"
+	CommitTag       sql.NullString     `json:"commit_tag"`       // Tag pointing at the verified commit, e.g. "v1.4.2".
+	Status          VerificationStatus `json:"status"`           // "pending", "verified", "failed", "stale"
 	VerificationMsg sql.NullString     `json:"verification_msg"` // "Built enclave identities MATCH..." or error message.
+	HasLog          bool               `json:"has_log"`          // Whether build output of the last verification is stored.
+	CLIVersion      sql.NullString     `json:"cli_version"`      // oasis-cli version used by the last verification.
+	BuilderImage    sql.NullString     `json:"builder_image"`    // Builder container image used by the last verification.
 	LastVerified    sql.NullTime       `json:"last_verified"`
-	CreatedAt       time.Time          `json:"created_at"`
-	UpdatedAt       time.Time          `json:"updated_at"`
+	VerifiedSince   sql.NullTime       `json:"verified_since"`    // Start of the current run of successful verifications.
+	FirstVerifiedAt sql.NullTime       `json:"first_verified_at"` // First successful verification, kept when the deployment fails later.
+	VerifiedStreak  int64              `json:"verified_streak"`   // Successful verifications in a row, 0 unless verified.
+	FailedStreak    int64              `json:"failed_streak"`     // Failed verifications in a row, 0 unless failed.
+
+	// Cross-check of the live deployment against the on-chain state indexed by Nexus.
+	LiveCheckedAt          sql.NullTime   `json:"live_checked_at"`
+	LiveInstances          sql.NullInt64  `json:"live_instances"`           // Number of active instances.
+	LiveUnverifiedEnclaves sql.NullString `json:"live_unverified_enclaves"` // Comma-separated identities in the on-chain policy missing from the verified build.
+
+	// Evaluation of the verification policy after the last verification.
+	PolicyCheckedAt  sql.NullTime      `json:"policy_checked_at"`
+	PolicyViolations []PolicyViolation `json:"policy_violations"`
+
+	// Enclave identities expected and built by the last verification, nil if unknown.
+	VerificationReport *VerificationReport `json:"verification_report"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // VerificationJob represents a build/verification job from the external service.
@@ -50,3 +133,210 @@ type VerificationJob struct {
 	CompletedAt sql.NullTime `json:"completed_at"`
 	CreatedAt   time.Time    `json:"created_at"`
 }
+
+// ImageDigest records the content digest a mutable image tag resolved to at verification time.
+type ImageDigest struct {
+	ID         int64     `json:"id"`
+	AppID      int64     `json:"app_id"`
+	Image      string    `json:"image"`  // Image reference as written in the compose file.
+	Digest     string    `json:"digest"` // e.g., "sha256:..."
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// VerificationEvent is an entry of the append-only verification log.
+type VerificationEvent struct {
+	Index     int64     `json:"index"`
+	Entry     string    `json:"entry"`     // JSON encoded event, hashed into the log as is.
+	LeafHash  string    `json:"leaf_hash"` // Hex encoded Merkle leaf hash of the entry.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PolicyViolation is a rule of the verification policy a verified deployment does not satisfy.
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Sources of the enclave identities a deployment is expected to build.
+const (
+	IdentitySourceManifest = "manifest" // Policy in the manifest at the built commit.
+	IdentitySourceOnChain  = "onchain"  // Policy registered on chain.
+)
+
+// VerificationReport is the machine-readable outcome of verifying a deployment: the enclave
+// identities it was expected to build and the ones it built.
+type VerificationReport struct {
+	Enclaves []EnclaveIdentities `json:"enclaves"`
+}
+
+// EnclaveIdentities pairs an enclave identity a deployment is expected to build with the one
+// built. They differ if the build does not reproduce the expected identity.
+type EnclaveIdentities struct {
+	Source   string `json:"source"`   // Where the expected identity comes from, IdentitySourceManifest or IdentitySourceOnChain.
+	Expected string `json:"expected"` // Empty if the build has an identity that is not expected.
+	Actual   string `json:"actual"`   // Empty if the build lacks an expected identity.
+}
+
+// DependencyReport summarizes the lockfiles of an app at the commit it was last verified at, tying
+// the attested build to an exact dependency set.
+type DependencyReport struct {
+	AppID     int64      `json:"app_id"`
+	CommitSHA string     `json:"commit_sha"`
+	Lockfiles []Lockfile `json:"lockfiles"` // Empty if the repository has none.
+	CheckedAt time.Time  `json:"checked_at"`
+}
+
+// Lockfile summarizes a dependency lockfile.
+type Lockfile struct {
+	Path         string   `json:"path"`
+	Ecosystem    string   `json:"ecosystem"` // "cargo", "go", or "npm".
+	SHA256       string   `json:"sha256"`    // Hex encoded digest of the lockfile.
+	Dependencies int      `json:"dependencies"`
+	Direct       []string `json:"direct,omitempty"` // Top-level dependencies as name@version.
+}
+
+// VerificationDuration is how long the backend took to verify a deployment, from submitting the
+// task until its result.
+type VerificationDuration struct {
+	AppID       int64
+	Slug        string
+	Deployment  string
+	CompletedAt time.Time
+	Duration    time.Duration
+}
+
+// Uptime summarizes the verification checks of an app over a window of days.
+type Uptime struct {
+	Days     int
+	Checks   int64       // Finished verifications of any deployment.
+	Verified int64       // Checks that verified the deployment.
+	Daily    []UptimeDay // One entry per day of the window, oldest first.
+}
+
+// UptimeDay counts the verification checks of an app finished on a day, in UTC.
+type UptimeDay struct {
+	Date     time.Time
+	Checks   int64
+	Verified int64
+}
+
+// WorkerHeartbeat is the latest sign of life of a worker instance.
+type WorkerHeartbeat struct {
+	InstanceID            string
+	HeartbeatAt           time.Time
+	LastCycleCompletedAt  *time.Time // Nil until the instance completes a cycle.
+	LastCycleDuration     time.Duration
+	ExpectedCycleDuration time.Duration // How long a cycle should take, given the schedule.
+}
+
+// StatusEvent records a change of the verification status of a deployment. Feeds and notifications
+// of status changes are all built from these.
+type StatusEvent struct {
+	ID         int64              `json:"id"`
+	AppID      int64              `json:"app_id"`
+	GitHubURL  string             `json:"github_url"`
+	Slug       string             `json:"slug,omitempty"`
+	Deployment string             `json:"deployment"`
+	OldStatus  VerificationStatus `json:"old_status,omitempty"` // Empty for a new deployment.
+	NewStatus  VerificationStatus `json:"new_status"`
+	CommitSHA  string             `json:"commit_sha,omitempty"`
+	Details    string             `json:"details,omitempty"` // Verification message or reason of the change.
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// NotificationDelivery is a notification of a status event queued in the outbox for delivery to a
+// destination of a notification channel, e.g. an email address or a webhook URL.
+type NotificationDelivery struct {
+	ID          int64
+	EventID     int64
+	Channel     string
+	Destination string
+	Payload     []byte // JSON encoded notification.
+	Priority    int    // Deliveries of higher priority are sent first.
+	Attempts    int
+}
+
+// LogAnchor is a root of the verification log published to a contract on chain.
+type LogAnchor struct {
+	Size       int64     `json:"size"`
+	RootHash   string    `json:"root_hash"`
+	ChainID    int64     `json:"chain_id"`
+	Contract   string    `json:"contract"`
+	TxHash     string    `json:"tx_hash"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// ManifestVersion is a distinct rofl.yaml content fetched for an app, addressed by its SHA-256 hash.
+type ManifestVersion struct {
+	ID          int64        `json:"id"`
+	AppID       int64        `json:"app_id"`
+	ContentHash string       `json:"content_hash"` // Hex encoded SHA-256 of the content.
+	Content     string       `json:"content"`
+	FirstSeenAt time.Time    `json:"first_seen_at"`
+	VerifiedAt  sql.NullTime `json:"verified_at"` // Last time a deployment verified against this version.
+}
+
+// Provenance records a build of a deployment that a successful verification reproduced, from which
+// provenance documents are generated.
+type Provenance struct {
+	AppID          int64
+	DeploymentName string
+	CommitSHA      string
+	ManifestSHA256 string // Content hash of the manifest version the build used.
+	CLIVersion     string
+	BuilderImage   string
+	TaskID         string       // Backend task that verified the build.
+	StartedAt      sql.NullTime // Submission of the task, unknown for results the worker did not submit.
+	FinishedAt     time.Time
+}
+
+// RegistryEntryError lists the problems of an invalid entry of the apps registry.
+type RegistryEntryError struct {
+	Namespace string    `json:"namespace"`      // Registry the entry belongs to.
+	Index     int       `json:"index"`          // Position of the entry in the registry, from 0.
+	Line      int       `json:"line,omitempty"` // Line of the entry in apps.yaml, if fetched.
+	URL       string    `json:"url,omitempty"`
+	Errors    []string  `json:"errors"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// MaintainerSession is a browser session of a GitHub user signed in to the maintainer dashboard.
+type MaintainerSession struct {
+	GitHubID          int64
+	Login             string
+	AvatarURL         string
+	AccessToken       string // OAuth token for checking the user's repository permissions, not stored.
+	SealedAccessToken []byte // AccessToken encrypted with the server's key, as stored.
+	ExpiresAt         time.Time
+}
+
+// AdminAction is an entry of the audit log of actions taken through the admin UI and API.
+type AdminAction struct {
+	ID        int64
+	Namespace string
+	Actor     string        // "admin UI" or the name of the API key.
+	Action    string        // E.g. "release", "verify", "move", "pause", "resume", or "rotate_key".
+	AppID     sql.NullInt64 // App acted on, if any.
+	Detail    string
+	CreatedAt time.Time
+}
+
+// Subscription is an email subscription of a visitor to the status changes of an app.
+type Subscription struct {
+	ID                 int64
+	AppID              int64
+	Email              string
+	UnsubscribeToken   string       // Secret of the unsubscribe link in every email.
+	ConfirmedAt        sql.NullTime // Null until the visitor confirms the address.
+	ConfirmationSentAt time.Time
+	CreatedAt          time.Time
+}
+
+// NotificationPreferences are the notification channels and filters of an app maintainer.
+type NotificationPreferences struct {
+	GitHubID     int64
+	Email        string // Empty disables email notifications.
+	WebhookURL   string // Empty disables webhook notifications.
+	OnlyFailures bool   // Only notify of deployments becoming failed or stale.
+	OnlyMainnet  bool   // Only notify of mainnet deployments.
+}
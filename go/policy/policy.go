@@ -0,0 +1,81 @@
+// Package policy evaluates the verification policy configured by the registry operator against
+// verified deployments. Violations are annotations next to the build verification, which only
+// checks that the app reproducibly builds to the enclave identities of its policy.
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Deployment is the state of a verified deployment that rules are evaluated against.
+type Deployment struct {
+	Name            string
+	Network         string
+	BuilderImage    string // Empty if the backend did not report it.
+	Enclaves        int    // Enclave identities admitted by the deployment policy.
+	TrustRootHeight uint64 // Zero if the manifest pins no trust root.
+	// Time of the trust root block, zero if it could not be looked up.
+	TrustRootTime time.Time
+}
+
+// Applies reports whether a rule applies to deployments on a network.
+func Applies(rule *config.PolicyRule, network string) bool {
+	return len(rule.Networks) == 0 || slices.Contains(rule.Networks, network)
+}
+
+// Evaluate returns the violations of the rules applying to a deployment. Rules that cannot be
+// evaluated, e.g. because the time of the trust root is unknown, are skipped.
+func Evaluate(rules []config.PolicyRule, deployment *Deployment, now time.Time) []models.PolicyViolation {
+	violations := []models.PolicyViolation{}
+	for i := range rules {
+		rule := &rules[i]
+		if !Applies(rule, deployment.Network) {
+			continue
+		}
+
+		message := check(rule, deployment, now)
+		if message == "" {
+			continue
+		}
+		if rule.Description != "" {
+			message = rule.Description
+		}
+		violations = append(violations, models.PolicyViolation{Rule: rule.Name, Message: message})
+	}
+	return violations
+}
+
+// check evaluates a single rule, returning why the deployment violates it or an empty string.
+func check(rule *config.PolicyRule, deployment *Deployment, now time.Time) string {
+	switch rule.Kind {
+	case config.PolicyPinnedBuilder:
+		switch {
+		case deployment.BuilderImage == "":
+			return "The builder image of the verification is unknown"
+		case !strings.Contains(deployment.BuilderImage, "@sha256:"):
+			return fmt.Sprintf("The builder image %s is not pinned by digest", deployment.BuilderImage)
+		}
+	case config.PolicyMinEnclaves:
+		if deployment.Enclaves < rule.Min {
+			return fmt.Sprintf("The policy admits %d enclave identities, at least %d are required", deployment.Enclaves, rule.Min)
+		}
+	case config.PolicyMaxTrustRootAge:
+		switch {
+		case deployment.TrustRootHeight == 0:
+			return "The manifest pins no trust root"
+		case deployment.TrustRootTime.IsZero():
+		default:
+			age := int(now.Sub(deployment.TrustRootTime).Hours() / 24)
+			if age > rule.MaxAge {
+				return fmt.Sprintf("The trust root at height %d is %d days old, at most %d are allowed", deployment.TrustRootHeight, age, rule.MaxAge)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Test evaluating each kind of rule.
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rules := []config.PolicyRule{
+		{Name: "pinned-builder", Kind: config.PolicyPinnedBuilder, Networks: []string{"mainnet"}},
+		{Name: "redundancy", Kind: config.PolicyMinEnclaves, Min: 2, Description: "Deploy at least two enclaves"},
+		{Name: "fresh-trust-root", Kind: config.PolicyMaxTrustRootAge, MaxAge: 30},
+	}
+
+	for _, tc := range []struct {
+		name       string
+		deployment Deployment
+		expected   []string
+	}{
+		{
+			name: "compliant",
+			deployment: Deployment{
+				Network:         "mainnet",
+				BuilderImage:    "ghcr.io/oasisprotocol/rofl-dev:v0.5.0@sha256:0123",
+				Enclaves:        2,
+				TrustRootHeight: 100,
+				TrustRootTime:   now.AddDate(0, 0, -10),
+			},
+		},
+		{
+			name: "violating",
+			deployment: Deployment{
+				Network:         "mainnet",
+				BuilderImage:    "ghcr.io/oasisprotocol/rofl-dev:v0.5.0",
+				Enclaves:        1,
+				TrustRootHeight: 100,
+				TrustRootTime:   now.AddDate(0, 0, -45),
+			},
+			expected: []string{"pinned-builder", "redundancy", "fresh-trust-root"},
+		},
+		{
+			name: "testnet without trust root",
+			deployment: Deployment{
+				Network:  "testnet",
+				Enclaves: 2,
+			},
+			expected: []string{"fresh-trust-root"},
+		},
+		{
+			name: "unknown trust root time",
+			deployment: Deployment{
+				Network:         "testnet",
+				Enclaves:        2,
+				TrustRootHeight: 100,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := Evaluate(rules, &tc.deployment, now)
+			if len(violations) != len(tc.expected) {
+				t.Fatalf("Expected violations of %v, got %v", tc.expected, violations)
+			}
+			for i, violation := range violations {
+				if violation.Rule != tc.expected[i] {
+					t.Errorf("Expected violation of %s, got %s", tc.expected[i], violation.Rule)
+				}
+				if violation.Message == "" {
+					t.Errorf("Expected a message for %s", violation.Rule)
+				}
+				if violation.Rule == "redundancy" && violation.Message != "Deploy at least two enclaves" {
+					t.Errorf("Expected the rule description, got %q", violation.Message)
+				}
+			}
+		})
+	}
+}
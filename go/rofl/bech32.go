@@ -0,0 +1,97 @@
+package rofl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bech32Charset is the bech32 data character set.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Generator holds the generator coefficients of the bech32 checksum.
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// decodeBech32 decodes a bech32 string into its human-readable part and 8-bit data.
+func decodeBech32(s string) (string, []byte, error) {
+	if len(s) < 8 || len(s) > 90 {
+		return "", nil, fmt.Errorf("invalid length %d", len(s))
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("invalid separator position")
+	}
+	hrp := s[:sep]
+
+	values := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v == -1 {
+			return "", nil, fmt.Errorf("invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+
+	if bech32Polymod(append(bech32ExpandHRP(hrp), values...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+
+	data, err := convertBits(values[:len(values)-6], 5, 8)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+// bech32Polymod computes the bech32 checksum polynomial.
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32ExpandHRP expands the human-readable part for checksum computation.
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// convertBits regroups 5-bit values into 8-bit bytes, rejecting non-zero padding.
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxV := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits))
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxV))
+		}
+	}
+	if bits >= fromBits || (acc<<(toBits-bits))&maxV != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
@@ -0,0 +1,117 @@
+package rofl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Compose represents a docker-compose file referenced by a container manifest.
+// Only the fields needed to show what runs inside the TEE are parsed.
+type Compose struct {
+	Services map[string]*ComposeService `yaml:"services"`
+}
+
+// ComposeService represents a single service in a compose file.
+type ComposeService struct {
+	Image string `yaml:"image"`
+	// Additional fields may be present but are not parsed.
+}
+
+// ImageRef is a container image reference split into its components.
+type ImageRef struct {
+	Service string // Compose service using the image.
+	Raw     string // Reference as written in the compose file.
+	Name    string // Repository name, e.g. "ghcr.io/oasisprotocol/demo-rofl".
+	Tag     string // Tag, if any, e.g. "latest".
+	Digest  string // Content digest, if any, e.g. "sha256:abcd...".
+}
+
+// ParseCompose parses a compose file from bytes.
+func ParseCompose(data []byte) (*Compose, error) {
+	var compose Compose
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+	return &compose, nil
+}
+
+// Images returns the image references of all services, sorted by service name.
+// Services without an image (e.g. built locally) are skipped.
+func (c *Compose) Images() []ImageRef {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	images := make([]ImageRef, 0, len(names))
+	for _, name := range names {
+		svc := c.Services[name]
+		if svc == nil || svc.Image == "" {
+			continue
+		}
+		ref := ParseImageRef(svc.Image)
+		ref.Service = name
+		images = append(images, ref)
+	}
+	return images
+}
+
+// ParseImageRef splits an image reference of the form name[:tag][@digest].
+func ParseImageRef(image string) ImageRef {
+	ref := ImageRef{Raw: image}
+
+	name := image
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port.
+	if i := strings.LastIndex(name, ":"); i != -1 && i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	ref.Name = name
+
+	return ref
+}
+
+// Pinned reports whether the image is pinned to an immutable content digest.
+func (r ImageRef) Pinned() bool {
+	return r.Digest != ""
+}
+
+// Reference returns the tag (or "latest" when none is given) used to resolve the image.
+func (r ImageRef) Reference() string {
+	if r.Tag != "" {
+		return r.Tag
+	}
+	return "latest"
+}
+
+// dockerHubRegistry is the registry host used for images without an explicit registry.
+const dockerHubRegistry = "registry-1.docker.io"
+
+// Registry splits the image name into the registry host and repository path,
+// applying Docker Hub defaults (e.g. "nginx" is "registry-1.docker.io" and "library/nginx").
+func (r ImageRef) Registry() (host, repository string) {
+	name := r.Name
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		host, repository = first, rest
+	} else {
+		host, repository = dockerHubRegistry, name
+	}
+
+	if host == "docker.io" || host == "index.docker.io" {
+		host = dockerHubRegistry
+	}
+	if host == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return host, repository
+}
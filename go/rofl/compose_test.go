@@ -0,0 +1,80 @@
+package rofl
+
+import "testing"
+
+// Test extracting image references from a compose file.
+func TestParseCompose_Images(t *testing.T) {
+	composeContent := `
+services:
+  web:
+    image: ghcr.io/oasisprotocol/demo-rofl:latest@sha256:0123456789abcdef
+    ports:
+      - "8080:8080"
+  oracle:
+    image: localhost:5000/oracle:v1
+  builder:
+    build: .
+`
+
+	compose, err := ParseCompose([]byte(composeContent))
+	if err != nil {
+		t.Fatalf("ParseCompose failed: %v", err)
+	}
+
+	images := compose.Images()
+	if len(images) != 2 {
+		t.Fatalf("Expected 2 images, got %d", len(images))
+	}
+
+	expected := []ImageRef{
+		{
+			Service: "oracle",
+			Raw:     "localhost:5000/oracle:v1",
+			Name:    "localhost:5000/oracle",
+			Tag:     "v1",
+		},
+		{
+			Service: "web",
+			Raw:     "ghcr.io/oasisprotocol/demo-rofl:latest@sha256:0123456789abcdef",
+			Name:    "ghcr.io/oasisprotocol/demo-rofl",
+			Tag:     "latest",
+			Digest:  "sha256:0123456789abcdef",
+		},
+	}
+
+	for i, exp := range expected {
+		if images[i] != exp {
+			t.Errorf("Image %d: expected %+v, got %+v", i, exp, images[i])
+		}
+	}
+}
+
+// Test image references without tags or digests.
+func TestParseImageRef_Bare(t *testing.T) {
+	ref := ParseImageRef("localhost:5000/oracle")
+	if ref.Name != "localhost:5000/oracle" || ref.Tag != "" || ref.Digest != "" {
+		t.Errorf("Unexpected parse result: %+v", ref)
+	}
+}
+
+// Test registry and repository defaults for image references.
+func TestImageRef_Registry(t *testing.T) {
+	tests := []struct {
+		image      string
+		host       string
+		repository string
+	}{
+		{"nginx", "registry-1.docker.io", "library/nginx"},
+		{"docker.io/nginx:1.25", "registry-1.docker.io", "library/nginx"},
+		{"oasisprotocol/demo:latest", "registry-1.docker.io", "oasisprotocol/demo"},
+		{"ghcr.io/oasisprotocol/demo-rofl:main", "ghcr.io", "oasisprotocol/demo-rofl"},
+		{"localhost:5000/oracle", "localhost:5000", "oracle"},
+	}
+
+	for _, tt := range tests {
+		host, repository := ParseImageRef(tt.image).Registry()
+		if host != tt.host || repository != tt.repository {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tt.image, tt.host, tt.repository, host, repository)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package rofl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest sections reported by DiffManifests.
+const (
+	SectionEnclaves  = "enclaves"
+	SectionPolicy    = "policy"
+	SectionArtifacts = "artifacts"
+	SectionResources = "resources"
+	SectionOther     = "other"
+)
+
+// Kinds of manifest changes.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// ManifestChange is a single changed value between two manifests.
+type ManifestChange struct {
+	Path    string `json:"path"`    // Dotted path of the value, e.g. "deployments.mainnet.policy.enclaves[0].id".
+	Section string `json:"section"` // One of the Section* constants.
+	Kind    string `json:"kind"`    // One of the Change* constants.
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// DiffManifests compares two raw manifests value by value, including fields not parsed into Manifest.
+// Changes are ordered by path.
+func DiffManifests(oldData, newData []byte) ([]ManifestChange, error) {
+	oldValues, err := flattenYAML(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old manifest: %w", err)
+	}
+	newValues, err := flattenYAML(newData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new manifest: %w", err)
+	}
+
+	paths := make(map[string]struct{}, len(oldValues)+len(newValues))
+	for path := range oldValues {
+		paths[path] = struct{}{}
+	}
+	for path := range newValues {
+		paths[path] = struct{}{}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var changes []ManifestChange
+	for _, path := range sorted {
+		oldValue, inOld := oldValues[path]
+		newValue, inNew := newValues[path]
+
+		change := ManifestChange{Path: path, Section: manifestSection(path), Old: oldValue, New: newValue}
+		switch {
+		case !inOld:
+			change.Kind = ChangeAdded
+		case !inNew:
+			change.Kind = ChangeRemoved
+		case oldValue != newValue:
+			change.Kind = ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// manifestSection classifies a flattened manifest path.
+func manifestSection(path string) string {
+	switch {
+	case strings.Contains(path, ".policy.enclaves"):
+		return SectionEnclaves
+	case strings.Contains(path, ".policy."):
+		return SectionPolicy
+	case strings.HasPrefix(path, "artifacts."):
+		return SectionArtifacts
+	case strings.HasPrefix(path, "resources."):
+		return SectionResources
+	default:
+		return SectionOther
+	}
+}
+
+// flattenYAML parses a YAML document into a map of dotted paths to scalar values.
+func flattenYAML(data []byte) (map[string]string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	if len(root.Content) > 0 {
+		flattenNode(root.Content[0], "", values)
+	}
+	return values, nil
+}
+
+// flattenNode adds the scalar values below node to values.
+func flattenNode(node *yaml.Node, path string, values map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			flattenNode(node.Content[i+1], key, values)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			flattenNode(item, fmt.Sprintf("%s[%d]", path, i), values)
+		}
+	case yaml.AliasNode:
+		flattenNode(node.Alias, path, values)
+	case yaml.ScalarNode:
+		values[path] = node.Value
+	}
+}
@@ -0,0 +1,60 @@
+package rofl
+
+import "testing"
+
+// Test that manifest diffs report changes by section.
+func TestDiffManifests(t *testing.T) {
+	oldData := []byte(`
+name: test-app
+version: 0.1.0
+resources:
+  memory: 512
+deployments:
+  mainnet:
+    network: mainnet
+    policy:
+      enclaves:
+        - id: old-enclave
+      max_expiration: 3
+`)
+	newData := []byte(`
+name: test-app
+version: 0.2.0
+resources:
+  memory: 1024
+artifacts:
+  kernel: https://example.com/kernel
+deployments:
+  mainnet:
+    network: mainnet
+    policy:
+      enclaves:
+        - id: new-enclave
+`)
+
+	changes, err := DiffManifests(oldData, newData)
+	if err != nil {
+		t.Fatalf("DiffManifests failed: %v", err)
+	}
+
+	expected := map[string]ManifestChange{
+		"artifacts.kernel":                          {Section: SectionArtifacts, Kind: ChangeAdded},
+		"deployments.mainnet.policy.enclaves[0].id": {Section: SectionEnclaves, Kind: ChangeModified},
+		"deployments.mainnet.policy.max_expiration": {Section: SectionPolicy, Kind: ChangeRemoved},
+		"resources.memory":                          {Section: SectionResources, Kind: ChangeModified},
+		"version":                                   {Section: SectionOther, Kind: ChangeModified},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+	for _, change := range changes {
+		want, ok := expected[change.Path]
+		if !ok {
+			t.Errorf("Unexpected change at %s", change.Path)
+			continue
+		}
+		if change.Section != want.Section || change.Kind != want.Kind {
+			t.Errorf("%s: expected %s/%s, got %s/%s", change.Path, want.Section, want.Kind, change.Section, change.Kind)
+		}
+	}
+}
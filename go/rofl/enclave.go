@@ -0,0 +1,83 @@
+package rofl
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// measurementSize is the size of a single SGX measurement (MRENCLAVE or MRSIGNER).
+const measurementSize = 32
+
+// EnclaveIdentity is a decoded enclave identity as used in ROFL policies.
+// It is encoded as MRENCLAVE followed by MRSIGNER, 64 bytes in total.
+type EnclaveIdentity struct {
+	MrEnclave [measurementSize]byte
+	MrSigner  [measurementSize]byte
+}
+
+// EnclaveComponent is a single named measurement of an enclave identity.
+type EnclaveComponent struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Hex         string `json:"hex"`
+}
+
+// DecodeEnclaveIdentity decodes a base64 encoded enclave identity.
+func DecodeEnclaveIdentity(encoded string) (*EnclaveIdentity, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid enclave identity encoding: %w", err)
+	}
+	if len(raw) != 2*measurementSize {
+		return nil, fmt.Errorf("invalid enclave identity length: expected %d bytes, got %d", 2*measurementSize, len(raw))
+	}
+
+	var id EnclaveIdentity
+	copy(id.MrEnclave[:], raw[:measurementSize])
+	copy(id.MrSigner[:], raw[measurementSize:])
+	return &id, nil
+}
+
+// HasSigner reports whether MRSIGNER is set. TDX identities leave it zeroed.
+func (e *EnclaveIdentity) HasSigner() bool {
+	for _, b := range e.MrSigner {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Components returns the measurements of the identity, named according to the TEE type.
+func (e *EnclaveIdentity) Components(tee string) []EnclaveComponent {
+	if strings.EqualFold(tee, TEETDX) {
+		components := []EnclaveComponent{{
+			Name:        "TD measurement",
+			Description: "Hash of the TD measurements (MRTD and RTMRs) of the firmware, kernel, and stage2 image",
+			Hex:         hex.EncodeToString(e.MrEnclave[:]),
+		}}
+		if e.HasSigner() {
+			components = append(components, EnclaveComponent{
+				Name:        "MRSIGNER",
+				Description: "Signer measurement",
+				Hex:         hex.EncodeToString(e.MrSigner[:]),
+			})
+		}
+		return components
+	}
+
+	return []EnclaveComponent{
+		{
+			Name:        "MRENCLAVE",
+			Description: "Measurement of the enclave code and initial data",
+			Hex:         hex.EncodeToString(e.MrEnclave[:]),
+		},
+		{
+			Name:        "MRSIGNER",
+			Description: "Measurement of the enclave signing key",
+			Hex:         hex.EncodeToString(e.MrSigner[:]),
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package rofl
+
+import "testing"
+
+// Test decoding a TDX enclave identity with a zeroed MRSIGNER.
+func TestDecodeEnclaveIdentity_TDX(t *testing.T) {
+	id, err := DecodeEnclaveIdentity("jypB1qfYh2YpoXQbDglIxMxHA2wqOWpH68cLAhp0CBkAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==")
+	if err != nil {
+		t.Fatalf("DecodeEnclaveIdentity failed: %v", err)
+	}
+
+	if id.HasSigner() {
+		t.Error("Expected zero MRSIGNER")
+	}
+
+	components := id.Components("tdx")
+	if len(components) != 1 {
+		t.Fatalf("Expected 1 component, got %d", len(components))
+	}
+
+	expected := "8f2a41d6a7d8876629a1741b0e0948c4cc47036c2a396a47ebc70b021a740819"
+	if components[0].Hex != expected {
+		t.Errorf("Expected measurement '%s', got '%s'", expected, components[0].Hex)
+	}
+}
+
+// Test that SGX identities are split into MRENCLAVE and MRSIGNER.
+func TestDecodeEnclaveIdentity_SGX(t *testing.T) {
+	id, err := DecodeEnclaveIdentity("jypB1qfYh2YpoXQbDglIxMxHA2wqOWpH68cLAhp0CBkAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==")
+	if err != nil {
+		t.Fatalf("DecodeEnclaveIdentity failed: %v", err)
+	}
+
+	components := id.Components("sgx")
+	if len(components) != 2 {
+		t.Fatalf("Expected 2 components, got %d", len(components))
+	}
+	if components[0].Name != "MRENCLAVE" || components[1].Name != "MRSIGNER" {
+		t.Errorf("Unexpected component names: %s, %s", components[0].Name, components[1].Name)
+	}
+}
+
+// Test that malformed identities are rejected.
+func TestDecodeEnclaveIdentity_Invalid(t *testing.T) {
+	for _, encoded := range []string{"not base64!", "AAAA"} {
+		if _, err := DecodeEnclaveIdentity(encoded); err == nil {
+			t.Errorf("Expected error for %q", encoded)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package rofl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// licenseRefPrefixes mark custom license identifiers, which are valid but never OSI-approved.
+var licenseRefPrefixes = []string{"licenseref-", "documentref-"}
+
+// spdxLicenseIndex and spdxExceptionIndex map lowercase identifiers to their canonical form,
+// since SPDX identifiers are matched case-insensitively.
+var (
+	spdxLicenseIndex   = make(map[string]string, len(spdxLicenses))
+	spdxExceptionIndex = make(map[string]string, len(spdxExceptions))
+)
+
+func init() {
+	for id := range spdxLicenses {
+		spdxLicenseIndex[strings.ToLower(id)] = id
+	}
+	for _, id := range spdxExceptions {
+		spdxExceptionIndex[strings.ToLower(id)] = id
+	}
+}
+
+// License is a checked SPDX license expression.
+type License struct {
+	Expression  string
+	OSIApproved bool // Every license the software may be used under is OSI-approved.
+}
+
+// ParseLicense checks an SPDX license expression, e.g. "MIT OR Apache-2.0", and reports
+// whether it is OSI-approved. An OR expression is OSI-approved if any of its alternatives is,
+// an AND expression if all of its parts are. Unknown identifiers are returned as errors.
+func ParseLicense(expression string) (*License, error) {
+	p := &licenseParser{tokens: tokenizeLicense(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	osi := p.parseOr()
+	if p.err == nil && p.pos < len(p.tokens) {
+		p.fail(fmt.Errorf("unexpected %q", p.tokens[p.pos]))
+	}
+	if err := errors.Join(p.unknown...); err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", expression, p.err)
+	}
+	return &License{Expression: expression, OSIApproved: osi}, nil
+}
+
+// tokenizeLicense splits a license expression into identifiers, operators, and parentheses.
+func tokenizeLicense(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+// licenseParser is a recursive descent parser of SPDX license expressions.
+type licenseParser struct {
+	tokens  []string
+	pos     int
+	err     error   // First syntax error.
+	unknown []error // Unknown identifiers.
+}
+
+func (p *licenseParser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// accept consumes the next token if it is the given operator or parenthesis.
+func (p *licenseParser) accept(token string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], token) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr parses: and-expression { OR and-expression }.
+func (p *licenseParser) parseOr() bool {
+	osi := p.parseAnd()
+	for p.accept("OR") {
+		osi = p.parseAnd() || osi
+	}
+	return osi
+}
+
+// parseAnd parses: with-expression { AND with-expression }.
+func (p *licenseParser) parseAnd() bool {
+	osi := p.parseWith()
+	for p.accept("AND") {
+		osi = p.parseWith() && osi
+	}
+	return osi
+}
+
+// parseWith parses: primary [ WITH exception ].
+func (p *licenseParser) parseWith() bool {
+	osi := p.parsePrimary()
+	if p.accept("WITH") {
+		if p.pos >= len(p.tokens) {
+			p.fail(fmt.Errorf("missing exception after WITH"))
+			return false
+		}
+		exception := p.tokens[p.pos]
+		p.pos++
+		if _, ok := spdxExceptionIndex[strings.ToLower(exception)]; !ok {
+			p.unknown = append(p.unknown, fmt.Errorf("unknown SPDX license exception %q", exception))
+		}
+	}
+	return osi
+}
+
+// parsePrimary parses: "(" or-expression ")" | license-id [ "+" ].
+func (p *licenseParser) parsePrimary() bool {
+	if p.accept("(") {
+		osi := p.parseOr()
+		if !p.accept(")") {
+			p.fail(fmt.Errorf("missing closing parenthesis"))
+		}
+		return osi
+	}
+	if p.pos >= len(p.tokens) {
+		p.fail(fmt.Errorf("missing license identifier"))
+		return false
+	}
+
+	token := p.tokens[p.pos]
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH", ")":
+		p.fail(fmt.Errorf("unexpected %q", token))
+		return false
+	}
+	p.pos++
+
+	id := strings.ToLower(strings.TrimSuffix(token, "+"))
+	for _, prefix := range licenseRefPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return false
+		}
+	}
+	canonical, ok := spdxLicenseIndex[id]
+	if !ok {
+		p.unknown = append(p.unknown, fmt.Errorf("unknown SPDX license identifier %q", token))
+		return false
+	}
+	return spdxLicenses[canonical]
+}
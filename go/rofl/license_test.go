@@ -0,0 +1,43 @@
+package rofl
+
+import "testing"
+
+// Test SPDX license expression parsing and OSI approval.
+func TestParseLicense(t *testing.T) {
+	valid := map[string]bool{
+		"MIT":                                  true,
+		"apache-2.0":                           true,
+		"GPL-2.0+":                             true,
+		"MIT OR BUSL-1.1":                      true,
+		"MIT AND BUSL-1.1":                     false,
+		"(MIT OR Apache-2.0) AND BSD-3-Clause": true,
+		"Apache-2.0 WITH LLVM-exception":       true,
+		"BUSL-1.1":                             false,
+		"LicenseRef-Proprietary":               false,
+		"CC0-1.0 OR (Apache-2.0 AND LicenseRef-X)": false,
+	}
+	for expression, osi := range valid {
+		license, err := ParseLicense(expression)
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", expression, err)
+			continue
+		}
+		if license.OSIApproved != osi {
+			t.Errorf("Expected OSI approval of %q to be %v", expression, osi)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"Proprietary",
+		"MIT OR",
+		"(MIT",
+		"MIT Apache-2.0",
+		"Apache-2.0 WITH Made-Up-exception",
+	}
+	for _, expression := range invalid {
+		if _, err := ParseLicense(expression); err == nil {
+			t.Errorf("Expected error for %q", expression)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package rofl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// TDX quote layout (version 4, ECDSA-256 attestation key).
+const (
+	tdxQuoteVersion     = 4
+	tdxAttestationKey   = 2 // ECDSA-256 with P-256.
+	tdxTEEType          = 0x81
+	tdxHeaderSize       = 48
+	tdxBodySize         = 584
+	tdxMeasurementSize  = 48
+	tdxSignatureSize    = 64
+	tdxAttestKeySize    = 64
+	tdxBodyMrTdOffset   = 136
+	tdxBodyRtmrOffset   = 328
+	tdxBodyReportOffset = 520
+)
+
+// tdxIdentityCustomization is the TupleHash customization string oasis-core uses to derive the
+// enclave identity of a TD from its measurements.
+const tdxIdentityCustomization = "oasis-core/tdx: MRENCLAVE"
+
+// errTDXSignature is returned when a quote is not signed by its attestation key.
+var errTDXSignature = errors.New("invalid quote signature")
+
+// TDXQuote is a parsed TDX quote.
+type TDXQuote struct {
+	MrTd       [tdxMeasurementSize]byte
+	Rtmr       [4][tdxMeasurementSize]byte
+	ReportData [64]byte
+
+	signed    []byte // Header and TD report body, covered by the signature.
+	signature []byte
+	attestKey []byte
+}
+
+// ParseTDXQuote parses a version 4 TDX quote signed with an ECDSA-256 attestation key.
+func ParseTDXQuote(raw []byte) (*TDXQuote, error) {
+	if len(raw) < tdxHeaderSize+tdxBodySize+4 {
+		return nil, fmt.Errorf("quote too short: %d bytes", len(raw))
+	}
+	if version := binary.LittleEndian.Uint16(raw[0:2]); version != tdxQuoteVersion {
+		return nil, fmt.Errorf("unsupported quote version %d", version)
+	}
+	if keyType := binary.LittleEndian.Uint16(raw[2:4]); keyType != tdxAttestationKey {
+		return nil, fmt.Errorf("unsupported attestation key type %d", keyType)
+	}
+	if teeType := binary.LittleEndian.Uint32(raw[4:8]); teeType != tdxTEEType {
+		return nil, fmt.Errorf("not a TDX quote (TEE type %#x)", teeType)
+	}
+
+	signedSize := tdxHeaderSize + tdxBodySize
+	sigDataSize := int(binary.LittleEndian.Uint32(raw[signedSize : signedSize+4]))
+	sigData := raw[signedSize+4:]
+	if sigDataSize > len(sigData) || sigDataSize < tdxSignatureSize+tdxAttestKeySize {
+		return nil, fmt.Errorf("invalid signature data length %d", sigDataSize)
+	}
+
+	q := &TDXQuote{
+		signed:    raw[:signedSize],
+		signature: sigData[:tdxSignatureSize],
+		attestKey: sigData[tdxSignatureSize : tdxSignatureSize+tdxAttestKeySize],
+	}
+	body := raw[tdxHeaderSize:signedSize]
+	copy(q.MrTd[:], body[tdxBodyMrTdOffset:])
+	for i := range q.Rtmr {
+		copy(q.Rtmr[i][:], body[tdxBodyRtmrOffset+i*tdxMeasurementSize:])
+	}
+	copy(q.ReportData[:], body[tdxBodyReportOffset:])
+	return q, nil
+}
+
+// VerifySignature checks that the quote is signed by the attestation key it carries. It does not
+// verify the attestation key itself, which requires Intel's PCK certificate chain and collateral.
+func (q *TDXQuote) VerifySignature() error {
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(q.attestKey[:32]),
+		Y:     new(big.Int).SetBytes(q.attestKey[32:]),
+	}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return fmt.Errorf("invalid attestation key")
+	}
+
+	digest := sha256.Sum256(q.signed)
+	r := new(big.Int).SetBytes(q.signature[:32])
+	s := new(big.Int).SetBytes(q.signature[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return errTDXSignature
+	}
+	return nil
+}
+
+// EnclaveIdentity returns the enclave identity of the TD as registered in ROFL policies: the
+// TupleHash of MRTD and RTMR0-3, with MRSIGNER left zeroed.
+func (q *TDXQuote) EnclaveIdentity() *EnclaveIdentity {
+	parts := make([][]byte, 0, 1+len(q.Rtmr))
+	parts = append(parts, q.MrTd[:])
+	for i := range q.Rtmr {
+		parts = append(parts, q.Rtmr[i][:])
+	}
+
+	var id EnclaveIdentity
+	copy(id.MrEnclave[:], tupleHash256(parts, measurementSize, tdxIdentityCustomization))
+	return &id
+}
+
+// tupleHash256 computes TupleHash256 as specified in NIST SP 800-185.
+func tupleHash256(parts [][]byte, size int, customization string) []byte {
+	h := sha3.NewCSHAKE256([]byte("TupleHash"), []byte(customization))
+	for _, part := range parts {
+		_, _ = h.Write(leftEncode(uint64(len(part)) * 8))
+		_, _ = h.Write(part)
+	}
+	_, _ = h.Write(rightEncode(uint64(size) * 8))
+
+	out := make([]byte, size)
+	_, _ = h.Read(out)
+	return out
+}
+
+// leftEncode encodes x with its length in bytes prepended, as in NIST SP 800-185.
+func leftEncode(x uint64) []byte {
+	b := encodeUint(x)
+	return append([]byte{byte(len(b))}, b...)
+}
+
+// rightEncode encodes x with its length in bytes appended, as in NIST SP 800-185.
+func rightEncode(x uint64) []byte {
+	b := encodeUint(x)
+	return append(b, byte(len(b)))
+}
+
+// encodeUint returns the big-endian encoding of x in as few bytes as possible, at least one.
+func encodeUint(x uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], x)
+	i := 0
+	for i < len(buf)-1 && buf[i] == 0 {
+		i++
+	}
+	return buf[i:]
+}
@@ -0,0 +1,105 @@
+package rofl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// testTDXQuote builds a quote with the given report data, signed by a fresh attestation key.
+func testTDXQuote(t *testing.T, reportData []byte) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	quote := make([]byte, tdxHeaderSize+tdxBodySize)
+	binary.LittleEndian.PutUint16(quote[0:2], tdxQuoteVersion)
+	binary.LittleEndian.PutUint16(quote[2:4], tdxAttestationKey)
+	binary.LittleEndian.PutUint32(quote[4:8], tdxTEEType)
+	body := quote[tdxHeaderSize:]
+	for i := range tdxMeasurementSize {
+		body[tdxBodyMrTdOffset+i] = 0xaa
+		body[tdxBodyRtmrOffset+3*tdxMeasurementSize+i] = 0x33
+	}
+	copy(body[tdxBodyReportOffset:], reportData)
+
+	digest := sha256.Sum256(quote)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sigData := make([]byte, 0, tdxSignatureSize+tdxAttestKeySize)
+	sigData = append(sigData, r.FillBytes(make([]byte, 32))...)
+	sigData = append(sigData, s.FillBytes(make([]byte, 32))...)
+	sigData = append(sigData, key.X.FillBytes(make([]byte, 32))...)
+	sigData = append(sigData, key.Y.FillBytes(make([]byte, 32))...)
+
+	quote = binary.LittleEndian.AppendUint32(quote, uint32(len(sigData)))
+	return append(quote, sigData...)
+}
+
+// Test TupleHash256 against sample #4 of the NIST SP 800-185 examples.
+func TestTupleHash256(t *testing.T) {
+	got := hex.EncodeToString(tupleHash256([][]byte{{0x00, 0x01, 0x02}, {0x10, 0x11, 0x12, 0x13, 0x14, 0x15}}, 64, ""))
+	expected := "cfb7058caca5e668f81a12a20a2195ce97a925f1dba3e7449a56f82201ec607311ac2696b1ab5ea2352df1423bde7bd4bb78c9aed1a853c78672f9eb23bbe194"
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// Test parsing a quote and verifying its signature.
+func TestParseTDXQuote(t *testing.T) {
+	raw := testTDXQuote(t, []byte("nonce"))
+
+	quote, err := ParseTDXQuote(raw)
+	if err != nil {
+		t.Fatalf("ParseTDXQuote failed: %v", err)
+	}
+	if quote.MrTd[0] != 0xaa || quote.Rtmr[3][47] != 0x33 || quote.Rtmr[0][0] != 0 {
+		t.Error("Unexpected measurements")
+	}
+	if string(quote.ReportData[:5]) != "nonce" {
+		t.Errorf("Unexpected report data %x", quote.ReportData[:5])
+	}
+	if err := quote.VerifySignature(); err != nil {
+		t.Errorf("VerifySignature failed: %v", err)
+	}
+
+	id := quote.EnclaveIdentity()
+	if id.HasSigner() {
+		t.Error("Expected zero MRSIGNER")
+	}
+	expected := tupleHash256([][]byte{quote.MrTd[:], quote.Rtmr[0][:], quote.Rtmr[1][:], quote.Rtmr[2][:], quote.Rtmr[3][:]}, 32, tdxIdentityCustomization)
+	if string(id.MrEnclave[:]) != string(expected) {
+		t.Errorf("Unexpected enclave identity %x", id.MrEnclave)
+	}
+}
+
+// Test that quotes with modified measurements or an unsupported format are rejected.
+func TestParseTDXQuote_Invalid(t *testing.T) {
+	raw := testTDXQuote(t, nil)
+	raw[tdxHeaderSize+tdxBodyRtmrOffset] ^= 1
+	quote, err := ParseTDXQuote(raw)
+	if err != nil {
+		t.Fatalf("ParseTDXQuote failed: %v", err)
+	}
+	if err := quote.VerifySignature(); err == nil {
+		t.Error("Expected signature of a modified quote to fail")
+	}
+
+	raw = testTDXQuote(t, nil)
+	binary.LittleEndian.PutUint16(raw[0:2], 3)
+	if _, err := ParseTDXQuote(raw); err == nil {
+		t.Error("Expected version 3 quote to fail")
+	}
+	if _, err := ParseTDXQuote(raw[:100]); err == nil {
+		t.Error("Expected truncated quote to fail")
+	}
+}
@@ -0,0 +1,167 @@
+package rofl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TEE types.
+const (
+	TEETDX = "tdx"
+	TEESGX = "sgx"
+)
+
+// App kinds.
+const (
+	KindContainer = "container"
+	KindRaw       = "raw"
+)
+
+// appIDPrefix is the bech32 human-readable part of ROFL app IDs.
+const appIDPrefix = "rofl"
+
+// appIDSize is the size of a decoded ROFL app ID (version byte and 20-byte identifier).
+const appIDSize = 21
+
+// ValidateAppID checks that an app ID is a well-formed rofl1... bech32 address.
+func ValidateAppID(appID string) error {
+	hrp, data, err := decodeBech32(appID)
+	if err != nil {
+		return fmt.Errorf("invalid app ID %q: %w", appID, err)
+	}
+	if hrp != appIDPrefix {
+		return fmt.Errorf("invalid app ID %q: expected %s1... address", appID, appIDPrefix)
+	}
+	if len(data) != appIDSize {
+		return fmt.Errorf("invalid app ID %q: expected %d bytes, got %d", appID, appIDSize, len(data))
+	}
+	return nil
+}
+
+// ValidateContractAddress checks that a contract address is a 0x-prefixed 20-byte hex address
+// with a valid EIP-55 checksum if it is mixed case.
+func ValidateContractAddress(address string) error {
+	if !strings.HasPrefix(address, "0x") || !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid contract address %q: expected 0x followed by 40 hex characters", address)
+	}
+	hex := address[2:]
+	if hex != strings.ToLower(hex) && hex != strings.ToUpper(hex) && common.HexToAddress(address).Hex() != address {
+		return fmt.Errorf("invalid contract address %q: bad checksum", address)
+	}
+	return nil
+}
+
+// deploymentNames returns the names of the manifest's deployments in sorted order.
+func (m *Manifest) deploymentNames() []string {
+	names := make([]string, 0, len(m.Deployments))
+	for name := range m.Deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks the manifest for invalid values. All problems found are returned joined.
+func (m *Manifest) Validate() error {
+	var errs []error
+	if m.License != "" {
+		if _, err := ParseLicense(m.License); err != nil {
+			errs = append(errs, fmt.Errorf("license: %w", err))
+		}
+	}
+	for _, name := range m.deploymentNames() {
+		deployment := m.Deployments[name]
+		if deployment == nil || deployment.AppID == "" {
+			continue
+		}
+		if err := ValidateAppID(deployment.AppID); err != nil {
+			errs = append(errs, fmt.Errorf("deployments.%s.app_id: %w", name, err))
+		}
+	}
+	for i, contract := range m.Contracts {
+		if contract.Network != "mainnet" && contract.Network != "testnet" {
+			errs = append(errs, fmt.Errorf("contracts[%d].network: expected mainnet or testnet, got %q", i, contract.Network))
+		}
+		if err := ValidateContractAddress(contract.Address); err != nil {
+			errs = append(errs, fmt.Errorf("contracts[%d].address: %w", i, err))
+		}
+	}
+	if err := m.ValidateTEE(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTEE checks that the kind, artifacts, and policy enclave identities of the manifest are
+// consistent with its TEE type. TDX apps boot a VM from firmware, kernel, and stage2 artifacts and
+// are identified by their measurements alone; SGX apps are raw enclaves also identified by their
+// signer. All problems found are returned joined.
+func (m *Manifest) ValidateTEE() error {
+	tee := strings.ToLower(m.TEE)
+	kind := strings.ToLower(m.Kind)
+	vmArtifacts := []struct{ field, value string }{
+		{"firmware", m.Artifacts.Firmware},
+		{"kernel", m.Artifacts.Kernel},
+		{"stage2", m.Artifacts.Stage2},
+	}
+
+	var errs []error
+	switch tee {
+	case TEETDX:
+		if kind != KindContainer && kind != KindRaw {
+			errs = append(errs, fmt.Errorf("kind: TDX apps must be %s or %s (got %q)", KindContainer, KindRaw, m.Kind))
+		}
+		for _, artifact := range vmArtifacts {
+			if artifact.value == "" {
+				errs = append(errs, fmt.Errorf("artifacts.%s: required for TDX apps", artifact.field))
+			}
+		}
+		if kind == KindContainer {
+			if m.Artifacts.Container.Runtime == "" {
+				errs = append(errs, fmt.Errorf("artifacts.container.runtime: required for container apps"))
+			}
+			if m.Artifacts.Container.Compose == "" {
+				errs = append(errs, fmt.Errorf("artifacts.container.compose: required for container apps"))
+			}
+		}
+	case TEESGX:
+		if kind != KindRaw {
+			errs = append(errs, fmt.Errorf("kind: SGX apps must be %s (got %q)", KindRaw, m.Kind))
+		}
+		if m.Artifacts.Container.Runtime != "" || m.Artifacts.Container.Compose != "" {
+			errs = append(errs, fmt.Errorf("artifacts.container: not used by SGX apps"))
+		}
+		for _, artifact := range vmArtifacts {
+			if artifact.value != "" {
+				errs = append(errs, fmt.Errorf("artifacts.%s: not used by SGX apps", artifact.field))
+			}
+		}
+	case "":
+		return fmt.Errorf("tee: not set")
+	default:
+		return fmt.Errorf("tee: unsupported TEE type %q (expected %s or %s)", m.TEE, TEETDX, TEESGX)
+	}
+
+	for _, name := range m.deploymentNames() {
+		deployment := m.Deployments[name]
+		if deployment == nil {
+			continue
+		}
+		for i, encoded := range deployment.Policy.Enclaves {
+			id, err := DecodeEnclaveIdentity(encoded)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("deployments.%s.policy.enclaves[%d]: %w", name, i, err))
+			case tee == TEETDX && id.HasSigner():
+				errs = append(errs, fmt.Errorf("deployments.%s.policy.enclaves[%d]: TDX identities have no MRSIGNER", name, i))
+			case tee == TEESGX && !id.HasSigner():
+				errs = append(errs, fmt.Errorf("deployments.%s.policy.enclaves[%d]: SGX identities require MRSIGNER", name, i))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+package rofl
+
+import "testing"
+
+// Test app ID validation against real and malformed addresses.
+func TestValidateAppID(t *testing.T) {
+	if err := ValidateAppID("rofl1qzp3c6zt96r5c5sw0sljlvepwgg4u23atgh4legq"); err != nil {
+		t.Errorf("Expected valid app ID, got: %v", err)
+	}
+
+	invalid := []string{
+		"rofl1test123",
+		"rofl1qzp3c6zt96r5c5sw0sljlvepwgg4u23atgh4legp", // Bad checksum.
+		"oasis1qzp3c6zt96r5c5sw0sljlvepwgg4u23atgh4legq",
+		"",
+	}
+	for _, appID := range invalid {
+		if err := ValidateAppID(appID); err == nil {
+			t.Errorf("Expected error for %q", appID)
+		}
+	}
+}
+
+// Test that TEE types are checked against kinds, artifacts, and policy enclave identities.
+func TestValidateTEE(t *testing.T) {
+	tdxID := "jypB1qfYh2YpoXQbDglIxMxHA2wqOWpH68cLAhp0CBkAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="
+	sgxID := "jypB1qfYh2YpoXQbDglIxMxHA2wqOWpH68cLAhp0CBmPKkHWp9iHZimhdBsOCUjEzEcDbCo5akfrxwsCGnQIGQ=="
+
+	tdx := &Manifest{
+		TEE:  "TDX",
+		Kind: "container",
+		Artifacts: Artifacts{
+			Firmware:  "firmware",
+			Kernel:    "kernel",
+			Stage2:    "stage2",
+			Container: ContainerArtifact{Runtime: "runtime", Compose: "compose.yaml"},
+		},
+		Deployments: map[string]*Deployment{"mainnet": {Policy: Policy{Enclaves: EnclaveList{tdxID}}}},
+	}
+	if err := tdx.ValidateTEE(); err != nil {
+		t.Errorf("Expected valid TDX manifest, got: %v", err)
+	}
+
+	sgx := &Manifest{
+		TEE:         "sgx",
+		Kind:        "raw",
+		Deployments: map[string]*Deployment{"mainnet": {Policy: Policy{Enclaves: EnclaveList{sgxID}}}},
+	}
+	if err := sgx.ValidateTEE(); err != nil {
+		t.Errorf("Expected valid SGX manifest, got: %v", err)
+	}
+
+	invalid := map[string]*Manifest{
+		"missing tee":           {Kind: "raw"},
+		"unknown tee":           {TEE: "sev", Kind: "raw"},
+		"tdx without stage2":    {TEE: "tdx", Kind: "raw", Artifacts: Artifacts{Firmware: "f", Kernel: "k"}},
+		"tdx without compose":   {TEE: "tdx", Kind: "container", Artifacts: Artifacts{Firmware: "f", Kernel: "k", Stage2: "s", Container: ContainerArtifact{Runtime: "r"}}},
+		"tdx with sgx identity": {TEE: "tdx", Kind: "raw", Artifacts: tdx.Artifacts, Deployments: map[string]*Deployment{"mainnet": {Policy: Policy{Enclaves: EnclaveList{sgxID}}}}},
+		"sgx container":         {TEE: "sgx", Kind: "container"},
+		"sgx with kernel":       {TEE: "sgx", Kind: "raw", Artifacts: Artifacts{Kernel: "k"}},
+		"sgx with tdx identity": {TEE: "sgx", Kind: "raw", Deployments: map[string]*Deployment{"mainnet": {Policy: Policy{Enclaves: EnclaveList{tdxID}}}}},
+		"malformed identity":    {TEE: "sgx", Kind: "raw", Deployments: map[string]*Deployment{"mainnet": {Policy: Policy{Enclaves: EnclaveList{"abc"}}}}},
+	}
+	for name, manifest := range invalid {
+		if err := manifest.ValidateTEE(); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+// Test contract address validation, including EIP-55 checksums of mixed-case addresses.
+func TestValidateContractAddress(t *testing.T) {
+	valid := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+	}
+	for _, address := range valid {
+		if err := ValidateContractAddress(address); err != nil {
+			t.Errorf("Expected valid address %q, got: %v", address, err)
+		}
+	}
+
+	invalid := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", // Bad checksum.
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+		"rofl1qzp3c6zt96r5c5sw0sljlvepwgg4u23atgh4legq",
+		"",
+	}
+	for _, address := range invalid {
+		if err := ValidateContractAddress(address); err == nil {
+			t.Errorf("Expected error for %q", address)
+		}
+	}
+}
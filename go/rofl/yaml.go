@@ -20,9 +20,11 @@ type Manifest struct {
 	Kind        string                 `yaml:"kind"`
 	Repository  string                 `yaml:"repository"`
 	Homepage    string                 `yaml:"homepage"`
+	Logo        string                 `yaml:"logo"` // Path in the repository or https URL.
 	Resources   Resources              `yaml:"resources"`
 	Artifacts   Artifacts              `yaml:"artifacts"`
 	Deployments map[string]*Deployment `yaml:"deployments"`
+	Contracts   []Contract             `yaml:"contracts"` // Smart contracts the app serves.
 	// Note: We only parse fields we actually use.
 	// Additional fields in rofl.yaml will not cause parsing errors.
 }
@@ -96,9 +98,24 @@ type Deployment struct {
 	Network string `yaml:"network"`
 	AppID   string `yaml:"app_id"` // ROFL app ID.
 	Policy  Policy `yaml:"policy"`
+	// Consensus block the app trusts, nil if the manifest does not pin one.
+	TrustRoot *TrustRoot `yaml:"trust_root"`
 	// Additional fields may be present but are not parsed.
 }
 
+// TrustRoot is the consensus block a deployment's light client trusts.
+type TrustRoot struct {
+	Height uint64 `yaml:"height"`
+	Hash   string `yaml:"hash"`
+}
+
+// Contract is a smart contract on Sapphire associated with the app.
+type Contract struct {
+	Network string `yaml:"network"` // "mainnet" or "testnet".
+	Address string `yaml:"address"` // 0x... address.
+	Label   string `yaml:"label"`   // Optional description, e.g. "Oracle".
+}
+
 // Parse parses a rofl.yaml from bytes.
 func Parse(data []byte) (*Manifest, error) {
 	var manifest Manifest
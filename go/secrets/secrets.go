@@ -0,0 +1,206 @@
+// Package secrets loads secrets from files and external secret stores.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/sigv4"
+)
+
+// maxSecretSize limits the size of secrets read from files and secret stores.
+const maxSecretSize = 64 * 1024
+
+// Provider fetches secrets from a secret store.
+type Provider interface {
+	// Get returns the value of the named secret.
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ReadFile reads a secret from a file, trimming surrounding whitespace.
+func ReadFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open secret file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSecretSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return secret, nil
+}
+
+// Load fetches the secret configured in cfg from its secret store.
+func Load(ctx context.Context, cfg *config.SecretConfig, client *http.Client) (string, error) {
+	provider, err := NewProvider(cfg, client)
+	if err != nil {
+		return "", err
+	}
+	return provider.Get(ctx, cfg.Name)
+}
+
+// NewProvider creates the secret store provider configured in cfg.
+func NewProvider(cfg *config.SecretConfig, client *http.Client) (Provider, error) {
+	switch cfg.Provider {
+	case config.SecretProviderVault:
+		address := cfg.VaultAddress
+		if address == "" {
+			address = os.Getenv("VAULT_ADDR")
+		}
+		token := cfg.VaultToken
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		if address == "" || token == "" {
+			return nil, fmt.Errorf("vault address and token are required (set VAULT_ADDR and VAULT_TOKEN)")
+		}
+		return &Vault{
+			client:  client,
+			address: strings.TrimRight(address, "/"),
+			token:   token,
+			mount:   cfg.VaultMount,
+			key:     cfg.Key,
+		}, nil
+	case config.SecretProviderAWS:
+		region := cfg.AWSRegion
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		creds := sigv4.Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if region == "" || creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return nil, fmt.Errorf("aws region and credentials are required (set AWS_REGION, AWS_ACCESS_KEY_ID, and AWS_SECRET_ACCESS_KEY)")
+		}
+		return &AWSSecretsManager{
+			client: client,
+			region: region,
+			creds:  creds,
+			key:    cfg.Key,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown secret provider %q", cfg.Provider)
+	}
+}
+
+// Vault is a Provider reading secrets from a HashiCorp Vault KV version 2 secrets engine.
+type Vault struct {
+	client  *http.Client
+	address string
+	token   string
+	mount   string
+	key     string
+}
+
+// Get implements Provider. The name is the path of the secret in the KV mount.
+func (v *Vault) Get(ctx context.Context, name string) (string, error) {
+	u := fmt.Sprintf("%s/v1/%s/data/%s", v.address, url.PathEscape(v.mount), strings.TrimPrefix(name, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	var result struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(v.client, req, &result); err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s: %w", name, err)
+	}
+
+	value, ok := result.Data.Data[v.key].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("vault secret %s has no %q field", name, v.key)
+	}
+	return value, nil
+}
+
+// AWSSecretsManager is a Provider reading secrets from AWS Secrets Manager.
+type AWSSecretsManager struct {
+	client *http.Client
+	region string
+	creds  sigv4.Credentials
+	key    string
+}
+
+// Get implements Provider. The name is the secret ID or ARN. Secrets holding a JSON object
+// are looked up by the configured key; other secrets are used as is.
+func (a *AWSSecretsManager) Get(ctx context.Context, name string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	u := fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", a.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	sigv4.Sign(req, payload, a.creds, a.region, "secretsmanager", time.Now())
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doJSON(a.client, req, &result); err != nil {
+		return "", fmt.Errorf("failed to read aws secret %s: %w", name, err)
+	}
+	if result.SecretString == "" {
+		return "", fmt.Errorf("aws secret %s has no string value", name)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(result.SecretString), &fields); err != nil {
+		return strings.TrimSpace(result.SecretString), nil
+	}
+	value, ok := fields[a.key].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("aws secret %s has no %q field", name, a.key)
+	}
+	return value, nil
+}
+
+// doJSON sends a request and decodes its JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package storage stores large artifacts in S3-compatible object storage.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/sigv4"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// maxObjectSize limits the size of objects read back from storage.
+const maxObjectSize = 64 * 1024 * 1024
+
+// Store is an object store for artifacts too large to keep in the database.
+type Store interface {
+	// Put stores data under key.
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get returns the data stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Artifacts keeps large artifacts out of the database. Artifacts above the configured
+// threshold are truncated in the database, and stored in full if object storage is configured.
+type Artifacts struct {
+	store     Store
+	threshold int
+}
+
+// New creates the artifact storage for the configuration.
+func New(cfg *config.StorageConfig, httpClient *http.Client) (*Artifacts, error) {
+	store, err := newStore(cfg, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifacts{store: store, threshold: cfg.Threshold}, nil
+}
+
+// Offload returns the content of an artifact to keep in the database and, if it was stored
+// in object storage, its key. Content above the threshold is truncated.
+func (a *Artifacts) Offload(ctx context.Context, key, contentType, content string) (string, string, error) {
+	if len(content) <= a.threshold {
+		return content, "", nil
+	}
+
+	excerpt := strings.ToValidUTF8(content[:a.threshold], "")
+	if a.store == nil {
+		return excerpt, "", nil
+	}
+	if err := a.store.Put(ctx, key, contentType, []byte(content)); err != nil {
+		return excerpt, "", err
+	}
+	return excerpt, key, nil
+}
+
+// Load returns an artifact stored by Offload.
+func (a *Artifacts) Load(ctx context.Context, key string) ([]byte, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("object storage is not configured")
+	}
+	return a.store.Get(ctx, key)
+}
+
+// newStore creates the configured store, or returns nil if object storage is disabled.
+func newStore(cfg *config.StorageConfig, httpClient *http.Client) (Store, error) {
+	switch cfg.Backend {
+	case config.StorageBackendNone:
+		return nil, nil
+	case config.StorageBackendS3:
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage endpoint: %w", err)
+		}
+		return &S3{
+			client:    httpClient,
+			endpoint:  endpoint,
+			region:    cfg.Region,
+			bucket:    cfg.Bucket,
+			prefix:    cfg.Prefix,
+			pathStyle: cfg.PathStyle,
+			accessKey: cfg.AccessKeyID,
+			secretKey: cfg.SecretAccessKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// S3 is a Store backed by an S3-compatible service, authenticated with AWS Signature Version 4.
+type S3 struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	pathStyle bool
+	accessKey string
+	secretKey string
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, key, contentType string, data []byte) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, data, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to store object: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to get object: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxObjectSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, nil
+}
+
+// newRequest creates a request for the object stored under key.
+func (s *S3) newRequest(ctx context.Context, method, key string, data []byte) (*http.Request, error) {
+	u := *s.endpoint
+	objectPath := "/" + s.prefix + key
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = objectPath
+	u.RawPath = escapePath(objectPath)
+
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return req, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req. All headers already set are signed.
+func (s *S3) sign(req *http.Request, payload []byte, now time.Time) {
+	sigv4.Sign(req, payload, sigv4.Credentials{AccessKeyID: s.accessKey, SecretAccessKey: s.secretKey}, s.region, "s3", now)
+}
+
+// escapePath URI-encodes each segment of an object path as required by S3.
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/ptrus/rofl-attestations/merkle"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+const (
+	// anchorReceiptTimeout is how long to wait for an anchor transaction to be included.
+	anchorReceiptTimeout = 2 * time.Minute
+	// anchorReceiptPoll is how often the receipt of an anchor transaction is polled.
+	anchorReceiptPoll = 5 * time.Second
+)
+
+// anchorSelector is the function selector of anchor(bytes32 root, uint256 size).
+var anchorSelector = crypto.Keccak256([]byte("anchor(bytes32,uint256)"))[:4]
+
+// errAnchorReverted is returned when an anchor transaction was included but failed.
+var errAnchorReverted = errors.New("anchor transaction reverted")
+
+// anchorLog periodically publishes the root of the verification log to the anchor contract.
+func (w *Worker) anchorLog(ctx context.Context) {
+	interval := time.Duration(w.cfg.Anchor.Interval) * time.Minute
+
+	w.logger.Info("anchoring verification log", "contract", w.cfg.Anchor.Contract, "address", w.anchorKey.Address().Hex(), "interval_minutes", w.cfg.Anchor.Interval)
+	for {
+		if err := w.anchorOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("failed to anchor verification log", "error", err)
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return
+		}
+	}
+}
+
+// anchorOnce publishes the current root of the verification log, unless it is empty or was already
+// anchored, and records the anchor once the transaction is included.
+func (w *Worker) anchorOnce(ctx context.Context) error {
+	size, root, err := w.db.GetEventLogRoot(ctx)
+	if err != nil {
+		return err
+	}
+	last, err := w.db.GetLastLogAnchor(ctx)
+	if err != nil {
+		return err
+	}
+	if size == 0 || (last != nil && last.Size == size && last.RootHash == root.String()) {
+		return nil
+	}
+
+	rpc := &ethRPC{client: w.external, url: w.cfg.Anchor.RPCURL}
+	contract := common.HexToAddress(w.cfg.Anchor.Contract)
+
+	var chainID, gasPrice hexutil.Big
+	var nonce hexutil.Uint64
+	if err := rpc.call(ctx, "eth_chainId", &chainID); err != nil {
+		return err
+	}
+	if err := rpc.call(ctx, "eth_getTransactionCount", &nonce, w.anchorKey.Address(), "pending"); err != nil {
+		return err
+	}
+	if err := rpc.call(ctx, "eth_gasPrice", &gasPrice); err != nil {
+		return err
+	}
+
+	raw, txHash, err := w.anchorKey.signLegacyTx(uint64(nonce), gasPrice.ToInt(), w.cfg.Anchor.GasLimit, contract, anchorCalldata(root, size), chainID.ToInt())
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := rpc.call(ctx, "eth_sendRawTransaction", nil, hexutil.Bytes(raw)); err != nil {
+		return err
+	}
+	w.logger.Info("sent anchor transaction", "tx_hash", txHash.Hex(), "size", size, "root_hash", root.String())
+
+	if err := rpc.waitForReceipt(ctx, txHash); err != nil {
+		return fmt.Errorf("transaction %s: %w", txHash.Hex(), err)
+	}
+
+	anchor := &models.LogAnchor{
+		Size:       size,
+		RootHash:   root.String(),
+		ChainID:    chainID.ToInt().Int64(),
+		Contract:   contract.Hex(),
+		TxHash:     txHash.Hex(),
+		AnchoredAt: time.Now(),
+	}
+	if err := w.db.RecordLogAnchor(ctx, anchor); err != nil {
+		return err
+	}
+	w.logger.Info("anchored verification log", "tx_hash", anchor.TxHash, "size", size, "root_hash", anchor.RootHash)
+	return nil
+}
+
+// anchorCalldata encodes a call of anchor(bytes32 root, uint256 size).
+func anchorCalldata(root merkle.Hash, size int64) []byte {
+	data := make([]byte, 0, len(anchorSelector)+2*32)
+	data = append(data, anchorSelector...)
+	data = append(data, root[:]...)
+	return append(data, common.LeftPadBytes(big.NewInt(size).Bytes(), 32)...)
+}
+
+// signLegacyTx signs a legacy transaction with EIP-155 replay protection and returns its raw
+// encoding and hash.
+func (s *KeySigner) signLegacyTx(nonce uint64, gasPrice *big.Int, gasLimit uint64, to common.Address, data []byte, chainID *big.Int) ([]byte, common.Hash, error) {
+	unsigned, err := rlp.EncodeToBytes([]any{nonce, gasPrice, gasLimit, to, big.NewInt(0), data, chainID, uint(0), uint(0)})
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(unsigned), s.privateKey)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	v := new(big.Int).Add(new(big.Int).Mul(chainID, big.NewInt(2)), big.NewInt(35+int64(sig[crypto.RecoveryIDOffset])))
+	r := new(big.Int).SetBytes(sig[:32])
+	sv := new(big.Int).SetBytes(sig[32:64])
+	raw, err := rlp.EncodeToBytes([]any{nonce, gasPrice, gasLimit, to, big.NewInt(0), data, v, r, sv})
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return raw, crypto.Keccak256Hash(raw), nil
+}
+
+// ethRPC is a minimal Ethereum JSON-RPC client.
+type ethRPC struct {
+	client *http.Client
+	url    string
+}
+
+// call invokes a JSON-RPC method and decodes its result into result, unless it is nil.
+func (c *ethRPC) call(ctx context.Context, method string, result any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: failed to send request: %w", method, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status code: %d", method, resp.StatusCode)
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: error %d: %s", method, response.Error.Code, response.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %w", method, err)
+	}
+	return nil
+}
+
+// waitForReceipt waits until a transaction is included and checks that it succeeded.
+func (c *ethRPC) waitForReceipt(ctx context.Context, txHash common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, anchorReceiptTimeout)
+	defer cancel()
+
+	for {
+		var receipt *struct {
+			Status hexutil.Uint64 `json:"status"`
+		}
+		if err := c.call(ctx, "eth_getTransactionReceipt", &receipt, txHash); err != nil {
+			return err
+		}
+		if receipt != nil {
+			if receipt.Status != 1 {
+				return errAnchorReverted
+			}
+			return nil
+		}
+		if err := sleep(ctx, anchorReceiptPoll); err != nil {
+			return fmt.Errorf("no receipt: %w", err)
+		}
+	}
+}
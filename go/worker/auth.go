@@ -3,9 +3,9 @@ package worker
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -18,48 +18,141 @@ import (
 	"github.com/spruceid/siwe-go"
 )
 
+// tokenLifetime is how long a JWT is assumed valid; the backend issues tokens for 12 hours.
+const tokenLifetime = 11 * time.Hour
+
+// ErrNoStandbyKey is returned by Rotate when there is no matching standby key.
+var ErrNoStandbyKey = errors.New("no matching standby key")
+
+// ErrInvalidKeyAddress is returned by Rotate when the address of the key to activate is invalid.
+var ErrInvalidKeyAddress = errors.New("invalid key address")
+
 // AuthClient handles SIWE authentication and JWT token management.
 type AuthClient struct {
 	backendURL string
-	privateKey *ecdsa.PrivateKey
-	address    common.Address
 	siweDomain string
 	chainID    int
+	client     *http.Client
 	logger     *slog.Logger
 
-	mu    sync.RWMutex
-	token string
-	exp   time.Time
-}
-
-// NewAuthClient creates a new authentication client.
-func NewAuthClient(backendURL, privateKeyHex, siweDomain string, chainID int, logger *slog.Logger) (*AuthClient, error) {
-	// Parse private key
-	privKeyBytes, err := hex.DecodeString(strings.TrimPrefix(privateKeyHex, "0x"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode private key: %w", err)
-	}
+	rotateMu sync.Mutex // Serializes rotations, without blocking token requests while signing in.
 
-	privateKey, err := crypto.ToECDSA(privKeyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
+	mu      sync.RWMutex
+	signers []Signer // Active signer first, followed by the standby signers.
+	token   string
+	exp     time.Time
+}
 
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+// KeyStatus describes a signing key of the auth client.
+type KeyStatus struct {
+	Address string `json:"address"`
+	Active  bool   `json:"active"`
+}
 
+// NewAuthClient creates a new authentication client, signing in with the first of signers and sending
+// requests to the backend via client. The remaining signers are standby keys that Rotate can switch to.
+func NewAuthClient(client *http.Client, backendURL string, signers []Signer, siweDomain string, chainID int, logger *slog.Logger) *AuthClient {
 	return &AuthClient{
 		backendURL: backendURL,
-		privateKey: privateKey,
-		address:    address,
+		signers:    signers,
 		siweDomain: siweDomain,
 		chainID:    chainID,
+		client:     client,
 		logger:     logger,
-	}, nil
+	}
 }
 
-// Address returns the Ethereum address of the auth client.
+// Address returns the Ethereum address of the active key.
 func (a *AuthClient) Address() common.Address {
-	return a.address
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.signers[0].Address()
+}
+
+// Keys returns the active key followed by the standby keys.
+func (a *AuthClient) Keys() []KeyStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	keys := make([]KeyStatus, 0, len(a.signers))
+	for i, signer := range a.signers {
+		keys = append(keys, KeyStatus{Address: signer.Address().Hex(), Active: i == 0})
+	}
+	return keys
+}
+
+// Rotate makes the standby key with the given address active, or the first standby key if address is empty.
+// The new key signs in before it is activated, so requests keep using the retired key's token until the
+// switch; the retired key becomes the last standby key and its token is discarded.
+func (a *AuthClient) Rotate(ctx context.Context, address string) (common.Address, error) {
+	a.rotateMu.Lock()
+	defer a.rotateMu.Unlock()
+
+	if address != "" && !common.IsHexAddress(address) {
+		return common.Address{}, fmt.Errorf("%w %q", ErrInvalidKeyAddress, address)
+	}
+
+	a.mu.RLock()
+	next := 1
+	if address != "" {
+		next = signerIndex(a.signers, common.HexToAddress(address))
+	}
+	var signer Signer
+	if next > 0 && next < len(a.signers) {
+		signer = a.signers[next]
+	}
+	a.mu.RUnlock()
+	if signer == nil {
+		return common.Address{}, ErrNoStandbyKey
+	}
+
+	// Only rotations change the order of the signers, so next still refers to signer afterwards.
+	token, err := a.performSIWELogin(ctx, signer)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to sign in with %s: %w", signer.Address().Hex(), err)
+	}
+
+	a.mu.Lock()
+	retired := a.signers[0]
+	a.signers = activateSigner(a.signers, next)
+	a.token = token
+	a.exp = time.Now().Add(tokenLifetime)
+	a.mu.Unlock()
+
+	a.logger.Info("rotated SIWE key", "address", signer.Address().Hex(), "retired", retired.Address().Hex())
+
+	return signer.Address(), nil
+}
+
+// ActivateSigner reorders signers so that the standby signer with the given address is active, as
+// after rotating to it. It reports whether such a signer exists.
+func ActivateSigner(signers []Signer, address common.Address) ([]Signer, bool) {
+	next := signerIndex(signers, address)
+	if next <= 0 {
+		return signers, false
+	}
+	return activateSigner(signers, next), true
+}
+
+// signerIndex returns the index of the signer with the given address, or -1 if there is none.
+func signerIndex(signers []Signer, address common.Address) int {
+	for i, signer := range signers {
+		if signer.Address() == address {
+			return i
+		}
+	}
+	return -1
+}
+
+// activateSigner returns signers with the standby signer at index next made active, and the active
+// signer retired to the end.
+func activateSigner(signers []Signer, next int) []Signer {
+	reordered := []Signer{signers[next]}
+	for i, s := range signers[1:] {
+		if i+1 != next {
+			reordered = append(reordered, s)
+		}
+	}
+	return append(reordered, signers[0])
 }
 
 // GetToken returns a valid JWT token, refreshing if necessary.
@@ -83,24 +176,26 @@ func (a *AuthClient) GetToken(ctx context.Context) (string, error) {
 	}
 
 	// Perform SIWE login
-	token, err := a.performSIWELogin(ctx)
+	signer := a.signers[0]
+	token, err := a.performSIWELogin(ctx, signer)
 	if err != nil {
 		return "", fmt.Errorf("failed to perform SIWE login: %w", err)
 	}
 
-	// Estimate expiry (12 hours is the default from backend)
 	a.token = token
-	a.exp = time.Now().Add(11 * time.Hour) // 11 hours to be safe
+	a.exp = time.Now().Add(tokenLifetime)
 
-	a.logger.Info("obtained new JWT token", "address", a.address.Hex())
+	a.logger.Info("obtained new JWT token", "address", signer.Address().Hex())
 
 	return a.token, nil
 }
 
-// performSIWELogin executes the complete SIWE authentication flow.
-func (a *AuthClient) performSIWELogin(ctx context.Context) (string, error) {
+// performSIWELogin executes the complete SIWE authentication flow for signer.
+func (a *AuthClient) performSIWELogin(ctx context.Context, signer Signer) (string, error) {
+	address := signer.Address()
+
 	// Step 1: Get nonce
-	nonce, err := a.getNonce(ctx)
+	nonce, err := a.getNonce(ctx, address)
 	if err != nil {
 		return "", fmt.Errorf("failed to get nonce: %w", err)
 	}
@@ -108,7 +203,7 @@ func (a *AuthClient) performSIWELogin(ctx context.Context) (string, error) {
 	// Step 2: Build SIWE message
 	msg, err := siwe.InitMessage(
 		a.siweDomain,
-		a.address.Hex(),
+		address.Hex(),
 		"http://"+a.siweDomain,
 		nonce,
 		map[string]interface{}{
@@ -122,14 +217,13 @@ func (a *AuthClient) performSIWELogin(ctx context.Context) (string, error) {
 	}
 
 	// Step 3: Sign the message
-	msgHash := signHash([]byte(msg.String()))
-	sig, err := crypto.Sign(msgHash.Bytes(), a.privateKey)
+	sig, err := signer.SignMessage(ctx, []byte(msg.String()))
 	if err != nil {
 		return "", fmt.Errorf("failed to sign message: %w", err)
 	}
 
 	// Step 4: Authenticate with backend
-	token, err := a.authenticate(ctx, msg.String(), sig)
+	token, err := a.authenticate(ctx, address, msg.String(), sig)
 	if err != nil {
 		return "", fmt.Errorf("failed to authenticate: %w", err)
 	}
@@ -138,15 +232,14 @@ func (a *AuthClient) performSIWELogin(ctx context.Context) (string, error) {
 }
 
 // getNonce requests a nonce from the backend.
-func (a *AuthClient) getNonce(ctx context.Context) (string, error) {
-	url := fmt.Sprintf("%s/auth/nonce?address=%s", a.backendURL, a.address.Hex())
+func (a *AuthClient) getNonce(ctx context.Context, address common.Address) (string, error) {
+	url := fmt.Sprintf("%s/auth/nonce?address=%s", a.backendURL, address.Hex())
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := a.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -169,7 +262,7 @@ func (a *AuthClient) getNonce(ctx context.Context) (string, error) {
 }
 
 // authenticate sends the signed SIWE message to the backend and receives a JWT token.
-func (a *AuthClient) authenticate(ctx context.Context, message string, signature []byte) (string, error) {
+func (a *AuthClient) authenticate(ctx context.Context, address common.Address, message string, signature []byte) (string, error) {
 	// Prepare request body
 	payload := map[string]string{
 		"message": message,
@@ -189,8 +282,7 @@ func (a *AuthClient) authenticate(ctx context.Context, message string, signature
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := a.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -214,8 +306,8 @@ func (a *AuthClient) authenticate(ctx context.Context, message string, signature
 		return "", fmt.Errorf("empty token in response")
 	}
 
-	if !strings.EqualFold(result.Address, a.address.Hex()) {
-		return "", fmt.Errorf("address mismatch: expected %s, got %s", a.address.Hex(), result.Address)
+	if !strings.EqualFold(result.Address, address.Hex()) {
+		return "", fmt.Errorf("address mismatch: expected %s, got %s", address.Hex(), result.Address)
 	}
 
 	return result.Token, nil
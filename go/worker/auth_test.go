@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestSigners returns n signers with new random keys.
+func newTestSigners(t *testing.T, n int) []Signer {
+	t.Helper()
+	signers := make([]Signer, 0, n)
+	for range n {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		signers = append(signers, &KeySigner{privateKey: key, address: crypto.PubkeyToAddress(key.PublicKey)})
+	}
+	return signers
+}
+
+// Test that a rotation signs in with the standby key without blocking token requests, activates it,
+// and retires the previous key, and that invalid and unknown addresses are rejected.
+func TestAuthClientRotate(t *testing.T) {
+	ctx := context.Background()
+	signers := newTestSigners(t, 3)
+
+	// The backend signs in any address, holding back sign-ins of the second key until released.
+	release := make(chan struct{})
+	signingIn := make(chan struct{})
+	var mu sync.Mutex
+	var address string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/nonce":
+			mu.Lock()
+			address = r.URL.Query().Get("address")
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]string{"nonce": "abcdefgh12345678"})
+		case "/auth/login":
+			mu.Lock()
+			addr := address
+			mu.Unlock()
+			if addr == signers[1].Address().Hex() {
+				close(signingIn)
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "token-" + addr, "address": addr})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer backend.Close()
+
+	auth := NewAuthClient(backend.Client(), backend.URL, signers, "localhost", 0x5aff, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	token, err := auth.GetToken(ctx)
+	if err != nil || token != "token-"+signers[0].Address().Hex() {
+		t.Fatalf("Failed to get token: %q, %v", token, err)
+	}
+
+	if _, err := auth.Rotate(ctx, "not-an-address"); !errors.Is(err, ErrInvalidKeyAddress) {
+		t.Errorf("Expected ErrInvalidKeyAddress, got %v", err)
+	}
+	if _, err := auth.Rotate(ctx, signers[0].Address().Hex()); !errors.Is(err, ErrNoStandbyKey) {
+		t.Errorf("Rotating to the active key: expected ErrNoStandbyKey, got %v", err)
+	}
+
+	rotated := make(chan error, 1)
+	go func() {
+		_, err := auth.Rotate(ctx, "")
+		rotated <- err
+	}()
+	<-signingIn
+	tokenDone := make(chan string, 1)
+	go func() {
+		token, _ := auth.GetToken(ctx)
+		tokenDone <- token
+	}()
+	select {
+	case token := <-tokenDone:
+		if token != "token-"+signers[0].Address().Hex() {
+			t.Errorf("Expected the token of the active key during the rotation, got %q", token)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Token request blocked by the rotation")
+	}
+	close(release)
+	if err := <-rotated; err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+
+	var order []string
+	for _, key := range auth.Keys() {
+		order = append(order, key.Address)
+	}
+	want := []string{signers[1].Address().Hex(), signers[2].Address().Hex(), signers[0].Address().Hex()}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected keys after rotation: %v", order)
+	}
+	if token, err := auth.GetToken(ctx); err != nil || token != "token-"+signers[1].Address().Hex() {
+		t.Errorf("Expected the token of the new key, got %q, %v", token, err)
+	}
+}
+
+// Test that the key activated by a rotation is activated again at startup, if configured.
+func TestActivateSigner(t *testing.T) {
+	signers := newTestSigners(t, 3)
+
+	activated, ok := ActivateSigner(signers, signers[2].Address())
+	if !ok || activated[0] != signers[2] || activated[1] != signers[1] || activated[2] != signers[0] {
+		t.Errorf("Unexpected signers after activating a standby key: %v", activated)
+	}
+	for _, address := range []common.Address{signers[0].Address(), {}} {
+		if activated, ok := ActivateSigner(signers, address); ok || activated[0] != signers[0] {
+			t.Errorf("Activating %s changed the active signer", address.Hex())
+		}
+	}
+}
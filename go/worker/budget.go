@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TaskClass is the kind of a backend task competing for the budget.
+type TaskClass int
+
+// Kinds of backend tasks.
+const (
+	TaskPeriodic    TaskClass = iota // Submitted by the worker's verification cycle.
+	TaskInteractive                  // Submitted by a user through the API.
+	numTaskClasses
+)
+
+// Budget limits the backend tasks in flight, shared by the worker and verifications requested
+// through the API. When tasks of both kinds wait for a slot, freed slots are granted to them in
+// turn, so that neither starves the other.
+type Budget struct {
+	mu       sync.Mutex
+	limit    int // 0 = unlimited.
+	inFlight int
+	waiting  [numTaskClasses][]chan struct{}
+	turn     TaskClass           // Kind of task served first when both wait.
+	held     map[string]struct{} // Slots held by interactive tasks until they finish, by task ID.
+}
+
+// NewBudget creates a budget of limit backend tasks in flight, 0 for unlimited.
+func NewBudget(limit int) *Budget {
+	return &Budget{limit: limit, held: make(map[string]struct{})}
+}
+
+// Acquire waits for a slot for a task of the given kind, until the context is done.
+func (b *Budget) Acquire(ctx context.Context, class TaskClass) error {
+	b.mu.Lock()
+	if b.limit <= 0 || b.inFlight < b.limit {
+		b.inFlight++
+		b.mu.Unlock()
+		return nil
+	}
+	granted := make(chan struct{})
+	b.waiting[class] = append(b.waiting[class], granted)
+	b.mu.Unlock()
+
+	select {
+	case <-granted:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if i := slices.Index(b.waiting[class], granted); i >= 0 {
+			b.waiting[class] = slices.Delete(b.waiting[class], i, i+1)
+		} else {
+			// Granted concurrently, pass the slot on.
+			b.releaseLocked()
+		}
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot acquired with Acquire.
+func (b *Budget) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.releaseLocked()
+}
+
+// Hold keeps an acquired slot until the task is reported done, or for at most ttl if it never is,
+// e.g. because the user stopped polling its results.
+func (b *Budget) Hold(taskID string, ttl time.Duration) {
+	b.mu.Lock()
+	b.held[taskID] = struct{}{}
+	b.mu.Unlock()
+	time.AfterFunc(ttl, func() { b.Done(taskID) })
+}
+
+// Done frees the slot held by a task, if any.
+func (b *Budget) Done(taskID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.held[taskID]; !ok {
+		return
+	}
+	delete(b.held, taskID)
+	b.releaseLocked()
+}
+
+// InFlight returns the number of slots in use.
+func (b *Budget) InFlight() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight
+}
+
+// releaseLocked hands a freed slot to the next waiting task, alternating between the kinds of
+// tasks, or returns it to the budget if none waits.
+func (b *Budget) releaseLocked() {
+	for i := range numTaskClasses {
+		class := (b.turn + i) % numTaskClasses
+		if len(b.waiting[class]) == 0 {
+			continue
+		}
+		close(b.waiting[class][0])
+		b.waiting[class] = b.waiting[class][1:]
+		b.turn = (class + 1) % numTaskClasses
+		return
+	}
+	b.inFlight--
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// Domain verification methods.
+const (
+	domainMethodDNS       = "dns"
+	domainMethodWellKnown = "well-known"
+)
+
+// domainTXTPrefix prefixes app IDs in DNS TXT records proving control of a domain.
+const domainTXTPrefix = "rofl-registry="
+
+// wellKnownPath is the path of the file listing app IDs on a homepage domain.
+const wellKnownPath = "/.well-known/rofl-registry"
+
+// checkDomain checks that the homepage domain of an app references the app ID of one of its
+// verified deployments and records the outcome.
+func (w *Worker) checkDomain(ctx context.Context, app *models.App, manifest *rofl.Manifest) error {
+	domain, method, checkErr := w.verifyDomain(ctx, app, manifest)
+	msg := ""
+	if checkErr != nil {
+		domain, method, msg = "", "", checkErr.Error()
+	}
+	if err := w.db.RecordDomainCheck(ctx, app.ID, domain, method, msg); err != nil {
+		return err
+	}
+	if checkErr == nil {
+		w.logger.Info("homepage domain verified", "app_id", app.ID, "domain", domain, "method", method)
+	}
+	return checkErr
+}
+
+// verifyDomain returns the homepage domain of an app and how it was proven to reference the app.
+// Domains are proven by a DNS TXT record "rofl-registry=<app_id>" or by listing the app ID in
+// /.well-known/rofl-registry, one per line.
+func (w *Worker) verifyDomain(ctx context.Context, app *models.App, manifest *rofl.Manifest) (string, string, error) {
+	u, err := url.Parse(manifest.Homepage)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return "", "", fmt.Errorf("homepage %q is not an https URL", manifest.Homepage)
+	}
+	domain := strings.ToLower(u.Hostname())
+
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, app.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get deployments: %w", err)
+	}
+	appIDs := make(map[string]bool)
+	for _, deployment := range deployments {
+		spec := manifest.Deployments[deployment.DeploymentName]
+		if deployment.Status == models.StatusVerified && spec != nil && spec.AppID != "" {
+			appIDs[spec.AppID] = true
+		}
+	}
+	if len(appIDs) == 0 {
+		return "", "", errNoVerifiedDeployment
+	}
+
+	records, dnsErr := net.DefaultResolver.LookupTXT(ctx, domain)
+	for _, record := range records {
+		if appID, ok := strings.CutPrefix(strings.TrimSpace(record), domainTXTPrefix); ok && appIDs[appID] {
+			return domain, domainMethodDNS, nil
+		}
+	}
+
+	listed, err := w.fetchWellKnownAppIDs(ctx, domain)
+	if err != nil {
+		var dnsError *net.DNSError
+		if dnsErr != nil && !(errors.As(dnsErr, &dnsError) && dnsError.IsNotFound) {
+			return "", "", fmt.Errorf("failed to look up TXT records: %w; %w", dnsErr, err)
+		}
+		return "", "", fmt.Errorf("no TXT record references the app and %w", err)
+	}
+	for _, appID := range listed {
+		if appIDs[appID] {
+			return domain, domainMethodWellKnown, nil
+		}
+	}
+	return "", "", fmt.Errorf("neither a TXT record nor %s references the app", wellKnownPath)
+}
+
+// fetchWellKnownAppIDs fetches the app IDs listed in the well-known file of a domain.
+func (w *Worker) fetchWellKnownAppIDs(ctx context.Context, domain string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+domain+wellKnownPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", wellKnownPath, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP %d", wellKnownPath, resp.StatusCode)
+	}
+
+	var appIDs []string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 64<<10))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			appIDs = append(appIDs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", wellKnownPath, err)
+	}
+	return appIDs, nil
+}
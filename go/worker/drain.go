@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// drainContext returns the context verifications run with. It outlives ctx by the shutdown grace
+// period, so that on shutdown the verification in progress can finish and record its result
+// instead of abandoning the backend task. The returned function releases it.
+func (w *Worker) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	workCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	grace := time.Duration(w.cfg.ShutdownGracePeriod) * time.Second
+
+	stop := context.AfterFunc(ctx, func() {
+		w.mu.Lock()
+		w.draining = true
+		w.mu.Unlock()
+
+		select {
+		case <-workCtx.Done():
+		case <-time.After(grace):
+			if w.cfg.Queue.Backend == config.QueueBackendRedis {
+				w.logger.Warn("shutdown grace period expired, leaving the task in progress in the queue to resume", "grace_period", grace)
+			} else {
+				w.logger.Warn("shutdown grace period expired, abandoning the task in progress", "grace_period", grace)
+			}
+			cancel()
+		}
+	})
+	return workCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// isDraining reports whether the worker is shutting down and should not start more work.
+func (w *Worker) isDraining() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.draining
+}
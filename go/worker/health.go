@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Backend health states.
+const (
+	BackendUnknown     = "unknown"     // Not probed yet.
+	BackendHealthy     = "healthy"     // Responded successfully.
+	BackendDegraded    = "degraded"    // Responded with an error or reported itself unhealthy, or was slow.
+	BackendUnreachable = "unreachable" // Did not respond.
+)
+
+// slowBackendThreshold is the response time above which the backend is considered degraded.
+const slowBackendThreshold = 5 * time.Second
+
+// BackendHealth is the result of the last probe of the verification backend.
+type BackendHealth struct {
+	Status    string     `json:"status"`
+	Version   string     `json:"version,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	LatencyMS int64      `json:"latency_ms,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// backendHealthResponse is the response of the backend health endpoint. Both fields are optional.
+type backendHealthResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+// BackendHealth returns the result of the last probe of the verification backend.
+func (w *Worker) BackendHealth() BackendHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.backendHealth.Status == "" {
+		return BackendHealth{Status: BackendUnknown}
+	}
+	return w.backendHealth
+}
+
+// monitorBackend periodically probes the health endpoint of the verification backend.
+func (w *Worker) monitorBackend(ctx context.Context) {
+	interval := time.Duration(w.cfg.HealthInterval) * time.Second
+
+	for {
+		health := w.probeBackend(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.mu.Lock()
+		previous := w.backendHealth.Status
+		w.backendHealth = health
+		w.mu.Unlock()
+
+		if health.Status != previous {
+			w.logger.Info("backend health changed", "status", health.Status, "version", health.Version, "error", health.Error)
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			return
+		}
+	}
+}
+
+// probeBackend requests the health endpoint of the verification backend once.
+func (w *Worker) probeBackend(ctx context.Context) BackendHealth {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	health := BackendHealth{CheckedAt: &start}
+
+	url := strings.TrimSuffix(w.cfg.BackendURL, "/") + w.cfg.HealthPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		health.Status = BackendUnreachable
+		health.Error = fmt.Sprintf("failed to create request: %v", err)
+		return health
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		health.Status = BackendUnreachable
+		health.Error = err.Error()
+		return health
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	latency := time.Since(start)
+	health.LatencyMS = latency.Milliseconds()
+
+	// The health endpoint may report its status and version as JSON; plain responses are accepted too.
+	var body backendHealthResponse
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = json.Unmarshal(data, &body)
+	health.Version = strings.TrimPrefix(body.Version, "v")
+
+	switch status := strings.ToLower(body.Status); {
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		health.Status = BackendDegraded
+		health.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	case status != "" && status != "ok" && status != "healthy" && status != "pass" && status != "up":
+		health.Status = BackendDegraded
+		health.Error = fmt.Sprintf("backend reported status %q", body.Status)
+	case latency > slowBackendThreshold:
+		health.Status = BackendDegraded
+		health.Error = fmt.Sprintf("slow response (%s)", latency.Round(time.Millisecond))
+	default:
+		health.Status = BackendHealthy
+	}
+	return health
+}
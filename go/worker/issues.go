@@ -0,0 +1,223 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// githubIssueChannel is the outbox channel of GitHub issues.
+const githubIssueChannel = "github_issue"
+
+// githubIssueNotifier files an issue in the repository of an app once a deployment failed
+// verification the configured number of times in a row, or comments on the issue if it is still
+// open from an earlier failure, and closes it once the deployment is verified again. Issues are only
+// filed for the apps configured with a token. The destination is the repository URL.
+//
+// Failures in a row do not change the status of a deployment, so filing is queued by
+// queueGitHubIssue rather than by the status event dispatch, which only queues the closing.
+type githubIssueNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *githubIssueNotifier) Channel() string {
+	return githubIssueChannel
+}
+
+// Destinations implements Notifier.
+func (n *githubIssueNotifier) Destinations(_ context.Context, notification *DeploymentNotification) ([]string, error) {
+	if notification.PreviousStatus != models.StatusFailed || notification.Status != models.StatusVerified {
+		return nil, nil
+	}
+	if n.w.issueToken(notification.GitHubURL) == "" {
+		return nil, nil
+	}
+	return []string{notification.GitHubURL}, nil
+}
+
+// Send implements Notifier.
+func (n *githubIssueNotifier) Send(ctx context.Context, repoURL string, notification *DeploymentNotification) error {
+	cfg := &n.w.cfg.Notifications.GitHubIssues
+	token := n.w.issueToken(repoURL)
+	if token == "" {
+		return permanent(fmt.Errorf("issues are not filed for %s", repoURL))
+	}
+
+	title := issueTitle(notification)
+	issue, err := n.w.github.FindOpenIssue(ctx, repoURL, token, cfg.Label, title)
+	if err != nil {
+		return githubIssueError(err)
+	}
+
+	if notification.Status == models.StatusVerified {
+		if issue == nil {
+			return nil
+		}
+		body := fmt.Sprintf("Deployment `%s` was verified again", notification.Deployment)
+		if notification.CommitSHA != "" {
+			body += fmt.Sprintf(" at commit %s", notification.CommitSHA)
+		}
+		body += ", closing.\n\n" + n.w.issueFooter(ctx, notification)
+		if err := n.w.github.CommentOnIssue(ctx, repoURL, token, issue.Number, body); err != nil {
+			return githubIssueError(err)
+		}
+		return githubIssueError(n.w.github.CloseIssue(ctx, repoURL, token, issue.Number))
+	}
+
+	body, err := n.w.issueBody(ctx, notification)
+	if err != nil {
+		return err
+	}
+	if issue != nil {
+		return githubIssueError(n.w.github.CommentOnIssue(ctx, repoURL, token, issue.Number, body))
+	}
+	issue, err = n.w.github.CreateIssue(ctx, repoURL, token, cfg.Label, title, body)
+	if err != nil {
+		return githubIssueError(err)
+	}
+	n.w.logger.Info("filed GitHub issue", "app_id", notification.AppID, "deployment", notification.Deployment, "issue_url", issue.HTMLURL)
+	return nil
+}
+
+// queueGitHubIssue queues filing an issue about a deployment that just failed verification as many
+// times in a row as configured. The delivery belongs to the status event the deployment failed
+// with, so an issue is filed once per run of failures.
+func (w *Worker) queueGitHubIssue(ctx context.Context, app *models.App, deploymentName string) error {
+	if w.issueToken(app.GitHubURL) == "" {
+		return nil
+	}
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	var deployment *models.Deployment
+	for _, d := range deployments {
+		if d.DeploymentName == deploymentName {
+			deployment = d
+		}
+	}
+	if deployment == nil || deployment.Status != models.StatusFailed || deployment.FailedStreak != int64(w.cfg.Notifications.GitHubIssues.After) {
+		return nil
+	}
+	event, err := w.db.GetLastDeploymentStatusEvent(ctx, app.ID, deploymentName)
+	if err != nil || event == nil {
+		return err
+	}
+
+	notification := w.newNotification(ctx, event)
+	notification.CommitSHA = deployment.CommitSHA.String
+	notification.Message = deployment.VerificationMsg.String
+	notification.Timestamp = time.Now().UTC()
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	delivery := &models.NotificationDelivery{
+		EventID:     event.ID,
+		Channel:     githubIssueChannel,
+		Destination: app.GitHubURL,
+		Payload:     payload,
+		Priority:    severityRank(notification.Severity),
+	}
+	if err := w.db.EnqueueNotifications(ctx, []*models.NotificationDelivery{delivery}); err != nil {
+		return err
+	}
+	select {
+	case w.notifyNow <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// issueToken returns the token issues are filed in the repository of an app with, or an empty
+// string if issues are not filed for it.
+func (w *Worker) issueToken(repoURL string) string {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return ""
+	}
+	return w.cfg.Notifications.GitHubIssues.TokenFor(owner, repo)
+}
+
+// issueTitle returns the title of the issue of a deployment, by which it is found again.
+func issueTitle(notification *DeploymentNotification) string {
+	return fmt.Sprintf("ROFL registry: deployment %s fails verification", notification.Deployment)
+}
+
+// issueBody formats the issue of a failing deployment as GitHub Markdown, with the enclave
+// identities that were not built as expected.
+func (w *Worker) issueBody(ctx context.Context, notification *DeploymentNotification) (string, error) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Deployment `%s` (%s) failed verification %d times in a row.\n\n",
+		notification.Deployment, notification.Network, w.cfg.Notifications.GitHubIssues.After)
+	if notification.CommitSHA != "" {
+		fmt.Fprintf(&body, "**Commit:** %s\n\n", notification.CommitSHA)
+	}
+	if notification.Message != "" {
+		fmt.Fprintf(&body, "```\n%s\n```\n\n", strings.ReplaceAll(notification.Message, "```", "'''"))
+	}
+
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, notification.AppID)
+	if err != nil {
+		return "", err
+	}
+	for _, deployment := range deployments {
+		if deployment.DeploymentName != notification.Deployment {
+			continue
+		}
+		if mismatched := mismatchedIdentities(deployment.VerificationReport); len(mismatched) > 0 {
+			body.WriteString("| Enclave | Expected | Built |\n| --- | --- | --- |\n")
+			for _, enclave := range mismatched {
+				fmt.Fprintf(&body, "| %s | %s | %s |\n", identitySourceName(enclave.Source), markdownCode(enclave.Expected), markdownCode(enclave.Actual))
+			}
+			body.WriteString("\n")
+		}
+	}
+
+	body.WriteString(w.issueFooter(ctx, notification))
+	return body.String(), nil
+}
+
+// issueFooter links to the registry entry of the app, in the namespace it is listed in, and
+// explains where the issue comes from.
+func (w *Worker) issueFooter(ctx context.Context, notification *DeploymentNotification) string {
+	namespace := models.DefaultNamespace
+	if app, err := w.db.GetAppByID(ctx, notification.AppID); err == nil {
+		namespace = app.Namespace
+	}
+	return fmt.Sprintf("Registry entry: %s/apps/%d\n\n<sub>Filed by the ROFL registry, which closes this issue once the deployment is verified again.</sub>\n",
+		w.cfg.Notifications.GitHubIssues.LinkURL(namespace), notification.AppID)
+}
+
+// identitySourceName describes where the expected identities of a report come from.
+func identitySourceName(source string) string {
+	if source == models.IdentitySourceOnChain {
+		return "On-chain policy"
+	}
+	return "Manifest"
+}
+
+// markdownCode formats an enclave identity as Markdown code, or "none" if it is missing.
+func markdownCode(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return "`" + s + "`"
+}
+
+// githubIssueError marks rejected GitHub API requests as permanent failures, like other HTTP
+// responses of notification channels.
+func githubIssueError(err error) error {
+	var statusErr *github.StatusError
+	if errors.As(err, &statusErr) {
+		return statusError(statusErr.StatusCode, err)
+	}
+	return err
+}
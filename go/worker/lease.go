@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// leaseDuration returns how long an app lease is held before other instances may take over.
+func (w *Worker) leaseDuration() time.Duration {
+	return time.Duration(w.cfg.LeaseDuration) * time.Minute
+}
+
+// claimApp acquires the lease of an app, so that replicas sharing the database do not verify
+// it concurrently. Apps another instance already attempted since cycleStart are not claimed.
+func (w *Worker) claimApp(ctx context.Context, app *models.App, cycleStart time.Time) (bool, error) {
+	return w.db.ClaimApp(ctx, app.ID, w.cfg.InstanceID, time.Now().Add(w.leaseDuration()), cycleStart)
+}
+
+// renewLease extends the lease of an app being verified, failing if it was taken over.
+func (w *Worker) renewLease(ctx context.Context, app *models.App) error {
+	held, err := w.db.RenewAppLease(ctx, app.ID, w.cfg.InstanceID, time.Now().Add(w.leaseDuration()))
+	if err != nil {
+		return err
+	}
+	if !held {
+		return fmt.Errorf("lease on app %d lost to another instance", app.ID)
+	}
+	return nil
+}
+
+// releaseApp releases the lease of an app after an attempt, even if ctx was cancelled meanwhile.
+func (w *Worker) releaseApp(ctx context.Context, app *models.App) {
+	if err := w.db.ReleaseApp(context.WithoutCancel(ctx), app.ID, w.cfg.InstanceID); err != nil {
+		w.logger.Warn("failed to release app lease", "app_id", app.ID, "error", err)
+	}
+}
+
+// ackApp acknowledges an app taken from the queue, even if ctx was cancelled meanwhile.
+func (w *Worker) ackApp(ctx context.Context, appID int64) {
+	if err := w.queue.Ack(context.WithoutCancel(ctx), appID); err != nil {
+		w.logger.Warn("failed to acknowledge queued app", "app_id", appID, "error", err)
+	}
+}
@@ -0,0 +1,208 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register GIF decoding.
+	_ "image/jpeg" // Register JPEG decoding.
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+const (
+	// maxLogoSize is the maximum size of a fetched logo.
+	maxLogoSize = 1 << 20 // 1 MiB
+	// maxLogoPixels bounds the decoded size of a logo, protecting against decompression bombs.
+	maxLogoPixels = 4096 * 4096
+	// logoSize is the width and height logos are scaled down to fit.
+	logoSize = 128
+	// logoRefreshInterval is how often an unchanged logo is fetched again.
+	logoRefreshInterval = 7 * 24 * time.Hour
+)
+
+// logoContentTypes are the accepted logo image types. SVG is not accepted, as it may contain scripts.
+var logoContentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// logoSource returns the URL of an app's logo at commitSHA, or an empty string if it has none.
+// A logo declared in the registry takes precedence over the manifest one. Paths are relative
+// to the repository root.
+func logoSource(app *models.App, manifestLogo, commitSHA string) (string, error) {
+	logo := manifestLogo
+	if app.LogoURL.Valid {
+		logo = app.LogoURL.String
+	}
+	logo = strings.TrimSpace(logo)
+
+	switch {
+	case logo == "":
+		return "", nil
+	case strings.Contains(logo, "://"):
+		u, err := url.Parse(logo)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("logo URL must be https (got %q)", logo)
+		}
+		return u.String(), nil
+	default:
+		clean := path.Clean("/" + logo)
+		return github.RawURL(app.GitHubURL, commitSHA, strings.TrimPrefix(clean, "/")), nil
+	}
+}
+
+// fetchLogo fetches an app's logo and stores a sanitized copy, unless the stored one is from
+// the same source and recent.
+func (w *Worker) fetchLogo(ctx context.Context, app *models.App, manifestLogo, commitSHA string) error {
+	source, err := logoSource(app, manifestLogo, commitSHA)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		// The logo was removed.
+		if app.HasLogo {
+			return w.db.UpdateAppLogo(ctx, app.ID, "", nil)
+		}
+		return nil
+	}
+	if app.HasLogo && app.LogoSource.String == source && time.Since(app.LogoUpdatedAt.Time) < logoRefreshInterval {
+		return nil
+	}
+
+	logo, err := w.downloadLogo(ctx, source)
+	if err != nil {
+		return err
+	}
+	if err := w.db.UpdateAppLogo(ctx, app.ID, source, logo); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+
+	w.logger.Debug("successfully fetched logo", "app_id", app.ID, "source", source, "size", len(logo))
+	return nil
+}
+
+// downloadLogo fetches a logo and returns it re-encoded as a PNG that fits logoSize, which drops
+// anything but the pixels.
+func (w *Worker) downloadLogo(ctx context.Context, source string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", strings.Join(logoContentTypes, ", "))
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch logo: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxLogoSize {
+		return nil, fmt.Errorf("logo too large (%d bytes)", resp.ContentLength)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logo: %w", err)
+	}
+	if len(data) > maxLogoSize {
+		return nil, fmt.Errorf("logo larger than %d bytes", maxLogoSize)
+	}
+
+	// raw.githubusercontent.com serves all files as text/plain, so only the sniffed type is trusted.
+	if contentType := http.DetectContentType(data); !isLogoContentType(contentType) {
+		return nil, fmt.Errorf("unsupported logo type %q", contentType)
+	}
+
+	return sanitizeLogo(data)
+}
+
+// isLogoContentType returns whether contentType is an accepted logo image type.
+func isLogoContentType(contentType string) bool {
+	for _, t := range logoContentTypes {
+		if contentType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeLogo decodes an image and re-encodes it as a PNG scaled down to fit logoSize.
+func sanitizeLogo(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxLogoPixels {
+		return nil, fmt.Errorf("logo dimensions %dx%d not allowed", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleDown(img, logoSize)); err != nil {
+		return nil, fmt.Errorf("failed to encode logo: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleDown scales an image down to fit a size×size square, keeping its aspect ratio, by
+// averaging the source pixels covered by each destination pixel. Smaller images are copied as is.
+func scaleDown(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/b.Dx())
+		} else {
+			w, h = max(1, w*size/b.Dy()), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					// Weight colors by alpha, so transparent pixels do not darken edges.
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / a >> 8),
+				G: uint8(g / a >> 8),
+				B: uint8(bl / a >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,223 @@
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// errNexusAppNotFound is returned when Nexus has not indexed a ROFL app.
+var errNexusAppNotFound = errors.New("app not indexed by nexus")
+
+// nexusROFLApp is the part of a Nexus ROFL app used to cross-check a deployment.
+type nexusROFLApp struct {
+	NumActiveInstances int `json:"num_active_instances"`
+	Policy             struct {
+		Enclaves []json.RawMessage `json:"enclaves"`
+	} `json:"policy"`
+}
+
+// checkLiveDeployments compares the on-chain policy of each verified deployment, as indexed by
+// Nexus, with the enclave identities of the verified build. Instances can only register with
+// identities admitted by the policy, so any extra identity may be running unverified code.
+func (w *Worker) checkLiveDeployments(ctx context.Context, app *models.App, manifest *rofl.Manifest) error {
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get deployments: %w", err)
+	}
+
+	var lastErr error
+	for _, deployment := range deployments {
+		if deployment.Status != models.StatusVerified {
+			continue
+		}
+		spec := manifest.Deployments[deployment.DeploymentName]
+		if spec == nil {
+			continue
+		}
+		nexusURL := w.nexusURL(spec.Network)
+		if nexusURL == "" {
+			continue
+		}
+
+		live, err := w.fetchNexusApp(ctx, nexusURL, spec.AppID)
+		switch {
+		case errors.Is(err, errNexusAppNotFound):
+			continue
+		case err != nil:
+			lastErr = fmt.Errorf("deployment %s: %w", deployment.DeploymentName, err)
+			continue
+		}
+
+		unverified, err := unverifiedEnclaves(live.Policy.Enclaves, spec.Policy.Enclaves)
+		if err != nil {
+			lastErr = fmt.Errorf("deployment %s: %w", deployment.DeploymentName, err)
+			continue
+		}
+		if len(unverified) > 0 {
+			w.logger.Warn("on-chain policy admits unverified enclave identities",
+				"app_id", app.ID,
+				"deployment", deployment.DeploymentName,
+				"active_instances", live.NumActiveInstances,
+				"enclaves", unverified)
+		}
+		if err := w.db.UpdateDeploymentLiveCheck(ctx, app.ID, deployment.DeploymentName, live.NumActiveInstances, unverified); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// nexusURL returns the Nexus API for a network, or an empty string if it is not indexed.
+func (w *Worker) nexusURL(network string) string {
+	switch network {
+	case "mainnet":
+		return w.cfg.Nexus.MainnetURL
+	case "testnet":
+		return w.cfg.Nexus.TestnetURL
+	default:
+		return ""
+	}
+}
+
+// fetchNexusApp fetches a ROFL app on Sapphire from Nexus.
+func (w *Worker) fetchNexusApp(ctx context.Context, nexusURL, appID string) (*nexusROFLApp, error) {
+	endpoint := fmt.Sprintf("%s/sapphire/rofl_apps/%s", strings.TrimSuffix(nexusURL, "/"), url.PathEscape(appID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := w.external.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errNexusAppNotFound
+	default:
+		return nil, fmt.Errorf("nexus returned HTTP %d", resp.StatusCode)
+	}
+
+	var app nexusROFLApp
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&app); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &app, nil
+}
+
+// fetchNexusBlockTime fetches the time of a consensus block from Nexus.
+func (w *Worker) fetchNexusBlockTime(ctx context.Context, nexusURL string, height uint64) (time.Time, error) {
+	endpoint := fmt.Sprintf("%s/consensus/blocks/%d", strings.TrimSuffix(nexusURL, "/"), height)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := w.external.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("nexus returned HTTP %d", resp.StatusCode)
+	}
+
+	var block struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&block); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if block.Timestamp.IsZero() {
+		return time.Time{}, fmt.Errorf("block %d has no timestamp", height)
+	}
+	return block.Timestamp, nil
+}
+
+// unverifiedEnclaves returns the base64 encoded identities of the live policy that are not among
+// the verified ones.
+func unverifiedEnclaves(live []json.RawMessage, verified []string) ([]string, error) {
+	known := make(map[rofl.EnclaveIdentity]bool, len(verified))
+	for _, encoded := range verified {
+		id, err := rofl.DecodeEnclaveIdentity(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("verified policy: %w", err)
+		}
+		known[*id] = true
+	}
+
+	var unverified []string
+	for _, raw := range live {
+		id, err := decodeNexusEnclave(raw)
+		if err != nil {
+			return nil, fmt.Errorf("on-chain policy: %w", err)
+		}
+		if !known[*id] {
+			unverified = append(unverified, base64.StdEncoding.EncodeToString(append(id.MrEnclave[:], id.MrSigner[:]...)))
+		}
+	}
+	return unverified, nil
+}
+
+// decodeNexusEnclave decodes an enclave identity of a policy returned by Nexus, either a base64
+// encoded identity or an object with base64 or hex encoded measurements.
+func decodeNexusEnclave(raw json.RawMessage) (*rofl.EnclaveIdentity, error) {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		return rofl.DecodeEnclaveIdentity(encoded)
+	}
+
+	var obj struct {
+		MrEnclave string `json:"mr_enclave"`
+		MrSigner  string `json:"mr_signer"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("invalid enclave identity: %w", err)
+	}
+	mrEnclave, err := decodeMeasurement(obj.MrEnclave)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mr_enclave: %w", err)
+	}
+	mrSigner, err := decodeMeasurement(obj.MrSigner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mr_signer: %w", err)
+	}
+	return rofl.DecodeEnclaveIdentity(base64.StdEncoding.EncodeToString(append(mrEnclave, mrSigner...)))
+}
+
+// decodeMeasurement decodes a 32-byte measurement encoded as hex or base64.
+func decodeMeasurement(s string) ([]byte, error) {
+	if b, err := hex.DecodeString(strings.TrimPrefix(s, "0x")); err == nil && len(b) == 32 {
+		return b, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("expected 32 bytes, got %d", len(b))
+	}
+	return b, nil
+}
@@ -0,0 +1,358 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// notifyTimeout bounds a single delivery of a notification.
+const notifyTimeout = 30 * time.Second
+
+// notifyPollInterval is how often recorded status changes are checked for notifications to send.
+const notifyPollInterval = 5 * time.Second
+
+// notifyBatchSize limits the number of status changes notified per poll.
+const notifyBatchSize = 100
+
+// Severities of deployment notifications.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"  // A deployment failed verification.
+	SeverityCritical = "critical" // A verified deployment failed verification.
+)
+
+// DeploymentNotification is sent to app maintainers when a verification changes the status of a
+// deployment. Webhooks receive it as a JSON POST body.
+type DeploymentNotification struct {
+	EventID        int64                     `json:"event_id"` // ID of the status change, for deduplicating deliveries.
+	AppID          int64                     `json:"app_id"`
+	GitHubURL      string                    `json:"github_url"`
+	Deployment     string                    `json:"deployment"`
+	Network        string                    `json:"network"`
+	Severity       string                    `json:"severity"`
+	Status         models.VerificationStatus `json:"status"`
+	PreviousStatus models.VerificationStatus `json:"previous_status,omitempty"`
+	Message        string                    `json:"message"`
+	CommitSHA      string                    `json:"commit_sha,omitempty"`
+	Timestamp      time.Time                 `json:"timestamp"`
+}
+
+// updateDeployment records the outcome of verifying a deployment. If its status changed, the
+// database records a status event, which dispatchNotifications then queues for delivery. Regressions of
+// verified deployments are dispatched right away, and deployments failing repeatedly may have an
+// issue filed in their repository.
+func (w *Worker) updateDeployment(ctx context.Context, app *models.App, deploymentName, commitSHA, status, verificationMsg string) error {
+	previous, err := w.db.UpsertDeployment(ctx, app.ID, deploymentName, commitSHA, status, verificationMsg)
+	if err != nil {
+		return err
+	}
+	if isRegression(previous, models.VerificationStatus(status)) {
+		w.logger.Error("verified deployment failed verification",
+			"app_id", app.ID,
+			"github_url", app.GitHubURL,
+			"deployment", deploymentName,
+			"commit_sha", commitSHA,
+			"message", verificationMsg)
+		select {
+		case w.notifyNow <- struct{}{}:
+		default:
+		}
+	}
+	if status == string(models.StatusFailed) && w.cfg.Notifications.GitHubIssues.Enabled() {
+		if err := w.queueGitHubIssue(ctx, app, deploymentName); err != nil {
+			w.logger.Warn("failed to queue GitHub issue", "app_id", app.ID, "deployment", deploymentName, "error", err)
+		}
+	}
+	return nil
+}
+
+// isRegression reports whether a status change is a verified deployment failing verification,
+// which indicates either a compromised deployment or a broken release process.
+func isRegression(previous, status models.VerificationStatus) bool {
+	return previous == models.StatusVerified && status == models.StatusFailed
+}
+
+// eventSeverity returns the severity of the notification of a status event.
+func eventSeverity(event *models.StatusEvent) string {
+	switch {
+	case isRegression(event.OldStatus, event.NewStatus):
+		return SeverityCritical
+	case event.NewStatus == models.StatusFailed:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// severityRank orders severities from the least to the most severe.
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// newNotification builds the notification of a status event.
+func (w *Worker) newNotification(ctx context.Context, event *models.StatusEvent) *DeploymentNotification {
+	network := event.Deployment
+	if app, err := w.db.GetAppByID(ctx, event.AppID); err == nil {
+		network = deploymentNetwork(app, event.Deployment)
+	}
+	return &DeploymentNotification{
+		EventID:        event.ID,
+		AppID:          event.AppID,
+		GitHubURL:      event.GitHubURL,
+		Deployment:     event.Deployment,
+		Network:        network,
+		Severity:       eventSeverity(event),
+		Status:         event.NewStatus,
+		PreviousStatus: event.OldStatus,
+		Message:        event.Details,
+		CommitSHA:      event.CommitSHA,
+		Timestamp:      event.CreatedAt.UTC(),
+	}
+}
+
+// deploymentNetwork returns the network of a deployment from the app's manifest, falling back
+// to the deployment name, which is the network for most apps.
+func deploymentNetwork(app *models.App, deploymentName string) string {
+	if manifest, err := rofl.Parse([]byte(app.RoflYAML.String)); err == nil {
+		if spec := manifest.Deployments[deploymentName]; spec != nil && spec.Network != "" {
+			return spec.Network
+		}
+	}
+	return deploymentName
+}
+
+// webhookNotifier posts notifications to the webhooks of the maintainers of the app.
+type webhookNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *webhookNotifier) Channel() string {
+	return "webhook"
+}
+
+// Destinations implements Notifier.
+func (n *webhookNotifier) Destinations(ctx context.Context, notification *DeploymentNotification) ([]string, error) {
+	return n.w.maintainerDestinations(ctx, notification, func(prefs *models.NotificationPreferences) string {
+		return prefs.WebhookURL
+	})
+}
+
+// Send implements Notifier.
+func (n *webhookNotifier) Send(ctx context.Context, webhookURL string, notification *DeploymentNotification) error {
+	return n.w.sendWebhook(ctx, webhookURL, notification)
+}
+
+// emailNotifier emails notifications to the maintainers of the app.
+type emailNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *emailNotifier) Channel() string {
+	return "email"
+}
+
+// Destinations implements Notifier.
+func (n *emailNotifier) Destinations(ctx context.Context, notification *DeploymentNotification) ([]string, error) {
+	return n.w.maintainerDestinations(ctx, notification, func(prefs *models.NotificationPreferences) string {
+		return prefs.Email
+	})
+}
+
+// Send implements Notifier.
+func (n *emailNotifier) Send(_ context.Context, to string, notification *DeploymentNotification) error {
+	return n.w.sendEmail(to, notification, "")
+}
+
+// maintainerDestinations returns a destination of every maintainer of the app whose filters match
+// a notification.
+func (w *Worker) maintainerDestinations(ctx context.Context, notification *DeploymentNotification, destination func(*models.NotificationPreferences) string) ([]string, error) {
+	preferences, err := w.db.GetAppNotificationPreferences(ctx, notification.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	var destinations []string
+	for _, prefs := range preferences {
+		if prefs.OnlyFailures && notification.Status != models.StatusFailed {
+			continue
+		}
+		if prefs.OnlyMainnet && notification.Network != "mainnet" {
+			continue
+		}
+		if dest := destination(prefs); dest != "" {
+			destinations = append(destinations, dest)
+		}
+	}
+	return destinations, nil
+}
+
+// watchNotifier emails notifications to the visitors watching the app. Destinations are
+// subscription IDs, so that emails are not sent to visitors who unsubscribed while queued.
+type watchNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *watchNotifier) Channel() string {
+	return "watch"
+}
+
+// Destinations implements Notifier.
+func (n *watchNotifier) Destinations(ctx context.Context, notification *DeploymentNotification) ([]string, error) {
+	subs, err := n.w.db.GetAppSubscriptions(ctx, notification.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	destinations := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		destinations = append(destinations, strconv.FormatInt(sub.ID, 10))
+	}
+	return destinations, nil
+}
+
+// Send implements Notifier.
+func (n *watchNotifier) Send(ctx context.Context, subscriptionID string, notification *DeploymentNotification) error {
+	id, err := strconv.ParseInt(subscriptionID, 10, 64)
+	if err != nil {
+		return permanent(fmt.Errorf("invalid subscription ID %q", subscriptionID))
+	}
+	sub, err := n.w.db.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sub == nil || !sub.ConfirmedAt.Valid {
+		return nil
+	}
+	app, err := n.w.db.GetAppByID(ctx, sub.AppID)
+	if err != nil {
+		return err
+	}
+	unsubscribeURL := n.w.cfg.Notifications.SMTP.LinkURL(app.Namespace) + "/watch/unsubscribe?token=" + url.QueryEscape(sub.UnsubscribeToken)
+	return n.w.sendEmail(sub.Email, notification, unsubscribeURL)
+}
+
+// sendWebhook posts a notification to a webhook URL.
+func (w *Worker) sendWebhook(ctx context.Context, webhookURL string, notification *DeploymentNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(resp.StatusCode, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// sendEmail emails a notification through the configured SMTP server. Emails to subscribers carry
+// an unsubscribe link.
+func (w *Worker) sendEmail(to string, notification *DeploymentNotification, unsubscribeURL string) error {
+	subject := fmt.Sprintf("%s %s: %s", strings.TrimPrefix(notification.GitHubURL, "https://github.com/"), notification.Deployment, notification.Status)
+	if notification.Severity == SeverityCritical {
+		subject = "[REGRESSION] " + subject
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Deployment %q of %s changed from %s to %s.\r\n\r\n",
+		notification.Deployment, notification.GitHubURL, orNone(string(notification.PreviousStatus)), notification.Status)
+	if notification.CommitSHA != "" {
+		fmt.Fprintf(&body, "Commit: %s\r\n", notification.CommitSHA)
+	}
+	fmt.Fprintf(&body, "%s\r\n", notification.Message)
+	if unsubscribeURL != "" {
+		fmt.Fprintf(&body, "\r\n--\r\nYou receive this email because you watch this app. Unsubscribe: %s\r\n", unsubscribeURL)
+	}
+	return w.sendMail(to, subject, body.String(), unsubscribeURL)
+}
+
+// SendSubscriptionConfirmation emails the link confirming the subscription of an address to the
+// status changes of an app.
+func (w *Worker) SendSubscriptionConfirmation(to, appName, confirmURL string) error {
+	if !w.cfg.Notifications.SMTP.Enabled() {
+		return fmt.Errorf("email notifications are not enabled")
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Someone, hopefully you, asked to be emailed when the verification status of %s changes.\r\n\r\n", appName)
+	fmt.Fprintf(&body, "Confirm the subscription: %s\r\n\r\n", confirmURL)
+	fmt.Fprintf(&body, "If you did not ask for this, ignore this email and you will not hear from us again.\r\n")
+	return w.sendMail(to, "Confirm watching "+appName, body.String(), "")
+}
+
+// sendMail sends a plain text email through the configured SMTP server. A non-empty unsubscribe URL
+// is also sent in the List-Unsubscribe header, for one-click unsubscribing in mail clients.
+func (w *Worker) sendMail(to, subject, body, unsubscribeURL string) error {
+	cfg := &w.cfg.Notifications.SMTP
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", stripNewlines(to))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", stripNewlines(subject))
+	if unsubscribeURL != "" {
+		fmt.Fprintf(&msg, "List-Unsubscribe: <%s>\r\n", stripNewlines(unsubscribeURL))
+		fmt.Fprintf(&msg, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(body)
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(cfg.Addr)
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	if err := smtp.SendMail(cfg.Addr, auth, from.Address, []string{to}, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// stripNewlines keeps user controlled values from adding email headers.
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// orNone returns "none" for an empty status.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
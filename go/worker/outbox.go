@@ -0,0 +1,220 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+const (
+	deliverBatchSize   = 20                               // Deliveries claimed per poll.
+	deliverLease       = deliverBatchSize * notifyTimeout // How long claimed deliveries are reserved.
+	deliverMaxAttempts = 8                                // Attempts before a delivery is given up on.
+	deliverBackoff     = time.Minute                      // Delay of the first retry, doubled for every further one.
+	deliverMaxBackoff  = time.Hour
+	outboxRetention    = 30 * 24 * time.Hour // How long sent and given up deliveries are kept.
+)
+
+// Notifier delivers notifications through a channel, e.g. email or webhooks. Adding a channel
+// takes implementing Notifier and registering it in newNotifiers.
+type Notifier interface {
+	// Channel names the channel in the outbox, so it must not change.
+	Channel() string
+	// Destinations returns where a notification is delivered, e.g. email addresses or chats, or
+	// none if the channel ignores it.
+	Destinations(ctx context.Context, notification *DeploymentNotification) ([]string, error)
+	// Send delivers a notification to a destination. Failures are retried unless wrapped by
+	// permanent.
+	Send(ctx context.Context, destination string, notification *DeploymentNotification) error
+}
+
+// newNotifiers returns the configured notification channels.
+func (w *Worker) newNotifiers() []Notifier {
+	notifiers := []Notifier{&webhookNotifier{w}}
+	if w.cfg.Notifications.SMTP.Enabled() {
+		notifiers = append(notifiers, &emailNotifier{w}, &watchNotifier{w})
+	}
+	if w.cfg.Notifications.PagerDuty.Enabled() {
+		notifiers = append(notifiers, &pagerDutyNotifier{w})
+	}
+	if w.cfg.Notifications.Telegram.Enabled() {
+		notifiers = append(notifiers, &telegramNotifier{w})
+	}
+	if w.cfg.Notifications.GitHubIssues.Enabled() {
+		notifiers = append(notifiers, &githubIssueNotifier{w})
+	}
+	return notifiers
+}
+
+// permanentError is a delivery failure that retrying cannot fix, e.g. a rejected request.
+type permanentError struct {
+	err error
+}
+
+// Error implements error.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the failure.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// permanent marks a delivery failure as not worth retrying.
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// statusError returns the error of an unsuccessful HTTP response, marked permanent for client
+// errors other than timeouts and rate limiting.
+func statusError(status int, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return permanent(err)
+	}
+	return err
+}
+
+// dispatchNotifications periodically queues the notifications of recorded status changes in the
+// outbox, including those made by other workers sharing the database and by the staleness sweep,
+// and delivers the queued notifications that are due.
+func (w *Worker) dispatchNotifications(ctx context.Context) {
+	var pruned time.Time
+	for {
+		queued := w.enqueueNotifications(ctx)
+		delivered := w.deliverNotifications(ctx)
+		if time.Since(pruned) > time.Hour {
+			if _, err := w.db.PruneNotificationOutbox(ctx, time.Now().Add(-outboxRetention)); err != nil && ctx.Err() == nil {
+				w.logger.Error("failed to prune notification outbox", "error", err)
+			}
+			pruned = time.Now()
+		}
+
+		if queued == notifyBatchSize || delivered == deliverBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.notifyNow:
+		case <-time.After(notifyPollInterval):
+		}
+	}
+}
+
+// enqueueNotifications queues the notifications of a batch of status events for delivery through
+// every channel that takes them, and returns the number of events.
+func (w *Worker) enqueueNotifications(ctx context.Context) int {
+	events, err := w.db.ClaimUnnotifiedStatusEvents(ctx, notifyBatchSize)
+	if err != nil && ctx.Err() == nil {
+		w.logger.Error("failed to get status events to notify", "error", err)
+	}
+	for _, event := range events {
+		notification := w.newNotification(ctx, event)
+		payload, err := json.Marshal(notification)
+		if err != nil {
+			w.logger.Error("failed to encode notification", "event_id", event.ID, "error", err)
+			continue
+		}
+
+		var deliveries []*models.NotificationDelivery
+		for _, notifier := range w.notifiers {
+			destinations, err := notifier.Destinations(ctx, notification)
+			if err != nil {
+				w.logger.Error("failed to get notification destinations", "event_id", event.ID, "channel", notifier.Channel(), "error", err)
+				continue
+			}
+			for _, destination := range destinations {
+				deliveries = append(deliveries, &models.NotificationDelivery{
+					EventID:     event.ID,
+					Channel:     notifier.Channel(),
+					Destination: destination,
+					Payload:     payload,
+					Priority:    severityRank(notification.Severity),
+				})
+			}
+		}
+		if err := w.db.EnqueueNotifications(ctx, deliveries); err != nil {
+			w.logger.Error("failed to queue notifications", "event_id", event.ID, "error", err)
+		}
+	}
+	return len(events)
+}
+
+// deliverNotifications sends a batch of the queued notifications that are due, regressions first,
+// and returns the number of deliveries attempted.
+func (w *Worker) deliverNotifications(ctx context.Context) int {
+	deliveries, err := w.db.ClaimDueNotifications(ctx, deliverBatchSize, deliverLease)
+	if err != nil && ctx.Err() == nil {
+		w.logger.Error("failed to get notifications to deliver", "error", err)
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Claims of the remaining deliveries expire, so that they are sent later.
+			break
+		}
+		w.deliver(ctx, delivery)
+	}
+	return len(deliveries)
+}
+
+// deliver sends a queued notification and records the outcome. Failed deliveries are retried with
+// exponential backoff until they succeed, fail permanently, or run out of attempts.
+func (w *Worker) deliver(ctx context.Context, delivery *models.NotificationDelivery) {
+	err := w.send(ctx, delivery)
+	if err == nil {
+		if err := w.db.MarkNotificationDelivered(ctx, delivery.ID); err != nil {
+			w.logger.Error("failed to record notification delivery", "outbox_id", delivery.ID, "error", err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	attempt := delivery.Attempts + 1
+	var next time.Time
+	var perm *permanentError
+	if !errors.As(err, &perm) && !errors.Is(err, httpclient.ErrBlockedDestination) && attempt < deliverMaxAttempts {
+		next = time.Now().Add(min(deliverBackoff<<(attempt-1), deliverMaxBackoff))
+	}
+	if err := w.db.RetryNotification(ctx, delivery.ID, next, err.Error()); err != nil {
+		w.logger.Error("failed to record notification attempt", "outbox_id", delivery.ID, "error", err)
+	}
+
+	logArgs := []any{"outbox_id", delivery.ID, "event_id", delivery.EventID, "channel", delivery.Channel, "attempt", attempt, "error", err}
+	if next.IsZero() {
+		w.logger.Error("failed to deliver notification, giving up", logArgs...)
+		return
+	}
+	w.logger.Warn("failed to deliver notification, retrying", append(logArgs, "retry_at", next)...)
+}
+
+// send delivers a queued notification through its channel.
+func (w *Worker) send(ctx context.Context, delivery *models.NotificationDelivery) error {
+	var notifier Notifier
+	for _, n := range w.notifiers {
+		if n.Channel() == delivery.Channel {
+			notifier = n
+			break
+		}
+	}
+	if notifier == nil {
+		return permanent(fmt.Errorf("channel %q is not configured", delivery.Channel))
+	}
+
+	var notification DeploymentNotification
+	if err := json.Unmarshal(delivery.Payload, &notification); err != nil {
+		return permanent(fmt.Errorf("failed to decode notification: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	return notifier.Send(ctx, delivery.Destination, &notification)
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// pagerDutyEvent is an event of the PagerDuty Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // "trigger" or "resolve".
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the incident of a triggered PagerDuty event.
+type pagerDutyPayload struct {
+	Summary       string                  `json:"summary"`
+	Source        string                  `json:"source"`
+	Severity      string                  `json:"severity"`
+	Timestamp     time.Time               `json:"timestamp"`
+	CustomDetails *DeploymentNotification `json:"custom_details"`
+}
+
+// pagerDutyNotifier opens a PagerDuty incident when a verified deployment fails verification, and
+// resolves it once the deployment is verified again. Other status changes are ignored. The
+// destination is the deduplication key of the incident of the deployment.
+type pagerDutyNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *pagerDutyNotifier) Channel() string {
+	return "pagerduty"
+}
+
+// Destinations implements Notifier.
+func (n *pagerDutyNotifier) Destinations(_ context.Context, notification *DeploymentNotification) ([]string, error) {
+	if pagerDutyAction(notification) == "" {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("rofl-registry/%d/%s", notification.AppID, notification.Deployment)}, nil
+}
+
+// Send implements Notifier.
+func (n *pagerDutyNotifier) Send(ctx context.Context, dedupKey string, notification *DeploymentNotification) error {
+	event := &pagerDutyEvent{
+		RoutingKey:  n.w.cfg.Notifications.PagerDuty.RoutingKey,
+		EventAction: pagerDutyAction(notification),
+		DedupKey:    dedupKey,
+	}
+	if event.EventAction == "trigger" {
+		event.Payload = &pagerDutyPayload{
+			Summary:       fmt.Sprintf("Verified ROFL deployment %s %s failed verification", strings.TrimPrefix(notification.GitHubURL, "https://github.com/"), notification.Deployment),
+			Source:        notification.GitHubURL,
+			Severity:      SeverityCritical,
+			Timestamp:     notification.Timestamp,
+			CustomDetails: notification,
+		}
+	}
+	return n.w.sendPagerDutyEvent(ctx, event)
+}
+
+// pagerDutyAction returns the PagerDuty event action of a notification, empty if there is none.
+func pagerDutyAction(notification *DeploymentNotification) string {
+	switch {
+	case notification.Severity == SeverityCritical:
+		return "trigger"
+	case notification.PreviousStatus == models.StatusFailed && notification.Status == models.StatusVerified:
+		return "resolve"
+	default:
+		return ""
+	}
+}
+
+// sendPagerDutyEvent posts an event to the PagerDuty Events API.
+func (w *Worker) sendPagerDutyEvent(ctx context.Context, event *pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.Notifications.PagerDuty.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.external.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(resp.StatusCode, fmt.Errorf("PagerDuty returned HTTP %d", resp.StatusCode))
+	}
+	return nil
+}
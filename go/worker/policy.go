@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/policy"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// checkPolicy evaluates the verification policy against the verified deployments of an app and
+// records the violations.
+func (w *Worker) checkPolicy(ctx context.Context, app *models.App, manifest *rofl.Manifest) error {
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, app.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get deployments: %w", err)
+	}
+
+	var lastErr error
+	for _, deployment := range deployments {
+		if deployment.Status != models.StatusVerified {
+			continue
+		}
+		spec := manifest.Deployments[deployment.DeploymentName]
+		if spec == nil {
+			continue
+		}
+
+		state := &policy.Deployment{
+			Name:         deployment.DeploymentName,
+			Network:      spec.Network,
+			BuilderImage: deployment.BuilderImage.String,
+			Enclaves:     len(spec.Policy.Enclaves),
+		}
+		if spec.TrustRoot != nil {
+			state.TrustRootHeight = spec.TrustRoot.Height
+			if w.checksTrustRoot(spec.Network) {
+				if state.TrustRootTime, err = w.trustRootTime(ctx, spec.Network, spec.TrustRoot.Height); err != nil {
+					w.logger.Warn("failed to look up trust root", "app_id", app.ID, "deployment", deployment.DeploymentName, "height", spec.TrustRoot.Height, "error", err)
+				}
+			}
+		}
+
+		violations := policy.Evaluate(w.cfg.Policy.Rules, state, time.Now())
+		for _, violation := range violations {
+			w.logger.Warn("deployment violates verification policy",
+				"app_id", app.ID,
+				"deployment", deployment.DeploymentName,
+				"rule", violation.Rule,
+				"message", violation.Message)
+		}
+		if err := w.db.UpdateDeploymentPolicy(ctx, app.ID, deployment.DeploymentName, violations); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// checksTrustRoot reports whether a policy rule on the age of trust roots applies to a network.
+func (w *Worker) checksTrustRoot(network string) bool {
+	for i := range w.cfg.Policy.Rules {
+		rule := &w.cfg.Policy.Rules[i]
+		if rule.Kind == config.PolicyMaxTrustRootAge && policy.Applies(rule, network) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustRootTime returns the time of the consensus block at a height, as indexed by Nexus.
+func (w *Worker) trustRootTime(ctx context.Context, network string, height uint64) (time.Time, error) {
+	nexusURL := w.nexusURL(network)
+	if nexusURL == "" {
+		return time.Time{}, fmt.Errorf("no nexus API for network %q", network)
+	}
+	return w.fetchNexusBlockTime(ctx, nexusURL, height)
+}
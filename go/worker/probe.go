@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// probeNonceSize is the size of the nonce an instance must include in the report data of its quote.
+const probeNonceSize = 32
+
+// errNoVerifiedDeployment is returned when an app has no verified identity to attest against.
+var errNoVerifiedDeployment = errors.New("no verified deployment")
+
+// probeAttestation requests a fresh TDX quote from the attestation endpoint of an app and records
+// whether it attests the enclave identity of a verified deployment.
+func (w *Worker) probeAttestation(ctx context.Context, app *models.App, manifest *rofl.Manifest) error {
+	deployment, probeErr := w.attestLiveInstance(ctx, app, manifest)
+	msg := ""
+	if probeErr != nil {
+		msg = probeErr.Error()
+	}
+	if err := w.db.RecordAttestationProbe(ctx, app.ID, deployment, msg); err != nil {
+		return err
+	}
+	if probeErr == nil {
+		w.logger.Info("live instance attested", "app_id", app.ID, "deployment", deployment)
+	}
+	return probeErr
+}
+
+// attestLiveInstance fetches and checks a quote, returning the verified deployment it attests.
+func (w *Worker) attestLiveInstance(ctx context.Context, app *models.App, manifest *rofl.Manifest) (string, error) {
+	if !strings.EqualFold(manifest.TEE, rofl.TEETDX) {
+		return "", fmt.Errorf("attestation probes require a TDX app")
+	}
+	deployments, err := w.db.GetDeploymentsByAppID(ctx, app.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployments: %w", err)
+	}
+	verified := make(map[rofl.EnclaveIdentity]string)
+	for _, deployment := range deployments {
+		spec := manifest.Deployments[deployment.DeploymentName]
+		if deployment.Status != models.StatusVerified || spec == nil {
+			continue
+		}
+		for _, encoded := range spec.Policy.Enclaves {
+			if id, err := rofl.DecodeEnclaveIdentity(encoded); err == nil {
+				verified[*id] = deployment.DeploymentName
+			}
+		}
+	}
+	if len(verified) == 0 {
+		return "", errNoVerifiedDeployment
+	}
+
+	nonce := make([]byte, probeNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	raw, err := w.fetchQuote(ctx, app.AttestationURL.String, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	quote, err := rofl.ParseTDXQuote(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse quote: %w", err)
+	}
+	if err := quote.VerifySignature(); err != nil {
+		return "", err
+	}
+	if !bytes.Equal(quote.ReportData[:probeNonceSize], nonce) {
+		return "", fmt.Errorf("quote does not include the nonce")
+	}
+
+	id := quote.EnclaveIdentity()
+	deployment, ok := verified[*id]
+	if !ok {
+		return "", fmt.Errorf("quote attests enclave identity %s, which is not verified",
+			base64.StdEncoding.EncodeToString(append(id.MrEnclave[:], id.MrSigner[:]...)))
+	}
+	return deployment, nil
+}
+
+// fetchQuote requests a quote for the given nonce from an attestation endpoint. The endpoint is
+// called with the hex encoded nonce as the nonce query parameter and returns a JSON object with
+// the hex or base64 encoded quote in its quote field. Endpoints are chosen by app authors, so they
+// are only called over https at public addresses.
+func (w *Worker) fetchQuote(ctx context.Context, endpoint string, nonce []byte) ([]byte, error) {
+	if err := httpclient.CheckURL(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid attestation URL: %w", err)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation URL %q", endpoint)
+	}
+	query := u.Query()
+	query.Set("nonce", hex.EncodeToString(nonce))
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("attestation endpoint returned HTTP %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Quote string `json:"quote"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	encoded := strings.TrimPrefix(strings.TrimSpace(response.Quote), "0x")
+	if encoded == "" {
+		return nil, fmt.Errorf("response has no quote")
+	}
+	if quote, err := hex.DecodeString(encoded); err == nil {
+		return quote, nil
+	}
+	quote, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote encoding")
+	}
+	return quote, nil
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ptrus/rofl-attestations/httpclient"
+)
+
+// Test that attestation endpoints are only called over https at public addresses.
+func TestFetchQuoteBlockedURLs(t *testing.T) {
+	w := &Worker{}
+	nonce := make([]byte, probeNonceSize)
+	for _, endpoint := range []string{
+		"http://attest.example.com/quote",
+		"https://localhost/quote",
+		"https://127.0.0.1/quote",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/quote",
+		"https://10.0.0.1:8443/quote",
+	} {
+		if _, err := w.fetchQuote(context.Background(), endpoint, nonce); !errors.Is(err, httpclient.ErrBlockedDestination) {
+			t.Errorf("Endpoint %s: expected ErrBlockedDestination, got %v", endpoint, err)
+		}
+	}
+}
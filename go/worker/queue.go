@@ -0,0 +1,366 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Queue holds the apps left to verify in the current cycle and the backend tasks in flight.
+// The in-memory queue is private to the process; the Redis queue is shared by all workers
+// using it and survives restarts.
+type Queue interface {
+	// Fill starts a new cycle with the given apps, unless a cycle is still in progress.
+	// It reports whether a new cycle was started.
+	Fill(ctx context.Context, appIDs []int64) (bool, error)
+	// Next takes the next app of the current cycle. It returns false once the cycle is done.
+	// Apps taken are held by the worker until acknowledged.
+	Next(ctx context.Context) (int64, bool, error)
+	// Ack acknowledges that an app taken with Next was processed.
+	Ack(ctx context.Context, appID int64) error
+	// Len returns the number of apps left in the current cycle.
+	Len(ctx context.Context) (int, error)
+
+	// SetTask records a submitted backend task of an app deployment.
+	SetTask(ctx context.Context, appID int64, deployment, taskID string) error
+	// Task returns the backend task in flight for an app deployment, if any.
+	Task(ctx context.Context, appID int64, deployment string) (string, error)
+	// ClearTask forgets the backend task of an app deployment.
+	ClearTask(ctx context.Context, appID int64, deployment string) error
+
+	// Close releases the resources held by the queue.
+	Close() error
+}
+
+// newQueue creates the queue configured for the worker, identified by instanceID.
+func newQueue(cfg *config.QueueConfig, instanceID string) (Queue, error) {
+	switch cfg.Backend {
+	case config.QueueBackendMemory:
+		return newMemoryQueue(), nil
+	case config.QueueBackendRedis:
+		client, err := newRedisClient(cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		return newRedisQueue(client, cfg.KeyPrefix, instanceID), nil
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", cfg.Backend)
+	}
+}
+
+// taskKey identifies the backend task of an app deployment.
+func taskKey(appID int64, deployment string) string {
+	return strconv.FormatInt(appID, 10) + "/" + deployment
+}
+
+// memoryQueue is an in-process Queue.
+type memoryQueue struct {
+	mu    sync.Mutex
+	apps  []int64
+	tasks map[string]string
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{tasks: make(map[string]string)}
+}
+
+func (q *memoryQueue) Fill(_ context.Context, appIDs []int64) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.apps) > 0 {
+		return false, nil
+	}
+	q.apps = append(q.apps, appIDs...)
+	return true, nil
+}
+
+func (q *memoryQueue) Next(_ context.Context) (int64, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.apps) == 0 {
+		return 0, false, nil
+	}
+	id := q.apps[0]
+	q.apps = q.apps[1:]
+	return id, true, nil
+}
+
+func (q *memoryQueue) Ack(_ context.Context, _ int64) error {
+	return nil
+}
+
+func (q *memoryQueue) Len(_ context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.apps), nil
+}
+
+func (q *memoryQueue) SetTask(_ context.Context, appID int64, deployment, taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tasks[taskKey(appID, deployment)] = taskID
+	return nil
+}
+
+func (q *memoryQueue) Task(_ context.Context, appID int64, deployment string) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.tasks[taskKey(appID, deployment)], nil
+}
+
+func (q *memoryQueue) ClearTask(_ context.Context, appID int64, deployment string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.tasks, taskKey(appID, deployment))
+	return nil
+}
+
+func (q *memoryQueue) Close() error {
+	return nil
+}
+
+// redisFillLockTTL bounds how long a crashed worker can block filling a new cycle, in milliseconds.
+const redisFillLockTTL = "10000"
+
+// redisWorkerTTL is how long a worker that stopped refreshing its liveness key is still considered
+// alive, before the apps it holds are queued again.
+const redisWorkerTTL = time.Minute
+
+// redisQueue is a Queue stored in Redis: a list of app IDs and a hash of tasks in flight.
+//
+// Apps are moved from the list to a processing list of the worker taking them, and removed once
+// acknowledged. Each worker keeps a liveness key with a TTL; the apps held by a worker whose key
+// expired, because it crashed or shut down while verifying, are moved back to the front of the list.
+type redisQueue struct {
+	client     *redisClient
+	prefix     string
+	instanceID string
+
+	reclaimedOwn bool // Whether apps held under this instance ID by a previous run were queued again.
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+func newRedisQueue(client *redisClient, prefix, instanceID string) *redisQueue {
+	q := &redisQueue{
+		client:     client,
+		prefix:     prefix,
+		instanceID: instanceID,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go q.keepAlive()
+	return q
+}
+
+// processingKey returns the key of the list of apps held by a worker.
+func (q *redisQueue) processingKey(instanceID string) string {
+	return q.prefix + "processing:" + instanceID
+}
+
+// aliveKey returns the liveness key of a worker.
+func (q *redisQueue) aliveKey(instanceID string) string {
+	return q.prefix + "alive:" + instanceID
+}
+
+// keepAlive refreshes the liveness key of the worker until the queue is closed.
+func (q *redisQueue) keepAlive() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(redisWorkerTTL / 4)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), redisWorkerTTL/4)
+		_ = q.refresh(ctx)
+		cancel()
+
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh sets the liveness key of the worker and registers it with the other workers.
+func (q *redisQueue) refresh(ctx context.Context) error {
+	ttl := strconv.FormatInt(redisWorkerTTL.Milliseconds(), 10)
+	if _, err := q.client.Do(ctx, "SET", q.aliveKey(q.instanceID), "1", "PX", ttl); err != nil {
+		return fmt.Errorf("failed to refresh worker liveness: %w", err)
+	}
+	if _, err := q.client.Do(ctx, "SADD", q.prefix+"workers", q.instanceID); err != nil {
+		return fmt.Errorf("failed to register worker: %w", err)
+	}
+	return nil
+}
+
+// reclaim moves the apps held by workers that are no longer alive back to the front of the queue,
+// as well as those held under this instance ID before a restart.
+func (q *redisQueue) reclaim(ctx context.Context) error {
+	reply, err := q.client.Do(ctx, "SMEMBERS", q.prefix+"workers")
+	if err != nil {
+		return fmt.Errorf("failed to list workers: %w", err)
+	}
+	members, _ := reply.([]any)
+	for _, member := range members {
+		instanceID, _ := member.(string)
+		if instanceID == q.instanceID {
+			if q.reclaimedOwn {
+				continue
+			}
+		} else {
+			alive, err := q.client.Do(ctx, "EXISTS", q.aliveKey(instanceID))
+			if err != nil {
+				return fmt.Errorf("failed to check worker liveness: %w", err)
+			}
+			if n, _ := alive.(int64); n > 0 {
+				continue
+			}
+		}
+
+		for {
+			_, err := q.client.Do(ctx, "LMOVE", q.processingKey(instanceID), q.prefix+"apps", "RIGHT", "LEFT")
+			if errors.Is(err, errRedisNil) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to queue apps of worker %s again: %w", instanceID, err)
+			}
+		}
+		if instanceID != q.instanceID {
+			if _, err := q.client.Do(ctx, "SREM", q.prefix+"workers", instanceID); err != nil {
+				return fmt.Errorf("failed to unregister worker %s: %w", instanceID, err)
+			}
+		}
+	}
+	q.reclaimedOwn = true
+	return nil
+}
+
+func (q *redisQueue) Fill(ctx context.Context, appIDs []int64) (bool, error) {
+	// Only one worker may fill the queue, the others join the cycle it started.
+	locked, err := q.client.Do(ctx, "SET", q.prefix+"fill-lock", "1", "NX", "PX", redisFillLockTTL)
+	if errors.Is(err, errRedisNil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock queue: %w", err)
+	}
+	if locked != "OK" {
+		return false, nil
+	}
+	defer func() {
+		_, _ = q.client.Do(context.WithoutCancel(ctx), "DEL", q.prefix+"fill-lock")
+	}()
+
+	// Apps held by crashed workers belong to the cycle in progress.
+	if err := q.reclaim(ctx); err != nil {
+		return false, err
+	}
+	n, err := q.Len(ctx)
+	if err != nil {
+		return false, err
+	}
+	if n > 0 || len(appIDs) == 0 {
+		return false, nil
+	}
+
+	args := []string{"RPUSH", q.prefix + "apps"}
+	for _, id := range appIDs {
+		args = append(args, strconv.FormatInt(id, 10))
+	}
+	if _, err := q.client.Do(ctx, args...); err != nil {
+		return false, fmt.Errorf("failed to fill queue: %w", err)
+	}
+	return true, nil
+}
+
+func (q *redisQueue) Next(ctx context.Context) (int64, bool, error) {
+	if err := q.refresh(ctx); err != nil {
+		return 0, false, err
+	}
+	if err := q.reclaim(ctx); err != nil {
+		return 0, false, err
+	}
+
+	reply, err := q.client.Do(ctx, "LMOVE", q.prefix+"apps", q.processingKey(q.instanceID), "LEFT", "RIGHT")
+	if errors.Is(err, errRedisNil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to take app from queue: %w", err)
+	}
+
+	s, _ := reply.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		_, _ = q.client.Do(ctx, "LREM", q.processingKey(q.instanceID), "1", s)
+		return 0, false, fmt.Errorf("invalid app ID %q in queue", s)
+	}
+	return id, true, nil
+}
+
+func (q *redisQueue) Ack(ctx context.Context, appID int64) error {
+	if _, err := q.client.Do(ctx, "LREM", q.processingKey(q.instanceID), "1", strconv.FormatInt(appID, 10)); err != nil {
+		return fmt.Errorf("failed to acknowledge app: %w", err)
+	}
+	return nil
+}
+
+func (q *redisQueue) Len(ctx context.Context) (int, error) {
+	reply, err := q.client.Do(ctx, "LLEN", q.prefix+"apps")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get queue length: %w", err)
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
+
+func (q *redisQueue) SetTask(ctx context.Context, appID int64, deployment, taskID string) error {
+	if _, err := q.client.Do(ctx, "HSET", q.prefix+"tasks", taskKey(appID, deployment), taskID); err != nil {
+		return fmt.Errorf("failed to record task: %w", err)
+	}
+	return nil
+}
+
+func (q *redisQueue) Task(ctx context.Context, appID int64, deployment string) (string, error) {
+	reply, err := q.client.Do(ctx, "HGET", q.prefix+"tasks", taskKey(appID, deployment))
+	if errors.Is(err, errRedisNil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get task: %w", err)
+	}
+	taskID, _ := reply.(string)
+	return taskID, nil
+}
+
+func (q *redisQueue) ClearTask(ctx context.Context, appID int64, deployment string) error {
+	if _, err := q.client.Do(ctx, "HDEL", q.prefix+"tasks", taskKey(appID, deployment)); err != nil {
+		return fmt.Errorf("failed to clear task: %w", err)
+	}
+	return nil
+}
+
+// Close stops refreshing the liveness key of the worker and removes it, so that the apps it still
+// holds are queued again right away.
+func (q *redisQueue) Close() error {
+	close(q.stop)
+	<-q.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = q.client.Do(ctx, "DEL", q.aliveKey(q.instanceID))
+	return q.client.Close()
+}
@@ -0,0 +1,218 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errRedisNil is returned for nil replies, e.g. popping from an empty list.
+var errRedisNil = errors.New("redis: nil")
+
+// redisClient is a minimal Redis client speaking RESP2 over a single connection.
+// It supports only what the task queue needs and reconnects after connection errors.
+type redisClient struct {
+	addr     string
+	useTLS   bool
+	username string
+	password string
+	database int
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// newRedisClient creates a client for a redis:// or rediss:// URL, e.g. redis://:password@localhost:6379/0.
+func newRedisClient(rawURL string) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid redis URL scheme %q", u.Scheme)
+	}
+
+	c := &redisClient{
+		addr:   u.Host,
+		useTLS: u.Scheme == "rediss",
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.database, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Do sends a command and returns its reply: a string, an int64, a []any, or errRedisNil.
+func (c *redisClient) Do(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.roundTrip(ctx, args)
+	if err != nil {
+		var redisErr redisError
+		if !errors.As(err, &redisErr) && !errors.Is(err, errRedisNil) {
+			// The connection is in an unknown state, start over on the next command.
+			_ = c.conn.Close()
+			c.conn = nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Close closes the connection.
+func (c *redisClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// connect dials the server, authenticates, and selects the database.
+func (c *redisClient) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	c.conn = conn
+	c.rd = bufio.NewReader(conn)
+
+	var setup [][]string
+	switch {
+	case c.username != "" && c.password != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.database != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.database)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(ctx, args); err != nil {
+			_ = c.conn.Close()
+			c.conn = nil
+			return fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// roundTrip writes a command and reads its reply.
+func (c *redisClient) roundTrip(ctx context.Context, args []string) (any, error) {
+	deadline := time.Now().Add(10 * time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, fmt.Errorf("failed to write redis command: %w", err)
+	}
+
+	return readRedisReply(c.rd)
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readRedisReply reads a single RESP2 reply.
+func readRedisReply(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk length: %w", err)
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length: %w", err)
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		items := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readRedisReply(rd)
+			if err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,384 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis is a Redis server holding strings, lists, sets, and hashes in memory, supporting the
+// commands used by the task queue. Expiry is not implemented.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	strings map[string]string
+	lists   map[string][]string
+	sets    map[string]map[string]bool
+	hashes  map[string]map[string]string
+	conns   []net.Conn
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	r := &fakeRedis{
+		listener: listener,
+		password: password,
+		strings:  make(map[string]string),
+		lists:    make(map[string][]string),
+		sets:     make(map[string]map[string]bool),
+		hashes:   make(map[string]map[string]string),
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		r.dropConnections()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.conns = append(r.conns, conn)
+			r.mu.Unlock()
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+// url returns the URL of the server.
+func (r *fakeRedis) url() string {
+	if r.password != "" {
+		return "redis://:" + r.password + "@" + r.listener.Addr().String() + "/1"
+	}
+	return "redis://" + r.listener.Addr().String()
+}
+
+// dropConnections closes the connections of all clients.
+func (r *fakeRedis) dropConnections() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.conns = nil
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	rd := bufio.NewReader(conn)
+	authenticated := r.password == ""
+	for {
+		reply, err := readRedisReply(rd)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, 0, len(items))
+		for _, item := range items {
+			s, _ := item.(string)
+			args = append(args, s)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		var out string
+		switch {
+		case strings.EqualFold(args[0], "AUTH"):
+			authenticated = args[len(args)-1] == r.password
+			out = "+OK\r\n"
+			if !authenticated {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			out = "-NOAUTH Authentication required.\r\n"
+		default:
+			out = r.exec(args)
+		}
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+// exec runs a command and returns its encoded reply.
+func (r *fakeRedis) exec(args []string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bulk := func(s string) string {
+		return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+	}
+	integer := func(n int) string {
+		return ":" + strconv.Itoa(n) + "\r\n"
+	}
+	const null = "$-1\r\n"
+
+	cmd := strings.ToUpper(args[0])
+	if cmd == "SELECT" {
+		return "+OK\r\n"
+	}
+	if len(args) < 2 {
+		return "-ERR wrong number of arguments\r\n"
+	}
+	key := args[1]
+	switch cmd {
+	case "SET":
+		if slices.Contains(args[3:], "NX") {
+			if _, ok := r.strings[key]; ok {
+				return null
+			}
+		}
+		r.strings[key] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := r.strings[key]; ok {
+				n++
+			}
+			delete(r.strings, key)
+			delete(r.lists, key)
+		}
+		return integer(n)
+	case "EXISTS":
+		if _, ok := r.strings[key]; ok {
+			return integer(1)
+		}
+		return integer(0)
+	case "RPUSH":
+		r.lists[key] = append(r.lists[key], args[2:]...)
+		return integer(len(r.lists[key]))
+	case "LLEN":
+		return integer(len(r.lists[key]))
+	case "LMOVE":
+		src := r.lists[key]
+		if len(src) == 0 {
+			return null
+		}
+		var v string
+		if args[3] == "LEFT" {
+			v, r.lists[key] = src[0], src[1:]
+		} else {
+			v, r.lists[key] = src[len(src)-1], src[:len(src)-1]
+		}
+		if args[4] == "LEFT" {
+			r.lists[args[2]] = append([]string{v}, r.lists[args[2]]...)
+		} else {
+			r.lists[args[2]] = append(r.lists[args[2]], v)
+		}
+		return bulk(v)
+	case "LREM":
+		if i := slices.Index(r.lists[key], args[3]); i >= 0 {
+			r.lists[key] = slices.Delete(r.lists[key], i, i+1)
+			return integer(1)
+		}
+		return integer(0)
+	case "SADD":
+		if r.sets[key] == nil {
+			r.sets[key] = make(map[string]bool)
+		}
+		for _, member := range args[2:] {
+			r.sets[key][member] = true
+		}
+		return integer(len(args) - 2)
+	case "SREM":
+		for _, member := range args[2:] {
+			delete(r.sets[key], member)
+		}
+		return integer(len(args) - 2)
+	case "SMEMBERS":
+		out := "*" + strconv.Itoa(len(r.sets[key])) + "\r\n"
+		for member := range r.sets[key] {
+			out += bulk(member)
+		}
+		return out
+	case "HSET":
+		if r.hashes[key] == nil {
+			r.hashes[key] = make(map[string]string)
+		}
+		r.hashes[key][args[2]] = args[3]
+		return integer(1)
+	case "HGET":
+		v, ok := r.hashes[key][args[2]]
+		if !ok {
+			return null
+		}
+		return bulk(v)
+	case "HDEL":
+		delete(r.hashes[key], args[2])
+		return integer(1)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// Test that RESP2 replies are parsed into strings, integers, arrays, nil, and errors.
+func TestReadRedisReply(t *testing.T) {
+	for _, tc := range []struct {
+		reply   string
+		want    any
+		wantErr error // Matched with errors.Is, or any error if errUnexpected.
+	}{
+		{reply: "+OK\r\n", want: "OK"},
+		{reply: ":42\r\n", want: int64(42)},
+		{reply: "$5\r\nhello\r\n", want: "hello"},
+		{reply: "$0\r\n\r\n", want: ""},
+		{reply: "$7\r\nfoo\r\nba\r\n", want: "foo\r\nba"},
+		{reply: "*3\r\n$1\r\na\r\n:1\r\n$-1\r\n", want: []any{"a", int64(1), nil}},
+		{reply: "*0\r\n", want: []any{}},
+		{reply: "$-1\r\n", wantErr: errRedisNil},
+		{reply: "*-1\r\n", wantErr: errRedisNil},
+		{reply: "-ERR wrong type\r\n", wantErr: redisError("ERR wrong type")},
+		{reply: "*2\r\n-ERR nested\r\n:1\r\n", wantErr: redisError("ERR nested")},
+		{reply: "$5\r\nhel", wantErr: errUnexpected},
+		{reply: "$x\r\n", wantErr: errUnexpected},
+		{reply: ":x\r\n", wantErr: errUnexpected},
+		{reply: "?what\r\n", wantErr: errUnexpected},
+		{reply: "\r\n", wantErr: errUnexpected},
+		{reply: "", wantErr: errUnexpected},
+	} {
+		got, err := readRedisReply(bufio.NewReader(strings.NewReader(tc.reply)))
+		switch {
+		case tc.wantErr == errUnexpected && err == nil,
+			tc.wantErr != nil && tc.wantErr != errUnexpected && !errors.Is(err, tc.wantErr),
+			tc.wantErr == nil && (err != nil || !reflect.DeepEqual(got, tc.want)):
+			t.Errorf("Reply %q: got %#v, %v; expected %#v, %v", tc.reply, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+// errUnexpected stands for any error in TestReadRedisReply.
+var errUnexpected = errors.New("any error")
+
+// Test that the client authenticates, keeps its connection after error replies, and reconnects
+// after losing it.
+func TestRedisClient(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "secret")
+
+	client, err := newRedisClient(server.url())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if reply, err := client.Do(ctx, "RPUSH", "list", "a", "b"); err != nil || reply != int64(2) {
+		t.Fatalf("RPUSH: got %v, %v", reply, err)
+	}
+	var redisErr redisError
+	if _, err := client.Do(ctx, "BOGUS", "list"); !errors.As(err, &redisErr) {
+		t.Errorf("Expected an error reply, got %v", err)
+	}
+	conn := client.conn
+	if _, err := client.Do(ctx, "HGET", "hash", "missing"); !errors.Is(err, errRedisNil) {
+		t.Errorf("Expected a nil reply, got %v", err)
+	}
+	if client.conn != conn {
+		t.Errorf("Connection was replaced after error and nil replies")
+	}
+
+	// The first command after the connection is lost fails, the next one reconnects.
+	server.dropConnections()
+	if _, err := client.Do(ctx, "LLEN", "list"); err == nil {
+		t.Errorf("Expected an error on a closed connection")
+	}
+	if reply, err := client.Do(ctx, "LLEN", "list"); err != nil || reply != int64(2) {
+		t.Errorf("LLEN after reconnecting: got %v, %v", reply, err)
+	}
+
+	wrong, err := newRedisClient(strings.Replace(server.url(), "secret", "wrong", 1))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := wrong.Do(ctx, "LLEN", "list"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+}
+
+// Test that apps held by a crashed worker are queued again, while acknowledged apps are not.
+func TestRedisQueueReclaim(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "")
+
+	newTestQueue := func(instanceID string) *redisQueue {
+		client, err := newRedisClient(server.url())
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		return newRedisQueue(client, "test:", instanceID)
+	}
+	next := func(q *redisQueue, want int64) {
+		t.Helper()
+		id, ok, err := q.Next(ctx)
+		if err != nil || !ok || id != want {
+			t.Fatalf("Worker %s: expected app %d, got %d, %v, %v", q.instanceID, want, id, ok, err)
+		}
+	}
+
+	a, b := newTestQueue("a"), newTestQueue("b")
+	defer func() {
+		_ = b.Close()
+	}()
+	if started, err := a.Fill(ctx, []int64{1, 2, 3}); err != nil || !started {
+		t.Fatalf("Failed to fill queue: %v, %v", started, err)
+	}
+	if started, err := b.Fill(ctx, []int64{1, 2, 3}); err != nil || started {
+		t.Fatalf("Second worker started a new cycle: %v, %v", started, err)
+	}
+
+	next(a, 1)
+	next(b, 2)
+	if err := b.Ack(ctx, 2); err != nil {
+		t.Fatalf("Failed to acknowledge app: %v", err)
+	}
+
+	// Worker a crashes: its liveness key expires without the app being acknowledged.
+	close(a.stop)
+	<-a.done
+	server.mu.Lock()
+	delete(server.strings, a.aliveKey("a"))
+	server.mu.Unlock()
+
+	next(b, 1)
+	next(b, 3)
+	if _, ok, err := b.Next(ctx); err != nil || ok {
+		t.Fatalf("Expected the cycle to be done: %v, %v", ok, err)
+	}
+	for _, id := range []int64{1, 3} {
+		if err := b.Ack(ctx, id); err != nil {
+			t.Fatalf("Failed to acknowledge app: %v", err)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if held := len(server.lists["test:processing:a"]) + len(server.lists["test:processing:b"]); held != 0 {
+		t.Errorf("Expected no held apps, got %d", held)
+	}
+	if server.sets["test:workers"]["a"] {
+		t.Errorf("Crashed worker is still registered")
+	}
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+// manifestAcceptHeader lists the manifest media types accepted when resolving digests.
+// Index types come first so multi-arch images resolve to the same digest docker pull uses.
+var manifestAcceptHeader = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// resolveImageDigest resolves a (mutable) image tag to its current content digest
+// using the OCI distribution API. Anonymous bearer tokens are obtained as needed.
+func (w *Worker) resolveImageDigest(ctx context.Context, ref rofl.ImageRef) (string, error) {
+	host, repository := ref.Registry()
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, url.PathEscape(ref.Reference()))
+
+	resp, err := w.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+
+	// Most public registries require an anonymous token even for pulls.
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := w.registryToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", fmt.Errorf("failed to get registry token: %w", err)
+		}
+		resp, err = w.headManifest(ctx, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+		_ = resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned HTTP %d", resp.StatusCode)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry did not return a content digest")
+	}
+	return digest, nil
+}
+
+// headManifest issues a HEAD request for an image manifest.
+func (w *Worker) headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", manifestAcceptHeader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// registryToken obtains an anonymous bearer token as described by a WWW-Authenticate challenge.
+func (w *Worker) registryToken(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+
+	values := parseChallengeParams(params)
+	realm := values["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("auth challenge without realm")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid realm %q: %w", realm, err)
+	}
+	q := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v := values[key]; v != "" {
+			q.Set(key, v)
+		}
+	}
+	tokenURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := w.untrusted.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	return result.AccessToken, nil
+}
+
+// parseChallengeParams parses the comma separated key="value" pairs of an auth challenge.
+func parseChallengeParams(params string) map[string]string {
+	values := make(map[string]string)
+	for _, part := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		values[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return values
+}
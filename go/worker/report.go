@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// verificationReport returns the structured outcome of a verification: the one reported by the
+// backend, or else the one parsed from the build output. It returns nil if neither has one.
+func verificationReport(result *VerifyDeploymentsResult) *models.VerificationReport {
+	if result.Report != nil {
+		return result.Report
+	}
+	return parseVerificationReport(result.Stdout + "\n" + result.Stderr)
+}
+
+// parseVerificationReport extracts the enclave identities of a failed verification from the
+// output of oasis-cli, which lists the built identities and the ones they differ from:
+//
+//	Built enclave identities DIFFER from on-chain enclave identities!
+//	Built enclave identities:
+//	  - <identity>
+//	On-chain enclave identities:
+//	  - <identity>
+//
+// The lists are collected first and paired at the end, so that they may come in any order. It
+// returns nil if the output lists no expected identities.
+func parseVerificationReport(output string) *models.VerificationReport {
+	type section struct {
+		source     string
+		identities []string
+	}
+	var built []string
+	var expected []*section
+	var list *[]string
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "- ") && list != nil:
+			*list = append(*list, strings.TrimSpace(strings.TrimPrefix(line, "- ")))
+		case strings.EqualFold(line, "Built enclave identities:"):
+			built = nil
+			list = &built
+		case strings.HasSuffix(strings.ToLower(line), " enclave identities:"):
+			sec := &section{source: models.IdentitySourceManifest}
+			switch strings.TrimSuffix(strings.ToLower(line), " enclave identities:") {
+			case "on-chain", "onchain":
+				sec.source = models.IdentitySourceOnChain
+			}
+			expected = append(expected, sec)
+			list = &sec.identities
+		default:
+			list = nil
+		}
+	}
+
+	var report models.VerificationReport
+	for _, sec := range expected {
+		report.Enclaves = append(report.Enclaves, pairIdentities(sec.source, sec.identities, built)...)
+	}
+	if len(report.Enclaves) == 0 {
+		return nil
+	}
+	return &report
+}
+
+// pairIdentities pairs the expected enclave identities of a source with the built ones. Identities
+// that were built as expected pair with themselves, the others pair in the order they are listed.
+func pairIdentities(source string, expected, actual []string) []models.EnclaveIdentities {
+	var pairs []models.EnclaveIdentities
+	var missing, unexpected []string
+	for _, id := range expected {
+		if slices.Contains(actual, id) {
+			pairs = append(pairs, models.EnclaveIdentities{Source: source, Expected: id, Actual: id})
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	for _, id := range actual {
+		if !slices.Contains(expected, id) {
+			unexpected = append(unexpected, id)
+		}
+	}
+	for i := range max(len(missing), len(unexpected)) {
+		pair := models.EnclaveIdentities{Source: source}
+		if i < len(missing) {
+			pair.Expected = missing[i]
+		}
+		if i < len(unexpected) {
+			pair.Actual = unexpected[i]
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// mismatchedIdentities returns the enclave identities of a report that were not built as expected.
+func mismatchedIdentities(report *models.VerificationReport) []models.EnclaveIdentities {
+	if report == nil {
+		return nil
+	}
+	var mismatched []models.EnclaveIdentities
+	for _, enclave := range report.Enclaves {
+		if enclave.Expected != enclave.Actual {
+			mismatched = append(mismatched, enclave)
+		}
+	}
+	return mismatched
+}
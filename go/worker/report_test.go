@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Test that enclave identities are parsed from oasis-cli output and paired whatever the order of
+// the lists.
+func TestParseVerificationReport(t *testing.T) {
+	const (
+		built   = "u5p7dpqRU0BjJVG6F1bYQrwiZcrW6kSxxw4f5JQoRHYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="
+		onChain = "pFKLsu4bTGBpbHvIzm6PPbnnv9NOZqNWiFeKcpgBfE8AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="
+		other   = "0UVwg6hcSeZzh9AaltmDD0hn0i1QJ3Y6yWbnTVO2D3kAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="
+	)
+	const header = `Building a ROFL application...
+Deployment: mainnet
+Network:    mainnet
+ParaTime:   sapphire
+App ID:     rofl1qqn9xndja7e2pnxhttktmecvwzz0yqwxsquqyxdf
+Name:       demo
+Version:    0.1.0
+TEE:        tdx
+Kind:       container
+Building a container-based TDX ROFL application...
+Computing enclave identity...
+`
+
+	for _, tc := range []struct {
+		name   string
+		output string
+		want   []models.EnclaveIdentities
+	}{
+		{
+			name: "built identities first",
+			output: header + `Built enclave identities DIFFER from on-chain enclave identities!
+Built enclave identities:
+  - ` + built + `
+On-chain enclave identities:
+  - ` + onChain + `
+  - ` + other + `
+`,
+			want: []models.EnclaveIdentities{
+				{Source: models.IdentitySourceOnChain, Expected: onChain, Actual: built},
+				{Source: models.IdentitySourceOnChain, Expected: other},
+			},
+		},
+		{
+			name: "expected identities first",
+			output: header + `Built enclave identities DIFFER from manifest enclave identities!
+Manifest enclave identities:
+  - ` + onChain + `
+Built enclave identities:
+  - ` + built + `
+`,
+			want: []models.EnclaveIdentities{
+				{Source: models.IdentitySourceManifest, Expected: onChain, Actual: built},
+			},
+		},
+		{
+			name: "identity built as expected",
+			output: header + `Built enclave identities DIFFER from on-chain enclave identities!
+On-chain enclave identities:
+  - ` + other + `
+  - ` + built + `
+Built enclave identities:
+  - ` + built + `
+`,
+			want: []models.EnclaveIdentities{
+				{Source: models.IdentitySourceOnChain, Expected: built, Actual: built},
+				{Source: models.IdentitySourceOnChain, Expected: other},
+			},
+		},
+		{
+			name:   "matching identities",
+			output: header + "Built enclave identities MATCH manifest enclave identities.\n",
+		},
+		{
+			name:   "no identities",
+			output: header + "Error: failed to build: exit status 1\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := parseVerificationReport(tc.output)
+			var got []models.EnclaveIdentities
+			if report != nil {
+				got = report.Enclaves
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
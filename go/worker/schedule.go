@@ -0,0 +1,165 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// sleep waits for the given duration or until the context is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// jitter returns a random duration between zero and the configured percentage of d.
+func (w *Worker) jitter(d time.Duration) time.Duration {
+	maxJitter := d * time.Duration(w.cfg.JitterPercent) / 100
+	if maxJitter <= 0 {
+		return 0
+	}
+	return rand.N(maxJitter)
+}
+
+// cycleWindow returns the window verifications are spread across, or zero if spreading is disabled.
+func (w *Worker) cycleWindow() time.Duration {
+	return time.Duration(w.cfg.CycleWindow) * time.Minute
+}
+
+// appDelay returns how long to wait before verifying the app at index (of numApps) in a cycle
+// that started at cycleStart.
+//
+// With a cycle window configured, apps get evenly sized slots across the window and each
+// verification starts at a random offset within the jitter range of its slot. Apps whose slot
+// already passed (because earlier verifications ran long) start immediately. Without a window,
+// apps are separated by app_interval plus jitter.
+func (w *Worker) appDelay(cycleStart time.Time, index, numApps int) time.Duration {
+	if window := w.cycleWindow(); window > 0 {
+		slot := window / time.Duration(numApps)
+		start := cycleStart.Add(slot*time.Duration(index) + w.jitter(slot))
+		return time.Until(start)
+	}
+
+	if index == 0 {
+		return 0
+	}
+	appInterval := time.Duration(w.cfg.AppInterval) * time.Minute
+	return appInterval + w.jitter(appInterval)
+}
+
+// cycleDelay returns how long to wait after a cycle that started at cycleStart before starting the next one.
+func (w *Worker) cycleDelay(cycleStart time.Time) time.Duration {
+	if window := w.cycleWindow(); window > 0 {
+		return time.Until(cycleStart.Add(window))
+	}
+	appInterval := time.Duration(w.cfg.AppInterval) * time.Minute
+	return appInterval + w.jitter(appInterval)
+}
+
+// expectedCycleDuration returns how long a cycle of numApps apps is expected to take by its
+// schedule, including the wait before the next one.
+func (w *Worker) expectedCycleDuration(numApps int) time.Duration {
+	if window := w.cycleWindow(); window > 0 {
+		return window
+	}
+	return time.Duration(w.cfg.AppInterval) * time.Minute * time.Duration(max(numApps, 1))
+}
+
+// deploymentDelay returns how long to wait between verifying deployments of the same app.
+func (w *Worker) deploymentDelay() time.Duration {
+	interval := time.Duration(w.cfg.DeploymentInterval) * time.Second
+	return interval + w.jitter(interval)
+}
+
+// failureBackoff returns how long to wait before retrying an app after the given number of
+// consecutive failures: failure_backoff doubled per failure, capped at max_failure_backoff.
+func (w *Worker) failureBackoff(failures int) time.Duration {
+	backoff := time.Duration(w.cfg.FailureBackoff) * time.Minute
+	maxBackoff := time.Duration(w.cfg.MaxFailureBackoff) * time.Minute
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// dueApps filters out apps that are backing off after consecutive failures.
+func (w *Worker) dueApps(apps []*models.App) []*models.App {
+	now := time.Now()
+	due := make([]*models.App, 0, len(apps))
+	for _, app := range apps {
+		if app.NextAttemptAt.Valid && app.NextAttemptAt.Time.After(now) {
+			w.logger.Debug("app backing off after failures, skipping",
+				"app_id", app.ID,
+				"failures", app.ConsecutiveFailures,
+				"next_attempt_at", app.NextAttemptAt.Time)
+			continue
+		}
+		due = append(due, app)
+	}
+	return due
+}
+
+// recordAttempt updates the failure tracking of an app after a verification attempt.
+// Only errors the app can cause, such as a manifest that cannot be fetched, parsed, or validated,
+// count as failures: rate limiting, backend errors, and shutdown are not the app's fault.
+func (w *Worker) recordAttempt(ctx context.Context, app *models.App, verifyErr error) {
+	switch {
+	case verifyErr == nil:
+		if app.ConsecutiveFailures == 0 {
+			return
+		}
+		if err := w.db.ResetAppFailures(ctx, app.ID); err != nil {
+			w.logger.Error("failed to reset app failures", "app_id", app.ID, "error", err)
+		}
+	case errors.Is(verifyErr, github.ErrRateLimited), errors.Is(verifyErr, errBackend), ctx.Err() != nil:
+		return
+	default:
+		backoff := w.failureBackoff(app.ConsecutiveFailures + 1)
+		failures, err := w.db.RecordAppFailure(ctx, app.ID, verifyErr.Error(), time.Now().Add(backoff))
+		if err != nil {
+			w.logger.Error("failed to record app failure", "app_id", app.ID, "error", err)
+			return
+		}
+		if failures >= w.cfg.QuarantineAfter {
+			w.logger.Warn("app quarantined after consecutive failures",
+				"app_id", app.ID,
+				"github_url", app.GitHubURL,
+				"failures", failures,
+				"backoff", backoff)
+		}
+	}
+}
+
+// staleSweepInterval is how often verified deployments are checked for staleness.
+const staleSweepInterval = 10 * time.Minute
+
+// expireStaleVerifications periodically downgrades verified deployments that were not
+// re-verified within max_verification_age, so outdated results are not shown as verified.
+func (w *Worker) expireStaleVerifications(ctx context.Context) {
+	maxAge := time.Duration(w.cfg.MaxVerificationAge) * time.Hour
+
+	for {
+		n, err := w.db.MarkStaleDeployments(ctx, time.Now().Add(-maxAge))
+		switch {
+		case err != nil && ctx.Err() == nil:
+			w.logger.Error("failed to mark stale deployments", "error", err)
+		case n > 0:
+			w.logger.Info("marked deployments as stale", "count", n, "max_age", maxAge)
+		}
+
+		if err := sleep(ctx, staleSweepInterval); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ptrus/rofl-attestations/config"
+)
+
+// Signer signs SIWE messages on behalf of an Ethereum account.
+type Signer interface {
+	// Address returns the address of the signing account.
+	Address() common.Address
+	// SignMessage returns the EIP-191 personal message signature of msg, with a recovery ID of 0 or 1.
+	SignMessage(ctx context.Context, msg []byte) ([]byte, error)
+}
+
+// NewSigner creates the signer configured for the worker: a remote signer, a local private key,
+// or nil if authentication is not configured.
+func NewSigner(cfg *config.WorkerConfig, client *http.Client) (Signer, error) {
+	switch {
+	case cfg.RemoteSigner.URL != "":
+		return NewRemoteSigner(client, &cfg.RemoteSigner)
+	case cfg.PrivateKey != "":
+		return NewKeySigner(cfg.PrivateKey)
+	default:
+		return nil, nil
+	}
+}
+
+// NewSigners creates the active signer followed by a signer for each standby key,
+// or nil if authentication is not configured.
+func NewSigners(cfg *config.WorkerConfig, client *http.Client) ([]Signer, error) {
+	active, err := NewSigner(cfg, client)
+	if err != nil || active == nil {
+		return nil, err
+	}
+
+	signers := []Signer{active}
+	seen := map[common.Address]bool{active.Address(): true}
+	for i, key := range cfg.StandbyPrivateKeys {
+		signer, err := NewKeySigner(key)
+		if err != nil {
+			return nil, fmt.Errorf("standby key %d: %w", i, err)
+		}
+		if seen[signer.Address()] {
+			return nil, fmt.Errorf("standby key %d: duplicate key %s", i, signer.Address().Hex())
+		}
+		seen[signer.Address()] = true
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+// NewKeySigner creates a signer from a hex-encoded private key.
+func NewKeySigner(privateKeyHex string) (*KeySigner, error) {
+	privKeyBytes, err := hex.DecodeString(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return &KeySigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}, nil
+}
+
+// Address implements Signer.
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignMessage implements Signer.
+func (s *KeySigner) SignMessage(_ context.Context, msg []byte) ([]byte, error) {
+	return crypto.Sign(signHash(msg).Bytes(), s.privateKey)
+}
+
+// RemoteSigner delegates signing to a service speaking Ethereum JSON-RPC, e.g. Web3Signer or Clef,
+// so the private key never enters this process.
+type RemoteSigner struct {
+	client  *http.Client
+	url     string
+	method  string
+	token   string
+	address common.Address
+}
+
+// NewRemoteSigner creates a signer for the account at the configured JSON-RPC endpoint.
+func NewRemoteSigner(client *http.Client, cfg *config.RemoteSignerConfig) (*RemoteSigner, error) {
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid remote signer address %q", cfg.Address)
+	}
+	return &RemoteSigner{
+		client:  client,
+		url:     cfg.URL,
+		method:  cfg.Method,
+		token:   cfg.Token,
+		address: common.HexToAddress(cfg.Address),
+	}, nil
+}
+
+// Address implements Signer.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignMessage implements Signer. The returned signature is checked to recover to the signer address.
+func (s *RemoteSigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  s.method,
+		"params":  []string{s.address.Hex(), "0x" + hex.EncodeToString(msg)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("remote signer error %d: %s", result.Error.Code, result.Error.Message)
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(result.Result, "0x"))
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature from remote signer")
+	}
+	// Signers return a recovery ID of 27 or 28 per the eth_sign convention.
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(signHash(msg).Bytes(), sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != s.address {
+		return nil, fmt.Errorf("remote signer signed with %s instead of %s", signer.Hex(), s.address.Hex())
+	}
+	return sig, nil
+}
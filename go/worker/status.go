@@ -0,0 +1,237 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Status is a snapshot of the worker state, exposed via the admin API.
+type Status struct {
+	Enabled                  bool       `json:"enabled"`
+	InstanceID               string     `json:"instance_id"`
+	Paused                   bool       `json:"paused"`
+	Draining                 bool       `json:"draining"` // Shutting down once the verification in progress finishes.
+	CurrentAppID             int64      `json:"current_app_id,omitempty"`
+	CurrentAppURL            string     `json:"current_app_url,omitempty"`
+	QueueLength              int        `json:"queue_length"` // Apps left in the current cycle, excluding the current one.
+	CycleStartedAt           *time.Time `json:"cycle_started_at,omitempty"`
+	LastCycleCompletedAt     *time.Time `json:"last_cycle_completed_at,omitempty"`
+	LastCycleDurationSeconds float64    `json:"last_cycle_duration_seconds"`
+}
+
+// Status returns the current worker state.
+func (w *Worker) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := Status{
+		Enabled:                  w.cfg.Enabled,
+		InstanceID:               w.cfg.InstanceID,
+		Paused:                   w.paused,
+		Draining:                 w.draining,
+		QueueLength:              w.queueLength,
+		LastCycleDurationSeconds: w.lastCycleDuration.Seconds(),
+	}
+	if w.currentApp != nil {
+		status.CurrentAppID = w.currentApp.ID
+		status.CurrentAppURL = w.currentApp.GitHubURL
+	}
+	if !w.cycleStartedAt.IsZero() {
+		t := w.cycleStartedAt
+		status.CycleStartedAt = &t
+	}
+	if !w.lastCycleCompletedAt.IsZero() {
+		t := w.lastCycleCompletedAt
+		status.LastCycleCompletedAt = &t
+	}
+	return status
+}
+
+// Budget returns the budget of backend tasks in flight, shared by the worker and verifications
+// requested through the API.
+func (w *Worker) Budget() *Budget {
+	return w.budget
+}
+
+// AuthClient returns the client used to authenticate with the backend, or nil if authentication is not configured.
+func (w *Worker) AuthClient() *AuthClient {
+	return w.authClient
+}
+
+// Pause stops the worker from starting new verifications. Verifications already in progress complete.
+func (w *Worker) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.paused {
+		return
+	}
+	w.paused = true
+	w.resumeCh = make(chan struct{})
+	w.logger.Info("worker paused")
+}
+
+// Resume lets a paused worker continue verifying apps.
+func (w *Worker) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.paused {
+		return
+	}
+	w.paused = false
+	close(w.resumeCh)
+	w.logger.Info("worker resumed")
+}
+
+// ErrAppBusy is returned when an app is already being verified.
+var ErrAppBusy = errors.New("app is being verified")
+
+// VerifyNow verifies an app right away, outside of the verification cycle. The verification runs
+// in the background once the app's lease is acquired, and also while the worker is paused.
+func (w *Worker) VerifyNow(ctx context.Context, app *models.App) error {
+	w.mu.Lock()
+	busy := w.requested[app.ID] || (w.currentApp != nil && w.currentApp.ID == app.ID)
+	if !busy {
+		if w.requested == nil {
+			w.requested = make(map[int64]bool)
+		}
+		w.requested[app.ID] = true
+	}
+	w.mu.Unlock()
+	if busy {
+		return ErrAppBusy
+	}
+	done := func() {
+		w.mu.Lock()
+		delete(w.requested, app.ID)
+		w.mu.Unlock()
+	}
+
+	claimed, err := w.db.ClaimApp(ctx, app.ID, w.cfg.InstanceID, time.Now().Add(w.leaseDuration()), time.Now())
+	if err != nil || !claimed {
+		done()
+		if err != nil {
+			return err
+		}
+		return ErrAppBusy
+	}
+
+	go func() {
+		defer done()
+		ctx := context.WithoutCancel(ctx)
+		w.logger.Info("verifying app on request", "app_id", app.ID)
+		err := w.verifyApp(ctx, app)
+		w.recordAttempt(ctx, app, err)
+		w.releaseApp(ctx, app)
+		if err != nil {
+			w.logger.Error("failed to verify app", "app_id", app.ID, "github_url", app.GitHubURL, "error", err)
+		}
+	}()
+	return nil
+}
+
+// RunOnce verifies every app once, one after another, without the pacing and failure backoff of
+// the verification cycle. Apps whose lease is held by another instance are skipped.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	apps, err := w.db.GetAllApps(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get apps: %w", err)
+	}
+	for _, app := range apps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		claimed, err := w.claimApp(ctx, app, time.Now())
+		if err != nil || !claimed {
+			w.logger.Warn("skipping app", "app_id", app.ID, "claimed", claimed, "error", err)
+			continue
+		}
+
+		err = w.verifyApp(ctx, app)
+		w.recordAttempt(ctx, app, err)
+		w.releaseApp(ctx, app)
+		if err != nil {
+			w.logger.Error("failed to verify app", "app_id", app.ID, "github_url", app.GitHubURL, "error", err)
+		}
+	}
+	return nil
+}
+
+// isRequested reports whether an app is being verified on request.
+func (w *Worker) isRequested(appID int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.requested[appID]
+}
+
+// waitIfPaused blocks while the worker is paused.
+func (w *Worker) waitIfPaused(ctx context.Context) error {
+	w.mu.Lock()
+	paused, resumeCh := w.paused, w.resumeCh
+	w.mu.Unlock()
+
+	if !paused {
+		return nil
+	}
+
+	w.logger.Info("worker paused, waiting for resume")
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumeCh:
+		return nil
+	}
+}
+
+// setCurrentApp records the app being verified and the number of apps left in the cycle.
+func (w *Worker) setCurrentApp(app *models.App, queueLength int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.currentApp = app
+	w.queueLength = queueLength
+}
+
+// startCycle records the start of a verification cycle.
+func (w *Worker) startCycle(start time.Time, numApps int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cycleStartedAt = start
+	w.queueLength = numApps
+}
+
+// finishCycle records the completion of the current verification cycle, returning when it
+// completed and how long it took.
+func (w *Worker) finishCycle() (time.Time, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.lastCycleDuration = now.Sub(w.cycleStartedAt)
+	w.lastCycleCompletedAt = now
+	w.cycleStartedAt = time.Time{}
+	w.currentApp = nil
+	w.queueLength = 0
+	return now, w.lastCycleDuration
+}
+
+// HeartbeatInterval is how often a running worker records that it is alive.
+const HeartbeatInterval = time.Minute
+
+// heartbeat records that the worker is alive every HeartbeatInterval until the context is done.
+func (w *Worker) heartbeat(ctx context.Context) {
+	for {
+		if err := w.db.RecordHeartbeat(ctx, w.cfg.InstanceID, time.Now()); err != nil && ctx.Err() == nil {
+			w.logger.Warn("failed to record heartbeat", "error", err)
+		}
+		if err := sleep(ctx, HeartbeatInterval); err != nil {
+			return
+		}
+	}
+}
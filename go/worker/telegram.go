@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// maxTelegramDetails caps the verification message quoted in a Telegram message, well below the
+// limit of 4096 characters per message.
+const maxTelegramDetails = 1000
+
+// telegramMessage is a request of the sendMessage method of the Telegram Bot API.
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// telegramNotifier posts notifications to the configured Telegram chats, unless the filters
+// exclude them.
+type telegramNotifier struct {
+	w *Worker
+}
+
+// Channel implements Notifier.
+func (n *telegramNotifier) Channel() string {
+	return "telegram"
+}
+
+// Destinations implements Notifier.
+func (n *telegramNotifier) Destinations(_ context.Context, notification *DeploymentNotification) ([]string, error) {
+	cfg := &n.w.cfg.Notifications.Telegram
+	if cfg.OnlyFailures && notification.Status != models.StatusFailed {
+		return nil, nil
+	}
+	if cfg.OnlyMainnet && notification.Network != "mainnet" {
+		return nil, nil
+	}
+	return cfg.ChatIDs, nil
+}
+
+// Send implements Notifier.
+func (n *telegramNotifier) Send(ctx context.Context, chatID string, notification *DeploymentNotification) error {
+	msg := &telegramMessage{ChatID: chatID, Text: telegramText(notification), DisableWebPagePreview: true}
+	return n.w.sendTelegramMessage(ctx, msg)
+}
+
+// telegramText formats a notification as a plain text Telegram message.
+func telegramText(notification *DeploymentNotification) string {
+	var text strings.Builder
+	if notification.Severity == SeverityCritical {
+		text.WriteString("🚨 REGRESSION\n")
+	}
+	fmt.Fprintf(&text, "%s %s: %s → %s\n", strings.TrimPrefix(notification.GitHubURL, "https://github.com/"),
+		notification.Deployment, orNone(string(notification.PreviousStatus)), notification.Status)
+	if notification.CommitSHA != "" {
+		fmt.Fprintf(&text, "Commit: %s\n", notification.CommitSHA)
+	}
+	if details := notification.Message; details != "" {
+		if len([]rune(details)) > maxTelegramDetails {
+			details = string([]rune(details)[:maxTelegramDetails]) + "…"
+		}
+		fmt.Fprintf(&text, "%s\n", details)
+	}
+	text.WriteString(notification.GitHubURL)
+	return text.String()
+}
+
+// sendTelegramMessage sends a message through the Telegram Bot API.
+func (w *Worker) sendTelegramMessage(ctx context.Context, msg *telegramMessage) error {
+	cfg := &w.cfg.Notifications.Telegram
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	endpoint := strings.TrimRight(cfg.APIURL, "/") + "/bot" + cfg.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.external.Do(req)
+	if err != nil {
+		// The bot token is part of the URL, which errors of the client include.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = strings.ReplaceAll(urlErr.URL, cfg.BotToken, "<bot_token>")
+		}
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return statusError(resp.StatusCode, fmt.Errorf("Telegram returned HTTP %d: %s", resp.StatusCode, result.Description))
+	}
+	return nil
+}
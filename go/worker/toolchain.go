@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ptrus/rofl-attestations/models"
+	"github.com/ptrus/rofl-attestations/rofl"
+)
+
+var (
+	// cliVersionPattern matches the oasis-cli version in build output, e.g. "Oasis CLI version: 0.17.0"
+	// or "Software version: v0.17.0".
+	cliVersionPattern = regexp.MustCompile(`(?i)(?:oasis(?:-cli| cli)?|software)\s+version:?\s+v?(\d+\.\d+\.\d+[0-9A-Za-z.+-]*)`)
+	// builderImagePattern matches the builder container image in build output, e.g.
+	// "Using builder image: ghcr.io/oasisprotocol/rofl-dev:v0.5.0@sha256:...".
+	builderImagePattern = regexp.MustCompile(`(?i)builder(?:\s+image)?:\s*([a-z0-9.-]+(?::\d+)?/[^\s"']+)`)
+)
+
+// toolchainVersions returns the oasis-cli version and builder image a verification was built with.
+// Versions reported by the backend take precedence over those found in the build output. The builder
+// image falls back to the one pinned in the manifest, which oasis-cli builds with.
+func toolchainVersions(result *VerifyDeploymentsResult, app *models.App) (cliVersion, builderImage string) {
+	output := result.Stdout + "\n" + result.Stderr
+
+	cliVersion = result.CLIVersion
+	if cliVersion == "" {
+		if m := cliVersionPattern.FindStringSubmatch(output); m != nil {
+			cliVersion = m[1]
+		}
+	}
+
+	builderImage = result.BuilderImage
+	if builderImage == "" {
+		if m := builderImagePattern.FindStringSubmatch(output); m != nil {
+			builderImage = strings.TrimRight(m[1], ".,;")
+		}
+	}
+	if builderImage == "" && app.RoflYAML.Valid {
+		if manifest, err := rofl.Parse([]byte(app.RoflYAML.String)); err == nil {
+			builderImage = manifest.Artifacts.Builder
+		}
+	}
+
+	return cliVersion, builderImage
+}
@@ -3,19 +3,31 @@ package worker
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"maps"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/ptrus/rofl-attestations/config"
 	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/github"
+	"github.com/ptrus/rofl-attestations/httpclient"
+	"github.com/ptrus/rofl-attestations/lockfile"
 	"github.com/ptrus/rofl-attestations/models"
 	"github.com/ptrus/rofl-attestations/rofl"
+	"github.com/ptrus/rofl-attestations/storage"
 )
 
 // Worker handles periodic verification of ROFL apps.
@@ -23,15 +35,39 @@ type Worker struct {
 	cfg        *config.WorkerConfig
 	db         *db.DB
 	logger     *slog.Logger
-	client     *http.Client
+	client     *http.Client // Client for the verification backend.
+	external   *http.Client // Client for configured external services, e.g. Nexus and PagerDuty.
+	untrusted  *http.Client // Client for URLs derived from manifests or user input, e.g. logos and webhooks.
+	github     *github.Client
+	artifacts  *storage.Artifacts
 	authClient *AuthClient
+	anchorKey  *KeySigner // Signs anchor transactions, nil if anchoring is disabled.
+	queue      Queue
+	budget     *Budget       // Backend tasks in flight, shared with verifications requested through the API.
+	notifyNow  chan struct{} // Wakes dispatchNotifications, e.g. to alert on a regression right away.
+	notifiers  []Notifier    // Configured notification channels.
+
+	// State exposed via Status, guarded by mu.
+	mu                   sync.Mutex
+	paused               bool
+	draining             bool // Shutting down, finishing the verification in progress.
+	resumeCh             chan struct{}
+	currentApp           *models.App
+	queueLength          int
+	cycleStartedAt       time.Time
+	lastCycleCompletedAt time.Time
+	lastCycleDuration    time.Duration
+	backendHealth        BackendHealth
+	requested            map[int64]bool // Apps being verified on request, outside of the cycle.
 }
 
 // VerifyDeploymentsRequest represents the request to verify_deployments endpoint.
 type VerifyDeploymentsRequest struct {
-	RepositoryURL  string `json:"repository_url"`
-	Ref            string `json:"ref"`
-	DeploymentName string `json:"deployment_name"`
+	RepositoryURL   string   `json:"repository_url"`
+	Ref             string   `json:"ref"`
+	DeploymentName  string   `json:"deployment_name,omitempty"`
+	DeploymentNames []string `json:"deployment_names,omitempty"` // Batched request, instead of deployment_name.
+	IncludeReport   bool     `json:"include_report"`             // Ask for a VerificationReport with the results, if the backend supports it.
 }
 
 // VerifyDeploymentsResponse represents the response from verify_deployments endpoint.
@@ -47,43 +83,129 @@ type VerifyDeploymentsResult struct {
 	Stdout    string `json:"stdout"`
 	Stderr    string `json:"stderr"`
 	Err       string `json:"err"`
+
+	// Toolchain the backend built with, if it reports it.
+	CLIVersion   string `json:"cli_version,omitempty"`
+	BuilderImage string `json:"builder_image,omitempty"`
+
+	// Enclave identities expected and built, nil if the backend does not report them.
+	Report *models.VerificationReport `json:"report,omitempty"`
+
+	// Verdicts of the deployments of a batched request, which share the build and its output.
+	Deployments map[string]DeploymentResult `json:"deployments,omitempty"`
+}
+
+// DeploymentResult is the verdict of one deployment of a batched request.
+type DeploymentResult struct {
+	Verified bool                       `json:"verified"`
+	Err      string                     `json:"err"`
+	Report   *models.VerificationReport `json:"report,omitempty"`
+}
+
+// forDeployment returns the result of one deployment of a batched request.
+func (r *VerifyDeploymentsResult) forDeployment(deploymentName string) *VerifyDeploymentsResult {
+	result := *r
+	result.Deployments = nil
+	verdict, ok := r.Deployments[deploymentName]
+	if !ok {
+		verdict = DeploymentResult{Err: "Backend returned no result for this deployment."}
+	}
+	result.Verified = verdict.Verified
+	result.Err = verdict.Err
+	result.Report = verdict.Report
+	return &result
 }
 
 // New creates a new worker instance.
-func New(cfg *config.WorkerConfig, database *db.DB, logger *slog.Logger) (*Worker, error) {
-	var authClient *AuthClient
-	var err error
+func New(cfg *config.WorkerConfig, httpCfg *config.HTTPConfig, database *db.DB, gh *github.Client, artifacts *storage.Artifacts, logger *slog.Logger) (*Worker, error) {
+	clients, err := httpclient.New(httpCfg, &cfg.BackendTLS)
+	if err != nil {
+		return nil, err
+	}
+	client := clients.Client(httpclient.Backend)
+	external := clients.Client(httpclient.External)
 
-	// Initialize auth client if private key is provided
-	if cfg.PrivateKey != "" {
-		authClient, err = NewAuthClient(
+	// Initialize auth client if a private key or remote signer is configured
+	signers, err := NewSigners(cfg, external)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	var authClient *AuthClient
+	if signers != nil {
+		// Keep the key made active by the latest rotation, if it is still configured.
+		active, err := database.GetActiveAuthKey(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		if active != "" {
+			var ok bool
+			if signers, ok = ActivateSigner(signers, common.HexToAddress(active)); ok {
+				logger.Info("using SIWE key activated by rotation", "address", active)
+			}
+		}
+		authClient = NewAuthClient(
+			client,
 			cfg.BackendURL,
-			cfg.PrivateKey,
+			signers,
 			cfg.SIWEDomain,
 			cfg.ChainID,
 			logger,
 		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create auth client: %w", err)
-		}
-		logger.Info("authentication enabled", "address", authClient.address.Hex())
+		logger.Info("authentication enabled", "address", authClient.Address().Hex(), "remote_signer", cfg.RemoteSigner.URL != "", "standby_keys", len(signers)-1)
 	} else {
 		logger.Warn("no private key configured, running without authentication")
 	}
 
-	return &Worker{
+	var anchorKey *KeySigner
+	if cfg.Anchor.Enabled {
+		if !common.IsHexAddress(cfg.Anchor.Contract) {
+			return nil, fmt.Errorf("invalid anchor contract address %q", cfg.Anchor.Contract)
+		}
+		if anchorKey, err = NewKeySigner(cfg.PrivateKey); err != nil {
+			return nil, fmt.Errorf("failed to create anchor signer: %w", err)
+		}
+	}
+
+	queue, err := newQueue(&cfg.Queue, cfg.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create queue: %w", err)
+	}
+
+	w := &Worker{
 		cfg:        cfg,
 		db:         database,
 		logger:     logger,
+		github:     gh,
+		artifacts:  artifacts,
 		authClient: authClient,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}, nil
+		anchorKey:  anchorKey,
+		queue:      queue,
+		budget:     NewBudget(cfg.MaxBackendTasks),
+		notifyNow:  make(chan struct{}, 1),
+		client:     client,
+		external:   external,
+		untrusted:  clients.Untrusted(),
+	}
+	w.notifiers = w.newNotifiers()
+	return w, nil
 }
 
 // Start begins the continuous verification loop, cycling through apps one by one.
 func (w *Worker) Start(ctx context.Context) error {
+	// Expire outdated verifications even if the worker itself is disabled.
+	if w.cfg.MaxVerificationAge > 0 {
+		go w.expireStaleVerifications(ctx)
+	}
+	// Probe the backend even if the worker is disabled, since live verifications use it too.
+	if w.cfg.BackendURL != "" {
+		go w.monitorBackend(ctx)
+	}
+	if w.anchorKey != nil {
+		go w.anchorLog(ctx)
+	}
+	// Notify status changes even if the worker is disabled, e.g. deployments turning stale.
+	go w.dispatchNotifications(ctx)
+
 	if !w.cfg.Enabled {
 		w.logger.Info("worker disabled, skipping periodic verification")
 		return nil
@@ -91,10 +213,22 @@ func (w *Worker) Start(ctx context.Context) error {
 
 	w.logger.Info("starting verification worker",
 		"app_interval", w.cfg.AppInterval,
+		"cycle_window", w.cfg.CycleWindow,
+		"jitter_percent", w.cfg.JitterPercent,
+		"instance_id", w.cfg.InstanceID,
+		"queue", w.cfg.Queue.Backend,
 		"backend_url", w.cfg.BackendURL)
+	defer func() {
+		_ = w.queue.Close()
+	}()
+	go w.heartbeat(ctx)
 
 	appInterval := time.Duration(w.cfg.AppInterval) * time.Minute
 
+	// Verifications outlive ctx by the shutdown grace period, so their results are not lost.
+	workCtx, release := w.drainContext(ctx)
+	defer release()
+
 	for {
 		// Check context before starting a new cycle
 		if ctx.Err() != nil {
@@ -127,51 +261,155 @@ func (w *Worker) Start(ctx context.Context) error {
 			}
 		}
 
+		// Skip apps backing off after consecutive failures
+		apps = w.dueApps(apps)
+		if len(apps) == 0 {
+			w.logger.Info("all apps backing off after failures, waiting before next cycle")
+			if err := sleep(ctx, time.Duration(w.cfg.AppInterval)*time.Minute); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Prioritize apps without verified deployments
 		if err := w.sortAppsByVerificationStatus(ctx, apps); err != nil {
 			w.logger.Error("failed to sort apps by verification status", "error", err)
 		}
 
-		w.logger.Info("verifying apps one by one", "count", len(apps))
+		// Queue the apps, or join the cycle another worker sharing the queue already started
+		appsByID := make(map[int64]*models.App, len(apps))
+		appIDs := make([]int64, 0, len(apps))
+		for _, app := range apps {
+			appsByID[app.ID] = app
+			appIDs = append(appIDs, app.ID)
+		}
+		started, err := w.queue.Fill(ctx, appIDs)
+		if err != nil {
+			w.logger.Error("failed to queue apps", "error", err)
+			if err := sleep(ctx, appInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		numApps, err := w.queue.Len(ctx)
+		if err != nil {
+			w.logger.Error("failed to get queue length", "error", err)
+			numApps = len(apps)
+		}
+		if started {
+			w.logger.Info("verifying apps one by one", "count", numApps)
+		} else {
+			w.logger.Info("joining verification cycle in progress", "remaining", numApps)
+		}
+
+		// Process each app one at a time, spread across the cycle
+		cycleStart := time.Now()
+		w.startCycle(cycleStart, numApps)
+		for i := 0; ; i++ {
+			appID, ok, err := w.queue.Next(ctx)
+			if err != nil {
+				w.logger.Error("failed to get next app from queue", "error", err)
+				break
+			}
+			if !ok {
+				break
+			}
+			app, ok := appsByID[appID]
+			if !ok {
+				// Queued by another worker after this one listed the apps
+				if app, err = w.db.GetAppByID(ctx, appID); err != nil {
+					w.logger.Warn("skipping queued app", "app_id", appID, "error", err)
+					w.ackApp(ctx, appID)
+					continue
+				}
+			}
+
+			// Wait for this app's turn
+			if delay := w.appDelay(cycleStart, i, max(numApps, i+1)); delay > 0 {
+				w.logger.Info("waiting before next app", "duration", delay)
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+			}
+
+			// Hold off while paused by an operator
+			if err := w.waitIfPaused(ctx); err != nil {
+				return err
+			}
 
-		// Process each app one at a time
-		for i, app := range apps {
 			if ctx.Err() != nil {
 				w.logger.Info("context cancelled, stopping verification cycle")
 				return ctx.Err()
 			}
 
+			if w.isRequested(app.ID) {
+				w.logger.Info("app being verified on request, skipping", "app_id", app.ID)
+				w.ackApp(ctx, app.ID)
+				continue
+			}
+
+			// Skip apps verified by another replica, or already attempted by one during this cycle
+			claimed, err := w.claimApp(ctx, app, cycleStart)
+			if err != nil {
+				w.logger.Error("failed to claim app", "app_id", app.ID, "error", err)
+				w.ackApp(ctx, app.ID)
+				continue
+			}
+			if !claimed {
+				w.logger.Info("app claimed by another instance, skipping", "app_id", app.ID)
+				w.ackApp(ctx, app.ID)
+				continue
+			}
+
+			remaining, err := w.queue.Len(ctx)
+			if err != nil {
+				remaining = max(numApps-i-1, 0)
+			}
+			w.setCurrentApp(app, remaining)
+
 			w.logger.Info("processing app",
 				"app_id", app.ID,
-				"progress", fmt.Sprintf("%d/%d", i+1, len(apps)))
-
-			if err := w.verifyApp(ctx, app); err != nil {
+				"progress", fmt.Sprintf("%d/%d", i+1, numApps))
+
+			err = w.verifyApp(workCtx, app)
+			w.recordAttempt(workCtx, app, err)
+			w.releaseApp(workCtx, app)
+			if workCtx.Err() == nil {
+				// An app whose verification was abandoned stays held, to be queued again and resumed.
+				w.ackApp(workCtx, app.ID)
+			}
+			if ctx.Err() != nil {
+				w.logger.Info("verification in progress finished, stopping worker", "app_id", app.ID, "error", err)
+				return ctx.Err()
+			}
+			if err != nil {
 				w.logger.Error("failed to verify app",
 					"app_id", app.ID,
 					"github_url", app.GitHubURL,
 					"error", err)
-			}
 
-			// Wait before processing next app
-			if i < len(apps)-1 {
-				w.logger.Info("waiting before next app", "duration", appInterval)
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-time.After(appInterval):
-					// Continue to next app
+				// Back off until GitHub rate limits reset instead of failing every remaining app.
+				if errors.Is(err, github.ErrRateLimited) {
+					if reset := w.github.RateLimitReset(); !reset.IsZero() {
+						w.logger.Warn("waiting for GitHub rate limit reset", "reset", reset)
+						if err := sleep(ctx, time.Until(reset)); err != nil {
+							return err
+						}
+					}
 				}
 			}
 		}
 
-		w.logger.Info("verification cycle completed, waiting before next cycle", "duration", appInterval)
+		completedAt, duration := w.finishCycle()
+		if err := w.db.RecordCycleCompleted(ctx, w.cfg.InstanceID, completedAt, duration, w.expectedCycleDuration(numApps)); err != nil {
+			w.logger.Warn("failed to record completed cycle", "error", err)
+		}
 
 		// Wait before starting the next cycle to avoid hammering the backend
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(appInterval):
-			// Continue to next cycle
+		delay := w.cycleDelay(cycleStart)
+		w.logger.Info("verification cycle completed, waiting before next cycle", "duration", delay)
+		if err := sleep(ctx, delay); err != nil {
+			return err
 		}
 	}
 }
@@ -221,8 +459,25 @@ func (w *Worker) sortAppsByVerificationStatus(ctx context.Context, apps []*model
 func (w *Worker) verifyApp(ctx context.Context, app *models.App) error {
 	w.logger.Info("verifying app", "app_id", app.ID, "github_url", app.GitHubURL)
 
+	// Check for renames first, as fetching files of a moved repository may fail. Deleted
+	// repositories are not verified, keeping the last results until they expire, instead of
+	// failing every attempt to fetch their files.
+	switch err := w.fetchRepository(ctx, app); {
+	case errors.Is(err, errRepositoryDeleted):
+		w.logger.Warn("repository deleted, skipping", "app_id", app.ID, "github_url", app.GitHubURL)
+		return nil
+	case err != nil:
+		w.logger.Warn("failed to fetch repository", "app_id", app.ID, "error", err)
+	}
+
+	// Resolve the ref to verify, looking up the default branch if requested
+	ref, err := w.github.ResolveRef(ctx, app.GitHubURL, app.GitRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref: %w", err)
+	}
+
 	// Fetch latest rofl.yaml from GitHub
-	if err := w.fetchRoflYAML(ctx, app); err != nil {
+	if err := w.fetchRoflYAML(ctx, app, ref); err != nil {
 		w.logger.Error("failed to fetch rofl.yaml", "app_id", app.ID, "error", err)
 		return fmt.Errorf("failed to fetch rofl.yaml: %w", err)
 	}
@@ -238,119 +493,671 @@ func (w *Worker) verifyApp(ctx context.Context, app *models.App) error {
 		return fmt.Errorf("failed to parse rofl.yaml: %w", err)
 	}
 
+	if err := manifest.Validate(); err != nil {
+		w.logger.Warn("rofl.yaml failed validation", "app_id", app.ID, "error", err)
+	}
+
+	if app.SelectedDeployments.String != "" {
+		w.selectDeployments(ctx, app, manifest)
+	}
+
 	if len(manifest.Deployments) == 0 {
 		w.logger.Warn("app has no deployments, skipping", "app_id", app.ID)
 		return nil
 	}
 
-	// Verify each deployment
+	// Reject manifests whose TEE type does not match their artifacts or policies, as builds of
+	// them cannot reproduce the registered identities
+	if err := manifest.ValidateTEE(); err != nil {
+		msg := fmt.Sprintf("Invalid manifest: %s", err)
+		for deploymentName := range manifest.Deployments {
+			if err := w.updateDeployment(ctx, app, deploymentName, "", string(models.StatusFailed), msg); err != nil {
+				return fmt.Errorf("failed to update deployment verification: %w", err)
+			}
+		}
+		return nil
+	}
+
+	// Verify all deployments in one backend task if the backend accepts batches, or else each
+	// deployment, pausing in between to avoid bursts against the backend
 	var lastErr error
-	for deploymentName := range manifest.Deployments {
-		w.logger.Info("verifying deployment",
-			"app_id", app.ID,
-			"deployment", deploymentName)
+	var commitSHA string
+	if w.cfg.BatchDeployments && len(manifest.Deployments) > 1 {
+		commitSHA, lastErr = w.verifyBatch(ctx, app, ref, manifest)
+	} else {
+		first := true
+		for deploymentName := range manifest.Deployments {
+			if !first {
+				// Leave the remaining deployments to the next run when shutting down
+				if w.isDraining() {
+					w.logger.Info("shutting down, skipping remaining deployments", "app_id", app.ID)
+					return lastErr
+				}
+				if err := sleep(ctx, w.deploymentDelay()); err != nil {
+					return err
+				}
+			}
+			first = false
 
-		if err := w.verifyDeployment(ctx, app, deploymentName); err != nil {
-			w.logger.Error("deployment verification failed",
+			// Keep other instances from taking over while deployments are verified
+			if err := w.renewLease(ctx, app); err != nil {
+				return err
+			}
+
+			w.logger.Info("verifying deployment",
 				"app_id", app.ID,
-				"deployment", deploymentName,
+				"deployment", deploymentName)
+
+			// Reject malformed app IDs up front instead of sending them to the verification backend
+			if deployment := manifest.Deployments[deploymentName]; deployment != nil {
+				if err := rofl.ValidateAppID(deployment.AppID); err != nil {
+					msg := fmt.Sprintf("Invalid manifest: %s", err)
+					if err := w.updateDeployment(ctx, app, deploymentName, "", string(models.StatusFailed), msg); err != nil {
+						lastErr = fmt.Errorf("failed to update deployment verification: %w", err)
+					}
+					continue
+				}
+			}
+
+			sha, err := w.verifyDeployment(ctx, app, ref, deploymentName)
+			if err != nil {
+				w.logger.Error("deployment verification failed",
+					"app_id", app.ID,
+					"deployment", deploymentName,
+					"error", err)
+				lastErr = err
+				continue
+			}
+			if sha != "" {
+				commitSHA = sha
+			}
+		}
+	}
+
+	// Fetch the compose file at the verified commit so reviewers can see what runs inside the TEE.
+	if composePath := manifest.Artifacts.Container.Compose; composePath != "" && commitSHA != "" {
+		if err := w.fetchCompose(ctx, app, composePath, commitSHA); err != nil {
+			w.logger.Warn("failed to fetch compose file",
+				"app_id", app.ID,
+				"path", composePath,
+				"commit_sha", commitSHA,
 				"error", err)
-			lastErr = err
 		}
 	}
 
+	// Fetch the README at the verified commit, unless already cached for it.
+	if commitSHA != "" && app.ReadmeCommitSHA.String != commitSHA {
+		if err := w.fetchReadme(ctx, app, commitSHA); err != nil {
+			w.logger.Warn("failed to fetch README",
+				"app_id", app.ID,
+				"commit_sha", commitSHA,
+				"error", err)
+		}
+	}
+
+	// Summarize the dependency lockfiles at the verified commit, unless already done for it.
+	if commitSHA != "" {
+		if err := w.fetchLockfiles(ctx, app, commitSHA); err != nil {
+			w.logger.Warn("failed to fetch lockfiles",
+				"app_id", app.ID,
+				"commit_sha", commitSHA,
+				"error", err)
+		}
+	}
+
+	// Tags may be added to a commit after it was verified, so they are looked up every time.
+	if commitSHA != "" {
+		if err := w.fetchCommitTag(ctx, app, commitSHA); err != nil {
+			w.logger.Warn("failed to fetch commit tag",
+				"app_id", app.ID,
+				"commit_sha", commitSHA,
+				"error", err)
+		}
+	}
+
+	if app.AttestationURL.String != "" {
+		if err := w.probeAttestation(ctx, app, manifest); err != nil {
+			w.logger.Warn("failed to attest live instance", "app_id", app.ID, "error", err)
+		}
+	}
+
+	if w.cfg.VerifyDomains && manifest.Homepage != "" {
+		if err := w.checkDomain(ctx, app, manifest); err != nil {
+			w.logger.Warn("failed to verify homepage domain", "app_id", app.ID, "error", err)
+		}
+	}
+
+	if w.cfg.Nexus.Enabled {
+		if err := w.checkLiveDeployments(ctx, app, manifest); err != nil {
+			w.logger.Warn("failed to check live deployments", "app_id", app.ID, "error", err)
+		}
+	}
+
+	if len(w.cfg.Policy.Rules) > 0 {
+		if err := w.checkPolicy(ctx, app, manifest); err != nil {
+			w.logger.Warn("failed to check verification policy", "app_id", app.ID, "error", err)
+		}
+	}
+
+	if err := w.fetchOwner(ctx, app); err != nil {
+		w.logger.Warn("failed to fetch repository owner", "app_id", app.ID, "error", err)
+	}
+
+	// Logos in the repository are taken from the verified commit if there is one.
+	logoRef := commitSHA
+	if logoRef == "" {
+		logoRef = ref
+	}
+	if err := w.fetchLogo(ctx, app, manifest.Logo, logoRef); err != nil {
+		w.logger.Warn("failed to fetch logo", "app_id", app.ID, "error", err)
+	}
+
 	return lastErr
 }
 
-// fetchRoflYAML fetches the rofl.yaml file from GitHub and updates the database.
-func (w *Worker) fetchRoflYAML(ctx context.Context, app *models.App) error {
-	rawURL := fmt.Sprintf("https://raw.githubusercontent.com%s/%s/rofl.yaml",
-		app.GitHubURL[len("https://github.com"):],
-		app.GitRef)
+// selectDeployments limits the deployments of a manifest to those selected in the registry and
+// removes the results of the others, so deployments that are not verified are not shown as stale.
+func (w *Worker) selectDeployments(ctx context.Context, app *models.App, manifest *rofl.Manifest) {
+	selected := strings.Split(app.SelectedDeployments.String, ",")
+	for name := range manifest.Deployments {
+		if !slices.Contains(selected, name) {
+			delete(manifest.Deployments, name)
+		}
+	}
+	for _, name := range selected {
+		if _, ok := manifest.Deployments[name]; !ok {
+			w.logger.Warn("selected deployment not declared in manifest", "app_id", app.ID, "deployment", name)
+		}
+	}
 
-	w.logger.Debug("fetching rofl.yaml", "url", rawURL)
+	removed, err := w.db.DeleteDeploymentsExcept(ctx, app.ID, selected)
+	if err != nil {
+		w.logger.Error("failed to remove unselected deployments", "app_id", app.ID, "error", err)
+		return
+	}
+	if removed > 0 {
+		w.logger.Info("removed unselected deployments", "app_id", app.ID, "count", removed)
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+// errRepositoryDeleted is returned when the repository of an app no longer exists.
+var errRepositoryDeleted = errors.New("repository deleted")
+
+// fetchRepository updates the GitHub stargazer count of an app, used to sort the app list, and
+// records whether its repository was archived, deleted, or renamed or transferred. Moves are only
+// recorded, so that the app is not moved to another repository without confirmation.
+func (w *Worker) fetchRepository(ctx context.Context, app *models.App) error {
+	repo, err := w.github.Repository(ctx, app.GitHubURL)
+	if errors.Is(err, github.ErrNotFound) {
+		if err := w.db.UpdateAppRepoStatus(ctx, app.ID, models.RepoDeleted); err != nil {
+			return fmt.Errorf("failed to update db: %w", err)
+		}
+		return errRepositoryDeleted
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
+	}
+	if err := w.db.UpdateAppStars(ctx, app.ID, repo.Stars); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+	status := ""
+	if repo.Archived {
+		status = models.RepoArchived
+	}
+	if status != app.RepoStatus.String {
+		w.logger.Info("repository status changed", "app_id", app.ID, "github_url", app.GitHubURL, "status", cmp.Or(status, "maintained"))
+	}
+	if err := w.db.UpdateAppRepoStatus(ctx, app.ID, status); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
 	}
 
-	resp, err := w.client.Do(req)
+	movedTo := ""
+	if repo.Moved(app.GitHubURL) {
+		movedTo = repo.URL
+	}
+	if movedTo == app.MovedTo.String {
+		return nil
+	}
+	if movedTo != "" {
+		w.logger.Warn("repository moved, confirm the move to update the app", "app_id", app.ID, "github_url", app.GitHubURL, "moved_to", movedTo)
+	}
+	if err := w.db.RecordAppMove(ctx, app.ID, movedTo); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+	return nil
+}
+
+// fetchOwner updates the profile of the user or organization owning an app's repository.
+func (w *Worker) fetchOwner(ctx context.Context, app *models.App) error {
+	owner, err := w.github.Owner(ctx, app.GitHubURL)
 	if err != nil {
-		return fmt.Errorf("failed to fetch: %w", err)
+		return err
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	if err := w.db.UpdateAppOwner(ctx, app.ID, owner.Login, owner.Name, owner.AvatarURL, owner.Type,
+		owner.Blog, owner.Email, owner.Verified); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
+// fetchCommitTag records the tag pointing at the verified commit of an app, if any, so that the
+// verified version is shown by name.
+func (w *Worker) fetchCommitTag(ctx context.Context, app *models.App, commitSHA string) error {
+	tag, err := w.github.CommitTag(ctx, app.GitHubURL, commitSHA)
+	if err != nil {
+		return err
+	}
+	if err := w.db.UpdateDeploymentCommitTag(ctx, app.ID, commitSHA, tag); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
 	}
+	return nil
+}
 
-	roflYAML, err := io.ReadAll(resp.Body)
+// fetchRoflYAML fetches the rofl.yaml file from GitHub at ref and updates the database.
+// The fetch is conditional on the previous fetch, so unchanged manifests are not re-downloaded.
+func (w *Worker) fetchRoflYAML(ctx context.Context, app *models.App, ref string) error {
+	manifest, err := w.github.FetchManifest(ctx, app.GitHubURL, ref, github.CachedManifest(app))
 	if err != nil {
-		return fmt.Errorf("failed to read: %w", err)
+		return err
+	}
+	if manifest.NotModified {
+		w.logger.Debug("rofl.yaml not modified", "path", manifest.Path)
+		return nil
 	}
 
-	if err := w.db.UpdateAppRoflYAML(ctx, app.ID, string(roflYAML)); err != nil {
+	if err := w.db.UpdateAppRoflYAML(ctx, app.ID, string(manifest.Data), manifest.Path, manifest.ETag, manifest.LastModified); err != nil {
 		return fmt.Errorf("failed to update db: %w", err)
 	}
+	if err := w.db.RecordManifestVersion(ctx, app.ID, string(manifest.Data)); err != nil {
+		return err
+	}
 
-	app.RoflYAML.String = string(roflYAML)
+	app.RoflYAML.String = string(manifest.Data)
 	app.RoflYAML.Valid = true
 
-	w.logger.Debug("successfully fetched rofl.yaml", "size", len(roflYAML))
+	w.logger.Debug("successfully fetched rofl.yaml", "path", manifest.Path, "size", len(manifest.Data))
+	return nil
+}
+
+// maxComposeSize limits the size of fetched compose files.
+const maxComposeSize = 1 * 1024 * 1024
+
+// fetchCompose fetches the compose file referenced by the manifest at the given commit and stores it.
+func (w *Worker) fetchCompose(ctx context.Context, app *models.App, composePath, commitSHA string) error {
+	// The compose path is relative to the repository root; refuse anything that escapes it.
+	cleanPath := path.Clean(composePath)
+	if path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+		return fmt.Errorf("invalid compose path %q", composePath)
+	}
+
+	compose, err := w.github.FetchFile(ctx, app.GitHubURL, commitSHA, cleanPath, maxComposeSize)
+	if err != nil {
+		return err
+	}
+
+	// Make sure it parses before storing it.
+	parsed, err := rofl.ParseCompose(compose)
+	if err != nil {
+		return err
+	}
+
+	// Keep oversized compose files out of the database.
+	key := fmt.Sprintf("apps/%d/compose/%s/%s", app.ID, commitSHA, path.Base(cleanPath))
+	inline, ref, err := w.artifacts.Offload(ctx, key, "application/yaml", string(compose))
+	if err != nil {
+		w.logger.Warn("failed to offload compose file, storing excerpt", "app_id", app.ID, "error", err)
+	}
+
+	if err := w.db.UpdateAppCompose(ctx, app.ID, inline, ref, commitSHA); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+
+	w.logger.Debug("successfully fetched compose file", "size", len(compose), "commit_sha", commitSHA)
+
+	w.checkComposeImages(ctx, app, parsed)
+	return nil
+}
+
+// maxReadmeExcerpt is the length of README excerpts shown in the app details.
+const maxReadmeExcerpt = 1500
+
+// fetchReadme fetches the README at the given commit and stores an excerpt of it.
+func (w *Worker) fetchReadme(ctx context.Context, app *models.App, commitSHA string) error {
+	readme, err := w.github.FetchReadme(ctx, app.GitHubURL, commitSHA)
+	switch {
+	case errors.Is(err, github.ErrNotFound):
+		// Remember the README is missing so it is not looked up again at this commit.
+		readme = nil
+	case err != nil:
+		return err
+	}
+
+	excerpt := github.ReadmeExcerpt(readme, maxReadmeExcerpt)
+	if err := w.db.UpdateAppReadme(ctx, app.ID, excerpt, commitSHA); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+
+	w.logger.Debug("successfully fetched README", "size", len(readme), "commit_sha", commitSHA)
+	return nil
+}
+
+// maxLockfileSize limits the size of fetched dependency lockfiles.
+const maxLockfileSize = 10 * 1024 * 1024
+
+// fetchLockfiles fetches the dependency lockfiles at the root of the repository at the given
+// commit and stores their digests and summaries.
+func (w *Worker) fetchLockfiles(ctx context.Context, app *models.App, commitSHA string) error {
+	existing, err := w.db.GetDependencyReport(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.CommitSHA == commitSHA {
+		return nil
+	}
+
+	var lockfiles []models.Lockfile
+	for _, filename := range lockfile.Filenames {
+		data, err := w.github.FetchFile(ctx, app.GitHubURL, commitSHA, filename, maxLockfileSize)
+		switch {
+		case errors.Is(err, github.ErrNotFound):
+			continue
+		case err != nil:
+			return err
+		}
+
+		summary, err := lockfile.Parse(filename, data)
+		if err != nil {
+			// Keep the digest, which still pins the dependency set.
+			w.logger.Warn("failed to parse lockfile", "app_id", app.ID, "path", filename, "error", err)
+			summary = &models.Lockfile{Path: filename, Ecosystem: lockfile.Ecosystem(filename), SHA256: lockfile.Digest(data)}
+		}
+		if summary.Ecosystem == "go" {
+			goMod, err := w.github.FetchFile(ctx, app.GitHubURL, commitSHA, "go.mod", maxLockfileSize)
+			if err != nil && !errors.Is(err, github.ErrNotFound) {
+				return err
+			}
+			summary.Direct = lockfile.GoModDirect(goMod)
+		}
+		lockfiles = append(lockfiles, *summary)
+	}
+
+	if err := w.db.UpsertDependencyReport(ctx, app.ID, commitSHA, lockfiles); err != nil {
+		return fmt.Errorf("failed to update db: %w", err)
+	}
+
+	w.logger.Debug("successfully fetched lockfiles", "count", len(lockfiles), "commit_sha", commitSHA)
 	return nil
 }
 
+// checkComposeImages warns about images referenced by mutable tags and, if enabled,
+// records the digests those tags currently resolve to.
+func (w *Worker) checkComposeImages(ctx context.Context, app *models.App, compose *rofl.Compose) {
+	for _, img := range compose.Images() {
+		if img.Pinned() {
+			continue
+		}
+
+		w.logger.Warn("compose image not pinned by digest",
+			"app_id", app.ID,
+			"service", img.Service,
+			"image", img.Raw)
+
+		if !w.cfg.ResolveImageDigests {
+			continue
+		}
+
+		digest, err := w.resolveImageDigest(ctx, img)
+		if err != nil {
+			w.logger.Warn("failed to resolve image digest",
+				"app_id", app.ID,
+				"image", img.Raw,
+				"error", err)
+			continue
+		}
+
+		if err := w.db.UpsertImageDigest(ctx, app.ID, img.Raw, digest); err != nil {
+			w.logger.Error("failed to record image digest",
+				"app_id", app.ID,
+				"image", img.Raw,
+				"error", err)
+			continue
+		}
+
+		w.logger.Info("resolved image digest",
+			"app_id", app.ID,
+			"image", img.Raw,
+			"digest", digest)
+	}
+}
+
 // verifyDeployment submits a verification request for a specific deployment and polls for results.
-func (w *Worker) verifyDeployment(ctx context.Context, app *models.App, deploymentName string) error {
-	// Submit verification request
-	taskID, err := w.submitVerification(ctx, app.GitHubURL, app.GitRef, deploymentName)
+// It returns the commit SHA reported by the backend.
+func (w *Worker) verifyDeployment(ctx context.Context, app *models.App, ref, deploymentName string) (string, error) {
+	task, err := w.runVerification(ctx, app, ref, []string{deploymentName})
 	if err != nil {
-		// Don't overwrite existing results if we couldn't even enqueue the job
-		// This allows previous verification results to remain visible
-		w.logger.Warn("failed to submit verification, keeping existing results",
+		return "", err
+	}
+	return w.recordVerification(ctx, app, ref, deploymentName, task, task.result)
+}
+
+// verifyDeploymentsBatch verifies deployments of an app in a single backend task, so that their
+// shared build runs once. It returns the commit SHA reported by the backend.
+func (w *Worker) verifyDeploymentsBatch(ctx context.Context, app *models.App, ref string, deploymentNames []string) (string, error) {
+	task, err := w.runVerification(ctx, app, ref, deploymentNames)
+	if err != nil {
+		return "", err
+	}
+
+	var commitSHA string
+	var lastErr error
+	for _, deploymentName := range deploymentNames {
+		sha, err := w.recordVerification(ctx, app, ref, deploymentName, task, task.result.forDeployment(deploymentName))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if sha != "" {
+			commitSHA = sha
+		}
+	}
+	return commitSHA, lastErr
+}
+
+// verifyBatch verifies all deployments of an app in one backend task. Deployments with malformed
+// app IDs are failed up front and left out of the batch.
+func (w *Worker) verifyBatch(ctx context.Context, app *models.App, ref string, manifest *rofl.Manifest) (string, error) {
+	// Keep other instances from taking over while deployments are verified
+	if err := w.renewLease(ctx, app); err != nil {
+		return "", err
+	}
+
+	var lastErr error
+	var deploymentNames []string
+	for _, deploymentName := range slices.Sorted(maps.Keys(manifest.Deployments)) {
+		if deployment := manifest.Deployments[deploymentName]; deployment != nil {
+			if err := rofl.ValidateAppID(deployment.AppID); err != nil {
+				msg := fmt.Sprintf("Invalid manifest: %s", err)
+				if err := w.updateDeployment(ctx, app, deploymentName, "", string(models.StatusFailed), msg); err != nil {
+					lastErr = fmt.Errorf("failed to update deployment verification: %w", err)
+				}
+				continue
+			}
+		}
+		deploymentNames = append(deploymentNames, deploymentName)
+	}
+
+	w.logger.Info("verifying deployments",
+		"app_id", app.ID,
+		"deployments", deploymentNames)
+
+	var commitSHA string
+	var err error
+	switch len(deploymentNames) {
+	case 0:
+		return "", lastErr
+	case 1:
+		commitSHA, err = w.verifyDeployment(ctx, app, ref, deploymentNames[0])
+	default:
+		commitSHA, err = w.verifyDeploymentsBatch(ctx, app, ref, deploymentNames)
+	}
+	if err != nil {
+		w.logger.Error("deployment verification failed",
 			"app_id", app.ID,
-			"deployment", deploymentName,
+			"deployments", deploymentNames,
 			"error", err)
-		return fmt.Errorf("failed to submit verification: %w", err)
+		return commitSHA, err
 	}
+	return commitSHA, lastErr
+}
 
-	w.logger.Info("verification task submitted",
-		"app_id", app.ID,
-		"deployment", deploymentName,
-		"task_id", taskID)
+// verificationTask is a finished backend task verifying deployments of an app.
+type verificationTask struct {
+	id          string
+	submittedAt time.Time // Zero if the task was resumed after a restart.
+	result      *VerifyDeploymentsResult
+}
+
+// errBackend tags verification errors caused by the backend rather than by the app, which do not
+// count as failures of the app.
+var errBackend = errors.New("verification backend error")
+
+// runVerification submits a backend task verifying deployments of an app, or resumes the one
+// submitted before a restart, and polls for its result. Errors are tagged with errBackend.
+func (w *Worker) runVerification(ctx context.Context, app *models.App, ref string, deploymentNames []string) (*verificationTask, error) {
+	if err := w.budget.Acquire(ctx, TaskPeriodic); err != nil {
+		return nil, fmt.Errorf("%w: failed to wait for backend capacity: %w", errBackend, err)
+	}
+	defer w.budget.Release()
+
+	// Resume a task submitted before a restart instead of submitting a new one. When resumed, the
+	// submission time is unknown, so the duration of the task is not recorded.
+	deployments := strings.Join(deploymentNames, ",")
+	task := &verificationTask{}
+	var err error
+	task.id, err = w.queue.Task(ctx, app.ID, deployments)
+	if err != nil {
+		w.logger.Warn("failed to look up task in flight", "app_id", app.ID, "deployment", deployments, "error", err)
+	}
+	if task.id != "" {
+		w.logger.Info("resuming verification task",
+			"app_id", app.ID,
+			"deployment", deployments,
+			"task_id", task.id)
+	} else {
+		// Submit verification request
+		task.submittedAt = time.Now()
+		task.id, err = w.submitVerification(ctx, app.GitHubURL, ref, deploymentNames)
+		if err != nil {
+			// Don't overwrite existing results if we couldn't even enqueue the job
+			// This allows previous verification results to remain visible
+			w.logger.Warn("failed to submit verification, keeping existing results",
+				"app_id", app.ID,
+				"deployment", deployments,
+				"error", err)
+			return nil, fmt.Errorf("%w: failed to submit verification: %w", errBackend, err)
+		}
+
+		w.logger.Info("verification task submitted",
+			"app_id", app.ID,
+			"deployment", deployments,
+			"task_id", task.id)
+
+		if err := w.queue.SetTask(ctx, app.ID, deployments, task.id); err != nil {
+			w.logger.Warn("failed to record task in flight", "app_id", app.ID, "task_id", task.id, "error", err)
+		}
+	}
 
 	// Poll for results
-	result, err := w.pollResults(ctx, taskID)
+	task.result, err = w.pollResults(ctx, task.id)
+	if ctx.Err() == nil {
+		// Keep the task on shutdown so it can be resumed, otherwise it is finished or dead.
+		if err := w.queue.ClearTask(ctx, app.ID, deployments); err != nil {
+			w.logger.Warn("failed to clear task in flight", "app_id", app.ID, "task_id", task.id, "error", err)
+		}
+	}
 	if err != nil {
 		// Don't overwrite existing results if polling failed
 		// This allows previous verification results to remain visible
 		w.logger.Warn("failed to poll results, keeping existing results",
 			"app_id", app.ID,
-			"deployment", deploymentName,
-			"task_id", taskID,
+			"deployment", deployments,
+			"task_id", task.id,
 			"error", err)
-		return fmt.Errorf("failed to poll results: %w", err)
+		return nil, fmt.Errorf("%w: failed to poll results: %w", errBackend, err)
 	}
+	return task, nil
+}
 
+// recordVerification stores the result of a backend task for a deployment. It returns the commit
+// SHA reported by the backend.
+func (w *Worker) recordVerification(ctx context.Context, app *models.App, ref, deploymentName string, task *verificationTask, result *VerifyDeploymentsResult) (string, error) {
 	// Update database with results
 	status := "failed"
 	var verificationMsg string
+	report := verificationReport(result)
 	if result.Verified {
 		status = "verified"
 		verificationMsg = "Built enclave identities MATCH on-chain measurements. Verification successful."
 	} else {
 		// Parse verification failure details
-		verificationMsg = w.formatVerificationError(result)
+		verificationMsg = formatVerificationError(result, report)
 	}
 
 	// Use commit SHA from backend response
 	commitSHA := result.CommitSHA
 
-	if err := w.db.UpsertDeployment(ctx, app.ID, deploymentName, commitSHA, status, verificationMsg); err != nil {
-		return fmt.Errorf("failed to update deployment verification: %w", err)
+	// Apps pinned to a commit must be verified at exactly that commit, which the backend must report.
+	if github.IsCommitSHA(ref) && !strings.EqualFold(commitSHA, ref) {
+		status = "failed"
+		verificationMsg = fmt.Sprintf("Backend built commit %s instead of the pinned commit %s.", commitSHA, ref)
+		if commitSHA == "" {
+			verificationMsg = fmt.Sprintf("Backend did not report the built commit, so it cannot be matched to the pinned commit %s.", ref)
+		}
+	}
+
+	if err := w.updateDeployment(ctx, app, deploymentName, commitSHA, status, verificationMsg); err != nil {
+		return "", fmt.Errorf("failed to update deployment verification: %w", err)
+	}
+
+	if err := w.db.UpdateDeploymentReport(ctx, app.ID, deploymentName, report); err != nil {
+		w.logger.Warn("failed to store verification report", "app_id", app.ID, "deployment", deploymentName, "error", err)
+	}
+
+	cliVersion, builderImage := toolchainVersions(result, app)
+	if err := w.db.UpdateDeploymentToolchain(ctx, app.ID, deploymentName, cliVersion, builderImage); err != nil {
+		w.logger.Warn("failed to store toolchain versions", "app_id", app.ID, "deployment", deploymentName, "error", err)
+	}
+
+	w.storeBuildLog(ctx, app, deploymentName, task.id, result)
+
+	if !task.submittedAt.IsZero() {
+		if err := w.db.RecordVerificationJob(ctx, app.ID, deploymentName, task.id, status, task.submittedAt, time.Now()); err != nil {
+			w.logger.Warn("failed to record verification duration", "app_id", app.ID, "deployment", deploymentName, "error", err)
+		}
+	}
+
+	// Remember which manifest version verified, so later changes can be diffed against it.
+	if status == string(models.StatusVerified) {
+		if err := w.db.MarkManifestVerified(ctx, app.ID, app.RoflYAML.String); err != nil {
+			w.logger.Warn("failed to mark manifest verified", "app_id", app.ID, "error", err)
+		}
+	}
+
+	// Record the reproduced build, which the API serves provenance of.
+	if status == string(models.StatusVerified) && commitSHA != "" {
+		provenance := &models.Provenance{
+			AppID:          app.ID,
+			DeploymentName: deploymentName,
+			CommitSHA:      commitSHA,
+			CLIVersion:     cliVersion,
+			BuilderImage:   builderImage,
+			TaskID:         task.id,
+			StartedAt:      sql.NullTime{Time: task.submittedAt, Valid: !task.submittedAt.IsZero()},
+			FinishedAt:     time.Now(),
+		}
+		if err := w.db.RecordProvenance(ctx, provenance, app.RoflYAML.String); err != nil {
+			w.logger.Warn("failed to record provenance", "app_id", app.ID, "deployment", deploymentName, "error", err)
+		}
 	}
 
 	w.logger.Info("verification completed",
@@ -358,23 +1165,59 @@ func (w *Worker) verifyDeployment(ctx context.Context, app *models.App, deployme
 		"deployment", deploymentName,
 		"status", status,
 		"verified", result.Verified,
-		"commit_sha", commitSHA)
+		"commit_sha", commitSHA,
+		"cli_version", cliVersion)
 
-	return nil
+	return commitSHA, nil
+}
+
+// storeBuildLog stores the build output of a verification, offloading it to object storage if large.
+func (w *Worker) storeBuildLog(ctx context.Context, app *models.App, deploymentName, taskID string, result *VerifyDeploymentsResult) {
+	var buildLog strings.Builder
+	for _, part := range []struct{ name, output string }{
+		{"stdout", result.Stdout},
+		{"stderr", result.Stderr},
+		{"error", result.Err},
+	} {
+		if part.output == "" {
+			continue
+		}
+		fmt.Fprintf(&buildLog, "==> %s <==\n%s\n", part.name, strings.TrimRight(part.output, "\n"))
+	}
+	if buildLog.Len() == 0 {
+		return
+	}
+
+	key := fmt.Sprintf("apps/%d/deployments/%s/%s.log", app.ID, deploymentName, taskID)
+	inline, ref, err := w.artifacts.Offload(ctx, key, "text/plain; charset=utf-8", buildLog.String())
+	if err != nil {
+		w.logger.Warn("failed to offload build log, storing excerpt", "app_id", app.ID, "deployment", deploymentName, "error", err)
+	}
+	if err := w.db.UpdateDeploymentLog(ctx, app.ID, deploymentName, inline, ref); err != nil {
+		w.logger.Warn("failed to store build log", "app_id", app.ID, "deployment", deploymentName, "error", err)
+	}
 }
 
-// formatVerificationError formats verification errors into user-friendly messages.
-func (w *Worker) formatVerificationError(result *VerifyDeploymentsResult) string {
+// formatVerificationError formats verification errors into user-friendly messages, listing the
+// enclave identities of the report that were not built as expected.
+func formatVerificationError(result *VerifyDeploymentsResult, report *models.VerificationReport) string {
+	mismatched := mismatchedIdentities(report)
+
 	// Check if it's a command failure
-	if strings.Contains(result.Err, "exit status 1") || strings.Contains(result.Err, "command") {
+	if len(mismatched) > 0 || strings.Contains(result.Err, "exit status 1") || strings.Contains(result.Err, "command") {
 		msg := "Verification failed: enclave measurements do not match on-chain deployments.\n\n"
 
-		// Try to parse mismatched IDs from stderr
-		mismatchedIDs := w.parseMismatchedIDs(result.Stderr + "\n" + result.Stdout)
-		if len(mismatchedIDs) > 0 {
+		if len(mismatched) > 0 {
 			msg += "Mismatched Enclave IDs:\n"
-			for _, id := range mismatchedIDs {
-				msg += fmt.Sprintf("  - %s\n", id)
+			for _, enclave := range mismatched {
+				switch {
+				case enclave.Actual == "":
+					msg += fmt.Sprintf("  - %s (%s): not built\n", enclave.Expected, enclave.Source)
+				case enclave.Expected == "":
+					msg += fmt.Sprintf("  - built %s, not in %s policy\n", enclave.Actual, enclave.Source)
+				default:
+					msg += fmt.Sprintf("  - %s (%s): built %s\n", enclave.Expected, enclave.Source, enclave.Actual)
+				}
 			}
 			msg += "\n"
 		}
@@ -394,63 +1237,18 @@ func (w *Worker) formatVerificationError(result *VerifyDeploymentsResult) string
 	return "Verification failed: enclave measurements do not match"
 }
 
-// parseMismatchedIDs attempts to extract enclave IDs from verification output.
-func (w *Worker) parseMismatchedIDs(output string) []string {
-	var ids []string
-
-	// Look for patterns like "rofl1..." in the output
-	// Common in oasis-cli output when showing mismatches
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		// Match lines containing enclave identifiers
-		if strings.Contains(line, "rofl1") {
-			// Extract rofl1... addresses
-			words := strings.Fields(line)
-			for _, word := range words {
-				if strings.HasPrefix(word, "rofl1") && len(word) > 10 {
-					// Clean up any trailing punctuation
-					id := strings.TrimRight(word, ",.;:")
-					ids = append(ids, id)
-				}
-			}
-		}
-
-		// Also look for explicit mismatch indicators
-		if strings.Contains(strings.ToLower(line), "mismatch") ||
-			strings.Contains(strings.ToLower(line), "expected") ||
-			strings.Contains(strings.ToLower(line), "actual") {
-			// This line might contain relevant info, keep it
-			if strings.Contains(line, "rofl1") {
-				words := strings.Fields(line)
-				for _, word := range words {
-					if strings.HasPrefix(word, "rofl1") && len(word) > 10 {
-						id := strings.TrimRight(word, ",.;:")
-						ids = append(ids, id)
-					}
-				}
-			}
-		}
-	}
-
-	// Remove duplicates
-	seen := make(map[string]bool)
-	unique := []string{}
-	for _, id := range ids {
-		if !seen[id] {
-			seen[id] = true
-			unique = append(unique, id)
-		}
-	}
-
-	return unique
-}
-
-// submitVerification submits a verification request to the backend.
-func (w *Worker) submitVerification(ctx context.Context, repositoryURL, ref, deploymentName string) (string, error) {
+// submitVerification submits a verification request of deployments to the backend, batched into
+// one task if there are several.
+func (w *Worker) submitVerification(ctx context.Context, repositoryURL, ref string, deploymentNames []string) (string, error) {
 	reqBody := VerifyDeploymentsRequest{
-		RepositoryURL:  repositoryURL,
-		Ref:            ref,
-		DeploymentName: deploymentName,
+		RepositoryURL: repositoryURL,
+		Ref:           ref,
+		IncludeReport: true,
+	}
+	if len(deploymentNames) == 1 {
+		reqBody.DeploymentName = deploymentNames[0]
+	} else {
+		reqBody.DeploymentNames = deploymentNames
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -499,22 +1297,25 @@ func (w *Worker) submitVerification(ctx context.Context, repositoryURL, ref, dep
 func (w *Worker) pollResults(ctx context.Context, taskID string) (*VerifyDeploymentsResult, error) {
 	pollInterval := time.Duration(w.cfg.PollInterval) * time.Second
 	timeout := time.Duration(w.cfg.PollTimeout) * time.Minute
-	deadline := time.Now().Add(timeout)
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return nil, fmt.Errorf("polling timeout after %v", timeout)
+		case <-pollCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
 			}
-
-			result, status, err := w.checkResults(ctx, taskID)
+			return nil, fmt.Errorf("polling timeout after %v", timeout)
+		case <-ticker.C:
+			result, status, err := w.checkResults(pollCtx, taskID)
 			if err != nil {
+				if ctx.Err() == nil && pollCtx.Err() != nil {
+					return nil, fmt.Errorf("polling timeout after %v", timeout)
+				}
 				return nil, fmt.Errorf("failed to check results: %w", err)
 			}
 
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ptrus/rofl-attestations/config"
+	"github.com/ptrus/rofl-attestations/db"
+	"github.com/ptrus/rofl-attestations/models"
+)
+
+// Test that a verified build of another commit than the pinned one, or of an unreported commit,
+// fails the deployment and does not mark its manifest verified.
+func TestRecordVerificationPinnedCommit(t *testing.T) {
+	ctx := context.Background()
+	const pinned = "0123456789abcdef0123456789abcdef01234567"
+
+	for _, tc := range []struct {
+		name      string
+		commitSHA string
+		verified  bool
+	}{
+		{name: "pinned commit", commitSHA: pinned, verified: true},
+		{name: "other commit", commitSHA: "89abcdef0123456789abcdef0123456789abcdef"},
+		{name: "unreported commit"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			database, err := db.New(filepath.Join(t.TempDir(), "registry.db"))
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer func() {
+				_ = database.Close()
+			}()
+			if err := database.InitSchema(); err != nil {
+				t.Fatalf("Failed to create schema: %v", err)
+			}
+			if err := database.UpsertApp(ctx, models.DefaultNamespace, "https://github.com/example/app", pinned, false, "", "", nil); err != nil {
+				t.Fatalf("Failed to add app: %v", err)
+			}
+			if err := database.UpdateAppRoflYAML(ctx, 1, "name: demo", "rofl.yaml", "", ""); err != nil {
+				t.Fatalf("Failed to update manifest: %v", err)
+			}
+			if err := database.RecordManifestVersion(ctx, 1, "name: demo"); err != nil {
+				t.Fatalf("Failed to add manifest version: %v", err)
+			}
+			app, err := database.GetAppByID(ctx, 1)
+			if err != nil {
+				t.Fatalf("Failed to get app: %v", err)
+			}
+
+			w := &Worker{
+				cfg:       &config.WorkerConfig{},
+				db:        database,
+				logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+				notifyNow: make(chan struct{}, 1),
+			}
+			result := &VerifyDeploymentsResult{Verified: true, CommitSHA: tc.commitSHA}
+			if _, err := w.recordVerification(ctx, app, pinned, "mainnet", &verificationTask{id: "task"}, result); err != nil {
+				t.Fatalf("Failed to record verification: %v", err)
+			}
+
+			deployments, err := database.GetDeploymentsByAppID(ctx, app.ID)
+			if err != nil || len(deployments) != 1 {
+				t.Fatalf("Failed to get deployments: %v, %v", deployments, err)
+			}
+			wantStatus := models.StatusFailed
+			if tc.verified {
+				wantStatus = models.StatusVerified
+			}
+			if deployments[0].Status != wantStatus {
+				t.Errorf("Expected status %s, got %s", wantStatus, deployments[0].Status)
+			}
+
+			versions, err := database.GetManifestVersions(ctx, app.ID)
+			if err != nil || len(versions) != 1 {
+				t.Fatalf("Failed to get manifest versions: %v, %v", versions, err)
+			}
+			if versions[0].VerifiedAt.Valid != tc.verified {
+				t.Errorf("Expected the manifest to be marked verified: %v, got %v", tc.verified, versions[0].VerifiedAt.Valid)
+			}
+		})
+	}
+}
+
+// Test that issues link to the app in the registry of the namespace it is listed in.
+func TestIssueFooterNamespace(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.New(filepath.Join(t.TempDir(), "registry.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+	if err := database.InitSchema(); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	for _, namespace := range []string{models.DefaultNamespace, "partner"} {
+		if err := database.UpsertApp(ctx, namespace, "https://github.com/example/app", "main", false, "", "", nil); err != nil {
+			t.Fatalf("Failed to add app: %v", err)
+		}
+	}
+
+	w := &Worker{
+		cfg: &config.WorkerConfig{Notifications: config.NotificationsConfig{GitHubIssues: config.GitHubIssuesConfig{
+			PublicURL:     "https://registry.example.com/",
+			NamespaceURLs: map[string]string{"partner": "https://registry.example.com/partner"},
+		}}},
+		db:     database,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for appID, want := range map[int64]string{
+		1: "Registry entry: https://registry.example.com/apps/1\n",
+		2: "Registry entry: https://registry.example.com/partner/apps/2\n",
+	} {
+		if footer := w.issueFooter(ctx, &DeploymentNotification{AppID: appID}); !strings.HasPrefix(footer, want) {
+			t.Errorf("Expected footer of app %d to start with %q, got %q", appID, want, footer)
+		}
+	}
+}